@@ -0,0 +1,65 @@
+package anidb
+
+import (
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+
+	"golang.org/x/crypto/md4"
+)
+
+// ED2KChunkSize is the size of the chunks hashed individually.
+const ED2KChunkSize = 9728000
+
+// ComputeED2K returns the ED2K hash of the file, as used by AniDB to identify files.
+func ComputeED2K(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	return ComputeED2KFromReader(f)
+}
+
+// ComputeED2KFromReader returns the ED2K hash of the data.
+//   - The data is split into chunks of 9500 KiB which are hashed with MD4
+//   - If there is only one chunk, its hash is the ED2K hash
+//   - Otherwise, the ED2K hash is the MD4 hash of the concatenated chunk hashes
+//
+// When the size is a multiple of the chunk size, no empty chunk is appended, which is the variant used by AniDB.
+func ComputeED2KFromReader(r io.Reader) (string, error) {
+	buf := make([]byte, ED2KChunkSize)
+	chunkHashes := make([]byte, 0)
+	chunks := 0
+
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			h := md4.New()
+			h.Write(buf[:n])
+			chunkHashes = h.Sum(chunkHashes)
+			chunks++
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if chunks == 0 {
+		h := md4.New()
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+
+	if chunks == 1 {
+		return hex.EncodeToString(chunkHashes), nil
+	}
+
+	h := md4.New()
+	h.Write(chunkHashes)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
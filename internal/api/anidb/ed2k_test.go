@@ -0,0 +1,61 @@
+package anidb
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/md4"
+)
+
+func TestComputeED2KFromReader(t *testing.T) {
+
+	md4Sum := func(data []byte) []byte {
+		h := md4.New()
+		h.Write(data)
+		return h.Sum(nil)
+	}
+
+	// Two full chunks and a partial one
+	data := bytes.Repeat([]byte{0x61}, ED2KChunkSize*2+1024)
+	chunkHashes := make([]byte, 0)
+	chunkHashes = append(chunkHashes, md4Sum(data[:ED2KChunkSize])...)
+	chunkHashes = append(chunkHashes, md4Sum(data[ED2KChunkSize:ED2KChunkSize*2])...)
+	chunkHashes = append(chunkHashes, md4Sum(data[ED2KChunkSize*2:])...)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{
+			name:     "Empty",
+			data:     []byte{},
+			expected: "31d6cfe0d16ae931b73c59d7e0c089c0",
+		},
+		{
+			name:     "Single chunk",
+			data:     []byte("abc"),
+			expected: "a448017aaf21d8525fc10ae87aa6729d",
+		},
+		{
+			name:     "Exactly one chunk",
+			data:     data[:ED2KChunkSize],
+			expected: hex.EncodeToString(md4Sum(data[:ED2KChunkSize])),
+		},
+		{
+			name:     "Multiple chunks",
+			data:     data,
+			expected: hex.EncodeToString(md4Sum(chunkHashes)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := ComputeED2KFromReader(bytes.NewReader(tt.data))
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, hash)
+		})
+	}
+}
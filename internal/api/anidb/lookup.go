@@ -0,0 +1,117 @@
+package anidb
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"seanime/internal/api/anizip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+var ErrFileNotFound = errors.New("file not found")
+
+type (
+	// FileLookupClient looks up a file by its ED2K hash and size.
+	// It returns ErrFileNotFound if the file is unknown.
+	FileLookupClient interface {
+		LookupFile(ed2k string, size int64) (*FileLookupResult, error)
+	}
+
+	// FileLookupResult is the AniDB data of a file.
+	FileLookupResult struct {
+		AnidbAnimeId   int `json:"anidbAnimeId"`
+		AnidbEpisodeId int `json:"anidbEpisodeId,omitempty"`
+		// AniDB episode number, e.g. "1", "S1" (special), "C1" (opening/ending), "T1" (trailer), "O1" (other)
+		AnidbEpisode string `json:"anidbEpisode"`
+		// Optional, resolved from the AniDB ID if missing
+		AnilistId int `json:"anilistId,omitempty"`
+	}
+
+	// HTTPFileLookupClient queries an AniDB-compatible HTTP endpoint.
+	// The URL can contain the {ed2k} and {size} placeholders, otherwise they are added as query parameters.
+	// The endpoint should respond with a FileLookupResult, or a 404 status if the file is unknown.
+	HTTPFileLookupClient struct {
+		url    string
+		client *http.Client
+	}
+)
+
+func NewHTTPFileLookupClient(url string) *HTTPFileLookupClient {
+	return &HTTPFileLookupClient{
+		url: url,
+		client: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+	}
+}
+
+func (c *HTTPFileLookupClient) LookupFile(ed2k string, size int64) (*FileLookupResult, error) {
+	req, err := http.NewRequest(http.MethodGet, c.getURL(ed2k, size), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrFileNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	var ret FileLookupResult
+	if err := json.NewDecoder(resp.Body).Decode(&ret); err != nil {
+		return nil, err
+	}
+
+	if ret.AnidbAnimeId == 0 && ret.AnilistId == 0 {
+		return nil, ErrFileNotFound
+	}
+
+	return &ret, nil
+}
+
+func (c *HTTPFileLookupClient) getURL(ed2k string, size int64) string {
+	if strings.Contains(c.url, "{ed2k}") {
+		return strings.NewReplacer("{ed2k}", url.PathEscape(ed2k), "{size}", strconv.FormatInt(size, 10)).Replace(c.url)
+	}
+
+	params := url.Values{}
+	params.Add("ed2k", ed2k)
+	params.Add("size", strconv.FormatInt(size, 10))
+	if strings.Contains(c.url, "?") {
+		return c.url + "&" + params.Encode()
+	}
+	return c.url + "?" + params.Encode()
+}
+
+// GetAnilistId returns the AniList ID of the result, using the AniDB mappings if it is missing.
+func (r *FileLookupResult) GetAnilistId(cache *anizip.Cache) (int, error) {
+	if r.AnilistId > 0 {
+		return r.AnilistId, nil
+	}
+	if r.AnidbAnimeId == 0 {
+		return 0, errors.New("no AniDB ID")
+	}
+
+	media, err := anizip.FetchAniZipMediaC("anidb", r.AnidbAnimeId, cache)
+	if err != nil {
+		return 0, err
+	}
+	if media.GetMappings() == nil || media.GetMappings().AnilistID == 0 {
+		return 0, fmt.Errorf("no AniList mapping for AniDB ID %d", r.AnidbAnimeId)
+	}
+
+	return media.GetMappings().AnilistID, nil
+}
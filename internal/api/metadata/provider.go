@@ -6,10 +6,12 @@ import (
 	"seanime/internal/api/anilist"
 	"seanime/internal/api/anizip"
 	"seanime/internal/api/tvdb"
+	"seanime/internal/extension"
 	"seanime/internal/hook"
 	"seanime/internal/util/filecache"
 	"seanime/internal/util/result"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -22,6 +24,10 @@ type (
 		fileCacher         *filecache.Cacher
 		animeMetadataCache *result.Cache[string, *AnimeMetadata]
 		anizipCache        *anizip.Cache
+		// Metadata provider extensions
+		extensionBank *extension.UnifiedBank
+		settings      ProviderSettings
+		mu            sync.RWMutex
 	}
 
 	NewProviderImplOptions struct {
@@ -41,6 +47,8 @@ func NewProvider(options *NewProviderImplOptions) Provider {
 		fileCacher:         options.FileCacher,
 		animeMetadataCache: result.NewCache[string, *AnimeMetadata](),
 		anizipCache:        anizip.NewCache(),
+		extensionBank:      extension.NewUnifiedBank(),
+		settings:           ProviderSettings{},
 	}
 }
 
@@ -49,7 +57,8 @@ func (p *ProviderImpl) GetCache() *result.Cache[string, *AnimeMetadata] {
 	return p.animeMetadataCache
 }
 
-// GetAnimeMetadata fetches anime metadata from api.ani.zip.
+// GetAnimeMetadata fetches anime metadata from the configured sources.
+// By default, the source is api.ani.zip.
 func (p *ProviderImpl) GetAnimeMetadata(platform Platform, mId int) (ret *AnimeMetadata, err error) {
 	ret, ok := p.animeMetadataCache.Get(GetAnimeMetadataCacheKey(platform, mId))
 	if ok {
@@ -99,14 +108,48 @@ func (p *ProviderImpl) GetAnimeMetadata(platform Platform, mId int) (ret *AnimeM
 		return ret, nil
 	}
 
+	fetched, err := p.fetchAnimeMetadata(platform, mId)
+	if err != nil || fetched == nil {
+		return nil, err
+	}
+	ret.Titles = fetched.Titles
+	ret.Episodes = fetched.Episodes
+	ret.EpisodeCount = fetched.EpisodeCount
+	ret.SpecialCount = fetched.SpecialCount
+	ret.Mappings = fetched.Mappings
+
+	// Event
+	event := &AnimeMetadataEvent{
+		MediaId:       mId,
+		AnimeMetadata: ret,
+	}
+	err = hook.GlobalHookManager.OnAnimeMetadata().Trigger(event)
+	if err != nil {
+		return nil, err
+	}
+	ret = event.AnimeMetadata
+	mId = event.MediaId
+
+	p.animeMetadataCache.SetT(GetAnimeMetadataCacheKey(platform, mId), ret, 1*time.Hour)
+
+	return ret, nil
+}
+
+// fetchAniZipAnimeMetadata fetches anime metadata from api.ani.zip.
+func (p *ProviderImpl) fetchAniZipAnimeMetadata(platform Platform, mId int) (*AnimeMetadata, error) {
 	anizipMedia, err := anizip.FetchAniZipMediaC(string(platform), mId, p.anizipCache)
 	if err != nil || anizipMedia == nil {
 		return nil, err
 	}
 
-	ret.Titles = anizipMedia.Titles
-	ret.EpisodeCount = anizipMedia.EpisodeCount
-	ret.SpecialCount = anizipMedia.SpecialCount
+	ret := &AnimeMetadata{
+		Titles:       anizipMedia.Titles,
+		Episodes:     make(map[string]*EpisodeMetadata),
+		EpisodeCount: anizipMedia.EpisodeCount,
+		SpecialCount: anizipMedia.SpecialCount,
+		Mappings:     &AnimeMappings{},
+	}
+
 	ret.Mappings.AnimeplanetId = anizipMedia.Mappings.AnimeplanetID
 	ret.Mappings.KitsuId = anizipMedia.Mappings.KitsuID
 	ret.Mappings.MalId = anizipMedia.Mappings.MalID
@@ -148,20 +191,6 @@ func (p *ProviderImpl) GetAnimeMetadata(platform Platform, mId int) (ret *AnimeM
 		ret.Episodes[key] = em
 	}
 
-	// Event
-	event := &AnimeMetadataEvent{
-		MediaId:       mId,
-		AnimeMetadata: ret,
-	}
-	err = hook.GlobalHookManager.OnAnimeMetadata().Trigger(event)
-	if err != nil {
-		return nil, err
-	}
-	ret = event.AnimeMetadata
-	mId = event.MediaId
-
-	p.animeMetadataCache.SetT(GetAnimeMetadataCacheKey(platform, mId), ret, 1*time.Hour)
-
 	return ret, nil
 }
 
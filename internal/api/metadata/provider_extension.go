@@ -0,0 +1,293 @@
+package metadata
+
+import (
+	"fmt"
+	"seanime/internal/extension"
+	hibikemetadata "seanime/internal/extension/hibike/metadata"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+type (
+	// ProviderSettings defines which metadata provider extensions are used by the ProviderImpl.
+	ProviderSettings struct {
+		// ID of the metadata provider extension used as the primary source.
+		// If empty, api.ani.zip is used as the primary source.
+		PrimaryProvider string
+		// IDs of the metadata provider extensions used to fill in missing data, in order of priority.
+		// api.ani.zip is always tried right after the primary source when an extension is used as the primary source.
+		FallbackProviders []string
+	}
+
+	// metadataSource is a single source of anime metadata in the provider chain.
+	metadataSource struct {
+		name  string
+		fetch func(platform Platform, mId int) (*AnimeMetadata, error)
+	}
+)
+
+const builtinMetadataSource = "anizip"
+
+// InitExtensionBank sets the extension bank used to look up metadata provider extensions.
+func (p *ProviderImpl) InitExtensionBank(bank *extension.UnifiedBank) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.extensionBank = bank
+
+	p.logger.Debug().Msg("metadata: Initialized metadata provider extension bank")
+}
+
+// SetSettings sets the metadata provider chain.
+// The anime metadata cache is emptied if the chain has changed.
+func (p *ProviderImpl) SetSettings(settings *ProviderSettings) {
+	if settings == nil {
+		settings = &ProviderSettings{}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	changed := p.settings.PrimaryProvider != settings.PrimaryProvider ||
+		strings.Join(p.settings.FallbackProviders, ",") != strings.Join(settings.FallbackProviders, ",")
+
+	p.settings = ProviderSettings{
+		PrimaryProvider:   settings.PrimaryProvider,
+		FallbackProviders: lo.Uniq(lo.Compact(settings.FallbackProviders)),
+	}
+
+	if changed {
+		p.animeMetadataCache.Clear()
+	}
+}
+
+// getSources returns the ordered list of sources used to fetch anime metadata.
+//
+//	e.g. [primary extension, anizip, fallback extension 1, fallback extension 2]
+func (p *ProviderImpl) getSources() []*metadataSource {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	ret := make([]*metadataSource, 0, len(p.settings.FallbackProviders)+2)
+
+	if p.settings.PrimaryProvider != "" && p.settings.PrimaryProvider != builtinMetadataSource {
+		ret = append(ret, p.newExtensionSource(p.settings.PrimaryProvider))
+	}
+
+	ret = append(ret, &metadataSource{
+		name:  builtinMetadataSource,
+		fetch: p.fetchAniZipAnimeMetadata,
+	})
+
+	for _, id := range p.settings.FallbackProviders {
+		if id == p.settings.PrimaryProvider || id == builtinMetadataSource {
+			continue
+		}
+		ret = append(ret, p.newExtensionSource(id))
+	}
+
+	return ret
+}
+
+func (p *ProviderImpl) newExtensionSource(id string) *metadataSource {
+	bank := p.extensionBank
+	return &metadataSource{
+		name: id,
+		fetch: func(platform Platform, mId int) (*AnimeMetadata, error) {
+			ext, found := extension.GetExtension[extension.MetadataProviderExtension](bank, id)
+			if !found {
+				return nil, fmt.Errorf("metadata provider extension '%s' not found", id)
+			}
+
+			provider := ext.GetProvider()
+			supportedPlatforms := provider.GetSettings().SupportedPlatforms
+			if len(supportedPlatforms) > 0 && !lo.Contains(supportedPlatforms, string(platform)) {
+				return nil, fmt.Errorf("metadata provider extension '%s' does not support %s", id, platform)
+			}
+
+			res, err := provider.GetAnimeMetadata(hibikemetadata.AnimeMetadataOptions{
+				Platform: string(platform),
+				MediaID:  mId,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			return fromHibikeAnimeMetadata(res), nil
+		},
+	}
+}
+
+// fetchAnimeMetadata fetches anime metadata from the provider chain.
+// The first source that returns metadata is used, subsequent sources are only queried to fill in missing data.
+func (p *ProviderImpl) fetchAnimeMetadata(platform Platform, mId int) (*AnimeMetadata, error) {
+	var ret *AnimeMetadata
+	var lastErr error
+
+	for _, source := range p.getSources() {
+		if ret != nil && !ret.isIncomplete() {
+			break
+		}
+
+		res, err := source.fetch(platform, mId)
+		if err != nil || res == nil {
+			if err != nil {
+				lastErr = err
+				p.logger.Warn().Err(err).Str("source", source.name).Int("mediaId", mId).Msg("metadata: Failed to fetch anime metadata")
+			}
+			continue
+		}
+
+		if ret == nil {
+			ret = res
+			continue
+		}
+
+		mergeAnimeMetadata(ret, res)
+	}
+
+	if ret == nil {
+		return nil, lastErr
+	}
+
+	return ret, nil
+}
+
+// isIncomplete returns true if the metadata is missing episodes or episode details.
+func (m *AnimeMetadata) isIncomplete() bool {
+	if len(m.Episodes) == 0 || len(m.Titles) == 0 {
+		return true
+	}
+	for _, ep := range m.Episodes {
+		if ep == nil || ep.Title == "" || ep.Image == "" || ep.Summary == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeAnimeMetadata fills in the missing data of dst with the data from src.
+func mergeAnimeMetadata(dst *AnimeMetadata, src *AnimeMetadata) {
+	if dst == nil || src == nil {
+		return
+	}
+
+	if dst.Titles == nil {
+		dst.Titles = make(map[string]string)
+	}
+	for lang, title := range src.Titles {
+		if _, found := dst.Titles[lang]; !found {
+			dst.Titles[lang] = title
+		}
+	}
+
+	if dst.EpisodeCount == 0 {
+		dst.EpisodeCount = src.EpisodeCount
+	}
+	if dst.SpecialCount == 0 {
+		dst.SpecialCount = src.SpecialCount
+	}
+
+	if dst.Episodes == nil {
+		dst.Episodes = make(map[string]*EpisodeMetadata)
+	}
+	for key, srcEp := range src.Episodes {
+		if srcEp == nil {
+			continue
+		}
+		dstEp, found := dst.Episodes[key]
+		if !found || dstEp == nil {
+			dst.Episodes[key] = srcEp
+			continue
+		}
+		dstEp.Title = lo.CoalesceOrEmpty(dstEp.Title, srcEp.Title)
+		dstEp.Image = lo.CoalesceOrEmpty(dstEp.Image, srcEp.Image)
+		dstEp.Summary = lo.CoalesceOrEmpty(dstEp.Summary, srcEp.Summary)
+		dstEp.Overview = lo.CoalesceOrEmpty(dstEp.Overview, srcEp.Overview)
+		dstEp.AirDate = lo.CoalesceOrEmpty(dstEp.AirDate, srcEp.AirDate)
+		dstEp.Length = lo.CoalesceOrEmpty(dstEp.Length, srcEp.Length)
+		dstEp.SeasonNumber = lo.CoalesceOrEmpty(dstEp.SeasonNumber, srcEp.SeasonNumber)
+		dstEp.AbsoluteEpisodeNumber = lo.CoalesceOrEmpty(dstEp.AbsoluteEpisodeNumber, srcEp.AbsoluteEpisodeNumber)
+		dstEp.AnidbId = lo.CoalesceOrEmpty(dstEp.AnidbId, srcEp.AnidbId)
+		dstEp.AnidbEid = lo.CoalesceOrEmpty(dstEp.AnidbEid, srcEp.AnidbEid)
+		dstEp.TvdbId = lo.CoalesceOrEmpty(dstEp.TvdbId, srcEp.TvdbId)
+	}
+
+	if src.Mappings == nil {
+		return
+	}
+	if dst.Mappings == nil {
+		dst.Mappings = &AnimeMappings{}
+	}
+	dst.Mappings.AnimeplanetId = lo.CoalesceOrEmpty(dst.Mappings.AnimeplanetId, src.Mappings.AnimeplanetId)
+	dst.Mappings.KitsuId = lo.CoalesceOrEmpty(dst.Mappings.KitsuId, src.Mappings.KitsuId)
+	dst.Mappings.MalId = lo.CoalesceOrEmpty(dst.Mappings.MalId, src.Mappings.MalId)
+	dst.Mappings.Type = lo.CoalesceOrEmpty(dst.Mappings.Type, src.Mappings.Type)
+	dst.Mappings.AnilistId = lo.CoalesceOrEmpty(dst.Mappings.AnilistId, src.Mappings.AnilistId)
+	dst.Mappings.AnisearchId = lo.CoalesceOrEmpty(dst.Mappings.AnisearchId, src.Mappings.AnisearchId)
+	dst.Mappings.AnidbId = lo.CoalesceOrEmpty(dst.Mappings.AnidbId, src.Mappings.AnidbId)
+	dst.Mappings.NotifymoeId = lo.CoalesceOrEmpty(dst.Mappings.NotifymoeId, src.Mappings.NotifymoeId)
+	dst.Mappings.LivechartId = lo.CoalesceOrEmpty(dst.Mappings.LivechartId, src.Mappings.LivechartId)
+	dst.Mappings.ThetvdbId = lo.CoalesceOrEmpty(dst.Mappings.ThetvdbId, src.Mappings.ThetvdbId)
+	dst.Mappings.ImdbId = lo.CoalesceOrEmpty(dst.Mappings.ImdbId, src.Mappings.ImdbId)
+	dst.Mappings.ThemoviedbId = lo.CoalesceOrEmpty(dst.Mappings.ThemoviedbId, src.Mappings.ThemoviedbId)
+}
+
+// fromHibikeAnimeMetadata converts the metadata returned by an extension.
+func fromHibikeAnimeMetadata(res *hibikemetadata.AnimeMetadata) *AnimeMetadata {
+	if res == nil {
+		return nil
+	}
+
+	ret := &AnimeMetadata{
+		Titles:       res.Titles,
+		Episodes:     make(map[string]*EpisodeMetadata, len(res.Episodes)),
+		EpisodeCount: res.EpisodeCount,
+		SpecialCount: res.SpecialCount,
+		Mappings:     &AnimeMappings{},
+	}
+	if ret.Titles == nil {
+		ret.Titles = make(map[string]string)
+	}
+
+	for key, ep := range res.Episodes {
+		if ep == nil {
+			continue
+		}
+		summary := strings.ReplaceAll(ep.Summary, "`", "'")
+		ret.Episodes[key] = &EpisodeMetadata{
+			AnidbId:               ep.AnidbEid,
+			TvdbId:                ep.TvdbId,
+			Title:                 ep.Title,
+			Image:                 ep.Image,
+			AirDate:               ep.AirDate,
+			Length:                ep.Length,
+			Summary:               summary,
+			Overview:              summary,
+			EpisodeNumber:         ep.EpisodeNumber,
+			Episode:               lo.CoalesceOrEmpty(ep.Episode, key),
+			SeasonNumber:          ep.SeasonNumber,
+			AbsoluteEpisodeNumber: ep.AbsoluteEpisodeNumber,
+			AnidbEid:              ep.AnidbEid,
+		}
+	}
+
+	if res.Mappings != nil {
+		ret.Mappings = &AnimeMappings{
+			AnimeplanetId: res.Mappings.AnimeplanetId,
+			KitsuId:       res.Mappings.KitsuId,
+			MalId:         res.Mappings.MalId,
+			Type:          res.Mappings.Type,
+			AnilistId:     res.Mappings.AnilistId,
+			AnisearchId:   res.Mappings.AnisearchId,
+			AnidbId:       res.Mappings.AnidbId,
+			NotifymoeId:   res.Mappings.NotifymoeId,
+			LivechartId:   res.Mappings.LivechartId,
+			ThetvdbId:     res.Mappings.ThetvdbId,
+			ImdbId:        res.Mappings.ImdbId,
+			ThemoviedbId:  res.Mappings.ThemoviedbId,
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,130 @@
+package metadata
+
+import (
+	"errors"
+	"seanime/internal/extension"
+	hibikemetadata "seanime/internal/extension/hibike/metadata"
+	"seanime/internal/util"
+	"seanime/internal/util/filecache"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeMetadataProvider struct {
+	res   *hibikemetadata.AnimeMetadata
+	err   error
+	calls int
+}
+
+func (f *fakeMetadataProvider) GetAnimeMetadata(opts hibikemetadata.AnimeMetadataOptions) (*hibikemetadata.AnimeMetadata, error) {
+	f.calls++
+	return f.res, f.err
+}
+
+func (f *fakeMetadataProvider) GetSettings() hibikemetadata.Settings {
+	return hibikemetadata.Settings{}
+}
+
+func newTestProvider(t *testing.T, providers map[string]*fakeMetadataProvider) *ProviderImpl {
+	filecacher, err := filecache.NewCacher(t.TempDir())
+	require.NoError(t, err)
+
+	p := NewProvider(&NewProviderImplOptions{
+		Logger:     util.NewLogger(),
+		FileCacher: filecacher,
+	}).(*ProviderImpl)
+
+	bank := extension.NewUnifiedBank()
+	for id, provider := range providers {
+		bank.Set(id, extension.NewMetadataProviderExtension(&extension.Extension{
+			ID:   id,
+			Type: extension.TypeMetadataProvider,
+		}, provider))
+	}
+	p.InitExtensionBank(bank)
+
+	return p
+}
+
+func TestProviderImpl_PrimaryExtension(t *testing.T) {
+	primary := &fakeMetadataProvider{
+		res: &hibikemetadata.AnimeMetadata{
+			Titles: map[string]string{"en": "Title"},
+			Episodes: map[string]*hibikemetadata.EpisodeMetadata{
+				"1": {EpisodeNumber: 1, Title: "Episode 1", Image: "image", Summary: "summary"},
+			},
+			EpisodeCount: 1,
+		},
+	}
+	fallback := &fakeMetadataProvider{err: errors.New("should not be called")}
+
+	p := newTestProvider(t, map[string]*fakeMetadataProvider{
+		"primary":  primary,
+		"fallback": fallback,
+	})
+	p.SetSettings(&ProviderSettings{
+		PrimaryProvider:   "primary",
+		FallbackProviders: []string{"fallback"},
+	})
+
+	res, err := p.GetAnimeMetadata(AnilistPlatform, 1)
+	require.NoError(t, err)
+
+	require.Equal(t, "Title", res.GetTitle())
+	require.Equal(t, 1, res.EpisodeCount)
+	ep, found := res.FindEpisode("1")
+	require.True(t, found)
+	require.Equal(t, "Episode 1", ep.GetTitle())
+	require.Equal(t, "1", ep.Episode)
+	require.NotNil(t, res.Mappings)
+
+	require.Equal(t, 1, primary.calls)
+	require.Equal(t, 0, fallback.calls)
+
+	// Cached
+	_, err = p.GetAnimeMetadata(AnilistPlatform, 1)
+	require.NoError(t, err)
+	require.Equal(t, 1, primary.calls)
+
+	// Changing the chain empties the cache
+	p.SetSettings(&ProviderSettings{
+		PrimaryProvider: "primary",
+	})
+	_, err = p.GetAnimeMetadata(AnilistPlatform, 1)
+	require.NoError(t, err)
+	require.Equal(t, 2, primary.calls)
+}
+
+func TestMergeAnimeMetadata(t *testing.T) {
+	dst := &AnimeMetadata{
+		Titles: map[string]string{"en": "Title"},
+		Episodes: map[string]*EpisodeMetadata{
+			"1": {EpisodeNumber: 1, Episode: "1", Title: "Episode 1"},
+		},
+		EpisodeCount: 2,
+		Mappings:     &AnimeMappings{AnilistId: 1},
+	}
+	src := &AnimeMetadata{
+		Titles: map[string]string{"en": "Other title", "ro": "Romaji"},
+		Episodes: map[string]*EpisodeMetadata{
+			"1": {EpisodeNumber: 1, Episode: "1", Title: "Other episode 1", Image: "image1", Summary: "summary1"},
+			"2": {EpisodeNumber: 2, Episode: "2", Title: "Episode 2"},
+		},
+		EpisodeCount: 3,
+		Mappings:     &AnimeMappings{AnilistId: 2, AnidbId: 3},
+	}
+
+	mergeAnimeMetadata(dst, src)
+
+	require.Equal(t, "Title", dst.Titles["en"])
+	require.Equal(t, "Romaji", dst.Titles["ro"])
+	require.Equal(t, 2, dst.EpisodeCount)
+	require.Len(t, dst.Episodes, 2)
+	require.Equal(t, "Episode 1", dst.Episodes["1"].Title)
+	require.Equal(t, "image1", dst.Episodes["1"].Image)
+	require.Equal(t, "summary1", dst.Episodes["1"].Summary)
+	require.Equal(t, "Episode 2", dst.Episodes["2"].Title)
+	require.Equal(t, 1, dst.Mappings.AnilistId)
+	require.Equal(t, 3, dst.Mappings.AnidbId)
+}
@@ -17,7 +17,7 @@ type (
 
 	Provider interface {
 		// GetAnimeMetadata fetches anime metadata for the given platform from a source.
-		// In this case, the source is api.ani.zip.
+		// By default, the source is api.ani.zip, metadata provider extensions can be used as primary or fallback sources.
 		GetAnimeMetadata(platform Platform, mId int) (*AnimeMetadata, error)
 		GetCache() *result.Cache[string, *AnimeMetadata]
 		// GetAnimeMetadataWrapper creates a wrapper for anime metadata.
@@ -103,6 +103,8 @@ func (m *Manager) UpdateWatchHistoryItem(opts *UpdateWatchHistoryItemOptions) (e
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	m.logWatchProgress(opts.Kind, opts.MediaId, opts.EpisodeNumber, opts.Filepath, opts.CurrentTime, opts.Duration)
+
 	added := false
 
 	// Get the current history
@@ -159,6 +161,36 @@ func (m *Manager) DeleteWatchHistoryItem(mediaId int) (err error) {
 	return nil
 }
 
+// UpdateWatchHistoryItemFilepaths replaces the file paths of the watch history items after files have been moved.
+//   - paths: Normalized old path -> New path
+func (m *Manager) UpdateWatchHistoryItemFilepaths(paths map[string]string) (err error) {
+	defer util.HandlePanicInModuleWithError("continuity/UpdateWatchHistoryItemFilepaths", &err)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	items, err := filecache.GetAll[*WatchHistoryItem](m.fileCacher, *m.watchHistoryFileCacheBucket)
+	if err != nil {
+		return fmt.Errorf("continuity: Failed to get watch history items: %w", err)
+	}
+
+	for key, item := range items {
+		if item == nil || item.Filepath == "" {
+			continue
+		}
+		newPath, found := paths[util.NormalizePath(item.Filepath)]
+		if !found {
+			continue
+		}
+		item.Filepath = newPath
+		if err := m.fileCacher.Set(*m.watchHistoryFileCacheBucket, key, item); err != nil {
+			return fmt.Errorf("continuity: Failed to save watch history item: %w", err)
+		}
+	}
+
+	return nil
+}
+
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
 // GetExternalPlayerEpisodeWatchHistoryItem is called before launching the external player to get the last known position.
@@ -300,21 +332,23 @@ func (m *Manager) UpdateExternalPlayerEpisodeWatchHistoryItem(currentTime, durat
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if !m.settings.WatchContinuityEnabled {
+	opts, ok := m.externalPlayerEpisodeDetails.Get()
+	if !ok {
 		return
 	}
 
-	if m.externalPlayerEpisodeDetails.IsAbsent() {
-		return
+	kind := opts.Kind
+	if kind == "" {
+		kind = ExternalPlayerKind
 	}
+	m.logWatchProgress(kind, opts.MediaId, opts.EpisodeNumber, opts.Filepath, currentTime, duration)
 
-	added := false
-
-	opts, ok := m.externalPlayerEpisodeDetails.Get()
-	if !ok {
+	if !m.settings.WatchContinuityEnabled {
 		return
 	}
 
+	added := false
+
 	// Get the current history
 	i, found := m.getWatchHistory(opts.MediaId)
 	if !found {
@@ -4,6 +4,7 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/samber/mo"
 	"seanime/internal/database/db"
+	"seanime/internal/platforms/platform"
 	"seanime/internal/util/filecache"
 	"sync"
 	"time"
@@ -13,6 +14,8 @@ const (
 	OnlinestreamKind   Kind = "onlinestream"
 	MediastreamKind    Kind = "mediastream"
 	ExternalPlayerKind Kind = "external_player"
+	TorrentstreamKind  Kind = "torrentstream"
+	DebridKind         Kind = "debrid"
 )
 
 type (
@@ -24,6 +27,10 @@ type (
 
 		externalPlayerEpisodeDetails mo.Option[*ExternalPlayerEpisodeDetails]
 
+		platform          platform.Platform
+		watchLogSessions  map[Kind]*watchLogSession
+		watchLogMediaInfo map[int]*watchLogMediaInfo
+
 		logger   *zerolog.Logger
 		settings *Settings
 		mu       sync.RWMutex
@@ -35,10 +42,13 @@ type (
 		EpisodeNumber int    `json:"episodeNumber"`
 		MediaId       int    `json:"mediaId"`
 		Filepath      string `json:"filepath"`
+		// Kind used in the watch log, defaults to ExternalPlayerKind
+		Kind Kind `json:"kind,omitempty"`
 	}
 
 	Settings struct {
 		WatchContinuityEnabled bool
+		WatchLogDisabled       bool
 	}
 
 	Kind string
@@ -49,6 +59,7 @@ type (
 		FileCacher *filecache.Cacher
 		Logger     *zerolog.Logger
 		Database   *db.Database
+		Platform   platform.Platform // optional - used to save the genres and studios in the watch log
 	}
 )
 
@@ -65,6 +76,9 @@ func NewManager(opts *NewManagerOptions) *Manager {
 			WatchContinuityEnabled: false,
 		},
 		externalPlayerEpisodeDetails: mo.None[*ExternalPlayerEpisodeDetails](),
+		platform:                     opts.Platform,
+		watchLogSessions:             make(map[Kind]*watchLogSession),
+		watchLogMediaInfo:            make(map[int]*watchLogMediaInfo),
 	}
 
 	ret.logger.Info().Msg("continuity: Initialized manager")
@@ -103,4 +117,12 @@ func (m *Manager) SetExternalPlayerEpisodeDetails(details *ExternalPlayerEpisode
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.externalPlayerEpisodeDetails = mo.Some(details)
+
+	// Start a new watch log entry
+	kind := details.Kind
+	if kind == "" {
+		kind = ExternalPlayerKind
+	}
+	delete(m.watchLogSessions, kind)
+	m.logWatchProgress(kind, details.MediaId, details.EpisodeNumber, details.Filepath, 0, 0)
 }
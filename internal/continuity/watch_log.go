@@ -0,0 +1,170 @@
+package continuity
+
+import (
+	"seanime/internal/database/models"
+	"seanime/internal/util"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+const (
+	// A new entry is created if the episode has not been updated for this long
+	watchLogSessionTimeout = 30 * time.Minute
+	// Position changes larger than the elapsed time (plus this margin) are counted as seeking
+	watchLogSeekMargin = 5 * time.Second
+)
+
+type (
+	// watchLogSession is the entry currently being updated for a Kind.
+	watchLogSession struct {
+		entry        *models.WatchLogEntry
+		lastPosition float64
+		lastUpdate   time.Time
+	}
+
+	watchLogMediaInfo struct {
+		genres  []string
+		studios []string
+	}
+)
+
+// logWatchProgress records the playback position of the episode in the watch log.
+// m.mu should be locked by the caller.
+func (m *Manager) logWatchProgress(kind Kind, mediaId int, episodeNumber int, filepath string, position float64, duration float64) {
+	defer util.HandlePanicInModuleThen("continuity/logWatchProgress", func() {})
+
+	if m.db == nil || m.settings.WatchLogDisabled || mediaId == 0 {
+		return
+	}
+
+	now := time.Now()
+
+	session, found := m.watchLogSessions[kind]
+	if !found ||
+		session.entry.MediaId != mediaId ||
+		session.entry.EpisodeNumber != episodeNumber ||
+		now.Sub(session.lastUpdate) > watchLogSessionTimeout {
+		session = m.startWatchLogSession(kind, mediaId, episodeNumber, filepath, position, now)
+		if session == nil {
+			return
+		}
+	}
+
+	session.entry.WatchedSeconds += getWatchedSeconds(session.lastPosition, position, now.Sub(session.lastUpdate))
+	session.entry.EndedAt = now
+	session.entry.Position = position
+	if duration > 0 {
+		session.entry.Duration = duration
+		if position/duration >= IgnoreRatioThreshold {
+			session.entry.Completed = true
+		}
+	}
+	session.lastPosition = position
+	session.lastUpdate = now
+
+	if err := m.db.UpdateWatchLogEntry(session.entry); err != nil {
+		m.logger.Error().Err(err).Msg("continuity: Failed to update watch log entry")
+	}
+}
+
+// startWatchLogSession creates a new entry for the episode.
+func (m *Manager) startWatchLogSession(kind Kind, mediaId int, episodeNumber int, filepath string, position float64, now time.Time) *watchLogSession {
+	entry := &models.WatchLogEntry{
+		MediaId:       mediaId,
+		EpisodeNumber: episodeNumber,
+		Kind:          string(kind),
+		Filepath:      filepath,
+		StartedAt:     now,
+		EndedAt:       now,
+		Position:      position,
+		Genres:        make([]string, 0),
+		Studios:       make([]string, 0),
+	}
+
+	if info, found := m.watchLogMediaInfo[mediaId]; found {
+		entry.Genres = info.genres
+		entry.Studios = info.studios
+	}
+
+	if err := m.db.InsertWatchLogEntry(entry); err != nil {
+		m.logger.Error().Err(err).Msg("continuity: Failed to create watch log entry")
+		return nil
+	}
+
+	session := &watchLogSession{
+		entry:        entry,
+		lastPosition: position,
+		lastUpdate:   now,
+	}
+	m.watchLogSessions[kind] = session
+
+	if _, found := m.watchLogMediaInfo[mediaId]; !found {
+		go m.fetchWatchLogMediaInfo(session.entry.ID, mediaId)
+	}
+
+	return session
+}
+
+// fetchWatchLogMediaInfo saves the genres and studios of the media in the entry.
+func (m *Manager) fetchWatchLogMediaInfo(entryId uint, mediaId int) {
+	defer util.HandlePanicInModuleThen("continuity/fetchWatchLogMediaInfo", func() {})
+
+	if m.platform == nil {
+		return
+	}
+
+	info := &watchLogMediaInfo{
+		genres:  make([]string, 0),
+		studios: make([]string, 0),
+	}
+
+	// The info is only cached if it could be fetched entirely, so that it is fetched again for the next entries
+	complete := true
+
+	if media, err := m.platform.GetAnime(mediaId); err == nil && media != nil {
+		info.genres = lo.Map(media.GetGenres(), func(g *string, _ int) string { return lo.FromPtr(g) })
+	} else {
+		complete = false
+	}
+	// Not available offline
+	if details, err := m.platform.GetAnimeDetails(mediaId); err == nil && details != nil {
+		if details.GetStudios() != nil {
+			for _, studio := range details.GetStudios().GetNodes() {
+				if studio != nil {
+					info.studios = append(info.studios, studio.GetName())
+				}
+			}
+		}
+	} else {
+		complete = false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if complete {
+		m.watchLogMediaInfo[mediaId] = info
+	}
+
+	for _, session := range m.watchLogSessions {
+		if session.entry.ID == entryId {
+			session.entry.Genres = info.genres
+			session.entry.Studios = info.studios
+			if err := m.db.UpdateWatchLogEntry(session.entry); err != nil {
+				m.logger.Error().Err(err).Msg("continuity: Failed to update watch log entry")
+			}
+			return
+		}
+	}
+}
+
+// getWatchedSeconds returns the time spent watching between two updates.
+// The position change is capped by the elapsed time so that seeking forward is not counted.
+func getWatchedSeconds(lastPosition float64, position float64, elapsed time.Duration) float64 {
+	delta := position - lastPosition
+	if delta <= 0 {
+		return 0
+	}
+	return min(delta, (elapsed + watchLogSeekMargin).Seconds())
+}
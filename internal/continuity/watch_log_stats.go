@@ -0,0 +1,181 @@
+package continuity
+
+import (
+	"errors"
+	"fmt"
+	"seanime/internal/database/models"
+	"sort"
+	"time"
+)
+
+const watchLogDayFormat = "2006-01-02"
+
+type (
+	// WatchLogStats is computed from the watch log entries, it does not depend on AniList.
+	WatchLogStats struct {
+		TotalSeconds      float64 `json:"totalSeconds"`
+		EpisodesWatched   int     `json:"episodesWatched"`
+		EpisodesRewatched int     `json:"episodesRewatched"`
+		// Time spent watching, by day (YYYY-MM-DD in local time)
+		SecondsByDay    map[string]float64 `json:"secondsByDay"`
+		SecondsByGenre  map[string]float64 `json:"secondsByGenre"`
+		SecondsByStudio map[string]float64 `json:"secondsByStudio"`
+		SecondsByKind   map[string]float64 `json:"secondsByKind"`
+		CurrentStreak   int                `json:"currentStreak"`
+		LongestStreak   int                `json:"longestStreak"`
+		Rewatches       []*WatchLogRewatch `json:"rewatches"`
+	}
+
+	// WatchLogRewatch is an episode that was completed more than once.
+	WatchLogRewatch struct {
+		MediaId       int `json:"mediaId"`
+		EpisodeNumber int `json:"episodeNumber"`
+		Count         int `json:"count"`
+	}
+
+	WatchLogOptions struct {
+		From    time.Time `json:"from"`
+		To      time.Time `json:"to"`
+		MediaId int       `json:"mediaId"`
+	}
+)
+
+// GetWatchLog returns the watch log entries that started in the time range.
+func (m *Manager) GetWatchLog(opts *WatchLogOptions) ([]*models.WatchLogEntry, error) {
+	if m.db == nil {
+		return nil, errors.New("database not initialized")
+	}
+
+	return m.db.GetWatchLogEntries(opts.From, opts.To, opts.MediaId)
+}
+
+// GetWatchLogStats returns the statistics of the watch log entries that started in the time range.
+func (m *Manager) GetWatchLogStats(opts *WatchLogOptions) (*WatchLogStats, error) {
+	entries, err := m.GetWatchLog(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return ComputeWatchLogStats(entries, time.Local, time.Now()), nil
+}
+
+// DeleteWatchLogEntry deletes an entry from the watch log.
+func (m *Manager) DeleteWatchLogEntry(id uint) error {
+	if m.db == nil {
+		return errors.New("database not initialized")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Stop updating the entry
+	for kind, session := range m.watchLogSessions {
+		if session.entry.ID == id {
+			delete(m.watchLogSessions, kind)
+		}
+	}
+
+	return m.db.DeleteWatchLogEntry(id)
+}
+
+// ComputeWatchLogStats aggregates the entries.
+// Days are computed in the given location, and the current streak is counted up to now.
+func ComputeWatchLogStats(entries []*models.WatchLogEntry, loc *time.Location, now time.Time) *WatchLogStats {
+	ret := &WatchLogStats{
+		SecondsByDay:    make(map[string]float64),
+		SecondsByGenre:  make(map[string]float64),
+		SecondsByStudio: make(map[string]float64),
+		SecondsByKind:   make(map[string]float64),
+		Rewatches:       make([]*WatchLogRewatch, 0),
+	}
+
+	completions := make(map[string]*WatchLogRewatch)
+
+	for _, entry := range entries {
+		if entry == nil {
+			continue
+		}
+
+		ret.TotalSeconds += entry.WatchedSeconds
+		if entry.WatchedSeconds > 0 {
+			ret.SecondsByDay[entry.StartedAt.In(loc).Format(watchLogDayFormat)] += entry.WatchedSeconds
+		}
+		ret.SecondsByKind[entry.Kind] += entry.WatchedSeconds
+		for _, genre := range entry.Genres {
+			ret.SecondsByGenre[genre] += entry.WatchedSeconds
+		}
+		for _, studio := range entry.Studios {
+			ret.SecondsByStudio[studio] += entry.WatchedSeconds
+		}
+
+		if entry.Completed {
+			key := fmt.Sprintf("%d-%d", entry.MediaId, entry.EpisodeNumber)
+			if c, found := completions[key]; found {
+				c.Count++
+			} else {
+				completions[key] = &WatchLogRewatch{
+					MediaId:       entry.MediaId,
+					EpisodeNumber: entry.EpisodeNumber,
+					Count:         1,
+				}
+			}
+		}
+	}
+
+	for _, c := range completions {
+		ret.EpisodesWatched++
+		if c.Count > 1 {
+			ret.EpisodesRewatched++
+			ret.Rewatches = append(ret.Rewatches, c)
+		}
+	}
+	sort.Slice(ret.Rewatches, func(i, j int) bool {
+		if ret.Rewatches[i].Count != ret.Rewatches[j].Count {
+			return ret.Rewatches[i].Count > ret.Rewatches[j].Count
+		}
+		if ret.Rewatches[i].MediaId != ret.Rewatches[j].MediaId {
+			return ret.Rewatches[i].MediaId < ret.Rewatches[j].MediaId
+		}
+		return ret.Rewatches[i].EpisodeNumber < ret.Rewatches[j].EpisodeNumber
+	})
+
+	ret.CurrentStreak, ret.LongestStreak = getWatchLogStreaks(ret.SecondsByDay, now.In(loc))
+
+	return ret
+}
+
+// getWatchLogStreaks returns the number of consecutive days with watch time.
+// The current streak is kept if nothing has been watched today yet.
+func getWatchLogStreaks(secondsByDay map[string]float64, now time.Time) (current int, longest int) {
+	days := make([]time.Time, 0, len(secondsByDay))
+	for day := range secondsByDay {
+		t, err := time.ParseInLocation(watchLogDayFormat, day, now.Location())
+		if err != nil {
+			continue
+		}
+		days = append(days, t)
+	}
+	if len(days) == 0 {
+		return 0, 0
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+	streak := 0
+	for i, day := range days {
+		if i > 0 && day.Equal(days[i-1].AddDate(0, 0, 1)) {
+			streak++
+		} else {
+			streak = 1
+		}
+		longest = max(longest, streak)
+	}
+
+	today := now.Format(watchLogDayFormat)
+	yesterday := now.AddDate(0, 0, -1).Format(watchLogDayFormat)
+	last := days[len(days)-1].Format(watchLogDayFormat)
+	if last == today || last == yesterday {
+		current = streak
+	}
+
+	return current, longest
+}
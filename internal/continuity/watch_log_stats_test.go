@@ -0,0 +1,53 @@
+package continuity
+
+import (
+	"seanime/internal/database/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeWatchLogStats(t *testing.T) {
+	loc := time.UTC
+	day := func(d int, hour int) time.Time {
+		return time.Date(2024, 5, d, hour, 0, 0, 0, loc)
+	}
+
+	entries := []*models.WatchLogEntry{
+		{MediaId: 1, EpisodeNumber: 1, Kind: string(ExternalPlayerKind), StartedAt: day(1, 20), WatchedSeconds: 1400, Completed: true, Genres: []string{"Action"}, Studios: []string{"MAPPA"}},
+		{MediaId: 1, EpisodeNumber: 2, Kind: string(ExternalPlayerKind), StartedAt: day(2, 20), WatchedSeconds: 1400, Completed: true, Genres: []string{"Action"}, Studios: []string{"MAPPA"}},
+		{MediaId: 2, EpisodeNumber: 1, Kind: string(TorrentstreamKind), StartedAt: day(3, 21), WatchedSeconds: 600, Genres: []string{"Comedy"}},
+		// Rewatch
+		{MediaId: 1, EpisodeNumber: 1, Kind: string(MediastreamKind), StartedAt: day(5, 10), WatchedSeconds: 1400, Completed: true, Genres: []string{"Action"}, Studios: []string{"MAPPA"}},
+		{MediaId: 1, EpisodeNumber: 1, Kind: string(MediastreamKind), StartedAt: day(6, 10), WatchedSeconds: 1400, Completed: true, Genres: []string{"Action"}, Studios: []string{"MAPPA"}},
+	}
+
+	stats := ComputeWatchLogStats(entries, loc, day(6, 23))
+
+	require.Equal(t, 6200.0, stats.TotalSeconds)
+	require.Equal(t, 2800.0, stats.SecondsByDay["2024-05-01"]+stats.SecondsByDay["2024-05-02"])
+	require.Equal(t, 5600.0, stats.SecondsByGenre["Action"])
+	require.Equal(t, 600.0, stats.SecondsByGenre["Comedy"])
+	require.Equal(t, 5600.0, stats.SecondsByStudio["MAPPA"])
+	require.Equal(t, 600.0, stats.SecondsByKind[string(TorrentstreamKind)])
+	require.Equal(t, 2, stats.EpisodesWatched)
+	require.Equal(t, 1, stats.EpisodesRewatched)
+	require.Len(t, stats.Rewatches, 1)
+	require.Equal(t, 3, stats.Rewatches[0].Count)
+	require.Equal(t, 3, stats.LongestStreak)
+	require.Equal(t, 2, stats.CurrentStreak)
+
+	// The streak is broken after a day without watching
+	stats = ComputeWatchLogStats(entries, loc, day(8, 12))
+	require.Equal(t, 0, stats.CurrentStreak)
+	require.Equal(t, 3, stats.LongestStreak)
+}
+
+func TestGetWatchedSeconds(t *testing.T) {
+	require.Equal(t, 30.0, getWatchedSeconds(100, 130, 30*time.Second))
+	// Seeking forward
+	require.Equal(t, 35.0, getWatchedSeconds(100, 700, 30*time.Second))
+	// Seeking backward
+	require.Equal(t, 0.0, getWatchedSeconds(700, 100, 30*time.Second))
+}
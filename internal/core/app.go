@@ -19,6 +19,7 @@ import (
 	"seanime/internal/library/autodownloader"
 	"seanime/internal/library/autoscanner"
 	"seanime/internal/library/fillermanager"
+	"seanime/internal/library/healthcheck"
 	"seanime/internal/library/playbackmanager"
 	"seanime/internal/library/scanner"
 	"seanime/internal/manga"
@@ -28,6 +29,7 @@ import (
 	"seanime/internal/mediaplayers/vlc"
 	"seanime/internal/mediastream"
 	"seanime/internal/onlinestream"
+	onlinestream_downloader "seanime/internal/onlinestream/downloader"
 	"seanime/internal/platforms/anilist_platform"
 	"seanime/internal/platforms/local_platform"
 	"seanime/internal/platforms/platform"
@@ -62,6 +64,8 @@ type (
 		FillerManager                 *fillermanager.FillerManager
 		WSEventManager                *events.WSEventManager
 		AutoDownloader                *autodownloader.AutoDownloader
+		LibraryHealthChecker          *healthcheck.Checker
+		MangaChapterUpdateChecker     *manga.ChapterUpdateChecker
 		ExtensionRepository           *extension_repo.Repository
 		ExtensionPlaygroundRepository *extension_playground.PlaygroundRepository
 		MediaPlayer                   struct {
@@ -76,6 +80,7 @@ type (
 		PlaybackManager         *playbackmanager.PlaybackManager
 		FileCacher              *filecache.Cacher
 		OnlinestreamRepository  *onlinestream.Repository
+		OnlinestreamDownloader  *onlinestream_downloader.Downloader
 		MangaRepository         *manga.Repository
 		MetadataProvider        metadata.Provider
 		DiscordPresence         *discordrpc_presence.Presence
@@ -314,6 +319,8 @@ func NewApp(configOpts *ConfigOptions, selfupdater *updater.SelfUpdater) *App {
 		TorrentRepository:             nil, // Initialized in App.initModulesOnce
 		FillerManager:                 nil, // Initialized in App.initModulesOnce
 		MangaDownloader:               nil, // Initialized in App.initModulesOnce
+		MangaChapterUpdateChecker:     nil, // Initialized in App.initModulesOnce
+		OnlinestreamDownloader:        nil, // Initialized in App.initModulesOnce
 		PlaybackManager:               nil, // Initialized in App.initModulesOnce
 		AutoDownloader:                nil, // Initialized in App.initModulesOnce
 		AutoScanner:                   nil, // Initialized in App.initModulesOnce
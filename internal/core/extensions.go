@@ -245,6 +245,11 @@ func (a *App) AddExtensionBankToConsumers() {
 		a.TorrentRepository,
 	}
 
+	// The metadata provider can be replaced by the local metadata provider in offline mode
+	if consumer, ok := a.MetadataProvider.(extension.Consumer); ok {
+		consumers = append(consumers, consumer)
+	}
+
 	for _, consumer := range consumers {
 		consumer.InitExtensionBank(a.ExtensionRepository.GetExtensionBank())
 	}
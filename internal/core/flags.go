@@ -11,6 +11,7 @@ type (
 		DataDir          string
 		Update           bool
 		IsDesktopSidecar bool
+		TestPlugin       string
 	}
 )
 
@@ -24,6 +25,8 @@ func GetSeanimeFlags() SeanimeFlags {
 		fmt.Printf("   directory that contains all Seanime data\n")
 		fmt.Printf("  -update")
 		fmt.Printf("   update the application\n")
+		fmt.Printf("  -test-plugin string")
+		fmt.Printf("   run the test scenario of a plugin (plugin directory or scenario file) and exit\n")
 		fmt.Printf("  -h                           show this help message\n")
 	}
 	// Parse flags
@@ -33,11 +36,14 @@ func GetSeanimeFlags() SeanimeFlags {
 	flag.BoolVar(&update, "update", false, "Update the application")
 	var isDesktopSidecar bool
 	flag.BoolVar(&isDesktopSidecar, "desktop-sidecar", false, "Run as the desktop sidecar")
+	var testPlugin string
+	flag.StringVar(&testPlugin, "test-plugin", "", "Run the test scenario of a plugin and exit")
 	flag.Parse()
 
 	return SeanimeFlags{
 		DataDir:          strings.TrimSpace(dataDir),
 		Update:           update,
 		IsDesktopSidecar: isDesktopSidecar,
+		TestPlugin:       strings.TrimSpace(testPlugin),
 	}
 }
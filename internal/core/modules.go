@@ -1,8 +1,10 @@
 package core
 
 import (
+	"path/filepath"
 	"runtime"
 	"seanime/internal/api/anilist"
+	"seanime/internal/api/metadata"
 	"seanime/internal/continuity"
 	"seanime/internal/database/db"
 	"seanime/internal/database/db_bridge"
@@ -14,6 +16,7 @@ import (
 	"seanime/internal/library/autodownloader"
 	"seanime/internal/library/autoscanner"
 	"seanime/internal/library/fillermanager"
+	"seanime/internal/library/healthcheck"
 	"seanime/internal/library/playbackmanager"
 	"seanime/internal/manga"
 	"seanime/internal/mediaplayers/mediaplayer"
@@ -22,8 +25,13 @@ import (
 	"seanime/internal/mediaplayers/vlc"
 	"seanime/internal/mediastream"
 	"seanime/internal/notifier"
+	"seanime/internal/onlinestream"
+	onlinestream_downloader "seanime/internal/onlinestream/downloader"
 	"seanime/internal/plugin"
+	"seanime/internal/torrent_clients/aria2"
+	"seanime/internal/torrent_clients/deluge"
 	"seanime/internal/torrent_clients/qbittorrent"
+	"seanime/internal/torrent_clients/rtorrent"
 	"seanime/internal/torrent_clients/torrent_client"
 	"seanime/internal/torrent_clients/transmission"
 	"seanime/internal/torrents/torrent"
@@ -86,6 +94,7 @@ func (a *App) initModulesOnce() {
 		FileCacher: a.FileCacher,
 		Logger:     a.Logger,
 		Database:   a.Database,
+		Platform:   a.AnilistPlatform,
 	})
 
 	// +---------------------+
@@ -148,6 +157,18 @@ func (a *App) initModulesOnce() {
 		a.AutoDownloader.Start()
 	}
 
+	// +---------------------+
+	// | Library Health Check|
+	// +---------------------+
+
+	a.LibraryHealthChecker = healthcheck.NewChecker(&healthcheck.NewCheckerOptions{
+		Logger:           a.Logger,
+		FileCacher:       a.FileCacher,
+		MetadataProvider: a.MetadataProvider,
+		WSEventManager:   a.WSEventManager,
+		AutoDownloader:   a.AutoDownloader,
+	})
+
 	// +---------------------+
 	// |   Auto Scanner      |
 	// +---------------------+
@@ -160,6 +181,7 @@ func (a *App) initModulesOnce() {
 		Enabled:          false, // Will be set in InitOrRefreshModules
 		AutoDownloader:   a.AutoDownloader,
 		MetadataProvider: a.MetadataProvider,
+		FileCacher:       a.FileCacher,
 		LogsDir:          a.Config.Logs.Dir,
 	})
 
@@ -176,6 +198,9 @@ func (a *App) initModulesOnce() {
 		WSEventManager: a.WSEventManager,
 		DownloadDir:    a.Config.Manga.DownloadDir,
 		Repository:     a.MangaRepository,
+		GetMedia: func(mediaId int) (*anilist.BaseManga, error) {
+			return a.AnilistPlatform.GetManga(mediaId)
+		},
 	})
 
 	if !a.IsOffline() {
@@ -183,6 +208,39 @@ func (a *App) initModulesOnce() {
 		a.MangaDownloader.Start()
 	}
 
+	// +-----------------------------+
+	// | Manga Chapter Update Checker |
+	// +-----------------------------+
+
+	a.MangaChapterUpdateChecker = manga.NewChapterUpdateChecker(&manga.NewChapterUpdateCheckerOptions{
+		Logger:         a.Logger,
+		Repository:     a.MangaRepository,
+		Downloader:     a.MangaDownloader,
+		Database:       a.Database,
+		WSEventManager: a.WSEventManager,
+		GetMangaCollection: func() (*anilist.MangaCollection, error) {
+			return a.GetMangaCollection(false)
+		},
+	})
+
+	if !a.IsOffline() {
+		// This is run in a goroutine
+		a.MangaChapterUpdateChecker.Start()
+	}
+
+	// +-------------------------+
+	// | Onlinestream Downloader |
+	// +-------------------------+
+
+	a.OnlinestreamDownloader = onlinestream_downloader.NewDownloader(&onlinestream_downloader.NewDownloaderOptions{
+		Logger:         a.Logger,
+		WSEventManager: a.WSEventManager,
+		Database:       a.Database,
+		DownloadDir:    filepath.Join(a.Config.Cache.Dir, "onlinestream"),
+	})
+
+	a.OnlinestreamDownloader.Start()
+
 	// +---------------------+
 	// |    Media Stream     |
 	// +---------------------+
@@ -202,17 +260,22 @@ func (a *App) initModulesOnce() {
 	// +---------------------+
 
 	a.TorrentstreamRepository = torrentstream.NewRepository(&torrentstream.NewRepositoryOptions{
-		Logger:             a.Logger,
-		BaseAnimeCache:     anilist.NewBaseAnimeCache(),
-		CompleteAnimeCache: anilist.NewCompleteAnimeCache(),
-		MetadataProvider:   a.MetadataProvider,
-		TorrentRepository:  a.TorrentRepository,
-		Platform:           a.AnilistPlatform,
-		PlaybackManager:    a.PlaybackManager,
-		WSEventManager:     a.WSEventManager,
-		Database:           a.Database,
+		Logger:                a.Logger,
+		BaseAnimeCache:        anilist.NewBaseAnimeCache(),
+		CompleteAnimeCache:    anilist.NewCompleteAnimeCache(),
+		MetadataProvider:      a.MetadataProvider,
+		TorrentRepository:     a.TorrentRepository,
+		Platform:              a.AnilistPlatform,
+		PlaybackManager:       a.PlaybackManager,
+		WSEventManager:        a.WSEventManager,
+		Database:              a.Database,
+		MediastreamRepository: a.MediastreamRepository,
 	})
 
+	a.DebridClientRepository.SetMediastreamRepository(a.MediastreamRepository)
+
+	a.initPlaylistStreamStarters()
+
 	plugin.GlobalAppContext.SetModulesPartial(plugin.AppContextModules{
 		MediaPlayerRepository: a.MediaPlayerRepository,
 		PlaybackManager:       a.PlaybackManager,
@@ -293,6 +356,26 @@ func (a *App) InitOrRefreshModules() {
 		a.TorrentRepository.SetSettings(&torrent.RepositorySettings{
 			DefaultAnimeProvider: settings.Library.TorrentProvider,
 		})
+
+		// Metadata provider
+		if metadataProvider, ok := a.MetadataProvider.(*metadata.ProviderImpl); ok {
+			metadataProvider.SetSettings(&metadata.ProviderSettings{
+				PrimaryProvider:   settings.Library.PrimaryMetadataProvider,
+				FallbackProviders: settings.Library.FallbackMetadataProviders,
+			})
+		}
+
+		// Online streaming
+		if a.OnlinestreamRepository != nil {
+			a.OnlinestreamRepository.SetSettings(&onlinestream.Settings{
+				FallbackProviders: settings.Library.OnlinestreamFallbackProviders,
+			})
+		}
+
+		// Online streaming downloader
+		if a.OnlinestreamDownloader != nil {
+			a.OnlinestreamDownloader.SetLibraryDir(settings.Library.LibraryPath)
+		}
 	}
 
 	if settings.MediaPlayer != nil {
@@ -325,6 +408,7 @@ func (a *App) InitOrRefreshModules() {
 		a.PlaybackManager.SetMediaPlayerRepository(a.MediaPlayerRepository)
 		a.PlaybackManager.SetSettings(&playbackmanager.Settings{
 			AutoPlayNextEpisode: a.Settings.GetLibrary().AutoPlayNextEpisode,
+			VersionPreferences:  anime.NewVersionPreferences(a.Settings.GetLibrary()),
 		})
 
 		a.TorrentstreamRepository.SetMediaPlayerRepository(a.MediaPlayerRepository)
@@ -332,6 +416,15 @@ func (a *App) InitOrRefreshModules() {
 		a.Logger.Warn().Msg("app: Did not initialize media player module, no settings found")
 	}
 
+	// +---------------------+
+	// |        Manga        |
+	// +---------------------+
+
+	if settings.Manga != nil {
+		a.MangaDownloader.SetSettings(settings.Manga)
+		a.MangaChapterUpdateChecker.SetSettings(settings.Manga)
+	}
+
 	// +---------------------+
 	// |       Torrents      |
 	// +---------------------+
@@ -370,6 +463,30 @@ func (a *App) InitOrRefreshModules() {
 		if err != nil && settings.Torrent.TransmissionUsername != "" && settings.Torrent.TransmissionPassword != "" { // Only log error if username and password are set
 			a.Logger.Error().Err(err).Msg("app: Failed to initialize transmission client")
 		}
+		// Init Deluge
+		delugeClient := deluge.New(&deluge.NewClientOptions{
+			Logger:   a.Logger,
+			Password: settings.Torrent.DelugePassword,
+			Host:     settings.Torrent.DelugeHost,
+			Port:     settings.Torrent.DelugePort,
+		})
+		// Init rTorrent
+		rtorrentClient, err := rtorrent.New(&rtorrent.NewClientOptions{
+			Logger:   a.Logger,
+			URL:      settings.Torrent.RTorrentURL,
+			Username: settings.Torrent.RTorrentUsername,
+			Password: settings.Torrent.RTorrentPassword,
+		})
+		if err != nil && settings.Torrent.Default == torrent_client.RTorrentClient {
+			a.Logger.Error().Err(err).Msg("app: Failed to initialize rTorrent client")
+		}
+		// Init aria2
+		aria2Client := aria2.New(&aria2.NewClientOptions{
+			Logger: a.Logger,
+			Secret: settings.Torrent.Aria2Secret,
+			Host:   settings.Torrent.Aria2Host,
+			Port:   settings.Torrent.Aria2Port,
+		})
 
 		// Shutdown torrent client first
 		if a.TorrentClientRepository != nil {
@@ -381,6 +498,9 @@ func (a *App) InitOrRefreshModules() {
 			Logger:            a.Logger,
 			QbittorrentClient: qbit,
 			Transmission:      trans,
+			Deluge:            delugeClient,
+			RTorrent:          rtorrentClient,
+			Aria2:             aria2Client,
 			TorrentRepository: a.TorrentRepository,
 			Provider:          settings.Torrent.Default,
 			MetadataProvider:  a.MetadataProvider,
@@ -434,6 +554,7 @@ func (a *App) InitOrRefreshModules() {
 	if settings.Library != nil {
 		a.ContinuityManager.SetSettings(&continuity.Settings{
 			WatchContinuityEnabled: settings.Library.EnableWatchContinuity,
+			WatchLogDisabled:       settings.Library.DisableWatchLog,
 		})
 	}
 
@@ -470,6 +591,10 @@ func (a *App) InitOrRefreshMediastreamSettings() {
 
 	a.MediastreamRepository.InitializeModules(settings, a.Config.Cache.Dir, a.Config.Cache.TranscodeDir)
 
+	if a.OnlinestreamDownloader != nil {
+		a.OnlinestreamDownloader.SetFfmpegPath(settings.FfmpegPath)
+	}
+
 	// Cleanup cache
 	go func() {
 		if settings.TranscodeEnabled {
@@ -0,0 +1,112 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"seanime/internal/continuity"
+	debrid_client "seanime/internal/debrid/client"
+	"seanime/internal/library/anime"
+	"seanime/internal/library/playbackmanager"
+	"seanime/internal/onlinestream"
+	"seanime/internal/torrentstream"
+)
+
+// initPlaylistStreamStarters lets the playback manager stream playlist items that are not in the library.
+// It should be called after the stream modules are initialized.
+func (a *App) initPlaylistStreamStarters() {
+
+	a.PlaybackManager.SetPlaylistStreamStarter(anime.PlaylistItemSourceTorrentstream, func(item *anime.PlaylistItem) error {
+		if a.SecondarySettings.Torrentstream == nil || !a.SecondarySettings.Torrentstream.Enabled {
+			return errors.New("torrent streaming is disabled")
+		}
+		if err := a.TorrentstreamRepository.FailIfNoSettings(); err != nil {
+			return err
+		}
+		// Sets the episode collection used by the playback manager for progress tracking
+		if _, err := a.TorrentstreamRepository.NewEpisodeCollection(item.MediaId); err != nil {
+			return err
+		}
+		return a.TorrentstreamRepository.StartStream(&torrentstream.StartStreamOptions{
+			MediaId:       item.MediaId,
+			EpisodeNumber: item.EpisodeNumber,
+			AniDBEpisode:  item.GetAniDBEpisode(),
+			AutoSelect:    true,
+			PlaybackType:  torrentstream.PlaybackTypeDefault,
+		})
+	})
+
+	a.PlaybackManager.SetPlaylistStreamStarter(anime.PlaylistItemSourceDebrid, func(item *anime.PlaylistItem) error {
+		if a.SecondarySettings.Debrid == nil || !a.SecondarySettings.Debrid.Enabled || !a.DebridClientRepository.HasProvider() {
+			return errors.New("debrid streaming is disabled")
+		}
+		if _, err := a.TorrentstreamRepository.NewEpisodeCollection(item.MediaId); err != nil {
+			return err
+		}
+		return a.DebridClientRepository.StartStream(&debrid_client.StartStreamOptions{
+			MediaId:       item.MediaId,
+			EpisodeNumber: item.EpisodeNumber,
+			AniDBEpisode:  item.GetAniDBEpisode(),
+			AutoSelect:    true,
+			PlaybackType:  debrid_client.PlaybackTypeDefault,
+		})
+	})
+
+	a.PlaybackManager.SetPlaylistStreamStarter(anime.PlaylistItemSourceOnlinestream, func(item *anime.PlaylistItem) error {
+		settings := a.Settings.GetLibrary()
+		if settings == nil || !settings.EnableOnlinestream {
+			return errors.New("online streaming is disabled")
+		}
+
+		provider := item.OnlinestreamProvider
+		if provider == "" && len(settings.OnlinestreamFallbackProviders) > 0 {
+			provider = settings.OnlinestreamFallbackProviders[0]
+		}
+		if provider == "" {
+			return errors.New("no online streaming provider")
+		}
+
+		media, err := a.OnlinestreamRepository.GetMedia(item.MediaId)
+		if err != nil {
+			return err
+		}
+
+		sources, err := a.OnlinestreamRepository.GetEpisodeSources(provider, item.MediaId, item.EpisodeNumber, false, media.GetStartYearSafe())
+		if err != nil {
+			return err
+		}
+
+		videoSource, ok := selectPlaylistVideoSource(sources.VideoSources)
+		if !ok {
+			return onlinestream.ErrNoVideoSourceFound
+		}
+
+		ec, err := a.TorrentstreamRepository.NewEpisodeCollection(item.MediaId)
+		if err != nil {
+			return err
+		}
+		// Set synchronously since the stream starts right away
+		a.PlaybackManager.SetStreamEpisodeCollection(ec.Episodes)
+
+		return a.PlaybackManager.StartStreamingUsingMediaPlayer(fmt.Sprintf("%s - Episode %d", media.GetPreferredTitle(), item.EpisodeNumber), &playbackmanager.StartPlayingOptions{
+			Payload:    videoSource.URL,
+			StreamKind: continuity.OnlinestreamKind,
+		}, media, item.GetAniDBEpisode())
+	})
+}
+
+// selectPlaylistVideoSource returns the first video source that does not require headers, since they cannot be sent by the media player.
+func selectPlaylistVideoSource(sources []*onlinestream.VideoSource) (*onlinestream.VideoSource, bool) {
+	var ret *onlinestream.VideoSource
+	for _, source := range sources {
+		if source == nil || source.URL == "" {
+			continue
+		}
+		if len(source.Headers) == 0 {
+			return source, true
+		}
+		if ret == nil {
+			ret = source
+		}
+	}
+	return ret, ret != nil
+}
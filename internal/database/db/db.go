@@ -35,6 +35,17 @@ func NewDatabase(appDataDir, dbName string, logger *zerolog.Logger) (*Database,
 		sqlitePath = filepath.Join(appDataDir, dbName+".db")
 	}
 
+	return openDatabase(sqlitePath, dbName, logger)
+}
+
+// NewInMemoryDatabase creates a database that only lives in memory.
+// It is used when running the app headlessly, e.g. to test plugins.
+func NewInMemoryDatabase(logger *zerolog.Logger) (*Database, error) {
+	return openDatabase(":memory:", "memory", logger)
+}
+
+func openDatabase(sqlitePath, dbName string, logger *zerolog.Logger) (*Database, error) {
+
 	// Connect to the SQLite database
 	db, err := gorm.Open(sqlite.Open(sqlitePath), &gorm.Config{
 		Logger: gormlogger.New(
@@ -52,6 +63,15 @@ func NewDatabase(appDataDir, dbName string, logger *zerolog.Logger) (*Database,
 		return nil, err
 	}
 
+	// Each connection to ":memory:" opens a new database, keep a single one
+	if sqlitePath == ":memory:" {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		sqlDB.SetMaxOpenConns(1)
+	}
+
 	// Migrate tables
 	err = migrateTables(db)
 	if err != nil {
@@ -88,9 +108,14 @@ func migrateTables(db *gorm.DB) error {
 		&models.MediaFiller{},
 		&models.MangaMapping{},
 		&models.OnlinestreamMapping{},
+		&models.OnlinestreamDownloadQueueItem{},
 		&models.DebridSettings{},
 		&models.DebridTorrentItem{},
 		&models.PluginData{},
+		&models.TitleAlias{},
+		&models.WatchLogEntry{},
+		&models.MangaChapterUpdate{},
+		&models.MangaKnownChapters{},
 		//&models.MangaChapterContainer{},
 	)
 	if err != nil {
@@ -0,0 +1,92 @@
+package db
+
+import (
+	"errors"
+	"seanime/internal/database/models"
+
+	"github.com/goccy/go-json"
+	"gorm.io/gorm"
+)
+
+// MangaKnownChapter is a chapter of a manga found during a chapter update check.
+type MangaKnownChapter struct {
+	ID     string `json:"id"`
+	Number string `json:"number"` // Normalized chapter number
+}
+
+// GetMangaChapterUpdates returns the chapter updates that haven't been dismissed, newest first.
+func (db *Database) GetMangaChapterUpdates() ([]*models.MangaChapterUpdate, error) {
+	var res []*models.MangaChapterUpdate
+	err := db.gormdb.Where("dismissed = ?", false).Order("detected_at desc").Find(&res).Error
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// InsertMangaChapterUpdate saves the chapter update if the chapter hasn't been recorded yet.
+// It returns false if the chapter was already recorded.
+func (db *Database) InsertMangaChapterUpdate(update *models.MangaChapterUpdate) (bool, error) {
+	var count int64
+	err := db.gormdb.Model(&models.MangaChapterUpdate{}).
+		Where("provider = ? AND media_id = ? AND chapter_id = ?", update.Provider, update.MediaId, update.ChapterId).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	return true, db.gormdb.Create(update).Error
+}
+
+// DismissMangaChapterUpdates hides the chapter updates from the unread feed.
+// If no IDs are given, all chapter updates are dismissed.
+func (db *Database) DismissMangaChapterUpdates(ids []uint) error {
+	query := db.gormdb.Model(&models.MangaChapterUpdate{})
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	} else {
+		query = query.Where("dismissed = ?", false)
+	}
+	return query.Update("dismissed", true).Error
+}
+
+// GetMangaKnownChapters returns the chapters found during the last check of the manga.
+// It returns false if the manga hasn't been checked yet.
+func (db *Database) GetMangaKnownChapters(provider string, mediaId int) ([]*MangaKnownChapter, bool, error) {
+	var res models.MangaKnownChapters
+	err := db.gormdb.Where("provider = ? AND media_id = ?", provider, mediaId).First(&res).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	var chapters []*MangaKnownChapter
+	if err := json.Unmarshal(res.Chapters, &chapters); err != nil {
+		return nil, false, err
+	}
+	return chapters, true, nil
+}
+
+// SaveMangaKnownChapters replaces the chapters found during the last check of the manga.
+func (db *Database) SaveMangaKnownChapters(provider string, mediaId int, chapters []*MangaKnownChapter) error {
+	data, err := json.Marshal(chapters)
+	if err != nil {
+		return err
+	}
+
+	var res models.MangaKnownChapters
+	err = db.gormdb.Where("provider = ? AND media_id = ?", provider, mediaId).First(&res).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	res.Provider = provider
+	res.MediaId = mediaId
+	res.Chapters = data
+	return db.gormdb.Save(&res).Error
+}
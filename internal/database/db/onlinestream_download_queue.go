@@ -0,0 +1,132 @@
+package db
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"seanime/internal/database/models"
+)
+
+func (db *Database) GetOnlinestreamDownloadQueue() ([]*models.OnlinestreamDownloadQueueItem, error) {
+	var res []*models.OnlinestreamDownloadQueueItem
+	err := db.gormdb.Find(&res).Error
+	if err != nil {
+		db.Logger.Error().Err(err).Msg("db: Failed to get onlinestream download queue")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (db *Database) GetNextOnlinestreamDownloadQueueItem() (*models.OnlinestreamDownloadQueueItem, error) {
+	var res models.OnlinestreamDownloadQueueItem
+	err := db.gormdb.Where("status = ?", "not_started").First(&res).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			db.Logger.Error().Err(err).Msg("db: Failed to get next onlinestream download queue item")
+		}
+		return nil, nil
+	}
+
+	return &res, nil
+}
+
+func (db *Database) DequeueOnlinestreamDownloadQueueItem() (*models.OnlinestreamDownloadQueueItem, error) {
+	// Pop the first item from the queue
+	var res models.OnlinestreamDownloadQueueItem
+	err := db.gormdb.Where("status = ?", "downloading").First(&res).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.gormdb.Delete(&res).Error
+	if err != nil {
+		db.Logger.Error().Err(err).Msg("db: Failed to delete onlinestream download queue item")
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (db *Database) InsertOnlinestreamDownloadQueueItem(item *models.OnlinestreamDownloadQueueItem) error {
+
+	// Check if the item already exists
+	var existingItem models.OnlinestreamDownloadQueueItem
+	err := db.gormdb.Where("provider = ? AND media_id = ? AND episode_number = ? AND dubbed = ?", item.Provider, item.MediaID, item.EpisodeNumber, item.Dubbed).First(&existingItem).Error
+	if err == nil {
+		db.Logger.Debug().Msg("db: Onlinestream download queue item already exists")
+		return errors.New("episode is already in the download queue")
+	}
+
+	if item.Provider == "" {
+		return errors.New("provider is empty")
+	}
+	if item.MediaID == 0 {
+		return errors.New("media ID is empty")
+	}
+	if len(item.SourceData) == 0 {
+		return errors.New("source is empty")
+	}
+
+	err = db.gormdb.Create(item).Error
+	if err != nil {
+		db.Logger.Error().Err(err).Msg("db: Failed to insert onlinestream download queue item")
+		return err
+	}
+	return nil
+}
+
+func (db *Database) UpdateOnlinestreamDownloadQueueItemStatus(provider string, mId int, episodeNumber int, dubbed bool, status string) error {
+	err := db.gormdb.Model(&models.OnlinestreamDownloadQueueItem{}).
+		Where("provider = ? AND media_id = ? AND episode_number = ? AND dubbed = ?", provider, mId, episodeNumber, dubbed).
+		Update("status", status).Error
+	if err != nil {
+		db.Logger.Error().Err(err).Msg("db: Failed to update onlinestream download queue item status")
+		return err
+	}
+	return nil
+}
+
+func (db *Database) DeleteOnlinestreamDownloadQueueItem(provider string, mId int, episodeNumber int, dubbed bool) error {
+	err := db.gormdb.
+		Where("provider = ? AND media_id = ? AND episode_number = ? AND dubbed = ?", provider, mId, episodeNumber, dubbed).
+		Delete(&models.OnlinestreamDownloadQueueItem{}).Error
+	if err != nil {
+		db.Logger.Error().Err(err).Msg("db: Failed to delete onlinestream download queue item")
+		return err
+	}
+	return nil
+}
+
+func (db *Database) ClearAllOnlinestreamDownloadQueueItems() error {
+	err := db.gormdb.
+		Where("status = ? OR status = ? OR status = ?", "not_started", "downloading", "errored").
+		Delete(&models.OnlinestreamDownloadQueueItem{}).
+		Error
+	if err != nil {
+		db.Logger.Error().Err(err).Msg("db: Failed to clear all onlinestream download queue items")
+		return err
+	}
+	return nil
+}
+
+func (db *Database) ResetErroredOnlinestreamDownloadQueueItems() error {
+	err := db.gormdb.Model(&models.OnlinestreamDownloadQueueItem{}).
+		Where("status = ?", "errored").
+		Update("status", "not_started").Error
+	if err != nil {
+		db.Logger.Error().Err(err).Msg("db: Failed to reset errored onlinestream download queue items")
+		return err
+	}
+	return nil
+}
+
+func (db *Database) ResetDownloadingOnlinestreamDownloadQueueItems() error {
+	err := db.gormdb.Model(&models.OnlinestreamDownloadQueueItem{}).
+		Where("status = ?", "downloading").
+		Update("status", "not_started").Error
+	if err != nil {
+		db.Logger.Error().Err(err).Msg("db: Failed to reset downloading onlinestream download queue items")
+		return err
+	}
+	return nil
+}
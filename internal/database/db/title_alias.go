@@ -0,0 +1,72 @@
+package db
+
+import (
+	"errors"
+	"seanime/internal/database/models"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+func (db *Database) GetTitleAliases() ([]*models.TitleAlias, error) {
+	var res []*models.TitleAlias
+	err := db.gormdb.Find(&res).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (db *Database) GetTitleAlias(id uint) (*models.TitleAlias, error) {
+	var res models.TitleAlias
+	err := db.gormdb.First(&res, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// UpsertTitleAlias inserts the alias or updates the existing alias with the same title, release group and season.
+// Release groups are compared case-insensitively.
+func (db *Database) UpsertTitleAlias(alias *models.TitleAlias) (*models.TitleAlias, error) {
+	alias.ReleaseGroup = normalizeAliasReleaseGroup(alias.ReleaseGroup)
+
+	var existing models.TitleAlias
+	// LOWER() also matches aliases saved before release groups were normalized
+	err := db.gormdb.Where("title = ? AND LOWER(release_group) = ? AND season = ?", alias.Title, alias.ReleaseGroup, alias.Season).First(&existing).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		alias.ID = 0
+		if err := db.gormdb.Create(alias).Error; err != nil {
+			return nil, err
+		}
+		return alias, nil
+	}
+
+	existing.ReleaseGroup = alias.ReleaseGroup
+	existing.MediaId = alias.MediaId
+	existing.EpisodeOffset = alias.EpisodeOffset
+	if err := db.gormdb.Save(&existing).Error; err != nil {
+		return nil, err
+	}
+
+	return &existing, nil
+}
+
+func (db *Database) UpdateTitleAlias(alias *models.TitleAlias) error {
+	alias.ReleaseGroup = normalizeAliasReleaseGroup(alias.ReleaseGroup)
+	return db.gormdb.Save(alias).Error
+}
+
+func (db *Database) DeleteTitleAlias(id uint) error {
+	return db.gormdb.Delete(&models.TitleAlias{}, id).Error
+}
+
+// normalizeAliasReleaseGroup lowercases the release group, aliases are looked up case-insensitively by the scanner.
+func normalizeAliasReleaseGroup(group string) string {
+	return strings.ToLower(strings.TrimSpace(group))
+}
@@ -0,0 +1,42 @@
+package db
+
+import (
+	"seanime/internal/database/models"
+	"time"
+)
+
+// GetWatchLogEntries returns the entries that started in the time range, oldest first.
+// A zero time means no bound.
+func (db *Database) GetWatchLogEntries(from time.Time, to time.Time, mediaId int) ([]*models.WatchLogEntry, error) {
+	var res []*models.WatchLogEntry
+
+	query := db.gormdb.Model(&models.WatchLogEntry{})
+	if !from.IsZero() {
+		query = query.Where("started_at >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("started_at < ?", to)
+	}
+	if mediaId != 0 {
+		query = query.Where("media_id = ?", mediaId)
+	}
+
+	err := query.Order("started_at asc").Find(&res).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (db *Database) InsertWatchLogEntry(entry *models.WatchLogEntry) error {
+	return db.gormdb.Create(entry).Error
+}
+
+func (db *Database) UpdateWatchLogEntry(entry *models.WatchLogEntry) error {
+	return db.gormdb.Save(entry).Error
+}
+
+func (db *Database) DeleteWatchLogEntry(id uint) error {
+	return db.gormdb.Delete(&models.WatchLogEntry{}, id).Error
+}
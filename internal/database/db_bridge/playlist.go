@@ -7,6 +7,45 @@ import (
 	"seanime/internal/library/anime"
 )
 
+// playlistValue is the value of a models.PlaylistEntry that contains items.
+// Playlists that only contain local files are stored as an array of local files.
+type playlistValue struct {
+	LocalFiles []*anime.LocalFile    `json:"localFiles"`
+	Items      []*anime.PlaylistItem `json:"items"`
+}
+
+func marshalPlaylist(playlist *anime.Playlist) ([]byte, error) {
+	if len(playlist.Items) == 0 {
+		return json.Marshal(playlist.LocalFiles)
+	}
+	return json.Marshal(&playlistValue{
+		LocalFiles: playlist.LocalFiles,
+		Items:      playlist.Items,
+	})
+}
+
+func unmarshalPlaylist(entry *models.PlaylistEntry) (*anime.Playlist, error) {
+	playlist := anime.NewPlaylist(entry.Name)
+	playlist.DbId = entry.ID
+
+	var localFiles []*anime.LocalFile
+	if err := json.Unmarshal(entry.Value, &localFiles); err == nil {
+		playlist.SetLocalFiles(localFiles)
+		return playlist, nil
+	}
+
+	var value playlistValue
+	if err := json.Unmarshal(entry.Value, &value); err != nil {
+		return nil, err
+	}
+	if value.LocalFiles != nil {
+		playlist.SetLocalFiles(value.LocalFiles)
+	}
+	playlist.Items = value.Items
+
+	return playlist, nil
+}
+
 func GetPlaylists(db *db.Database) ([]*anime.Playlist, error) {
 	var res []*models.PlaylistEntry
 	err := db.Gorm().Find(&res).Error
@@ -16,11 +55,7 @@ func GetPlaylists(db *db.Database) ([]*anime.Playlist, error) {
 
 	playlists := make([]*anime.Playlist, 0)
 	for _, p := range res {
-		var localFiles []*anime.LocalFile
-		if err := json.Unmarshal(p.Value, &localFiles); err == nil {
-			playlist := anime.NewPlaylist(p.Name)
-			playlist.SetLocalFiles(localFiles)
-			playlist.DbId = p.ID
+		if playlist, err := unmarshalPlaylist(p); err == nil {
 			playlists = append(playlists, playlist)
 		}
 	}
@@ -28,7 +63,7 @@ func GetPlaylists(db *db.Database) ([]*anime.Playlist, error) {
 }
 
 func SavePlaylist(db *db.Database, playlist *anime.Playlist) error {
-	data, err := json.Marshal(playlist.LocalFiles)
+	data, err := marshalPlaylist(playlist)
 	if err != nil {
 		return err
 	}
@@ -45,7 +80,7 @@ func DeletePlaylist(db *db.Database, id uint) error {
 }
 
 func UpdatePlaylist(db *db.Database, playlist *anime.Playlist) error {
-	data, err := json.Marshal(playlist.LocalFiles)
+	data, err := marshalPlaylist(playlist)
 	if err != nil {
 		return err
 	}
@@ -69,14 +104,5 @@ func GetPlaylist(db *db.Database, id uint) (*anime.Playlist, error) {
 		return nil, err
 	}
 
-	var localFiles []*anime.LocalFile
-	if err := json.Unmarshal(playlistEntry.Value, &localFiles); err != nil {
-		return nil, err
-	}
-
-	playlist := anime.NewPlaylist(playlistEntry.Name)
-	playlist.SetLocalFiles(localFiles)
-	playlist.DbId = playlistEntry.ID
-
-	return playlist, nil
+	return unmarshalPlaylist(playlistEntry)
 }
@@ -2,6 +2,7 @@ package models
 
 import (
 	"database/sql/driver"
+	"encoding/json"
 	"errors"
 	"strings"
 	"time"
@@ -85,6 +86,31 @@ type LibrarySettings struct {
 	ScannerMatchingAlgorithm string  `gorm:"column:scanner_matching_algorithm" json:"scannerMatchingAlgorithm"`
 	// Flag to track whether getting started screen has been shown
 	CompletedGettingStarted bool     `gorm:"column:completed_getting_started" json:"completedGettingStarted"`
+	// v2.9+
+	// ID of the metadata provider extension used as the primary source, empty to use the default source
+	PrimaryMetadataProvider string `gorm:"column:primary_metadata_provider" json:"primaryMetadataProvider"`
+	// IDs of the metadata provider extensions used to fill in missing data, in order of priority
+	FallbackMetadataProviders StringSlice `gorm:"column:fallback_metadata_providers;type:text" json:"fallbackMetadataProviders"`
+	// IDs of the online streaming providers tried when the selected provider fails
+	OnlinestreamFallbackProviders StringSlice `gorm:"column:onlinestream_fallback_providers;type:text" json:"onlinestreamFallbackProviders"`
+	// Identify files by their ED2K hash before matching
+	ScannerUseFileHashes bool `gorm:"column:scanner_use_file_hashes" json:"scannerUseFileHashes"`
+	// AniDB-compatible endpoint used to look up file hashes
+	ScannerFileLookupUrl string `gorm:"column:scanner_file_lookup_url" json:"scannerFileLookupUrl"`
+	// Preferences used to pick between multiple versions of the same episode, in order of priority
+	PreferredResolutions   StringSlice `gorm:"column:preferred_resolutions;type:text" json:"preferredResolutions"`
+	PreferredReleaseGroups StringSlice `gorm:"column:preferred_release_groups;type:text" json:"preferredReleaseGroups"`
+	PreferredCodecs        StringSlice `gorm:"column:preferred_codecs;type:text" json:"preferredCodecs"`
+	PreferLargerFiles      bool        `gorm:"column:prefer_larger_files" json:"preferLargerFiles"`
+	// Identify files using the AniList IDs of their NFO files before matching
+	ScannerUseNFOFiles bool `gorm:"column:scanner_use_nfo_files" json:"scannerUseNFOFiles"`
+	// Write NFO files and artwork after each scan
+	ExportNFOFiles bool `gorm:"column:export_nfo_files" json:"exportNFOFiles"`
+	// Directory where NFO files are written instead of next to the media files
+	NFOMirrorDir      string `gorm:"column:nfo_mirror_dir" json:"nfoMirrorDir"`
+	NFODownloadImages bool   `gorm:"column:nfo_download_images" json:"nfoDownloadImages"`
+	// Stops recording watch sessions in the watch log
+	DisableWatchLog bool `gorm:"column:disable_watch_log" json:"disableWatchLog"`
 }
 
 func (o *LibrarySettings) GetLibraryPaths() (ret []string) {
@@ -113,9 +139,50 @@ func (o LibraryPaths) Value() (driver.Value, error) {
 	return strings.Join(o, ","), nil
 }
 
+// StringSlice is a list of strings stored as a comma-separated value.
+type StringSlice []string
+
+func (o *StringSlice) Scan(src interface{}) error {
+	if src == nil {
+		*o = StringSlice{}
+		return nil
+	}
+	str, ok := src.(string)
+	if !ok {
+		return errors.New("src value cannot cast to string")
+	}
+	if str == "" {
+		*o = StringSlice{}
+		return nil
+	}
+	*o = strings.Split(str, ",")
+	return nil
+}
+func (o StringSlice) Value() (driver.Value, error) {
+	if len(o) == 0 {
+		return nil, nil
+	}
+	return strings.Join(o, ","), nil
+}
+
 type MangaSettings struct {
 	DefaultProvider    string `gorm:"column:default_manga_provider" json:"defaultMangaProvider"`
 	AutoUpdateProgress bool   `gorm:"column:manga_auto_update_progress" json:"mangaAutoUpdateProgress"`
+	// Interval in minutes between checks for new chapters, 0 disables the checks
+	ChapterUpdateCheckInterval int  `gorm:"column:manga_chapter_update_check_interval" json:"mangaChapterUpdateCheckInterval"`
+	AutoDownloadNewChapters    bool `gorm:"column:manga_auto_download_new_chapters" json:"mangaAutoDownloadNewChapters"`
+	// "Send to device" folder, e.g. a folder synced with an e-reader
+	ExportDir string `gorm:"column:manga_export_dir" json:"mangaExportDir"`
+	// Format of the exports written to ExportDir when a chapter is downloaded, "cbz" or "epub", empty disables automatic exports
+	AutoExportFormat string `gorm:"column:manga_auto_export_format" json:"mangaAutoExportFormat"`
+	// Page processing of automatic exports, 0 means no limit
+	ExportMaxWidth  int  `gorm:"column:manga_export_max_width" json:"mangaExportMaxWidth"`
+	ExportMaxHeight int  `gorm:"column:manga_export_max_height" json:"mangaExportMaxHeight"`
+	ExportGrayscale bool `gorm:"column:manga_export_grayscale" json:"mangaExportGrayscale"`
+	// Preferences of the merged chapter list, in order of preference
+	MergeProviders  StringSlice `gorm:"column:manga_merge_providers;type:text" json:"mangaMergeProviders"`
+	MergeScanlators StringSlice `gorm:"column:manga_merge_scanlators;type:text" json:"mangaMergeScanlators"`
+	MergeLanguages  StringSlice `gorm:"column:manga_merge_languages;type:text" json:"mangaMergeLanguages"`
 }
 
 type MediaPlayerSettings struct {
@@ -148,6 +215,18 @@ type TorrentSettings struct {
 	ShowActiveTorrentCount bool `gorm:"column:show_active_torrent_count" json:"showActiveTorrentCount"`
 	// v2.2+
 	HideTorrentList bool `gorm:"column:hide_torrent_list" json:"hideTorrentList"`
+	// Deluge (Web UI)
+	DelugeHost     string `gorm:"column:deluge_host" json:"delugeHost"`
+	DelugePort     int    `gorm:"column:deluge_port" json:"delugePort"`
+	DelugePassword string `gorm:"column:deluge_password" json:"delugePassword"`
+	// rTorrent (XML-RPC over HTTP or SCGI)
+	RTorrentURL      string `gorm:"column:rtorrent_url" json:"rtorrentUrl"`
+	RTorrentUsername string `gorm:"column:rtorrent_username" json:"rtorrentUsername"`
+	RTorrentPassword string `gorm:"column:rtorrent_password" json:"rtorrentPassword"`
+	// aria2 (JSON-RPC)
+	Aria2Host   string `gorm:"column:aria2_host" json:"aria2Host"`
+	Aria2Port   int    `gorm:"column:aria2_port" json:"aria2Port"`
+	Aria2Secret string `gorm:"column:aria2_secret" json:"aria2Secret"`
 }
 
 type ListSyncSettings struct {
@@ -168,6 +247,7 @@ type NotificationSettings struct {
 	DisableNotifications               bool `gorm:"column:disable_notifications" json:"disableNotifications"`
 	DisableAutoDownloaderNotifications bool `gorm:"column:disable_auto_downloader_notifications" json:"disableAutoDownloaderNotifications"`
 	DisableAutoScannerNotifications    bool `gorm:"column:disable_auto_scanner_notifications" json:"disableAutoScannerNotifications"`
+	DisableMangaUpdateNotifications    bool `gorm:"column:disable_manga_update_notifications" json:"disableMangaUpdateNotifications"`
 }
 
 // +---------------------+
@@ -220,6 +300,49 @@ type AutoDownloaderSettings struct {
 	EnableEnhancedQueries bool   `gorm:"column:auto_downloader_enable_enhanced_queries" json:"enableEnhancedQueries"`
 	EnableSeasonCheck     bool   `gorm:"column:auto_downloader_enable_season_check" json:"enableSeasonCheck"`
 	UseDebrid             bool   `gorm:"column:auto_downloader_use_debrid" json:"useDebrid"`
+	// v2.9+
+	// When enabled and qBittorrent is the torrent client, the rules are delegated to qBittorrent's RSS downloader
+	UseQbittorrentRss bool `gorm:"column:auto_downloader_use_qbittorrent_rss" json:"useQbittorrentRss"`
+}
+
+// +---------------------+
+// |    Title aliases    |
+// +---------------------+
+
+// TitleAlias maps a parsed title to a media.
+// Aliases are learned from manual matches and are consulted before matching.
+type TitleAlias struct {
+	BaseModel
+	Title         string `gorm:"column:title;index" json:"title"`          // Normalized parsed title
+	ReleaseGroup  string `gorm:"column:release_group" json:"releaseGroup"` // Empty to match any release group
+	Season        int    `gorm:"column:season" json:"season"`              // 0 to match any season
+	MediaId       int    `gorm:"column:media_id" json:"mediaId"`
+	EpisodeOffset int    `gorm:"column:episode_offset" json:"episodeOffset"` // Added to the parsed episode number
+}
+
+// +---------------------+
+// |      Watch log      |
+// +---------------------+
+
+// WatchLogEntry is a viewing session of an episode.
+// Unlike the continuity watch history, entries are never trimmed.
+type WatchLogEntry struct {
+	BaseModel
+	MediaId       int       `gorm:"column:media_id;index" json:"mediaId"`
+	EpisodeNumber int       `gorm:"column:episode_number" json:"episodeNumber"`
+	Kind          string    `gorm:"column:kind" json:"kind"` // e.g. "external_player", "mediastream", "torrentstream"
+	Filepath      string    `gorm:"column:filepath" json:"filepath,omitempty"`
+	StartedAt     time.Time `gorm:"column:started_at;index" json:"startedAt"`
+	EndedAt       time.Time `gorm:"column:ended_at" json:"endedAt"`
+	// Last known position in seconds
+	Position float64 `gorm:"column:position" json:"position"`
+	Duration float64 `gorm:"column:duration" json:"duration"`
+	// Time spent watching in seconds, seeking is not counted
+	WatchedSeconds float64 `gorm:"column:watched_seconds" json:"watchedSeconds"`
+	Completed      bool    `gorm:"column:completed" json:"completed"`
+	// Saved with the entry so that statistics can be computed offline
+	Genres  StringSlice `gorm:"column:genres;type:text" json:"genres"`
+	Studios StringSlice `gorm:"column:studios;type:text" json:"studios"`
 }
 
 // +---------------------+
@@ -355,6 +478,11 @@ type TorrentstreamSettings struct {
 	StreamUrlAddress string `gorm:"column:stream_url_address" json:"streamUrlAddress"`
 	// v2.7+
 	SlowSeeding bool `gorm:"column:slow_seeding" json:"slowSeeding"`
+	// Maximum number of streams that can run at the same time, 0 uses the default
+	MaxConcurrentSessions int `gorm:"column:max_concurrent_sessions" json:"maxConcurrentSessions"`
+	// Bandwidth caps shared by all streams in KiB/s, 0 means unlimited
+	DownloadRateLimit int `gorm:"column:download_rate_limit" json:"downloadRateLimit"`
+	UploadRateLimit   int `gorm:"column:upload_rate_limit" json:"uploadRateLimit"`
 }
 
 type TorrentstreamHistory struct {
@@ -387,6 +515,28 @@ type MangaMapping struct {
 	MangaID  string `gorm:"column:manga_id" json:"mangaId"` // ID from search result, used to fetch chapters
 }
 
+// MangaChapterUpdate is a chapter that appeared since the last time the chapters of the manga were checked.
+type MangaChapterUpdate struct {
+	BaseModel
+	MediaId       int       `gorm:"column:media_id;index" json:"mediaId"`
+	Provider      string    `gorm:"column:provider" json:"provider"`
+	ChapterId     string    `gorm:"column:chapter_id" json:"chapterId"`
+	ChapterNumber string    `gorm:"column:chapter_number" json:"chapterNumber"`
+	Title         string    `gorm:"column:title" json:"title"`
+	DetectedAt    time.Time `gorm:"column:detected_at;index" json:"detectedAt"`
+	// Dismissed updates are hidden from the unread feed
+	Dismissed bool `gorm:"column:dismissed" json:"dismissed"`
+}
+
+// MangaKnownChapters is the chapter list of a manga found during the last chapter update check.
+// New chapters are found by comparing the provider's chapter list against it.
+type MangaKnownChapters struct {
+	BaseModel
+	Provider string `gorm:"column:provider;index" json:"provider"`
+	MediaId  int    `gorm:"column:media_id;index" json:"mediaId"`
+	Chapters []byte `gorm:"column:chapters" json:"chapters"` // JSON array of db.MangaKnownChapter
+}
+
 type MangaChapterContainer struct {
 	BaseModel
 	Provider  string `gorm:"column:provider" json:"provider"`
@@ -406,6 +556,17 @@ type OnlinestreamMapping struct {
 	AnimeID  string `gorm:"column:anime_id" json:"anime_id"` // ID from search result, used to fetch episodes
 }
 
+type OnlinestreamDownloadQueueItem struct {
+	BaseModel
+	Provider      string `gorm:"column:provider" json:"provider"`
+	MediaID       int    `gorm:"column:media_id" json:"mediaId"`
+	EpisodeNumber int    `gorm:"column:episode_number" json:"episodeNumber"`
+	Dubbed        bool   `gorm:"column:dubbed" json:"dubbed"`
+	Title         string `gorm:"column:title" json:"title"`            // Media title, used to name the file
+	SourceData    []byte `gorm:"column:source_data" json:"sourceData"` // Contains the video source and subtitles
+	Status        string `gorm:"column:status" json:"status"`
+}
+
 // +---------------------+
 // |       Debrid        |
 // +---------------------+
@@ -419,6 +580,48 @@ type DebridSettings struct {
 	IncludeDebridStreamInLibrary bool   `gorm:"column:include_debrid_stream_in_library" json:"includeDebridStreamInLibrary"`
 	StreamAutoSelect             bool   `gorm:"column:stream_auto_select" json:"streamAutoSelect"`
 	StreamPreferredResolution    string `gorm:"column:stream_preferred_resolution" json:"streamPreferredResolution"`
+	// Providers used in addition to the main provider, for the torrents that are cached on them
+	AdditionalProviders DebridProviderList `gorm:"column:additional_providers;type:text" json:"additionalProviders"`
+	// IDs of the providers in the order they are checked for instant availability
+	ProviderPriority StringSlice `gorm:"column:provider_priority;type:text" json:"providerPriority"`
+}
+
+type DebridProviderSettings struct {
+	Provider string `json:"provider"`
+	ApiKey   string `json:"apiKey"`
+}
+
+// DebridProviderList is a list of debrid providers stored as JSON.
+type DebridProviderList []*DebridProviderSettings
+
+func (o *DebridProviderList) Scan(src interface{}) error {
+	var data []byte
+	switch src := src.(type) {
+	case nil:
+		*o = DebridProviderList{}
+		return nil
+	case string:
+		data = []byte(src)
+	case []byte:
+		data = src
+	default:
+		return errors.New("src value cannot cast to string")
+	}
+	if len(data) == 0 {
+		*o = DebridProviderList{}
+		return nil
+	}
+	return json.Unmarshal(data, o)
+}
+func (o DebridProviderList) Value() (driver.Value, error) {
+	if len(o) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
 }
 
 type DebridTorrentItem struct {
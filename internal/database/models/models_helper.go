@@ -73,6 +73,9 @@ func (s *Settings) GetSensitiveValues() []string {
 		s.GetMediaPlayer().VlcPassword,
 		s.GetTorrent().QBittorrentPassword,
 		s.GetTorrent().TransmissionPassword,
+		s.GetTorrent().DelugePassword,
+		s.GetTorrent().RTorrentPassword,
+		s.GetTorrent().Aria2Secret,
 	}
 }
 
@@ -80,7 +83,13 @@ func (s *DebridSettings) GetSensitiveValues() []string {
 	if s == nil {
 		return []string{}
 	}
-	return []string{
+	ret := []string{
 		s.ApiKey,
 	}
+	for _, p := range s.AdditionalProviders {
+		if p != nil {
+			ret = append(ret, p.ApiKey)
+		}
+	}
+	return ret
 }
@@ -0,0 +1,632 @@
+package alldebrid
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"seanime/internal/debrid/debrid"
+	"seanime/internal/util"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/samber/mo"
+)
+
+const agent = "seanime"
+
+type (
+	AllDebrid struct {
+		baseUrl string
+		apiKey  mo.Option[string]
+		client  *http.Client
+		logger  *zerolog.Logger
+	}
+
+	Response struct {
+		Status string          `json:"status"` // "success" or "error"
+		Data   json.RawMessage `json:"data"`
+		Error  *ErrorResponse  `json:"error"`
+	}
+
+	ErrorResponse struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+
+	Magnet struct {
+		ID             int     `json:"id"`
+		Filename       string  `json:"filename"`
+		Size           int64   `json:"size"`
+		Hash           string  `json:"hash"`
+		Status         string  `json:"status"`
+		StatusCode     int     `json:"statusCode"`
+		Downloaded     int64   `json:"downloaded"`
+		Uploaded       int64   `json:"uploaded"`
+		Seeders        int     `json:"seeders"`
+		DownloadSpeed  int64   `json:"downloadSpeed"`
+		UploadSpeed    int64   `json:"uploadSpeed"`
+		UploadDate     int64   `json:"uploadDate"`
+		CompletionDate int64   `json:"completionDate"`
+		Links          []*Link `json:"links"`
+	}
+
+	// Link is a file of a magnet, available once the magnet is ready.
+	Link struct {
+		Link     string `json:"link"` // Locked link, must be unlocked to get the download URL
+		Filename string `json:"filename"`
+		Size     int64  `json:"size"`
+	}
+
+	// File is a node of the file tree returned by the instant availability endpoint.
+	File struct {
+		Name    string  `json:"n"`
+		Size    int64   `json:"s"`
+		Entries []*File `json:"e"` // Set if the node is a folder
+	}
+
+	InstantAvailabilityItem struct {
+		Magnet  string  `json:"magnet"`
+		Hash    string  `json:"hash"`
+		Instant bool    `json:"instant"`
+		Files   []*File `json:"files"`
+	}
+
+	UploadedMagnet struct {
+		Magnet string         `json:"magnet"`
+		Hash   string         `json:"hash"`
+		Name   string         `json:"name"`
+		Size   int64          `json:"size"`
+		Ready  bool           `json:"ready"`
+		ID     int            `json:"id"`
+		Error  *ErrorResponse `json:"error"`
+	}
+
+	UnlockedLink struct {
+		Link     string `json:"link"`
+		Filename string `json:"filename"`
+		Filesize int64  `json:"filesize"`
+	}
+)
+
+// Magnet status codes, see https://docs.alldebrid.com/#status
+const (
+	StatusCodeInQueue     = 0
+	StatusCodeDownloading = 1
+	StatusCodeCompressing = 2
+	StatusCodeUploading   = 3
+	StatusCodeReady       = 4
+	// Status codes 5 and above are errors
+)
+
+func NewAllDebrid(logger *zerolog.Logger) debrid.Provider {
+	return &AllDebrid{
+		baseUrl: "https://api.alldebrid.com/v4",
+		apiKey:  mo.None[string](),
+		client: &http.Client{
+			Timeout: time.Second * 30,
+		},
+		logger: logger,
+	}
+}
+
+func (t *AllDebrid) GetSettings() debrid.Settings {
+	return debrid.Settings{
+		ID:   "alldebrid",
+		Name: "AllDebrid",
+	}
+}
+
+// doQuery sends a request to the API and returns the "data" field of the response.
+// Parameters are sent in the query string for GET requests and as a form otherwise.
+func (t *AllDebrid) doQuery(method, path string, params url.Values) (ret json.RawMessage, err error) {
+	apiKey, found := t.apiKey.Get()
+	if !found {
+		return nil, debrid.ErrNotAuthenticated
+	}
+
+	if params == nil {
+		params = url.Values{}
+	}
+	params.Set("agent", agent)
+
+	var req *http.Request
+	if method == http.MethodGet {
+		req, err = http.NewRequest(method, t.baseUrl+path+"?"+params.Encode(), nil)
+	} else {
+		req, err = http.NewRequest(method, t.baseUrl+path, strings.NewReader(params.Encode()))
+		if req != nil {
+			req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Authorization", "Bearer "+apiKey)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var r Response
+	if err := json.Unmarshal(content, &r); err != nil {
+		t.logger.Error().Err(err).Msg("alldebrid: Failed to decode response")
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if r.Status != "success" {
+		if r.Error != nil {
+			return nil, fmt.Errorf("failed to query API: %s, %s", r.Error.Code, r.Error.Message)
+		}
+		return nil, fmt.Errorf("failed to query API: %s", resp.Status)
+	}
+
+	return r.Data, nil
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (t *AllDebrid) Authenticate(apiKey string) error {
+	t.apiKey = mo.Some(apiKey)
+	return nil
+}
+
+func (t *AllDebrid) GetInstantAvailability(hashes []string) map[string]debrid.TorrentItemInstantAvailability {
+
+	t.logger.Trace().Strs("hashes", hashes).Msg("alldebrid: Checking instant availability")
+
+	availability := make(map[string]debrid.TorrentItemInstantAvailability)
+
+	if len(hashes) == 0 {
+		return availability
+	}
+
+	for i := 0; i < len(hashes); i += 100 {
+		end := min(i+100, len(hashes))
+
+		params := url.Values{}
+		for _, hash := range hashes[i:end] {
+			params.Add("magnets[]", hash)
+		}
+
+		resp, err := t.doQuery(http.MethodGet, "/magnet/instant", params)
+		if err != nil {
+			t.logger.Error().Err(err).Msg("alldebrid: Failed to get instant availability")
+			return availability
+		}
+
+		var data struct {
+			Magnets []*InstantAvailabilityItem `json:"magnets"`
+		}
+		if err := json.Unmarshal(resp, &data); err != nil {
+			t.logger.Error().Err(err).Msg("alldebrid: Failed to parse instant availability")
+			return availability
+		}
+
+		for _, item := range data.Magnets {
+			if !item.Instant {
+				continue
+			}
+
+			// Use the hash that was requested
+			hash := item.Hash
+			for _, h := range hashes[i:end] {
+				if strings.EqualFold(h, item.Hash) || strings.EqualFold(h, item.Magnet) {
+					hash = h
+					break
+				}
+			}
+
+			avail := debrid.TorrentItemInstantAvailability{
+				CachedFiles: make(map[string]*debrid.CachedFile),
+			}
+			for idx, f := range flattenFiles(item.Files) {
+				avail.CachedFiles[strconv.Itoa(idx)] = &debrid.CachedFile{
+					Name: f.Name,
+					Size: f.Size,
+				}
+			}
+			availability[hash] = avail
+		}
+	}
+
+	return availability
+}
+
+// flattenFiles returns the files of the tree, depth-first.
+func flattenFiles(files []*File) (ret []*File) {
+	for _, f := range files {
+		if len(f.Entries) > 0 {
+			ret = append(ret, flattenFiles(f.Entries)...)
+			continue
+		}
+		ret = append(ret, f)
+	}
+	return
+}
+
+func (t *AllDebrid) AddTorrent(opts debrid.AddTorrentOptions) (string, error) {
+
+	// Check if the torrent is already added
+	if opts.InfoHash != "" {
+		magnets, err := t.getMagnets()
+		if err == nil {
+			for _, m := range magnets {
+				if strings.EqualFold(m.Hash, opts.InfoHash) {
+					t.logger.Debug().Int("torrentId", m.ID).Msg("alldebrid: Torrent already added")
+					return strconv.Itoa(m.ID), nil
+				}
+			}
+		}
+	}
+
+	uploaded, err := t.uploadMagnet(opts.MagnetLink)
+	if err != nil {
+		return "", err
+	}
+
+	t.logger.Debug().Int("torrentId", uploaded.ID).Str("torrentName", uploaded.Name).Str("torrentHash", uploaded.Hash).Msg("alldebrid: Torrent added")
+
+	return strconv.Itoa(uploaded.ID), nil
+}
+
+// GetTorrentStreamUrl blocks until the torrent is downloaded and returns the stream URL for the torrent file.
+func (t *AllDebrid) GetTorrentStreamUrl(ctx context.Context, opts debrid.StreamTorrentOptions, itemCh chan debrid.TorrentItem) (streamUrl string, err error) {
+
+	t.logger.Trace().Str("torrentId", opts.ID).Str("fileId", opts.FileId).Msg("alldebrid: Retrieving stream link")
+
+	doneCh := make(chan struct{})
+
+	go func(ctx context.Context) {
+		defer func() {
+			close(doneCh)
+		}()
+		for {
+			select {
+			case <-ctx.Done():
+				err = ctx.Err()
+				return
+			case <-time.After(4 * time.Second):
+				magnet, _err := t.getMagnet(opts.ID)
+				if _err != nil {
+					t.logger.Error().Err(_err).Msg("alldebrid: Failed to get torrent")
+					err = fmt.Errorf("alldebrid: Failed to get torrent: %w", _err)
+					return
+				}
+
+				dt := toDebridTorrent(magnet)
+				itemCh <- *dt
+
+				if dt.Status == debrid.TorrentItemStatusError {
+					err = fmt.Errorf("alldebrid: Torrent failed, %s", magnet.Status)
+					return
+				}
+
+				// Check if the torrent is ready
+				if dt.IsReady {
+					link, _err := getLink(magnet, opts.FileId)
+					if _err != nil {
+						err = _err
+						return
+					}
+
+					unlocked, _err := t.unlockLink(link.Link)
+					if _err != nil {
+						t.logger.Error().Err(_err).Msg("alldebrid: Failed to get download URL")
+						err = _err
+						return
+					}
+
+					streamUrl = unlocked.Link
+					return
+				}
+			}
+		}
+	}(ctx)
+
+	<-doneCh
+
+	return
+}
+
+// GetTorrentDownloadUrl returns the download URL of the file, or the comma-separated URLs of all files if no file is specified.
+func (t *AllDebrid) GetTorrentDownloadUrl(opts debrid.DownloadTorrentOptions) (downloadUrl string, err error) {
+
+	t.logger.Trace().Str("torrentId", opts.ID).Msg("alldebrid: Retrieving download link")
+
+	magnet, err := t.getMagnet(opts.ID)
+	if err != nil {
+		return "", fmt.Errorf("alldebrid: Failed to get download URL: %w", err)
+	}
+
+	if magnet.StatusCode != StatusCodeReady {
+		return "", fmt.Errorf("alldebrid: Torrent is not ready")
+	}
+
+	if opts.FileId != "" {
+		link, err := getLink(magnet, opts.FileId)
+		if err != nil {
+			return "", err
+		}
+		unlocked, err := t.unlockLink(link.Link)
+		if err != nil {
+			return "", fmt.Errorf("alldebrid: Failed to get download URL: %w", err)
+		}
+		return unlocked.Link, nil
+	}
+
+	urls := make([]string, 0, len(magnet.Links))
+	for _, link := range magnet.Links {
+		unlocked, err := t.unlockLink(link.Link)
+		if err != nil {
+			return "", fmt.Errorf("alldebrid: Failed to get download URL: %w", err)
+		}
+		urls = append(urls, unlocked.Link)
+	}
+
+	return strings.Join(urls, ","), nil
+}
+
+// getLink returns the link of the file, the file ID is its index.
+func getLink(magnet *Magnet, fileId string) (*Link, error) {
+	idx := 0
+	if fileId != "" {
+		var err error
+		idx, err = strconv.Atoi(fileId)
+		if err != nil {
+			return nil, fmt.Errorf("alldebrid: Invalid file ID")
+		}
+	}
+	if idx < 0 || idx >= len(magnet.Links) {
+		return nil, fmt.Errorf("alldebrid: File not found")
+	}
+	return magnet.Links[idx], nil
+}
+
+func (t *AllDebrid) GetTorrent(id string) (ret *debrid.TorrentItem, err error) {
+	magnet, err := t.getMagnet(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toDebridTorrent(magnet), nil
+}
+
+// GetTorrentInfo adds the torrent to the user's account to retrieve its files, then removes it.
+// AllDebrid only lists the files of ready torrents, so this fails if the torrent is not cached.
+func (t *AllDebrid) GetTorrentInfo(opts debrid.GetTorrentInfoOptions) (ret *debrid.TorrentInfo, err error) {
+
+	if opts.MagnetLink == "" {
+		return nil, fmt.Errorf("alldebrid: Magnet link is required")
+	}
+
+	uploaded, err := t.uploadMagnet(opts.MagnetLink)
+	if err != nil {
+		return nil, fmt.Errorf("alldebrid: Failed to get info: %w", err)
+	}
+
+	magnet, err := t.getMagnet(strconv.Itoa(uploaded.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		// Remove the torrent
+		err := t.DeleteTorrent(strconv.Itoa(magnet.ID))
+		if err != nil {
+			t.logger.Error().Err(err).Msg("alldebrid: Failed to delete torrent")
+		}
+	}()
+
+	if len(magnet.Links) == 0 {
+		return nil, fmt.Errorf("alldebrid: Torrent is not cached")
+	}
+
+	ret = toDebridTorrentInfo(magnet)
+
+	return ret, nil
+}
+
+func (t *AllDebrid) GetTorrents() (ret []*debrid.TorrentItem, err error) {
+
+	magnets, err := t.getMagnets()
+	if err != nil {
+		return nil, fmt.Errorf("alldebrid: Failed to get torrents: %w", err)
+	}
+
+	// Limit the number of torrents to 500
+	if len(magnets) > 500 {
+		magnets = magnets[:500]
+	}
+
+	for _, m := range magnets {
+		ret = append(ret, toDebridTorrent(m))
+	}
+
+	slices.SortFunc(ret, func(i, j *debrid.TorrentItem) int {
+		return cmp.Compare(j.AddedAt, i.AddedAt)
+	})
+
+	return ret, nil
+}
+
+func (t *AllDebrid) DeleteTorrent(id string) error {
+
+	_, err := t.doQuery(http.MethodGet, "/magnet/delete", url.Values{"id": {id}})
+	if err != nil {
+		t.logger.Error().Err(err).Msg("alldebrid: Failed to delete torrent")
+		return fmt.Errorf("alldebrid: Failed to delete torrent: %w", err)
+	}
+
+	return nil
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (t *AllDebrid) uploadMagnet(magnet string) (ret *UploadedMagnet, err error) {
+
+	t.logger.Trace().Str("magnetLink", magnet).Msg("alldebrid: Adding torrent")
+
+	resp, err := t.doQuery(http.MethodPost, "/magnet/upload", url.Values{"magnets[]": {magnet}})
+	if err != nil {
+		t.logger.Error().Err(err).Msg("alldebrid: Failed to add torrent")
+		return nil, fmt.Errorf("alldebrid: Failed to add torrent: %w", err)
+	}
+
+	var data struct {
+		Magnets []*UploadedMagnet `json:"magnets"`
+	}
+	if err := json.Unmarshal(resp, &data); err != nil {
+		return nil, fmt.Errorf("alldebrid: Failed to parse torrent: %w", err)
+	}
+
+	if len(data.Magnets) == 0 {
+		return nil, fmt.Errorf("alldebrid: Failed to add torrent")
+	}
+	if data.Magnets[0].Error != nil {
+		return nil, fmt.Errorf("alldebrid: Failed to add torrent: %s", data.Magnets[0].Error.Message)
+	}
+
+	return data.Magnets[0], nil
+}
+
+func (t *AllDebrid) unlockLink(link string) (ret *UnlockedLink, err error) {
+
+	resp, err := t.doQuery(http.MethodGet, "/link/unlock", url.Values{"link": {link}})
+	if err != nil {
+		t.logger.Error().Err(err).Msg("alldebrid: Failed to unlock link")
+		return nil, fmt.Errorf("alldebrid: Failed to unlock link: %w", err)
+	}
+
+	if err := json.Unmarshal(resp, &ret); err != nil {
+		return nil, fmt.Errorf("alldebrid: Failed to parse unlocked link: %w", err)
+	}
+
+	return ret, nil
+}
+
+func (t *AllDebrid) getMagnets() (ret []*Magnet, err error) {
+
+	resp, err := t.doQuery(http.MethodGet, "/magnet/status", nil)
+	if err != nil {
+		return nil, fmt.Errorf("alldebrid: Failed to get torrents: %w", err)
+	}
+
+	var data struct {
+		Magnets []*Magnet `json:"magnets"`
+	}
+	if err := json.Unmarshal(resp, &data); err != nil {
+		t.logger.Error().Err(err).Msg("alldebrid: Failed to parse torrents")
+		return nil, fmt.Errorf("alldebrid: Failed to parse torrents: %w", err)
+	}
+
+	return data.Magnets, nil
+}
+
+func (t *AllDebrid) getMagnet(id string) (ret *Magnet, err error) {
+
+	resp, err := t.doQuery(http.MethodGet, "/magnet/status", url.Values{"id": {id}})
+	if err != nil {
+		return nil, fmt.Errorf("alldebrid: Failed to get torrent: %w", err)
+	}
+
+	// DEVNOTE: "magnets" is an object when an ID is given
+	var data struct {
+		Magnets *Magnet `json:"magnets"`
+	}
+	if err := json.Unmarshal(resp, &data); err != nil {
+		return nil, fmt.Errorf("alldebrid: Failed to parse torrent: %w", err)
+	}
+	if data.Magnets == nil {
+		return nil, fmt.Errorf("alldebrid: Torrent not found")
+	}
+
+	return data.Magnets, nil
+}
+
+func toDebridTorrent(m *Magnet) (ret *debrid.TorrentItem) {
+
+	status := toDebridTorrentStatus(m)
+
+	completionPercentage := 0
+	if m.Size > 0 {
+		completionPercentage = int(m.Downloaded * 100 / m.Size)
+	}
+	if status == debrid.TorrentItemStatusCompleted {
+		completionPercentage = 100
+	}
+
+	eta := ""
+	if m.DownloadSpeed > 0 && m.Size > m.Downloaded {
+		eta = util.FormatETA(int((m.Size - m.Downloaded) / m.DownloadSpeed))
+	}
+
+	ret = &debrid.TorrentItem{
+		ID:                   strconv.Itoa(m.ID),
+		Name:                 m.Filename,
+		Hash:                 m.Hash,
+		Size:                 m.Size,
+		FormattedSize:        util.Bytes(uint64(m.Size)),
+		CompletionPercentage: completionPercentage,
+		ETA:                  eta,
+		Status:               status,
+		AddedAt:              time.Unix(m.UploadDate, 0).UTC().Format(time.RFC3339),
+		Speed:                util.ToHumanReadableSpeed(int(m.DownloadSpeed)),
+		Seeders:              m.Seeders,
+		IsReady:              status == debrid.TorrentItemStatusCompleted,
+	}
+
+	return
+}
+
+func toDebridTorrentInfo(m *Magnet) (ret *debrid.TorrentInfo) {
+
+	var files []*debrid.TorrentItemFile
+	for idx, l := range m.Links {
+		files = append(files, &debrid.TorrentItemFile{
+			ID:    strconv.Itoa(idx),
+			Index: idx,
+			Name:  l.Filename,       // e.g. "Big Buck Bunny.mp4"
+			Path:  "/" + l.Filename, // e.g. "/Big Buck Bunny.mp4"
+			Size:  l.Size,
+		})
+	}
+
+	id := strconv.Itoa(m.ID)
+
+	ret = &debrid.TorrentInfo{
+		ID:    &id,
+		Name:  m.Filename,
+		Hash:  m.Hash,
+		Size:  m.Size,
+		Files: files,
+	}
+
+	return
+}
+
+func toDebridTorrentStatus(m *Magnet) debrid.TorrentItemStatus {
+	switch m.StatusCode {
+	case StatusCodeInQueue:
+		return debrid.TorrentItemStatusStalled
+	case StatusCodeDownloading, StatusCodeCompressing, StatusCodeUploading:
+		return debrid.TorrentItemStatusDownloading
+	case StatusCodeReady:
+		return debrid.TorrentItemStatusCompleted
+	default:
+		return debrid.TorrentItemStatusError
+	}
+}
@@ -0,0 +1,271 @@
+package alldebrid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"seanime/internal/debrid/debrid"
+	"seanime/internal/util"
+	"testing"
+	"time"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+)
+
+// Responses recorded from the AllDebrid API, with identifying values replaced
+var recordedResponses = map[string]string{
+	"/magnet/instant": `{
+  "status": "success",
+  "data": {
+    "magnets": [
+      {
+        "magnet": "80431b4f9a12f4e06616062d3d3973b9ef99b5e6",
+        "hash": "80431b4f9a12f4e06616062d3d3973b9ef99b5e6",
+        "instant": true,
+        "files": [
+          {"n": "[SubsPlease] Bocchi the Rock! - 01 (1080p) [E04F4EFB].mkv", "s": 1445813416}
+        ]
+      },
+      {
+        "magnet": "a1b2c3d4e5f60718293a4b5c6d7e8f9012345678",
+        "hash": "a1b2c3d4e5f60718293a4b5c6d7e8f9012345678",
+        "instant": true,
+        "files": [
+          {"n": "Show", "e": [
+            {"n": "Show - 01.mkv", "s": 1000},
+            {"n": "Show - 02.mkv", "s": 2000}
+          ]}
+        ]
+      },
+      {
+        "magnet": "0000000000000000000000000000000000000000",
+        "hash": "0000000000000000000000000000000000000000",
+        "instant": false
+      }
+    ]
+  }
+}`,
+	"/magnet/upload": `{
+  "status": "success",
+  "data": {
+    "magnets": [
+      {
+        "magnet": "magnet:?xt=urn:btih:80431b4f9a12f4e06616062d3d3973b9ef99b5e6",
+        "hash": "80431b4f9a12f4e06616062d3d3973b9ef99b5e6",
+        "name": "[SubsPlease] Bocchi the Rock! - 01 (1080p) [E04F4EFB].mkv",
+        "size": 1445813416,
+        "ready": true,
+        "id": 251289046
+      }
+    ]
+  }
+}`,
+	"/magnet/status": `{
+  "status": "success",
+  "data": {
+    "magnets": [
+      {
+        "id": 251289046,
+        "filename": "[SubsPlease] Bocchi the Rock! - 01 (1080p) [E04F4EFB].mkv",
+        "size": 1445813416,
+        "hash": "80431b4f9a12f4e06616062d3d3973b9ef99b5e6",
+        "status": "Ready",
+        "statusCode": 4,
+        "downloaded": 1445813416,
+        "uploaded": 1445813416,
+        "seeders": 0,
+        "downloadSpeed": 0,
+        "uploadSpeed": 0,
+        "uploadDate": 1718000000,
+        "completionDate": 1718000000,
+        "links": [
+          {"link": "https://alldebrid.com/f/AAAAAAAAAAAA", "filename": "[SubsPlease] Bocchi the Rock! - 01 (1080p) [E04F4EFB].mkv", "size": 1445813416}
+        ]
+      },
+      {
+        "id": 251289047,
+        "filename": "Show",
+        "size": 3000,
+        "hash": "a1b2c3d4e5f60718293a4b5c6d7e8f9012345678",
+        "status": "Downloading",
+        "statusCode": 1,
+        "downloaded": 1500,
+        "uploaded": 0,
+        "seeders": 12,
+        "downloadSpeed": 500,
+        "uploadSpeed": 0,
+        "uploadDate": 1718000100,
+        "completionDate": 0,
+        "links": []
+      }
+    ]
+  }
+}`,
+	"/magnet/status?id": `{
+  "status": "success",
+  "data": {
+    "magnets": {
+      "id": 251289046,
+      "filename": "[SubsPlease] Bocchi the Rock! - 01 (1080p) [E04F4EFB].mkv",
+      "size": 1445813416,
+      "hash": "80431b4f9a12f4e06616062d3d3973b9ef99b5e6",
+      "status": "Ready",
+      "statusCode": 4,
+      "downloaded": 1445813416,
+      "uploaded": 1445813416,
+      "seeders": 0,
+      "downloadSpeed": 0,
+      "uploadSpeed": 0,
+      "uploadDate": 1718000000,
+      "completionDate": 1718000000,
+      "links": [
+        {"link": "https://alldebrid.com/f/AAAAAAAAAAAA", "filename": "[SubsPlease] Bocchi the Rock! - 01 (1080p) [E04F4EFB].mkv", "size": 1445813416}
+      ]
+    }
+  }
+}`,
+	"/link/unlock": `{
+  "status": "success",
+  "data": {
+    "link": "https://abcd.debrid.it/dl/AAAAAAAAAAAA/Bocchi.mkv",
+    "filename": "[SubsPlease] Bocchi the Rock! - 01 (1080p) [E04F4EFB].mkv",
+    "filesize": 1445813416
+  }
+}`,
+	"/magnet/delete": `{
+  "status": "success",
+  "data": {
+    "message": "Magnet was successfully deleted"
+  }
+}`,
+}
+
+func newTestAllDebrid(t *testing.T, apiKey string) *AllDebrid {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-key" {
+			_, _ = w.Write([]byte(`{"status": "error", "error": {"code": "AUTH_BAD_APIKEY", "message": "The auth apikey is invalid"}}`))
+			return
+		}
+		require.Equal(t, agent, r.FormValue("agent"))
+
+		key := r.URL.Path
+		if key == "/magnet/status" && r.FormValue("id") != "" {
+			key += "?id"
+		}
+		resp, ok := recordedResponses[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(resp))
+	}))
+	t.Cleanup(server.Close)
+
+	return &AllDebrid{
+		baseUrl: server.URL,
+		apiKey:  mo.Some(apiKey),
+		client:  server.Client(),
+		logger:  util.NewLogger(),
+	}
+}
+
+func TestAllDebrid_GetInstantAvailability(t *testing.T) {
+	ad := newTestAllDebrid(t, "test-key")
+
+	avail := ad.GetInstantAvailability([]string{
+		"80431B4F9A12F4E06616062D3D3973B9EF99B5E6",
+		"a1b2c3d4e5f60718293a4b5c6d7e8f9012345678",
+		"0000000000000000000000000000000000000000",
+	})
+
+	require.Len(t, avail, 2)
+	// The requested hash is used as key
+	require.Contains(t, avail, "80431B4F9A12F4E06616062D3D3973B9EF99B5E6")
+	require.Len(t, avail["80431B4F9A12F4E06616062D3D3973B9EF99B5E6"].CachedFiles, 1)
+
+	// Folders are flattened
+	files := avail["a1b2c3d4e5f60718293a4b5c6d7e8f9012345678"].CachedFiles
+	require.Len(t, files, 2)
+	require.Equal(t, "Show - 02.mkv", files["1"].Name)
+	require.Equal(t, int64(2000), files["1"].Size)
+}
+
+func TestAllDebrid_AddTorrent(t *testing.T) {
+	ad := newTestAllDebrid(t, "test-key")
+
+	// Already added
+	id, err := ad.AddTorrent(debrid.AddTorrentOptions{
+		MagnetLink: "magnet:?xt=urn:btih:80431b4f9a12f4e06616062d3d3973b9ef99b5e6",
+		InfoHash:   "80431b4f9a12f4e06616062d3d3973b9ef99b5e6",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "251289046", id)
+
+	// Uploaded
+	id, err = ad.AddTorrent(debrid.AddTorrentOptions{
+		MagnetLink: "magnet:?xt=urn:btih:80431b4f9a12f4e06616062d3d3973b9ef99b5e6",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "251289046", id)
+}
+
+func TestAllDebrid_GetTorrents(t *testing.T) {
+	ad := newTestAllDebrid(t, "test-key")
+
+	torrents, err := ad.GetTorrents()
+	require.NoError(t, err)
+	require.Len(t, torrents, 2)
+
+	// Sorted by date added, most recent first
+	require.Equal(t, "251289047", torrents[0].ID)
+	require.Equal(t, debrid.TorrentItemStatusDownloading, torrents[0].Status)
+	require.Equal(t, 50, torrents[0].CompletionPercentage)
+	require.False(t, torrents[0].IsReady)
+
+	require.Equal(t, debrid.TorrentItemStatusCompleted, torrents[1].Status)
+	require.True(t, torrents[1].IsReady)
+}
+
+func TestAllDebrid_GetTorrentDownloadUrl(t *testing.T) {
+	ad := newTestAllDebrid(t, "test-key")
+
+	downloadUrl, err := ad.GetTorrentDownloadUrl(debrid.DownloadTorrentOptions{ID: "251289046", FileId: "0"})
+	require.NoError(t, err)
+	require.Equal(t, "https://abcd.debrid.it/dl/AAAAAAAAAAAA/Bocchi.mkv", downloadUrl)
+
+	_, err = ad.GetTorrentDownloadUrl(debrid.DownloadTorrentOptions{ID: "251289046", FileId: "1"})
+	require.Error(t, err)
+}
+
+func TestAllDebrid_GetTorrentStreamUrl(t *testing.T) {
+	ad := newTestAllDebrid(t, "test-key")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	itemCh := make(chan debrid.TorrentItem, 1)
+	go func() {
+		for range itemCh {
+		}
+	}()
+	defer close(itemCh)
+
+	streamUrl, err := ad.GetTorrentStreamUrl(ctx, debrid.StreamTorrentOptions{ID: "251289046", FileId: "0"}, itemCh)
+	require.NoError(t, err)
+	require.Equal(t, "https://abcd.debrid.it/dl/AAAAAAAAAAAA/Bocchi.mkv", streamUrl)
+}
+
+func TestAllDebrid_DeleteTorrent(t *testing.T) {
+	ad := newTestAllDebrid(t, "test-key")
+
+	err := ad.DeleteTorrent("251289046")
+	require.NoError(t, err)
+}
+
+func TestAllDebrid_InvalidApiKey(t *testing.T) {
+	ad := newTestAllDebrid(t, "invalid")
+
+	_, err := ad.GetTorrents()
+	require.ErrorContains(t, err, "AUTH_BAD_APIKEY")
+}
@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"seanime/internal/database/models"
 	"seanime/internal/debrid/debrid"
 	"seanime/internal/events"
 	"seanime/internal/hook"
@@ -34,62 +35,86 @@ func (r *Repository) launchDownloadLoop(ctx context.Context) {
 				return
 			case <-time.After(time.Minute * 1):
 				// Every minute, check if there are any completed downloads
-				provider, found := r.provider.Get()
-				if !found {
-					continue
-				}
+				r.downloadReadyTorrentItems()
+			}
+		}
+	}()
+}
 
-				// Get the list of completed downloads
-				items, err := provider.GetTorrents()
-				if err != nil {
-					r.logger.Err(err).Msg("debrid: Failed to get torrents")
-					continue
-				}
+// downloadReadyTorrentItems downloads the queued torrents that are ready on their provider.
+func (r *Repository) downloadReadyTorrentItems() {
+	if r.provider.IsAbsent() {
+		return
+	}
 
-				readyItems := make([]*debrid.TorrentItem, 0)
-				for _, item := range items {
-					if item.IsReady {
-						readyItems = append(readyItems, item)
-					}
-				}
+	dbItems, err := r.db.GetDebridTorrentItems()
+	if err != nil {
+		r.logger.Err(err).Msg("debrid: Failed to get debrid torrent items")
+		return
+	}
+	if len(dbItems) == 0 {
+		return
+	}
 
-				dbItems, err := r.db.GetDebridTorrentItems()
-				if err != nil {
-					r.logger.Err(err).Msg("debrid: Failed to get debrid torrent items")
-					continue
-				}
+	// Group the queued torrents by the provider they were added to
+	providerItems := make(map[debrid.Provider][]*models.DebridTorrentItem)
+	for _, dbItem := range dbItems {
+		provider, err := r.getProviderById(dbItem.Provider)
+		if err != nil {
+			r.logger.Warn().Str("provider", dbItem.Provider).Str("torrentItemId", dbItem.TorrentItemID).Msg("debrid: Provider of queued torrent not set")
+			continue
+		}
+		providerItems[provider] = append(providerItems[provider], dbItem)
+	}
 
-				for _, dbItem := range dbItems {
-					// Check if the item is ready for download
-					for _, readyItem := range readyItems {
-						if dbItem.TorrentItemID == readyItem.ID {
-							r.logger.Debug().Str("torrentItemId", dbItem.TorrentItemID).Msg("debrid: Torrent is ready for download")
-							// Remove the item from the database
-							err = r.db.DeleteDebridTorrentItemByDbId(dbItem.ID)
-							if err != nil {
-								r.logger.Err(err).Msg("debrid: Failed to remove debrid torrent item")
-								continue
-							}
-							time.Sleep(1 * time.Second)
-							// Download the torrent locally
-							err = r.downloadTorrentItem(readyItem.ID, readyItem.Name, dbItem.Destination)
-							if err != nil {
-								r.logger.Err(err).Msg("debrid: Failed to download torrent")
-								continue
-							}
-						}
+	for provider, items := range providerItems {
+		// Get the list of completed downloads
+		torrents, err := provider.GetTorrents()
+		if err != nil {
+			r.logger.Err(err).Str("provider", provider.GetSettings().ID).Msg("debrid: Failed to get torrents")
+			continue
+		}
+
+		readyItems := make([]*debrid.TorrentItem, 0)
+		for _, item := range torrents {
+			if item.IsReady {
+				readyItems = append(readyItems, item)
+			}
+		}
+
+		for _, dbItem := range items {
+			// Check if the item is ready for download
+			for _, readyItem := range readyItems {
+				if dbItem.TorrentItemID == readyItem.ID {
+					r.logger.Debug().Str("torrentItemId", dbItem.TorrentItemID).Msg("debrid: Torrent is ready for download")
+					// Remove the item from the database
+					err = r.db.DeleteDebridTorrentItemByDbId(dbItem.ID)
+					if err != nil {
+						r.logger.Err(err).Msg("debrid: Failed to remove debrid torrent item")
+						continue
+					}
+					time.Sleep(1 * time.Second)
+					// Download the torrent locally
+					err = r.downloadTorrentItem(provider, readyItem.ID, readyItem.Name, dbItem.Destination)
+					if err != nil {
+						r.logger.Err(err).Msg("debrid: Failed to download torrent")
+						continue
 					}
 				}
-
 			}
 		}
-	}()
+	}
 }
 
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
+// DownloadTorrent downloads a torrent of the main provider.
 func (r *Repository) DownloadTorrent(item debrid.TorrentItem, destination string) error {
-	return r.downloadTorrentItem(item.ID, item.Name, destination)
+	provider, err := r.GetProvider()
+	if err != nil {
+		return err
+	}
+	return r.downloadTorrentItem(provider, item.ID, item.Name, destination)
 }
 
 type downloadStatus struct {
@@ -97,14 +122,9 @@ type downloadStatus struct {
 	TotalSize  int64
 }
 
-func (r *Repository) downloadTorrentItem(tId string, torrentName string, destination string) (err error) {
+func (r *Repository) downloadTorrentItem(provider debrid.Provider, tId string, torrentName string, destination string) (err error) {
 	defer util.HandlePanicInModuleWithError("debrid/client/downloadTorrentItem", &err)
 
-	provider, err := r.GetProvider()
-	if err != nil {
-		return err
-	}
-
 	r.logger.Debug().Str("torrentName", torrentName).Str("destination", destination).Msg("debrid: Downloading torrent")
 
 	// Get the download URL
@@ -75,7 +75,7 @@ func TestTorBoxDownload(t *testing.T) {
 	require.NoError(t, err)
 
 	// Download the torrent
-	err = repo.downloadTorrentItem(dbTorrentItem.TorrentItemID, torrentItem.Name, dbTorrentItem.Destination)
+	err = repo.downloadTorrentItem(provider, dbTorrentItem.TorrentItemID, torrentItem.Name, dbTorrentItem.Destination)
 	require.NoError(t, err)
 
 	time.Sleep(time.Millisecond * 500)
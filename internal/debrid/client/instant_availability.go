@@ -0,0 +1,75 @@
+package debrid_client
+
+import (
+	"seanime/internal/debrid/debrid"
+	"slices"
+)
+
+// GetInstantAvailability checks the instant availability of the torrents on every configured provider.
+// Providers are checked in the order of the priority setting, followed by the main provider and the additional providers.
+// A torrent that is cached on several providers is reported for the first one.
+func (r *Repository) GetInstantAvailability(hashes []string) map[string]debrid.TorrentItemInstantAvailability {
+	ret := make(map[string]debrid.TorrentItemInstantAvailability)
+
+	providers := r.getProvidersByPriority()
+	if len(providers) == 0 || len(hashes) == 0 {
+		return ret
+	}
+
+	remaining := hashes
+	for _, provider := range providers {
+		if len(remaining) == 0 {
+			break
+		}
+
+		avail := provider.GetInstantAvailability(remaining)
+		if len(avail) == 0 {
+			continue
+		}
+
+		for hash, item := range avail {
+			if len(providers) > 1 {
+				item.Provider = provider.GetSettings().ID
+			}
+			ret[hash] = item
+		}
+
+		next := make([]string, 0, len(remaining))
+		for _, hash := range remaining {
+			if _, found := avail[hash]; !found {
+				next = append(next, hash)
+			}
+		}
+		remaining = next
+	}
+
+	return ret
+}
+
+// getProvidersByPriority returns the main and additional providers, sorted by priority.
+func (r *Repository) getProvidersByPriority() []debrid.Provider {
+	providers := make([]debrid.Provider, 0, len(r.additionalProviders)+1)
+	if p, found := r.provider.Get(); found {
+		providers = append(providers, p)
+	}
+	providers = append(providers, r.additionalProviders...)
+
+	priority := r.settings.ProviderPriority
+	if len(priority) == 0 {
+		return providers
+	}
+
+	rank := func(p debrid.Provider) int {
+		if idx := slices.Index(priority, p.GetSettings().ID); idx != -1 {
+			return idx
+		}
+		return len(priority)
+	}
+
+	// Stable sort to keep the main provider first among providers that are not in the priority list
+	slices.SortStableFunc(providers, func(a, b debrid.Provider) int {
+		return rank(a) - rank(b)
+	})
+
+	return providers
+}
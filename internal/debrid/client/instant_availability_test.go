@@ -0,0 +1,102 @@
+package debrid_client
+
+import (
+	"seanime/internal/database/models"
+	"seanime/internal/debrid/debrid"
+	"testing"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAvailabilityProvider struct {
+	debrid.Provider
+	id      string
+	cached  map[string]bool
+	queries [][]string
+}
+
+func (p *fakeAvailabilityProvider) GetSettings() debrid.Settings {
+	return debrid.Settings{ID: p.id, Name: p.id}
+}
+
+func (p *fakeAvailabilityProvider) GetInstantAvailability(hashes []string) map[string]debrid.TorrentItemInstantAvailability {
+	p.queries = append(p.queries, hashes)
+	ret := make(map[string]debrid.TorrentItemInstantAvailability)
+	for _, hash := range hashes {
+		if p.cached[hash] {
+			ret[hash] = debrid.TorrentItemInstantAvailability{CachedFiles: map[string]*debrid.CachedFile{}}
+		}
+	}
+	return ret
+}
+
+func TestRepository_GetInstantAvailability(t *testing.T) {
+	realdebrid := &fakeAvailabilityProvider{id: "realdebrid", cached: map[string]bool{"a": true, "b": true}}
+	alldebrid := &fakeAvailabilityProvider{id: "alldebrid", cached: map[string]bool{"b": true, "c": true}}
+	premiumize := &fakeAvailabilityProvider{id: "premiumize", cached: map[string]bool{"d": true}}
+
+	r := &Repository{
+		provider:            mo.Some[debrid.Provider](realdebrid),
+		additionalProviders: []debrid.Provider{premiumize, alldebrid},
+		settings: &models.DebridSettings{
+			ProviderPriority: models.StringSlice{"alldebrid"},
+		},
+	}
+
+	avail := r.GetInstantAvailability([]string{"a", "b", "c", "d", "e"})
+
+	require.Len(t, avail, 4)
+	// AllDebrid has the highest priority
+	require.Equal(t, "alldebrid", avail["b"].Provider)
+	require.Equal(t, "alldebrid", avail["c"].Provider)
+	require.Equal(t, "realdebrid", avail["a"].Provider)
+	require.Equal(t, "premiumize", avail["d"].Provider)
+
+	// Only the hashes that were not found are checked on the next providers
+	require.Equal(t, [][]string{{"a", "b", "c", "d", "e"}}, alldebrid.queries)
+	require.Equal(t, [][]string{{"a", "d", "e"}}, realdebrid.queries)
+	require.Equal(t, [][]string{{"d", "e"}}, premiumize.queries)
+}
+
+func TestRepository_GetInstantAvailability_SingleProvider(t *testing.T) {
+	torbox := &fakeAvailabilityProvider{id: "torbox", cached: map[string]bool{"a": true}}
+
+	r := &Repository{
+		provider: mo.Some[debrid.Provider](torbox),
+		settings: &models.DebridSettings{},
+	}
+
+	avail := r.GetInstantAvailability([]string{"a", "b"})
+
+	require.Len(t, avail, 1)
+	require.Empty(t, avail["a"].Provider)
+}
+
+func TestRepository_GetProviderForHash(t *testing.T) {
+	realdebrid := &fakeAvailabilityProvider{id: "realdebrid", cached: map[string]bool{"a": true, "b": true}}
+	alldebrid := &fakeAvailabilityProvider{id: "alldebrid", cached: map[string]bool{"b": true, "c": true}}
+
+	r := &Repository{
+		provider:            mo.Some[debrid.Provider](realdebrid),
+		additionalProviders: []debrid.Provider{alldebrid},
+		settings:            &models.DebridSettings{},
+	}
+
+	tests := []struct {
+		hash     string
+		expected string
+	}{
+		{"a", "realdebrid"},
+		{"b", "realdebrid"}, // Cached on both, the main provider comes first
+		{"c", "alldebrid"},
+		{"d", "realdebrid"}, // Not cached
+		{"", "realdebrid"},
+	}
+
+	for _, tt := range tests {
+		provider, err := r.getProviderForHash(tt.hash)
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, provider.GetSettings().ID, tt.hash)
+	}
+}
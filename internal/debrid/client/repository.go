@@ -10,11 +10,14 @@ import (
 	"seanime/internal/api/metadata"
 	"seanime/internal/database/db"
 	"seanime/internal/database/models"
+	"seanime/internal/debrid/alldebrid"
 	"seanime/internal/debrid/debrid"
+	"seanime/internal/debrid/premiumize"
 	"seanime/internal/debrid/realdebrid"
 	"seanime/internal/debrid/torbox"
 	"seanime/internal/events"
 	"seanime/internal/library/playbackmanager"
+	"seanime/internal/mediastream"
 	"seanime/internal/platforms/platform"
 	"seanime/internal/torrents/torrent"
 	"seanime/internal/util/result"
@@ -27,6 +30,7 @@ var (
 type (
 	Repository struct {
 		provider               mo.Option[debrid.Provider]
+		additionalProviders    []debrid.Provider // Used for the torrents that are cached on them but not on the main provider
 		logger                 *zerolog.Logger
 		db                     *db.Database
 		settings               *models.DebridSettings
@@ -35,11 +39,12 @@ type (
 		downloadLoopCancelFunc context.CancelFunc
 		torrentRepository      *torrent.Repository
 
-		playbackManager    *playbackmanager.PlaybackManager
-		streamManager      *StreamManager
-		completeAnimeCache *anilist.CompleteAnimeCache
-		metadataProvider   metadata.Provider
-		platform           platform.Platform
+		playbackManager       *playbackmanager.PlaybackManager
+		streamManager         *StreamManager
+		completeAnimeCache    *anilist.CompleteAnimeCache
+		metadataProvider      metadata.Provider
+		platform              platform.Platform
+		mediastreamRepository *mediastream.Repository // Set by [SetMediastreamRepository]
 	}
 
 	NewRepositoryOptions struct {
@@ -98,19 +103,14 @@ func (r *Repository) InitializeProvider(settings *models.DebridSettings) error {
 
 	if !settings.Enabled {
 		r.provider = mo.None[debrid.Provider]()
+		r.additionalProviders = nil
 		// Stop the download loop if it's running
 		r.startOrStopDownloadLoop()
 		return nil
 	}
 
-	switch settings.Provider {
-	case "torbox":
-		r.provider = mo.Some(torbox.NewTorBox(r.logger))
-	case "realdebrid":
-		r.provider = mo.Some(realdebrid.NewRealDebrid(r.logger))
-	default:
-		r.provider = mo.None[debrid.Provider]()
-	}
+	r.provider = newProvider(settings.Provider, r.logger)
+	r.initializeAdditionalProviders(settings)
 
 	if r.provider.IsAbsent() {
 		r.logger.Warn().Str("provider", settings.Provider).Msg("debrid: No provider set")
@@ -137,6 +137,48 @@ func (r *Repository) InitializeProvider(settings *models.DebridSettings) error {
 	return nil
 }
 
+func newProvider(id string, logger *zerolog.Logger) mo.Option[debrid.Provider] {
+	switch id {
+	case "torbox":
+		return mo.Some(torbox.NewTorBox(logger))
+	case "realdebrid":
+		return mo.Some(realdebrid.NewRealDebrid(logger))
+	case "alldebrid":
+		return mo.Some(alldebrid.NewAllDebrid(logger))
+	case "premiumize":
+		return mo.Some(premiumize.NewPremiumize(logger))
+	default:
+		return mo.None[debrid.Provider]()
+	}
+}
+
+// initializeAdditionalProviders creates and authenticates the additional providers.
+// Providers that are unknown, duplicated or that fail to authenticate are ignored.
+func (r *Repository) initializeAdditionalProviders(settings *models.DebridSettings) {
+	r.additionalProviders = make([]debrid.Provider, 0, len(settings.AdditionalProviders))
+
+	seen := map[string]struct{}{settings.Provider: {}}
+	for _, s := range settings.AdditionalProviders {
+		if s == nil || s.ApiKey == "" {
+			continue
+		}
+		if _, ok := seen[s.Provider]; ok {
+			continue
+		}
+		p, ok := newProvider(s.Provider, r.logger).Get()
+		if !ok {
+			r.logger.Warn().Str("provider", s.Provider).Msg("debrid: Unknown additional provider")
+			continue
+		}
+		if err := p.Authenticate(s.ApiKey); err != nil {
+			r.logger.Err(err).Str("provider", s.Provider).Msg("debrid: Failed to authenticate additional provider")
+			continue
+		}
+		seen[s.Provider] = struct{}{}
+		r.additionalProviders = append(r.additionalProviders, p)
+	}
+}
+
 func (r *Repository) GetProvider() (debrid.Provider, error) {
 	p, found := r.provider.Get()
 	if !found {
@@ -146,11 +188,45 @@ func (r *Repository) GetProvider() (debrid.Provider, error) {
 	return p, nil
 }
 
+// getProviderById returns the main provider if the ID is empty or is the main provider's, or the additional provider with the ID.
+func (r *Repository) getProviderById(id string) (debrid.Provider, error) {
+	p, err := r.GetProvider()
+	if err != nil {
+		return nil, err
+	}
+	if id == "" || p.GetSettings().ID == id {
+		return p, nil
+	}
+
+	for _, ap := range r.additionalProviders {
+		if ap.GetSettings().ID == id {
+			return ap, nil
+		}
+	}
+
+	return nil, fmt.Errorf("debrid: Provider %s not set", id)
+}
+
+// getProviderForHash returns the provider the torrent is cached on, following the priority setting.
+// It returns the main provider if the torrent isn't cached or there are no additional providers.
+func (r *Repository) getProviderForHash(hash string) (debrid.Provider, error) {
+	if hash == "" || len(r.additionalProviders) == 0 {
+		return r.GetProvider()
+	}
+
+	if item, found := r.GetInstantAvailability([]string{hash})[hash]; found {
+		return r.getProviderById(item.Provider)
+	}
+
+	return r.GetProvider()
+}
+
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
 // AddAndQueueTorrent adds a torrent to the debrid service and queues it for automatic download
+// The torrent is added to the provider it is cached on, see [getProviderForHash].
 func (r *Repository) AddAndQueueTorrent(opts debrid.AddTorrentOptions, destination string, mId int) (string, error) {
-	provider, err := r.GetProvider()
+	provider, err := r.getProviderForHash(opts.InfoHash)
 	if err != nil {
 		return "", err
 	}
@@ -180,8 +256,9 @@ func (r *Repository) AddAndQueueTorrent(opts debrid.AddTorrentOptions, destinati
 // GetTorrentInfo retrieves information about a torrent.
 // This is used for file section for debrid streaming.
 // On Real Debrid, this adds the torrent to the user's account.
+// The information comes from the provider the torrent is cached on, since it is the one that will stream it.
 func (r *Repository) GetTorrentInfo(opts debrid.GetTorrentInfoOptions) (*debrid.TorrentInfo, error) {
-	provider, err := r.GetProvider()
+	provider, err := r.getProviderForHash(opts.InfoHash)
 	if err != nil {
 		return nil, err
 	}
@@ -205,6 +282,11 @@ func (r *Repository) GetSettings() *models.DebridSettings {
 	return r.settings
 }
 
+// SetMediastreamRepository sets the mediastream repository used to transcode streams played in the browser.
+func (r *Repository) SetMediastreamRepository(mediastreamRepository *mediastream.Repository) {
+	r.mediastreamRepository = mediastreamRepository
+}
+
 // CancelDownload cancels the download for the given item ID
 func (r *Repository) CancelDownload(itemID string) error {
 	cancelFunc, found := r.ctxMap.Get(itemID)
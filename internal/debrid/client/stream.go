@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"seanime/internal/continuity"
 	"seanime/internal/database/db_bridge"
 	"seanime/internal/debrid/debrid"
 	"seanime/internal/events"
 	hibiketorrent "seanime/internal/extension/hibike/torrent"
 	"seanime/internal/hook"
 	"seanime/internal/library/playbackmanager"
+	"seanime/internal/mediastream"
 	"seanime/internal/util"
 	"strconv"
 	"time"
@@ -19,6 +21,7 @@ type (
 	StreamManager struct {
 		repository            *Repository
 		currentTorrentItemId  string
+		currentProvider       debrid.Provider // Provider the current torrent was added to
 		downloadCtxCancelFunc context.CancelFunc
 	}
 
@@ -70,6 +73,7 @@ func NewStreamManager(repository *Repository) *StreamManager {
 const (
 	PlaybackTypeDefault        StreamPlaybackType = "default"
 	PlaybackTypeExternalPlayer StreamPlaybackType = "externalPlayerLink"
+	PlaybackTypeTranscode      StreamPlaybackType = "transcode" // Transcoded by the mediastream module and played in the browser
 )
 
 // startStream is called by the client to start streaming a torrent
@@ -137,6 +141,12 @@ func (s *StreamManager) startStream(opts *StartStreamOptions) (err error) {
 			Message:     "Analyzing selected torrent...",
 		})
 
+		// Stream from the provider the torrent is cached on
+		provider, err = s.repository.getProviderForHash(selectedTorrent.InfoHash)
+		if err != nil {
+			return fmt.Errorf("debridstream: Failed to start stream: %w", err)
+		}
+
 		// If no fileId is provided, we need to analyze the torrent to find the correct file
 		if fileId == "" {
 			var chosenFileIndex *int
@@ -186,6 +196,7 @@ func (s *StreamManager) startStream(opts *StartStreamOptions) (err error) {
 
 	// Save the current torrent item id
 	s.currentTorrentItemId = torrentItemId
+	s.currentProvider = provider
 	ctx, cancelCtx := context.WithCancel(context.Background())
 	s.downloadCtxCancelFunc = cancelCtx
 
@@ -347,9 +358,10 @@ func (s *StreamManager) startStream(opts *StartStreamOptions) (err error) {
 			// Sends the stream to the media player
 			// DEVNOTE: Events are handled by the torrentstream.Repository module
 			err = s.repository.playbackManager.StartStreamingUsingMediaPlayer(windowTitle, &playbackmanager.StartPlayingOptions{
-				Payload:   streamUrl,
-				UserAgent: opts.UserAgent,
-				ClientId:  opts.ClientId,
+				Payload:    streamUrl,
+				UserAgent:  opts.UserAgent,
+				ClientId:   opts.ClientId,
+				StreamKind: continuity.DebridKind,
 			}, media, aniDbEpisode)
 			if err != nil {
 				// Failed to start the stream, we'll drop the torrents and stop the server
@@ -379,6 +391,22 @@ func (s *StreamManager) startStream(opts *StartStreamOptions) (err error) {
 				TorrentName: selectedTorrent.Name,
 				Message:     "External player link sent",
 			})
+
+		case PlaybackTypeTranscode:
+			//
+			// Transcode the stream
+			//
+			s.repository.logger.Debug().Msg("debridstream: Sending the stream to the transcoder")
+			err = s.startTranscodeStream(provider.GetSettings().ID, torrentItemId, fileId, streamUrl, opts)
+			if err != nil {
+				s.repository.logger.Err(err).Msg("debridstream: Failed to transcode the stream")
+				s.repository.wsEventManager.SendEvent(events.DebridStreamState, StreamState{
+					Status:      StreamStatusFailed,
+					TorrentName: selectedTorrent.Name,
+					Message:     fmt.Sprintf("Failed to transcode the stream, %v", err),
+				})
+				return
+			}
 		}
 
 		go func() {
@@ -398,22 +426,29 @@ func (s *StreamManager) startStream(opts *StartStreamOptions) (err error) {
 	return nil
 }
 
+// startTranscodeStream sends the stream URL to the transcoder so that it can be watched in the browser.
+func (s *StreamManager) startTranscodeStream(providerId string, torrentItemId string, fileId string, streamUrl string, opts *StartStreamOptions) error {
+	if s.repository.mediastreamRepository == nil || !s.repository.mediastreamRepository.IsInitialized() {
+		return errors.New("media streaming is not enabled")
+	}
+
+	return s.repository.mediastreamRepository.StartSourceTranscode(&mediastream.StreamSource{
+		ID:            fmt.Sprintf("debrid:%s:%s:%s", providerId, torrentItemId, fileId),
+		Url:           streamUrl,
+		MediaId:       opts.MediaId,
+		EpisodeNumber: opts.EpisodeNumber,
+	}, opts.ClientId)
+}
+
 func (s *StreamManager) cancelStream(opts *CancelStreamOptions) {
 	if s.downloadCtxCancelFunc != nil {
 		s.downloadCtxCancelFunc()
 		s.downloadCtxCancelFunc = nil
 	}
 
-	if opts.RemoveTorrent && s.currentTorrentItemId != "" {
-		// Remove the torrent from the debrid service
-		provider, err := s.repository.GetProvider()
-		if err != nil {
-			s.repository.logger.Err(err).Msg("debridstream: Failed to remove torrent")
-			return
-		}
-
-		// Remove the torrent from the debrid service
-		err = provider.DeleteTorrent(s.currentTorrentItemId)
+	if opts.RemoveTorrent && s.currentTorrentItemId != "" && s.currentProvider != nil {
+		// Remove the torrent from the debrid service it was added to
+		err := s.currentProvider.DeleteTorrent(s.currentTorrentItemId)
 		if err != nil {
 			s.repository.logger.Err(err).Msg("debridstream: Failed to remove torrent")
 		}
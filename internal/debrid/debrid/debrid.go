@@ -79,7 +79,8 @@ type (
 	TorrentItemStatus string
 
 	TorrentItemInstantAvailability struct {
-		CachedFiles map[string]*CachedFile `json:"cachedFiles"` // Key is the file ID (or index)
+		CachedFiles map[string]*CachedFile `json:"cachedFiles"`        // Key is the file ID (or index)
+		Provider    string                 `json:"provider,omitempty"` // ID of the provider, set when several providers are checked
 	}
 
 	//------------------------------------------------------------------
@@ -0,0 +1,606 @@
+package premiumize
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"seanime/internal/debrid/debrid"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/samber/mo"
+)
+
+type (
+	Premiumize struct {
+		baseUrl string
+		apiKey  mo.Option[string]
+		client  *http.Client
+		logger  *zerolog.Logger
+	}
+
+	Response struct {
+		Status  string `json:"status"` // "success" or "error"
+		Message string `json:"message"`
+	}
+
+	Transfer struct {
+		ID       string  `json:"id"`
+		Name     string  `json:"name"`
+		Message  string  `json:"message"`
+		Status   string  `json:"status"`
+		Progress float64 `json:"progress"` // 0 to 1
+		Src      string  `json:"src"`      // Magnet link
+		FolderID string  `json:"folder_id"`
+		FileID   string  `json:"file_id"` // Set if the transfer is a single file
+	}
+
+	Item struct {
+		ID         string `json:"id"`
+		Name       string `json:"name"`
+		Type       string `json:"type"` // "file" or "folder"
+		Size       int64  `json:"size"`
+		Link       string `json:"link"`
+		StreamLink string `json:"stream_link"`
+	}
+
+	DirectDownloadFile struct {
+		Path       string `json:"path"` // e.g. "Big Buck Bunny/Big Buck Bunny.mp4"
+		Size       int64  `json:"size"`
+		Link       string `json:"link"`
+		StreamLink string `json:"stream_link"`
+	}
+
+	// transferFile is a file of a finished transfer
+	transferFile struct {
+		Path string
+		Item *Item
+	}
+)
+
+var btihRegex = regexp.MustCompile(`(?i)urn:btih:([a-z0-9]+)`)
+
+func NewPremiumize(logger *zerolog.Logger) debrid.Provider {
+	return &Premiumize{
+		baseUrl: "https://www.premiumize.me/api",
+		apiKey:  mo.None[string](),
+		client: &http.Client{
+			Timeout: time.Second * 30,
+		},
+		logger: logger,
+	}
+}
+
+func (t *Premiumize) GetSettings() debrid.Settings {
+	return debrid.Settings{
+		ID:   "premiumize",
+		Name: "Premiumize",
+	}
+}
+
+// doQuery sends a request to the API and decodes the response into ret.
+// Parameters are sent in the query string for GET requests and as a form otherwise.
+func (t *Premiumize) doQuery(method, path string, params url.Values, ret interface{}) (err error) {
+	apiKey, found := t.apiKey.Get()
+	if !found {
+		return debrid.ErrNotAuthenticated
+	}
+
+	if params == nil {
+		params = url.Values{}
+	}
+
+	var req *http.Request
+	if method == http.MethodGet {
+		params.Set("apikey", apiKey)
+		req, err = http.NewRequest(method, t.baseUrl+path+"?"+params.Encode(), nil)
+	} else {
+		req, err = http.NewRequest(method, t.baseUrl+path+"?apikey="+url.QueryEscape(apiKey), strings.NewReader(params.Encode()))
+		if req != nil {
+			req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var r Response
+	if err := json.Unmarshal(content, &r); err != nil {
+		t.logger.Error().Err(err).Msg("premiumize: Failed to decode response")
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if r.Status != "success" {
+		if r.Message != "" {
+			return fmt.Errorf("failed to query API: %s", r.Message)
+		}
+		return fmt.Errorf("failed to query API: %s", resp.Status)
+	}
+
+	if ret != nil {
+		if err := json.Unmarshal(content, ret); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (t *Premiumize) Authenticate(apiKey string) error {
+	t.apiKey = mo.Some(apiKey)
+	return nil
+}
+
+// GetInstantAvailability checks the cache of the given hashes.
+// Premiumize only returns the name and size of the largest file, which is used as the only cached file.
+func (t *Premiumize) GetInstantAvailability(hashes []string) map[string]debrid.TorrentItemInstantAvailability {
+
+	t.logger.Trace().Strs("hashes", hashes).Msg("premiumize: Checking instant availability")
+
+	availability := make(map[string]debrid.TorrentItemInstantAvailability)
+
+	if len(hashes) == 0 {
+		return availability
+	}
+
+	for i := 0; i < len(hashes); i += 100 {
+		end := min(i+100, len(hashes))
+		batch := hashes[i:end]
+
+		params := url.Values{}
+		for _, hash := range batch {
+			params.Add("items[]", hash)
+		}
+
+		var data struct {
+			Response []bool        `json:"response"`
+			Filename []string      `json:"filename"`
+			Filesize []interface{} `json:"filesize"` // Can be a string or a number
+		}
+		err := t.doQuery(http.MethodGet, "/cache/check", params, &data)
+		if err != nil {
+			t.logger.Error().Err(err).Msg("premiumize: Failed to get instant availability")
+			return availability
+		}
+
+		for idx, cached := range data.Response {
+			if !cached || idx >= len(batch) {
+				continue
+			}
+
+			avail := debrid.TorrentItemInstantAvailability{
+				CachedFiles: make(map[string]*debrid.CachedFile),
+			}
+			file := &debrid.CachedFile{}
+			if idx < len(data.Filename) {
+				file.Name = data.Filename[idx]
+			}
+			if idx < len(data.Filesize) {
+				file.Size = toInt64(data.Filesize[idx])
+			}
+			avail.CachedFiles["0"] = file
+
+			availability[batch[idx]] = avail
+		}
+	}
+
+	return availability
+}
+
+func (t *Premiumize) AddTorrent(opts debrid.AddTorrentOptions) (string, error) {
+
+	// Check if the torrent is already added
+	if opts.InfoHash != "" {
+		transfers, err := t.getTransfers()
+		if err == nil {
+			for _, tr := range transfers {
+				if strings.EqualFold(getHash(tr.Src), opts.InfoHash) && !isErrorStatus(tr.Status) {
+					t.logger.Debug().Str("torrentId", tr.ID).Msg("premiumize: Torrent already added")
+					return tr.ID, nil
+				}
+			}
+		}
+	}
+
+	t.logger.Trace().Str("magnetLink", opts.MagnetLink).Msg("premiumize: Adding torrent")
+
+	var data struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	err := t.doQuery(http.MethodPost, "/transfer/create", url.Values{"src": {opts.MagnetLink}}, &data)
+	if err != nil {
+		t.logger.Error().Err(err).Msg("premiumize: Failed to add torrent")
+		return "", fmt.Errorf("premiumize: Failed to add torrent: %w", err)
+	}
+
+	t.logger.Debug().Str("torrentId", data.ID).Str("torrentName", data.Name).Msg("premiumize: Torrent added")
+
+	return data.ID, nil
+}
+
+// GetTorrentStreamUrl blocks until the torrent is downloaded and returns the stream URL for the torrent file.
+func (t *Premiumize) GetTorrentStreamUrl(ctx context.Context, opts debrid.StreamTorrentOptions, itemCh chan debrid.TorrentItem) (streamUrl string, err error) {
+
+	t.logger.Trace().Str("torrentId", opts.ID).Str("fileId", opts.FileId).Msg("premiumize: Retrieving stream link")
+
+	doneCh := make(chan struct{})
+
+	go func(ctx context.Context) {
+		defer func() {
+			close(doneCh)
+		}()
+		for {
+			select {
+			case <-ctx.Done():
+				err = ctx.Err()
+				return
+			case <-time.After(4 * time.Second):
+				transfer, _err := t.getTransfer(opts.ID)
+				if _err != nil {
+					t.logger.Error().Err(_err).Msg("premiumize: Failed to get torrent")
+					err = fmt.Errorf("premiumize: Failed to get torrent: %w", _err)
+					return
+				}
+
+				dt := toDebridTorrent(transfer)
+				itemCh <- *dt
+
+				if dt.Status == debrid.TorrentItemStatusError {
+					err = fmt.Errorf("premiumize: Torrent failed, %s", transfer.Message)
+					return
+				}
+
+				// Check if the torrent is ready
+				if dt.IsReady {
+					file, _err := t.getTransferFile(transfer, opts.FileId)
+					if _err != nil {
+						err = _err
+						return
+					}
+
+					streamUrl = file.Item.Link
+					return
+				}
+			}
+		}
+	}(ctx)
+
+	<-doneCh
+
+	return
+}
+
+// GetTorrentDownloadUrl returns the download URL of the file, or the comma-separated URLs of all files if no file is specified.
+func (t *Premiumize) GetTorrentDownloadUrl(opts debrid.DownloadTorrentOptions) (downloadUrl string, err error) {
+
+	t.logger.Trace().Str("torrentId", opts.ID).Msg("premiumize: Retrieving download link")
+
+	transfer, err := t.getTransfer(opts.ID)
+	if err != nil {
+		return "", fmt.Errorf("premiumize: Failed to get download URL: %w", err)
+	}
+
+	if !isReadyStatus(transfer.Status) {
+		return "", fmt.Errorf("premiumize: Torrent is not ready")
+	}
+
+	if opts.FileId != "" {
+		file, err := t.getTransferFile(transfer, opts.FileId)
+		if err != nil {
+			return "", err
+		}
+		return file.Item.Link, nil
+	}
+
+	files, err := t.getTransferFiles(transfer)
+	if err != nil {
+		return "", fmt.Errorf("premiumize: Failed to get download URL: %w", err)
+	}
+
+	urls := make([]string, 0, len(files))
+	for _, f := range files {
+		urls = append(urls, f.Item.Link)
+	}
+
+	return strings.Join(urls, ","), nil
+}
+
+func (t *Premiumize) GetTorrent(id string) (ret *debrid.TorrentItem, err error) {
+	transfer, err := t.getTransfer(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toDebridTorrent(transfer), nil
+}
+
+// GetTorrentInfo returns the files of a cached torrent without adding it to the user's account.
+// Premiumize can only list the files of cached torrents.
+func (t *Premiumize) GetTorrentInfo(opts debrid.GetTorrentInfoOptions) (ret *debrid.TorrentInfo, err error) {
+
+	src := opts.MagnetLink
+	if src == "" {
+		if opts.InfoHash == "" {
+			return nil, fmt.Errorf("premiumize: Magnet link is required")
+		}
+		src = "magnet:?xt=urn:btih:" + opts.InfoHash
+	}
+
+	var data struct {
+		Content []*DirectDownloadFile `json:"content"`
+	}
+	err = t.doQuery(http.MethodPost, "/transfer/directdl", url.Values{"src": {src}}, &data)
+	if err != nil {
+		return nil, fmt.Errorf("premiumize: Torrent is not cached: %w", err)
+	}
+
+	if len(data.Content) == 0 {
+		return nil, fmt.Errorf("premiumize: Torrent is not cached")
+	}
+
+	hash := opts.InfoHash
+	if hash == "" {
+		hash = getHash(src)
+	}
+
+	ret = toDebridTorrentInfo(hash, data.Content)
+
+	return ret, nil
+}
+
+func (t *Premiumize) GetTorrents() (ret []*debrid.TorrentItem, err error) {
+
+	transfers, err := t.getTransfers()
+	if err != nil {
+		return nil, fmt.Errorf("premiumize: Failed to get torrents: %w", err)
+	}
+
+	// Limit the number of torrents to 500
+	if len(transfers) > 500 {
+		transfers = transfers[:500]
+	}
+
+	for _, tr := range transfers {
+		ret = append(ret, toDebridTorrent(tr))
+	}
+
+	return ret, nil
+}
+
+func (t *Premiumize) DeleteTorrent(id string) error {
+
+	err := t.doQuery(http.MethodPost, "/transfer/delete", url.Values{"id": {id}}, nil)
+	if err != nil {
+		t.logger.Error().Err(err).Msg("premiumize: Failed to delete torrent")
+		return fmt.Errorf("premiumize: Failed to delete torrent: %w", err)
+	}
+
+	return nil
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (t *Premiumize) getTransfers() (ret []*Transfer, err error) {
+
+	var data struct {
+		Transfers []*Transfer `json:"transfers"`
+	}
+	err = t.doQuery(http.MethodGet, "/transfer/list", nil, &data)
+	if err != nil {
+		return nil, fmt.Errorf("premiumize: Failed to get torrents: %w", err)
+	}
+
+	return data.Transfers, nil
+}
+
+// getTransfer returns the transfer with the given ID, Premiumize does not have an endpoint for a single transfer.
+func (t *Premiumize) getTransfer(id string) (ret *Transfer, err error) {
+	transfers, err := t.getTransfers()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tr := range transfers {
+		if tr.ID == id {
+			return tr, nil
+		}
+	}
+
+	return nil, fmt.Errorf("premiumize: Torrent not found")
+}
+
+// getTransferFile returns the file of a finished transfer.
+// The file ID is the name of the file, since the files of GetTorrentInfo do not have Premiumize IDs.
+func (t *Premiumize) getTransferFile(transfer *Transfer, fileId string) (*transferFile, error) {
+	files, err := t.getTransferFiles(transfer)
+	if err != nil {
+		return nil, fmt.Errorf("premiumize: Failed to get files: %w", err)
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("premiumize: No files downloaded")
+	}
+
+	if fileId == "" {
+		return files[0], nil
+	}
+
+	for _, f := range files {
+		if f.Item.Name == fileId || f.Path == fileId || f.Item.ID == fileId {
+			return f, nil
+		}
+	}
+
+	return nil, fmt.Errorf("premiumize: File not found")
+}
+
+// getTransferFiles returns the files of a finished transfer, walking its folder.
+func (t *Premiumize) getTransferFiles(transfer *Transfer) ([]*transferFile, error) {
+	if transfer.FileID != "" {
+		var item Item
+		err := t.doQuery(http.MethodGet, "/item/details", url.Values{"id": {transfer.FileID}}, &item)
+		if err != nil {
+			return nil, err
+		}
+		return []*transferFile{{Path: item.Name, Item: &item}}, nil
+	}
+
+	if transfer.FolderID == "" {
+		return nil, fmt.Errorf("premiumize: Torrent has no files")
+	}
+
+	ret := make([]*transferFile, 0)
+	err := t.walkFolder(transfer.FolderID, "", &ret, 0)
+	return ret, err
+}
+
+func (t *Premiumize) walkFolder(folderId string, prefix string, ret *[]*transferFile, depth int) error {
+	if depth > 10 {
+		return nil
+	}
+
+	var data struct {
+		Content []*Item `json:"content"`
+	}
+	err := t.doQuery(http.MethodGet, "/folder/list", url.Values{"id": {folderId}}, &data)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range data.Content {
+		p := path.Join(prefix, item.Name)
+		if item.Type == "folder" {
+			if err := t.walkFolder(item.ID, p, ret, depth+1); err != nil {
+				return err
+			}
+			continue
+		}
+		*ret = append(*ret, &transferFile{Path: p, Item: item})
+	}
+
+	return nil
+}
+
+// getHash returns the info hash of a magnet link.
+func getHash(magnet string) string {
+	matches := btihRegex.FindStringSubmatch(magnet)
+	if len(matches) < 2 {
+		return ""
+	}
+	return strings.ToLower(matches[1])
+}
+
+func isReadyStatus(status string) bool {
+	return status == "finished" || status == "seeding"
+}
+
+func isErrorStatus(status string) bool {
+	switch status {
+	case "error", "timeout", "deleted", "banned":
+		return true
+	}
+	return false
+}
+
+func toDebridTorrent(t *Transfer) (ret *debrid.TorrentItem) {
+
+	status := toDebridTorrentStatus(t)
+
+	completionPercentage := int(t.Progress * 100)
+	if isReadyStatus(t.Status) {
+		completionPercentage = 100
+	}
+
+	ret = &debrid.TorrentItem{
+		ID:                   t.ID,
+		Name:                 t.Name,
+		Hash:                 getHash(t.Src),
+		CompletionPercentage: completionPercentage,
+		Status:               status,
+		IsReady:              isReadyStatus(t.Status),
+	}
+
+	return
+}
+
+func toDebridTorrentInfo(hash string, content []*DirectDownloadFile) (ret *debrid.TorrentInfo) {
+
+	var files []*debrid.TorrentItemFile
+	var size int64
+	for idx, f := range content {
+		name := path.Base(f.Path)
+		size += f.Size
+
+		files = append(files, &debrid.TorrentItemFile{
+			ID:    name, // The name is used to find the file in the transfer's folder
+			Index: idx,
+			Name:  name,         // e.g. "Big Buck Bunny.mp4"
+			Path:  "/" + f.Path, // e.g. "/Big Buck Bunny/Big Buck Bunny.mp4"
+			Size:  f.Size,
+		})
+	}
+
+	name := ""
+	if len(content) > 0 {
+		name = strings.Split(content[0].Path, "/")[0]
+	}
+
+	ret = &debrid.TorrentInfo{
+		Name:  name,
+		Hash:  hash,
+		Size:  size,
+		Files: files,
+	}
+
+	return
+}
+
+func toDebridTorrentStatus(t *Transfer) debrid.TorrentItemStatus {
+	switch t.Status {
+	case "waiting", "queued":
+		return debrid.TorrentItemStatusStalled
+	case "running":
+		return debrid.TorrentItemStatusDownloading
+	case "finished":
+		return debrid.TorrentItemStatusCompleted
+	case "seeding":
+		return debrid.TorrentItemStatusSeeding
+	case "error", "timeout", "deleted", "banned":
+		return debrid.TorrentItemStatusError
+	default:
+		return debrid.TorrentItemStatusOther
+	}
+}
+
+// toInt64 parses a number that can be returned as a string or a number.
+func toInt64(v interface{}) int64 {
+	switch v := v.(type) {
+	case float64:
+		return int64(v)
+	case string:
+		i, _ := strconv.ParseInt(v, 10, 64)
+		return i
+	}
+	return 0
+}
@@ -0,0 +1,228 @@
+package premiumize
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"seanime/internal/debrid/debrid"
+	"seanime/internal/util"
+	"testing"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+)
+
+// Responses recorded from the Premiumize API, with identifying values replaced
+var recordedResponses = map[string]string{
+	"/cache/check": `{
+  "status": "success",
+  "response": [true, false, true],
+  "transcoded": [true, false, false],
+  "filename": ["[SubsPlease] Bocchi the Rock! - 01 (1080p) [E04F4EFB].mkv", null, "Show - 02.mkv"],
+  "filesize": ["1445813416", null, 2000]
+}`,
+	"/transfer/list": `{
+  "status": "success",
+  "transfers": [
+    {
+      "id": "Xk3c5RzqZ9pPv3wXbW0g3A",
+      "name": "[SubsPlease] Bocchi the Rock! - 01 (1080p) [E04F4EFB].mkv",
+      "message": null,
+      "status": "finished",
+      "progress": 0,
+      "src": "magnet:?xt=urn:btih:80431B4F9A12F4E06616062D3D3973B9EF99B5E6&dn=Bocchi",
+      "folder_id": null,
+      "file_id": "a8Yw2rYQ5dK3lHqB3n6w1g"
+    },
+    {
+      "id": "Bq1fG7zT4mNc8vXyL2kP0w",
+      "name": "Show",
+      "message": "Downloading at 1.2 MB/s, 50% done",
+      "status": "running",
+      "progress": 0.5,
+      "src": "magnet:?xt=urn:btih:a1b2c3d4e5f60718293a4b5c6d7e8f9012345678&dn=Show",
+      "folder_id": null,
+      "file_id": null
+    },
+    {
+      "id": "Zp0aQ2wE3rT4yU5iO6pA7s",
+      "name": "Show 2",
+      "message": null,
+      "status": "finished",
+      "progress": 1,
+      "src": "magnet:?xt=urn:btih:b1b2c3d4e5f60718293a4b5c6d7e8f9012345678&dn=Show+2",
+      "folder_id": "F0lder1dAAAAAAAAAAAAAA",
+      "file_id": null
+    }
+  ]
+}`,
+	"/item/details": `{
+  "status": "success",
+  "id": "a8Yw2rYQ5dK3lHqB3n6w1g",
+  "name": "[SubsPlease] Bocchi the Rock! - 01 (1080p) [E04F4EFB].mkv",
+  "type": "file",
+  "size": 1445813416,
+  "link": "https://abcd.energycdn.com/dl/Bocchi.mkv",
+  "stream_link": "https://abcd.energycdn.com/stream/Bocchi.mkv"
+}`,
+	"/folder/list": `{
+  "status": "success",
+  "content": [
+    {"id": "f1", "name": "Show 2 - 01.mkv", "type": "file", "size": 1000, "link": "https://abcd.energycdn.com/dl/01.mkv"},
+    {"id": "F0lder2dBBBBBBBBBBBBBB", "name": "Extras", "type": "folder"}
+  ]
+}`,
+	"/folder/list?sub": `{
+  "status": "success",
+  "content": [
+    {"id": "f2", "name": "NCOP.mkv", "type": "file", "size": 500, "link": "https://abcd.energycdn.com/dl/NCOP.mkv"}
+  ]
+}`,
+	"/transfer/create": `{
+  "status": "success",
+  "id": "Nw8bH1cV2xZ3aS4dF5gH6j",
+  "name": "Show 3",
+  "type": "torrent"
+}`,
+	"/transfer/directdl": `{
+  "status": "success",
+  "content": [
+    {"path": "Show/Show - 01.mkv", "size": 1000, "link": "https://abcd.energycdn.com/dl/Show01.mkv", "stream_link": null},
+    {"path": "Show/Show - 02.mkv", "size": 2000, "link": "https://abcd.energycdn.com/dl/Show02.mkv", "stream_link": null}
+  ]
+}`,
+	"/transfer/delete": `{
+  "status": "success"
+}`,
+}
+
+func newTestPremiumize(t *testing.T, apiKey string) *Premiumize {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("apikey") != "test-key" {
+			_, _ = w.Write([]byte(`{"status": "error", "message": "Not logged in."}`))
+			return
+		}
+
+		key := r.URL.Path
+		if key == "/folder/list" && r.FormValue("id") == "F0lder2dBBBBBBBBBBBBBB" {
+			key += "?sub"
+		}
+		resp, ok := recordedResponses[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(resp))
+	}))
+	t.Cleanup(server.Close)
+
+	return &Premiumize{
+		baseUrl: server.URL,
+		apiKey:  mo.Some(apiKey),
+		client:  server.Client(),
+		logger:  util.NewLogger(),
+	}
+}
+
+func TestPremiumize_GetInstantAvailability(t *testing.T) {
+	pm := newTestPremiumize(t, "test-key")
+
+	avail := pm.GetInstantAvailability([]string{
+		"80431b4f9a12f4e06616062d3d3973b9ef99b5e6",
+		"0000000000000000000000000000000000000000",
+		"a1b2c3d4e5f60718293a4b5c6d7e8f9012345678",
+	})
+
+	require.Len(t, avail, 2)
+	require.Equal(t, int64(1445813416), avail["80431b4f9a12f4e06616062d3d3973b9ef99b5e6"].CachedFiles["0"].Size)
+	require.Equal(t, int64(2000), avail["a1b2c3d4e5f60718293a4b5c6d7e8f9012345678"].CachedFiles["0"].Size)
+	require.NotContains(t, avail, "0000000000000000000000000000000000000000")
+}
+
+func TestPremiumize_AddTorrent(t *testing.T) {
+	pm := newTestPremiumize(t, "test-key")
+
+	// Already added, the hash of the magnet link is uppercase
+	id, err := pm.AddTorrent(debrid.AddTorrentOptions{
+		MagnetLink: "magnet:?xt=urn:btih:80431b4f9a12f4e06616062d3d3973b9ef99b5e6",
+		InfoHash:   "80431b4f9a12f4e06616062d3d3973b9ef99b5e6",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Xk3c5RzqZ9pPv3wXbW0g3A", id)
+
+	// Created
+	id, err = pm.AddTorrent(debrid.AddTorrentOptions{
+		MagnetLink: "magnet:?xt=urn:btih:c1b2c3d4e5f60718293a4b5c6d7e8f9012345678",
+		InfoHash:   "c1b2c3d4e5f60718293a4b5c6d7e8f9012345678",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Nw8bH1cV2xZ3aS4dF5gH6j", id)
+}
+
+func TestPremiumize_GetTorrents(t *testing.T) {
+	pm := newTestPremiumize(t, "test-key")
+
+	torrents, err := pm.GetTorrents()
+	require.NoError(t, err)
+	require.Len(t, torrents, 3)
+
+	require.Equal(t, "80431b4f9a12f4e06616062d3d3973b9ef99b5e6", torrents[0].Hash)
+	require.Equal(t, debrid.TorrentItemStatusCompleted, torrents[0].Status)
+	require.True(t, torrents[0].IsReady)
+	require.Equal(t, 100, torrents[0].CompletionPercentage)
+
+	require.Equal(t, debrid.TorrentItemStatusDownloading, torrents[1].Status)
+	require.Equal(t, 50, torrents[1].CompletionPercentage)
+	require.False(t, torrents[1].IsReady)
+}
+
+func TestPremiumize_GetTorrentDownloadUrl(t *testing.T) {
+	pm := newTestPremiumize(t, "test-key")
+
+	// Single file transfer
+	downloadUrl, err := pm.GetTorrentDownloadUrl(debrid.DownloadTorrentOptions{ID: "Xk3c5RzqZ9pPv3wXbW0g3A"})
+	require.NoError(t, err)
+	require.Equal(t, "https://abcd.energycdn.com/dl/Bocchi.mkv", downloadUrl)
+
+	// Folder transfer, sub-folders are included
+	downloadUrl, err = pm.GetTorrentDownloadUrl(debrid.DownloadTorrentOptions{ID: "Zp0aQ2wE3rT4yU5iO6pA7s"})
+	require.NoError(t, err)
+	require.Equal(t, "https://abcd.energycdn.com/dl/01.mkv,https://abcd.energycdn.com/dl/NCOP.mkv", downloadUrl)
+
+	// File selected by name
+	downloadUrl, err = pm.GetTorrentDownloadUrl(debrid.DownloadTorrentOptions{ID: "Zp0aQ2wE3rT4yU5iO6pA7s", FileId: "NCOP.mkv"})
+	require.NoError(t, err)
+	require.Equal(t, "https://abcd.energycdn.com/dl/NCOP.mkv", downloadUrl)
+
+	// Not ready
+	_, err = pm.GetTorrentDownloadUrl(debrid.DownloadTorrentOptions{ID: "Bq1fG7zT4mNc8vXyL2kP0w"})
+	require.Error(t, err)
+}
+
+func TestPremiumize_GetTorrentInfo(t *testing.T) {
+	pm := newTestPremiumize(t, "test-key")
+
+	info, err := pm.GetTorrentInfo(debrid.GetTorrentInfoOptions{
+		MagnetLink: "magnet:?xt=urn:btih:a1b2c3d4e5f60718293a4b5c6d7e8f9012345678&dn=Show",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Show", info.Name)
+	require.Equal(t, "a1b2c3d4e5f60718293a4b5c6d7e8f9012345678", info.Hash)
+	require.Equal(t, int64(3000), info.Size)
+	require.Len(t, info.Files, 2)
+	require.Equal(t, "Show - 02.mkv", info.Files[1].ID)
+	require.Equal(t, "/Show/Show - 02.mkv", info.Files[1].Path)
+}
+
+func TestPremiumize_DeleteTorrent(t *testing.T) {
+	pm := newTestPremiumize(t, "test-key")
+
+	err := pm.DeleteTorrent("Xk3c5RzqZ9pPv3wXbW0g3A")
+	require.NoError(t, err)
+}
+
+func TestPremiumize_InvalidApiKey(t *testing.T) {
+	pm := newTestPremiumize(t, "invalid")
+
+	_, err := pm.GetTorrents()
+	require.ErrorContains(t, err, "Not logged in.")
+}
@@ -47,8 +47,15 @@ const (
 	RefreshedMangaDownloadData  = "refreshed-manga-download-data"
 	ChapterDownloadQueueUpdated = "chapter-download-queue-updated"
 	OfflineSnapshotCreated      = "offline-snapshot-created"
+	MangaChapterUpdatesFound    = "manga-chapter-updates-found" // New chapters were found by the chapter update checker
 
-	MediastreamShutdownStream = "mediastream-shutdown-stream"
+	OnlinestreamDownloadQueueUpdated = "onlinestream-download-queue-updated"
+	OnlinestreamDownloadProgress     = "onlinestream-download-progress"
+	OnlinestreamEpisodeDownloaded    = "onlinestream-episode-downloaded"
+
+	MediastreamShutdownStream       = "mediastream-shutdown-stream"
+	MediastreamSourceReady          = "mediastream-source-ready"           // A torrent or debrid stream is ready to be played in the browser
+	MediastreamSourceSubtitlesReady = "mediastream-source-subtitles-ready" // The subtitles of a torrent or debrid stream have been extracted
 
 	ExtensionsReloaded    = "extensions-reloaded"
 	ExtensionUpdatesFound = "extension-updates-found"
@@ -64,6 +71,11 @@ const (
 	DebridDownloadProgress = "debrid-download-progress"
 	DebridStreamState      = "debrid-stream-state"
 
+	LibraryHealthCheckProgress  = "library-health-check-progress"  // Progress of the library health check
+	LibraryHealthCheckCompleted = "library-health-check-completed" // The library health check has finished
+	LibraryOrganizerProgress    = "library-organizer-progress"     // Progress of the library organizer
+	LibraryOrganizerCompleted   = "library-organizer-completed"    // The library organizer has finished
+
 	InvalidateQueries = "invalidate-queries"
 	ConsoleLog        = "console-log"
 	ConsoleWarn       = "console-warn"
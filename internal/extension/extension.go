@@ -20,6 +20,7 @@ const (
 	TypeAnimeTorrentProvider Type = "anime-torrent-provider"
 	TypeMangaProvider        Type = "manga-provider"
 	TypeOnlinestreamProvider Type = "onlinestream-provider"
+	TypeMetadataProvider     Type = "metadata-provider"
 	TypePlugin               Type = "plugin"
 )
 
@@ -67,6 +68,8 @@ type Extension struct {
 	PayloadURI string `json:"payloadURI,omitempty"`
 	// Plugin is the manifest of the extension if it is a plugin.
 	Plugin *PluginManifest `json:"plugin,omitempty"`
+	// Changelog of the extension, from the most recent version to the oldest.
+	Changelog []ChangelogEntry `json:"changelog,omitempty"`
 
 	// IsDevelopment is true if the extension is in development mode.
 	// If true, the extension code will be loaded from PayloadURI and allow you to edit the code from an editor and reload the extension without restarting the application.
@@ -75,6 +78,13 @@ type Extension struct {
 	SavedUserConfig *SavedUserConfig `json:"-"` // Contains the saved user config for the extension
 }
 
+type ChangelogEntry struct {
+	Version string `json:"version"` // e.g. "1.0.1"
+	// Date of the release, e.g. "2025-01-01"
+	Date  string `json:"date,omitempty"`
+	Notes string `json:"notes"`
+}
+
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
 // BaseExtension is the base interface for all extensions
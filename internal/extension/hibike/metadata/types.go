@@ -0,0 +1,83 @@
+package hibikemetadata
+
+type (
+	Provider interface {
+		// GetAnimeMetadata returns the metadata of the anime with the given ID.
+		// This includes titles, episode metadata and mappings to other databases.
+		GetAnimeMetadata(opts AnimeMetadataOptions) (*AnimeMetadata, error)
+		// GetSettings returns the provider settings.
+		GetSettings() Settings
+	}
+
+	AnimeMetadataOptions struct {
+		// Platform of the media ID.
+		// e.g. "anilist", "mal"
+		Platform string `json:"platform"`
+		// ID of the media on the platform.
+		MediaID int `json:"mediaId"`
+	}
+
+	Settings struct {
+		// Platforms supported by the provider.
+		// e.g. ["anilist", "mal"]
+		// Leave it empty if the provider supports all platforms.
+		SupportedPlatforms []string `json:"supportedPlatforms"`
+	}
+
+	AnimeMetadata struct {
+		// Titles of the anime, keyed by language code.
+		// e.g. { "en": "Attack on Titan", "x-jat": "Shingeki no Kyojin" }
+		Titles map[string]string `json:"titles"`
+		// Episodes of the anime, keyed by episode number.
+		// Specials should be prefixed with "S", e.g. "S1".
+		Episodes map[string]*EpisodeMetadata `json:"episodes"`
+		// Number of main episodes.
+		EpisodeCount int `json:"episodeCount"`
+		// Number of specials.
+		SpecialCount int `json:"specialCount"`
+		// Mappings to other databases.
+		// Can be nil if the provider does not provide mappings.
+		Mappings *AnimeMappings `json:"mappings,omitempty"`
+	}
+
+	EpisodeMetadata struct {
+		// e.g. "1", "S1"
+		Episode string `json:"episode"`
+		// Episode number.
+		EpisodeNumber int `json:"episodeNumber"`
+		// Episode number relative to the whole series, if different from the episode number.
+		AbsoluteEpisodeNumber int `json:"absoluteEpisodeNumber,omitempty"`
+		// Season number, if applicable.
+		SeasonNumber int `json:"seasonNumber,omitempty"`
+		// Episode title.
+		Title string `json:"title"`
+		// Episode thumbnail URL.
+		Image string `json:"image,omitempty"`
+		// Episode summary.
+		Summary string `json:"summary,omitempty"`
+		// Air date of the episode.
+		// e.g. "2024-01-01"
+		AirDate string `json:"airDate,omitempty"`
+		// Length of the episode in minutes.
+		Length int `json:"length,omitempty"`
+		// AniDB episode ID, if available.
+		AnidbEid int `json:"anidbEid,omitempty"`
+		// TVDB episode ID, if available.
+		TvdbId int `json:"tvdbId,omitempty"`
+	}
+
+	AnimeMappings struct {
+		AnimeplanetId string `json:"animeplanetId,omitempty"`
+		KitsuId       int    `json:"kitsuId,omitempty"`
+		MalId         int    `json:"malId,omitempty"`
+		Type          string `json:"type,omitempty"`
+		AnilistId     int    `json:"anilistId,omitempty"`
+		AnisearchId   int    `json:"anisearchId,omitempty"`
+		AnidbId       int    `json:"anidbId,omitempty"`
+		NotifymoeId   string `json:"notifymoeId,omitempty"`
+		LivechartId   int    `json:"livechartId,omitempty"`
+		ThetvdbId     int    `json:"thetvdbId,omitempty"`
+		ImdbId        string `json:"imdbId,omitempty"`
+		ThemoviedbId  string `json:"themoviedbId,omitempty"`
+	}
+)
@@ -0,0 +1,98 @@
+package extension
+
+import (
+	hibikemetadata "seanime/internal/extension/hibike/metadata"
+)
+
+type MetadataProviderExtension interface {
+	BaseExtension
+	GetProvider() hibikemetadata.Provider
+}
+
+type MetadataProviderExtensionImpl struct {
+	ext      *Extension
+	provider hibikemetadata.Provider
+}
+
+func NewMetadataProviderExtension(ext *Extension, provider hibikemetadata.Provider) MetadataProviderExtension {
+	return &MetadataProviderExtensionImpl{
+		ext:      ext,
+		provider: provider,
+	}
+}
+
+func (m *MetadataProviderExtensionImpl) GetProvider() hibikemetadata.Provider {
+	return m.provider
+}
+
+func (m *MetadataProviderExtensionImpl) GetExtension() *Extension {
+	return m.ext
+}
+
+func (m *MetadataProviderExtensionImpl) GetType() Type {
+	return m.ext.Type
+}
+
+func (m *MetadataProviderExtensionImpl) GetID() string {
+	return m.ext.ID
+}
+
+func (m *MetadataProviderExtensionImpl) GetName() string {
+	return m.ext.Name
+}
+
+func (m *MetadataProviderExtensionImpl) GetVersion() string {
+	return m.ext.Version
+}
+
+func (m *MetadataProviderExtensionImpl) GetManifestURI() string {
+	return m.ext.ManifestURI
+}
+
+func (m *MetadataProviderExtensionImpl) GetLanguage() Language {
+	return m.ext.Language
+}
+
+func (m *MetadataProviderExtensionImpl) GetLang() string {
+	return GetExtensionLang(m.ext.Lang)
+}
+
+func (m *MetadataProviderExtensionImpl) GetDescription() string {
+	return m.ext.Description
+}
+
+func (m *MetadataProviderExtensionImpl) GetAuthor() string {
+	return m.ext.Author
+}
+
+func (m *MetadataProviderExtensionImpl) GetPayload() string {
+	return m.ext.Payload
+}
+
+func (m *MetadataProviderExtensionImpl) GetWebsite() string {
+	return m.ext.Website
+}
+
+func (m *MetadataProviderExtensionImpl) GetIcon() string {
+	return m.ext.Icon
+}
+
+func (m *MetadataProviderExtensionImpl) GetPermissions() []string {
+	return m.ext.Permissions
+}
+
+func (m *MetadataProviderExtensionImpl) GetUserConfig() *UserConfig {
+	return m.ext.UserConfig
+}
+
+func (m *MetadataProviderExtensionImpl) GetSavedUserConfig() *SavedUserConfig {
+	return m.ext.SavedUserConfig
+}
+
+func (m *MetadataProviderExtensionImpl) GetPayloadURI() string {
+	return m.ext.PayloadURI
+}
+
+func (m *MetadataProviderExtensionImpl) GetIsDevelopment() bool {
+	return m.ext.IsDevelopment
+}
@@ -3,6 +3,7 @@ package extension
 import (
 	"crypto/sha256"
 	"fmt"
+	"slices"
 	"strings"
 )
 
@@ -21,6 +22,7 @@ var (
 	PluginPermissionNotification  PluginPermissionScope = "notification"   // Allows the plugin to use the notification manager
 	PluginPermissionDiscord       PluginPermissionScope = "discord"        // Allows the plugin to use the discord rpc
 	PluginPermissionTorrentClient PluginPermissionScope = "torrent-client" // Allows the plugin to use the torrent client
+	PluginPermissionHTTPEndpoints PluginPermissionScope = "http-endpoints" // Allows the plugin to expose HTTP endpoints under /api/v1/plugins/{id}
 )
 
 type PluginManifest struct {
@@ -28,11 +30,76 @@ type PluginManifest struct {
 	// Permissions is a list of permissions that the plugin is asking for.
 	// The user must acknowledge these permissions before the plugin can be loaded.
 	Permissions PluginPermissions `json:"permissions,omitempty"`
+	// Bus declares the topics and functions that the plugin shares with other plugins.
+	Bus PluginBus `json:"bus,omitempty"`
 }
 
 type PluginPermissions struct {
 	Scopes []PluginPermissionScope `json:"scopes,omitempty"`
 	Allow  PluginAllowlist         `json:"allow,omitempty"`
+	// Plugins is a list of other plugins that the plugin is asking to access through the plugin bus.
+	Plugins []PluginAccess `json:"plugins,omitempty"`
+}
+
+// PluginBus declares what a plugin shares with other plugins through the plugin bus.
+// Other plugins can only subscribe to declared topics and call declared functions.
+type PluginBus struct {
+	// Topics is a list of topics that the plugin publishes events to.
+	Topics []PluginBusTopic `json:"topics,omitempty"`
+	// Exports is a list of functions that other plugins can call.
+	Exports []string `json:"exports,omitempty"`
+}
+
+// PluginBusTopic is a topic that a plugin publishes events to.
+type PluginBusTopic struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Schema maps the fields of the payload to their type.
+	// Types are "string", "number", "boolean", "object", "array" and "any", a trailing "?" makes the field optional.
+	// If empty, the payload is not validated.
+	Schema map[string]string `json:"schema,omitempty"`
+}
+
+// PluginAccess is the access a plugin asks for to another plugin's topics and functions.
+//
+// The user must acknowledge these permissions before the plugin can be loaded.
+type PluginAccess struct {
+	// ID is the extension ID of the other plugin.
+	ID string `json:"id"`
+	// Topics is a list of topics of the other plugin that the plugin subscribes to.
+	Topics []string `json:"topics,omitempty"`
+	// Functions is a list of functions exported by the other plugin that the plugin calls.
+	Functions []string `json:"functions,omitempty"`
+}
+
+// GetTopic returns the topic with the given name.
+func (b *PluginBus) GetTopic(name string) (*PluginBusTopic, bool) {
+	for i := range b.Topics {
+		if b.Topics[i].Name == name {
+			return &b.Topics[i], true
+		}
+	}
+	return nil, false
+}
+
+// CanSubscribe returns true if the plugin is allowed to subscribe to the topic of the other plugin.
+func (p *PluginPermissions) CanSubscribe(pluginId string, topic string) bool {
+	for _, access := range p.Plugins {
+		if access.ID == pluginId && slices.Contains(access.Topics, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanCall returns true if the plugin is allowed to call the function exported by the other plugin.
+func (p *PluginPermissions) CanCall(pluginId string, function string) bool {
+	for _, access := range p.Plugins {
+		if access.ID == pluginId && slices.Contains(access.Functions, function) {
+			return true
+		}
+	}
+	return false
 }
 
 // PluginAllowlist is a list of system permissions that the plugin is asking for.
@@ -129,7 +196,8 @@ func (p *PluginPermissions) GetHash() string {
 	if len(p.Scopes) == 0 &&
 		len(p.Allow.ReadPaths) == 0 &&
 		len(p.Allow.WritePaths) == 0 &&
-		len(p.Allow.CommandScopes) == 0 {
+		len(p.Allow.CommandScopes) == 0 &&
+		len(p.Plugins) == 0 {
 		return ""
 	}
 
@@ -158,6 +226,17 @@ func (p *PluginPermissions) GetHash() string {
 		}
 	}
 
+	// Hash plugin access
+	for _, access := range p.Plugins {
+		h.Write([]byte("plugin:" + access.ID))
+		for _, topic := range access.Topics {
+			h.Write([]byte("topic:" + topic))
+		}
+		for _, function := range access.Functions {
+			h.Write([]byte("function:" + function))
+		}
+	}
+
 	return fmt.Sprintf("%x", h.Sum(nil))
 }
 
@@ -170,7 +249,8 @@ func (p *PluginPermissions) GetDescription() string {
 	if len(p.Scopes) == 0 &&
 		len(p.Allow.ReadPaths) == 0 &&
 		len(p.Allow.WritePaths) == 0 &&
-		len(p.Allow.CommandScopes) == 0 {
+		len(p.Allow.CommandScopes) == 0 &&
+		len(p.Plugins) == 0 {
 		return "No permissions requested."
 	}
 
@@ -200,6 +280,8 @@ func (p *PluginPermissions) GetDescription() string {
 				desc.WriteString("Notification: Send system notifications\n")
 			case PluginPermissionDiscord:
 				desc.WriteString("Discord: Set Discord Rich Presence\n")
+			case PluginPermissionHTTPEndpoints:
+				desc.WriteString("HTTP Endpoints: Expose HTTP endpoints on the server\n")
 			default:
 				desc.WriteString(string(scope) + "\n")
 			}
@@ -258,6 +340,21 @@ func (p *PluginPermissions) GetDescription() string {
 
 			desc.WriteString(cmdDesc + "\n")
 		}
+		desc.WriteString("\n")
+	}
+
+	// Add plugin access if any exist
+	if len(p.Plugins) > 0 {
+		desc.WriteString("Other Plugins:\n")
+		for _, access := range p.Plugins {
+			desc.WriteString("• " + access.ID + "\n")
+			if len(access.Topics) > 0 {
+				desc.WriteString("\t  - Receive events: " + strings.Join(access.Topics, ", ") + "\n")
+			}
+			if len(access.Functions) > 0 {
+				desc.WriteString("\t  - Call functions: " + strings.Join(access.Functions, ", ") + "\n")
+			}
+		}
 	}
 
 	return strings.TrimSpace(desc.String())
@@ -4,6 +4,7 @@ import (
 	"seanime/internal/events"
 	"seanime/internal/extension"
 	hibikemanga "seanime/internal/extension/hibike/manga"
+	hibikemetadata "seanime/internal/extension/hibike/metadata"
 	hibikeonlinestream "seanime/internal/extension/hibike/onlinestream"
 	hibiketorrent "seanime/internal/extension/hibike/torrent"
 )
@@ -115,6 +116,19 @@ func (r *Repository) loadBuiltInExtension(ext extension.Extension, provider inte
 		case extension.LanguageJavascript, extension.LanguageTypescript:
 			r.loadBuiltInOnlinestreamProviderExtensionJS(ext)
 		}
+	case extension.TypeMetadataProvider:
+		switch ext.Language {
+		// Go
+		case extension.LanguageGo:
+			if provider == nil {
+				r.logger.Error().Str("id", ext.ID).Msg("extensions: Built-in metadata provider extension requires a provider")
+				return
+			}
+			saveUserConfigInProvider(&ext, provider)
+			if metadataProvider, ok := provider.(hibikemetadata.Provider); ok {
+				r.loadBuiltInMetadataProviderExtension(ext, metadataProvider)
+			}
+		}
 	case extension.TypePlugin:
 		// TODO: Implement
 	}
@@ -138,6 +152,11 @@ func (r *Repository) loadBuiltInOnlinestreamProviderExtension(ext extension.Exte
 	r.logger.Debug().Str("id", ext.ID).Msg("extensions: Loaded built-in onlinestream provider extension")
 }
 
+func (r *Repository) loadBuiltInMetadataProviderExtension(ext extension.Extension, provider hibikemetadata.Provider) {
+	r.extensionBank.Set(ext.ID, extension.NewMetadataProviderExtension(&ext, provider))
+	r.logger.Debug().Str("id", ext.ID).Msg("extensions: Loaded built-in metadata provider extension")
+}
+
 func (r *Repository) loadBuiltInOnlinestreamProviderExtensionJS(ext extension.Extension) {
 	// Load the extension as if it was an external extension
 	err := r.loadExternalOnlinestreamExtensionJS(&ext, ext.Language)
@@ -134,6 +134,11 @@ func (r *Repository) InstallExternalExtension(manifestURI string) (*ExtensionIns
 		return nil, fmt.Errorf("failed to fetch extension data, %w", err)
 	}
 
+	// Pinned extensions are held at their version
+	if err := r.checkPinnedVersion(ext.ID, ext.Version); err != nil {
+		return nil, err
+	}
+
 	filename := filepath.Join(r.extensionDir, ext.ID+".json")
 
 	update := false
@@ -142,6 +147,10 @@ func (r *Repository) InstallExternalExtension(manifestURI string) (*ExtensionIns
 	// i.e. a file with the same ID exists
 	if _, err := os.Stat(filename); err == nil {
 		r.logger.Debug().Str("id", ext.ID).Msg("extensions: Updating extension")
+		// Keep the old extension so that it can be restored
+		if err := r.backupExtension(ext.ID); err != nil {
+			r.logger.Warn().Err(err).Str("id", ext.ID).Msg("extensions: Failed to back up old extension")
+		}
 		// Delete the old extension
 		err := os.Remove(filename)
 		if err != nil {
@@ -173,6 +182,15 @@ func (r *Repository) InstallExternalExtension(manifestURI string) (*ExtensionIns
 	r.reloadExtension(ext.ID)
 
 	if update {
+		// Restore the previous version if the new one fails to load
+		if r.hasFailedToLoad(ext.ID) && r.HasExtensionBackup(ext.ID) {
+			r.logger.Warn().Str("id", ext.ID).Str("version", ext.Version).Msg("extensions: New version failed to load, rolling back")
+			if _, err := r.RollbackExtension(ext.ID); err != nil {
+				return nil, fmt.Errorf("failed to load %s %s and could not roll back, %w", ext.Name, ext.Version, err)
+			}
+			return nil, fmt.Errorf("failed to load %s %s, the previous version was restored", ext.Name, ext.Version)
+		}
+
 		r.updateDataMu.Lock()
 		r.updateData = lo.Filter(r.updateData, func(item UpdateData, _ int) bool {
 			return item.ExtensionID != ext.ID
@@ -214,6 +232,8 @@ func (r *Repository) UninstallExternalExtension(id string) error {
 
 	go func() {
 		_ = r.deleteExtensionUserConfig(id)
+		r.deleteExtensionBackup(id)
+		r.removeExtensionUpdatePolicy(id)
 
 		// Delete the plugin data if it was a plugin
 		if ext.Type == extension.TypePlugin {
@@ -240,6 +260,8 @@ func (r *Repository) checkForUpdates() (ret []UpdateData) {
 
 	r.logger.Trace().Msg("extensions: Checking for updates")
 
+	updatePolicies := r.ListExtensionUpdatePolicies()
+
 	// Check for updates for all extensions
 	r.extensionBank.Range(func(key string, ext extension.BaseExtension) bool {
 		wg.Add(1)
@@ -251,6 +273,11 @@ func (r *Repository) checkForUpdates() (ret []UpdateData) {
 				return
 			}
 
+			// Skip pinned extensions
+			if policy, found := updatePolicies[ext.GetID()]; found && policy != nil && policy.PinnedVersion != "" {
+				return
+			}
+
 			// Get the extension data from the repository
 			extFromRepo, err := r.fetchExternalExtensionData(ext.GetManifestURI())
 			if err != nil {
@@ -273,9 +300,11 @@ func (r *Repository) checkForUpdates() (ret []UpdateData) {
 			if extFromRepo.Version != ext.GetVersion() {
 				mu.Lock()
 				ret = append(ret, UpdateData{
-					ExtensionID: extFromRepo.ID,
-					Version:     extFromRepo.Version,
-					ManifestURI: extFromRepo.ManifestURI,
+					ExtensionID:    extFromRepo.ID,
+					Version:        extFromRepo.Version,
+					ManifestURI:    extFromRepo.ManifestURI,
+					CurrentVersion: ext.GetVersion(),
+					Changelog:      getChangelogSince(extFromRepo.Changelog, ext.GetVersion()),
 				})
 				mu.Unlock()
 			}
@@ -599,6 +628,9 @@ func (r *Repository) loadExternalExtension(filePath string) {
 	case extension.TypeAnimeTorrentProvider:
 		// Load torrent provider
 		loadingErr = r.loadExternalAnimeTorrentProviderExtension(ext)
+	case extension.TypeMetadataProvider:
+		// Load metadata provider
+		loadingErr = r.loadExternalMetadataProviderExtension(ext)
 	case extension.TypePlugin:
 		// Load plugin
 		loadingErr = r.loadPlugin(ext)
@@ -0,0 +1,41 @@
+package extension_repo
+
+import (
+	"fmt"
+	"seanime/internal/extension"
+	"seanime/internal/util"
+)
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Metadata
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (r *Repository) loadExternalMetadataProviderExtension(ext *extension.Extension) (err error) {
+	defer util.HandlePanicInModuleWithError("extension_repo/loadExternalMetadataProviderExtension", &err)
+
+	switch ext.Language {
+	case extension.LanguageJavascript, extension.LanguageTypescript:
+		err = r.loadExternalMetadataProviderExtensionJS(ext, ext.Language)
+	default:
+		err = fmt.Errorf("unsupported language: %v", ext.Language)
+	}
+
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (r *Repository) loadExternalMetadataProviderExtensionJS(ext *extension.Extension, language extension.Language) error {
+	provider, gojaExt, err := NewGojaMetadataProvider(ext, language, r.logger, r.gojaRuntimeManager)
+	if err != nil {
+		return err
+	}
+
+	// Add the extension to the map
+	retExt := extension.NewMetadataProviderExtension(ext, provider)
+	r.extensionBank.Set(ext.ID, retExt)
+	r.gojaExtensions.Set(ext.ID, gojaExt)
+	return nil
+}
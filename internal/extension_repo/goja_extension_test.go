@@ -4,6 +4,7 @@ import (
 	"os"
 	"seanime/internal/extension"
 	hibikemanga "seanime/internal/extension/hibike/manga"
+	hibikemetadata "seanime/internal/extension/hibike/metadata"
 	hibikeonlinestream "seanime/internal/extension/hibike/onlinestream"
 	"seanime/internal/extension_repo"
 	"seanime/internal/goja/goja_runtime"
@@ -160,3 +161,38 @@ func TestGojaOnlinestreamExtension2(t *testing.T) {
 
 	spew.Dump(server)
 }
+
+func TestGojaMetadataProvider(t *testing.T) {
+	runtimeManager := goja_runtime.NewManager(util.NewLogger())
+	fileB, err := os.ReadFile("./goja_metadata_test/my-metadata-provider.ts")
+	require.NoError(t, err)
+
+	ext := &extension.Extension{
+		ID:          "my-metadata-provider",
+		Name:        "MyMetadataProvider",
+		Version:     "0.1.0",
+		ManifestURI: "",
+		Language:    extension.LanguageTypescript,
+		Type:        extension.TypeMetadataProvider,
+		Payload:     string(fileB),
+	}
+
+	provider, _, err := extension_repo.NewGojaMetadataProvider(ext, ext.Language, util.NewLogger(), runtimeManager)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"anilist"}, provider.GetSettings().SupportedPlatforms)
+
+	res, err := provider.GetAnimeMetadata(hibikemetadata.AnimeMetadataOptions{
+		Platform: "anilist",
+		MediaID:  21,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, "Media 21", res.Titles["en"])
+	require.Equal(t, 3, res.EpisodeCount)
+	require.Len(t, res.Episodes, 3)
+	require.Equal(t, "Episode 2", res.Episodes["2"].Title)
+	require.Equal(t, 24, res.Episodes["2"].Length)
+	require.NotNil(t, res.Mappings)
+	require.Equal(t, 21, res.Mappings.AnilistId)
+}
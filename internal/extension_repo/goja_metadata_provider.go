@@ -0,0 +1,71 @@
+package extension_repo
+
+import (
+	"context"
+	"fmt"
+	"seanime/internal/extension"
+	hibikemetadata "seanime/internal/extension/hibike/metadata"
+	"seanime/internal/goja/goja_runtime"
+	"seanime/internal/util"
+
+	"github.com/rs/zerolog"
+)
+
+type GojaMetadataProvider struct {
+	*gojaProviderBase
+}
+
+func NewGojaMetadataProvider(ext *extension.Extension, language extension.Language, logger *zerolog.Logger, runtimeManager *goja_runtime.Manager) (hibikemetadata.Provider, *GojaMetadataProvider, error) {
+	base, err := initializeProviderBase(ext, language, logger, runtimeManager)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	provider := &GojaMetadataProvider{
+		gojaProviderBase: base,
+	}
+	return provider, provider, nil
+}
+
+func (g *GojaMetadataProvider) GetAnimeMetadata(opts hibikemetadata.AnimeMetadataOptions) (ret *hibikemetadata.AnimeMetadata, err error) {
+	defer util.HandlePanicInModuleWithError(g.ext.ID+".GetAnimeMetadata", &err)
+
+	method, err := g.callClassMethod(context.Background(), "getAnimeMetadata", structToMap(opts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to call getAnimeMetadata method: %w", err)
+	}
+
+	promiseRes, err := g.waitForPromise(method)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait for promise: %w", err)
+	}
+
+	err = g.unmarshalValue(promiseRes, &ret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal anime metadata: %w", err)
+	}
+
+	if ret == nil {
+		return nil, fmt.Errorf("no metadata returned")
+	}
+
+	return ret, nil
+}
+
+func (g *GojaMetadataProvider) GetSettings() (ret hibikemetadata.Settings) {
+	defer util.HandlePanicInModuleThen(g.ext.ID+".GetSettings", func() {
+		ret = hibikemetadata.Settings{}
+	})
+
+	method, err := g.callClassMethod(context.Background(), "getSettings")
+	if err != nil {
+		return
+	}
+
+	err = g.unmarshalValue(method, &ret)
+	if err != nil {
+		return
+	}
+
+	return
+}
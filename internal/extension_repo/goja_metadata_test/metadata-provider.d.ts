@@ -0,0 +1,51 @@
+declare type AnimeMetadataOptions = {
+    platform: "anilist" | "mal"
+    mediaId: number
+}
+
+declare type AnimeMetadata = {
+    titles: { [lang: string]: string }
+    episodes: { [episode: string]: EpisodeMetadata }
+    episodeCount: number
+    specialCount: number
+    mappings?: AnimeMappings
+}
+
+declare type EpisodeMetadata = {
+    episode: string
+    episodeNumber: number
+    absoluteEpisodeNumber?: number
+    seasonNumber?: number
+    title: string
+    image?: string
+    summary?: string
+    airDate?: string
+    length?: number
+    anidbEid?: number
+    tvdbId?: number
+}
+
+declare type AnimeMappings = {
+    animeplanetId?: string
+    kitsuId?: number
+    malId?: number
+    type?: string
+    anilistId?: number
+    anisearchId?: number
+    anidbId?: number
+    notifymoeId?: string
+    livechartId?: number
+    thetvdbId?: number
+    imdbId?: string
+    themoviedbId?: string
+}
+
+declare type Settings = {
+    supportedPlatforms: string[]
+}
+
+declare abstract class MetadataProvider {
+    getAnimeMetadata(opts: AnimeMetadataOptions): Promise<AnimeMetadata>
+
+    getSettings(): Settings
+}
@@ -0,0 +1,33 @@
+/// <reference path="./metadata-provider.d.ts" />
+
+class Provider {
+
+    getSettings(): Settings {
+        return {
+            supportedPlatforms: ["anilist"],
+        }
+    }
+
+    async getAnimeMetadata(opts: AnimeMetadataOptions): Promise<AnimeMetadata> {
+        const episodes: { [episode: string]: EpisodeMetadata } = {}
+        for (let i = 1; i <= 3; i++) {
+            episodes[i.toString()] = {
+                episode: i.toString(),
+                episodeNumber: i,
+                title: `Episode ${i}`,
+                summary: `Summary of episode ${i}`,
+                length: 24,
+            }
+        }
+
+        return {
+            titles: { "en": `Media ${opts.mediaId}` },
+            episodes: episodes,
+            episodeCount: 3,
+            specialCount: 0,
+            mappings: {
+                anilistId: opts.mediaId,
+            },
+        }
+    }
+}
@@ -43,6 +43,7 @@ type TestPluginOptions struct {
 	Payload     string
 	Language    extension.Language
 	Permissions extension.PluginPermissions
+	Bus         extension.PluginBus
 	PoolSize    int
 	SetupHooks  bool
 }
@@ -75,13 +76,14 @@ func InitTestPlugin(t testing.TB, opts TestPluginOptions) (*GojaPlugin, *zerolog
 		Plugin:   &extension.PluginManifest{},
 	}
 
-	if len(opts.Permissions.Scopes) > 0 {
+	if len(opts.Permissions.Scopes) > 0 || len(opts.Permissions.Plugins) > 0 {
 		ext.Plugin = &extension.PluginManifest{
 			Permissions: opts.Permissions,
 		}
 	}
 
 	ext.Plugin.Permissions.Allow = opts.Permissions.Allow
+	ext.Plugin.Bus = opts.Bus
 
 	logger := util.NewLogger()
 	wsEventManager := events.NewMockWSEventManager(logger)
@@ -615,6 +617,147 @@ func TestGojaPluginTryCatch(t *testing.T) {
 
 /////////////////////////////////////////////////////////////////////////////////////////////
 
+func TestGojaPluginHTTPEndpoints(t *testing.T) {
+	payload := `
+	function init() {
+
+		$ui.register((ctx) => {
+			ctx.http.get("/items/:id", (req, res) => {
+				res.header("X-Plugin", "test").json({ id: req.params.id, q: req.query.q })
+			})
+
+			ctx.http.post("/items", async (req, res) => {
+				const body = req.json()
+				await new Promise(resolve => ctx.setTimeout(resolve, 100))
+				res.status(201)
+				return { name: body.name }
+			})
+
+			ctx.http.delete("/items/:id", (req, res) => {
+				throw new Error("not allowed")
+			})
+		})
+
+	}
+	`
+
+	opts := DefaultTestPluginOptions()
+	opts.Payload = payload
+	opts.Permissions = extension.PluginPermissions{
+		Scopes: []extension.PluginPermissionScope{
+			extension.PluginPermissionHTTPEndpoints,
+		},
+	}
+
+	p, _, _, _, _, err := InitTestPlugin(t, opts)
+	require.NoError(t, err)
+
+	res, err := plugin.GlobalEndpointRegistry.Serve(opts.ID, &plugin.EndpointRequest{
+		Method: http.MethodGet,
+		Path:   "/items/42",
+		Query:  map[string]string{"q": "search"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.Status)
+	require.Equal(t, "test", res.Headers["X-Plugin"])
+	require.JSONEq(t, `{"id": "42", "q": "search"}`, string(res.Body))
+
+	res, err = plugin.GlobalEndpointRegistry.Serve(opts.ID, &plugin.EndpointRequest{
+		Method: http.MethodPost,
+		Path:   "/items",
+		Body:   []byte(`{"name": "Bocchi"}`),
+	})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, res.Status)
+	require.JSONEq(t, `{"name": "Bocchi"}`, string(res.Body))
+
+	_, err = plugin.GlobalEndpointRegistry.Serve(opts.ID, &plugin.EndpointRequest{
+		Method: http.MethodDelete,
+		Path:   "/items/42",
+	})
+	require.ErrorContains(t, err, "not allowed")
+
+	// The endpoints are removed when the plugin is unloaded
+	p.ClearInterrupt()
+	_, err = plugin.GlobalEndpointRegistry.Serve(opts.ID, &plugin.EndpointRequest{
+		Method: http.MethodGet,
+		Path:   "/items/42",
+	})
+	require.ErrorIs(t, err, plugin.ErrEndpointNotFound)
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////
+
+func TestGojaPluginBus(t *testing.T) {
+	publisherPayload := `
+	function init() {
+
+		$ui.register((ctx) => {
+			ctx.bus.export("getStats", async (mediaId) => {
+				await new Promise(resolve => ctx.setTimeout(resolve, 100))
+				return { mediaId, watched: 12 }
+			})
+
+			ctx.setTimeout(() => {
+				ctx.bus.publish("stats-updated", { watched: 12 })
+			}, 200)
+		})
+
+	}
+	`
+
+	subscriberPayload := `
+	function init() {
+
+		$ui.register((ctx) => {
+			ctx.bus.subscribe("stats-plugin", "stats-updated", (event) => {
+				$store.set("event", event.payload)
+			})
+
+			ctx.bus.call("stats-plugin", "getStats", 21).then((stats) => {
+				$store.set("stats", stats)
+			})
+
+			try {
+				ctx.bus.subscribe("stats-plugin", "private", () => {})
+			} catch (e) {
+				$store.set("error", e.message)
+			}
+		})
+
+	}
+	`
+
+	publisherOpts := DefaultTestPluginOptions()
+	publisherOpts.ID = "stats-plugin"
+	publisherOpts.Payload = publisherPayload
+	publisherOpts.Bus = extension.PluginBus{
+		Topics:  []extension.PluginBusTopic{{Name: "stats-updated", Schema: map[string]string{"watched": "number"}}, {Name: "private"}},
+		Exports: []string{"getStats"},
+	}
+
+	subscriberOpts := DefaultTestPluginOptions()
+	subscriberOpts.ID = "tracker-plugin"
+	subscriberOpts.Payload = subscriberPayload
+	subscriberOpts.SetupHooks = false
+	subscriberOpts.Permissions = extension.PluginPermissions{
+		Plugins: []extension.PluginAccess{{ID: "stats-plugin", Topics: []string{"stats-updated"}, Functions: []string{"getStats"}}},
+	}
+
+	_, _, _, _, _, err := InitTestPlugin(t, publisherOpts)
+	require.NoError(t, err)
+	subscriber, _, _, _, _, err := InitTestPlugin(t, subscriberOpts)
+	require.NoError(t, err)
+
+	time.Sleep(time.Second)
+
+	require.Equal(t, map[string]interface{}{"watched": float64(12)}, subscriber.store.Get("event"))
+	require.Equal(t, map[string]interface{}{"mediaId": float64(21), "watched": float64(12)}, subscriber.store.Get("stats"))
+	require.Contains(t, subscriber.store.Get("error"), "Access to plugin denied")
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////
+
 func TestGojaSharedMemory(t *testing.T) {
 	payload := `
 	function init() {
@@ -572,6 +572,74 @@ declare namespace $app {
     }
 
 
+    /**
+     * @package chapter_downloader
+     */
+
+    /**
+     * @event MangaChapterDownloadQueuedEvent
+     * @file internal/manga/downloader/hook_events.go
+     * @description
+     * MangaChapterDownloadQueuedEvent is triggered when a chapter is about to be added to the download queue.
+     * The pages can be modified.
+     * Prevent default to skip adding the chapter to the queue.
+     */
+    function onMangaChapterDownloadQueued(cb: (event: MangaChapterDownloadQueuedEvent) => void): void;
+
+    interface MangaChapterDownloadQueuedEvent {
+        next(): void;
+
+        preventDefault(): void;
+
+        provider: string;
+        mediaId: number;
+        chapterId: string;
+        chapterNumber: string;
+        pages?: Array<HibikeManga_ChapterPage>;
+    }
+
+    /**
+     * @event MangaChapterDownloadRequestedEvent
+     * @file internal/manga/downloader/hook_events.go
+     * @description
+     * MangaChapterDownloadRequestedEvent is triggered when a chapter of the queue is about to be downloaded.
+     * The destination directory of the pages can be modified.
+     * Seanime only lists the chapters stored in the download directory, a chapter downloaded elsewhere will not appear as downloaded.
+     * Prevent default to skip the download, the chapter will be removed from the queue.
+     */
+    function onMangaChapterDownloadRequested(cb: (event: MangaChapterDownloadRequestedEvent) => void): void;
+
+    interface MangaChapterDownloadRequestedEvent {
+        next(): void;
+
+        preventDefault(): void;
+
+        provider: string;
+        mediaId: number;
+        chapterId: string;
+        chapterNumber: string;
+        destination: string;
+    }
+
+    /**
+     * @event MangaChapterDownloadedEvent
+     * @file internal/manga/downloader/hook_events.go
+     * @description
+     * MangaChapterDownloadedEvent is triggered after a chapter has been downloaded.
+     */
+    function onMangaChapterDownloaded(cb: (event: MangaChapterDownloadedEvent) => void): void;
+
+    interface MangaChapterDownloadedEvent {
+        next(): void;
+
+        provider: string;
+        mediaId: number;
+        chapterId: string;
+        chapterNumber: string;
+        destination: string;
+    }
+
+
     /**
      * @package continuity
      */
@@ -581,8 +649,7 @@ declare namespace $app {
      * @file internal/continuity/hook_events.go
      * @description
      * WatchHistoryItemRequestedEvent is triggered when a watch history item is requested.
-     * Prevent default to skip getting the watch history item from the file cache, in this case the event should have a valid WatchHistoryItem object
-     *     or set it to nil to indicate that the watch history item was not found.
+     * Prevent default to skip getting the watch history item from the file cache, in this case the event should have a valid WatchHistoryItem object or set it to nil to indicate that the watch history item was not found.
      */
     function onWatchHistoryItemRequested(cb: (event: WatchHistoryItemRequestedEvent) => void): void;
 
@@ -729,10 +796,10 @@ declare namespace $app {
      * @event DiscordPresenceAnimeActivityRequestedEvent
      * @file internal/discordrpc/presence/hook_events.go
      * @description
-     * DiscordPresenceAnimeActivityRequestedEvent is triggered when anime activity is requested, after the [animeActivity] is processed, and right
-     *     before the activity is sent to queue. There is no guarantee as to when or if the activity will be successfully sent to discord. Note that
-     *     this event is triggered every 6 seconds or so, avoid heavy processing or perform it only when the activity is changed. Prevent default to
-     *     stop the activity from being sent to discord.
+     * DiscordPresenceAnimeActivityRequestedEvent is triggered when anime activity is requested, after the [animeActivity] is processed, and right before the activity is sent to queue.
+     * There is no guarantee as to when or if the activity will be successfully sent to discord.
+     * Note that this event is triggered every 6 seconds or so, avoid heavy processing or perform it only when the activity is changed.
+     * Prevent default to stop the activity from being sent to discord.
      */
     function onDiscordPresenceAnimeActivityRequested(cb: (event: DiscordPresenceAnimeActivityRequestedEvent) => void): void;
 
@@ -759,10 +826,10 @@ declare namespace $app {
      * @event DiscordPresenceMangaActivityRequestedEvent
      * @file internal/discordrpc/presence/hook_events.go
      * @description
-     * DiscordPresenceMangaActivityRequestedEvent is triggered when manga activity is requested, after the [mangaActivity] is processed, and right
-     *     before the activity is sent to queue. There is no guarantee as to when or if the activity will be successfully sent to discord. Note that
-     *     this event is triggered every 6 seconds or so, avoid heavy processing or perform it only when the activity is changed. Prevent default to
-     *     stop the activity from being sent to discord.
+     * DiscordPresenceMangaActivityRequestedEvent is triggered when manga activity is requested, after the [mangaActivity] is processed, and right before the activity is sent to queue.
+     * There is no guarantee as to when or if the activity will be successfully sent to discord.
+     * Note that this event is triggered every 6 seconds or so, avoid heavy processing or perform it only when the activity is changed.
+     * Prevent default to stop the activity from being sent to discord.
      */
     function onDiscordPresenceMangaActivityRequested(cb: (event: DiscordPresenceMangaActivityRequestedEvent) => void): void;
 
@@ -1010,6 +1077,46 @@ declare namespace $app {
     }
 
 
+    /**
+     * @package mediastream
+     */
+
+    /**
+     * @event MediastreamPlaybackRequestedEvent
+     * @file internal/mediastream/hook_events.go
+     * @description
+     * MediastreamPlaybackRequestedEvent is triggered when the playback of a file or stream is requested.
+     * SourceId is empty for local files, and Filepath is the URL of the stream otherwise.
+     * The file path can be modified.
+     * Prevent default to refuse the playback, an error will be returned to the client.
+     */
+    function onMediastreamPlaybackRequested(cb: (event: MediastreamPlaybackRequestedEvent) => void): void;
+
+    interface MediastreamPlaybackRequestedEvent {
+        next(): void;
+
+        preventDefault(): void;
+
+        filepath: string;
+        sourceId: string;
+        streamType: Mediastream_StreamType;
+    }
+
+    /**
+     * @event MediastreamMediaContainerEvent
+     * @file internal/mediastream/hook_events.go
+     * @description
+     * MediastreamMediaContainerEvent is triggered when the media container is being returned to the client.
+     */
+    function onMediastreamMediaContainer(cb: (event: MediastreamMediaContainerEvent) => void): void;
+
+    interface MediastreamMediaContainerEvent {
+        next(): void;
+
+        mediaContainer?: Mediastream_MediaContainer;
+    }
+
+
     /**
      * @package metadata
      */
@@ -1078,9 +1185,9 @@ declare namespace $app {
      * @file internal/api/metadata/hook_events.go
      * @description
      * AnimeEpisodeMetadataEvent is triggered when anime episode metadata is available and is about to be returned.
-     * In the current implementation, episode metadata is requested for display purposes. It is used to get a more complete metadata object since the
-     *     original AnimeMetadata object is not complete. This event is triggered after [AnimeEpisodeMetadataRequestedEvent]. If the modified episode
-     *     metadata is nil, an empty EpisodeMetadata object will be returned.
+     * In the current implementation, episode metadata is requested for display purposes. It is used to get a more complete metadata object since the original AnimeMetadata object is not complete.
+     * This event is triggered after [AnimeEpisodeMetadataRequestedEvent].
+     * If the modified episode metadata is nil, an empty EpisodeMetadata object will be returned.
      */
     function onAnimeEpisodeMetadata(cb: (event: AnimeEpisodeMetadataEvent) => void): void;
 
@@ -1093,6 +1200,89 @@ declare namespace $app {
     }
 
 
+    /**
+     * @package onlinestream
+     */
+
+    /**
+     * @event OnlinestreamEpisodeListRequestedEvent
+     * @file internal/onlinestream/hook_events.go
+     * @description
+     * OnlinestreamEpisodeListRequestedEvent is triggered when the episode list of a media is requested from a provider.
+     * Prevent default to skip the default behavior and return the modified episodes.
+     * If the modified episodes are nil, an error will be returned.
+     */
+    function onOnlinestreamEpisodeListRequested(cb: (event: OnlinestreamEpisodeListRequestedEvent) => void): void;
+
+    interface OnlinestreamEpisodeListRequestedEvent {
+        next(): void;
+
+        preventDefault(): void;
+
+        provider: string;
+        media?: AL_BaseAnime;
+        dubbed: boolean;
+        episodes?: Array<Onlinestream_Episode>;
+    }
+
+    /**
+     * @event OnlinestreamEpisodeListEvent
+     * @file internal/onlinestream/hook_events.go
+     * @description
+     * OnlinestreamEpisodeListEvent is triggered when the episode list is being returned.
+     */
+    function onOnlinestreamEpisodeList(cb: (event: OnlinestreamEpisodeListEvent) => void): void;
+
+    interface OnlinestreamEpisodeListEvent {
+        next(): void;
+
+        provider: string;
+        media?: AL_BaseAnime;
+        dubbed: boolean;
+        episodes?: Array<Onlinestream_Episode>;
+    }
+
+    /**
+     * @event OnlinestreamEpisodeSourceRequestedEvent
+     * @file internal/onlinestream/hook_events.go
+     * @description
+     * OnlinestreamEpisodeSourceRequestedEvent is triggered when the video sources of an episode are requested.
+     * This event happens before the providers are queried.
+     * Prevent default to skip the default behavior and return the modified episode source.
+     * If the modified episode source is nil, an error will be returned.
+     */
+    function onOnlinestreamEpisodeSourceRequested(cb: (event: OnlinestreamEpisodeSourceRequestedEvent) => void): void;
+
+    interface OnlinestreamEpisodeSourceRequestedEvent {
+        next(): void;
+
+        preventDefault(): void;
+
+        provider: string;
+        mediaId: number;
+        episodeNumber: number;
+        dubbed: boolean;
+        year: number;
+        episodeSource?: Onlinestream_EpisodeSource;
+    }
+
+    /**
+     * @event OnlinestreamEpisodeSourceEvent
+     * @file internal/onlinestream/hook_events.go
+     * @description
+     * OnlinestreamEpisodeSourceEvent is triggered when the video sources of an episode are being returned.
+     * The video sources can be modified, e.g. to rewrite their URLs or headers.
+     */
+    function onOnlinestreamEpisodeSource(cb: (event: OnlinestreamEpisodeSourceEvent) => void): void;
+
+    interface OnlinestreamEpisodeSourceEvent {
+        next(): void;
+
+        mediaId: number;
+        episodeSource?: Onlinestream_EpisodeSource;
+    }
+
+
     /**
      * @package playbackmanager
      */
@@ -1158,8 +1348,8 @@ declare namespace $app {
      * PlaybackLocalFileDetailsRequestedEvent is triggered when the local files details for a specific path are requested.
      * This event is triggered right after the media player loads an episode.
      * The playback manager uses the local files details to track the progress, propose next episodes, etc.
-     * In the current implementation, the details are fetched by selecting the local file from the database and making requests to retrieve the media
-     *     and anime list entry. Prevent default to skip the default fetching and override the details.
+     * In the current implementation, the details are fetched by selecting the local file from the database and making requests to retrieve the media and anime list entry.
+     * Prevent default to skip the default fetching and override the details.
      */
     function onPlaybackLocalFileDetailsRequested(cb: (event: PlaybackLocalFileDetailsRequestedEvent) => void): void;
 
@@ -1181,8 +1371,7 @@ declare namespace $app {
      * @description
      * PlaybackStreamDetailsRequestedEvent is triggered when the stream details are requested.
      * Prevent default to skip the default fetching and override the details.
-     * In the current implementation, the details are fetched by selecting the anime from the anime collection. If nothing is found, the stream is
-     *     still tracked.
+     * In the current implementation, the details are fetched by selecting the anime from the anime collection. If nothing is found, the stream is still tracked.
      */
     function onPlaybackStreamDetailsRequested(cb: (event: PlaybackStreamDetailsRequestedEvent) => void): void;
 
@@ -1412,6 +1601,80 @@ declare namespace $app {
     }
 
 
+    /**
+     * @package torrent_client
+     */
+
+    /**
+     * @event TorrentClientAddTorrentsRequestedEvent
+     * @file internal/torrent_clients/torrent_client/hook_events.go
+     * @description
+     * TorrentClientAddTorrentsRequestedEvent is triggered when torrents are about to be added to the torrent client.
+     * The magnets and the destination can be modified.
+     * Prevent default to veto the addition, an error will be returned to the caller.
+     */
+    function onTorrentClientAddTorrentsRequested(cb: (event: TorrentClientAddTorrentsRequestedEvent) => void): void;
+
+    interface TorrentClientAddTorrentsRequestedEvent {
+        next(): void;
+
+        preventDefault(): void;
+
+        magnets?: Array<string>;
+        destination: string;
+    }
+
+    /**
+     * @event TorrentClientTorrentsAddedEvent
+     * @file internal/torrent_clients/torrent_client/hook_events.go
+     * @description
+     * TorrentClientTorrentsAddedEvent is triggered after the torrents have been added to the torrent client.
+     */
+    function onTorrentClientTorrentsAdded(cb: (event: TorrentClientTorrentsAddedEvent) => void): void;
+
+    interface TorrentClientTorrentsAddedEvent {
+        next(): void;
+
+        magnets?: Array<string>;
+        destination: string;
+    }
+
+    /**
+     * @event TorrentClientActionRequestedEvent
+     * @file internal/torrent_clients/torrent_client/hook_events.go
+     * @description
+     * TorrentClientActionRequestedEvent is triggered when torrents are about to be removed, paused or resumed.
+     * The action is one of "remove", "pause" or "resume".
+     * The hashes can be modified.
+     * Prevent default to veto the action, an error will be returned to the caller.
+     */
+    function onTorrentClientActionRequested(cb: (event: TorrentClientActionRequestedEvent) => void): void;
+
+    interface TorrentClientActionRequestedEvent {
+        next(): void;
+
+        preventDefault(): void;
+
+        action: string;
+        hashes?: Array<string>;
+    }
+
+    /**
+     * @event TorrentClientActionEvent
+     * @file internal/torrent_clients/torrent_client/hook_events.go
+     * @description
+     * TorrentClientActionEvent is triggered after torrents have been removed, paused or resumed.
+     */
+    function onTorrentClientAction(cb: (event: TorrentClientActionEvent) => void): void;
+
+    interface TorrentClientActionEvent {
+        next(): void;
+
+        action: string;
+        hashes?: Array<string>;
+    }
+
+
     /**
      * @package torrentstream
      */
@@ -2671,6 +2934,10 @@ declare namespace $app {
          */
         metadataIssue?: string;
         baseAnime?: AL_BaseAnime;
+        /**
+         * All versions of the episode if there are multiple local files, the preferred version first
+         */
+        versions?: Array<Anime_EpisodeVersion>;
     }
 
     /**
@@ -2686,6 +2953,18 @@ declare namespace $app {
         isFiller?: boolean;
     }
 
+    /**
+     * - Filepath: internal/library/anime/episode_version.go
+     */
+    interface Anime_EpisodeVersion {
+        localFile?: Anime_LocalFile;
+        resolution?: string;
+        releaseGroup?: string;
+        codecs?: Array<string>;
+        size?: number;
+        isPreferred: boolean;
+    }
+
     /**
      * - Filepath: internal/library/anime/collection.go
      */
@@ -2794,6 +3073,7 @@ declare namespace $app {
     interface Anime_LocalFileWrapperEntry {
         mediaId: number;
         localFiles?: Array<Anime_LocalFile>;
+        versionPreferences?: Anime_VersionPreferences;
     }
 
     /**
@@ -2859,6 +3139,39 @@ declare namespace $app {
         suggestions?: Array<AL_BaseAnime>;
     }
 
+    /**
+     * - Filepath: internal/library/anime/episode_version.go
+     */
+    interface Anime_VersionPreferences {
+        /**
+         * e.g. ["1080p", "720p"]
+         */
+        resolutions?: Array<string>;
+        /**
+         * e.g. ["SubsPlease", "Erai-raws"]
+         */
+        releaseGroups?: Array<string>;
+        /**
+         * e.g. ["HEVC", "x265"]
+         */
+        codecs?: Array<string>;
+        preferLargerFiles: boolean;
+    }
+
+    /**
+     * - Filepath: internal/mediastream/videofile/info.go
+     */
+    interface Audio {
+        index: number;
+        title?: string;
+        language?: string;
+        codec: string;
+        mimeCodec?: string;
+        isDefault: boolean;
+        isForced: boolean;
+        channels: number;
+    }
+
     /**
      * - Filepath: internal/library/autodownloader/autodownloader_torrent.go
      */
@@ -2888,10 +3201,19 @@ declare namespace $app {
         confirmed: boolean;
     }
 
+    /**
+     * - Filepath: internal/mediastream/videofile/info.go
+     */
+    interface Chapter {
+        startTime: number;
+        endTime: number;
+        name: string;
+    }
+
     /**
      * - Filepath: internal/continuity/manager.go
      */
-    export type Continuity_Kind = "onlinestream" | "mediastream" | "external_player";
+    export type Continuity_Kind = "onlinestream" | "mediastream" | "external_player" | "torrentstream" | "debrid";
 
     /**
      * - Filepath: internal/continuity/history.go
@@ -2994,6 +3316,16 @@ declare namespace $app {
         updatedAt?: string;
     }
 
+    /**
+     * - Filepath: internal/extension/hibike/manga/types.go
+     */
+    interface HibikeManga_ChapterPage {
+        provider: string;
+        url: string;
+        index: number;
+        headers?: Record<string, string>;
+    }
+
     /**
      * - Filepath: internal/extension/hibike/torrent/types.go
      */
@@ -3104,6 +3436,48 @@ declare namespace $app {
         chapterNumber: string;
     }
 
+    /**
+     * - Filepath: internal/mediastream/videofile/info.go
+     */
+    interface MediaInfo {
+        ready: any;
+        sha: string;
+        path: string;
+        extension: string;
+        mimeCodec?: string;
+        size: number;
+        duration: number;
+        container?: string;
+        video?: Video;
+        videos?: Array<Video>;
+        audios?: Array<Audio>;
+        subtitles?: Array<Subtitle>;
+        fonts?: Array<string>;
+        chapters?: Array<Chapter>;
+    }
+
+    /**
+     * - Filepath: internal/mediastream/playback.go
+     */
+    interface Mediastream_MediaContainer {
+        filePath: string;
+        hash: string;
+        /**
+         * Tells the frontend how to play the media.
+         */
+        streamType: Mediastream_StreamType;
+        /**
+         * The relative endpoint to stream the media.
+         */
+        streamUrl: string;
+        mediaInfo?: MediaInfo;
+    }
+
+    /**
+     * - Filepath: internal/mediastream/playback.go
+     */
+    export type Mediastream_StreamType = "transcode" | "optimized" | "direct";
+
     /**
      * - Filepath: internal/api/metadata/types.go
      */
@@ -3180,6 +3554,7 @@ declare namespace $app {
         enableEnhancedQueries: boolean;
         enableSeasonCheck: boolean;
         useDebrid: boolean;
+        useQbittorrentRss: boolean;
     }
 
     /**
@@ -3193,6 +3568,62 @@ declare namespace $app {
         isFiller?: boolean;
     }
 
+    /**
+     * - Filepath: internal/onlinestream/repository.go
+     */
+    interface Onlinestream_EpisodeSource {
+        number: number;
+        provider?: string;
+        videoSources?: Array<Onlinestream_VideoSource>;
+        subtitles?: Array<Onlinestream_Subtitle>;
+    }
+
+    /**
+     * - Filepath: internal/onlinestream/repository.go
+     */
+    interface Onlinestream_Subtitle {
+        url: string;
+        language: string;
+    }
+
+    /**
+     * - Filepath: internal/onlinestream/repository.go
+     */
+    interface Onlinestream_VideoSource {
+        server: string;
+        headers?: Record<string, string>;
+        url: string;
+        quality: string;
+    }
+
+    /**
+     * - Filepath: internal/mediastream/videofile/video_quality.go
+     */
+    export type Quality = "240p" |
+    "360p" |
+    "480p" |
+    "720p" |
+    "1080p" |
+    "1440p" |
+    "4k" |
+    "8k" |
+    "original";
+
+    /**
+     * - Filepath: internal/mediastream/videofile/info.go
+     */
+    interface Subtitle {
+        index: number;
+        title?: string;
+        language?: string;
+        codec: string;
+        extension?: string;
+        isDefault: boolean;
+        isForced: boolean;
+        isExternal: boolean;
+        link?: string;
+    }
+
     /**
      * - Filepath: internal/torrent_clients/torrent_client/torrent.go
      */
@@ -3214,4 +3645,17 @@ declare namespace $app {
      */
     export type TorrentClient_TorrentStatus = "downloading" | "seeding" | "paused" | "other" | "stopped";
 
+    /**
+     * - Filepath: internal/mediastream/videofile/info.go
+     */
+    interface Video {
+        codec: string;
+        mimeCodec?: string;
+        language?: string;
+        quality: Quality;
+        width: number;
+        height: number;
+        bitrate: number;
+    }
+
 }
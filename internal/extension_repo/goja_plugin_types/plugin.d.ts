@@ -86,6 +86,16 @@ declare namespace $ui {
          */
         torrentClient: TorrentClient
 
+        /**
+         * HTTP endpoints, requires the "http-endpoints" permission
+         */
+        http: HTTP
+
+        /**
+         * Plugin bus, shares events and functions with other plugins
+         */
+        bus: Bus
+
         /**
          * Creates a new state object with an initial value.
          * @param initialValue - The initial value for the state
@@ -253,6 +263,14 @@ declare namespace $ui {
         checkbox: CheckboxComponentFunction
         radioGroup: RadioGroupComponentFunction
         switch: SwitchComponentFunction
+        image: ImageComponentFunction
+        progress: ProgressComponentFunction
+        badge: BadgeComponentFunction
+        tabs: TabsComponentFunction
+        modal: ModalComponentFunction
+        dataTable: DataTableComponentFunction
+        mediaCard: MediaCardComponentFunction
+        mediaGrid: MediaGridComponentFunction
 
         /** Invoked when the tray icon is clicked */
         onClick(cb: () => void): void
@@ -750,6 +768,131 @@ declare namespace $ui {
         (label: string, props?: { side?: "left" | "right" } & FieldComponentProps<boolean>): void
     }
 
+    /**
+     * src must be an http(s) URL or an image data URL.
+     * @default fit="cover"
+     */
+    type ImageComponentFunction = {
+        (props: { src: string, alt?: string, width?: number, height?: number, fit?: "cover" | "contain" | "fill" | "none" } & ComponentProps): void
+        (src: string, props?: { alt?: string, width?: number, height?: number, fit?: "cover" | "contain" | "fill" | "none" } & ComponentProps): void
+    }
+    /**
+     * The value is clamped to max.
+     * @default max=100
+     * @default size="md"
+     */
+    type ProgressComponentFunction = {
+        (props: { value: number, max?: number, label?: string, showValue?: boolean, size?: "xs" | "sm" | "md" | "lg" | "xl" } & ComponentProps): void
+    }
+    type BadgeIntent =
+        "gray"
+        | "primary"
+        | "success"
+        | "warning"
+        | "alert"
+        | "info"
+        | "white"
+        | "gray-solid"
+        | "primary-solid"
+        | "success-solid"
+        | "warning-solid"
+        | "alert-solid"
+        | "info-solid"
+        | "white-solid"
+    /**
+     * @default intent="gray"
+     * @default size="md"
+     */
+    type BadgeComponentFunction = {
+        (props: { text: string, intent?: BadgeIntent, size?: "sm" | "md" | "lg" | "xl" } & ComponentProps): void
+        (text: string, props?: { intent?: BadgeIntent, size?: "sm" | "md" | "lg" | "xl" } & ComponentProps): void
+    }
+    type Tab = {
+        value: string,
+        label: string,
+        items?: any[],
+    }
+    /**
+     * onChange is triggered with { value } when a tab is selected.
+     * @default value - The value of the first tab
+     */
+    type TabsComponentFunction = {
+        (props: { tabs: Tab[], value?: string, onChange?: string } & ComponentProps): void
+        (tabs: Tab[], props?: { value?: string, onChange?: string } & ComponentProps): void
+    }
+    /**
+     * The modal is controlled, onClose is triggered when the user closes it.
+     * The items of a closed modal are not sent to the client.
+     * @default open=false
+     * @default size="md"
+     */
+    type ModalComponentFunction = {
+        (props: {
+            items?: any[],
+            title?: string,
+            description?: string,
+            open?: boolean,
+            onClose?: string,
+            size?: "sm" | "md" | "lg" | "xl"
+        } & ComponentProps): void
+    }
+    type DataTableColumn = {
+        /** Key of the cell in the rows */
+        key: string,
+        label?: string,
+        sortable?: boolean,
+    }
+    /**
+     * Rows are sorted and paginated by the server, only the rows of the current page are sent to the client.
+     * Cells are displayed as text.
+     *
+     * - onPageChange is triggered with { page }
+     * - onSortChange is triggered with { sortBy, sortDirection }
+     * - onRowClick is triggered with { row }
+     *
+     * @default pageSize=10
+     * @default page=1
+     * @default sortDirection="asc"
+     */
+    type DataTableComponentFunction = {
+        (props: {
+            columns: DataTableColumn[],
+            rows: Record<string, string | number | boolean | null>[],
+            /** Key of the cell that identifies a row */
+            rowKey?: string,
+            pageSize?: number,
+            page?: number,
+            /** Key of a sortable column */
+            sortBy?: string,
+            sortDirection?: "asc" | "desc",
+            onPageChange?: string,
+            onSortChange?: string,
+            onRowClick?: string,
+            /** Text displayed when there are no rows */
+            emptyText?: string
+        } & ComponentProps): void
+    }
+    /**
+     * The media is fetched from AniList by the server in the background, a placeholder is displayed until the tray is rendered again with it.
+     * onClick is triggered with { mediaId }.
+     * @default type="anime"
+     */
+    type MediaCardComponentFunction = {
+        (props: { mediaId: number, type?: "anime" | "manga", onClick?: string } & ComponentProps): void
+    }
+    /**
+     * The media are fetched from AniList by the server in the background, placeholders are displayed until the tray is rendered again with them.
+     * Media that can't be found are not displayed.
+     * A grid can display up to 50 media.
+     * onItemClick is triggered with { mediaId }.
+     * @default type="anime"
+     * @default columns=4
+     */
+    type MediaGridComponentFunction = {
+        (props: { mediaIds: number[], type?: "anime" | "manga", columns?: number, onItemClick?: string } & ComponentProps): void
+        (mediaIds: number[], props?: { type?: "anime" | "manga", columns?: number, onItemClick?: string } & ComponentProps): void
+    }
+
     // DOM Element interface
     interface DOMElement {
         id: string
@@ -1032,6 +1175,146 @@ declare namespace $ui {
         send(message: string): void
     }
 
+    interface HTTP {
+        /**
+         * Registers an HTTP endpoint under /api/v1/plugins/{id}.
+         * The path can contain parameters (e.g. "/items/:id") and end with a wildcard (e.g. "/files/*").
+         * If the handler returns a value and doesn't send a response, the value is sent as JSON.
+         * @param method - The HTTP method, "*" matches any method
+         * @param path - The path of the endpoint
+         * @param handler - The function handling the requests
+         */
+        handle(method: "GET" | "POST" | "PUT" | "PATCH" | "DELETE" | "*", path: string, handler: HTTPHandler): void
+
+        /**
+         * Registers a GET endpoint under /api/v1/plugins/{id}.
+         */
+        get(path: string, handler: HTTPHandler): void
+
+        /**
+         * Registers a POST endpoint under /api/v1/plugins/{id}.
+         */
+        post(path: string, handler: HTTPHandler): void
+
+        /**
+         * Registers a PUT endpoint under /api/v1/plugins/{id}.
+         */
+        put(path: string, handler: HTTPHandler): void
+
+        /**
+         * Registers a PATCH endpoint under /api/v1/plugins/{id}.
+         */
+        patch(path: string, handler: HTTPHandler): void
+
+        /**
+         * Registers a DELETE endpoint under /api/v1/plugins/{id}.
+         */
+        delete(path: string, handler: HTTPHandler): void
+
+        /**
+         * Unregisters an endpoint.
+         * @param method - The HTTP method the endpoint was registered with
+         * @param path - The path the endpoint was registered with
+         */
+        remove(method: string, path: string): void
+    }
+
+    interface Bus {
+        /**
+         * Publishes an event to a topic declared in the manifest.
+         * The payload is copied as JSON and validated against the topic schema.
+         * @param topic - The topic
+         * @param payload - The payload
+         * @returns The number of subscribers the event was delivered to
+         * @throws Error if the topic isn't declared or the payload doesn't match the schema
+         */
+        publish(topic: string, payload: any): number
+
+        /**
+         * Subscribes to a topic of another plugin.
+         * Access to the topic must be granted in the manifest.
+         * @param pluginId - The ID of the publisher
+         * @param topic - The topic
+         * @param callback - The function receiving the events
+         * @returns A function to unsubscribe
+         * @throws Error if access to the topic isn't granted
+         */
+        subscribe<T = any>(pluginId: string, topic: string, callback: (event: BusEvent<T>) => void): () => void
+
+        /**
+         * Exports a function declared in the manifest so that other plugins can call it.
+         * The arguments and the result are copied as JSON.
+         * @param name - The name of the function
+         * @param fn - The function
+         * @throws Error if the function isn't declared
+         */
+        export(name: string, fn: (...args: any[]) => any | Promise<any>): void
+
+        /**
+         * Calls a function exported by another plugin.
+         * Access to the function must be granted in the manifest.
+         * @param pluginId - The ID of the plugin exporting the function
+         * @param name - The name of the function
+         * @param args - The arguments
+         * @returns A promise that resolves to the result of the function
+         */
+        call<T = any>(pluginId: string, name: string, ...args: any[]): Promise<T>
+    }
+
+    interface BusEvent<T = any> {
+        publisher: string
+        topic: string
+        payload: T
+        /** Unix timestamp in milliseconds */
+        timestamp: number
+    }
+
+    type HTTPHandler = (req: HTTPRequest, res: HTTPResponse) => any | Promise<any>
+
+    interface HTTPRequest {
+        method: string
+        /** Path relative to /api/v1/plugins/{id} */
+        path: string
+        /** Path parameters, the rest of the path matched by a wildcard is stored under "*" */
+        params: Record<string, string>
+        query: Record<string, string>
+        headers: Record<string, string>
+        body: string
+
+        /**
+         * Parses the body as JSON.
+         * @returns The parsed body, undefined if the body is empty
+         */
+        json<T = any>(): T
+    }
+
+    interface HTTPResponse {
+        /**
+         * Sets the status code, defaults to 200 (204 if nothing is sent).
+         */
+        status(code: number): HTTPResponse
+
+        /**
+         * Sets a response header.
+         */
+        header(key: string, value: string): HTTPResponse
+
+        /**
+         * Sends the data as JSON.
+         */
+        json(data: any): void
+
+        /**
+         * Sends plain text.
+         */
+        text(text: string): void
+
+        /**
+         * Sends HTML.
+         */
+        html(html: string): void
+    }
+
     interface Anime {
         /**
          * Get an anime entry
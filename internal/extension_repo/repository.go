@@ -7,6 +7,7 @@ import (
 	"seanime/internal/events"
 	"seanime/internal/extension"
 	hibikemanga "seanime/internal/extension/hibike/manga"
+	hibikemetadata "seanime/internal/extension/hibike/metadata"
 	hibiketorrent "seanime/internal/extension/hibike/torrent"
 	"seanime/internal/goja/goja_runtime"
 	"seanime/internal/hook"
@@ -50,6 +51,8 @@ type (
 		updateData   []UpdateData
 		updateDataMu sync.Mutex
 
+		extensionSettingsMu sync.Mutex
+
 		// Called when the external extensions are loaded for the first time
 		firstExternalExtensionLoadedFunc context.CancelFunc
 	}
@@ -70,9 +73,12 @@ type (
 	}
 
 	UpdateData struct {
-		ExtensionID string `json:"extensionID"`
-		ManifestURI string `json:"manifestURI"`
-		Version     string `json:"version"`
+		ExtensionID    string `json:"extensionID"`
+		ManifestURI    string `json:"manifestURI"`
+		Version        string `json:"version"`
+		CurrentVersion string `json:"currentVersion"`
+		// Changelog entries of the versions newer than the installed version
+		Changelog []extension.ChangelogEntry `json:"changelog,omitempty"`
 	}
 
 	MangaProviderExtensionItem struct {
@@ -96,6 +102,13 @@ type (
 		Lang     string                              `json:"lang"` // ISO 639-1 language code
 		Settings hibiketorrent.AnimeProviderSettings `json:"settings"`
 	}
+
+	MetadataProviderExtensionItem struct {
+		ID       string                  `json:"id"`
+		Name     string                  `json:"name"`
+		Lang     string                  `json:"lang"` // ISO 639-1 language code
+		Settings hibikemetadata.Settings `json:"settings"`
+	}
 )
 
 type NewRepositoryOptions struct {
@@ -144,10 +157,14 @@ func NewRepository(opts *NewRepositoryOptions) *Repository {
 
 			ret.firstExternalExtensionLoadedFunc = nil
 
-			ret.updateData = ret.checkForUpdates()
-			if len(ret.updateData) > 0 {
+			// Install automatic updates and only notify the user about the remaining ones
+			updateData, notifyData := ret.applyUpdatePolicies(ret.checkForUpdates())
+			ret.updateDataMu.Lock()
+			ret.updateData = updateData
+			ret.updateDataMu.Unlock()
+			if len(notifyData) > 0 {
 				// Signal the frontend that there are updates available
-				ret.wsEventManager.SendEvent(events.ExtensionUpdatesFound, ret.updateData)
+				ret.wsEventManager.SendEvent(events.ExtensionUpdatesFound, notifyData)
 			}
 			time.Sleep(12 * time.Hour)
 		}
@@ -296,6 +313,23 @@ func (r *Repository) ListAnimeTorrentProviderExtensions() []*AnimeTorrentProvide
 	return ret
 }
 
+func (r *Repository) ListMetadataProviderExtensions() []*MetadataProviderExtensionItem {
+	ret := make([]*MetadataProviderExtensionItem, 0)
+
+	extension.RangeExtensions(r.extensionBank, func(key string, ext extension.MetadataProviderExtension) bool {
+		settings := ext.GetProvider().GetSettings()
+		ret = append(ret, &MetadataProviderExtensionItem{
+			ID:       ext.GetID(),
+			Name:     ext.GetName(),
+			Lang:     extension.GetExtensionLang(ext.GetLang()),
+			Settings: settings,
+		})
+		return true
+	})
+
+	return ret
+}
+
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
 // GetLoadedExtension returns the loaded extension by ID.
@@ -329,6 +363,11 @@ func (r *Repository) GetAnimeTorrentProviderExtensionByID(id string) (extension.
 	return ext, found
 }
 
+func (r *Repository) GetMetadataProviderExtensionByID(id string) (extension.MetadataProviderExtension, bool) {
+	ext, found := extension.GetExtension[extension.MetadataProviderExtension](r.extensionBank, id)
+	return ext, found
+}
+
 func (r *Repository) loadPlugin(ext *extension.Extension) (err error) {
 	defer util.HandlePanicInModuleWithError("extension_repo/loadPlugin", &err)
 
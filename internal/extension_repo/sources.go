@@ -0,0 +1,268 @@
+package extension_repo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"seanime/internal/extension"
+	"seanime/internal/util"
+	"seanime/internal/util/filecache"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-json"
+	"github.com/samber/lo"
+)
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Extension sources
+// - A source is a remote index listing multiple extensions
+// - Sources are persisted and queried together to list the extensions available for installation
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+const ExtensionSettingsKey = "1"
+const ExtensionSettingsBucket = "extension-settings"
+
+type (
+	StoredExtensionSettingsData struct {
+		Sources        []*ExtensionSource                `json:"sources"`
+		UpdatePolicies map[string]*ExtensionUpdatePolicy `json:"updatePolicies"` // Extension ID -> Update policy
+	}
+
+	// ExtensionSource is a remote index of extensions.
+	ExtensionSource struct {
+		URL string `json:"url"`
+		// Name of the source, as defined by the index.
+		// Defaults to the URL host.
+		Name string `json:"name"`
+	}
+
+	// ExtensionSourceItem is an extension listed by a source.
+	ExtensionSourceItem struct {
+		Extension  *extension.Extension `json:"extension"`
+		SourceURL  string               `json:"sourceUrl"`
+		SourceName string               `json:"sourceName"`
+		// Version of the extension currently installed, empty if it is not installed.
+		InstalledVersion string `json:"installedVersion,omitempty"`
+	}
+
+	// extensionSourceIndex is the content of a source.
+	//
+	//	{ "name": "My repository", "extensions": [ { "id": "...", "manifestURI": "...", ... } ] }
+	//
+	// A plain list of extensions (like the marketplace) is also accepted.
+	extensionSourceIndex struct {
+		Name       string                 `json:"name"`
+		Extensions []*extension.Extension `json:"extensions"`
+	}
+)
+
+var DefaultStoredExtensionSettingsData = StoredExtensionSettingsData{
+	Sources:        []*ExtensionSource{},
+	UpdatePolicies: map[string]*ExtensionUpdatePolicy{},
+}
+
+// GetExtensionSettings returns the stored extension settings.
+// If no settings are found, it will return the default settings.
+func (r *Repository) GetExtensionSettings() *StoredExtensionSettingsData {
+	r.extensionSettingsMu.Lock()
+	defer r.extensionSettingsMu.Unlock()
+	return r.getExtensionSettings()
+}
+
+func (r *Repository) getExtensionSettings() *StoredExtensionSettingsData {
+	bucket := filecache.NewPermanentBucket(ExtensionSettingsBucket)
+
+	var settings StoredExtensionSettingsData
+	found, _ := r.fileCacher.GetPerm(bucket, ExtensionSettingsKey, &settings)
+	if !found {
+		r.fileCacher.SetPerm(bucket, ExtensionSettingsKey, DefaultStoredExtensionSettingsData)
+		return &StoredExtensionSettingsData{
+			Sources:        []*ExtensionSource{},
+			UpdatePolicies: map[string]*ExtensionUpdatePolicy{},
+		}
+	}
+
+	if settings.Sources == nil {
+		settings.Sources = []*ExtensionSource{}
+	}
+	if settings.UpdatePolicies == nil {
+		settings.UpdatePolicies = map[string]*ExtensionUpdatePolicy{}
+	}
+
+	return &settings
+}
+
+// updateExtensionSettings applies the given function to the stored settings and saves them.
+func (r *Repository) updateExtensionSettings(f func(settings *StoredExtensionSettingsData) error) error {
+	r.extensionSettingsMu.Lock()
+	defer r.extensionSettingsMu.Unlock()
+
+	settings := r.getExtensionSettings()
+	if err := f(settings); err != nil {
+		return err
+	}
+
+	bucket := filecache.NewPermanentBucket(ExtensionSettingsBucket)
+	return r.fileCacher.SetPerm(bucket, ExtensionSettingsKey, settings)
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// ListExtensionSources returns the stored extension sources.
+func (r *Repository) ListExtensionSources() []*ExtensionSource {
+	return r.GetExtensionSettings().Sources
+}
+
+// AddExtensionSource fetches the index at the given URL and stores it as a source.
+func (r *Repository) AddExtensionSource(sourceUrl string) (*ExtensionSource, error) {
+	sourceUrl = strings.TrimSpace(sourceUrl)
+	if _, err := url.ParseRequestURI(sourceUrl); err != nil {
+		return nil, fmt.Errorf("invalid source URL: %w", err)
+	}
+
+	// Make sure the index is valid before storing it
+	index, err := r.fetchExtensionSourceIndex(sourceUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	source := &ExtensionSource{
+		URL:  sourceUrl,
+		Name: getExtensionSourceName(sourceUrl, index),
+	}
+
+	err = r.updateExtensionSettings(func(settings *StoredExtensionSettingsData) error {
+		if lo.ContainsBy(settings.Sources, func(s *ExtensionSource) bool { return s.URL == sourceUrl }) {
+			return errors.New("source already added")
+		}
+		settings.Sources = append(settings.Sources, source)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Debug().Str("url", sourceUrl).Int("count", len(index.Extensions)).Msg("extensions: Added extension source")
+
+	return source, nil
+}
+
+// RemoveExtensionSource removes the source with the given URL.
+// Extensions installed from the source are kept.
+func (r *Repository) RemoveExtensionSource(sourceUrl string) error {
+	return r.updateExtensionSettings(func(settings *StoredExtensionSettingsData) error {
+		settings.Sources = lo.Filter(settings.Sources, func(s *ExtensionSource, _ int) bool {
+			return s.URL != sourceUrl
+		})
+		return nil
+	})
+}
+
+// ListExtensionSourceExtensions fetches the indexes of all sources and returns the extensions they list.
+// If an extension is listed by multiple sources, the first source wins.
+func (r *Repository) ListExtensionSourceExtensions() (ret []*ExtensionSourceItem, err error) {
+	defer util.HandlePanicInModuleWithError("extension_repo/ListExtensionSourceExtensions", &err)
+
+	sources := r.ListExtensionSources()
+
+	indexes := make([]*extensionSourceIndex, len(sources))
+	wg := sync.WaitGroup{}
+	for i, source := range sources {
+		wg.Add(1)
+		go func(i int, source *ExtensionSource) {
+			defer wg.Done()
+			index, err := r.fetchExtensionSourceIndex(source.URL)
+			if err != nil {
+				r.logger.Warn().Err(err).Str("url", source.URL).Msg("extensions: Failed to fetch extension source")
+				return
+			}
+			indexes[i] = index
+		}(i, source)
+	}
+	wg.Wait()
+
+	ret = make([]*ExtensionSourceItem, 0)
+	seen := make(map[string]struct{})
+	for i, index := range indexes {
+		if index == nil {
+			continue
+		}
+		for _, ext := range index.Extensions {
+			if _, found := seen[ext.ID]; found {
+				continue
+			}
+			seen[ext.ID] = struct{}{}
+
+			ext.Payload = ""
+			item := &ExtensionSourceItem{
+				Extension:  ext,
+				SourceURL:  sources[i].URL,
+				SourceName: sources[i].Name,
+			}
+			if installed, found := r.extensionBank.Get(ext.ID); found {
+				item.InstalledVersion = installed.GetVersion()
+			}
+			ret = append(ret, item)
+		}
+	}
+
+	return ret, nil
+}
+
+// fetchExtensionSourceIndex fetches and parses the index of a source.
+func (r *Repository) fetchExtensionSourceIndex(sourceUrl string) (*extensionSourceIndex, error) {
+	resp, err := r.client.Get(sourceUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch extension source: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("failed to fetch extension source, status: %s", resp.Status)
+	}
+
+	bodyR, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read extension source: %w", err)
+	}
+
+	index, err := parseExtensionSourceIndex(bodyR)
+	if err != nil {
+		return nil, err
+	}
+
+	return index, nil
+}
+
+func parseExtensionSourceIndex(data []byte) (*extensionSourceIndex, error) {
+	index := &extensionSourceIndex{}
+
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal(data, &index.Extensions); err != nil {
+			return nil, fmt.Errorf("failed to parse extension source: %w", err)
+		}
+	} else {
+		if err := json.Unmarshal(data, index); err != nil {
+			return nil, fmt.Errorf("failed to parse extension source: %w", err)
+		}
+	}
+
+	index.Extensions = lo.Filter(index.Extensions, func(item *extension.Extension, _ int) bool {
+		return item != nil && item.ID != "" && item.ManifestURI != ""
+	})
+
+	return index, nil
+}
+
+func getExtensionSourceName(sourceUrl string, index *extensionSourceIndex) string {
+	if index != nil && index.Name != "" {
+		return index.Name
+	}
+	if u, err := url.Parse(sourceUrl); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return sourceUrl
+}
@@ -0,0 +1,293 @@
+package extension_repo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"seanime/internal/events"
+	"seanime/internal/extension"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/samber/lo"
+)
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Update policies
+// - "manual": updates are listed when the user checks for updates
+// - "notify": updates are listed and the user is notified (default)
+// - "auto": minor and patch updates are installed automatically, major updates are notified
+// - An extension pinned to a version is held at that version, it is rolled back to it if needed and never updated
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type ExtensionUpdatePolicyType string
+
+const (
+	ExtensionUpdatePolicyManual ExtensionUpdatePolicyType = "manual"
+	ExtensionUpdatePolicyNotify ExtensionUpdatePolicyType = "notify"
+	ExtensionUpdatePolicyAuto   ExtensionUpdatePolicyType = "auto"
+)
+
+// extensionBackupDir is the name of the directory, relative to the extension directory, containing the previously installed payloads.
+// Backups don't use the .json extension so that they're not loaded as extensions.
+const extensionBackupDir = ".backup"
+
+type ExtensionUpdatePolicy struct {
+	Policy ExtensionUpdatePolicyType `json:"policy"`
+	// Version the extension is pinned to, empty if the extension is not pinned.
+	PinnedVersion string `json:"pinnedVersion,omitempty"`
+}
+
+// GetExtensionUpdatePolicy returns the update policy of the extension with the given ID.
+func (r *Repository) GetExtensionUpdatePolicy(id string) *ExtensionUpdatePolicy {
+	policy, found := r.GetExtensionSettings().UpdatePolicies[id]
+	if !found || policy == nil {
+		return &ExtensionUpdatePolicy{Policy: ExtensionUpdatePolicyNotify}
+	}
+	return policy
+}
+
+// ListExtensionUpdatePolicies returns the update policies that were set by the user.
+func (r *Repository) ListExtensionUpdatePolicies() map[string]*ExtensionUpdatePolicy {
+	return r.GetExtensionSettings().UpdatePolicies
+}
+
+// SetExtensionUpdatePolicy sets the update policy of the extension with the given ID.
+// If pinnedVersion is not empty, it must be the installed version or the previous version, in which case the extension is rolled back.
+func (r *Repository) SetExtensionUpdatePolicy(id string, policy ExtensionUpdatePolicyType, pinnedVersion string) error {
+	if id == "" {
+		return errors.New("id is empty")
+	}
+
+	switch policy {
+	case ExtensionUpdatePolicyManual, ExtensionUpdatePolicyNotify, ExtensionUpdatePolicyAuto:
+	case "":
+		policy = ExtensionUpdatePolicyNotify
+	default:
+		return fmt.Errorf("invalid update policy: %s", policy)
+	}
+
+	rollback := false
+	if pinnedVersion != "" {
+		if _, err := semver.NewVersion(pinnedVersion); err != nil {
+			return fmt.Errorf("invalid pinned version: %s", pinnedVersion)
+		}
+
+		installed, err := extractExtensionFromFile(filepath.Join(r.extensionDir, id+".json"))
+		if err != nil {
+			return fmt.Errorf("extension %s is not installed", id)
+		}
+		previousVersion := ""
+		if previous, err := extractExtensionFromFile(r.getExtensionBackupPath(id)); err == nil {
+			previousVersion = previous.Version
+		}
+
+		rollback, err = resolvePinnedVersion(pinnedVersion, installed.Version, previousVersion)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := r.updateExtensionSettings(func(settings *StoredExtensionSettingsData) error {
+		settings.UpdatePolicies[id] = &ExtensionUpdatePolicy{
+			Policy:        policy,
+			PinnedVersion: pinnedVersion,
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if rollback {
+		if _, err := r.RollbackExtension(id); err != nil {
+			return err
+		}
+	}
+
+	// Remove pinned extensions from the update data
+	if pinnedVersion != "" {
+		r.updateDataMu.Lock()
+		r.updateData = lo.Filter(r.updateData, func(item UpdateData, _ int) bool {
+			return item.ExtensionID != id
+		})
+		r.updateDataMu.Unlock()
+	}
+
+	return nil
+}
+
+// resolvePinnedVersion returns true if the extension should be rolled back to the previous version to hold the pinned version.
+// Extensions can only be pinned to a version that is on disk, i.e. the installed version or the previous one.
+func resolvePinnedVersion(pinned string, installed string, previous string) (rollback bool, err error) {
+	pinnedV, err := semver.NewVersion(pinned)
+	if err != nil {
+		return false, fmt.Errorf("invalid pinned version: %s", pinned)
+	}
+	if installedV, err := semver.NewVersion(installed); err == nil && installedV.Equal(pinnedV) {
+		return false, nil
+	}
+	if previousV, err := semver.NewVersion(previous); err == nil && previousV.Equal(pinnedV) {
+		return true, nil
+	}
+	return false, fmt.Errorf("version %s is not available, an extension can only be pinned to its installed or previous version", pinned)
+}
+
+// checkPinnedVersion returns an error if the extension is pinned to a version other than the given one.
+func (r *Repository) checkPinnedVersion(id string, version string) error {
+	policy, found := r.ListExtensionUpdatePolicies()[id]
+	if !found || policy == nil || policy.PinnedVersion == "" {
+		return nil
+	}
+	if rollback, err := resolvePinnedVersion(policy.PinnedVersion, version, ""); err != nil || rollback {
+		return fmt.Errorf("extension %s is pinned to %s", id, policy.PinnedVersion)
+	}
+	return nil
+}
+
+func (r *Repository) removeExtensionUpdatePolicy(id string) {
+	_ = r.updateExtensionSettings(func(settings *StoredExtensionSettingsData) error {
+		delete(settings.UpdatePolicies, id)
+		return nil
+	})
+}
+
+// applyUpdatePolicies filters the update data according to the update policies and installs automatic updates.
+// It returns the update data that should be kept and the update data the user should be notified about.
+func (r *Repository) applyUpdatePolicies(updates []UpdateData) (kept []UpdateData, notify []UpdateData) {
+	kept = make([]UpdateData, 0, len(updates))
+	notify = make([]UpdateData, 0, len(updates))
+
+	policies := r.ListExtensionUpdatePolicies()
+
+	for _, update := range updates {
+		policy, found := policies[update.ExtensionID]
+		if !found || policy == nil {
+			policy = &ExtensionUpdatePolicy{Policy: ExtensionUpdatePolicyNotify}
+		}
+
+		switch policy.Policy {
+		case ExtensionUpdatePolicyAuto:
+			if isMinorOrPatchUpdate(update.CurrentVersion, update.Version) {
+				_, err := r.InstallExternalExtension(update.ManifestURI)
+				if err != nil {
+					r.logger.Error().Err(err).Str("id", update.ExtensionID).Msg("extensions: Failed to apply automatic update")
+					kept = append(kept, update)
+					notify = append(notify, update)
+					continue
+				}
+				r.logger.Info().Str("id", update.ExtensionID).Str("version", update.Version).Msg("extensions: Applied automatic update")
+				r.wsEventManager.SendEvent(events.InfoToast, fmt.Sprintf("Extension %s updated to %s", update.ExtensionID, update.Version))
+				continue
+			}
+			kept = append(kept, update)
+			notify = append(notify, update)
+		case ExtensionUpdatePolicyManual:
+			kept = append(kept, update)
+		default:
+			kept = append(kept, update)
+			notify = append(notify, update)
+		}
+	}
+
+	return kept, notify
+}
+
+// isMinorOrPatchUpdate returns true if the new version is greater than the current version and has the same major version.
+func isMinorOrPatchUpdate(current string, next string) bool {
+	currentV, err := semver.NewVersion(current)
+	if err != nil {
+		return false
+	}
+	nextV, err := semver.NewVersion(next)
+	if err != nil {
+		return false
+	}
+	return nextV.Major() == currentV.Major() && nextV.GreaterThan(currentV)
+}
+
+// getChangelogSince returns the changelog entries of the versions greater than the given version.
+func getChangelogSince(changelog []extension.ChangelogEntry, version string) []extension.ChangelogEntry {
+	currentV, err := semver.NewVersion(version)
+	if err != nil {
+		return changelog
+	}
+	return lo.Filter(changelog, func(entry extension.ChangelogEntry, _ int) bool {
+		v, err := semver.NewVersion(entry.Version)
+		return err == nil && v.GreaterThan(currentV)
+	})
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Rollback
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (r *Repository) getExtensionBackupPath(id string) string {
+	return filepath.Join(r.extensionDir, extensionBackupDir, id+".json.bak")
+}
+
+// backupExtension copies the installed extension file so that it can be restored if the new version fails to load.
+func (r *Repository) backupExtension(id string) error {
+	data, err := os.ReadFile(filepath.Join(r.extensionDir, id+".json"))
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Join(r.extensionDir, extensionBackupDir), os.ModePerm); err != nil {
+		return err
+	}
+
+	return os.WriteFile(r.getExtensionBackupPath(id), data, 0644)
+}
+
+// HasExtensionBackup returns true if a previously installed payload can be restored.
+func (r *Repository) HasExtensionBackup(id string) bool {
+	_, err := os.Stat(r.getExtensionBackupPath(id))
+	return err == nil
+}
+
+// RollbackExtension restores the previously installed payload of the extension and reloads it.
+func (r *Repository) RollbackExtension(id string) (*ExtensionInstallResponse, error) {
+	data, err := os.ReadFile(r.getExtensionBackupPath(id))
+	if err != nil {
+		return nil, fmt.Errorf("no previous version found for %s", id)
+	}
+
+	ext, err := extractExtensionFromFile(r.getExtensionBackupPath(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read previous version, %w", err)
+	}
+
+	err = os.WriteFile(filepath.Join(r.extensionDir, id+".json"), data, 0644)
+	if err != nil {
+		r.logger.Error().Err(err).Str("id", id).Msg("extensions: Failed to restore previous version")
+		return nil, fmt.Errorf("failed to restore previous version, %w", err)
+	}
+
+	_ = os.Remove(r.getExtensionBackupPath(id))
+
+	r.reloadExtension(id)
+
+	r.logger.Info().Str("id", id).Str("version", ext.Version).Msg("extensions: Rolled back extension")
+
+	return &ExtensionInstallResponse{
+		Message: fmt.Sprintf("Successfully rolled back %s to %s", ext.Name, ext.Version),
+	}, nil
+}
+
+// hasFailedToLoad returns true if the extension could not be loaded because of its manifest or payload.
+func (r *Repository) hasFailedToLoad(id string) bool {
+	invalidExt, found := r.invalidExtensions.Get(id)
+	if !found {
+		return false
+	}
+	switch invalidExt.Code {
+	case extension.InvalidExtensionManifestError, extension.InvalidExtensionPayloadError, extension.InvalidExtensionSemverConstraintError:
+		return true
+	}
+	return false
+}
+
+func (r *Repository) deleteExtensionBackup(id string) {
+	_ = os.Remove(r.getExtensionBackupPath(id))
+}
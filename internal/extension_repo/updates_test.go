@@ -0,0 +1,90 @@
+package extension_repo
+
+import (
+	"seanime/internal/extension"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsMinorOrPatchUpdate(t *testing.T) {
+
+	tests := []struct {
+		current  string
+		next     string
+		expected bool
+	}{
+		{"1.0.0", "1.0.1", true},
+		{"1.0.0", "1.1.0", true},
+		{"1.0.0", "2.0.0", false},
+		{"1.2.0", "1.1.0", false},
+		{"1.0.0", "1.0.0", false},
+		{"invalid", "1.0.1", false},
+	}
+
+	for _, test := range tests {
+		if isMinorOrPatchUpdate(test.current, test.next) != test.expected {
+			t.Errorf("isMinorOrPatchUpdate(%v, %v) != %v", test.current, test.next, test.expected)
+		}
+	}
+
+}
+
+func TestResolvePinnedVersion(t *testing.T) {
+
+	tests := []struct {
+		pinned    string
+		installed string
+		previous  string
+		rollback  bool
+		err       bool
+	}{
+		{"1.0.0", "1.0.0", "", false, false},
+		{"1.0", "1.0.0", "0.9.0", false, false},
+		{"0.9.0", "1.0.0", "0.9.0", true, false},
+		{"0.8.0", "1.0.0", "0.9.0", false, true},
+		{"0.9.0", "1.0.0", "", false, true},
+		{"invalid", "1.0.0", "", false, true},
+	}
+
+	for _, test := range tests {
+		rollback, err := resolvePinnedVersion(test.pinned, test.installed, test.previous)
+		if test.err {
+			require.Error(t, err, test.pinned)
+			continue
+		}
+		require.NoError(t, err, test.pinned)
+		require.Equal(t, test.rollback, rollback, test.pinned)
+	}
+
+}
+
+func TestGetChangelogSince(t *testing.T) {
+
+	changelog := []extension.ChangelogEntry{
+		{Version: "1.2.0", Notes: "c"},
+		{Version: "1.1.0", Notes: "b"},
+		{Version: "1.0.0", Notes: "a"},
+	}
+
+	ret := getChangelogSince(changelog, "1.0.0")
+	require.Len(t, ret, 2)
+	require.Equal(t, "1.2.0", ret[0].Version)
+	require.Equal(t, "1.1.0", ret[1].Version)
+
+}
+
+func TestParseExtensionSourceIndex(t *testing.T) {
+
+	index, err := parseExtensionSourceIndex([]byte(`{"name":"My repository","extensions":[{"id":"a","manifestURI":"https://example.com/a.json"},{"id":"b"}]}`))
+	require.NoError(t, err)
+	require.Equal(t, "My repository", index.Name)
+	require.Len(t, index.Extensions, 1)
+	require.Equal(t, "My repository", getExtensionSourceName("https://example.com/index.json", index))
+
+	index, err = parseExtensionSourceIndex([]byte(`[{"id":"a","manifestURI":"https://example.com/a.json"}]`))
+	require.NoError(t, err)
+	require.Len(t, index.Extensions, 1)
+	require.Equal(t, "example.com", getExtensionSourceName("https://example.com/index.json", index))
+
+}
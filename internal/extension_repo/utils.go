@@ -73,6 +73,7 @@ func manifestSanityCheck(ext *extension.Extension) error {
 	if ext.Type != extension.TypeMangaProvider &&
 		ext.Type != extension.TypeOnlinestreamProvider &&
 		ext.Type != extension.TypeAnimeTorrentProvider &&
+		ext.Type != extension.TypeMetadataProvider &&
 		ext.Type != extension.TypePlugin {
 		return fmt.Errorf("unsupported extension type: %v", ext.Type)
 	}
@@ -88,6 +89,12 @@ func manifestSanityCheck(ext *extension.Extension) error {
 	return nil
 }
 
+// ManifestSanityCheck checks if the extension manifest has all the required fields.
+// It is used to validate manifests loaded outside the repository, e.g. by the plugin test harness.
+func ManifestSanityCheck(ext *extension.Extension) error {
+	return manifestSanityCheck(ext)
+}
+
 // extensionSanityCheck checks if the extension has all the required fields in the manifest.
 func (r *Repository) extensionSanityCheck(ext *extension.Extension) error {
 
@@ -36,10 +36,11 @@ func (h *Handler) HandleGetLibraryCollection(c echo.Context) error {
 	}
 
 	libraryCollection, err := anime.NewLibraryCollection(&anime.NewLibraryCollectionOptions{
-		AnimeCollection:  animeCollection,
-		Platform:         h.App.AnilistPlatform,
-		LocalFiles:       lfs,
-		MetadataProvider: h.App.MetadataProvider,
+		AnimeCollection:    animeCollection,
+		Platform:           h.App.AnilistPlatform,
+		LocalFiles:         lfs,
+		MetadataProvider:   h.App.MetadataProvider,
+		VersionPreferences: anime.NewVersionPreferences(h.App.Settings.GetLibrary()),
 	})
 	if err != nil {
 		return h.RespondWithError(c, err)
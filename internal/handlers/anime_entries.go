@@ -58,11 +58,12 @@ func (h *Handler) HandleGetAnimeEntry(c echo.Context) error {
 
 	// Create a new media entry
 	entry, err := anime.NewEntry(&anime.NewEntryOptions{
-		MediaId:          mId,
-		LocalFiles:       lfs,
-		AnimeCollection:  animeCollection,
-		Platform:         h.App.AnilistPlatform,
-		MetadataProvider: h.App.MetadataProvider,
+		MediaId:            mId,
+		LocalFiles:         lfs,
+		AnimeCollection:    animeCollection,
+		Platform:           h.App.AnilistPlatform,
+		MetadataProvider:   h.App.MetadataProvider,
+		VersionPreferences: anime.NewVersionPreferences(h.App.Settings.GetLibrary()),
 	})
 	if err != nil {
 		return h.RespondWithError(c, err)
@@ -418,6 +419,9 @@ func (h *Handler) HandleAnimeEntryManualMatch(c echo.Context) error {
 		return h.RespondWithError(c, err)
 	}
 
+	// Remember the match for future scans
+	h.learnTitleAliases(event.MatchedLocalFiles, event.MediaId)
+
 	return h.RespondWithData(c, retLfs)
 }
 
@@ -130,6 +130,9 @@ func (h *Handler) HandleCreateAutoDownloaderRule(c echo.Context) error {
 		return h.RespondWithError(c, err)
 	}
 
+	// Update the qBittorrent RSS rules if enabled
+	h.App.AutoDownloader.SyncRules()
+
 	return h.RespondWithData(c, rule)
 }
 
@@ -165,6 +168,9 @@ func (h *Handler) HandleUpdateAutoDownloaderRule(c echo.Context) error {
 		return h.RespondWithError(c, err)
 	}
 
+	// Update the qBittorrent RSS rules if enabled
+	h.App.AutoDownloader.SyncRules()
+
 	return h.RespondWithData(c, b.Rule)
 }
 
@@ -185,6 +191,9 @@ func (h *Handler) HandleDeleteAutoDownloaderRule(c echo.Context) error {
 		return h.RespondWithError(c, err)
 	}
 
+	// Update the qBittorrent RSS rules if enabled
+	h.App.AutoDownloader.SyncRules()
+
 	return h.RespondWithData(c, true)
 }
 
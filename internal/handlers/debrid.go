@@ -339,7 +339,7 @@ func (h *Handler) HandleDebridStartStream(c echo.Context) error {
 		Torrent       *hibiketorrent.AnimeTorrent      `json:"torrent"`
 		FileId        string                           `json:"fileId"`
 		FileIndex     *int                             `json:"fileIndex"`
-		PlaybackType  debrid_client.StreamPlaybackType `json:"playbackType"` // "default" or "externalPlayerLink"
+		PlaybackType  debrid_client.StreamPlaybackType `json:"playbackType"` // "default", "externalPlayerLink" or "transcode"
 		ClientId      string                           `json:"clientId"`
 	}
 
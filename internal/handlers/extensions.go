@@ -1,10 +1,15 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"net/url"
 	"seanime/internal/extension"
 	"seanime/internal/extension_playground"
+	"seanime/internal/extension_repo"
+	"seanime/internal/plugin"
 
 	"github.com/labstack/echo/v4"
 )
@@ -78,6 +83,29 @@ func (h *Handler) HandleUninstallExternalExtension(c echo.Context) error {
 	return h.RespondWithData(c, true)
 }
 
+// HandleRollbackExternalExtension
+//
+//	@summary restores the previously installed version of the extension with the given ID.
+//	@route /api/v1/extensions/external/rollback [POST]
+//	@returns extension_repo.ExtensionInstallResponse
+func (h *Handler) HandleRollbackExternalExtension(c echo.Context) error {
+	type body struct {
+		ID string `json:"id"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	res, err := h.App.ExtensionRepository.RollbackExtension(b.ID)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, res)
+}
+
 // HandleUpdateExtensionCode
 //
 //	@summary updates the extension code with the given ID and reloads the extensions.
@@ -218,6 +246,16 @@ func (h *Handler) HandleListAnimeTorrentProviderExtensions(c echo.Context) error
 	return h.RespondWithData(c, extensions)
 }
 
+// HandleListMetadataProviderExtensions
+//
+//	@summary returns the installed metadata providers.
+//	@route /api/v1/extensions/list/metadata-provider [GET]
+//	@returns []extension_repo.MetadataProviderExtensionItem
+func (h *Handler) HandleListMetadataProviderExtensions(c echo.Context) error {
+	extensions := h.App.ExtensionRepository.ListMetadataProviderExtensions()
+	return h.RespondWithData(c, extensions)
+}
+
 // HandleGetPluginSettings
 //
 //	@summary returns the plugin settings.
@@ -360,3 +398,180 @@ func (h *Handler) HandleGetMarketplaceExtensions(c echo.Context) error {
 
 	return h.RespondWithData(c, extensions)
 }
+
+// HandleListExtensionSources
+//
+//	@summary returns the extension sources.
+//	@route /api/v1/extensions/sources [GET]
+//	@returns []extension_repo.ExtensionSource
+func (h *Handler) HandleListExtensionSources(c echo.Context) error {
+	return h.RespondWithData(c, h.App.ExtensionRepository.ListExtensionSources())
+}
+
+// HandleAddExtensionSource
+//
+//	@summary adds an extension source.
+//	@desc The URL must point to an index listing multiple extensions.
+//	@route /api/v1/extensions/sources [POST]
+//	@returns extension_repo.ExtensionSource
+func (h *Handler) HandleAddExtensionSource(c echo.Context) error {
+	type body struct {
+		URL string `json:"url"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	source, err := h.App.ExtensionRepository.AddExtensionSource(b.URL)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, source)
+}
+
+// HandleRemoveExtensionSource
+//
+//	@summary removes an extension source.
+//	@desc Extensions installed from the source are not uninstalled.
+//	@route /api/v1/extensions/sources [DELETE]
+//	@returns bool
+func (h *Handler) HandleRemoveExtensionSource(c echo.Context) error {
+	type body struct {
+		URL string `json:"url"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	err := h.App.ExtensionRepository.RemoveExtensionSource(b.URL)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, true)
+}
+
+// HandleListExtensionSourceExtensions
+//
+//	@summary returns the extensions listed by all extension sources.
+//	@route /api/v1/extensions/sources/extensions [GET]
+//	@returns []extension_repo.ExtensionSourceItem
+func (h *Handler) HandleListExtensionSourceExtensions(c echo.Context) error {
+	extensions, err := h.App.ExtensionRepository.ListExtensionSourceExtensions()
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, extensions)
+}
+
+// HandleListExtensionUpdatePolicies
+//
+//	@summary returns the update policies set by the user.
+//	@route /api/v1/extensions/update-policies [GET]
+//	@returns map[string]extension_repo.ExtensionUpdatePolicy
+func (h *Handler) HandleListExtensionUpdatePolicies(c echo.Context) error {
+	return h.RespondWithData(c, h.App.ExtensionRepository.ListExtensionUpdatePolicies())
+}
+
+// HandleSetExtensionUpdatePolicy
+//
+//	@summary sets the update policy of an extension.
+//	@desc Policy can be "manual", "notify" or "auto".
+//	@desc The pinned version must be the installed version or the previous version, in which case the extension is rolled back.
+//	@desc A pinned extension is held at that version, it is not updated until it is unpinned.
+//	@route /api/v1/extensions/update-policy [POST]
+//	@returns bool
+func (h *Handler) HandleSetExtensionUpdatePolicy(c echo.Context) error {
+	type body struct {
+		ID            string                                   `json:"id"`
+		Policy        extension_repo.ExtensionUpdatePolicyType `json:"policy"`
+		PinnedVersion string                                   `json:"pinnedVersion"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	err := h.App.ExtensionRepository.SetExtensionUpdatePolicy(b.ID, b.Policy, b.PinnedVersion)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, true)
+}
+
+const pluginEndpointMaxBodySize = 10 << 20 // 10 MB
+
+// pluginEndpointStrippedHeaders are the headers that are not passed to plugin endpoints.
+// They hold the credentials and the client ID of the caller.
+var pluginEndpointStrippedHeaders = map[string]struct{}{
+	"Authorization":       {},
+	"Cookie":              {},
+	"Proxy-Authorization": {},
+	"Proxy-Authenticate":  {},
+	"Www-Authenticate":    {},
+	"Seanime-Client-Id":   {},
+}
+
+// HandlePluginEndpoint
+//
+//	@summary calls an HTTP endpoint registered by a plugin.
+//	@desc Plugins register endpoints with ctx.http, they are served under /api/v1/plugins/{id}.
+//	@desc The response status, headers and body are set by the plugin handler.
+//	@desc Credential and cookie headers are not passed to the plugin, bodies over 10 MB are rejected with a 413 status.
+//	@param id - string - true - "The plugin ID"
+//	@returns any
+//	@route /api/v1/plugins/{id}/{path} [GET,POST,PUT,PATCH,DELETE]
+func (h *Handler) HandlePluginEndpoint(c echo.Context) error {
+	// Read one more byte than allowed to know if the body is too large
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, pluginEndpointMaxBodySize+1))
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+	if len(body) > pluginEndpointMaxBodySize {
+		return c.JSON(http.StatusRequestEntityTooLarge, NewErrorResponse(errors.New("request body is too large")))
+	}
+
+	req := &plugin.EndpointRequest{
+		Method:  c.Request().Method,
+		Path:    "/" + c.Param("*"),
+		Query:   make(map[string]string),
+		Headers: make(map[string]string),
+		Body:    body,
+	}
+	for key, values := range c.QueryParams() {
+		req.Query[key] = values[0]
+	}
+	for key, values := range c.Request().Header {
+		if _, stripped := pluginEndpointStrippedHeaders[http.CanonicalHeaderKey(key)]; stripped {
+			continue
+		}
+		req.Headers[key] = values[0]
+	}
+
+	res, err := plugin.GlobalEndpointRegistry.Serve(c.Param("id"), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, plugin.ErrEndpointNotFound):
+			return c.JSON(http.StatusNotFound, NewErrorResponse(err))
+		case errors.Is(err, plugin.ErrEndpointMethodDenied):
+			return c.JSON(http.StatusMethodNotAllowed, NewErrorResponse(err))
+		}
+		return h.RespondWithError(c, err)
+	}
+
+	for key, value := range res.Headers {
+		c.Response().Header().Set(key, value)
+	}
+	if res.Body == nil {
+		return c.NoContent(res.Status)
+	}
+	return c.Blob(res.Status, res.Headers["Content-Type"], res.Body)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"seanime/internal/database/db_bridge"
+	"seanime/internal/library/healthcheck"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HandleStartLibraryHealthCheck
+//
+//	@summary starts checking the local files with ffprobe.
+//	@desc The check runs in the background. Zero-length, unreadable, truncated files and files whose duration does not match the episode are reported.
+//	@desc Files missing an audio or subtitle track in the required languages are also reported.
+//	@desc If 'autoRedownload' is true, empty and truncated episodes are downloaded again using the auto downloader rule of the media.
+//	@desc Returns an error if ffprobe cannot be run.
+//	@desc Progress is sent through the "library-health-check-progress" websocket event.
+//	@route /api/v1/library/health-check [POST]
+//	@returns bool
+func (h *Handler) HandleStartLibraryHealthCheck(c echo.Context) error {
+
+	var b healthcheck.Options
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	if h.App.LibraryHealthChecker == nil {
+		return h.RespondWithError(c, errors.New("health check is not available"))
+	}
+
+	lfs, _, err := db_bridge.GetLocalFiles(h.App.Database)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	ffprobePath := ""
+	if h.App.SecondarySettings.Mediastream != nil {
+		ffprobePath = h.App.SecondarySettings.Mediastream.FfprobePath
+	}
+
+	if err := h.App.LibraryHealthChecker.Start(lfs, ffprobePath, &b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, true)
+}
+
+// HandleGetLibraryHealthCheckReport
+//
+//	@summary returns the report of the current or last health check.
+//	@desc Returns null if no check was run since the server started.
+//	@route /api/v1/library/health-check/report [GET]
+//	@returns healthcheck.Report
+func (h *Handler) HandleGetLibraryHealthCheckReport(c echo.Context) error {
+
+	if h.App.LibraryHealthChecker == nil {
+		return h.RespondWithData(c, nil)
+	}
+
+	return h.RespondWithData(c, h.App.LibraryHealthChecker.GetReport())
+}
+
+// HandleCancelLibraryHealthCheck
+//
+//	@summary cancels the running health check.
+//	@route /api/v1/library/health-check [DELETE]
+//	@returns bool
+func (h *Handler) HandleCancelLibraryHealthCheck(c echo.Context) error {
+
+	if h.App.LibraryHealthChecker != nil {
+		h.App.LibraryHealthChecker.Cancel()
+	}
+
+	return h.RespondWithData(c, true)
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"seanime/internal/database/db_bridge"
+	"seanime/internal/library/anime"
+	"seanime/internal/library/nfo"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HandleExportNFOFiles
+//
+//	@summary writes Kodi/Jellyfin NFO files and artwork for the matched local files.
+//	@desc Files are written next to the media files, or in 'mirrorDir' with the same layout as the library.
+//	@desc NFO files that were not created by Seanime are not overwritten.
+//	@route /api/v1/library/nfo/export [POST]
+//	@returns nfo.ExportResult
+func (h *Handler) HandleExportNFOFiles(c echo.Context) error {
+
+	var b nfo.ExportOptions
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	lfs, _, err := db_bridge.GetLocalFiles(h.App.Database)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	exporter, err := h.newNFOExporter()
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	res, err := exporter.Export(lfs, &b)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, res)
+}
+
+func (h *Handler) newNFOExporter() (*nfo.Exporter, error) {
+	libraryPaths, err := h.App.Database.GetAllLibraryPathsFromSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	animeCollection, err := h.App.GetAnimeCollection(false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nfo.Exporter{
+		Logger:           h.App.Logger,
+		Platform:         h.App.AnilistPlatform,
+		MetadataProvider: h.App.MetadataProvider,
+		AnimeCollection:  animeCollection,
+		LibraryPaths:     libraryPaths,
+	}, nil
+}
+
+// exportNFOFilesAfterScan updates the NFO files of the library if the export is enabled in the settings.
+func (h *Handler) exportNFOFilesAfterScan(lfs []*anime.LocalFile) {
+	settings := h.App.Settings.GetLibrary()
+	if settings == nil || !settings.ExportNFOFiles {
+		return
+	}
+
+	exporter, err := h.newNFOExporter()
+	if err != nil {
+		h.App.Logger.Error().Err(err).Msg("nfo: Failed to export NFO files")
+		return
+	}
+
+	if _, err := exporter.Export(lfs, nfo.NewExportOptions(settings)); err != nil {
+		h.App.Logger.Error().Err(err).Msg("nfo: Failed to export NFO files")
+	}
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"seanime/internal/library/organizer"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HandlePreviewLibraryOrganization
+//
+//	@summary returns the changes the library organizer would make.
+//	@desc This is a dry run, no file is modified.
+//	@route /api/v1/library/organizer/preview [POST]
+//	@returns organizer.Plan
+func (h *Handler) HandlePreviewLibraryOrganization(c echo.Context) error {
+
+	var b organizer.Options
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	o, err := h.newLibraryOrganizer()
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	plan, err := o.Preview(&b)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, plan)
+}
+
+// HandleOrganizeLibrary
+//
+//	@summary renames and moves matched files into the folder layout built from the template.
+//	@desc Files can be moved, hard-linked or copied. Files that are being seeded can be hard-linked instead of moved.
+//	@desc The destination must be inside a library path.
+//	@desc Moved files are updated in place. The sources of copied and hard-linked files are kept and marked as ignored.
+//	@desc Playlists and watch history are updated.
+//	@desc The files are organized in the background. Progress is sent through the "library-organizer-progress" websocket event
+//	@desc and the plan is sent through the "library-organizer-completed" websocket event.
+//	@desc The client should refetch the entire library collection and media entry once completed.
+//	@route /api/v1/library/organizer/organize [POST]
+//	@returns bool
+func (h *Handler) HandleOrganizeLibrary(c echo.Context) error {
+
+	var b organizer.Options
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	o, err := h.newLibraryOrganizer()
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	if err := o.Start(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, true)
+}
+
+func (h *Handler) newLibraryOrganizer() (*organizer.Organizer, error) {
+	libraryPaths, err := h.App.Database.GetAllLibraryPathsFromSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	animeCollection, err := h.App.GetAnimeCollection(false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &organizer.Organizer{
+		Logger:                  h.App.Logger,
+		Database:                h.App.Database,
+		AnimeCollection:         animeCollection,
+		ContinuityManager:       h.App.ContinuityManager,
+		TorrentClientRepository: h.App.TorrentClientRepository,
+		LibraryPaths:            libraryPaths,
+		WSEventManager:          h.App.WSEventManager,
+	}, nil
+}
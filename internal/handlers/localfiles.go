@@ -31,6 +31,27 @@ func (h *Handler) HandleGetLocalFiles(c echo.Context) error {
 	return h.RespondWithData(c, lfs)
 }
 
+// HandleGetDuplicateEpisodeVersions
+//
+//	@summary returns the episodes that have multiple versions in the library.
+//	@desc Versions are ranked using the user's preferences, the preferred version first.
+//	@desc The other versions can be deleted to free up space.
+//	@route /api/v1/library/local-files/duplicates [GET]
+//	@returns []anime.EpisodeVersionGroup
+func (h *Handler) HandleGetDuplicateEpisodeVersions(c echo.Context) error {
+
+	lfs, _, err := db_bridge.GetLocalFiles(h.App.Database)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	lfs = lo.Filter(lfs, func(lf *anime.LocalFile, _ int) bool {
+		return !lf.Ignored
+	})
+
+	return h.RespondWithData(c, anime.GetDuplicateEpisodeVersions(lfs, anime.NewVersionPreferences(h.App.Settings.GetLibrary())))
+}
+
 func (h *Handler) HandleDumpLocalFilesToFile(c echo.Context) error {
 
 	lfs, _, err := db_bridge.GetLocalFiles(h.App.Database)
@@ -194,6 +194,78 @@ func (h *Handler) HandleRefetchMangaChapterContainers(c echo.Context) error {
 	return nil
 }
 
+// HandleGetMangaChapterUpdates
+//
+//	@summary returns the new chapters that haven't been read, grouped by manga.
+//	@desc New chapters are recorded by the chapter update checker for manga with the "CURRENT" status.
+//	@desc Chapters below the user's progress and dismissed chapters are not returned.
+//	@route /api/v1/manga/chapter-updates [GET]
+//	@returns []manga.ChapterUpdateFeedItem
+func (h *Handler) HandleGetMangaChapterUpdates(c echo.Context) error {
+	mangaCollection, err := h.App.GetMangaCollection(false)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	ret, err := h.App.MangaChapterUpdateChecker.GetUnreadFeed(mangaCollection)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, ret)
+}
+
+// HandleCheckMangaChapterUpdates
+//
+//	@summary checks for new chapters now instead of waiting for the next scheduled check.
+//	@desc The selected provider map is optional, the provider the manga was last read with is used by default.
+//	@desc Returns the chapters that were found.
+//	@route /api/v1/manga/chapter-updates/check [POST]
+//	@returns []models.MangaChapterUpdate
+func (h *Handler) HandleCheckMangaChapterUpdates(c echo.Context) error {
+
+	type body struct {
+		SelectedProviderMap map[int]string `json:"selectedProviderMap"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	ret, err := h.App.MangaChapterUpdateChecker.CheckForUpdates(b.SelectedProviderMap)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, ret)
+}
+
+// HandleDismissMangaChapterUpdates
+//
+//	@summary removes chapter updates from the unread feed.
+//	@desc If no IDs are provided, all chapter updates are dismissed.
+//	@route /api/v1/manga/chapter-updates/dismiss [POST]
+//	@returns bool
+func (h *Handler) HandleDismissMangaChapterUpdates(c echo.Context) error {
+
+	type body struct {
+		IDs []uint `json:"ids"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	err := h.App.MangaChapterUpdateChecker.DismissUpdates(b.IDs)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, true)
+}
+
 // HandleEmptyMangaEntryCache
 //
 //	@summary empties the cache for a manga entry.
@@ -224,6 +296,8 @@ func (h *Handler) HandleEmptyMangaEntryCache(c echo.Context) error {
 // HandleGetMangaEntryChapters
 //
 //	@summary returns the chapters for a manga entry based on the provider.
+//	@desc If the provider is "merged", the chapters of the providers set in the manga settings are combined.
+//	@desc Each chapter of the merged list keeps the provider it comes from.
 //	@route /api/v1/manga/chapters [POST]
 //	@returns manga.ChapterContainer
 func (h *Handler) HandleGetMangaEntryChapters(c echo.Context) error {
@@ -252,6 +326,23 @@ func (h *Handler) HandleGetMangaEntryChapters(c echo.Context) error {
 		titles = baseManga.GetAllTitles()
 	}
 
+	// Combine the chapters of the providers set in the settings
+	if b.Provider == manga.MergedProvider {
+		mangaSettings := h.App.Settings.GetManga()
+		container, err := h.App.MangaRepository.GetMergedChapterContainer(&manga.GetMergedChapterContainerOptions{
+			MediaId:    b.MediaId,
+			Titles:     titles,
+			Year:       baseManga.GetStartYearSafe(),
+			Providers:  mangaSettings.MergeProviders,
+			Scanlators: mangaSettings.MergeScanlators,
+			Languages:  mangaSettings.MergeLanguages,
+		})
+		if err != nil {
+			return h.RespondWithError(c, err)
+		}
+		return h.RespondWithData(c, container)
+	}
+
 	container, err := h.App.MangaRepository.GetMangaChapterContainer(&manga.GetMangaChapterContainerOptions{
 		Provider: b.Provider,
 		MediaId:  b.MediaId,
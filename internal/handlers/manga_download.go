@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"path/filepath"
 	"seanime/internal/events"
 	"seanime/internal/manga"
 	chapter_downloader "seanime/internal/manga/downloader"
@@ -208,3 +209,42 @@ func (h *Handler) HandleGetMangaDownloadsList(c echo.Context) error {
 
 	return h.RespondWithData(c, res)
 }
+
+// HandleExportMangaChapters
+//
+//	@summary packages downloaded chapters into CBZ or EPUB files.
+//	@desc The format can be "cbz" (with ComicInfo.xml metadata) or "epub" (fixed layout).
+//	@desc Chapters are exported in one file per chapter unless 'singleFile' is true or a volume number is provided.
+//	@desc Pages can be resized and converted to grayscale for e-ink devices.
+//	@desc If 'sendToDevice' is true, the files are written to the "send to device" folder set in the manga settings.
+//	@desc Otherwise, they can be downloaded with HandleDownloadMangaExport.
+//	@route /api/v1/manga/export [POST]
+//	@returns []manga.ExportedFile
+func (h *Handler) HandleExportMangaChapters(c echo.Context) error {
+
+	var b manga.ExportChaptersOptions
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	res, err := h.App.MangaDownloader.ExportChapters(&b)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, res)
+}
+
+// HandleDownloadMangaExport
+//
+//	@summary downloads a file created by HandleExportMangaChapters.
+//	@route /api/v1/manga/export/:filename [GET]
+func (h *Handler) HandleDownloadMangaExport(c echo.Context) error {
+
+	path, err := h.App.MangaDownloader.GetExportPath(c.Param("filename"))
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return c.Attachment(path, filepath.Base(path))
+}
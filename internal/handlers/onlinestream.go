@@ -119,6 +119,26 @@ func (h *Handler) HandleOnlineStreamEmptyCache(c echo.Context) error {
 	return h.RespondWithData(c, true)
 }
 
+// HandleGetOnlinestreamProviderHealth
+//
+//	@summary returns the health records of the online streaming providers and their servers.
+//	@desc The score, between 0 and 100, is used to order the fallback providers and servers when fetching episode sources.
+//	@route /api/v1/onlinestream/provider-health [GET]
+//	@returns []onlinestream.ProviderHealth
+func (h *Handler) HandleGetOnlinestreamProviderHealth(c echo.Context) error {
+	return h.RespondWithData(c, h.App.OnlinestreamRepository.GetProviderHealth())
+}
+
+// HandleResetOnlinestreamProviderHealth
+//
+//	@summary removes the health records of the online streaming providers.
+//	@route /api/v1/onlinestream/provider-health [DELETE]
+//	@returns bool
+func (h *Handler) HandleResetOnlinestreamProviderHealth(c echo.Context) error {
+	h.App.OnlinestreamRepository.ResetProviderHealth()
+	return h.RespondWithData(c, true)
+}
+
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
 // HandleOnlinestreamManualSearch
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"errors"
+	"seanime/internal/onlinestream"
+	onlinestream_downloader "seanime/internal/onlinestream/downloader"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HandleDownloadOnlineStreamEpisode
+//
+//	@summary adds an episode to the online streaming download queue.
+//	@desc The video source is chosen by server and quality, if they are empty, the first source is used.
+//	@desc Once downloaded, the episode is remuxed to MKV with its subtitles and written to the library.
+//	@route /api/v1/onlinestream/download [POST]
+//	@returns bool
+func (h *Handler) HandleDownloadOnlineStreamEpisode(c echo.Context) error {
+
+	type body struct {
+		MediaId       int    `json:"mediaId"`
+		EpisodeNumber int    `json:"episodeNumber"`
+		Provider      string `json:"provider"`
+		Dubbed        bool   `json:"dubbed"`
+		Server        string `json:"server,omitempty"`
+		Quality       string `json:"quality,omitempty"`
+		StartNow      bool   `json:"startNow"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	media, err := h.App.OnlinestreamRepository.GetMedia(b.MediaId)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	sources, err := h.App.OnlinestreamRepository.GetEpisodeSources(b.Provider, b.MediaId, b.EpisodeNumber, b.Dubbed, media.GetStartYearSafe())
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	var videoSource *onlinestream.VideoSource
+	for _, vs := range sources.VideoSources {
+		if (b.Server == "" || vs.Server == b.Server) && (b.Quality == "" || vs.Quality == b.Quality) {
+			videoSource = vs
+			break
+		}
+	}
+	if videoSource == nil {
+		return h.RespondWithError(c, errors.New("no matching video source found"))
+	}
+
+	err = h.App.OnlinestreamDownloader.AddToQueue(onlinestream_downloader.DownloadOptions{
+		DownloadID: onlinestream_downloader.DownloadID{
+			// The provider can differ from the requested one if it failed
+			Provider:      sources.Provider,
+			MediaId:       b.MediaId,
+			EpisodeNumber: b.EpisodeNumber,
+			Dubbed:        b.Dubbed,
+		},
+		Title: media.GetRomajiTitleSafe(),
+		Source: &onlinestream_downloader.SourceData{
+			VideoSource: videoSource,
+			Subtitles:   sources.Subtitles,
+		},
+		StartNow: b.StartNow,
+	})
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, true)
+}
+
+// HandleGetOnlineStreamDownloadQueue
+//
+//	@summary returns the items in the online streaming download queue.
+//	@route /api/v1/onlinestream/download-queue [GET]
+//	@returns []models.OnlinestreamDownloadQueueItem
+func (h *Handler) HandleGetOnlineStreamDownloadQueue(c echo.Context) error {
+
+	data, err := h.App.OnlinestreamDownloader.GetQueue()
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, data)
+}
+
+// HandleGetOnlineStreamDownloadQueueCurrent
+//
+//	@summary returns the episode being downloaded and its progress.
+//	@desc Returns null if nothing is being downloaded.
+//	@desc The progress is also sent through the 'onlinestream-download-progress' websocket event.
+//	@route /api/v1/onlinestream/download-queue/current [GET]
+//	@returns onlinestream_downloader.QueueInfo
+func (h *Handler) HandleGetOnlineStreamDownloadQueueCurrent(c echo.Context) error {
+
+	current, ok := h.App.OnlinestreamDownloader.GetCurrent()
+	if !ok {
+		return h.RespondWithData(c, nil)
+	}
+
+	return h.RespondWithData(c, current)
+}
+
+// HandleStartOnlineStreamDownloadQueue
+//
+//	@summary starts the online streaming download queue if it's not already running.
+//	@route /api/v1/onlinestream/download-queue/start [POST]
+//	@returns bool
+func (h *Handler) HandleStartOnlineStreamDownloadQueue(c echo.Context) error {
+
+	h.App.OnlinestreamDownloader.Run()
+
+	return h.RespondWithData(c, true)
+}
+
+// HandleStopOnlineStreamDownloadQueue
+//
+//	@summary stops the online streaming download queue.
+//	@desc The episode being downloaded is canceled and put back in the queue.
+//	@route /api/v1/onlinestream/download-queue/stop [POST]
+//	@returns bool
+func (h *Handler) HandleStopOnlineStreamDownloadQueue(c echo.Context) error {
+
+	h.App.OnlinestreamDownloader.Stop()
+
+	return h.RespondWithData(c, true)
+}
+
+// HandleRemoveOnlineStreamDownloadQueueItem
+//
+//	@summary removes an episode from the online streaming download queue.
+//	@route /api/v1/onlinestream/download-queue/item [DELETE]
+//	@returns bool
+func (h *Handler) HandleRemoveOnlineStreamDownloadQueueItem(c echo.Context) error {
+
+	type body struct {
+		MediaId       int    `json:"mediaId"`
+		EpisodeNumber int    `json:"episodeNumber"`
+		Provider      string `json:"provider"`
+		Dubbed        bool   `json:"dubbed"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	err := h.App.OnlinestreamDownloader.RemoveFromQueue(onlinestream_downloader.DownloadID{
+		Provider:      b.Provider,
+		MediaId:       b.MediaId,
+		EpisodeNumber: b.EpisodeNumber,
+		Dubbed:        b.Dubbed,
+	})
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, true)
+}
+
+// HandleClearOnlineStreamDownloadQueue
+//
+//	@summary clears the online streaming download queue.
+//	@desc The episode being downloaded is not removed.
+//	@route /api/v1/onlinestream/download-queue [DELETE]
+//	@returns bool
+func (h *Handler) HandleClearOnlineStreamDownloadQueue(c echo.Context) error {
+
+	err := h.App.OnlinestreamDownloader.ClearQueue()
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, true)
+}
+
+// HandleResetErroredOnlineStreamDownloadQueue
+//
+//	@summary resets the errored episodes in the online streaming download queue.
+//	@route /api/v1/onlinestream/download-queue/reset-errored [POST]
+//	@returns bool
+func (h *Handler) HandleResetErroredOnlineStreamDownloadQueue(c echo.Context) error {
+
+	err := h.App.OnlinestreamDownloader.ResetErroredItems()
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, true)
+}
@@ -35,6 +35,39 @@ func (h *Handler) HandlePlaybackPlayVideo(c echo.Context) error {
 	return h.RespondWithData(c, true)
 }
 
+// HandlePlaybackPlayEpisode
+//
+//	@summary plays an episode using the default media player.
+//	@desc If the path is empty, the preferred version of the episode is played.
+//	@desc Otherwise, the path should be one of the versions of the episode.
+//	@desc This returns 'true' if the video was successfully played.
+//	@route /api/v1/playback-manager/play-episode [POST]
+//	@returns bool
+func (h *Handler) HandlePlaybackPlayEpisode(c echo.Context) error {
+	type body struct {
+		MediaId       int    `json:"mediaId"`
+		EpisodeNumber int    `json:"episodeNumber"`
+		Path          string `json:"path"`
+	}
+	b := new(body)
+	if err := c.Bind(b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	err := h.App.PlaybackManager.StartPlayingEpisode(&playbackmanager.StartPlayingEpisodeOptions{
+		MediaId:       b.MediaId,
+		EpisodeNumber: b.EpisodeNumber,
+		Path:          b.Path,
+		UserAgent:     c.Request().Header.Get("User-Agent"),
+		ClientId:      "",
+	})
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, true)
+}
+
 // HandlePlaybackPlayRandomVideo
 //
 //	@summary plays a random, unwatched video using the default media player.
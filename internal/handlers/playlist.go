@@ -13,14 +13,16 @@ import (
 //
 //	@summary creates a new playlist.
 //	@desc This will create a new playlist with the given name and local file paths.
+//	@desc If 'items' is provided, episodes that are not in the library are streamed.
 //	@desc The response is ignored, the client should re-fetch the playlists after this.
 //	@route /api/v1/playlist [POST]
 //	@returns anime.Playlist
 func (h *Handler) HandleCreatePlaylist(c echo.Context) error {
 
 	type body struct {
-		Name  string   `json:"name"`
-		Paths []string `json:"paths"`
+		Name  string                `json:"name"`
+		Paths []string              `json:"paths"`
+		Items []*anime.PlaylistItem `json:"items"` // Optional, episodes that are played from local files or streams
 	}
 
 	var b body
@@ -48,6 +50,10 @@ func (h *Handler) HandleCreatePlaylist(c echo.Context) error {
 	// Create the playlist
 	playlist := anime.NewPlaylist(b.Name)
 	playlist.SetLocalFiles(lfs)
+	if len(b.Items) > 0 {
+		playlist.Items = b.Items
+		playlist.ResolveLocalFiles(dbLfs)
+	}
 
 	// Save the playlist
 	if err := db_bridge.SavePlaylist(h.App.Database, playlist); err != nil {
@@ -83,9 +89,10 @@ func (h *Handler) HandleGetPlaylists(c echo.Context) error {
 func (h *Handler) HandleUpdatePlaylist(c echo.Context) error {
 
 	type body struct {
-		DbId  uint     `json:"dbId"`
-		Name  string   `json:"name"`
-		Paths []string `json:"paths"`
+		DbId  uint                  `json:"dbId"`
+		Name  string                `json:"name"`
+		Paths []string              `json:"paths"`
+		Items []*anime.PlaylistItem `json:"items"` // Optional, episodes that are played from local files or streams
 	}
 
 	var b body
@@ -115,6 +122,10 @@ func (h *Handler) HandleUpdatePlaylist(c echo.Context) error {
 	playlist.DbId = b.DbId
 	playlist.Name = b.Name
 	playlist.SetLocalFiles(lfs)
+	if len(b.Items) > 0 {
+		playlist.Items = b.Items
+		playlist.ResolveLocalFiles(dbLfs)
+	}
 
 	// Save the playlist
 	if err := db_bridge.UpdatePlaylist(h.App.Database, playlist); err != nil {
@@ -183,3 +194,45 @@ func (h *Handler) HandleGetPlaylistEpisodes(c echo.Context) error {
 
 	return h.RespondWithData(c, toWatch)
 }
+
+// HandleGetPlaylistItems
+//
+//	@summary returns playlist items for all the episodes of a media that have not been watched.
+//	@desc Items of episodes that are in the library have their local file set, the others are streamed.
+//	@route /api/v1/playlist/items/{id}/{progress} [GET]
+//	@param id - int - true - "The ID of the media entry."
+//	@param progress - int - true - "The progress of the media entry."
+//	@returns []anime.PlaylistItem
+func (h *Handler) HandleGetPlaylistItems(c echo.Context) error {
+
+	mId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+	progress, err := strconv.Atoi(c.Param("progress"))
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	media, err := h.App.AnilistPlatform.GetAnime(mId)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	lfs, _, err := db_bridge.GetLocalFiles(h.App.Database)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	playlist := anime.NewPlaylist("")
+	playlist.Items = make([]*anime.PlaylistItem, 0)
+	for ep := progress + 1; ep <= media.GetCurrentEpisodeCount(); ep++ {
+		playlist.Items = append(playlist.Items, &anime.PlaylistItem{
+			MediaId:       mId,
+			EpisodeNumber: ep,
+		})
+	}
+	playlist.ResolveLocalFiles(lfs)
+
+	return h.RespondWithData(c, playlist.Items)
+}
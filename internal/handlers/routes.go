@@ -201,11 +201,21 @@ func InitRoutes(app *core.App, e *echo.Echo) {
 
 	v1Library.DELETE("/empty-directories", h.HandleRemoveEmptyDirectories)
 
+	v1Library.POST("/organizer/preview", h.HandlePreviewLibraryOrganization)
+	v1Library.POST("/organizer/organize", h.HandleOrganizeLibrary)
+
+	v1Library.POST("/nfo/export", h.HandleExportNFOFiles)
+
+	v1Library.POST("/health-check", h.HandleStartLibraryHealthCheck)
+	v1Library.DELETE("/health-check", h.HandleCancelLibraryHealthCheck)
+	v1Library.GET("/health-check/report", h.HandleGetLibraryHealthCheckReport)
+
 	v1Library.GET("/local-files", h.HandleGetLocalFiles)
 	v1Library.POST("/local-files", h.HandleLocalFileBulkAction)
 	v1Library.PATCH("/local-files", h.HandleUpdateLocalFiles)
 	v1Library.DELETE("/local-files", h.HandleDeleteLocalFiles)
 	v1Library.GET("/local-files/dump", h.HandleDumpLocalFilesToFile)
+	v1Library.GET("/local-files/duplicates", h.HandleGetDuplicateEpisodeVersions)
 	v1Library.POST("/local-files/import", h.HandleImportLocalFiles)
 	v1Library.PATCH("/local-file", h.HandleUpdateLocalFileData)
 
@@ -227,6 +237,12 @@ func InitRoutes(app *core.App, e *echo.Echo) {
 
 	v1Library.POST("/unknown-media", h.HandleAddUnknownMedia)
 
+	v1Library.GET("/title-aliases", h.HandleGetTitleAliases)
+	v1Library.GET("/title-aliases/export", h.HandleExportTitleAliases)
+	v1Library.POST("/title-aliases/import", h.HandleImportTitleAliases)
+	v1Library.POST("/title-alias", h.HandleSaveTitleAlias)
+	v1Library.DELETE("/title-alias", h.HandleDeleteTitleAlias)
+
 	//
 	// Torrent / Torrent Client
 	//
@@ -272,6 +288,7 @@ func InitRoutes(app *core.App, e *echo.Echo) {
 	v1.POST("/playback-manager/autoplay-next-episode", h.HandlePlaybackAutoPlayNextEpisode)
 	v1.POST("/playback-manager/play", h.HandlePlaybackPlayVideo)
 	v1.POST("/playback-manager/play-random", h.HandlePlaybackPlayRandomVideo)
+	v1.POST("/playback-manager/play-episode", h.HandlePlaybackPlayEpisode)
 	//------------
 	v1.POST("/playback-manager/manual-tracking/start", h.HandlePlaybackStartManualTracking)
 	v1.POST("/playback-manager/manual-tracking/cancel", h.HandlePlaybackCancelManualTracking)
@@ -285,6 +302,7 @@ func InitRoutes(app *core.App, e *echo.Echo) {
 	v1.PATCH("/playlist", h.HandleUpdatePlaylist)
 	v1.DELETE("/playlist", h.HandleDeletePlaylist)
 	v1.GET("/playlist/episodes/:id/:progress", h.HandleGetPlaylistEpisodes)
+	v1.GET("/playlist/items/:id/:progress", h.HandleGetPlaylistItems)
 
 	//
 	// Onlinestream
@@ -293,6 +311,16 @@ func InitRoutes(app *core.App, e *echo.Echo) {
 	v1.POST("/onlinestream/episode-source", h.HandleGetOnlineStreamEpisodeSource)
 	v1.POST("/onlinestream/episode-list", h.HandleGetOnlineStreamEpisodeList)
 	v1.DELETE("/onlinestream/cache", h.HandleOnlineStreamEmptyCache)
+	v1.GET("/onlinestream/provider-health", h.HandleGetOnlinestreamProviderHealth)
+	v1.DELETE("/onlinestream/provider-health", h.HandleResetOnlinestreamProviderHealth)
+	v1.POST("/onlinestream/download", h.HandleDownloadOnlineStreamEpisode)
+	v1.GET("/onlinestream/download-queue", h.HandleGetOnlineStreamDownloadQueue)
+	v1.GET("/onlinestream/download-queue/current", h.HandleGetOnlineStreamDownloadQueueCurrent)
+	v1.POST("/onlinestream/download-queue/start", h.HandleStartOnlineStreamDownloadQueue)
+	v1.POST("/onlinestream/download-queue/stop", h.HandleStopOnlineStreamDownloadQueue)
+	v1.DELETE("/onlinestream/download-queue/item", h.HandleRemoveOnlineStreamDownloadQueueItem)
+	v1.DELETE("/onlinestream/download-queue", h.HandleClearOnlineStreamDownloadQueue)
+	v1.POST("/onlinestream/download-queue/reset-errored", h.HandleResetErroredOnlineStreamDownloadQueue)
 
 	v1.POST("/onlinestream/search", h.HandleOnlinestreamManualSearch)
 	v1.POST("/onlinestream/manual-mapping", h.HandleOnlinestreamManualMapping)
@@ -321,6 +349,9 @@ func InitRoutes(app *core.App, e *echo.Echo) {
 	v1Manga.GET("/collection", h.HandleGetMangaCollection)
 	v1Manga.GET("/latest-chapter-numbers", h.HandleGetMangaLatestChapterNumbersMap)
 	v1Manga.POST("/refetch-chapter-containers", h.HandleRefetchMangaChapterContainers)
+	v1Manga.GET("/chapter-updates", h.HandleGetMangaChapterUpdates)
+	v1Manga.POST("/chapter-updates/check", h.HandleCheckMangaChapterUpdates)
+	v1Manga.POST("/chapter-updates/dismiss", h.HandleDismissMangaChapterUpdates)
 	v1Manga.GET("/entry/:id", h.HandleGetMangaEntry)
 	v1Manga.GET("/entry/:id/details", h.HandleGetMangaEntryDetails)
 	v1Manga.DELETE("/entry/cache", h.HandleEmptyMangaEntryCache)
@@ -338,6 +369,8 @@ func InitRoutes(app *core.App, e *echo.Echo) {
 	v1Manga.POST("/download-queue/stop", h.HandleStopMangaDownloadQueue)
 	v1Manga.DELETE("/download-queue", h.HandleClearAllChapterDownloadQueue)
 	v1Manga.POST("/download-queue/reset-errored", h.HandleResetErroredChapterDownloadQueue)
+	v1Manga.POST("/export", h.HandleExportMangaChapters)
+	v1Manga.GET("/export/:filename", h.HandleDownloadMangaExport)
 
 	v1Manga.POST("/search", h.HandleMangaManualSearch)
 	v1Manga.POST("/manual-mapping", h.HandleMangaManualMapping)
@@ -392,6 +425,9 @@ func InitRoutes(app *core.App, e *echo.Echo) {
 	v1.POST("/torrentstream/drop", h.HandleTorrentstreamDropTorrent)
 	v1.POST("/torrentstream/torrent-file-previews", h.HandleGetTorrentstreamTorrentFilePreviews)
 	v1.POST("/torrentstream/batch-history", h.HandleGetTorrentstreamBatchHistory)
+	v1.GET("/torrentstream/sessions", h.HandleGetTorrentstreamSessions)
+	v1.POST("/torrentstream/sessions/stop", h.HandleTorrentstreamStopSession)
+	v1.GET("/torrentstream/stream/:sessionId/*", echo.WrapHandler(h.HandleTorrentstreamServeStream()))
 	v1.GET("/torrentstream/stream/*", echo.WrapHandler(h.HandleTorrentstreamServeStream()))
 
 	//
@@ -403,6 +439,7 @@ func InitRoutes(app *core.App, e *echo.Echo) {
 	v1Extensions.POST("/external/fetch", h.HandleFetchExternalExtensionData)
 	v1Extensions.POST("/external/install", h.HandleInstallExternalExtension)
 	v1Extensions.POST("/external/uninstall", h.HandleUninstallExternalExtension)
+	v1Extensions.POST("/external/rollback", h.HandleRollbackExternalExtension)
 	v1Extensions.POST("/external/edit-payload", h.HandleUpdateExtensionCode)
 	v1Extensions.POST("/external/reload", h.HandleReloadExternalExtensions)
 	v1Extensions.POST("/external/reload", h.HandleReloadExternalExtension)
@@ -414,13 +451,25 @@ func InitRoutes(app *core.App, e *echo.Echo) {
 	v1Extensions.GET("/list/manga-provider", h.HandleListMangaProviderExtensions)
 	v1Extensions.GET("/list/onlinestream-provider", h.HandleListOnlinestreamProviderExtensions)
 	v1Extensions.GET("/list/anime-torrent-provider", h.HandleListAnimeTorrentProviderExtensions)
+	v1Extensions.GET("/list/metadata-provider", h.HandleListMetadataProviderExtensions)
 	v1Extensions.GET("/user-config/:id", h.HandleGetExtensionUserConfig)
 	v1Extensions.POST("/user-config", h.HandleSaveExtensionUserConfig)
 	v1Extensions.GET("/marketplace", h.HandleGetMarketplaceExtensions)
+	v1Extensions.GET("/sources", h.HandleListExtensionSources)
+	v1Extensions.POST("/sources", h.HandleAddExtensionSource)
+	v1Extensions.DELETE("/sources", h.HandleRemoveExtensionSource)
+	v1Extensions.GET("/sources/extensions", h.HandleListExtensionSourceExtensions)
+	v1Extensions.GET("/update-policies", h.HandleListExtensionUpdatePolicies)
+	v1Extensions.POST("/update-policy", h.HandleSetExtensionUpdatePolicy)
 	v1Extensions.GET("/plugin-settings", h.HandleGetPluginSettings)
 	v1Extensions.POST("/plugin-settings/pinned-trays", h.HandleSetPluginSettingsPinnedTrays)
 	v1Extensions.POST("/plugin-permissions/grant", h.HandleGrantPluginPermissions)
 
+	//
+	// Plugins
+	//
+	v1.Any("/plugins/:id/*", h.HandlePluginEndpoint)
+
 	//
 	// Continuity
 	//
@@ -428,6 +477,9 @@ func InitRoutes(app *core.App, e *echo.Echo) {
 	v1Continuity.PATCH("/item", h.HandleUpdateContinuityWatchHistoryItem)
 	v1Continuity.GET("/item/:id", h.HandleGetContinuityWatchHistoryItem)
 	v1Continuity.GET("/history", h.HandleGetContinuityWatchHistory)
+	v1Continuity.POST("/watch-log", h.HandleGetWatchLog)
+	v1Continuity.POST("/watch-log/stats", h.HandleGetWatchLogStats)
+	v1Continuity.DELETE("/watch-log-entry", h.HandleDeleteWatchLogEntry)
 
 	//
 	// Sync
@@ -480,6 +532,12 @@ func (h *Handler) RespondWithError(c echo.Context, err error) error {
 	return c.JSON(500, NewErrorResponse(err))
 }
 
+// getClientId returns the client ID set by the client ID middleware.
+func getClientId(c echo.Context) string {
+	clientId, _ := c.Get("Seanime-Client-Id").(string)
+	return clientId
+}
+
 func headMethodMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		if c.Request().Method == http.MethodHead {
@@ -75,6 +75,9 @@ func (h *Handler) HandleScanLocalFiles(c echo.Context) error {
 		MetadataProvider:   h.App.MetadataProvider,
 		MatchingAlgorithm:  h.App.Settings.GetLibrary().ScannerMatchingAlgorithm,
 		MatchingThreshold:  h.App.Settings.GetLibrary().ScannerMatchingThreshold,
+		FileIdentifier:     scanner.NewFileIdentifier(h.App.Settings.GetLibrary(), h.App.FileCacher, h.App.Logger),
+		TitleAliases:       h.getTitleAliases(),
+		NFOReader:          scanner.NewNFOReader(h.App.Settings.GetLibrary()),
 	}
 
 	// Scan the library
@@ -98,6 +101,8 @@ func (h *Handler) HandleScanLocalFiles(c echo.Context) error {
 
 	go h.App.AutoDownloader.CleanUpDownloadedItems()
 
+	go h.exportNFOFilesAfterScan(lfs)
+
 	return h.RespondWithData(c, lfs)
 
 }
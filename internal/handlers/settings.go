@@ -250,6 +250,7 @@ func (h *Handler) HandleSaveAutoDownloaderSettings(c echo.Context) error {
 		EnableEnhancedQueries bool `json:"enableEnhancedQueries"`
 		EnableSeasonCheck     bool `json:"enableSeasonCheck"`
 		UseDebrid             bool `json:"useDebrid"`
+		UseQbittorrentRss     bool `json:"useQbittorrentRss"`
 	}
 
 	var b body
@@ -276,6 +277,7 @@ func (h *Handler) HandleSaveAutoDownloaderSettings(c echo.Context) error {
 		EnableEnhancedQueries: b.EnableEnhancedQueries,
 		EnableSeasonCheck:     b.EnableSeasonCheck,
 		UseDebrid:             b.UseDebrid,
+		UseQbittorrentRss:     b.UseQbittorrentRss,
 	}
 
 	currSettings.AutoDownloader = autoDownloaderSettings
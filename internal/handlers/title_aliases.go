@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"seanime/internal/database/models"
+	"seanime/internal/library/anime"
+	"seanime/internal/library/scanner"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/labstack/echo/v4"
+)
+
+// HandleGetTitleAliases
+//
+//	@summary returns all title aliases.
+//	@desc Title aliases are learned from manual matches and are used by the scanner before matching files.
+//	@route /api/v1/library/title-aliases [GET]
+//	@returns []models.TitleAlias
+func (h *Handler) HandleGetTitleAliases(c echo.Context) error {
+
+	aliases, err := h.App.Database.GetTitleAliases()
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, aliases)
+}
+
+// HandleSaveTitleAlias
+//
+//	@summary creates or updates a title alias.
+//	@desc If the ID is 0, the alias is created or replaces the alias with the same title, release group and season.
+//	@desc The title is normalized before being saved.
+//	@route /api/v1/library/title-alias [POST]
+//	@returns models.TitleAlias
+func (h *Handler) HandleSaveTitleAlias(c echo.Context) error {
+
+	var b models.TitleAlias
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	b.Title = scanner.NormalizeAliasTitle(b.Title)
+	if b.Title == "" {
+		return h.RespondWithError(c, errors.New("title is required"))
+	}
+	if b.MediaId == 0 {
+		return h.RespondWithError(c, errors.New("media ID is required"))
+	}
+
+	if b.ID == 0 {
+		alias, err := h.App.Database.UpsertTitleAlias(&b)
+		if err != nil {
+			return h.RespondWithError(c, err)
+		}
+		return h.RespondWithData(c, alias)
+	}
+
+	existing, err := h.App.Database.GetTitleAlias(b.ID)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	existing.Title = b.Title
+	existing.ReleaseGroup = b.ReleaseGroup
+	existing.Season = b.Season
+	existing.MediaId = b.MediaId
+	existing.EpisodeOffset = b.EpisodeOffset
+
+	if err := h.App.Database.UpdateTitleAlias(existing); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, existing)
+}
+
+// HandleDeleteTitleAlias
+//
+//	@summary deletes a title alias.
+//	@route /api/v1/library/title-alias [DELETE]
+//	@returns bool
+func (h *Handler) HandleDeleteTitleAlias(c echo.Context) error {
+
+	type body struct {
+		ID uint `json:"id"`
+	}
+
+	b := new(body)
+	if err := c.Bind(b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	if err := h.App.Database.DeleteTitleAlias(b.ID); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, true)
+}
+
+// HandleExportTitleAliases
+//
+//	@summary downloads all title aliases as a JSON file.
+//	@desc The file can be imported with HandleImportTitleAliases.
+//	@route /api/v1/library/title-aliases/export [GET]
+func (h *Handler) HandleExportTitleAliases(c echo.Context) error {
+
+	aliases, err := h.App.Database.GetTitleAliases()
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	filename := fmt.Sprintf("seanime-title-aliases-%s.json", time.Now().Format("2006-01-02_15-04-05"))
+
+	c.Response().Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	c.Response().Header().Set("Content-Type", "application/json")
+
+	jsonData, err := json.MarshalIndent(aliases, "", "  ")
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return c.Blob(200, "application/json", jsonData)
+}
+
+// HandleImportTitleAliases
+//
+//	@summary imports title aliases.
+//	@desc Imported aliases replace the existing aliases with the same title, release group and season.
+//	@route /api/v1/library/title-aliases/import [POST]
+//	@returns []models.TitleAlias
+func (h *Handler) HandleImportTitleAliases(c echo.Context) error {
+
+	type body struct {
+		Aliases []*models.TitleAlias `json:"aliases"`
+	}
+
+	b := new(body)
+	if err := c.Bind(b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	if len(b.Aliases) == 0 {
+		return h.RespondWithError(c, errors.New("no title aliases found"))
+	}
+
+	for _, alias := range b.Aliases {
+		if alias == nil || alias.MediaId == 0 {
+			continue
+		}
+		alias.Title = scanner.NormalizeAliasTitle(alias.Title)
+		if alias.Title == "" {
+			continue
+		}
+		if _, err := h.App.Database.UpsertTitleAlias(alias); err != nil {
+			return h.RespondWithError(c, err)
+		}
+	}
+
+	aliases, err := h.App.Database.GetTitleAliases()
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, aliases)
+}
+
+// learnTitleAliases saves title aliases from manually matched local files.
+func (h *Handler) learnTitleAliases(lfs []*anime.LocalFile, mediaId int) {
+	for _, alias := range scanner.LearnTitleAliases(lfs, mediaId) {
+		if _, err := h.App.Database.UpsertTitleAlias(alias); err != nil {
+			h.App.Logger.Warn().Err(err).Str("title", alias.Title).Msg("scanner: Failed to save title alias")
+		}
+	}
+}
+
+// getTitleAliases returns the title aliases used by the scanner.
+func (h *Handler) getTitleAliases() *scanner.TitleAliases {
+	aliases, err := h.App.Database.GetTitleAliases()
+	if err != nil {
+		h.App.Logger.Warn().Err(err).Msg("scanner: Failed to get title aliases")
+		return nil
+	}
+	return scanner.NewTitleAliases(aliases)
+}
@@ -68,12 +68,9 @@ func (h *Handler) HandleSearchTorrent(c echo.Context) error {
 		var found bool
 		data.DebridInstantAvailability, found = debridInstantAvailabilityCache.Get(hashesKey)
 		if !found {
-			provider, err := h.App.DebridClientRepository.GetProvider()
-			if err == nil {
-				instantAvail := provider.GetInstantAvailability(hashes)
-				data.DebridInstantAvailability = instantAvail
-				debridInstantAvailabilityCache.Set(hashesKey, instantAvail)
-			}
+			instantAvail := h.App.DebridClientRepository.GetInstantAvailability(hashes)
+			data.DebridInstantAvailability = instantAvail
+			debridInstantAvailabilityCache.Set(hashesKey, instantAvail)
 		}
 	}
 
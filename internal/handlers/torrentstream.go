@@ -163,7 +163,7 @@ func (h *Handler) HandleTorrentstreamStartStream(c echo.Context) error {
 		AutoSelect    bool                        `json:"autoSelect"`
 		Torrent       *hibiketorrent.AnimeTorrent `json:"torrent,omitempty"` // Nil if autoSelect is true
 		FileIndex     *int                        `json:"fileIndex,omitempty"`
-		PlaybackType  torrentstream.PlaybackType  `json:"playbackType"` // "default" or "externalPlayerLink"
+		PlaybackType  torrentstream.PlaybackType  `json:"playbackType"` // "default", "externalPlayerLink" or "transcode"
 		ClientId      string                      `json:"clientId"`
 	}
 
@@ -195,13 +195,14 @@ func (h *Handler) HandleTorrentstreamStartStream(c echo.Context) error {
 // HandleTorrentstreamStopStream
 //
 //	@summary stop a torrent stream.
-//	@desc This stops the entire streaming process and drops the torrent if it's below a threshold.
+//	@desc This stops the last stream started by the client and drops its torrent if it's below a threshold.
+//	@desc Streams started by other clients are not affected.
 //	@desc This is made to be used while the stream is running.
 //	@returns bool
 //	@route /api/v1/torrentstream/stop [POST]
 func (h *Handler) HandleTorrentstreamStopStream(c echo.Context) error {
 
-	err := h.App.TorrentstreamRepository.StopStream()
+	err := h.App.TorrentstreamRepository.StopStream(getClientId(c))
 	if err != nil {
 		return h.RespondWithError(c, err)
 	}
@@ -212,13 +213,48 @@ func (h *Handler) HandleTorrentstreamStopStream(c echo.Context) error {
 // HandleTorrentstreamDropTorrent
 //
 //	@summary drops a torrent stream.
-//	@desc This stops the entire streaming process and drops the torrent completely.
+//	@desc This stops the streams started by the client and drops their torrents completely.
+//	@desc Torrents that are no longer streamed are also dropped, streams started by other clients are not affected.
 //	@desc This is made to be used to force drop a torrent.
 //	@returns bool
 //	@route /api/v1/torrentstream/drop [POST]
 func (h *Handler) HandleTorrentstreamDropTorrent(c echo.Context) error {
 
-	err := h.App.TorrentstreamRepository.DropTorrent()
+	err := h.App.TorrentstreamRepository.DropTorrent(getClientId(c))
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, true)
+}
+
+// HandleGetTorrentstreamSessions
+//
+//	@summary returns the running torrent streams.
+//	@desc Each session streams its own torrent and file, several sessions can run at the same time.
+//	@returns []torrentstream.StreamSession
+//	@route /api/v1/torrentstream/sessions [GET]
+func (h *Handler) HandleGetTorrentstreamSessions(c echo.Context) error {
+	return h.RespondWithData(c, h.App.TorrentstreamRepository.ListSessions())
+}
+
+// HandleTorrentstreamStopSession
+//
+//	@summary stops a torrent stream session.
+//	@desc This stops the session and drops its torrent if it's below a threshold.
+//	@desc Other sessions are not affected.
+//	@returns bool
+//	@route /api/v1/torrentstream/sessions/stop [POST]
+func (h *Handler) HandleTorrentstreamStopSession(c echo.Context) error {
+	type body struct {
+		SessionId string `json:"sessionId"`
+	}
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	err := h.App.TorrentstreamRepository.StopSession(b.SessionId)
 	if err != nil {
 		return h.RespondWithError(c, err)
 	}
@@ -246,6 +282,7 @@ func (h *Handler) HandleGetTorrentstreamBatchHistory(c echo.Context) error {
 }
 
 // route /api/v1/torrentstream/stream/*
+// route /api/v1/torrentstream/stream/:sessionId/*
 func (h *Handler) HandleTorrentstreamServeStream() http.Handler {
 	return h.App.TorrentstreamRepository.HTTPStreamHandler()
 }
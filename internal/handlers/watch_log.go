@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"seanime/internal/continuity"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HandleGetWatchLog
+//
+//	@summary returns the watch log entries.
+//	@desc Entries are returned oldest first. 'from' and 'to' filter by start time and are optional.
+//	@desc If 'mediaId' is not 0, only the entries of that media are returned.
+//	@route /api/v1/continuity/watch-log [POST]
+//	@returns []models.WatchLogEntry
+func (h *Handler) HandleGetWatchLog(c echo.Context) error {
+
+	var b continuity.WatchLogOptions
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	entries, err := h.App.ContinuityManager.GetWatchLog(&b)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, entries)
+}
+
+// HandleGetWatchLogStats
+//
+//	@summary returns viewing statistics computed from the watch log.
+//	@desc This does not require an AniList account.
+//	@route /api/v1/continuity/watch-log/stats [POST]
+//	@returns continuity.WatchLogStats
+func (h *Handler) HandleGetWatchLogStats(c echo.Context) error {
+
+	var b continuity.WatchLogOptions
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	stats, err := h.App.ContinuityManager.GetWatchLogStats(&b)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, stats)
+}
+
+// HandleDeleteWatchLogEntry
+//
+//	@summary deletes an entry from the watch log.
+//	@route /api/v1/continuity/watch-log-entry [DELETE]
+//	@returns bool
+func (h *Handler) HandleDeleteWatchLogEntry(c echo.Context) error {
+
+	type body struct {
+		ID uint `json:"id"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	if err := h.App.ContinuityManager.DeleteWatchLogEntry(b.ID); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, true)
+}
@@ -91,6 +91,11 @@ type Manager interface {
 	OnMangaChapterContainerRequested() *Hook[hook_resolver.Resolver]
 	OnMangaChapterContainer() *Hook[hook_resolver.Resolver]
 
+	// Manga chapter downloader events
+	OnMangaChapterDownloadQueued() *Hook[hook_resolver.Resolver]
+	OnMangaChapterDownloadRequested() *Hook[hook_resolver.Resolver]
+	OnMangaChapterDownloaded() *Hook[hook_resolver.Resolver]
+
 	// Playback events
 	OnLocalFilePlaybackRequested() *Hook[hook_resolver.Resolver]
 	OnPlaybackBeforeTracking() *Hook[hook_resolver.Resolver]
@@ -112,6 +117,22 @@ type Manager interface {
 	OnTorrentStreamAutoSelectTorrentsFetched() *Hook[hook_resolver.Resolver]
 	OnTorrentStreamSendStreamToMediaPlayer() *Hook[hook_resolver.Resolver]
 
+	// Torrent client events
+	OnTorrentClientAddTorrentsRequested() *Hook[hook_resolver.Resolver]
+	OnTorrentClientTorrentsAdded() *Hook[hook_resolver.Resolver]
+	OnTorrentClientActionRequested() *Hook[hook_resolver.Resolver]
+	OnTorrentClientAction() *Hook[hook_resolver.Resolver]
+
+	// Online stream events
+	OnOnlinestreamEpisodeListRequested() *Hook[hook_resolver.Resolver]
+	OnOnlinestreamEpisodeList() *Hook[hook_resolver.Resolver]
+	OnOnlinestreamEpisodeSourceRequested() *Hook[hook_resolver.Resolver]
+	OnOnlinestreamEpisodeSource() *Hook[hook_resolver.Resolver]
+
+	// Media stream events
+	OnMediastreamPlaybackRequested() *Hook[hook_resolver.Resolver]
+	OnMediastreamMediaContainer() *Hook[hook_resolver.Resolver]
+
 	// Continuity events
 	OnWatchHistoryItemRequested() *Hook[hook_resolver.Resolver]
 	OnWatchHistoryItemUpdated() *Hook[hook_resolver.Resolver]
@@ -197,6 +218,10 @@ type ManagerImpl struct {
 	onMangaDownloadMap                          *Hook[hook_resolver.Resolver]
 	onMangaChapterContainerRequested            *Hook[hook_resolver.Resolver]
 	onMangaChapterContainer                     *Hook[hook_resolver.Resolver]
+	// Manga chapter downloader events
+	onMangaChapterDownloadQueued    *Hook[hook_resolver.Resolver]
+	onMangaChapterDownloadRequested *Hook[hook_resolver.Resolver]
+	onMangaChapterDownloaded        *Hook[hook_resolver.Resolver]
 	// Playback events
 	onLocalFilePlaybackRequested        *Hook[hook_resolver.Resolver]
 	onPlaybackBeforeTracking            *Hook[hook_resolver.Resolver]
@@ -214,6 +239,19 @@ type ManagerImpl struct {
 	// Torrent stream events
 	onTorrentStreamAutoSelectTorrentsFetched *Hook[hook_resolver.Resolver]
 	onTorrentStreamSendStreamToMediaPlayer   *Hook[hook_resolver.Resolver]
+	// Torrent client events
+	onTorrentClientAddTorrentsRequested *Hook[hook_resolver.Resolver]
+	onTorrentClientTorrentsAdded        *Hook[hook_resolver.Resolver]
+	onTorrentClientActionRequested      *Hook[hook_resolver.Resolver]
+	onTorrentClientAction               *Hook[hook_resolver.Resolver]
+	// Online stream events
+	onOnlinestreamEpisodeListRequested   *Hook[hook_resolver.Resolver]
+	onOnlinestreamEpisodeList            *Hook[hook_resolver.Resolver]
+	onOnlinestreamEpisodeSourceRequested *Hook[hook_resolver.Resolver]
+	onOnlinestreamEpisodeSource          *Hook[hook_resolver.Resolver]
+	// Media stream events
+	onMediastreamPlaybackRequested *Hook[hook_resolver.Resolver]
+	onMediastreamMediaContainer    *Hook[hook_resolver.Resolver]
 	// Continuity events
 	onWatchHistoryItemRequested                 *Hook[hook_resolver.Resolver]
 	onWatchHistoryItemUpdated                   *Hook[hook_resolver.Resolver]
@@ -319,6 +357,10 @@ func (m *ManagerImpl) initHooks() {
 	m.onMangaDownloadMap = &Hook[hook_resolver.Resolver]{}
 	m.onMangaChapterContainerRequested = &Hook[hook_resolver.Resolver]{}
 	m.onMangaChapterContainer = &Hook[hook_resolver.Resolver]{}
+	// Manga chapter downloader events
+	m.onMangaChapterDownloadQueued = &Hook[hook_resolver.Resolver]{}
+	m.onMangaChapterDownloadRequested = &Hook[hook_resolver.Resolver]{}
+	m.onMangaChapterDownloaded = &Hook[hook_resolver.Resolver]{}
 	// Playback events
 	m.onLocalFilePlaybackRequested = &Hook[hook_resolver.Resolver]{}
 	m.onPlaybackBeforeTracking = &Hook[hook_resolver.Resolver]{}
@@ -336,6 +378,19 @@ func (m *ManagerImpl) initHooks() {
 	// Torrent stream events
 	m.onTorrentStreamAutoSelectTorrentsFetched = &Hook[hook_resolver.Resolver]{}
 	m.onTorrentStreamSendStreamToMediaPlayer = &Hook[hook_resolver.Resolver]{}
+	// Torrent client events
+	m.onTorrentClientAddTorrentsRequested = &Hook[hook_resolver.Resolver]{}
+	m.onTorrentClientTorrentsAdded = &Hook[hook_resolver.Resolver]{}
+	m.onTorrentClientActionRequested = &Hook[hook_resolver.Resolver]{}
+	m.onTorrentClientAction = &Hook[hook_resolver.Resolver]{}
+	// Online stream events
+	m.onOnlinestreamEpisodeListRequested = &Hook[hook_resolver.Resolver]{}
+	m.onOnlinestreamEpisodeList = &Hook[hook_resolver.Resolver]{}
+	m.onOnlinestreamEpisodeSourceRequested = &Hook[hook_resolver.Resolver]{}
+	m.onOnlinestreamEpisodeSource = &Hook[hook_resolver.Resolver]{}
+	// Media stream events
+	m.onMediastreamPlaybackRequested = &Hook[hook_resolver.Resolver]{}
+	m.onMediastreamMediaContainer = &Hook[hook_resolver.Resolver]{}
 	// Continuity events
 	m.onWatchHistoryItemRequested = &Hook[hook_resolver.Resolver]{}
 	m.onWatchHistoryItemUpdated = &Hook[hook_resolver.Resolver]{}
@@ -799,6 +854,29 @@ func (m *ManagerImpl) OnMangaChapterContainer() *Hook[hook_resolver.Resolver] {
 	return m.onMangaChapterContainer
 }
 
+// Manga chapter downloader events
+
+func (m *ManagerImpl) OnMangaChapterDownloadQueued() *Hook[hook_resolver.Resolver] {
+	if m == nil {
+		return &Hook[hook_resolver.Resolver]{}
+	}
+	return m.onMangaChapterDownloadQueued
+}
+
+func (m *ManagerImpl) OnMangaChapterDownloadRequested() *Hook[hook_resolver.Resolver] {
+	if m == nil {
+		return &Hook[hook_resolver.Resolver]{}
+	}
+	return m.onMangaChapterDownloadRequested
+}
+
+func (m *ManagerImpl) OnMangaChapterDownloaded() *Hook[hook_resolver.Resolver] {
+	if m == nil {
+		return &Hook[hook_resolver.Resolver]{}
+	}
+	return m.onMangaChapterDownloaded
+}
+
 // Playback events
 
 func (m *ManagerImpl) OnLocalFilePlaybackRequested() *Hook[hook_resolver.Resolver] {
@@ -898,6 +976,82 @@ func (m *ManagerImpl) OnTorrentStreamSendStreamToMediaPlayer() *Hook[hook_resolv
 	return m.onTorrentStreamSendStreamToMediaPlayer
 }
 
+// Torrent client events
+
+func (m *ManagerImpl) OnTorrentClientAddTorrentsRequested() *Hook[hook_resolver.Resolver] {
+	if m == nil {
+		return &Hook[hook_resolver.Resolver]{}
+	}
+	return m.onTorrentClientAddTorrentsRequested
+}
+
+func (m *ManagerImpl) OnTorrentClientTorrentsAdded() *Hook[hook_resolver.Resolver] {
+	if m == nil {
+		return &Hook[hook_resolver.Resolver]{}
+	}
+	return m.onTorrentClientTorrentsAdded
+}
+
+func (m *ManagerImpl) OnTorrentClientActionRequested() *Hook[hook_resolver.Resolver] {
+	if m == nil {
+		return &Hook[hook_resolver.Resolver]{}
+	}
+	return m.onTorrentClientActionRequested
+}
+
+func (m *ManagerImpl) OnTorrentClientAction() *Hook[hook_resolver.Resolver] {
+	if m == nil {
+		return &Hook[hook_resolver.Resolver]{}
+	}
+	return m.onTorrentClientAction
+}
+
+// Online stream events
+
+func (m *ManagerImpl) OnOnlinestreamEpisodeListRequested() *Hook[hook_resolver.Resolver] {
+	if m == nil {
+		return &Hook[hook_resolver.Resolver]{}
+	}
+	return m.onOnlinestreamEpisodeListRequested
+}
+
+func (m *ManagerImpl) OnOnlinestreamEpisodeList() *Hook[hook_resolver.Resolver] {
+	if m == nil {
+		return &Hook[hook_resolver.Resolver]{}
+	}
+	return m.onOnlinestreamEpisodeList
+}
+
+func (m *ManagerImpl) OnOnlinestreamEpisodeSourceRequested() *Hook[hook_resolver.Resolver] {
+	if m == nil {
+		return &Hook[hook_resolver.Resolver]{}
+	}
+	return m.onOnlinestreamEpisodeSourceRequested
+}
+
+func (m *ManagerImpl) OnOnlinestreamEpisodeSource() *Hook[hook_resolver.Resolver] {
+	if m == nil {
+		return &Hook[hook_resolver.Resolver]{}
+	}
+	return m.onOnlinestreamEpisodeSource
+}
+
+// Media stream events
+
+func (m *ManagerImpl) OnMediastreamPlaybackRequested() *Hook[hook_resolver.Resolver] {
+	if m == nil {
+		return &Hook[hook_resolver.Resolver]{}
+	}
+	return m.onMediastreamPlaybackRequested
+}
+
+func (m *ManagerImpl) OnMediastreamMediaContainer() *Hook[hook_resolver.Resolver] {
+	if m == nil {
+		return &Hook[hook_resolver.Resolver]{}
+	}
+	return m.onMediastreamMediaContainer
+}
+
 // Continuity events
 
 func (m *ManagerImpl) OnWatchHistoryItemRequested() *Hook[hook_resolver.Resolver] {
@@ -85,10 +85,11 @@ type (
 type (
 	// NewLibraryCollectionOptions is a struct that holds the data needed for creating a new LibraryCollection.
 	NewLibraryCollectionOptions struct {
-		AnimeCollection  *anilist.AnimeCollection
-		LocalFiles       []*LocalFile
-		Platform         platform.Platform
-		MetadataProvider metadata.Provider
+		AnimeCollection    *anilist.AnimeCollection
+		LocalFiles         []*LocalFile
+		Platform           platform.Platform
+		MetadataProvider   metadata.Provider
+		VersionPreferences *VersionPreferences // optional
 	}
 )
 
@@ -137,6 +138,7 @@ func NewLibraryCollection(opts *NewLibraryCollectionOptions) (lc *LibraryCollect
 		opts.AnimeCollection,
 		opts.Platform,
 		opts.MetadataProvider,
+		opts.VersionPreferences,
 	)
 
 	lc.UnmatchedLocalFiles = lo.Filter(opts.LocalFiles, func(lf *LocalFile, index int) bool {
@@ -345,6 +347,7 @@ func (lc *LibraryCollection) hydrateContinueWatchingList(
 	animeCollection *anilist.AnimeCollection,
 	platform platform.Platform,
 	metadataProvider metadata.Provider,
+	versionPreferences *VersionPreferences,
 ) {
 
 	// Get currently watching list
@@ -368,11 +371,12 @@ func (lc *LibraryCollection) hydrateContinueWatchingList(
 	for _, mId := range mIds {
 		mEntryPool.Go(func() *Entry {
 			me, _ := NewEntry(&NewEntryOptions{
-				MediaId:          mId,
-				LocalFiles:       localFiles,
-				AnimeCollection:  animeCollection,
-				Platform:         platform,
-				MetadataProvider: metadataProvider,
+				MediaId:            mId,
+				LocalFiles:         localFiles,
+				AnimeCollection:    animeCollection,
+				Platform:           platform,
+				MetadataProvider:   metadataProvider,
+				VersionPreferences: versionPreferences,
 			})
 			return me
 		})
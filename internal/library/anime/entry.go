@@ -43,11 +43,12 @@ type (
 type (
 	// NewEntryOptions is a constructor for Entry.
 	NewEntryOptions struct {
-		MediaId          int
-		LocalFiles       []*LocalFile // All local files
-		AnimeCollection  *anilist.AnimeCollection
-		Platform         platform.Platform
-		MetadataProvider metadata.Provider
+		MediaId            int
+		LocalFiles         []*LocalFile // All local files
+		AnimeCollection    *anilist.AnimeCollection
+		Platform           platform.Platform
+		MetadataProvider   metadata.Provider
+		VersionPreferences *VersionPreferences // optional - used to pick between multiple versions of an episode
 	}
 )
 
@@ -165,10 +166,11 @@ func NewEntry(opts *NewEntryOptions) (*Entry, error) {
 
 		// If AniZip data is not found, we will still create the Entry without it
 		simpleAnimeEntry, err := NewSimpleEntry(&NewSimpleAnimeEntryOptions{
-			MediaId:         opts.MediaId,
-			LocalFiles:      opts.LocalFiles,
-			AnimeCollection: opts.AnimeCollection,
-			Platform:        opts.Platform,
+			MediaId:            opts.MediaId,
+			LocalFiles:         opts.LocalFiles,
+			AnimeCollection:    opts.AnimeCollection,
+			Platform:           opts.Platform,
+			VersionPreferences: opts.VersionPreferences,
 		})
 		if err != nil {
 			return nil, err
@@ -216,7 +218,7 @@ func NewEntry(opts *NewEntryOptions) (*Entry, error) {
 	// +---------------------+
 
 	// Create episode entities
-	entry.hydrateEntryEpisodeData(anilistEntry, animeMetadata, opts.MetadataProvider)
+	entry.hydrateEntryEpisodeData(anilistEntry, animeMetadata, opts.MetadataProvider, opts.VersionPreferences)
 
 	event := new(AnimeEntryEvent)
 	event.Entry = entry
@@ -236,6 +238,7 @@ func (e *Entry) hydrateEntryEpisodeData(
 	anilistEntry *anilist.AnimeListEntry,
 	animeMetadata *metadata.AnimeMetadata,
 	metadataProvider metadata.Provider,
+	versionPreferences *VersionPreferences,
 ) {
 
 	if animeMetadata.Episodes == nil && len(animeMetadata.Episodes) == 0 {
@@ -278,11 +281,12 @@ func (e *Entry) hydrateEntryEpisodeData(
 	// |       Episodes      |
 	// +---------------------+
 
+	// Only the preferred version of each episode is listed
 	p := pool.NewWithResults[*Episode]()
-	for _, lf := range e.LocalFiles {
+	for _, g := range GroupEpisodeVersions(e.LocalFiles, versionPreferences) {
 		p.Go(func() *Episode {
-			return NewEpisode(&NewEpisodeOptions{
-				LocalFile:            lf,
+			ep := NewEpisode(&NewEpisodeOptions{
+				LocalFile:            g.Versions[0].LocalFile,
 				OptionalAniDBEpisode: "",
 				AnimeMetadata:        animeMetadata,
 				Media:                e.Media,
@@ -290,6 +294,10 @@ func (e *Entry) hydrateEntryEpisodeData(
 				IsDownloaded:         true,
 				MetadataProvider:     metadataProvider,
 			})
+			if len(g.Versions) > 1 {
+				ep.Versions = g.Versions
+			}
+			return ep
 		})
 	}
 	episodes := p.Wait()
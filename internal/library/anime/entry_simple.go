@@ -29,10 +29,11 @@ type (
 	}
 
 	NewSimpleAnimeEntryOptions struct {
-		MediaId         int
-		LocalFiles      []*LocalFile // All local files
-		AnimeCollection *anilist.AnimeCollection
-		Platform        platform.Platform
+		MediaId            int
+		LocalFiles         []*LocalFile // All local files
+		AnimeCollection    *anilist.AnimeCollection
+		Platform           platform.Platform
+		VersionPreferences *VersionPreferences // optional
 	}
 )
 
@@ -104,7 +105,7 @@ func NewSimpleEntry(opts *NewSimpleAnimeEntryOptions) (*SimpleEntry, error) {
 	// +---------------------+
 
 	// Create episode entities
-	entry.hydrateEntryEpisodeData()
+	entry.hydrateEntryEpisodeData(opts.VersionPreferences)
 
 	return entry, nil
 
@@ -114,21 +115,24 @@ func NewSimpleEntry(opts *NewSimpleAnimeEntryOptions) (*SimpleEntry, error) {
 
 // hydrateEntryEpisodeData
 // AniZipData, Media and LocalFiles should be defined
-func (e *SimpleEntry) hydrateEntryEpisodeData() {
+func (e *SimpleEntry) hydrateEntryEpisodeData(versionPreferences *VersionPreferences) {
 
 	// +---------------------+
 	// |       Episodes      |
 	// +---------------------+
 
 	p := pool.NewWithResults[*Episode]()
-	for _, lf := range e.LocalFiles {
-		lf := lf
+	for _, g := range GroupEpisodeVersions(e.LocalFiles, versionPreferences) {
 		p.Go(func() *Episode {
-			return NewSimpleEpisode(&NewSimpleEpisodeOptions{
-				LocalFile:    lf,
+			ep := NewSimpleEpisode(&NewSimpleEpisodeOptions{
+				LocalFile:    g.Versions[0].LocalFile,
 				Media:        e.Media,
 				IsDownloaded: true,
 			})
+			if len(g.Versions) > 1 {
+				ep.Versions = g.Versions
+			}
+			return ep
 		})
 	}
 	episodes := p.Wait()
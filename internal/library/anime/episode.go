@@ -24,6 +24,7 @@ type (
 		IsInvalid             bool               `json:"isInvalid"`               // No AniDB data
 		MetadataIssue         string             `json:"metadataIssue,omitempty"` // Alerts the user that there is a discrepancy between AniList and AniDB
 		BaseAnime             *anilist.BaseAnime `json:"baseAnime,omitempty"`
+		Versions              []*EpisodeVersion  `json:"versions,omitempty"` // All versions of the episode if there are multiple local files, the preferred version first
 	}
 
 	// EpisodeMetadata represents the metadata of an Episode.
@@ -0,0 +1,242 @@
+package anime
+
+import (
+	"cmp"
+	"os"
+	"seanime/internal/database/models"
+	"slices"
+	"strings"
+
+	"github.com/5rahim/habari"
+)
+
+type (
+	// VersionPreferences are used to rank the versions of an episode.
+	// Each list is in order of priority, values are case-insensitive.
+	VersionPreferences struct {
+		Resolutions       []string `json:"resolutions"`   // e.g. ["1080p", "720p"]
+		ReleaseGroups     []string `json:"releaseGroups"` // e.g. ["SubsPlease", "Erai-raws"]
+		Codecs            []string `json:"codecs"`        // e.g. ["HEVC", "x265"]
+		PreferLargerFiles bool     `json:"preferLargerFiles"`
+	}
+
+	// EpisodeVersion is one of the local files of an episode.
+	EpisodeVersion struct {
+		LocalFile    *LocalFile `json:"localFile"`
+		Resolution   string     `json:"resolution,omitempty"`
+		ReleaseGroup string     `json:"releaseGroup,omitempty"`
+		Codecs       []string   `json:"codecs,omitempty"`
+		Size         int64      `json:"size,omitempty"`
+		IsPreferred  bool       `json:"isPreferred"`
+	}
+
+	// EpisodeVersionGroup holds the versions of an episode, the preferred version first.
+	EpisodeVersionGroup struct {
+		MediaId  int               `json:"mediaId"`
+		Type     LocalFileType     `json:"type"`
+		Episode  int               `json:"episode"`
+		Versions []*EpisodeVersion `json:"versions"`
+	}
+)
+
+// NewVersionPreferences returns the version preferences from the library settings.
+func NewVersionPreferences(settings *models.LibrarySettings) *VersionPreferences {
+	if settings == nil {
+		return nil
+	}
+	return &VersionPreferences{
+		Resolutions:       settings.PreferredResolutions,
+		ReleaseGroups:     settings.PreferredReleaseGroups,
+		Codecs:            settings.PreferredCodecs,
+		PreferLargerFiles: settings.PreferLargerFiles,
+	}
+}
+
+// GroupEpisodeVersions groups the local files that are versions of the same episode.
+// Only main episodes and specials are grouped, other local files are in their own group.
+// Groups are returned in the order of their first local file, versions are ranked using the preferences.
+func GroupEpisodeVersions(lfs []*LocalFile, prefs *VersionPreferences) []*EpisodeVersionGroup {
+	type key struct {
+		mediaId int
+		typ     LocalFileType
+		episode int
+	}
+
+	groups := make(map[key]*EpisodeVersionGroup)
+	ret := make([]*EpisodeVersionGroup, 0)
+
+	for _, lf := range lfs {
+		if lf == nil || lf.Metadata == nil {
+			continue
+		}
+
+		version := NewEpisodeVersion(lf)
+
+		if lf.MediaId == 0 || (lf.Metadata.Type != LocalFileTypeMain && lf.Metadata.Type != LocalFileTypeSpecial) {
+			ret = append(ret, &EpisodeVersionGroup{
+				MediaId:  lf.MediaId,
+				Type:     lf.Metadata.Type,
+				Episode:  lf.Metadata.Episode,
+				Versions: []*EpisodeVersion{version},
+			})
+			continue
+		}
+
+		k := key{mediaId: lf.MediaId, typ: lf.Metadata.Type, episode: lf.Metadata.Episode}
+		if g, ok := groups[k]; ok {
+			g.Versions = append(g.Versions, version)
+			continue
+		}
+		g := &EpisodeVersionGroup{
+			MediaId:  lf.MediaId,
+			Type:     lf.Metadata.Type,
+			Episode:  lf.Metadata.Episode,
+			Versions: []*EpisodeVersion{version},
+		}
+		groups[k] = g
+		ret = append(ret, g)
+	}
+
+	for _, g := range ret {
+		RankEpisodeVersions(g.Versions, prefs)
+	}
+
+	return ret
+}
+
+// GetDuplicateEpisodeVersions returns the groups that have more than one version.
+func GetDuplicateEpisodeVersions(lfs []*LocalFile, prefs *VersionPreferences) []*EpisodeVersionGroup {
+	ret := make([]*EpisodeVersionGroup, 0)
+	for _, g := range GroupEpisodeVersions(lfs, prefs) {
+		if len(g.Versions) > 1 {
+			ret = append(ret, g)
+		}
+	}
+	return ret
+}
+
+// SelectPreferredVersion returns the preferred local file.
+func SelectPreferredVersion(lfs []*LocalFile, prefs *VersionPreferences) (*LocalFile, bool) {
+	if len(lfs) == 0 {
+		return nil, false
+	}
+	if len(lfs) == 1 {
+		return lfs[0], true
+	}
+	versions := make([]*EpisodeVersion, 0, len(lfs))
+	for _, lf := range lfs {
+		versions = append(versions, NewEpisodeVersion(lf))
+	}
+	RankEpisodeVersions(versions, prefs)
+	return versions[0].LocalFile, true
+}
+
+// NewEpisodeVersion parses the version information from the filename.
+func NewEpisodeVersion(lf *LocalFile) *EpisodeVersion {
+	elements := habari.Parse(lf.Name)
+	ret := &EpisodeVersion{
+		LocalFile:    lf,
+		Resolution:   normalizeResolution(elements.VideoResolution),
+		ReleaseGroup: elements.ReleaseGroup,
+		Codecs:       elements.VideoTerm,
+	}
+	if ret.ReleaseGroup == "" && lf.ParsedData != nil {
+		ret.ReleaseGroup = lf.ParsedData.ReleaseGroup
+	}
+	return ret
+}
+
+// RankEpisodeVersions sorts the versions from most to least preferred and marks the first one as preferred.
+// Versions are compared by resolution, release group, codec and file size.
+// Without preferences, higher resolutions and larger files are preferred.
+func RankEpisodeVersions(versions []*EpisodeVersion, prefs *VersionPreferences) {
+	if len(versions) == 0 {
+		return
+	}
+	if prefs == nil {
+		prefs = &VersionPreferences{}
+	}
+
+	// File sizes are only needed to break ties
+	if len(versions) > 1 {
+		for _, v := range versions {
+			if v.Size == 0 {
+				if info, err := os.Stat(v.LocalFile.GetPath()); err == nil {
+					v.Size = info.Size()
+				}
+			}
+		}
+	}
+
+	slices.SortStableFunc(versions, func(a, b *EpisodeVersion) int {
+		// Resolution
+		if len(prefs.Resolutions) > 0 {
+			if c := cmp.Compare(preferenceIndex(prefs.Resolutions, a.Resolution), preferenceIndex(prefs.Resolutions, b.Resolution)); c != 0 {
+				return c
+			}
+		} else if c := cmp.Compare(resolutionHeight(b.Resolution), resolutionHeight(a.Resolution)); c != 0 {
+			return c
+		}
+		// Release group
+		if c := cmp.Compare(preferenceIndex(prefs.ReleaseGroups, a.ReleaseGroup), preferenceIndex(prefs.ReleaseGroups, b.ReleaseGroup)); c != 0 {
+			return c
+		}
+		// Codec
+		if c := cmp.Compare(preferenceIndexAny(prefs.Codecs, a.Codecs), preferenceIndexAny(prefs.Codecs, b.Codecs)); c != 0 {
+			return c
+		}
+		// File size
+		if c := cmp.Compare(b.Size, a.Size); c != 0 && (prefs.PreferLargerFiles || len(prefs.Resolutions) == 0) {
+			return c
+		}
+		return cmp.Compare(a.LocalFile.GetNormalizedPath(), b.LocalFile.GetNormalizedPath())
+	})
+
+	for i, v := range versions {
+		v.IsPreferred = i == 0
+	}
+}
+
+// preferenceIndex returns the index of the value in the preferences, or the length of the preferences if it is not found.
+func preferenceIndex(prefs []string, value string) int {
+	for i, p := range prefs {
+		if value != "" && strings.EqualFold(p, value) {
+			return i
+		}
+	}
+	return len(prefs)
+}
+
+// preferenceIndexAny returns the lowest preference index of the values.
+func preferenceIndexAny(prefs []string, values []string) int {
+	ret := len(prefs)
+	for _, v := range values {
+		ret = min(ret, preferenceIndex(prefs, v))
+	}
+	return ret
+}
+
+// normalizeResolution converts resolutions to the "1080p" format.
+//   - "1920x1080" -> "1080p"
+//   - "1080P" -> "1080p"
+func normalizeResolution(resolution string) string {
+	resolution = strings.ToLower(strings.TrimSpace(resolution))
+	if _, h, found := strings.Cut(resolution, "x"); found {
+		return h + "p"
+	}
+	if resolution == "4k" {
+		return "2160p"
+	}
+	return resolution
+}
+
+func resolutionHeight(resolution string) int {
+	h := 0
+	for _, r := range strings.TrimSuffix(resolution, "p") {
+		if r < '0' || r > '9' {
+			return 0
+		}
+		h = h*10 + int(r-'0')
+	}
+	return h
+}
@@ -0,0 +1,71 @@
+package anime
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupEpisodeVersions(t *testing.T) {
+	dir := filepath.FromSlash("E:/Anime")
+
+	newLf := func(name string, episode int, fileType LocalFileType) *LocalFile {
+		lf := NewLocalFile(filepath.Join(dir, name), dir)
+		lf.MediaId = 154587
+		lf.Metadata = &LocalFileMetadata{Episode: episode, Type: fileType}
+		return lf
+	}
+
+	lfs := []*LocalFile{
+		newLf("[SubsPlease] Sousou no Frieren - 01 (720p).mkv", 1, LocalFileTypeMain),
+		newLf("[SubsPlease] Sousou no Frieren - 01 (1080p).mkv", 1, LocalFileTypeMain),
+		newLf("[Erai-raws] Sousou no Frieren - 01 [1080p][HEVC].mkv", 1, LocalFileTypeMain),
+		newLf("[SubsPlease] Sousou no Frieren - 02 (1080p).mkv", 2, LocalFileTypeMain),
+		newLf("[Erai-raws] Sousou no Frieren - 02 [1080p][HEVC].mkv", 2, LocalFileTypeMain),
+		newLf("[SubsPlease] Sousou no Frieren - 03 (1080p).mkv", 3, LocalFileTypeMain),
+		newLf("[SubsPlease] Sousou no Frieren - NCOP (1080p).mkv", 0, LocalFileTypeNC),
+		newLf("[SubsPlease] Sousou no Frieren - NCED (1080p).mkv", 0, LocalFileTypeNC),
+	}
+
+	prefs := &VersionPreferences{
+		Resolutions:   []string{"1080p"},
+		ReleaseGroups: []string{"SubsPlease"},
+	}
+
+	groups := GroupEpisodeVersions(lfs, prefs)
+	require.Len(t, groups, 5)
+
+	require.Len(t, groups[0].Versions, 3)
+	require.Equal(t, "[SubsPlease] Sousou no Frieren - 01 (1080p).mkv", groups[0].Versions[0].LocalFile.Name)
+	require.True(t, groups[0].Versions[0].IsPreferred)
+	require.Equal(t, "1080p", groups[0].Versions[0].Resolution)
+	require.Equal(t, "[Erai-raws] Sousou no Frieren - 01 [1080p][HEVC].mkv", groups[0].Versions[1].LocalFile.Name)
+	require.False(t, groups[0].Versions[1].IsPreferred)
+	require.Equal(t, "[SubsPlease] Sousou no Frieren - 01 (720p).mkv", groups[0].Versions[2].LocalFile.Name)
+
+	// Codec preference before the release group
+	prefs = &VersionPreferences{
+		Codecs: []string{"HEVC"},
+	}
+	_, found := SelectPreferredVersion(nil, prefs)
+	require.False(t, found)
+
+	duplicates := GetDuplicateEpisodeVersions(lfs, prefs)
+	require.Len(t, duplicates, 2)
+	require.Equal(t, "[Erai-raws] Sousou no Frieren - 02 [1080p][HEVC].mkv", duplicates[1].Versions[0].LocalFile.Name)
+
+	// The next episode is from the same release group
+	lfw := NewLocalFileWrapper(lfs)
+	lfw.SetVersionPreferences(prefs)
+	lfe, ok := lfw.GetLocalEntryById(154587)
+	require.True(t, ok)
+
+	next, found := lfe.FindNextEpisode(lfs[1])
+	require.True(t, found)
+	require.Equal(t, "[SubsPlease] Sousou no Frieren - 02 (1080p).mkv", next.Name)
+
+	next, found = lfe.FindLocalFileWithEpisodeNumber(2)
+	require.True(t, found)
+	require.Equal(t, "[Erai-raws] Sousou no Frieren - 02 [1080p][HEVC].mkv", next.Name)
+}
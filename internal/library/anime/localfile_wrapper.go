@@ -14,8 +14,9 @@ type (
 	}
 
 	LocalFileWrapperEntry struct {
-		MediaId    int          `json:"mediaId"`
-		LocalFiles []*LocalFile `json:"localFiles"`
+		MediaId            int          `json:"mediaId"`
+		LocalFiles         []*LocalFile `json:"localFiles"`
+		versionPreferences *VersionPreferences
 	}
 )
 
@@ -43,6 +44,13 @@ func NewLocalFileWrapper(lfs []*LocalFile) *LocalFileWrapper {
 	return lfw
 }
 
+// SetVersionPreferences sets the preferences used to pick between multiple versions of the same episode.
+func (lfw *LocalFileWrapper) SetVersionPreferences(prefs *VersionPreferences) {
+	for _, e := range lfw.LocalEntries {
+		e.versionPreferences = prefs
+	}
+}
+
 func (lfw *LocalFileWrapper) GetLocalEntryById(mId int) (*LocalFileWrapperEntry, bool) {
 	for _, me := range lfw.LocalEntries {
 		if me.MediaId == mId {
@@ -103,7 +111,7 @@ func (e *LocalFileWrapperEntry) GetFirstUnwatchedLocalFiles(progress int) (*Loca
 	slices.SortStableFunc(lfs, func(a, b *LocalFile) int {
 		return cmp.Compare(a.GetEpisodeNumber(), b.GetEpisodeNumber())
 	})
-	return lfs[0], true
+	return e.FindLocalFileWithEpisodeNumber(lfs[0].GetEpisodeNumber())
 }
 
 // HasMainLocalFiles returns true if there are any *main* local files.
@@ -117,16 +125,23 @@ func (e *LocalFileWrapperEntry) HasMainLocalFiles() bool {
 }
 
 // FindLocalFileWithEpisodeNumber returns the *main* local file with the given episode number.
+// If there are multiple versions of the episode, the preferred version is returned.
 func (e *LocalFileWrapperEntry) FindLocalFileWithEpisodeNumber(ep int) (*LocalFile, bool) {
+	return SelectPreferredVersion(e.FindLocalFileVersions(ep), e.versionPreferences)
+}
+
+// FindLocalFileVersions returns all the *main* local files with the given episode number.
+func (e *LocalFileWrapperEntry) FindLocalFileVersions(ep int) []*LocalFile {
+	ret := make([]*LocalFile, 0)
 	for _, lf := range e.LocalFiles {
 		if !lf.IsMain() {
 			continue
 		}
 		if lf.GetEpisodeNumber() == ep {
-			return lf, true
+			ret = append(ret, lf)
 		}
 	}
-	return nil, false
+	return ret
 }
 
 // FindLatestLocalFile returns the *main* local file with the highest episode number.
@@ -142,27 +157,32 @@ func (e *LocalFileWrapperEntry) FindLatestLocalFile() (*LocalFile, bool) {
 			latest = lf
 		}
 	}
-	return latest, true
+	return e.FindLocalFileWithEpisodeNumber(latest.GetEpisodeNumber())
 }
 
 // FindNextEpisode returns the *main* local file whose episode number is after the given local file.
+// If there are multiple versions of the next episode, the version from the same release group is returned,
+// otherwise the preferred version is returned.
 func (e *LocalFileWrapperEntry) FindNextEpisode(lf *LocalFile) (*LocalFile, bool) {
-	lfs, ok := e.GetMainLocalFiles()
-	if !ok {
+	// Get the local files whose episode number is after the given local file
+	versions := e.FindLocalFileVersions(lf.GetEpisodeNumber() + 1)
+	if len(versions) == 0 {
 		return nil, false
 	}
-	// Get the local file whose episode number is after the given local file
-	var next *LocalFile
-	for _, l := range lfs {
-		if l.GetEpisodeNumber() == lf.GetEpisodeNumber()+1 {
-			next = l
-			break
+
+	if len(versions) > 1 && lf.ParsedData != nil && lf.ParsedData.ReleaseGroup != "" {
+		sameGroup := make([]*LocalFile, 0)
+		for _, v := range versions {
+			if v.ParsedData != nil && v.ParsedData.ReleaseGroup == lf.ParsedData.ReleaseGroup {
+				sameGroup = append(sameGroup, v)
+			}
+		}
+		if len(sameGroup) > 0 {
+			versions = sameGroup
 		}
 	}
-	if next == nil {
-		return nil, false
-	}
-	return next, true
+
+	return SelectPreferredVersion(versions, e.versionPreferences)
 }
 
 // GetProgressNumber returns the progress number of a **main** local file.
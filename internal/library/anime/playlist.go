@@ -2,15 +2,55 @@ package anime
 
 import (
 	"seanime/internal/util"
+	"strconv"
 )
 
+const (
+	PlaylistItemSourceLocalFile     PlaylistItemSource = "localfile"
+	PlaylistItemSourceTorrentstream PlaylistItemSource = "torrentstream"
+	PlaylistItemSourceDebrid        PlaylistItemSource = "debrid"
+	PlaylistItemSourceOnlinestream  PlaylistItemSource = "onlinestream"
+)
+
+// DefaultPlaylistItemSources is the order in which sources are tried when a PlaylistItem does not specify any.
+var DefaultPlaylistItemSources = []PlaylistItemSource{
+	PlaylistItemSourceLocalFile,
+	PlaylistItemSourceTorrentstream,
+	PlaylistItemSourceDebrid,
+	PlaylistItemSourceOnlinestream,
+}
+
 type (
 	// Playlist holds the data from models.PlaylistEntry
 	Playlist struct {
-		DbId       uint         `json:"dbId"`       // DbId is the database ID of the models.PlaylistEntry
-		Name       string       `json:"name"`       // Name is the name of the playlist
-		LocalFiles []*LocalFile `json:"localFiles"` // LocalFiles is a list of local files in the playlist, in order
+		DbId       uint            `json:"dbId"`       // DbId is the database ID of the models.PlaylistEntry
+		Name       string          `json:"name"`       // Name is the name of the playlist
+		LocalFiles []*LocalFile    `json:"localFiles"` // LocalFiles is a list of local files in the playlist, in order
+		Items      []*PlaylistItem `json:"items"`      // Items is a list of episodes in the playlist, in order. If empty, LocalFiles is used
+	}
+
+	// PlaylistItem is an episode in a playlist.
+	// It is played from the first source that is available, e.g. the local file if it is in the library, otherwise a stream.
+	PlaylistItem struct {
+		MediaId       int    `json:"mediaId"`
+		EpisodeNumber int    `json:"episodeNumber"`
+		AniDBEpisode  string `json:"aniDBEpisode"`
+		// Sources tried in order, DefaultPlaylistItemSources if empty
+		Sources []PlaylistItemSource `json:"sources,omitempty"`
+		// Online streaming provider used by PlaylistItemSourceOnlinestream
+		OnlinestreamProvider string `json:"onlinestreamProvider,omitempty"`
+		// LocalFile is set by [Playlist.ResolveLocalFiles] if the episode is in the library
+		LocalFile *LocalFile `json:"localFile,omitempty"`
+
+		// \/ Progress tracking
+
+		// Source used to play the item
+		Source               PlaylistItemSource `json:"source,omitempty"`
+		CompletionPercentage float64            `json:"completionPercentage"`
+		Completed            bool               `json:"completed"`
 	}
+
+	PlaylistItemSource string
 )
 
 // NewPlaylist creates a new Playlist instance
@@ -40,6 +80,68 @@ func (pd *Playlist) RemoveLocalFile(path string) {
 	}
 }
 
+// GetItems returns the items of the playlist.
+// Playlists created with local files only are converted to items.
+func (pd *Playlist) GetItems() []*PlaylistItem {
+	if len(pd.Items) > 0 {
+		return pd.Items
+	}
+
+	pd.Items = make([]*PlaylistItem, 0, len(pd.LocalFiles))
+	for _, lf := range pd.LocalFiles {
+		pd.Items = append(pd.Items, NewPlaylistItemFromLocalFile(lf))
+	}
+	return pd.Items
+}
+
+// ResolveLocalFiles sets the local file of the items whose episode is in the library.
+// Items whose local file is no longer in the library are unset.
+func (pd *Playlist) ResolveLocalFiles(lfs []*LocalFile) {
+	for _, item := range pd.GetItems() {
+		var found *LocalFile
+		for _, lf := range lfs {
+			if item.LocalFile != nil && lf.GetNormalizedPath() == item.LocalFile.GetNormalizedPath() {
+				found = lf
+				break
+			}
+			if found == nil && lf.MediaId == item.MediaId && lf.IsMain() && lf.GetEpisodeNumber() == item.EpisodeNumber {
+				found = lf
+				if item.LocalFile == nil {
+					break
+				}
+			}
+		}
+		item.LocalFile = found
+	}
+}
+
+// NewPlaylistItemFromLocalFile creates an item that is only played from the local file.
+func NewPlaylistItemFromLocalFile(lf *LocalFile) *PlaylistItem {
+	return &PlaylistItem{
+		MediaId:       lf.MediaId,
+		EpisodeNumber: lf.GetEpisodeNumber(),
+		AniDBEpisode:  lf.GetAniDBEpisode(),
+		Sources:       []PlaylistItemSource{PlaylistItemSourceLocalFile},
+		LocalFile:     lf,
+	}
+}
+
+// GetSources returns the sources to try, in order.
+func (i *PlaylistItem) GetSources() []PlaylistItemSource {
+	if len(i.Sources) == 0 {
+		return DefaultPlaylistItemSources
+	}
+	return i.Sources
+}
+
+// GetAniDBEpisode returns the AniDB episode, defaults to the episode number.
+func (i *PlaylistItem) GetAniDBEpisode() string {
+	if i.AniDBEpisode != "" {
+		return i.AniDBEpisode
+	}
+	return strconv.Itoa(i.EpisodeNumber)
+}
+
 func (pd *Playlist) LocalFileExists(path string, lfs []*LocalFile) bool {
 	for _, lf := range lfs {
 		if lf.GetNormalizedPath() == util.NormalizePath(path) {
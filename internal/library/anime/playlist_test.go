@@ -0,0 +1,51 @@
+package anime_test
+
+import (
+	"seanime/internal/library/anime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaylist_ResolveLocalFiles(t *testing.T) {
+	newLocalFile := func(path string, mediaId int, episode int, lfType anime.LocalFileType) *anime.LocalFile {
+		lf := anime.NewLocalFile(path, "/Anime")
+		lf.MediaId = mediaId
+		lf.Metadata.Episode = episode
+		lf.Metadata.Type = lfType
+		return lf
+	}
+
+	ep1 := newLocalFile("/Anime/Show/Show - 01.mkv", 1, 1, anime.LocalFileTypeMain)
+	special := newLocalFile("/Anime/Show/Show - S01.mkv", 1, 1, anime.LocalFileTypeSpecial)
+	lfs := []*anime.LocalFile{special, ep1}
+
+	// Playlist created with local files only
+	playlist := anime.NewPlaylist("local")
+	playlist.SetLocalFiles([]*anime.LocalFile{special, ep1})
+	playlist.ResolveLocalFiles(lfs)
+
+	items := playlist.GetItems()
+	require.Len(t, items, 2)
+	require.Equal(t, special.GetNormalizedPath(), items[0].LocalFile.GetNormalizedPath())
+	require.Equal(t, ep1.GetNormalizedPath(), items[1].LocalFile.GetNormalizedPath())
+	require.Equal(t, []anime.PlaylistItemSource{anime.PlaylistItemSourceLocalFile}, items[0].GetSources())
+
+	// Mixed playlist, episode 2 is not in the library
+	playlist = anime.NewPlaylist("mixed")
+	playlist.Items = []*anime.PlaylistItem{
+		{MediaId: 1, EpisodeNumber: 1},
+		{MediaId: 1, EpisodeNumber: 2},
+	}
+	playlist.ResolveLocalFiles(lfs)
+
+	require.NotNil(t, playlist.Items[0].LocalFile)
+	require.Equal(t, ep1.GetNormalizedPath(), playlist.Items[0].LocalFile.GetNormalizedPath())
+	require.Nil(t, playlist.Items[1].LocalFile)
+	require.Equal(t, anime.DefaultPlaylistItemSources, playlist.Items[1].GetSources())
+	require.Equal(t, "2", playlist.Items[1].GetAniDBEpisode())
+
+	// The local file was removed from the library
+	playlist.ResolveLocalFiles([]*anime.LocalFile{special})
+	require.Nil(t, playlist.Items[0].LocalFile)
+}
@@ -112,11 +112,16 @@ func (ad *AutoDownloader) SetSettings(settings *models.AutoDownloaderSettings, p
 	go func() {
 		ad.mu.Lock()
 		defer ad.mu.Unlock()
+		wasQbittorrentRssEnabled := ad.isQbittorrentRssEnabled()
 		ad.settings = settings
 		// Update the provider if it's provided
 		if provider != "" {
 			ad.settings.Provider = provider
 		}
+		// Remove the qBittorrent RSS rules if they are no longer used
+		if wasQbittorrentRssEnabled && !ad.isQbittorrentRssEnabled() {
+			go ad.removeQbittorrentRss()
+		}
 		ad.settingsUpdatedCh <- struct{}{} // Notify that the settings have been updated
 		if ad.settings.Enabled {
 			ad.startCh <- struct{}{} // Start the auto downloader
@@ -215,11 +220,11 @@ func (ad *AutoDownloader) start() {
 		case <-ad.startCh:
 			if ad.settings.Enabled {
 				ad.logger.Debug().Msg("autodownloader: Auto Downloader started")
-				ad.checkForNewEpisodes()
+				ad.run()
 			}
 		case <-ticker.C:
 			if ad.settings.Enabled {
-				ad.checkForNewEpisodes()
+				ad.run()
 			}
 		}
 		ticker.Stop()
@@ -227,6 +232,15 @@ func (ad *AutoDownloader) start() {
 
 }
 
+// run checks for new episodes.
+// If the qBittorrent RSS mode is enabled, the rules are synced with qBittorrent instead.
+func (ad *AutoDownloader) run() {
+	if ad.isQbittorrentRssEnabled() && ad.runQbittorrentRss() {
+		return
+	}
+	ad.checkForNewEpisodes()
+}
+
 func (ad *AutoDownloader) checkForNewEpisodes() {
 	defer util.HandlePanicInModuleThen("autodownloader/checkForNewEpisodes", func() {})
 
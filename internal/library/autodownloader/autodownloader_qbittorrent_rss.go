@@ -0,0 +1,449 @@
+package autodownloader
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"seanime/internal/database/db_bridge"
+	"seanime/internal/database/models"
+	hibiketorrent "seanime/internal/extension/hibike/torrent"
+	"seanime/internal/library/anime"
+	"seanime/internal/notifier"
+	"seanime/internal/torrent_clients/qbittorrent"
+	qbittorrent_model "seanime/internal/torrent_clients/qbittorrent/model"
+	"seanime/internal/torrents/torrent"
+	"seanime/internal/util"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/5rahim/habari"
+	"github.com/goccy/go-json"
+)
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// qBittorrent RSS
+// - The rules are translated into qBittorrent RSS feeds and download rules so that downloads continue while Seanime is offline
+// - Feeds are created in the "Seanime" folder, rules are prefixed with "Seanime - "
+// - Torrents added by the rules are assigned the "seanime" category and imported back into the auto downloader history
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+const (
+	qbittorrentRssFolder     = "Seanime"
+	qbittorrentRssRulePrefix = "Seanime - "
+	qbittorrentRssCategory   = "seanime"
+)
+
+// SyncRules updates the qBittorrent RSS feeds and rules after the rules have been modified.
+// It does nothing if the qBittorrent RSS mode is not enabled.
+func (ad *AutoDownloader) SyncRules() {
+	if ad == nil {
+		return
+	}
+	go func() {
+		defer util.HandlePanicInModuleThen("autodownloader/SyncRules", func() {})
+
+		if !ad.isQbittorrentRssEnabled() {
+			return
+		}
+		if err := ad.syncQbittorrentRss(); err != nil {
+			ad.logger.Error().Err(err).Msg("autodownloader: Failed to sync qBittorrent RSS rules")
+		}
+	}()
+}
+
+// isQbittorrentRssEnabled returns true if the rules should be delegated to qBittorrent's RSS downloader.
+func (ad *AutoDownloader) isQbittorrentRssEnabled() bool {
+	if ad.settings == nil || !ad.settings.Enabled || !ad.settings.UseQbittorrentRss || ad.settings.UseDebrid {
+		return false
+	}
+	if ad.torrentClientRepository == nil {
+		return false
+	}
+	_, ok := ad.torrentClientRepository.GetQbittorrentClient()
+	return ok
+}
+
+// runQbittorrentRss syncs the rules and imports the torrents added by qBittorrent.
+// It returns false if the provider doesn't support RSS feeds, in which case the regular check should be used.
+func (ad *AutoDownloader) runQbittorrentRss() bool {
+	defer util.HandlePanicInModuleThen("autodownloader/runQbittorrentRss", func() {})
+
+	if _, ok := getQbittorrentRssFeedURL(ad.settings.Provider, "test"); !ok {
+		ad.logger.Warn().Msgf("autodownloader: Provider '%s' does not support qBittorrent RSS, falling back to regular checks", ad.settings.Provider)
+		return false
+	}
+
+	if err := ad.syncQbittorrentRss(); err != nil {
+		ad.logger.Error().Err(err).Msg("autodownloader: Failed to sync qBittorrent RSS rules")
+		return true
+	}
+
+	ad.importQbittorrentRssDownloads()
+	return true
+}
+
+// syncQbittorrentRss creates or updates the feeds and rules for each enabled rule
+// and removes the feeds and rules that are no longer needed.
+func (ad *AutoDownloader) syncQbittorrentRss() error {
+	client, ok := ad.torrentClientRepository.GetQbittorrentClient()
+	if !ok {
+		return fmt.Errorf("qBittorrent is not the selected torrent client")
+	}
+
+	if started := ad.torrentClientRepository.Start(); !started {
+		return fmt.Errorf("torrent client is not running")
+	}
+
+	rules, err := db_bridge.GetAutoDownloaderRules(ad.database)
+	if err != nil {
+		return err
+	}
+
+	// Feed URL -> Feed name
+	feeds := make(map[string]string)
+	// Rule name -> Rule definition
+	ruleDefs := make(map[string]qbittorrent_model.RuleDefinition)
+
+	for _, rule := range rules {
+		if !rule.Enabled || rule.ComparisonTitle == "" {
+			continue
+		}
+
+		feedURL, ok := getQbittorrentRssFeedURL(ad.settings.Provider, rule.ComparisonTitle)
+		if !ok {
+			return fmt.Errorf("provider '%s' does not support RSS feeds", ad.settings.Provider)
+		}
+		feeds[feedURL] = getQbittorrentRssFeedName(ad.settings.Provider, rule.ComparisonTitle)
+
+		// Exclude the episodes that have already been downloaded
+		downloadedEpisodes := make([]int, 0)
+		if items, err := ad.database.GetAutoDownloaderItemByMediaId(rule.MediaId); err == nil {
+			for _, item := range items {
+				downloadedEpisodes = append(downloadedEpisodes, item.Episode)
+			}
+		}
+
+		ruleDefs[getQbittorrentRssRuleName(rule)] = buildQbittorrentRssRuleDefinition(rule, feedURL, downloadedEpisodes, !ad.settings.DownloadAutomatically)
+	}
+
+	// +---------------------+
+	// |        Feeds        |
+	// +---------------------+
+
+	existingFeeds, folderExists, err := getQbittorrentRssFolderFeeds(client)
+	if err != nil {
+		return err
+	}
+
+	if !folderExists && len(feeds) > 0 {
+		if err := client.RSS.AddFolder(qbittorrentRssFolder); err != nil {
+			return fmt.Errorf("failed to create RSS folder: %w", err)
+		}
+	}
+
+	for feedURL, name := range feeds {
+		if _, found := existingFeeds[feedURL]; found {
+			continue
+		}
+		if err := client.RSS.AddFeed(feedURL, qbittorrentRssFolder+"\\"+name); err != nil {
+			ad.logger.Error().Err(err).Str("url", feedURL).Msg("autodownloader: Failed to add qBittorrent RSS feed")
+		}
+	}
+
+	for feedURL, name := range existingFeeds {
+		if _, found := feeds[feedURL]; found {
+			continue
+		}
+		if err := client.RSS.RemoveItem(qbittorrentRssFolder + "\\" + name); err != nil {
+			ad.logger.Error().Err(err).Str("url", feedURL).Msg("autodownloader: Failed to remove qBittorrent RSS feed")
+		}
+	}
+
+	// +---------------------+
+	// |        Rules        |
+	// +---------------------+
+
+	existingRules, err := client.RSS.GetRules()
+	if err != nil {
+		return fmt.Errorf("failed to get RSS rules: %w", err)
+	}
+
+	for name := range existingRules {
+		if !strings.HasPrefix(name, qbittorrentRssRulePrefix) {
+			continue
+		}
+		if _, found := ruleDefs[name]; found {
+			continue
+		}
+		if err := client.RSS.RemoveRule(name); err != nil {
+			ad.logger.Error().Err(err).Str("rule", name).Msg("autodownloader: Failed to remove qBittorrent RSS rule")
+		}
+	}
+
+	for name, def := range ruleDefs {
+		// Keep the state of the smart episode filter
+		if existing, found := existingRules[name]; found {
+			def.PreviouslyMatchedEpisodes = existing.PreviouslyMatchedEpisodes
+			def.LastMatch = existing.LastMatch
+		}
+		if err := client.RSS.AddRule(name, def); err != nil {
+			ad.logger.Error().Err(err).Str("rule", name).Msg("autodownloader: Failed to set qBittorrent RSS rule")
+		}
+	}
+
+	ad.logger.Debug().Int("feeds", len(feeds)).Int("rules", len(ruleDefs)).Msg("autodownloader: Synced qBittorrent RSS rules")
+
+	return nil
+}
+
+// removeQbittorrentRss removes the feeds and rules created by the auto downloader.
+func (ad *AutoDownloader) removeQbittorrentRss() {
+	defer util.HandlePanicInModuleThen("autodownloader/removeQbittorrentRss", func() {})
+
+	if ad.torrentClientRepository == nil {
+		return
+	}
+	client, ok := ad.torrentClientRepository.GetQbittorrentClient()
+	if !ok {
+		return
+	}
+
+	rules, err := client.RSS.GetRules()
+	if err != nil {
+		return
+	}
+	for name := range rules {
+		if strings.HasPrefix(name, qbittorrentRssRulePrefix) {
+			_ = client.RSS.RemoveRule(name)
+		}
+	}
+
+	if _, folderExists, err := getQbittorrentRssFolderFeeds(client); err == nil && folderExists {
+		_ = client.RSS.RemoveItem(qbittorrentRssFolder)
+	}
+
+	ad.logger.Debug().Msg("autodownloader: Removed qBittorrent RSS rules")
+}
+
+// importQbittorrentRssDownloads adds the torrents downloaded by the qBittorrent rules to the auto downloader history.
+func (ad *AutoDownloader) importQbittorrentRssDownloads() {
+	client, ok := ad.torrentClientRepository.GetQbittorrentClient()
+	if !ok {
+		return
+	}
+
+	category := qbittorrentRssCategory
+	torrents, err := client.Torrent.GetList(&qbittorrent_model.GetTorrentListOptions{Filter: "all", Category: &category})
+	if err != nil {
+		ad.logger.Error().Err(err).Msg("autodownloader: Failed to get qBittorrent torrents")
+		return
+	}
+	if len(torrents) == 0 {
+		return
+	}
+
+	rules, err := db_bridge.GetAutoDownloaderRules(ad.database)
+	if err != nil {
+		return
+	}
+
+	lfs, _, err := db_bridge.GetLocalFiles(ad.database)
+	if err != nil {
+		return
+	}
+	lfWrapper := anime.NewLocalFileWrapper(lfs)
+
+	imported := 0
+	for _, t := range torrents {
+		normalizedTorrent := &NormalizedTorrent{
+			AnimeTorrent: hibiketorrent.AnimeTorrent{
+				Name:     t.Name,
+				InfoHash: t.Hash,
+			},
+			ParsedData: habari.Parse(t.Name),
+		}
+
+		for _, rule := range rules {
+			if !rule.Enabled {
+				continue
+			}
+			listEntry, found := ad.getRuleListEntry(rule)
+			if !found {
+				continue
+			}
+			localEntry, _ := lfWrapper.GetLocalEntryById(rule.MediaId)
+
+			items, err := ad.database.GetAutoDownloaderItemByMediaId(rule.MediaId)
+			if err != nil {
+				items = make([]*models.AutoDownloaderItem, 0)
+			}
+			// Skip torrents that have already been imported
+			if slices.ContainsFunc(items, func(item *models.AutoDownloaderItem) bool { return strings.EqualFold(item.Hash, t.Hash) }) {
+				break
+			}
+
+			episode, ok := ad.torrentFollowsRule(normalizedTorrent, rule, listEntry, localEntry, items)
+			if !ok {
+				continue
+			}
+
+			_ = ad.database.InsertAutoDownloaderItem(&models.AutoDownloaderItem{
+				RuleID:      rule.DbID,
+				MediaID:     rule.MediaId,
+				Episode:     episode,
+				Hash:        t.Hash,
+				Magnet:      "magnet:?xt=urn:btih:" + t.Hash,
+				TorrentName: t.Name,
+				Downloaded:  ad.settings.DownloadAutomatically,
+			})
+			imported++
+			break
+		}
+	}
+
+	if imported > 0 {
+		ad.logger.Info().Int("count", imported).Msg("autodownloader: Imported torrents added by qBittorrent RSS rules")
+		notifier.GlobalNotifier.Notify(
+			notifier.AutoDownloader,
+			fmt.Sprintf("%d %s %s been added by qBittorrent.", imported, util.Pluralize(imported, "episode", "episodes"), util.Pluralize(imported, "has", "have")),
+		)
+		// Update the rules so that the imported episodes are excluded
+		if err := ad.syncQbittorrentRss(); err != nil {
+			ad.logger.Error().Err(err).Msg("autodownloader: Failed to sync qBittorrent RSS rules")
+		}
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// getQbittorrentRssFolderFeeds returns the feeds in the Seanime folder.
+// Feed URL -> Feed name
+func getQbittorrentRssFolderFeeds(client *qbittorrent.Client) (map[string]string, bool, error) {
+	items, err := client.RSS.GetItems(false)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get RSS items: %w", err)
+	}
+
+	ret := make(map[string]string)
+	folder, found := items[qbittorrentRssFolder]
+	if !found {
+		return ret, false, nil
+	}
+
+	var folderItems map[string]json.RawMessage
+	if err := json.Unmarshal(folder, &folderItems); err != nil {
+		return ret, true, nil
+	}
+	for name, item := range folderItems {
+		var feed struct {
+			URL string `json:"url"`
+		}
+		if err := json.Unmarshal(item, &feed); err == nil && feed.URL != "" {
+			ret[feed.URL] = name
+		}
+	}
+
+	return ret, true, nil
+}
+
+// getQbittorrentRssFeedURL returns the URL of the RSS feed searching for the title on the provider.
+func getQbittorrentRssFeedURL(provider string, title string) (string, bool) {
+	switch provider {
+	case torrent.ProviderNyaa:
+		return "https://nyaa.si/?page=rss&c=1_2&f=0&q=" + url.QueryEscape(title), true
+	case torrent.ProviderAnimeTosho:
+		return "https://feed.animetosho.org/rss2?q=" + url.QueryEscape(title), true
+	}
+	return "", false
+}
+
+func getQbittorrentRssFeedName(provider string, title string) string {
+	// Backslashes are used as path separators
+	return fmt.Sprintf("%s - %s", provider, strings.ReplaceAll(title, "\\", " "))
+}
+
+func getQbittorrentRssRuleName(rule *anime.AutoDownloaderRule) string {
+	return fmt.Sprintf("%s%d - %s", qbittorrentRssRulePrefix, rule.DbID, rule.ComparisonTitle)
+}
+
+// buildQbittorrentRssRuleDefinition translates the rule into a qBittorrent rule definition.
+// The conditions are combined into a single regular expression using lookaheads.
+//   - The title is only checked for the "contains" comparison type, the feed already searches for the title
+//   - Selected episodes are required, downloaded episodes are excluded
+func buildQbittorrentRssRuleDefinition(rule *anime.AutoDownloaderRule, feedURL string, downloadedEpisodes []int, addPaused bool) qbittorrent_model.RuleDefinition {
+	mustContain := "(?i)^"
+
+	if rule.TitleComparisonType == anime.AutoDownloaderRuleTitleComparisonContains && rule.ComparisonTitle != "" {
+		mustContain += "(?=.*" + regexp.QuoteMeta(rule.ComparisonTitle) + ")"
+	}
+
+	if len(rule.ReleaseGroups) > 0 {
+		mustContain += "(?=.*" + quoteAlternatives(rule.ReleaseGroups, nil) + ")"
+	}
+
+	if len(rule.Resolutions) > 0 {
+		mustContain += "(?=.*" + quoteAlternatives(rule.Resolutions, func(s string) string {
+			return strings.TrimSuffix(s, "p")
+		}) + ")"
+	}
+
+	for _, optionsText := range rule.AdditionalTerms {
+		options := strings.Split(strings.TrimSpace(optionsText), ",")
+		if len(options) == 0 || strings.TrimSpace(optionsText) == "" {
+			continue
+		}
+		mustContain += "(?=.*" + quoteAlternatives(options, strings.TrimSpace) + ")"
+	}
+
+	if rule.EpisodeType == anime.AutoDownloaderRuleEpisodeSelected && len(rule.EpisodeNumbers) > 0 {
+		mustContain += "(?=.*" + episodeNumbersRegex(rule.EpisodeNumbers) + ")"
+	}
+
+	mustNotContain := ""
+	if len(downloadedEpisodes) > 0 {
+		mustNotContain = "(?i)" + episodeNumbersRegex(downloadedEpisodes)
+	}
+
+	return qbittorrent_model.RuleDefinition{
+		Enabled:          true,
+		MustContain:      mustContain,
+		MustNotContain:   mustNotContain,
+		UseRegex:         true,
+		SmartFilter:      true,
+		AffectedFeeds:    []string{feedURL},
+		AddPaused:        addPaused,
+		AssignedCategory: qbittorrentRssCategory,
+		SavePath:         rule.Destination,
+	}
+}
+
+// quoteAlternatives returns a non-capturing group matching any of the values.
+func quoteAlternatives(values []string, transform func(string) string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		if transform != nil {
+			v = transform(v)
+		}
+		if v == "" {
+			continue
+		}
+		quoted = append(quoted, regexp.QuoteMeta(v))
+	}
+	return "(?:" + strings.Join(quoted, "|") + ")"
+}
+
+// episodeNumbersRegex returns a regular expression matching the episode numbers,
+// e.g. "Title - 05 (1080p)", "Title - 05v2", "Title S01E05".
+func episodeNumbersRegex(episodes []int) string {
+	episodes = slices.Clone(episodes)
+	sort.Ints(episodes)
+	episodes = slices.Compact(episodes)
+
+	numbers := make([]string, 0, len(episodes))
+	for _, ep := range episodes {
+		numbers = append(numbers, strconv.Itoa(ep))
+	}
+	return `(?:\s-\s|E)0*(?:` + strings.Join(numbers, "|") + `)(?:v\d+)?(?!\d)`
+}
@@ -0,0 +1,111 @@
+package autodownloader
+
+import (
+	"seanime/internal/library/anime"
+	"testing"
+
+	"github.com/dlclark/regexp2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildQbittorrentRssRuleDefinition(t *testing.T) {
+
+	tests := []struct {
+		name               string
+		rule               *anime.AutoDownloaderRule
+		downloadedEpisodes []int
+		expectedMatches    map[string]bool
+	}{
+		{
+			name: "Release group and resolution",
+			rule: &anime.AutoDownloaderRule{
+				ComparisonTitle:     "Dandadan",
+				TitleComparisonType: anime.AutoDownloaderRuleTitleComparisonContains,
+				EpisodeType:         anime.AutoDownloaderRuleEpisodeRecent,
+				ReleaseGroups:       []string{"SubsPlease", "Erai-raws"},
+				Resolutions:         []string{"1080p"},
+			},
+			expectedMatches: map[string]bool{
+				"[SubsPlease] Dandadan - 05 (1080p) [ABCDEF12].mkv":    true,
+				"[Erai-raws] Dandadan - 05 [1080p][Multiple Subtitle]": true,
+				"[SubsPlease] Dandadan - 05 (720p) [ABCDEF12].mkv":     false,
+				"[Other] Dandadan - 05 (1080p).mkv":                    false,
+				"[SubsPlease] Bleach - 05 (1080p) [ABCDEF12].mkv":      false,
+			},
+		},
+		{
+			name: "Downloaded episodes are excluded",
+			rule: &anime.AutoDownloaderRule{
+				ComparisonTitle:     "Dandadan",
+				TitleComparisonType: anime.AutoDownloaderRuleTitleComparisonLikely,
+				EpisodeType:         anime.AutoDownloaderRuleEpisodeRecent,
+			},
+			downloadedEpisodes: []int{1, 5, 5},
+			expectedMatches: map[string]bool{
+				"[SubsPlease] Dandadan - 05 (1080p).mkv":   false,
+				"[SubsPlease] Dandadan - 05v2 (1080p).mkv": false,
+				"[SubsPlease] Dandadan - 01 (1080p).mkv":   false,
+				"[SubsPlease] Dandadan - 15 (1080p).mkv":   true,
+				"[SubsPlease] Dandadan - 10 (1080p).mkv":   true,
+				"Dandadan S01E06 1080p WEB-DL":             true,
+			},
+		},
+		{
+			name: "Selected episodes and additional terms",
+			rule: &anime.AutoDownloaderRule{
+				ComparisonTitle:     "Dandadan",
+				TitleComparisonType: anime.AutoDownloaderRuleTitleComparisonContains,
+				EpisodeType:         anime.AutoDownloaderRuleEpisodeSelected,
+				EpisodeNumbers:      []int{2, 3},
+				AdditionalTerms:     []string{"H264, H.264", "CR"},
+			},
+			expectedMatches: map[string]bool{
+				"[Group] Dandadan - 02 (1080p CR WEB-DL H264).mkv": true,
+				"[Group] Dandadan S01E03 1080p CR WEB-DL H.264":    true,
+				"[Group] Dandadan - 04 (1080p CR WEB-DL H264).mkv": false,
+				"[Group] Dandadan - 02 (1080p CR WEB-DL HEVC).mkv": false,
+				"[Group] Dandadan - 02 (1080p WEB-DL H264).mkv":    false,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def := buildQbittorrentRssRuleDefinition(tt.rule, "https://nyaa.si/?page=rss", tt.downloadedEpisodes, false)
+
+			require.True(t, def.UseRegex)
+			require.Equal(t, []string{"https://nyaa.si/?page=rss"}, def.AffectedFeeds)
+			require.Equal(t, qbittorrentRssCategory, def.AssignedCategory)
+
+			mustContain := regexp2.MustCompile(def.MustContain, regexp2.None)
+			var mustNotContain *regexp2.Regexp
+			if def.MustNotContain != "" {
+				mustNotContain = regexp2.MustCompile(def.MustNotContain, regexp2.None)
+			}
+
+			for name, expected := range tt.expectedMatches {
+				matched, err := mustContain.MatchString(name)
+				require.NoError(t, err)
+				if matched && mustNotContain != nil {
+					excluded, err := mustNotContain.MatchString(name)
+					require.NoError(t, err)
+					matched = !excluded
+				}
+				require.Equalf(t, expected, matched, "unexpected result for %q", name)
+			}
+		})
+	}
+}
+
+func TestGetQbittorrentRssFeedURL(t *testing.T) {
+	feedURL, ok := getQbittorrentRssFeedURL("nyaa", "Dandadan 2nd Season")
+	require.True(t, ok)
+	require.Equal(t, "https://nyaa.si/?page=rss&c=1_2&f=0&q=Dandadan+2nd+Season", feedURL)
+
+	feedURL, ok = getQbittorrentRssFeedURL("animetosho", "Dandadan")
+	require.True(t, ok)
+	require.Equal(t, "https://feed.animetosho.org/rss2?q=Dandadan", feedURL)
+
+	_, ok = getQbittorrentRssFeedURL("seadex", "Dandadan")
+	require.False(t, ok)
+}
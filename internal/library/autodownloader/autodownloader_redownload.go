@@ -0,0 +1,126 @@
+package autodownloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"seanime/internal/database/db_bridge"
+	"seanime/internal/library/anime"
+	"seanime/internal/torrents/torrent"
+	"seanime/internal/util"
+	"seanime/internal/util/comparison"
+	"sort"
+	"time"
+
+	"github.com/5rahim/habari"
+)
+
+// Redownload searches for a torrent of the episode and downloads or queues it using the rule of the media.
+// This is used to replace local files that are broken.
+// The episode is downloaded even if it is already in the library or has been downloaded before.
+func (ad *AutoDownloader) Redownload(mediaId int, episode int) (err error) {
+	defer util.HandlePanicInModuleWithError("autodownloader/Redownload", &err)
+
+	if ad == nil || ad.torrentRepository == nil {
+		return errors.New("auto downloader is not available")
+	}
+
+	// Find an enabled rule for the media
+	var rule *anime.AutoDownloaderRule
+	for _, r := range db_bridge.GetAutoDownloaderRulesByMediaId(ad.database, mediaId) {
+		if r.Enabled {
+			rule = r
+			break
+		}
+	}
+	if rule == nil {
+		return errors.New("no auto downloader rule found for this media")
+	}
+
+	listEntry, found := ad.getRuleListEntry(rule)
+	if !found {
+		return errors.New("media not found in the anime collection")
+	}
+
+	providerExtension, found := ad.torrentRepository.GetDefaultAnimeProviderExtension()
+	if !found {
+		return errors.New("no default torrent provider found")
+	}
+
+	searchType := torrent.AnimeSearchTypeSimple
+	if providerExtension.GetProvider().GetSettings().CanSmartSearch {
+		searchType = torrent.AnimeSearchTypeSmart
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Minute)
+	defer cancel()
+
+	data, err := ad.torrentRepository.SearchAnime(ctx, torrent.AnimeSearchOptions{
+		Provider:      providerExtension.GetID(),
+		Type:          searchType,
+		Media:         listEntry.GetMedia(),
+		Query:         fmt.Sprintf("%s %02d", rule.ComparisonTitle, episode),
+		EpisodeNumber: episode,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to search torrents: %w", err)
+	}
+
+	candidates := make([]*NormalizedTorrent, 0)
+	for _, t := range data.Torrents {
+		if t.IsBatch {
+			continue
+		}
+		nt := &NormalizedTorrent{
+			AnimeTorrent: *t,
+			ParsedData:   habari.Parse(t.Name),
+		}
+		if !ad.isReleaseGroupMatch(nt.ParsedData.ReleaseGroup, rule) ||
+			!ad.isResolutionMatch(nt.ParsedData.VideoResolution, rule) ||
+			!ad.isAdditionalTermsMatch(nt.Name, rule) {
+			continue
+		}
+		if searchType == torrent.AnimeSearchTypeSimple {
+			if !ad.isTitleMatch(nt.ParsedData, nt.Name, rule, listEntry) {
+				continue
+			}
+			if len(nt.ParsedData.EpisodeNumber) != 1 {
+				continue
+			}
+			if ep, ok := util.StringToInt(nt.ParsedData.EpisodeNumber[0]); !ok || ep != episode {
+				continue
+			}
+		}
+		candidates = append(candidates, nt)
+	}
+
+	if len(candidates) == 0 {
+		return errors.New("no torrent found for this episode")
+	}
+
+	// Sort by resolution, then by seeders
+	sort.SliceStable(candidates, func(i, j int) bool {
+		qI := comparison.ExtractResolutionInt(candidates[i].ParsedData.VideoResolution)
+		qJ := comparison.ExtractResolutionInt(candidates[j].ParsedData.VideoResolution)
+		if qI != qJ {
+			return qI > qJ
+		}
+		return candidates[i].Seeders > candidates[j].Seeders
+	})
+
+	// Remove the previous items of the episode so that it can be downloaded again
+	items, _ := ad.database.GetAutoDownloaderItemByMediaId(mediaId)
+	for _, item := range items {
+		if item.Episode == episode {
+			_ = ad.database.DeleteAutoDownloaderItem(item.ID)
+		}
+	}
+
+	ad.logger.Debug().Int("mediaId", mediaId).Int("episode", episode).Str("torrent", candidates[0].Name).Msg("autodownloader: Re-downloading episode")
+
+	if ok := ad.downloadTorrent(candidates[0], rule, episode); !ok {
+		return errors.New("failed to download torrent")
+	}
+
+	return nil
+}
@@ -8,12 +8,15 @@ import (
 	"seanime/internal/database/db_bridge"
 	"seanime/internal/database/models"
 	"seanime/internal/events"
+	"seanime/internal/library/anime"
 	"seanime/internal/library/autodownloader"
+	"seanime/internal/library/nfo"
 	"seanime/internal/library/scanner"
 	"seanime/internal/library/summary"
 	"seanime/internal/notifier"
 	"seanime/internal/platforms/platform"
 	"seanime/internal/util"
+	"seanime/internal/util/filecache"
 	"sync"
 	"time"
 )
@@ -34,6 +37,7 @@ type (
 		db               *db.Database                   // Database instance is required to update the local files.
 		autoDownloader   *autodownloader.AutoDownloader // AutoDownloader instance is required to refresh queue.
 		metadataProvider metadata.Provider
+		fileCacher       *filecache.Cacher
 		logsDir          string
 	}
 	NewAutoScannerOptions struct {
@@ -45,6 +49,7 @@ type (
 		AutoDownloader   *autodownloader.AutoDownloader
 		WaitTime         time.Duration
 		MetadataProvider metadata.Provider
+		FileCacher       *filecache.Cacher
 		LogsDir          string
 	}
 )
@@ -69,6 +74,7 @@ func New(opts *NewAutoScannerOptions) *AutoScanner {
 		db:               opts.Database,
 		autoDownloader:   opts.AutoDownloader,
 		metadataProvider: opts.MetadataProvider,
+		fileCacher:       opts.FileCacher,
 		logsDir:          opts.LogsDir,
 	}
 }
@@ -202,6 +208,12 @@ func (as *AutoScanner) scan() {
 		return
 	}
 
+	// Get title aliases learned from manual matches
+	titleAliases, err := as.db.GetTitleAliases()
+	if err != nil {
+		as.logger.Warn().Err(err).Msg("autoscanner: Failed to get title aliases")
+	}
+
 	// Create a new scan logger
 	var scanLogger *scanner.ScanLogger
 	if as.logsDir != "" {
@@ -229,6 +241,9 @@ func (as *AutoScanner) scan() {
 		MetadataProvider:   as.metadataProvider,
 		MatchingThreshold:  as.settings.ScannerMatchingThreshold,
 		MatchingAlgorithm:  as.settings.ScannerMatchingAlgorithm,
+		FileIdentifier:     scanner.NewFileIdentifier(settings.Library, as.fileCacher, as.logger),
+		TitleAliases:       scanner.NewTitleAliases(titleAliases),
+		NFOReader:          scanner.NewNFOReader(settings.Library),
 	}
 
 	allLfs, err := sc.Scan()
@@ -262,7 +277,35 @@ func (as *AutoScanner) scan() {
 	// Refresh the queue
 	go as.autoDownloader.CleanUpDownloadedItems()
 
+	// Update the NFO files
+	if settings.Library.ExportNFOFiles && len(allLfs) > 0 {
+		go as.exportNFOFiles(settings.Library, allLfs)
+	}
+
 	notifier.GlobalNotifier.Notify(notifier.AutoScanner, "Your library has been scanned.")
 
 	return
 }
+
+// exportNFOFiles writes the NFO files of the library.
+func (as *AutoScanner) exportNFOFiles(settings *models.LibrarySettings, lfs []*anime.LocalFile) {
+	defer util.HandlePanicInModuleThen("scanner/autoscanner/exportNFOFiles", func() {})
+
+	animeCollection, err := as.platform.GetAnimeCollection(false)
+	if err != nil {
+		as.logger.Error().Err(err).Msg("autoscanner: Failed to export NFO files")
+		return
+	}
+
+	exporter := &nfo.Exporter{
+		Logger:           as.logger,
+		Platform:         as.platform,
+		MetadataProvider: as.metadataProvider,
+		AnimeCollection:  animeCollection,
+		LibraryPaths:     settings.GetLibraryPaths(),
+	}
+
+	if _, err := exporter.Export(lfs, nfo.NewExportOptions(settings)); err != nil {
+		as.logger.Error().Err(err).Msg("autoscanner: Failed to export NFO files")
+	}
+}
@@ -0,0 +1,99 @@
+package healthcheck
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+const (
+	IssueZeroLength              IssueType = "zero_length"
+	IssueUnreadable              IssueType = "unreadable"
+	IssueTruncated               IssueType = "truncated"
+	IssueDurationMismatch        IssueType = "duration_mismatch"
+	IssueMissingAudioLanguage    IssueType = "missing_audio_language"
+	IssueMissingSubtitleLanguage IssueType = "missing_subtitle_language"
+)
+
+const (
+	// Maximum gap between the last video packet and the duration of the file, in seconds
+	truncationTolerance = 10.0
+	// Minimum and relative gap between the duration of the file and the expected duration
+	durationMismatchMinTolerance   = 120.0
+	durationMismatchRatioTolerance = 0.2
+)
+
+type (
+	IssueType string
+
+	Issue struct {
+		Type    IssueType `json:"type"`
+		Message string    `json:"message"`
+	}
+)
+
+// ShouldRedownload returns true if the file is certainly broken and should be downloaded again.
+// Unreadable files and duration mismatches are only reported, they can be caused by ffprobe or wrong metadata.
+func (i *Issue) ShouldRedownload() bool {
+	switch i.Type {
+	case IssueZeroLength, IssueTruncated:
+		return true
+	}
+	return false
+}
+
+// Evaluate returns the issues of a probed file.
+// expectedDuration is the duration of the episode in seconds, 0 if unknown.
+func Evaluate(res *ProbeResult, expectedDuration float64, opts *Options) []*Issue {
+	ret := make([]*Issue, 0)
+
+	if res.Size == 0 {
+		return append(ret, &Issue{Type: IssueZeroLength, Message: "The file is empty"})
+	}
+
+	if res.Error != "" {
+		return append(ret, &Issue{Type: IssueUnreadable, Message: res.Error})
+	}
+
+	if !res.HasVideo {
+		return append(ret, &Issue{Type: IssueUnreadable, Message: "The file has no video stream"})
+	}
+
+	if res.Duration > 0 && res.Duration-res.LastPacketTime > truncationTolerance {
+		ret = append(ret, &Issue{
+			Type:    IssueTruncated,
+			Message: fmt.Sprintf("The video stops at %s but the file should last %s", formatSeconds(res.LastPacketTime), formatSeconds(res.Duration)),
+		})
+	}
+
+	if expectedDuration > 0 {
+		tolerance := max(durationMismatchMinTolerance, expectedDuration*durationMismatchRatioTolerance)
+		if math.Abs(res.Duration-expectedDuration) > tolerance {
+			ret = append(ret, &Issue{
+				Type:    IssueDurationMismatch,
+				Message: fmt.Sprintf("The file lasts %s but the episode should last about %s", formatSeconds(res.Duration), formatSeconds(expectedDuration)),
+			})
+		}
+	}
+
+	if opts != nil {
+		for _, lang := range opts.RequiredAudioLanguages {
+			if lang = normalizeLanguage(lang); lang != "" && !slices.Contains(res.AudioLanguages, lang) {
+				ret = append(ret, &Issue{Type: IssueMissingAudioLanguage, Message: fmt.Sprintf("No audio track in %q", lang)})
+			}
+		}
+		for _, lang := range opts.RequiredSubtitleLanguages {
+			if lang = normalizeLanguage(lang); lang != "" && !slices.Contains(res.SubtitleLanguages, lang) {
+				ret = append(ret, &Issue{Type: IssueMissingSubtitleLanguage, Message: fmt.Sprintf("No subtitle track in %q", lang)})
+			}
+		}
+	}
+
+	return ret
+}
+
+// formatSeconds formats the duration as "mm:ss".
+func formatSeconds(seconds float64) string {
+	s := int(math.Round(seconds))
+	return fmt.Sprintf("%02d:%02d", s/60, s%60)
+}
@@ -0,0 +1,131 @@
+package healthcheck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluate(t *testing.T) {
+
+	healthy := func() *ProbeResult {
+		return &ProbeResult{
+			Size:              300_000_000,
+			Duration:          1420,
+			LastPacketTime:    1419.5,
+			HasVideo:          true,
+			AudioLanguages:    []string{"ja"},
+			SubtitleLanguages: []string{"en", ""},
+		}
+	}
+
+	tests := []struct {
+		name             string
+		res              func() *ProbeResult
+		expectedDuration float64
+		opts             *Options
+		expectedIssues   []IssueType
+	}{
+		{
+			name:             "healthy file",
+			res:              healthy,
+			expectedDuration: 24 * 60,
+			opts: &Options{
+				RequiredAudioLanguages:    []string{"jpn"},
+				RequiredSubtitleLanguages: []string{"eng"},
+			},
+			expectedIssues: []IssueType{},
+		},
+		{
+			name: "zero-length file",
+			res: func() *ProbeResult {
+				return &ProbeResult{}
+			},
+			expectedIssues: []IssueType{IssueZeroLength},
+		},
+		{
+			name: "unreadable file",
+			res: func() *ProbeResult {
+				res := healthy()
+				res.Error = "Invalid data found when processing input"
+				return res
+			},
+			expectedIssues: []IssueType{IssueUnreadable},
+		},
+		{
+			name: "no video stream",
+			res: func() *ProbeResult {
+				res := healthy()
+				res.HasVideo = false
+				return res
+			},
+			expectedIssues: []IssueType{IssueUnreadable},
+		},
+		{
+			name: "truncated file",
+			res: func() *ProbeResult {
+				res := healthy()
+				res.LastPacketTime = 700
+				return res
+			},
+			expectedIssues: []IssueType{IssueTruncated},
+		},
+		{
+			name:             "duration within tolerance",
+			res:              healthy,
+			expectedDuration: 25 * 60,
+			expectedIssues:   []IssueType{},
+		},
+		{
+			name: "duration mismatch",
+			res: func() *ProbeResult {
+				res := healthy()
+				res.Duration = 90
+				res.LastPacketTime = 89.9
+				return res
+			},
+			expectedDuration: 24 * 60,
+			expectedIssues:   []IssueType{IssueDurationMismatch},
+		},
+		{
+			name:             "missing languages",
+			res:              healthy,
+			expectedDuration: 0,
+			opts: &Options{
+				RequiredAudioLanguages:    []string{"en"},
+				RequiredSubtitleLanguages: []string{"en", "fr"},
+			},
+			expectedIssues: []IssueType{IssueMissingAudioLanguage, IssueMissingSubtitleLanguage},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := Evaluate(tt.res(), tt.expectedDuration, tt.opts)
+
+			types := make([]IssueType, 0, len(issues))
+			for _, issue := range issues {
+				types = append(types, issue.Type)
+			}
+			require.Equal(t, tt.expectedIssues, types)
+		})
+	}
+}
+
+func TestShouldRedownload(t *testing.T) {
+	redownloaded := make([]IssueType, 0)
+	for _, issueType := range []IssueType{IssueZeroLength, IssueUnreadable, IssueTruncated, IssueDurationMismatch, IssueMissingAudioLanguage, IssueMissingSubtitleLanguage} {
+		if (&Issue{Type: issueType}).ShouldRedownload() {
+			redownloaded = append(redownloaded, issueType)
+		}
+	}
+	require.Equal(t, []IssueType{IssueZeroLength, IssueTruncated}, redownloaded)
+}
+
+func TestCheckFFprobe(t *testing.T) {
+	require.Error(t, checkFFprobe("/nonexistent/ffprobe"))
+
+	c := NewChecker(&NewCheckerOptions{})
+	require.Error(t, c.Start(nil, "/nonexistent/ffprobe", nil))
+	require.Nil(t, c.GetReport())
+}
@@ -0,0 +1,347 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"seanime/internal/api/metadata"
+	"seanime/internal/events"
+	"seanime/internal/library/anime"
+	"seanime/internal/library/autodownloader"
+	"seanime/internal/util"
+	"seanime/internal/util/filecache"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/sourcegraph/conc/pool"
+)
+
+const (
+	maxWorkers = 2
+)
+
+type (
+	// Checker probes the local files with ffprobe to find broken files.
+	// The check runs in the background, its progress is sent to the client and the last report is kept in memory.
+	Checker struct {
+		logger           *zerolog.Logger
+		fileCacher       *filecache.Cacher
+		metadataProvider metadata.Provider
+		wsEventManager   events.WSEventManagerInterface
+		autoDownloader   *autodownloader.AutoDownloader
+
+		mu      sync.Mutex
+		cancel  context.CancelFunc
+		report  *Report
+		running bool
+	}
+
+	NewCheckerOptions struct {
+		Logger           *zerolog.Logger
+		FileCacher       *filecache.Cacher
+		MetadataProvider metadata.Provider
+		WSEventManager   events.WSEventManagerInterface
+		AutoDownloader   *autodownloader.AutoDownloader // optional
+	}
+
+	Options struct {
+		// Only check the files of these media, all files are checked if empty
+		MediaIds []int `json:"mediaIds"`
+		// e.g. ["ja"], files without an audio track in one of these languages are reported
+		RequiredAudioLanguages []string `json:"requiredAudioLanguages"`
+		// e.g. ["en"], files without a subtitle track in one of these languages are reported
+		RequiredSubtitleLanguages []string `json:"requiredSubtitleLanguages"`
+		// Queue a new download of empty or truncated episodes using the auto downloader rule of the media
+		AutoRedownload bool `json:"autoRedownload"`
+	}
+
+	Report struct {
+		IsRunning  bool       `json:"isRunning"`
+		StartedAt  time.Time  `json:"startedAt"`
+		FinishedAt *time.Time `json:"finishedAt,omitempty"`
+		Total      int        `json:"total"`
+		Checked    int        `json:"checked"`
+		// Files with at least one issue
+		Files       []*FileHealth `json:"files"`
+		Redownloads []*Redownload `json:"redownloads"`
+	}
+
+	FileHealth struct {
+		Path    string `json:"path"`
+		MediaId int    `json:"mediaId"`
+		Episode int    `json:"episode"`
+		// Duration of the file in seconds
+		Duration float64 `json:"duration"`
+		// Duration of the episode in seconds according to the metadata, 0 if unknown
+		ExpectedDuration float64  `json:"expectedDuration"`
+		Issues           []*Issue `json:"issues"`
+	}
+
+	Redownload struct {
+		MediaId int    `json:"mediaId"`
+		Episode int    `json:"episode"`
+		Error   string `json:"error,omitempty"`
+	}
+
+	ProgressEvent struct {
+		Total   int `json:"total"`
+		Checked int `json:"checked"`
+	}
+)
+
+func NewChecker(opts *NewCheckerOptions) *Checker {
+	return &Checker{
+		logger:           opts.Logger,
+		fileCacher:       opts.FileCacher,
+		metadataProvider: opts.MetadataProvider,
+		wsEventManager:   opts.WSEventManager,
+		autoDownloader:   opts.AutoDownloader,
+	}
+}
+
+// Start checks the local files in the background.
+// It returns an error if a check is already running or if ffprobe cannot be run.
+func (c *Checker) Start(lfs []*anime.LocalFile, ffprobePath string, opts *Options) error {
+	// Without ffprobe every file would be reported as unreadable
+	if err := checkFFprobe(ffprobePath); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.running {
+		return errors.New("a health check is already running")
+	}
+
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	toCheck := make([]*anime.LocalFile, 0, len(lfs))
+	for _, lf := range lfs {
+		if len(opts.MediaIds) > 0 && !slices.Contains(opts.MediaIds, lf.MediaId) {
+			continue
+		}
+		toCheck = append(toCheck, lf)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.running = true
+	c.report = &Report{
+		IsRunning:   true,
+		StartedAt:   time.Now(),
+		Total:       len(toCheck),
+		Files:       make([]*FileHealth, 0),
+		Redownloads: make([]*Redownload, 0),
+	}
+
+	go c.run(ctx, toCheck, ffprobePath, opts)
+
+	return nil
+}
+
+// Cancel stops the running check.
+// The files checked before cancellation are kept in the report.
+func (c *Checker) Cancel() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cancel != nil {
+		c.cancel()
+	}
+}
+
+// GetReport returns a copy of the current or last report, nil if no check was run.
+func (c *Checker) GetReport() *Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.report == nil {
+		return nil
+	}
+
+	ret := *c.report
+	ret.Files = slices.Clone(c.report.Files)
+	ret.Redownloads = slices.Clone(c.report.Redownloads)
+	return &ret
+}
+
+func (c *Checker) run(ctx context.Context, lfs []*anime.LocalFile, ffprobePath string, opts *Options) {
+	defer util.HandlePanicInModuleThen("library/healthcheck/run", func() {
+		c.finish()
+	})
+
+	c.logger.Info().Int("count", len(lfs)).Msg("health check: Checking local files")
+
+	expectedDurations := newExpectedDurations(c.metadataProvider)
+
+	p := pool.New().WithMaxGoroutines(maxWorkers)
+	for _, lf := range lfs {
+		p.Go(func() {
+			if ctx.Err() != nil {
+				return
+			}
+
+			fh := c.checkFile(lf, ffprobePath, expectedDurations.get(lf), opts)
+
+			c.mu.Lock()
+			c.report.Checked++
+			if len(fh.Issues) > 0 {
+				c.report.Files = append(c.report.Files, fh)
+			}
+			progress := ProgressEvent{Total: c.report.Total, Checked: c.report.Checked}
+			c.mu.Unlock()
+
+			c.wsEventManager.SendEvent(events.LibraryHealthCheckProgress, progress)
+		})
+	}
+	p.Wait()
+
+	if opts.AutoRedownload && ctx.Err() == nil {
+		c.redownload()
+	}
+
+	c.logger.Info().Msg("health check: Finished checking local files")
+
+	c.finish()
+}
+
+func (c *Checker) finish() {
+	c.mu.Lock()
+	if c.report != nil && c.report.IsRunning {
+		now := time.Now()
+		c.report.IsRunning = false
+		c.report.FinishedAt = &now
+		// Sort the files so that the report is stable
+		slices.SortFunc(c.report.Files, func(a, b *FileHealth) int {
+			if a.MediaId != b.MediaId {
+				return a.MediaId - b.MediaId
+			}
+			if a.Episode != b.Episode {
+				return a.Episode - b.Episode
+			}
+			if a.Path < b.Path {
+				return -1
+			}
+			return 1
+		})
+	}
+	c.running = false
+	c.cancel = nil
+	c.mu.Unlock()
+
+	c.wsEventManager.SendEvent(events.LibraryHealthCheckCompleted, nil)
+}
+
+func (c *Checker) checkFile(lf *anime.LocalFile, ffprobePath string, expectedDuration float64, opts *Options) *FileHealth {
+	ret := &FileHealth{
+		Path:             lf.GetPath(),
+		MediaId:          lf.MediaId,
+		ExpectedDuration: expectedDuration,
+		Issues:           make([]*Issue, 0),
+	}
+	if lf.Metadata != nil {
+		ret.Episode = lf.Metadata.Episode
+	}
+
+	res, err := c.probe(ffprobePath, lf.GetPath())
+	if err != nil {
+		c.logger.Debug().Err(err).Str("path", lf.GetPath()).Msg("health check: Failed to probe file")
+		ret.Issues = append(ret.Issues, &Issue{Type: IssueUnreadable, Message: err.Error()})
+		return ret
+	}
+
+	ret.Duration = res.Duration
+	ret.Issues = Evaluate(res, expectedDuration, opts)
+
+	return ret
+}
+
+// redownload queues a new download of the main episodes that are empty or truncated.
+func (c *Checker) redownload() {
+	if c.autoDownloader == nil {
+		return
+	}
+
+	type key struct {
+		mediaId int
+		episode int
+	}
+
+	c.mu.Lock()
+	keys := make([]key, 0)
+	for _, fh := range c.report.Files {
+		if fh.MediaId == 0 || fh.Episode <= 0 {
+			continue
+		}
+		if !slices.ContainsFunc(fh.Issues, func(i *Issue) bool { return i.ShouldRedownload() }) {
+			continue
+		}
+		k := key{mediaId: fh.MediaId, episode: fh.Episode}
+		if !slices.Contains(keys, k) {
+			keys = append(keys, k)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, k := range keys {
+		rd := &Redownload{MediaId: k.mediaId, Episode: k.episode}
+		if err := c.autoDownloader.Redownload(k.mediaId, k.episode); err != nil {
+			c.logger.Warn().Err(err).Int("mediaId", k.mediaId).Int("episode", k.episode).Msg("health check: Failed to queue re-download")
+			rd.Error = err.Error()
+		}
+
+		c.mu.Lock()
+		c.report.Redownloads = append(c.report.Redownloads, rd)
+		c.mu.Unlock()
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// expectedDurations returns the duration of episodes from the metadata, fetching the metadata of each media once.
+type expectedDurations struct {
+	provider metadata.Provider
+	mu       sync.Mutex
+	metadata map[int]*metadata.AnimeMetadata
+}
+
+func newExpectedDurations(provider metadata.Provider) *expectedDurations {
+	return &expectedDurations{
+		provider: provider,
+		metadata: make(map[int]*metadata.AnimeMetadata),
+	}
+}
+
+// get returns the duration of the episode in seconds, 0 if unknown.
+func (ed *expectedDurations) get(lf *anime.LocalFile) float64 {
+	if ed.provider == nil || lf.MediaId == 0 || lf.Metadata == nil || lf.Metadata.AniDBEpisode == "" {
+		return 0
+	}
+	if lf.Metadata.Type != anime.LocalFileTypeMain && lf.Metadata.Type != anime.LocalFileTypeSpecial {
+		return 0
+	}
+
+	ed.mu.Lock()
+	animeMetadata, found := ed.metadata[lf.MediaId]
+	if !found {
+		animeMetadata, _ = ed.provider.GetAnimeMetadata(metadata.AnilistPlatform, lf.MediaId)
+		ed.metadata[lf.MediaId] = animeMetadata
+	}
+	ed.mu.Unlock()
+
+	if animeMetadata == nil {
+		return 0
+	}
+
+	episode, found := animeMetadata.FindEpisode(lf.Metadata.AniDBEpisode)
+	if !found || episode.Length <= 0 {
+		return 0
+	}
+
+	// Length is in minutes
+	return float64(episode.Length) * 60
+}
@@ -0,0 +1,193 @@
+package healthcheck
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"seanime/internal/mediastream/videofile"
+	"seanime/internal/util"
+	"seanime/internal/util/filecache"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+	"gopkg.in/vansante/go-ffprobe.v2"
+)
+
+const (
+	probeBucketName = "library_health_check"
+	probeCacheTTL   = 24 * 7 * 52 * time.Hour
+	probeTimeout    = 40 * time.Second
+	// Number of seconds read at the end of the file to check that it is complete
+	tailReadSeconds = 30
+)
+
+// ProbeResult holds the information extracted from a file by ffprobe.
+type ProbeResult struct {
+	Size int64 `json:"size"`
+	// Duration reported by the container, in seconds
+	Duration float64 `json:"duration"`
+	// Timestamp of the last video packet that could be read, in seconds
+	LastPacketTime    float64  `json:"lastPacketTime"`
+	HasVideo          bool     `json:"hasVideo"`
+	AudioLanguages    []string `json:"audioLanguages"`
+	SubtitleLanguages []string `json:"subtitleLanguages"`
+	// Error returned by ffprobe if the file could not be read
+	Error string `json:"error,omitempty"`
+}
+
+// probe returns the probe result of the file.
+// Results are cached using the path and modification time of the file so that unchanged files are not probed again.
+func (c *Checker) probe(ffprobePath string, path string) (*ProbeResult, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Zero-length files are not probed
+	if info.Size() == 0 {
+		return &ProbeResult{}, nil
+	}
+
+	hash, err := videofile.GetHashFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket := filecache.NewBucket(probeBucketName, probeCacheTTL)
+
+	var ret *ProbeResult
+	if found, _ := c.fileCacher.Get(bucket, hash, &ret); found && ret != nil {
+		return ret, nil
+	}
+
+	ret = probeFile(ffprobePath, path)
+	ret.Size = info.Size()
+
+	// Failed probes are not cached, the error can be temporary (e.g. timeout)
+	if ret.Error == "" {
+		_ = c.fileCacher.Set(bucket, hash, ret)
+	}
+
+	return ret, nil
+}
+
+// checkFFprobe returns an error if the ffprobe binary cannot be run.
+func checkFFprobe(ffprobePath string) error {
+	if ffprobePath == "" {
+		ffprobePath = "ffprobe"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := util.NewCmdCtx(ctx, ffprobePath, "-version").Run(); err != nil {
+		return fmt.Errorf("health check: cannot run ffprobe (%s): %w", ffprobePath, err)
+	}
+	return nil
+}
+
+func probeFile(ffprobePath string, path string) *ProbeResult {
+	ret := &ProbeResult{
+		AudioLanguages:    make([]string, 0),
+		SubtitleLanguages: make([]string, 0),
+	}
+
+	if ffprobePath == "" {
+		ffprobePath = "ffprobe"
+	}
+	ffprobe.SetFFProbeBinPath(ffprobePath)
+
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	data, err := ffprobe.ProbeURL(ctx, path)
+	if err != nil {
+		ret.Error = err.Error()
+		return ret
+	}
+
+	if data.Format != nil {
+		ret.Duration = data.Format.DurationSeconds
+	}
+
+	for _, stream := range data.Streams {
+		switch stream.CodecType {
+		case string(ffprobe.StreamVideo):
+			// Cover images are attached as video streams
+			if stream.Disposition.AttachedPic == 0 {
+				ret.HasVideo = true
+			}
+		case string(ffprobe.StreamAudio):
+			ret.AudioLanguages = append(ret.AudioLanguages, normalizeLanguage(stream.Tags.Language))
+		case string(ffprobe.StreamSubtitle):
+			ret.SubtitleLanguages = append(ret.SubtitleLanguages, normalizeLanguage(stream.Tags.Language))
+		}
+	}
+
+	if ret.HasVideo && ret.Duration > 0 {
+		lastPacketTime, err := getLastPacketTime(ffprobePath, path, ret.Duration)
+		if err != nil {
+			ret.Error = err.Error()
+			return ret
+		}
+		ret.LastPacketTime = lastPacketTime
+	}
+
+	return ret
+}
+
+// getLastPacketTime reads the video packets at the end of the file and returns the timestamp of the last one.
+// The timestamp is lower than the duration if the file is truncated.
+func getLastPacketTime(ffprobePath string, path string, duration float64) (float64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	start := max(0, duration-tailReadSeconds)
+
+	cmd := util.NewCmdCtx(ctx,
+		ffprobePath,
+		"-loglevel", "error",
+		"-select_streams", "v:0",
+		"-read_intervals", fmt.Sprintf("%.3f%%", start),
+		"-show_entries", "packet=pts_time",
+		"-of", "csv=print_section=0",
+		path,
+	)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return 0, err
+	}
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+
+	ret := 0.0
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		pts, err := strconv.ParseFloat(strings.Trim(strings.TrimSpace(scanner.Text()), ","), 64)
+		if err != nil {
+			continue
+		}
+		ret = max(ret, pts)
+	}
+
+	if err := cmd.Wait(); err != nil {
+		return 0, fmt.Errorf("failed to read the end of the file: %w", err)
+	}
+
+	return ret, nil
+}
+
+// normalizeLanguage returns the BCP 47 tag of the language, or an empty string if it is undefined.
+//   - "jpn" -> "ja"
+//   - "und" -> ""
+func normalizeLanguage(lang string) string {
+	tag, err := language.Parse(strings.TrimSpace(lang))
+	if err != nil || tag == language.Und {
+		return ""
+	}
+	return tag.String()
+}
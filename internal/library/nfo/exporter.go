@@ -0,0 +1,423 @@
+package nfo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"seanime/internal/api/anilist"
+	"seanime/internal/api/metadata"
+	"seanime/internal/database/models"
+	"seanime/internal/library/anime"
+	"seanime/internal/platforms/platform"
+	"seanime/internal/util"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/samber/lo"
+)
+
+const (
+	PosterFilename = "poster.jpg"
+	FanartFilename = "fanart.jpg"
+)
+
+type (
+	// Exporter writes Kodi/Jellyfin NFO files and artwork for the matched local files.
+	// Files are written next to the media files, or in a mirror directory that has the same layout as the library.
+	Exporter struct {
+		Logger           *zerolog.Logger
+		Platform         platform.Platform
+		MetadataProvider metadata.Provider
+		AnimeCollection  *anilist.AnimeCollection // optional - media not in the collection are fetched
+		LibraryPaths     []string
+		httpClient       *http.Client
+	}
+
+	ExportOptions struct {
+		// Only export the files of these media, all matched files are exported if empty
+		MediaIds []int `json:"mediaIds"`
+		// Write the files in this directory instead of next to the media files
+		MirrorDir string `json:"mirrorDir"`
+		// Download the poster, fanart and episode thumbnails
+		DownloadImages bool `json:"downloadImages"`
+	}
+
+	ExportResult struct {
+		// Number of NFO files written
+		Written int `json:"written"`
+		// Number of NFO files that were already up-to-date
+		Unchanged int `json:"unchanged"`
+		// Number of NFO files that were not written because they were created by another program
+		Skipped int `json:"skipped"`
+		// Number of images downloaded
+		Images int      `json:"images"`
+		Errors []string `json:"errors"`
+	}
+)
+
+// NewExportOptions returns the options used to export NFO files after scans.
+func NewExportOptions(settings *models.LibrarySettings) *ExportOptions {
+	return &ExportOptions{
+		MirrorDir:      settings.NFOMirrorDir,
+		DownloadImages: settings.NFODownloadImages,
+	}
+}
+
+// Export writes the NFO files of the local files.
+// Existing files are only rewritten if their content changed, images are only downloaded if they are missing.
+func (e *Exporter) Export(lfs []*anime.LocalFile, opts *ExportOptions) (ret *ExportResult, err error) {
+	defer util.HandlePanicInModuleWithError("library/nfo/Export", &err)
+
+	if opts == nil {
+		opts = &ExportOptions{}
+	}
+
+	if e.httpClient == nil {
+		e.httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	ret = &ExportResult{Errors: make([]string, 0)}
+
+	// Group the local files by media
+	groups := lo.GroupBy(lo.Filter(lfs, func(lf *anime.LocalFile, _ int) bool {
+		if lf.MediaId == 0 || lf.Metadata == nil || lf.IsIgnored() {
+			return false
+		}
+		return len(opts.MediaIds) == 0 || slices.Contains(opts.MediaIds, lf.MediaId)
+	}), func(lf *anime.LocalFile) int {
+		return lf.MediaId
+	})
+
+	showDirs := e.getShowDirs(groups)
+
+	e.Logger.Debug().Int("count", len(groups)).Msg("nfo: Exporting NFO files")
+
+	for mediaId, mediaLfs := range groups {
+		media, err := e.getMedia(mediaId)
+		if err != nil {
+			ret.Errors = append(ret.Errors, fmt.Sprintf("media %d: %s", mediaId, err.Error()))
+			continue
+		}
+
+		animeMetadata, _ := e.MetadataProvider.GetAnimeMetadata(metadata.AnilistPlatform, mediaId)
+
+		// Show
+		if showDir, ok := showDirs[mediaId]; ok {
+			dir, err := e.getDestinationDir(showDir, opts)
+			if err != nil {
+				ret.Errors = append(ret.Errors, err.Error())
+			} else {
+				e.writeFile(filepath.Join(dir, TVShowFilename), NewTVShow(media, animeMetadata), ret)
+				if opts.DownloadImages {
+					e.downloadImage(media.GetCoverImageSafe(), filepath.Join(dir, PosterFilename), ret)
+					e.downloadImage(lo.FromPtr(media.GetBannerImage()), filepath.Join(dir, FanartFilename), ret)
+				}
+			}
+		}
+
+		// Episodes
+		for _, lf := range mediaLfs {
+			if lf.Metadata.Type != anime.LocalFileTypeMain && lf.Metadata.Type != anime.LocalFileTypeSpecial {
+				continue
+			}
+			dir, err := e.getDestinationDir(filepath.Dir(lf.GetPath()), opts)
+			if err != nil {
+				ret.Errors = append(ret.Errors, err.Error())
+				continue
+			}
+			path := filepath.Join(dir, filepath.Base(lf.GetPath()))
+
+			episode := NewEpisode(media, animeMetadata, lf)
+			e.writeFile(GetEpisodePath(path), episode, ret)
+			if opts.DownloadImages && len(episode.Thumbs) > 0 {
+				e.downloadImage(episode.Thumbs[0].URL, GetEpisodeThumbPath(path), ret)
+			}
+		}
+	}
+
+	e.Logger.Info().
+		Int("written", ret.Written).
+		Int("unchanged", ret.Unchanged).
+		Int("skipped", ret.Skipped).
+		Int("images", ret.Images).
+		Int("errors", len(ret.Errors)).
+		Msg("nfo: Exported NFO files")
+
+	return ret, nil
+}
+
+// getShowDirs returns the directory of each media.
+// The directory of a media is the common parent directory of its files.
+// Media sharing a directory, or whose files are directly in a library directory, have no directory.
+func (e *Exporter) getShowDirs(groups map[int][]*anime.LocalFile) map[int]string {
+	ret := make(map[int]string)
+	count := make(map[string]int)
+
+	for mediaId, lfs := range groups {
+		dir := ""
+		for i, lf := range lfs {
+			if i == 0 {
+				dir = filepath.Dir(lf.GetPath())
+				continue
+			}
+			dir = commonDir(dir, filepath.Dir(lf.GetPath()))
+		}
+		if dir == "" || e.isLibraryPath(dir) {
+			continue
+		}
+		ret[mediaId] = dir
+		count[util.NormalizePath(dir)]++
+	}
+
+	for mediaId, dir := range ret {
+		if count[util.NormalizePath(dir)] > 1 {
+			delete(ret, mediaId)
+		}
+	}
+
+	return ret
+}
+
+func (e *Exporter) isLibraryPath(dir string) bool {
+	for _, p := range e.LibraryPaths {
+		if util.NormalizePath(p) == util.NormalizePath(dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// getDestinationDir returns the directory where the files of the directory are written.
+func (e *Exporter) getDestinationDir(dir string, opts *ExportOptions) (string, error) {
+	if opts.MirrorDir == "" {
+		return dir, nil
+	}
+	ret, ok := GetMirrorPath(e.LibraryPaths, opts.MirrorDir, dir)
+	if !ok {
+		return "", fmt.Errorf("%s is not in a library directory", dir)
+	}
+	return ret, nil
+}
+
+// GetMirrorPath returns the path in the mirror directory.
+//   - "/Anime/Show/Episode 1.mkv" -> "/Mirror/Show/Episode 1.mkv"
+func GetMirrorPath(libraryPaths []string, mirrorDir string, path string) (string, bool) {
+	for _, p := range libraryPaths {
+		if p == "" {
+			continue
+		}
+		if rel, err := filepath.Rel(p, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return filepath.Join(mirrorDir, rel), true
+		}
+	}
+	return "", false
+}
+
+func (e *Exporter) getMedia(mediaId int) (*anilist.BaseAnime, error) {
+	if e.AnimeCollection != nil {
+		if entry, found := e.AnimeCollection.GetListEntryFromAnimeId(mediaId); found && entry.GetMedia() != nil {
+			return entry.GetMedia(), nil
+		}
+	}
+	if e.Platform == nil {
+		return nil, errors.New("media not found")
+	}
+	return e.Platform.GetAnime(mediaId)
+}
+
+// writeFile writes the NFO file if its content changed.
+// Files that were not created by the exporter are not overwritten.
+func (e *Exporter) writeFile(path string, v interface{}, ret *ExportResult) {
+	data, err := Marshal(v)
+	if err != nil {
+		ret.Errors = append(ret.Errors, fmt.Sprintf("%s: %s", path, err.Error()))
+		return
+	}
+
+	if existing, err := os.ReadFile(path); err == nil {
+		if !IsGenerated(existing) {
+			ret.Skipped++
+			return
+		}
+		if bytes.Equal(existing, data) {
+			ret.Unchanged++
+			return
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		ret.Errors = append(ret.Errors, fmt.Sprintf("%s: %s", path, err.Error()))
+		return
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		ret.Errors = append(ret.Errors, fmt.Sprintf("%s: %s", path, err.Error()))
+		return
+	}
+	ret.Written++
+}
+
+// downloadImage downloads the image if the file does not exist.
+func (e *Exporter) downloadImage(url string, path string, ret *ExportResult) {
+	if url == "" {
+		return
+	}
+	if _, err := os.Stat(path); err == nil {
+		return
+	}
+
+	resp, err := e.httpClient.Get(url)
+	if err != nil {
+		ret.Errors = append(ret.Errors, fmt.Sprintf("%s: %s", url, err.Error()))
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		ret.Errors = append(ret.Errors, fmt.Sprintf("%s: status %d", url, resp.StatusCode))
+		return
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		ret.Errors = append(ret.Errors, fmt.Sprintf("%s: %s", url, err.Error()))
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		ret.Errors = append(ret.Errors, fmt.Sprintf("%s: %s", path, err.Error()))
+		return
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		ret.Errors = append(ret.Errors, fmt.Sprintf("%s: %s", path, err.Error()))
+		return
+	}
+	ret.Images++
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// NewTVShow creates the "tvshow.nfo" content of the media.
+func NewTVShow(media *anilist.BaseAnime, animeMetadata *metadata.AnimeMetadata) *TVShow {
+	ret := &TVShow{
+		Title:         media.GetPreferredTitle(),
+		OriginalTitle: lo.FromPtr(media.GetTitle().GetNative()),
+		Plot:          util.HTMLToPlainText(lo.FromPtr(media.GetDescription())),
+		Year:          media.GetStartYearSafe(),
+		Genres:        lo.Map(media.GetGenres(), func(g *string, _ int) string { return lo.FromPtr(g) }),
+		Thumbs:        make([]*Thumb, 0),
+		AnilistId:     strconv.Itoa(media.GetID()),
+		UniqueIds: []*UniqueId{
+			{Type: UniqueIdAnilist, Default: true, Value: strconv.Itoa(media.GetID())},
+		},
+	}
+
+	if media.GetStartDate() != nil {
+		ret.Premiered = formatDate(media.GetStartDate().GetYear(), media.GetStartDate().GetMonth(), media.GetStartDate().GetDay())
+	}
+
+	if media.GetStatus() != nil {
+		switch *media.GetStatus() {
+		case anilist.MediaStatusReleasing:
+			ret.Status = "Continuing"
+		case anilist.MediaStatusFinished, anilist.MediaStatusCancelled:
+			ret.Status = "Ended"
+		}
+	}
+
+	if media.GetIDMal() != nil {
+		ret.UniqueIds = append(ret.UniqueIds, &UniqueId{Type: UniqueIdMal, Value: strconv.Itoa(*media.GetIDMal())})
+	}
+
+	if animeMetadata != nil && animeMetadata.GetMappings() != nil {
+		if id := animeMetadata.GetMappings().AnidbId; id > 0 {
+			ret.AnidbId = strconv.Itoa(id)
+			ret.UniqueIds = append(ret.UniqueIds, &UniqueId{Type: UniqueIdAnidb, Value: ret.AnidbId})
+		}
+		if id := animeMetadata.GetMappings().ThetvdbId; id > 0 {
+			ret.TvdbId = strconv.Itoa(id)
+			ret.UniqueIds = append(ret.UniqueIds, &UniqueId{Type: UniqueIdTvdb, Value: ret.TvdbId})
+		}
+	}
+
+	if poster := media.GetCoverImageSafe(); poster != "" {
+		ret.Thumbs = append(ret.Thumbs, &Thumb{Aspect: "poster", URL: poster})
+	}
+	if banner := media.GetBannerImage(); banner != nil && *banner != "" {
+		ret.Fanart = &Fanart{Thumbs: []*Thumb{{URL: *banner}}}
+	}
+
+	return ret
+}
+
+// NewEpisode creates the NFO content of the local file.
+func NewEpisode(media *anilist.BaseAnime, animeMetadata *metadata.AnimeMetadata, lf *anime.LocalFile) *Episode {
+	ret := &Episode{
+		Title:     fmt.Sprintf("Episode %d", lf.Metadata.Episode),
+		ShowTitle: media.GetPreferredTitle(),
+		Season:    1,
+		Episode:   lf.Metadata.Episode,
+		Thumbs:    make([]*Thumb, 0),
+		UniqueIds: make([]*UniqueId, 0),
+		AnilistId: strconv.Itoa(media.GetID()),
+	}
+
+	if lf.Metadata.Type == anime.LocalFileTypeSpecial {
+		ret.Season = 0
+		ret.Title = fmt.Sprintf("Special %d", lf.Metadata.Episode)
+	}
+
+	if animeMetadata == nil {
+		return ret
+	}
+
+	episodeMetadata, found := animeMetadata.FindEpisode(lf.Metadata.AniDBEpisode)
+	if !found {
+		return ret
+	}
+
+	if episodeMetadata.Title != "" {
+		ret.Title = episodeMetadata.Title
+	}
+	ret.Plot = util.HTMLToPlainText(lo.CoalesceOrEmpty(episodeMetadata.Summary, episodeMetadata.Overview))
+	ret.Aired = episodeMetadata.AirDate
+	ret.Runtime = episodeMetadata.Length
+	if episodeMetadata.Image != "" {
+		ret.Thumbs = append(ret.Thumbs, &Thumb{URL: episodeMetadata.Image})
+	}
+	if episodeMetadata.AnidbEid > 0 {
+		ret.UniqueIds = append(ret.UniqueIds, &UniqueId{Type: UniqueIdAnidb, Default: true, Value: strconv.Itoa(episodeMetadata.AnidbEid)})
+	}
+	if episodeMetadata.TvdbId > 0 {
+		ret.UniqueIds = append(ret.UniqueIds, &UniqueId{Type: UniqueIdTvdb, Value: strconv.Itoa(episodeMetadata.TvdbId)})
+	}
+
+	return ret
+}
+
+func formatDate(year, month, day *int) string {
+	if year == nil || month == nil || day == nil {
+		return ""
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", *year, *month, *day)
+}
+
+// commonDir returns the deepest directory containing both directories.
+func commonDir(a, b string) string {
+	for {
+		if rel, err := filepath.Rel(a, b); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return a
+		}
+		parent := filepath.Dir(a)
+		if parent == a {
+			return ""
+		}
+		a = parent
+	}
+}
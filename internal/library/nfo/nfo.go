@@ -0,0 +1,164 @@
+package nfo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	TVShowFilename = "tvshow.nfo"
+
+	// generatedComment is written in the files created by the exporter.
+	// Files without it were created by another program and are never overwritten.
+	generatedComment = "<!-- Generated by Seanime -->"
+
+	UniqueIdAnilist = "anilist"
+	UniqueIdMal     = "mal"
+	UniqueIdAnidb   = "anidb"
+	UniqueIdTvdb    = "tvdb"
+)
+
+type (
+	// TVShow is the Kodi/Jellyfin "tvshow.nfo" format.
+	TVShow struct {
+		XMLName       xml.Name    `xml:"tvshow"`
+		Title         string      `xml:"title"`
+		OriginalTitle string      `xml:"originaltitle,omitempty"`
+		Plot          string      `xml:"plot,omitempty"`
+		Year          int         `xml:"year,omitempty"`
+		Premiered     string      `xml:"premiered,omitempty"`
+		Status        string      `xml:"status,omitempty"`
+		Genres        []string    `xml:"genre"`
+		Thumbs        []*Thumb    `xml:"thumb"`
+		Fanart        *Fanart     `xml:"fanart,omitempty"`
+		UniqueIds     []*UniqueId `xml:"uniqueid"`
+		// Jellyfin writes provider IDs as "<provider>id" elements
+		AnilistId string `xml:"anilistid,omitempty"`
+		AnidbId   string `xml:"anidbid,omitempty"`
+		TvdbId    string `xml:"tvdbid,omitempty"`
+	}
+
+	// Episode is the Kodi/Jellyfin "<filename>.nfo" format.
+	// Main episodes are in season 1 and specials in season 0.
+	Episode struct {
+		XMLName   xml.Name    `xml:"episodedetails"`
+		Title     string      `xml:"title"`
+		ShowTitle string      `xml:"showtitle,omitempty"`
+		Season    int         `xml:"season"`
+		Episode   int         `xml:"episode"`
+		Plot      string      `xml:"plot,omitempty"`
+		Aired     string      `xml:"aired,omitempty"`
+		Runtime   int         `xml:"runtime,omitempty"`
+		Thumbs    []*Thumb    `xml:"thumb"`
+		UniqueIds []*UniqueId `xml:"uniqueid"`
+		// AniList ID of the show, so that files can be identified without the "tvshow.nfo" file
+		AnilistId string `xml:"anilistid,omitempty"`
+	}
+
+	Thumb struct {
+		Aspect string `xml:"aspect,attr,omitempty"`
+		URL    string `xml:",chardata"`
+	}
+
+	Fanart struct {
+		Thumbs []*Thumb `xml:"thumb"`
+	}
+
+	UniqueId struct {
+		Type    string `xml:"type,attr"`
+		Default bool   `xml:"default,attr,omitempty"`
+		Value   string `xml:",chardata"`
+	}
+)
+
+// GetAnilistId returns the AniList ID of the show, 0 if there is none.
+func (s *TVShow) GetAnilistId() int {
+	return getAnilistId(s.UniqueIds, s.AnilistId)
+}
+
+// GetAnilistId returns the AniList ID of the show the episode belongs to, 0 if there is none.
+func (e *Episode) GetAnilistId() int {
+	return getAnilistId(e.UniqueIds, e.AnilistId)
+}
+
+// GetAnidbEpisode returns the AniDB episode number.
+//   - Season 0, Episode 1 -> "S1"
+//   - Season 1, Episode 1 -> "1"
+func (e *Episode) GetAnidbEpisode() string {
+	if e.Episode <= 0 {
+		return ""
+	}
+	if e.Season == 0 {
+		return "S" + strconv.Itoa(e.Episode)
+	}
+	return strconv.Itoa(e.Episode)
+}
+
+func getAnilistId(uniqueIds []*UniqueId, anilistId string) int {
+	for _, id := range uniqueIds {
+		if id != nil && strings.EqualFold(id.Type, UniqueIdAnilist) {
+			if ret, err := strconv.Atoi(strings.TrimSpace(id.Value)); err == nil {
+				return ret
+			}
+		}
+	}
+	if ret, err := strconv.Atoi(strings.TrimSpace(anilistId)); err == nil {
+		return ret
+	}
+	return 0
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// Marshal returns the content of the NFO file.
+func Marshal(v interface{}) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.NewBufferString(xml.Header)
+	buf.WriteString(generatedComment + "\n")
+	buf.Write(data)
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// IsGenerated returns true if the NFO file was created by the exporter.
+func IsGenerated(data []byte) bool {
+	return bytes.Contains(data, []byte(generatedComment))
+}
+
+// ReadTVShow reads a "tvshow.nfo" file.
+func ReadTVShow(path string) (*TVShow, error) {
+	ret := &TVShow{}
+	if err := readFile(path, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// ReadEpisode reads an episode NFO file.
+func ReadEpisode(path string) (*Episode, error) {
+	ret := &Episode{}
+	if err := readFile(path, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func readFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	// Some programs write NFO files with a different encoding declaration
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	return decoder.Decode(v)
+}
@@ -0,0 +1,178 @@
+package nfo
+
+import (
+	"os"
+	"path/filepath"
+	"seanime/internal/api/anilist"
+	"seanime/internal/api/metadata"
+	"seanime/internal/library/anime"
+	"seanime/internal/util"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReader_FindMatch(t *testing.T) {
+	libraryDir := t.TempDir()
+	mirrorDir := t.TempDir()
+
+	writeFile := func(path string, v interface{}) {
+		data, err := Marshal(v)
+		require.NoError(t, err)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, data, 0644))
+	}
+
+	// Show with a "tvshow.nfo" file and an episode NFO file for the special
+	writeFile(filepath.Join(libraryDir, "Show", TVShowFilename), &TVShow{
+		Title:     "Show",
+		UniqueIds: []*UniqueId{{Type: UniqueIdAnilist, Value: "21"}},
+	})
+	writeFile(filepath.Join(libraryDir, "Show", "Season 1", "Show - S01.nfo"), &Episode{
+		Title:     "Special",
+		Season:    0,
+		Episode:   1,
+		AnilistId: "21",
+	})
+	// Jellyfin-style NFO file in the mirror directory
+	writeFile(filepath.Join(mirrorDir, "Mirrored", TVShowFilename), &TVShow{
+		Title:     "Mirrored",
+		AnilistId: "1535",
+	})
+	// NFO file in the library directory should be ignored
+	writeFile(filepath.Join(libraryDir, TVShowFilename), &TVShow{
+		Title:     "Library",
+		UniqueIds: []*UniqueId{{Type: UniqueIdAnilist, Value: "1"}},
+	})
+
+	reader := NewReader([]string{libraryDir}, mirrorDir)
+
+	tests := []struct {
+		name                 string
+		path                 string
+		expectedFound        bool
+		expectedMediaId      int
+		expectedAnidbEpisode string
+	}{
+		{
+			name:            "tvshow.nfo in parent directory",
+			path:            filepath.Join(libraryDir, "Show", "Season 1", "Show - 01.mkv"),
+			expectedFound:   true,
+			expectedMediaId: 21,
+		},
+		{
+			name:                 "episode NFO file",
+			path:                 filepath.Join(libraryDir, "Show", "Season 1", "Show - S01.mkv"),
+			expectedFound:        true,
+			expectedMediaId:      21,
+			expectedAnidbEpisode: "S1",
+		},
+		{
+			name:            "tvshow.nfo in mirror directory",
+			path:            filepath.Join(libraryDir, "Mirrored", "Mirrored - 01.mkv"),
+			expectedFound:   true,
+			expectedMediaId: 1535,
+		},
+		{
+			name:          "tvshow.nfo in library directory",
+			path:          filepath.Join(libraryDir, "Other", "Other - 01.mkv"),
+			expectedFound: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, found := reader.FindMatch(tt.path)
+			require.Equal(t, tt.expectedFound, found)
+			if !found {
+				return
+			}
+			require.Equal(t, tt.expectedMediaId, match.MediaId)
+			require.Equal(t, tt.expectedAnidbEpisode, match.AnidbEpisode)
+		})
+	}
+}
+
+func TestExporter_writeFile(t *testing.T) {
+	dir := t.TempDir()
+	e := &Exporter{}
+
+	episode := &Episode{Title: "Episode 1", Season: 1, Episode: 1, AnilistId: "21"}
+
+	// Write, then keep the file if it did not change
+	path := filepath.Join(dir, "Show - 01.nfo")
+	ret := &ExportResult{}
+	e.writeFile(path, episode, ret)
+	e.writeFile(path, episode, ret)
+	require.Equal(t, 1, ret.Written)
+	require.Equal(t, 1, ret.Unchanged)
+
+	read, err := ReadEpisode(path)
+	require.NoError(t, err)
+	require.Equal(t, 21, read.GetAnilistId())
+	require.Equal(t, "1", read.GetAnidbEpisode())
+
+	// Files created by another program are not overwritten
+	otherPath := filepath.Join(dir, "Show - 02.nfo")
+	require.NoError(t, os.WriteFile(otherPath, []byte("<episodedetails><title>Custom</title></episodedetails>"), 0644))
+	ret = &ExportResult{}
+	e.writeFile(otherPath, episode, ret)
+	require.Equal(t, 1, ret.Skipped)
+
+	read, err = ReadEpisode(otherPath)
+	require.NoError(t, err)
+	require.Equal(t, "Custom", read.Title)
+}
+
+func TestNewEpisode(t *testing.T) {
+	media := &anilist.BaseAnime{
+		ID: 21,
+		Title: &anilist.BaseAnime_Title{
+			UserPreferred: lo.ToPtr("One Piece"),
+		},
+	}
+	animeMetadata := &metadata.AnimeMetadata{
+		Episodes: map[string]*metadata.EpisodeMetadata{
+			"1": {
+				Title:    "I'm Luffy!",
+				Summary:  "Summary",
+				AirDate:  "1999-10-20",
+				Length:   24,
+				Image:    "https://example.com/1.jpg",
+				AnidbEid: 123,
+			},
+		},
+	}
+
+	ep := NewEpisode(media, animeMetadata, &anime.LocalFile{
+		Path: util.NormalizePath("/Anime/One Piece/One Piece - 01.mkv"),
+		Metadata: &anime.LocalFileMetadata{
+			Episode:      1,
+			AniDBEpisode: "1",
+			Type:         anime.LocalFileTypeMain,
+		},
+	})
+
+	require.Equal(t, "I'm Luffy!", ep.Title)
+	require.Equal(t, "One Piece", ep.ShowTitle)
+	require.Equal(t, 1, ep.Season)
+	require.Equal(t, 1, ep.Episode)
+	require.Equal(t, 24, ep.Runtime)
+	require.Equal(t, "https://example.com/1.jpg", ep.Thumbs[0].URL)
+	require.Equal(t, "123", ep.UniqueIds[0].Value)
+	require.Equal(t, 21, ep.GetAnilistId())
+
+	special := NewEpisode(media, animeMetadata, &anime.LocalFile{
+		Path: util.NormalizePath("/Anime/One Piece/One Piece - S01.mkv"),
+		Metadata: &anime.LocalFileMetadata{
+			Episode:      1,
+			AniDBEpisode: "S1",
+			Type:         anime.LocalFileTypeSpecial,
+		},
+	})
+
+	require.Equal(t, "Special 1", special.Title)
+	require.Equal(t, 0, special.Season)
+	require.Equal(t, "S1", special.GetAnidbEpisode())
+}
@@ -0,0 +1,138 @@
+package nfo
+
+import (
+	"path/filepath"
+	"seanime/internal/util"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	// Number of parent directories searched for a "tvshow.nfo" file, e.g. "Show/Season 1/file.mkv"
+	maxTVShowDepth = 3
+)
+
+type (
+	// Reader finds the AniList IDs written in the NFO files of local files.
+	Reader struct {
+		libraryPaths []string
+		mirrorDir    string
+		mu           sync.Mutex
+		shows        map[string]*TVShow // Directory -> show, nil if the directory has no "tvshow.nfo" file
+	}
+
+	Match struct {
+		MediaId int
+		// Empty if the file was identified from a "tvshow.nfo" file
+		AnidbEpisode string
+		// Path of the NFO file
+		Path string
+	}
+)
+
+// NewReader returns a Reader.
+// If mirrorDir is not empty, NFO files are also searched in the mirror directory.
+func NewReader(libraryPaths []string, mirrorDir string) *Reader {
+	return &Reader{
+		libraryPaths: libraryPaths,
+		mirrorDir:    mirrorDir,
+		shows:        make(map[string]*TVShow),
+	}
+}
+
+// FindMatch returns the AniList ID of the file from its NFO files.
+// The episode NFO file is used first, then the "tvshow.nfo" file of the closest parent directory.
+// Library directories are not searched, a "tvshow.nfo" file should be in the directory of the show.
+func (r *Reader) FindMatch(path string) (*Match, bool) {
+	if r == nil {
+		return nil, false
+	}
+
+	if ret, ok := r.findMatch(path); ok {
+		return ret, true
+	}
+
+	if r.mirrorDir != "" {
+		if mirrorPath, ok := GetMirrorPath(r.libraryPaths, r.mirrorDir, path); ok {
+			return r.findMatch(mirrorPath)
+		}
+	}
+
+	return nil, false
+}
+
+func (r *Reader) findMatch(path string) (*Match, bool) {
+	episodePath := GetEpisodePath(path)
+	if ep, err := ReadEpisode(episodePath); err == nil {
+		if mediaId := ep.GetAnilistId(); mediaId > 0 {
+			return &Match{
+				MediaId:      mediaId,
+				AnidbEpisode: ep.GetAnidbEpisode(),
+				Path:         episodePath,
+			}, true
+		}
+	}
+
+	dir := filepath.Dir(path)
+	for i := 0; i < maxTVShowDepth; i++ {
+		if r.isRootDir(dir) {
+			break
+		}
+		if show := r.getShow(dir); show != nil {
+			if mediaId := show.GetAnilistId(); mediaId > 0 {
+				return &Match{
+					MediaId: mediaId,
+					Path:    filepath.Join(dir, TVShowFilename),
+				}, true
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	return nil, false
+}
+
+func (r *Reader) getShow(dir string) *TVShow {
+	key := util.NormalizePath(dir)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if show, found := r.shows[key]; found {
+		return show
+	}
+	show, err := ReadTVShow(filepath.Join(dir, TVShowFilename))
+	if err != nil {
+		show = nil
+	}
+	r.shows[key] = show
+	return show
+}
+
+// isRootDir returns true if the directory is a library directory or the mirror directory.
+func (r *Reader) isRootDir(dir string) bool {
+	dir = util.NormalizePath(dir)
+	if r.mirrorDir != "" && util.NormalizePath(r.mirrorDir) == dir {
+		return true
+	}
+	return slices.ContainsFunc(r.libraryPaths, func(p string) bool {
+		return util.NormalizePath(p) == dir
+	})
+}
+
+// GetEpisodePath returns the path of the NFO file of a media file.
+//   - "/Show/Episode 1.mkv" -> "/Show/Episode 1.nfo"
+func GetEpisodePath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".nfo"
+}
+
+// GetEpisodeThumbPath returns the path of the thumbnail of a media file.
+//   - "/Show/Episode 1.mkv" -> "/Show/Episode 1-thumb.jpg"
+func GetEpisodeThumbPath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + "-thumb.jpg"
+}
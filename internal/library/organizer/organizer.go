@@ -0,0 +1,562 @@
+package organizer
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"seanime/internal/api/anilist"
+	"seanime/internal/continuity"
+	"seanime/internal/database/db"
+	"seanime/internal/database/db_bridge"
+	"seanime/internal/events"
+	"seanime/internal/library/anime"
+	"seanime/internal/torrent_clients/torrent_client"
+	"seanime/internal/util"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/5rahim/habari"
+	"github.com/rs/zerolog"
+)
+
+const (
+	ModeMove     Mode = "move"
+	ModeHardlink Mode = "hardlink"
+	ModeCopy     Mode = "copy"
+
+	OperationStatusPending OperationStatus = "pending"
+	OperationStatusSkipped OperationStatus = "skipped"
+	OperationStatusDone    OperationStatus = "done"
+	OperationStatusFailed  OperationStatus = "failed"
+)
+
+type (
+	Mode            string
+	OperationStatus string
+
+	// Organizer renames and moves matched local files into a folder layout built from a template.
+	Organizer struct {
+		Logger            *zerolog.Logger
+		Database          *db.Database
+		AnimeCollection   *anilist.AnimeCollection
+		ContinuityManager *continuity.Manager
+		// Optional, used to detect files that are being seeded
+		TorrentClientRepository *torrent_client.Repository
+		// Library paths, the first one is used as the default destination
+		LibraryPaths []string
+		// Optional, used to send the progress of Start
+		WSEventManager events.WSEventManagerInterface
+	}
+
+	Options struct {
+		// e.g. "{romaji} ({year})/Season {season}/{title} - {episode:02} [{group}]"
+		Template string `json:"template"`
+		Mode     Mode   `json:"mode"`
+		// Root directory of the new layout, defaults to the main library path.
+		// It must be a library path or be inside one.
+		Destination string `json:"destination"`
+		// Only organize the files of these media, all matched files are organized if empty
+		MediaIds []int `json:"mediaIds"`
+		// Files that are part of a torrent are hard-linked instead of moved so that they can still be seeded
+		HardlinkSeedingFiles bool `json:"hardlinkSeedingFiles"`
+	}
+
+	ProgressEvent struct {
+		Total     int `json:"total"`
+		Processed int `json:"processed"`
+	}
+
+	CompletedEvent struct {
+		Plan  *Plan  `json:"plan,omitempty"`
+		Error string `json:"error,omitempty"`
+	}
+
+	// Plan is the list of operations that will be or have been performed.
+	Plan struct {
+		Operations []*Operation `json:"operations"`
+		Done       int          `json:"done"`
+		Skipped    int          `json:"skipped"`
+		Failed     int          `json:"failed"`
+	}
+
+	Operation struct {
+		MediaId     int    `json:"mediaId"`
+		Episode     int    `json:"episode"`
+		Source      string `json:"source"`
+		Destination string `json:"destination"`
+		// Can differ from the requested mode if the file is being seeded
+		Mode    Mode            `json:"mode"`
+		Seeding bool            `json:"seeding"`
+		Status  OperationStatus `json:"status"`
+		// Reason for skipping or error message
+		Reason string `json:"reason,omitempty"`
+	}
+)
+
+// Preview returns the operations that would be performed without touching the files.
+func (o *Organizer) Preview(opts *Options) (*Plan, error) {
+	defer util.HandlePanicInModuleThen("library/organizer/Preview", func() {})
+
+	lfs, _, err := db_bridge.GetLocalFiles(o.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	return o.buildPlan(lfs, opts)
+}
+
+// organizing is true while files are being organized, only one organization can run at a time
+var organizing atomic.Bool
+
+var ErrAlreadyOrganizing = errors.New("the library is already being organized")
+
+// Organize performs the operations and updates the stored local file paths.
+// The local files are saved once all the files have been processed.
+// If they cannot be saved, the operations are reverted so that the stored paths stay valid.
+func (o *Organizer) Organize(opts *Options) (plan *Plan, err error) {
+	defer util.HandlePanicInModuleWithError("library/organizer/Organize", &err)
+
+	if !organizing.CompareAndSwap(false, true) {
+		return nil, ErrAlreadyOrganizing
+	}
+	defer organizing.Store(false)
+
+	lfs, lfsId, plan, err := o.prepare(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return o.execute(lfs, lfsId, plan, opts)
+}
+
+// Start organizes the files in the background.
+// The options are validated before returning, progress is sent through websocket events and
+// the plan is sent with the completed event.
+func (o *Organizer) Start(opts *Options) error {
+	if !organizing.CompareAndSwap(false, true) {
+		return ErrAlreadyOrganizing
+	}
+
+	lfs, lfsId, plan, err := o.prepare(opts)
+	if err != nil {
+		organizing.Store(false)
+		return err
+	}
+
+	go func() {
+		defer organizing.Store(false)
+		defer util.HandlePanicInModuleThen("library/organizer/Start", func() {
+			o.sendEvent(events.LibraryOrganizerCompleted, CompletedEvent{Error: "an unexpected error occurred"})
+		})
+
+		plan, err := o.execute(lfs, lfsId, plan, opts)
+
+		event := CompletedEvent{Plan: plan}
+		if err != nil {
+			event.Error = err.Error()
+		}
+		o.sendEvent(events.LibraryOrganizerCompleted, event)
+	}()
+
+	return nil
+}
+
+func (o *Organizer) prepare(opts *Options) ([]*anime.LocalFile, uint, *Plan, error) {
+	lfs, lfsId, err := db_bridge.GetLocalFiles(o.Database)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	plan, err := o.buildPlan(lfs, opts)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	return lfs, lfsId, plan, nil
+}
+
+func (o *Organizer) sendEvent(t string, payload interface{}) {
+	if o.WSEventManager != nil {
+		o.WSEventManager.SendEvent(t, payload)
+	}
+}
+
+func (o *Organizer) execute(lfs []*anime.LocalFile, lfsId uint, plan *Plan, opts *Options) (*Plan, error) {
+	o.Logger.Info().Str("mode", string(opts.Mode)).Msg("organizer: Organizing library")
+
+	// Old path -> New path
+	renamed := make(map[string]string)
+	// Operations that are done, by normalized source path
+	doneBySource := make(map[string]*Operation)
+	done := make([]*Operation, 0)
+
+	progress := ProgressEvent{Total: plan.countPending()}
+	o.sendEvent(events.LibraryOrganizerProgress, progress)
+
+	for _, op := range plan.Operations {
+		if op.Status != OperationStatusPending {
+			continue
+		}
+
+		var opErr error
+		switch op.Mode {
+		case ModeMove:
+			opErr = moveFile(op.Source, op.Destination)
+		case ModeHardlink:
+			opErr = linkFile(op.Source, op.Destination)
+		case ModeCopy:
+			opErr = copyFile(op.Source, op.Destination)
+		}
+
+		progress.Processed++
+		o.sendEvent(events.LibraryOrganizerProgress, progress)
+
+		if opErr != nil {
+			o.Logger.Error().Err(opErr).Str("path", op.Source).Msg("organizer: Failed to organize file")
+			op.Status = OperationStatusFailed
+			op.Reason = opErr.Error()
+			continue
+		}
+
+		op.Status = OperationStatusDone
+		done = append(done, op)
+		renamed[util.NormalizePath(op.Source)] = op.Destination
+		doneBySource[util.NormalizePath(op.Source)] = op
+	}
+
+	if len(done) > 0 {
+		libraryPath, _ := o.getLibraryPathOf(opts.Destination)
+
+		// Update the local files
+		updatedLfs := make([]*anime.LocalFile, 0, len(lfs))
+		for _, lf := range lfs {
+			op, found := doneBySource[lf.GetNormalizedPath()]
+			if !found {
+				updatedLfs = append(updatedLfs, lf)
+				continue
+			}
+			if op.Mode == ModeMove {
+				updatedLfs = append(updatedLfs, relocateLocalFile(lf, op.Destination, libraryPath))
+				continue
+			}
+			// The source of a copy or hard link is still on disk, it is kept but ignored
+			// so that the next scan doesn't pick it up as a duplicate
+			source := *lf
+			source.Ignored = true
+			updatedLfs = append(updatedLfs, &source, relocateLocalFile(lf, op.Destination, libraryPath))
+		}
+
+		if _, err := db_bridge.SaveLocalFiles(o.Database, lfsId, updatedLfs); err != nil {
+			o.Logger.Error().Err(err).Msg("organizer: Failed to save local files, reverting changes")
+			o.revert(done)
+			return nil, fmt.Errorf("failed to save local files: %w", err)
+		}
+
+		o.updatePlaylists(renamed)
+
+		if o.ContinuityManager != nil {
+			if err := o.ContinuityManager.UpdateWatchHistoryItemFilepaths(renamed); err != nil {
+				o.Logger.Warn().Err(err).Msg("organizer: Failed to update watch history")
+			}
+		}
+
+		// Remove the directories left empty by moved files
+		for _, op := range done {
+			if op.Mode != ModeMove {
+				continue
+			}
+			if root, found := o.getLibraryPathOf(op.Source); found {
+				removeEmptyParents(op.Source, root)
+			}
+		}
+	}
+
+	plan.count()
+
+	o.Logger.Info().Int("done", plan.Done).Int("skipped", plan.Skipped).Int("failed", plan.Failed).Msg("organizer: Finished organizing library")
+
+	return plan, nil
+}
+
+// revert undoes the operations, it is called when the local files could not be saved.
+func (o *Organizer) revert(ops []*Operation) {
+	for _, op := range ops {
+		var err error
+		if op.Mode == ModeMove {
+			err = moveFile(op.Destination, op.Source)
+		} else {
+			err = removeFile(op.Destination)
+		}
+		if err != nil {
+			o.Logger.Error().Err(err).Str("path", op.Destination).Msg("organizer: Failed to revert operation")
+		}
+		op.Status = OperationStatusFailed
+		op.Reason = "reverted"
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (o *Organizer) buildPlan(lfs []*anime.LocalFile, opts *Options) (*Plan, error) {
+	if opts.Template == "" {
+		opts.Template = DefaultTemplate
+	}
+	if err := ValidateTemplate(opts.Template); err != nil {
+		return nil, err
+	}
+
+	switch opts.Mode {
+	case ModeMove, ModeHardlink, ModeCopy:
+	case "":
+		opts.Mode = ModeMove
+	default:
+		return nil, fmt.Errorf("invalid mode: %s", opts.Mode)
+	}
+
+	if opts.Destination == "" {
+		if len(o.LibraryPaths) == 0 || o.LibraryPaths[0] == "" {
+			return nil, errors.New("no destination or library path set")
+		}
+		opts.Destination = o.LibraryPaths[0]
+	}
+	opts.Destination = filepath.Clean(opts.Destination)
+	// Files organized outside the library would disappear from it on the next scan
+	if _, found := o.getLibraryPathOf(opts.Destination); !found {
+		return nil, fmt.Errorf("destination is not inside a library path: %s", opts.Destination)
+	}
+
+	if o.AnimeCollection == nil {
+		return nil, errors.New("anime collection not found")
+	}
+
+	seedingPaths := make([]string, 0)
+	if opts.Mode == ModeMove && opts.HardlinkSeedingFiles {
+		seedingPaths = o.getSeedingPaths()
+	}
+
+	plan := &Plan{
+		Operations: make([]*Operation, 0),
+	}
+
+	// Normalized destination -> Operation
+	destinations := make(map[string]*Operation)
+
+	for _, lf := range lfs {
+		if lf.MediaId == 0 || lf.IsIgnored() {
+			continue
+		}
+		if len(opts.MediaIds) > 0 && !slices.Contains(opts.MediaIds, lf.MediaId) {
+			continue
+		}
+
+		op := &Operation{
+			MediaId: lf.MediaId,
+			Episode: lf.GetEpisodeNumber(),
+			Source:  lf.GetPath(),
+			Mode:    opts.Mode,
+			Status:  OperationStatusPending,
+		}
+		plan.Operations = append(plan.Operations, op)
+
+		if lf.GetType() == anime.LocalFileTypeNC {
+			op.Status = OperationStatusSkipped
+			op.Reason = "not an episode"
+			continue
+		}
+
+		media, found := o.AnimeCollection.FindAnime(lf.MediaId)
+		if !found {
+			op.Status = OperationStatusSkipped
+			op.Reason = "media not found in collection"
+			continue
+		}
+
+		relPath := RenderTemplate(opts.Template, getTemplateValues(lf, media))
+		if relPath == "" {
+			op.Status = OperationStatusSkipped
+			op.Reason = "empty path"
+			continue
+		}
+		op.Destination = filepath.Join(opts.Destination, relPath+strings.ToLower(filepath.Ext(lf.GetPath())))
+
+		normalizedDest := util.NormalizePath(op.Destination)
+		if normalizedDest == lf.GetNormalizedPath() {
+			op.Status = OperationStatusSkipped
+			op.Reason = "already organized"
+			continue
+		}
+		if _, found := destinations[normalizedDest]; found {
+			op.Status = OperationStatusSkipped
+			op.Reason = "another file has the same destination"
+			continue
+		}
+		if fileExists(op.Destination) {
+			op.Status = OperationStatusSkipped
+			op.Reason = "destination already exists"
+			continue
+		}
+		destinations[normalizedDest] = op
+
+		if opts.Mode == ModeMove && isSeeding(lf.GetPath(), seedingPaths) {
+			op.Mode = ModeHardlink
+			op.Seeding = true
+		}
+	}
+
+	plan.count()
+
+	return plan, nil
+}
+
+func (p *Plan) countPending() int {
+	ret := 0
+	for _, op := range p.Operations {
+		if op.Status == OperationStatusPending {
+			ret++
+		}
+	}
+	return ret
+}
+
+func (p *Plan) count() {
+	p.Done, p.Skipped, p.Failed = 0, 0, 0
+	for _, op := range p.Operations {
+		switch op.Status {
+		case OperationStatusDone:
+			p.Done++
+		case OperationStatusSkipped:
+			p.Skipped++
+		case OperationStatusFailed:
+			p.Failed++
+		}
+	}
+}
+
+func getTemplateValues(lf *anime.LocalFile, media *anilist.BaseAnime) *TemplateValues {
+	values := &TemplateValues{
+		Title:   media.GetPreferredTitle(),
+		Romaji:  media.GetRomajiTitleSafe(),
+		English: media.GetEnglishTitleSafe(),
+		Year:    media.GetStartYearSafe(),
+		Season:  1,
+		Episode: strconv.Itoa(lf.GetEpisodeNumber()),
+		MediaId: media.GetID(),
+	}
+
+	if lf.GetType() == anime.LocalFileTypeSpecial && lf.GetAniDBEpisode() != "" {
+		values.Episode = lf.GetAniDBEpisode()
+	}
+
+	if lf.ParsedData != nil {
+		values.Group = lf.ParsedData.ReleaseGroup
+		values.EpisodeTitle = lf.ParsedData.EpisodeTitle
+		if season, err := strconv.Atoi(lf.ParsedData.Season); err == nil && season > 0 {
+			values.Season = season
+		}
+	}
+
+	values.Resolution = habari.Parse(lf.Name).VideoResolution
+
+	return values
+}
+
+// relocateLocalFile returns a copy of the local file with the new path.
+// The file name is parsed again but the match and metadata are kept.
+func relocateLocalFile(lf *anime.LocalFile, newPath string, libraryPath string) *anime.LocalFile {
+	ret := anime.NewLocalFile(newPath, libraryPath)
+	ret.MediaId = lf.MediaId
+	ret.Metadata = lf.Metadata
+	ret.Locked = lf.Locked
+	ret.Ignored = lf.Ignored
+	return ret
+}
+
+func (o *Organizer) updatePlaylists(renamed map[string]string) {
+	playlists, err := db_bridge.GetPlaylists(o.Database)
+	if err != nil {
+		return
+	}
+
+	for _, playlist := range playlists {
+		updated := false
+		// Items can share their local file with the LocalFiles list, so each file is only renamed once
+		seen := make(map[*anime.LocalFile]struct{})
+		rename := func(lf *anime.LocalFile) {
+			if lf == nil {
+				return
+			}
+			if _, ok := seen[lf]; ok {
+				return
+			}
+			seen[lf] = struct{}{}
+			if newPath, found := renamed[lf.GetNormalizedPath()]; found {
+				lf.Path = newPath
+				lf.Name = filepath.Base(newPath)
+				updated = true
+			}
+		}
+
+		for _, lf := range playlist.LocalFiles {
+			rename(lf)
+		}
+		for _, item := range playlist.Items {
+			if item != nil {
+				rename(item.LocalFile)
+			}
+		}
+		if updated {
+			if err := db_bridge.UpdatePlaylist(o.Database, playlist); err != nil {
+				o.Logger.Warn().Err(err).Str("name", playlist.Name).Msg("organizer: Failed to update playlist")
+			}
+		}
+	}
+}
+
+// getSeedingPaths returns the content paths of the torrents in the torrent client.
+func (o *Organizer) getSeedingPaths() []string {
+	ret := make([]string, 0)
+	if o.TorrentClientRepository == nil {
+		return ret
+	}
+
+	torrents, err := o.TorrentClientRepository.GetList()
+	if err != nil {
+		o.Logger.Warn().Err(err).Msg("organizer: Could not get torrents, seeding files will not be detected")
+		return ret
+	}
+
+	for _, t := range torrents {
+		if t.ContentPath != "" {
+			ret = append(ret, util.NormalizePath(t.ContentPath))
+		}
+	}
+	return ret
+}
+
+// isSeeding returns true if the file is the content of a torrent or is inside the content directory of a torrent.
+func isSeeding(path string, seedingPaths []string) bool {
+	path = util.NormalizePath(path)
+	for _, p := range seedingPaths {
+		if path == p || strings.HasPrefix(path, strings.TrimSuffix(p, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// getLibraryPathOf returns the library path that is or contains the path.
+func (o *Organizer) getLibraryPathOf(path string) (string, bool) {
+	normalizedPath := strings.TrimSuffix(util.NormalizePath(path), "/")
+	for _, libraryPath := range o.LibraryPaths {
+		if libraryPath == "" {
+			continue
+		}
+		normalizedLibraryPath := strings.TrimSuffix(util.NormalizePath(libraryPath), "/")
+		if normalizedPath == normalizedLibraryPath || strings.HasPrefix(normalizedPath, normalizedLibraryPath+"/") {
+			return libraryPath, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,251 @@
+package organizer
+
+import (
+	"os"
+	"path/filepath"
+	"seanime/internal/api/anilist"
+	"seanime/internal/database/db"
+	"seanime/internal/database/db_bridge"
+	"seanime/internal/library/anime"
+	"seanime/internal/util"
+	"strconv"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	values := &TemplateValues{
+		Title:   "Re:Zero - Starting Life in Another World",
+		Romaji:  "Re:Zero kara Hajimeru Isekai Seikatsu",
+		Year:    2016,
+		Season:  1,
+		Episode: "5",
+		Group:   "SubsPlease",
+		MediaId: 21355,
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		values   *TemplateValues
+		expected string
+	}{
+		{
+			name:     "Default template",
+			template: DefaultTemplate,
+			values:   values,
+			expected: filepath.Join("Re-Zero kara Hajimeru Isekai Seikatsu (2016)", "Re-Zero - Starting Life in Another World - 05 [SubsPlease]"),
+		},
+		{
+			name:     "Season folder",
+			template: "{romaji} ({year})/Season {season:02}/{english} - {episode:03}",
+			values:   values,
+			expected: filepath.Join("Re-Zero kara Hajimeru Isekai Seikatsu (2016)", "Season 01", "Re-Zero kara Hajimeru Isekai Seikatsu - 005"),
+		},
+		{
+			name:     "Missing values",
+			template: "{title} ({year})/{title} - {episode:02} [{group}] ({resolution})",
+			values: &TemplateValues{
+				Title:   "Frieren",
+				Episode: "S1",
+			},
+			expected: filepath.Join("Frieren", "Frieren - S01"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, ValidateTemplate(tt.template))
+			require.Equal(t, tt.expected, RenderTemplate(tt.template, tt.values))
+		})
+	}
+
+	require.Error(t, ValidateTemplate(""))
+	require.Error(t, ValidateTemplate("{title}/{title}"))
+	require.Error(t, ValidateTemplate("{title} - {episode} {unknown}"))
+}
+
+func TestBuildPlan(t *testing.T) {
+	libraryDir := t.TempDir()
+	downloadDir := t.TempDir()
+
+	seeding := filepath.Join(downloadDir, "[SubsPlease] Frieren - 02 (1080p).mkv")
+	existing := filepath.Join(libraryDir, "Sousou no Frieren (2023)", "Frieren - 03 [SubsPlease].mkv")
+	for _, p := range []string{seeding, existing} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		require.NoError(t, os.WriteFile(p, []byte("data"), 0644))
+	}
+
+	lfs := []*anime.LocalFile{
+		newTestLocalFile(filepath.Join(downloadDir, "[SubsPlease] Frieren - 01 (1080p).mkv"), downloadDir, 154587, 1, anime.LocalFileTypeMain),
+		newTestLocalFile(seeding, downloadDir, 154587, 2, anime.LocalFileTypeMain),
+		newTestLocalFile(filepath.Join(downloadDir, "[SubsPlease] Frieren - 01v2 (1080p).mkv"), downloadDir, 154587, 1, anime.LocalFileTypeMain),
+		newTestLocalFile(existing, libraryDir, 154587, 3, anime.LocalFileTypeMain),
+		newTestLocalFile(filepath.Join(downloadDir, "[SubsPlease] Frieren - NCOP (1080p).mkv"), downloadDir, 154587, 0, anime.LocalFileTypeNC),
+		newTestLocalFile(filepath.Join(downloadDir, "[SubsPlease] Unknown - 01 (1080p).mkv"), downloadDir, 1, 1, anime.LocalFileTypeMain),
+		newTestLocalFile(filepath.Join(downloadDir, "Unmatched.mkv"), downloadDir, 0, 0, anime.LocalFileTypeMain),
+	}
+
+	o := &Organizer{
+		Logger:          util.NewLogger(),
+		AnimeCollection: newTestAnimeCollection(154587, "Sousou no Frieren", "Frieren: Beyond Journey's End", 2023),
+		LibraryPaths:    []string{libraryDir},
+	}
+
+	plan, err := o.buildPlan(lfs, &Options{
+		Template: "{romaji} ({year})/Frieren - {episode:02} [{group}]",
+		Mode:     ModeMove,
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Operations, 6)
+
+	expectedDir := filepath.Join(libraryDir, "Sousou no Frieren (2023)")
+
+	require.Equal(t, OperationStatusPending, plan.Operations[0].Status)
+	require.Equal(t, filepath.Join(expectedDir, "Frieren - 01 [SubsPlease].mkv"), plan.Operations[0].Destination)
+
+	require.Equal(t, OperationStatusPending, plan.Operations[1].Status)
+	require.Equal(t, filepath.Join(expectedDir, "Frieren - 02 [SubsPlease].mkv"), plan.Operations[1].Destination)
+
+	require.Equal(t, OperationStatusSkipped, plan.Operations[2].Status)
+	require.Equal(t, "another file has the same destination", plan.Operations[2].Reason)
+
+	require.Equal(t, OperationStatusSkipped, plan.Operations[3].Status)
+	require.Equal(t, "already organized", plan.Operations[3].Reason)
+
+	require.Equal(t, OperationStatusSkipped, plan.Operations[4].Status)
+	require.Equal(t, OperationStatusSkipped, plan.Operations[5].Status)
+
+	require.Equal(t, 4, plan.Skipped)
+
+	// Files inside the content path of a torrent are seeding
+	require.True(t, isSeeding(seeding, []string{util.NormalizePath(downloadDir)}))
+	require.False(t, isSeeding(existing, []string{util.NormalizePath(downloadDir)}))
+}
+
+func TestOrganize(t *testing.T) {
+	libraryDir := t.TempDir()
+	downloadDir := filepath.Join(libraryDir, "downloads")
+
+	moved := filepath.Join(downloadDir, "[SubsPlease] Frieren - 01 (1080p).mkv")
+	copied := filepath.Join(downloadDir, "[SubsPlease] Frieren - 02 (1080p).mkv")
+	for _, p := range []string{moved, copied} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		require.NoError(t, os.WriteFile(p, []byte("data"), 0644))
+	}
+
+	database, err := db.NewInMemoryDatabase(util.NewLogger())
+	require.NoError(t, err)
+	_, err = db_bridge.InsertLocalFiles(database, []*anime.LocalFile{
+		newTestLocalFile(moved, libraryDir, 154587, 1, anime.LocalFileTypeMain),
+	})
+	require.NoError(t, err)
+
+	o := &Organizer{
+		Logger:          util.NewLogger(),
+		Database:        database,
+		AnimeCollection: newTestAnimeCollection(154587, "Sousou no Frieren", "Frieren: Beyond Journey's End", 2023),
+		LibraryPaths:    []string{libraryDir},
+	}
+	template := "{romaji}/Frieren - {episode:02}"
+
+	// The destination must be inside a library path
+	_, err = o.Organize(&Options{Template: template, Destination: t.TempDir()})
+	require.Error(t, err)
+
+	// Moved files are updated in place
+	plan, err := o.Organize(&Options{Template: template, Mode: ModeMove})
+	require.NoError(t, err)
+	require.Equal(t, 1, plan.Done)
+
+	lfs, lfsId, err := db_bridge.GetLocalFiles(database)
+	require.NoError(t, err)
+	require.Len(t, lfs, 1)
+	require.Equal(t, filepath.Join(libraryDir, "Sousou no Frieren", "Frieren - 01.mkv"), lfs[0].GetPath())
+
+	// The source of a copied file is kept and ignored
+	lfs = append(lfs, newTestLocalFile(copied, libraryDir, 154587, 2, anime.LocalFileTypeMain))
+	_, err = db_bridge.SaveLocalFiles(database, lfsId, lfs)
+	require.NoError(t, err)
+
+	plan, err = o.Organize(&Options{Template: template, Mode: ModeCopy})
+	require.NoError(t, err)
+	require.Equal(t, 1, plan.Done)
+	require.FileExists(t, copied)
+
+	lfs, _, err = db_bridge.GetLocalFiles(database)
+	require.NoError(t, err)
+	require.Len(t, lfs, 3)
+	source, found := lo.Find(lfs, func(lf *anime.LocalFile) bool { return lf.GetPath() == copied })
+	require.True(t, found)
+	require.True(t, source.IsIgnored())
+	destination, found := lo.Find(lfs, func(lf *anime.LocalFile) bool {
+		return lf.GetPath() == filepath.Join(libraryDir, "Sousou no Frieren", "Frieren - 02.mkv")
+	})
+	require.True(t, found)
+	require.False(t, destination.IsIgnored())
+	require.Equal(t, 154587, destination.MediaId)
+}
+
+func TestFileOperations(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "downloads", "file.mkv")
+	require.NoError(t, os.MkdirAll(filepath.Dir(src), 0755))
+	require.NoError(t, os.WriteFile(src, []byte("data"), 0644))
+
+	copied := filepath.Join(dir, "library", "copy", "file.mkv")
+	require.NoError(t, copyFile(src, copied))
+	require.FileExists(t, copied)
+	require.NoFileExists(t, copied+".part")
+
+	linked := filepath.Join(dir, "library", "link", "file.mkv")
+	require.NoError(t, linkFile(src, linked))
+	require.FileExists(t, linked)
+
+	moved := filepath.Join(dir, "library", "move", "file.mkv")
+	require.NoError(t, moveFile(src, moved))
+	require.FileExists(t, moved)
+	require.NoFileExists(t, src)
+
+	removeEmptyParents(src, dir)
+	require.NoDirExists(t, filepath.Join(dir, "downloads"))
+	require.DirExists(t, dir)
+}
+
+func newTestLocalFile(path string, dir string, mediaId int, episode int, fileType anime.LocalFileType) *anime.LocalFile {
+	lf := anime.NewLocalFile(path, dir)
+	lf.MediaId = mediaId
+	lf.Metadata = &anime.LocalFileMetadata{
+		Episode:      episode,
+		AniDBEpisode: lo.Ternary(episode > 0, strconv.Itoa(episode), ""),
+		Type:         fileType,
+	}
+	return lf
+}
+
+func newTestAnimeCollection(mediaId int, romaji string, english string, year int) *anilist.AnimeCollection {
+	return &anilist.AnimeCollection{
+		MediaListCollection: &anilist.AnimeCollection_MediaListCollection{
+			Lists: []*anilist.AnimeCollection_MediaListCollection_Lists{
+				{
+					Entries: []*anilist.AnimeCollection_MediaListCollection_Lists_Entries{
+						{
+							Media: &anilist.BaseAnime{
+								ID: mediaId,
+								Title: &anilist.BaseAnime_Title{
+									Romaji:  &romaji,
+									English: &english,
+								},
+								StartDate: &anilist.BaseAnime_StartDate{
+									Year: &year,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
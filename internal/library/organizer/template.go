@@ -0,0 +1,158 @@
+package organizer
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultTemplate is used when no template is provided.
+const DefaultTemplate = "{romaji} ({year})/{title} - {episode:02} [{group}]"
+
+var (
+	templateTokenRegex = regexp.MustCompile(`\{(\w+)(?::(\d+))?}`)
+	emptyBracketsRegex = regexp.MustCompile(`\(\s*\)|\[\s*]|\{\s*}`)
+	whitespaceRegex    = regexp.MustCompile(`\s+`)
+
+	// Characters that are not allowed in file names on at least one platform.
+	invalidCharsReplacer = strings.NewReplacer(
+		": ", " - ",
+		":", "-",
+		"/", "-",
+		"\\", "-",
+		"|", "-",
+		"\"", "'",
+		"<", "",
+		">", "",
+		"?", "",
+		"*", "",
+	)
+
+	templateTokens = map[string]struct{}{
+		"title":        {}, // User preferred title
+		"romaji":       {},
+		"english":      {}, // Falls back to the romaji title
+		"year":         {},
+		"season":       {},
+		"episode":      {},
+		"episodeTitle": {},
+		"group":        {},
+		"resolution":   {},
+		"mediaId":      {},
+	}
+)
+
+// TemplateValues holds the values used to render a template.
+type TemplateValues struct {
+	Title        string
+	Romaji       string
+	English      string
+	Year         int
+	Season       int
+	Episode      string // e.g. "1", "S1"
+	EpisodeTitle string
+	Group        string
+	Resolution   string
+	MediaId      int
+}
+
+// ValidateTemplate returns an error if the template is empty or contains unknown tokens.
+func ValidateTemplate(template string) error {
+	if strings.TrimSpace(template) == "" {
+		return errors.New("template is empty")
+	}
+	if !strings.Contains(template, "{episode") {
+		return errors.New("template must contain the {episode} token")
+	}
+	for _, match := range templateTokenRegex.FindAllStringSubmatch(template, -1) {
+		if _, ok := templateTokens[match[1]]; !ok {
+			return fmt.Errorf("unknown token {%s}", match[1])
+		}
+	}
+	return nil
+}
+
+// RenderTemplate returns the relative path built from the template, without the extension.
+// Each path segment is sanitized and empty brackets left by missing values are removed.
+func RenderTemplate(template string, values *TemplateValues) string {
+	template = strings.ReplaceAll(template, "\\", "/")
+
+	segments := make([]string, 0)
+	for _, segment := range strings.Split(template, "/") {
+		rendered := templateTokenRegex.ReplaceAllStringFunc(segment, func(token string) string {
+			match := templateTokenRegex.FindStringSubmatch(token)
+			padding, _ := strconv.Atoi(match[2])
+			return invalidCharsReplacer.Replace(values.get(match[1], padding))
+		})
+		rendered = cleanSegment(rendered)
+		if rendered == "" {
+			continue
+		}
+		segments = append(segments, rendered)
+	}
+
+	return filepath.Join(segments...)
+}
+
+func (v *TemplateValues) get(token string, padding int) string {
+	switch token {
+	case "title":
+		return v.Title
+	case "romaji":
+		return v.Romaji
+	case "english":
+		if v.English == "" {
+			return v.Romaji
+		}
+		return v.English
+	case "year":
+		if v.Year == 0 {
+			return ""
+		}
+		return strconv.Itoa(v.Year)
+	case "season":
+		return padNumber(strconv.Itoa(v.Season), padding)
+	case "episode":
+		return padNumber(v.Episode, padding)
+	case "episodeTitle":
+		return v.EpisodeTitle
+	case "group":
+		return v.Group
+	case "resolution":
+		return v.Resolution
+	case "mediaId":
+		return strconv.Itoa(v.MediaId)
+	}
+	return ""
+}
+
+// padNumber pads the numeric part of the value with zeros.
+// e.g. "1" -> "01", "S1" -> "S01"
+func padNumber(value string, padding int) string {
+	if padding <= 0 {
+		return value
+	}
+	prefix := strings.TrimRightFunc(value, func(r rune) bool { return r >= '0' && r <= '9' })
+	number := strings.TrimPrefix(value, prefix)
+	if number == "" {
+		return value
+	}
+	for len(number) < padding {
+		number = "0" + number
+	}
+	return prefix + number
+}
+
+func cleanSegment(segment string) string {
+	// Remove brackets left empty by missing values, e.g. "Title - 01 []"
+	for emptyBracketsRegex.MatchString(segment) {
+		segment = emptyBracketsRegex.ReplaceAllString(segment, "")
+	}
+	segment = whitespaceRegex.ReplaceAllString(segment, " ")
+	// Remove dangling separators, e.g. "Title - " and trailing dots which are not allowed on Windows
+	segment = strings.TrimRight(strings.Trim(segment, " -_"), ". ")
+	return segment
+}
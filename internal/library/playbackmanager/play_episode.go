@@ -0,0 +1,57 @@
+package playbackmanager
+
+import (
+	"errors"
+	"fmt"
+	"seanime/internal/database/db_bridge"
+	"seanime/internal/library/anime"
+	"seanime/internal/util"
+
+	"github.com/samber/lo"
+)
+
+type StartPlayingEpisodeOptions struct {
+	MediaId       int
+	EpisodeNumber int
+	Path          string // optional - path of the version to play
+	UserAgent     string
+	ClientId      string
+}
+
+// StartPlayingEpisode plays a main episode using the media player.
+// If a path is given, this version of the episode is played, otherwise the preferred version is played.
+func (pm *PlaybackManager) StartPlayingEpisode(opts *StartPlayingEpisodeOptions) error {
+	lfs, _, err := db_bridge.GetLocalFiles(pm.Database)
+	if err != nil {
+		return fmt.Errorf("error getting local files: %s", err.Error())
+	}
+
+	lfw := anime.NewLocalFileWrapper(lfs)
+	lfw.SetVersionPreferences(pm.settings.VersionPreferences)
+
+	lfe, ok := lfw.GetLocalEntryById(opts.MediaId)
+	if !ok {
+		return errors.New("no local files found")
+	}
+
+	var lf *anime.LocalFile
+	if opts.Path != "" {
+		lf, ok = lo.Find(lfe.FindLocalFileVersions(opts.EpisodeNumber), func(l *anime.LocalFile) bool {
+			return l.GetNormalizedPath() == util.NormalizePath(opts.Path)
+		})
+		if !ok {
+			return errors.New("version not found")
+		}
+	} else {
+		lf, ok = lfe.FindLocalFileWithEpisodeNumber(opts.EpisodeNumber)
+		if !ok {
+			return fmt.Errorf("episode %d not found", opts.EpisodeNumber)
+		}
+	}
+
+	return pm.StartPlayingUsingMediaPlayer(&StartPlayingOptions{
+		Payload:   lf.GetPath(),
+		UserAgent: opts.UserAgent,
+		ClientId:  opts.ClientId,
+	})
+}
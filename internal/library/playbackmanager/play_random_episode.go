@@ -37,6 +37,7 @@ func (pm *PlaybackManager) StartRandomVideo(opts *StartRandomVideoOptions) error
 
 	// Create a local file wrapper
 	lfw := anime.NewLocalFileWrapper(lfs)
+	lfw.SetVersionPreferences(pm.settings.VersionPreferences)
 	// Get entries (grouped by media id)
 	lfEntries := lfw.GetLocalEntries()
 	lfEntries = lo.Filter(lfEntries, func(e *anime.LocalFileWrapperEntry, _ int) bool {
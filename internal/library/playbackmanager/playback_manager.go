@@ -88,6 +88,8 @@ type (
 		currentStreamEpisode mo.Option[*anime.Episode]
 		// The current media being streamed, set in [StartStreamingUsingMediaPlayer]
 		currentStreamMedia mo.Option[*anilist.BaseAnime]
+		// The kind of stream, set in [StartStreamingUsingMediaPlayer]
+		currentStreamKind continuity.Kind
 
 		// \/ Manual progress tracking (non-integrated external player)
 		manualTrackingCtx           context.Context
@@ -103,6 +105,9 @@ type (
 		animeCollection mo.Option[*anilist.AnimeCollection]
 
 		playbackStatusSubscribers *result.Map[string, *PlaybackStatusSubscriber]
+
+		// Functions used to stream playlist items, by source
+		playlistStreamStarters *result.Map[anime.PlaylistItemSource, PlaylistStreamStarter]
 	}
 
 	PlaybackStatusSubscriber struct {
@@ -121,6 +126,10 @@ type (
 
 	PlaybackStateType string
 
+	// PlaylistStreamStarter starts streaming a playlist item, see [PlaybackManager.SetPlaylistStreamStarter].
+	// It should return an error if the item cannot be streamed so that the next source is tried.
+	PlaylistStreamStarter func(item *anime.PlaylistItem) error
+
 	// PlaybackState is used to keep track of the user's current video playback
 	// It is sent to the client each time the video playback state is picked up -- this is used to update the client's UI
 	PlaybackState struct {
@@ -148,6 +157,7 @@ type (
 
 	Settings struct {
 		AutoPlayNextEpisode bool
+		VersionPreferences  *anime.VersionPreferences // Used to pick between multiple versions of an episode
 	}
 )
 
@@ -176,6 +186,7 @@ func New(opts *NewPlaybackManagerOptions) *PlaybackManager {
 		currentMediaListEntry:          mo.None[*anilist.AnimeListEntry](),
 		continuityManager:              opts.ContinuityManager,
 		playbackStatusSubscribers:      result.NewResultMap[string, *PlaybackStatusSubscriber](),
+		playlistStreamStarters:         result.NewResultMap[anime.PlaylistItemSource, PlaylistStreamStarter](),
 	}
 
 	pm.playlistHub = newPlaylistHub(pm)
@@ -241,6 +252,8 @@ type StartPlayingOptions struct {
 	Payload   string // url or path
 	UserAgent string
 	ClientId  string
+	// Kind of stream used in the watch log, e.g. continuity.TorrentstreamKind
+	StreamKind continuity.Kind
 }
 
 func (pm *PlaybackManager) StartPlayingUsingMediaPlayer(opts *StartPlayingOptions) error {
@@ -315,7 +328,10 @@ func (pm *PlaybackManager) StartStreamingUsingMediaPlayer(windowTitle string, op
 		return nil
 	}
 
-	pm.playlistHub.reset()
+	// Streams started by the playlist do not cancel it
+	if !pm.playlistHub.consumePendingStream() {
+		pm.playlistHub.reset()
+	}
 	if pm.isOffline {
 		return errors.New("cannot stream when offline")
 	}
@@ -336,6 +352,7 @@ func (pm *PlaybackManager) StartStreamingUsingMediaPlayer(windowTitle string, op
 	}
 
 	pm.currentStreamMedia = mo.Some(media)
+	pm.currentStreamKind = opts.StreamKind
 
 	episodeNumber := 0
 
@@ -502,24 +519,35 @@ func (pm *PlaybackManager) RequestNextPlaylistFile() error {
 	return nil
 }
 
+// SetPlaylistStreamStarter sets the function used to stream playlist items from the given source.
+// The function should send the stream to the media player using StartStreamingUsingMediaPlayer.
+func (pm *PlaybackManager) SetPlaylistStreamStarter(source anime.PlaylistItemSource, starter PlaylistStreamStarter) {
+	pm.playlistStreamStarters.Set(source, starter)
+}
+
 // StartPlaylist starts a playlist.
 // This action is triggered by the client.
 func (pm *PlaybackManager) StartPlaylist(playlist *anime.Playlist) (err error) {
 	defer util.HandlePanicInModuleWithError("library/playbackmanager/StartPlaylist", &err)
 
+	if len(playlist.GetItems()) == 0 {
+		return errors.New("playlist is empty")
+	}
+
 	pm.playlistHub.loadPlaylist(playlist)
 
 	_ = pm.checkOrLoadAnimeCollection()
 
-	// Play the first video in the playlist
-	firstVidPath := playlist.LocalFiles[0].Path
-	err = pm.MediaPlayerRepository.Play(firstVidPath)
-	if err != nil {
-		return err
+	// Use the episodes that were added to the library since the playlist was created
+	if lfs, _, err := db_bridge.GetLocalFiles(pm.Database); err == nil {
+		playlist.ResolveLocalFiles(lfs)
 	}
 
-	// Start tracking the video
-	pm.MediaPlayerRepository.StartTracking()
+	// Play the first video in the playlist
+	if !pm.playPlaylistItemOrNext(playlist.GetItems()[0]) {
+		pm.playlistHub.reset()
+		return errors.New("could not play any episode in the playlist")
+	}
 
 	// Create a new context for the playlist hub
 	var ctx context.Context
@@ -536,22 +564,19 @@ func (pm *PlaybackManager) StartPlaylist(playlist *anime.Playlist) (err error) {
 				// Send event to the client -- nil signals that no playlist is being played
 				pm.wsEventManager.SendEvent(events.PlaybackManagerPlaylistState, nil)
 				return
-			case path := <-pm.playlistHub.requestNewFileCh:
-				// requestNewFileCh receives the path of the next video to play
+			case item := <-pm.playlistHub.requestNewItemCh:
+				// requestNewItemCh receives the next item to play
 				// The channel is fed when it's time to play the next video or when the client requests the next video
 				// see: RequestNextPlaylistFile, playlistHub code
-				pm.Logger.Debug().Str("path", path).Msg("playback manager: Playing next file")
+				pm.Logger.Debug().Int("mediaId", item.MediaId).Int("episode", item.EpisodeNumber).Msg("playback manager: Playing next item")
 				// Send notification to the client
 				pm.wsEventManager.SendEvent(events.InfoToast, "Playing next file in playlist")
 				// Play the requested video
-				err := pm.MediaPlayerRepository.Play(path)
-				if err != nil {
-					pm.Logger.Error().Err(err).Msg("playback manager: Failed to play next file in playlist")
+				if !pm.playPlaylistItemOrNext(item) {
+					pm.wsEventManager.SendEvent(events.InfoToast, "End of playlist")
 					pm.playlistHub.cancel()
 					return
 				}
-				// Start tracking the video
-				pm.MediaPlayerRepository.StartTracking()
 			case <-pm.playlistHub.endOfPlaylistCh:
 				pm.Logger.Debug().Msg("playback manager: End of playlist")
 				pm.wsEventManager.SendEvent(events.InfoToast, "End of playlist")
@@ -560,7 +585,6 @@ func (pm *PlaybackManager) StartPlaylist(playlist *anime.Playlist) (err error) {
 				go pm.MediaPlayerRepository.Stop()
 				pm.playlistHub.cancel()
 				return
-			default:
 			}
 		}
 	}()
@@ -578,6 +602,67 @@ func (pm *PlaybackManager) StartPlaylist(playlist *anime.Playlist) (err error) {
 	return nil
 }
 
+// playPlaylistItemOrNext plays the item, or the following items if it cannot be played.
+// It returns false if no item could be played.
+func (pm *PlaybackManager) playPlaylistItemOrNext(item *anime.PlaylistItem) bool {
+	for item != nil {
+		err := pm.playPlaylistItem(item)
+		if err == nil {
+			return true
+		}
+		pm.Logger.Error().Err(err).Msg("playback manager: Failed to play playlist item, skipping")
+		pm.wsEventManager.SendEvent(events.ErrorToast, fmt.Sprintf("Could not play episode %d, skipping", item.EpisodeNumber))
+
+		item, _ = pm.playlistHub.findItemAfter(item)
+	}
+	return false
+}
+
+// playPlaylistItem plays the item from the first source that is available.
+func (pm *PlaybackManager) playPlaylistItem(item *anime.PlaylistItem) error {
+	var lastErr error
+
+	for _, source := range item.GetSources() {
+		switch source {
+		case anime.PlaylistItemSourceLocalFile:
+			if item.LocalFile == nil {
+				continue
+			}
+			pm.playlistHub.setRequestedItem(item, false)
+			if err := pm.MediaPlayerRepository.Play(item.LocalFile.GetPath()); err != nil {
+				lastErr = err
+				continue
+			}
+			// Start tracking the video
+			pm.MediaPlayerRepository.StartTracking()
+		default:
+			if pm.isOffline {
+				continue
+			}
+			starter, ok := pm.playlistStreamStarters.Get(source)
+			if !ok {
+				continue
+			}
+			pm.playlistHub.setRequestedItem(item, true)
+			if err := starter(item); err != nil {
+				pm.playlistHub.setRequestedItem(item, false)
+				pm.Logger.Warn().Err(err).Str("source", string(source)).Int("mediaId", item.MediaId).Int("episode", item.EpisodeNumber).Msg("playback manager: Could not stream playlist item")
+				lastErr = err
+				continue
+			}
+		}
+
+		pm.Logger.Debug().Str("source", string(source)).Int("mediaId", item.MediaId).Int("episode", item.EpisodeNumber).Msg("playback manager: Playing playlist item")
+		item.Source = source
+		return nil
+	}
+
+	if lastErr == nil {
+		lastErr = errors.New("no source available")
+	}
+	return fmt.Errorf("episode %d of %d: %w", item.EpisodeNumber, item.MediaId, lastErr)
+}
+
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
 func (pm *PlaybackManager) checkOrLoadAnimeCollection() (err error) {
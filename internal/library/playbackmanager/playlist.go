@@ -3,28 +3,31 @@ package playbackmanager
 import (
 	"context"
 	"fmt"
-	"github.com/rs/zerolog"
 	"seanime/internal/api/anilist"
 	"seanime/internal/events"
 	"seanime/internal/library/anime"
+	"seanime/internal/util"
 	"sync"
+
+	"github.com/rs/zerolog"
 )
 
 type (
 	playlistHub struct {
-		requestNewFileCh chan string
+		requestNewItemCh chan *anime.PlaylistItem
 		endOfPlaylistCh  chan struct{}
 
 		wsEventManager  events.WSEventManagerInterface
 		logger          *zerolog.Logger
-		currentPlaylist *anime.Playlist    // The current playlist that is being played (can be nil)
-		nextLocalFile   *anime.LocalFile   // The next episode that will be played (can be nil)
-		cancel          context.CancelFunc // The cancel function for the current playlist
-		mu              sync.Mutex         // The mutex
+		currentPlaylist *anime.Playlist     // The current playlist that is being played (can be nil)
+		nextItem        *anime.PlaylistItem // The next episode that will be played (can be nil)
+		cancel          context.CancelFunc  // The cancel function for the current playlist
+		mu              sync.Mutex          // The mutex
 
-		playingLf             *anime.LocalFile        // The currently playing local file
-		playingMediaListEntry *anilist.AnimeListEntry // The currently playing media entry
-		completedCurrent      bool                    // Whether the current episode has been completed
+		playingItem      *anime.PlaylistItem // The currently playing item
+		requestedItem    *anime.PlaylistItem // The item that was last sent to the media player
+		pendingStream    bool                // Whether a stream is being started for requestedItem
+		completedCurrent bool                // Whether the current episode has been completed
 
 		currentState *PlaylistState // This is sent to the client to show the current playlist state
 
@@ -32,14 +35,16 @@ type (
 	}
 
 	PlaylistState struct {
-		Current   *PlaylistStateItem `json:"current"`
-		Next      *PlaylistStateItem `json:"next"`
-		Remaining int                `json:"remaining"`
+		Current   *PlaylistStateItem    `json:"current"`
+		Next      *PlaylistStateItem    `json:"next"`
+		Remaining int                   `json:"remaining"`
+		Items     []*anime.PlaylistItem `json:"items"` // Items of the playlist with their progress
 	}
 
 	PlaylistStateItem struct {
-		Name       string `json:"name"`
-		MediaImage string `json:"mediaImage"`
+		Name       string                   `json:"name"`
+		MediaImage string                   `json:"mediaImage"`
+		Source     anime.PlaylistItemSource `json:"source,omitempty"`
 	}
 )
 
@@ -48,7 +53,7 @@ func newPlaylistHub(pm *PlaybackManager) *playlistHub {
 		logger:           pm.Logger,
 		wsEventManager:   pm.wsEventManager,
 		playbackManager:  pm,
-		requestNewFileCh: make(chan string, 1),
+		requestNewItemCh: make(chan *anime.PlaylistItem, 1),
 		endOfPlaylistCh:  make(chan struct{}, 1),
 	}
 }
@@ -59,7 +64,9 @@ func (h *playlistHub) loadPlaylist(playlist *anime.Playlist) {
 		return
 	}
 	h.reset()
+	h.mu.Lock()
 	h.currentPlaylist = playlist
+	h.mu.Unlock()
 	h.logger.Debug().Str("name", playlist.Name).Msg("playlist hub: Playlist loaded")
 	return
 }
@@ -68,33 +75,80 @@ func (h *playlistHub) reset() {
 	if h.cancel != nil {
 		h.cancel()
 	}
+	h.mu.Lock()
 	h.currentPlaylist = nil
-	h.playingLf = nil
-	h.playingMediaListEntry = nil
+	h.playingItem = nil
+	h.requestedItem = nil
+	h.nextItem = nil
+	h.pendingStream = false
 	h.currentState = nil
-	h.wsEventManager.SendEvent(events.PlaybackManagerPlaylistState, h.currentState)
+	h.mu.Unlock()
+	h.wsEventManager.SendEvent(events.PlaybackManagerPlaylistState, nil)
 	return
 }
 
-func (h *playlistHub) check(currListEntry *anilist.AnimeListEntry, currLf *anime.LocalFile, ps PlaybackState) bool {
-	if h.currentPlaylist == nil || currLf == nil || currListEntry == nil {
-		h.currentPlaylist = nil
-		h.playingLf = nil
-		h.playingMediaListEntry = nil
-		return false
+// setRequestedItem is called before the item is sent to the media player.
+// isStream should be true if the item is played by a PlaylistStreamStarter.
+func (h *playlistHub) setRequestedItem(item *anime.PlaylistItem, isStream bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.requestedItem = item
+	h.pendingStream = isStream
+}
+
+// consumePendingStream returns true if a stream was requested by the playlist.
+// It is called when a stream is sent to the media player, streams that are not part of the playlist cancel it.
+func (h *playlistHub) consumePendingStream() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ret := h.pendingStream
+	h.pendingStream = false
+	return ret
+}
+
+// findItem returns the index of the item matching the video, starting from the requested item.
+// path is only set for local files.
+func (h *playlistHub) findItem(mediaId int, episodeNumber int, path string) (int, bool) {
+	items := h.currentPlaylist.GetItems()
+
+	start := 0
+	for i, item := range items {
+		if item == h.requestedItem {
+			start = i
+			break
+		}
+	}
+
+	for i := start; i < len(items); i++ {
+		item := items[i]
+		if path != "" && item.LocalFile != nil {
+			if item.LocalFile.GetNormalizedPath() == util.NormalizePath(path) {
+				return i, true
+			}
+			continue
+		}
+		if item.MediaId == mediaId && item.EpisodeNumber == episodeNumber {
+			return i, true
+		}
 	}
-	return true
+
+	return 0, false
 }
 
-func (h *playlistHub) findNextFile() (*anime.LocalFile, bool) {
-	if h.currentPlaylist == nil || h.playingLf == nil {
+// findItemAfter returns the item following the given item.
+func (h *playlistHub) findItemAfter(item *anime.PlaylistItem) (*anime.PlaylistItem, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.currentPlaylist == nil || item == nil {
 		return nil, false
 	}
 
-	for i, lf := range h.currentPlaylist.LocalFiles {
-		if lf.GetNormalizedPath() == h.playingLf.GetNormalizedPath() {
-			if i+1 < len(h.currentPlaylist.LocalFiles) {
-				return h.currentPlaylist.LocalFiles[i+1], true
+	items := h.currentPlaylist.GetItems()
+	for i, it := range items {
+		if it == item {
+			if i+1 < len(items) {
+				return items[i+1], true
 			}
 			break
 		}
@@ -104,97 +158,126 @@ func (h *playlistHub) findNextFile() (*anime.LocalFile, bool) {
 }
 
 func (h *playlistHub) playNextFile() (*anime.LocalFile, bool) {
-	if h.currentPlaylist == nil || h.playingLf == nil || h.nextLocalFile == nil {
+	h.mu.Lock()
+	if h.currentPlaylist == nil || h.playingItem == nil || h.nextItem == nil {
+		h.mu.Unlock()
 		return nil, false
 	}
-
-	h.logger.Debug().Str("path", h.nextLocalFile.Path).Str("cmd", "playNextFile").Msg("playlist hub: Requesting next file")
-	h.requestNewFileCh <- h.nextLocalFile.Path
+	next := h.nextItem
 	h.completedCurrent = false
+	h.mu.Unlock()
+
+	h.logger.Debug().Int("mediaId", next.MediaId).Int("episode", next.EpisodeNumber).Str("cmd", "playNextFile").Msg("playlist hub: Requesting next item")
+	h.requestNewItemCh <- next
 
 	return nil, false
 }
 
-func (h *playlistHub) onVideoStart(currListEntry *anilist.AnimeListEntry, currLf *anime.LocalFile, ps PlaybackState) {
-	if !h.check(currListEntry, currLf, ps) {
+// onVideoStart is called when a video starts playing.
+// path is the path of the local file, it is empty for streams.
+func (h *playlistHub) onVideoStart(media *anilist.BaseAnime, episodeNumber int, path string, ps PlaybackState) {
+	if h.currentPlaylist == nil || media == nil {
 		return
 	}
 
-	h.completedCurrent = false
-	h.playingLf = currLf
-	h.playingMediaListEntry = currListEntry
+	h.mu.Lock()
 
-	h.nextLocalFile, _ = h.findNextFile()
-
-	if h.playbackManager.animeCollection.IsAbsent() {
+	idx, found := h.findItem(media.GetID(), episodeNumber, path)
+	if !found {
+		// The video is not part of the playlist
+		h.mu.Unlock()
+		h.logger.Debug().Int("mediaId", media.GetID()).Int("episode", episodeNumber).Msg("playlist hub: Video is not part of the playlist")
+		h.reset()
 		return
 	}
 
+	items := h.currentPlaylist.GetItems()
+
+	h.completedCurrent = false
+	h.playingItem = items[idx]
+	h.nextItem = nil
+	if idx+1 < len(items) {
+		h.nextItem = items[idx+1]
+	}
+
 	// Refresh current playlist state
 	playlistState := &PlaylistState{}
 	playlistState.Current = &PlaylistStateItem{
-		Name:       fmt.Sprintf("%s - Episode %d", currListEntry.GetMedia().GetPreferredTitle(), currLf.GetEpisodeNumber()),
-		MediaImage: currListEntry.GetMedia().GetCoverImageSafe(),
-	}
-	if h.nextLocalFile != nil {
-		lfe, found := h.playbackManager.animeCollection.MustGet().GetListEntryFromAnimeId(h.nextLocalFile.MediaId)
-		if found {
-			playlistState.Next = &PlaylistStateItem{
-				Name:       fmt.Sprintf("%s - Episode %d", lfe.GetMedia().GetPreferredTitle(), h.nextLocalFile.GetEpisodeNumber()),
-				MediaImage: lfe.GetMedia().GetCoverImageSafe(),
-			}
-		}
+		Name:       fmt.Sprintf("%s - Episode %d", media.GetPreferredTitle(), episodeNumber),
+		MediaImage: media.GetCoverImageSafe(),
+		Source:     h.playingItem.Source,
 	}
-	remaining := 0
-	for i, lf := range h.currentPlaylist.LocalFiles {
-		if lf.GetNormalizedPath() == currLf.GetNormalizedPath() {
-			remaining = len(h.currentPlaylist.LocalFiles) - 1 - i
-			break
-		}
+	if h.nextItem != nil {
+		playlistState.Next = h.getStateItem(h.nextItem)
 	}
-	playlistState.Remaining = remaining
+	playlistState.Remaining = len(items) - 1 - idx
+	playlistState.Items = items
 	h.currentState = playlistState
 
-	h.logger.Debug().Str("path", currLf.Path).Msgf("playlist hub: Video started")
+	h.mu.Unlock()
+
+	h.logger.Debug().Int("mediaId", media.GetID()).Int("episode", episodeNumber).Msgf("playlist hub: Video started")
 
 	return
 }
 
-func (h *playlistHub) onVideoCompleted(currListEntry *anilist.AnimeListEntry, currLf *anime.LocalFile, ps PlaybackState) {
-	if !h.check(currListEntry, currLf, ps) {
+// getStateItem returns the state of an item that is not playing.
+func (h *playlistHub) getStateItem(item *anime.PlaylistItem) *PlaylistStateItem {
+	ret := &PlaylistStateItem{
+		Name: fmt.Sprintf("Episode %d", item.EpisodeNumber),
+	}
+	if h.playbackManager.animeCollection.IsAbsent() {
+		return ret
+	}
+	lfe, found := h.playbackManager.animeCollection.MustGet().GetListEntryFromAnimeId(item.MediaId)
+	if found {
+		ret.Name = fmt.Sprintf("%s - Episode %d", lfe.GetMedia().GetPreferredTitle(), item.EpisodeNumber)
+		ret.MediaImage = lfe.GetMedia().GetCoverImageSafe()
+	}
+	return ret
+}
+
+func (h *playlistHub) onVideoCompleted(ps PlaybackState) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.currentPlaylist == nil || h.playingItem == nil {
 		return
 	}
 
 	h.completedCurrent = true
+	h.playingItem.Completed = true
+	h.playingItem.CompletionPercentage = ps.CompletionPercentage
 
 	return
 }
 
-func (h *playlistHub) onPlaybackStatus(currListEntry *anilist.AnimeListEntry, currLf *anime.LocalFile, ps PlaybackState) {
-	if !h.check(currListEntry, currLf, ps) {
+func (h *playlistHub) onPlaybackStatus(ps PlaybackState) {
+	h.mu.Lock()
+	if h.currentPlaylist == nil || h.playingItem == nil {
+		h.mu.Unlock()
 		return
 	}
 
-	h.wsEventManager.SendEvent(events.PlaybackManagerPlaylistState, h.currentState)
+	h.playingItem.CompletionPercentage = ps.CompletionPercentage
+	state := h.currentState
+	h.mu.Unlock()
+
+	h.wsEventManager.SendEvent(events.PlaybackManagerPlaylistState, state)
 
 	return
 }
 
 func (h *playlistHub) onTrackingStopped() {
-	if h.currentPlaylist == nil || h.playingLf == nil { // Return if no playlist
+	h.mu.Lock()
+	if h.currentPlaylist == nil || h.playingItem == nil { // Return if no playlist
+		h.mu.Unlock()
 		return
 	}
+	completed := h.completedCurrent
+	h.mu.Unlock()
 
-	// When tracking has stopped, request next file
-	//if h.nextLocalFile != nil {
-	//	h.logger.Debug().Str("path", h.nextLocalFile.Path).Msg("playlist hub: Requesting next file")
-	//	h.requestNewFileCh <- h.nextLocalFile.Path
-	//} else {
-	//	h.logger.Debug().Msg("playlist hub: End of playlist")
-	//	h.endOfPlaylistCh <- struct{}{}
-	//}
-
-	if !h.completedCurrent {
+	if !completed {
 		h.reset()
 	}
 
@@ -202,21 +285,22 @@ func (h *playlistHub) onTrackingStopped() {
 }
 
 func (h *playlistHub) onTrackingError() {
-	if h.currentPlaylist == nil { // Return if no playlist
+	h.mu.Lock()
+	if h.currentPlaylist == nil || !h.completedCurrent { // Return if no playlist
+		h.mu.Unlock()
 		return
 	}
-
-	// When tracking has stopped, request next file
-	if h.completedCurrent {
-		if h.nextLocalFile != nil {
-			h.logger.Debug().Str("path", h.nextLocalFile.Path).Msg("playlist hub: Requesting next file")
-			h.requestNewFileCh <- h.nextLocalFile.Path
-			h.completedCurrent = false
-		} else {
-			h.logger.Debug().Msg("playlist hub: End of playlist")
-			h.endOfPlaylistCh <- struct{}{}
-			h.completedCurrent = false
-		}
+	next := h.nextItem
+	h.completedCurrent = false
+	h.mu.Unlock()
+
+	// When tracking has stopped, request next item
+	if next != nil {
+		h.logger.Debug().Int("mediaId", next.MediaId).Int("episode", next.EpisodeNumber).Msg("playlist hub: Requesting next item")
+		h.requestNewItemCh <- next
+	} else {
+		h.logger.Debug().Msg("playlist hub: End of playlist")
+		h.endOfPlaylistCh <- struct{}{}
 	}
 
 	return
@@ -84,7 +84,7 @@ func (pm *PlaybackManager) listenToMediaPlayerEvents(ctx context.Context) {
 				})
 
 				// ------- Playlist ------- //
-				go pm.playlistHub.onVideoStart(pm.currentMediaListEntry.MustGet(), pm.currentLocalFile.MustGet(), _ps)
+				go pm.playlistHub.onVideoStart(pm.currentMediaListEntry.MustGet().GetMedia(), pm.currentLocalFile.MustGet().GetEpisodeNumber(), pm.currentLocalFile.MustGet().GetPath(), _ps)
 
 				// ------- Discord ------- //
 				if pm.discordPresence != nil && !pm.isOffline {
@@ -130,9 +130,7 @@ func (pm *PlaybackManager) listenToMediaPlayerEvents(ctx context.Context) {
 				pm.historyMap[status.Filename] = _ps
 
 				// ------- Playlist ------- //
-				if pm.currentMediaListEntry.IsPresent() && pm.currentLocalFile.IsPresent() {
-					go pm.playlistHub.onVideoCompleted(pm.currentMediaListEntry.MustGet(), pm.currentLocalFile.MustGet(), _ps)
-				}
+				go pm.playlistHub.onVideoCompleted(_ps)
 
 				pm.eventMu.Unlock()
 			case reason := <-pm.mediaPlayerRepoSubscriber.TrackingStoppedCh: // Tracking has stopped completely
@@ -198,9 +196,7 @@ func (pm *PlaybackManager) listenToMediaPlayerEvents(ctx context.Context) {
 				pm.wsEventManager.SendEvent(events.PlaybackManagerProgressPlaybackState, _ps)
 
 				// ------- Playlist ------- //
-				if pm.currentMediaListEntry.IsPresent() && pm.currentLocalFile.IsPresent() {
-					go pm.playlistHub.onPlaybackStatus(pm.currentMediaListEntry.MustGet(), pm.currentLocalFile.MustGet(), _ps)
-				}
+				go pm.playlistHub.onPlaybackStatus(_ps)
 
 				// ------- Discord ------- //
 				if pm.discordPresence != nil && !pm.isOffline {
@@ -260,8 +256,12 @@ func (pm *PlaybackManager) listenToMediaPlayerEvents(ctx context.Context) {
 					EpisodeNumber: pm.currentStreamEpisode.MustGet().GetProgressNumber(),
 					MediaId:       pm.currentStreamMedia.MustGet().GetID(),
 					Filepath:      "",
+					Kind:          pm.currentStreamKind,
 				})
 
+				// ------- Playlist ------- //
+				go pm.playlistHub.onVideoStart(pm.currentStreamMedia.MustGet(), pm.currentStreamEpisode.MustGet().EpisodeNumber, "", _ps)
+
 				// ------- Discord ------- //
 				if pm.discordPresence != nil && !pm.isOffline {
 					go pm.discordPresence.SetAnimeActivity(&discordrpc_presence.AnimeActivity{
@@ -305,6 +305,9 @@ func (pm *PlaybackManager) listenToMediaPlayerEvents(ctx context.Context) {
 				// Send the playback state to the client
 				pm.wsEventManager.SendEvent(events.PlaybackManagerProgressPlaybackState, _ps)
 
+				// ------- Playlist ------- //
+				go pm.playlistHub.onPlaybackStatus(_ps)
+
 				// ------- Discord ------- //
 				if pm.discordPresence != nil && !pm.isOffline {
 					go pm.discordPresence.UpdateAnimeActivity(int(pm.currentMediaPlaybackStatus.CurrentTimeInSeconds), int(pm.currentMediaPlaybackStatus.DurationInSeconds), !pm.currentMediaPlaybackStatus.Playing)
@@ -345,6 +348,9 @@ func (pm *PlaybackManager) listenToMediaPlayerEvents(ctx context.Context) {
 				// Push the video playback state to the history
 				pm.historyMap[status.Filename] = _ps
 
+				// ------- Playlist ------- //
+				go pm.playlistHub.onVideoCompleted(_ps)
+
 				pm.eventMu.Unlock()
 			case reason := <-pm.mediaPlayerRepoSubscriber.StreamingTrackingStoppedCh:
 				pm.eventMu.Lock()
@@ -368,6 +374,9 @@ func (pm *PlaybackManager) listenToMediaPlayerEvents(ctx context.Context) {
 				pm.Logger.Debug().Msg("playback manager: Received tracking stopped event")
 				pm.wsEventManager.SendEvent(events.PlaybackManagerProgressTrackingStopped, reason)
 
+				// ------- Playlist ------- //
+				go pm.playlistHub.onTrackingStopped()
+
 				// ------- Discord ------- //
 				if pm.discordPresence != nil && !pm.isOffline {
 					go pm.discordPresence.Close()
@@ -375,7 +384,8 @@ func (pm *PlaybackManager) listenToMediaPlayerEvents(ctx context.Context) {
 
 				pm.eventMu.Unlock()
 			case _ = <-pm.mediaPlayerRepoSubscriber.StreamingTrackingRetryCh:
-				// Do nothing
+				// ------- Playlist ------- //
+				go pm.playlistHub.onTrackingError()
 			}
 		}
 	}()
@@ -97,6 +97,7 @@ func (pm *PlaybackManager) getLocalFilePlaybackDetails(path string) (*anilist.An
 
 	// Create local file wrapper
 	lfw := anime.NewLocalFileWrapper(lfs)
+	lfw.SetVersionPreferences(pm.settings.VersionPreferences)
 	lfe, ok := lfw.GetLocalEntryById(lf.MediaId)
 	if !ok {
 		return nil, nil, nil, errors.New("local file wrapper entry not found")
@@ -33,6 +33,8 @@ type FileHydrator struct {
 	ScanLogger         *ScanLogger                // optional
 	ScanSummaryLogger  *summary.ScanSummaryLogger // optional
 	ForceMediaId       int                        // optional - force all local files to have this media ID
+	IdentifiedFiles    map[string]*IdentifiedFile // optional - files identified by their hash or NFO files keep their metadata
+	TitleAliases       *TitleAliases              // optional - used to offset episode numbers
 }
 
 // HydrateMetadata will hydrate the metadata of each LocalFile with the metadata of the matched anilist.BaseAnime.
@@ -163,6 +165,31 @@ func (fh *FileHydrator) hydrateGroupMetadata(
 			return
 		}
 
+		// Identified metadata
+		// Files identified without an episode (e.g. the lookup only returned the anime) are hydrated normally
+		if identified, ok := fh.IdentifiedFiles[lf.GetNormalizedPath()]; ok && identified.MediaId == mId && identified.AnidbEpisode != "" {
+			lf.Metadata = GetLocalFileMetadataFromAnidbEpisode(identified.AnidbEpisode)
+			episode = lf.Metadata.Episode
+
+			/*Log */
+			if identified.NFO != "" {
+				if fh.ScanLogger != nil {
+					fh.logFileHydration(zerolog.DebugLevel, lf, mId, episode).
+						Str("nfo", identified.NFO).
+						Msg("File metadata set from NFO file")
+				}
+				fh.ScanSummaryLogger.LogDebug(lf, "File metadata set from NFO file")
+				return
+			}
+			if fh.ScanLogger != nil {
+				fh.logFileHydration(zerolog.DebugLevel, lf, mId, episode).
+					Str("ed2k", identified.ED2K).
+					Msg("File metadata set from hash identification")
+			}
+			fh.ScanSummaryLogger.LogDebug(lf, "File metadata set from hash identification")
+			return
+		}
+
 		lf.Metadata.Type = anime.LocalFileTypeMain
 
 		// Get episode number
@@ -172,6 +199,16 @@ func (fh *FileHydrator) hydrateGroupMetadata(
 			}
 		}
 
+		// Offset the episode number if the file was matched using an alias
+		if alias, ok := fh.TitleAliases.Find(lf); ok && alias.MediaId == mId && alias.EpisodeOffset != 0 && episode > -1 {
+			if fh.ScanLogger != nil {
+				fh.logFileHydration(zerolog.DebugLevel, lf, mId, episode).
+					Int("offset", alias.EpisodeOffset).
+					Msg("Episode number offset using title alias")
+			}
+			episode = max(episode+alias.EpisodeOffset, 0)
+		}
+
 		// NC metadata
 		if comparison.ValueContainsNC(lf.Name) {
 			lf.Metadata.Episode = 0
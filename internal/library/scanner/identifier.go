@@ -0,0 +1,240 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"seanime/internal/api/anidb"
+	"seanime/internal/api/anizip"
+	"seanime/internal/database/models"
+	"seanime/internal/library/anime"
+	"seanime/internal/util"
+	"seanime/internal/util/filecache"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/sourcegraph/conc/pool"
+)
+
+const (
+	ed2kHashBucketName       = "ed2k-hashes"
+	fileLookupBucketName     = "anidb-file-lookup"
+	fileLookupCacheTTL       = 7 * 24 * time.Hour
+	fileIdentifierMaxWorkers = 2
+)
+
+type (
+	// FileIdentifier identifies local files using their ED2K hash before the matching process.
+	// Identified files are matched authoritatively and skipped by the Matcher.
+	FileIdentifier struct {
+		Client     anidb.FileLookupClient
+		FileCacher *filecache.Cacher // optional - used to cache the hashes and lookups
+		Logger     *zerolog.Logger
+		ScanLogger *ScanLogger // optional
+	}
+
+	// IdentifiedFile holds the result of the identification of a local file.
+	IdentifiedFile struct {
+		ED2K string
+		// Path of the NFO file if the file was identified from its NFO files
+		NFO     string
+		MediaId int
+		// Empty if only the media is known, the episode is then hydrated normally
+		AnidbEpisode string
+	}
+)
+
+// NewFileIdentifier returns a FileIdentifier if hash-based identification is enabled in the settings, nil otherwise.
+func NewFileIdentifier(settings *models.LibrarySettings, fileCacher *filecache.Cacher, logger *zerolog.Logger) *FileIdentifier {
+	if settings == nil || !settings.ScannerUseFileHashes || settings.ScannerFileLookupUrl == "" {
+		return nil
+	}
+	return &FileIdentifier{
+		Client:     anidb.NewHTTPFileLookupClient(settings.ScannerFileLookupUrl),
+		FileCacher: fileCacher,
+		Logger:     logger,
+	}
+}
+
+// Identify hashes the local files and looks them up.
+// The media ID of identified files is set, their metadata is set if the episode is known.
+// It returns the identified files by normalized path.
+func (fi *FileIdentifier) Identify(lfs []*anime.LocalFile) map[string]*IdentifiedFile {
+	defer util.HandlePanicInModuleThen("scanner/identifier/Identify", func() {})
+
+	start := time.Now()
+	ret := make(map[string]*IdentifiedFile)
+	mu := sync.Mutex{}
+	anizipCache := anizip.NewCache()
+
+	fi.Logger.Debug().Int("count", len(lfs)).Msg("file identifier: Identifying files")
+
+	p := pool.New().WithMaxGoroutines(fileIdentifierMaxWorkers)
+	for _, lf := range lfs {
+		if lf.MediaId != 0 {
+			continue
+		}
+		p.Go(func() {
+			identified, err := fi.identify(lf, anizipCache)
+			if err != nil {
+				if fi.ScanLogger != nil {
+					fi.ScanLogger.LogFileIdentifier(zerolog.DebugLevel).
+						Str("filename", lf.Name).
+						Str("reason", err.Error()).
+						Msg("File not identified")
+				}
+				return
+			}
+
+			lf.MediaId = identified.MediaId
+			// The metadata is left untouched if the episode is unknown, the file is then hydrated normally
+			if identified.AnidbEpisode != "" {
+				lf.Metadata = GetLocalFileMetadataFromAnidbEpisode(identified.AnidbEpisode)
+			}
+
+			if fi.ScanLogger != nil {
+				fi.ScanLogger.LogFileIdentifier(zerolog.DebugLevel).
+					Str("filename", lf.Name).
+					Str("ed2k", identified.ED2K).
+					Int("mediaId", identified.MediaId).
+					Str("anidbEpisode", identified.AnidbEpisode).
+					Msg("File identified")
+			}
+
+			mu.Lock()
+			ret[lf.GetNormalizedPath()] = identified
+			mu.Unlock()
+		})
+	}
+	p.Wait()
+
+	fi.Logger.Debug().Int("identified", len(ret)).Msg("file identifier: Finished identifying files")
+
+	if fi.ScanLogger != nil {
+		fi.ScanLogger.LogFileIdentifier(zerolog.InfoLevel).
+			Int64("ms", time.Since(start).Milliseconds()).
+			Int("identified", len(ret)).
+			Msg("Finished identifying files")
+	}
+
+	return ret
+}
+
+func (fi *FileIdentifier) identify(lf *anime.LocalFile, anizipCache *anizip.Cache) (*IdentifiedFile, error) {
+	info, err := os.Stat(lf.GetPath())
+	if err != nil {
+		return nil, err
+	}
+
+	ed2k, err := fi.getHash(lf.GetPath(), info)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash file: %w", err)
+	}
+
+	res, err := fi.lookup(ed2k, info.Size())
+	if err != nil {
+		return nil, err
+	}
+
+	mediaId, err := res.GetAnilistId(anizipCache)
+	if err != nil {
+		return nil, err
+	}
+
+	return &IdentifiedFile{
+		ED2K:         ed2k,
+		MediaId:      mediaId,
+		AnidbEpisode: res.AnidbEpisode,
+	}, nil
+}
+
+// getHash returns the ED2K hash of the file.
+// Hashes are cached by path, size and modification time.
+func (fi *FileIdentifier) getHash(path string, info os.FileInfo) (string, error) {
+	key := fmt.Sprintf("%s$%d$%d", util.NormalizePath(path), info.Size(), info.ModTime().Unix())
+	bucket := filecache.NewPermanentBucket(ed2kHashBucketName)
+
+	if fi.FileCacher != nil {
+		var hash string
+		if found, _ := fi.FileCacher.GetPerm(bucket, key, &hash); found && hash != "" {
+			return hash, nil
+		}
+	}
+
+	hash, err := anidb.ComputeED2K(path)
+	if err != nil {
+		return "", err
+	}
+
+	if fi.FileCacher != nil {
+		_ = fi.FileCacher.SetPerm(bucket, key, hash)
+	}
+
+	return hash, nil
+}
+
+// lookup returns the AniDB data of the file.
+// Results, including unknown files, are cached for a week.
+func (fi *FileIdentifier) lookup(ed2k string, size int64) (*anidb.FileLookupResult, error) {
+	key := ed2k + "$" + strconv.FormatInt(size, 10)
+	bucket := filecache.NewBucket(fileLookupBucketName, fileLookupCacheTTL)
+
+	if fi.FileCacher != nil {
+		var res *anidb.FileLookupResult
+		if found, _ := fi.FileCacher.Get(bucket, key, &res); found {
+			if res == nil {
+				return nil, anidb.ErrFileNotFound
+			}
+			return res, nil
+		}
+	}
+
+	res, err := fi.Client.LookupFile(ed2k, size)
+	if err != nil {
+		if errors.Is(err, anidb.ErrFileNotFound) && fi.FileCacher != nil {
+			_ = fi.FileCacher.Set(bucket, key, nil)
+		}
+		return nil, err
+	}
+
+	if fi.FileCacher != nil {
+		_ = fi.FileCacher.Set(bucket, key, res)
+	}
+
+	return res, nil
+}
+
+// GetLocalFileMetadataFromAnidbEpisode returns the metadata corresponding to the AniDB episode number.
+//   - "1" -> Main episode 1
+//   - "S1" -> Special episode 1
+//   - "C1", "T1", "O1", etc. -> NC
+func GetLocalFileMetadataFromAnidbEpisode(anidbEpisode string) *anime.LocalFileMetadata {
+	anidbEpisode = strings.ToUpper(strings.TrimSpace(anidbEpisode))
+
+	if ep, err := strconv.Atoi(anidbEpisode); err == nil {
+		return &anime.LocalFileMetadata{
+			Episode:      ep,
+			AniDBEpisode: strconv.Itoa(ep),
+			Type:         anime.LocalFileTypeMain,
+		}
+	}
+
+	if strings.HasPrefix(anidbEpisode, "S") {
+		if ep, err := strconv.Atoi(anidbEpisode[1:]); err == nil {
+			return &anime.LocalFileMetadata{
+				Episode:      ep,
+				AniDBEpisode: "S" + strconv.Itoa(ep),
+				Type:         anime.LocalFileTypeSpecial,
+			}
+		}
+	}
+
+	return &anime.LocalFileMetadata{
+		Episode:      0,
+		AniDBEpisode: "",
+		Type:         anime.LocalFileTypeNC,
+	}
+}
@@ -0,0 +1,110 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"seanime/internal/api/anidb"
+	"seanime/internal/library/anime"
+	"seanime/internal/util"
+	"seanime/internal/util/filecache"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeFileLookupClient struct {
+	files map[string]*anidb.FileLookupResult
+	calls int
+}
+
+func (c *fakeFileLookupClient) LookupFile(ed2k string, size int64) (*anidb.FileLookupResult, error) {
+	c.calls++
+	res, ok := c.files[ed2k]
+	if !ok {
+		return nil, anidb.ErrFileNotFound
+	}
+	return res, nil
+}
+
+func TestFileIdentifier_Identify(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"[Group] Unknown Title - 01.mkv":  "episode",
+		"[Group] Unknown Title - OP.mkv":  "opening",
+		"[Group] Unknown Title - SP1.mkv": "special",
+		"[Group] Not In AniDB - 01.mkv":   "unknown",
+		"[Group] Unknown Title - 02.mkv":  "no episode",
+	}
+
+	lfs := make([]*anime.LocalFile, 0)
+	hashes := make(map[string]string)
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(p, []byte(content), 0644))
+		lfs = append(lfs, anime.NewLocalFile(p, dir))
+
+		hash, err := anidb.ComputeED2K(p)
+		require.NoError(t, err)
+		hashes[content] = hash
+	}
+
+	client := &fakeFileLookupClient{
+		files: map[string]*anidb.FileLookupResult{
+			hashes["episode"]: {AnidbAnimeId: 1, AnidbEpisode: "1", AnilistId: 21},
+			hashes["opening"]: {AnidbAnimeId: 1, AnidbEpisode: "C1", AnilistId: 21},
+			hashes["special"]: {AnidbAnimeId: 1, AnidbEpisode: "S1", AnilistId: 21},
+			// Only the anime is known
+			hashes["no episode"]: {AnidbAnimeId: 1, AnilistId: 21},
+		},
+	}
+
+	fileCacher, err := filecache.NewCacher(t.TempDir())
+	require.NoError(t, err)
+
+	fi := &FileIdentifier{
+		Client:     client,
+		FileCacher: fileCacher,
+		Logger:     util.NewLogger(),
+	}
+
+	metadata := make(map[string]*anime.LocalFileMetadata)
+	for _, lf := range lfs {
+		metadata[lf.Name] = lf.Metadata
+	}
+
+	identified := fi.Identify(lfs)
+	require.Len(t, identified, 4)
+	require.Equal(t, 5, client.calls)
+
+	for _, lf := range lfs {
+		switch lf.Name {
+		case "[Group] Unknown Title - 01.mkv":
+			require.Equal(t, 21, lf.MediaId)
+			require.Equal(t, anime.LocalFileTypeMain, lf.Metadata.Type)
+			require.Equal(t, 1, lf.Metadata.Episode)
+			require.Equal(t, "1", lf.Metadata.AniDBEpisode)
+		case "[Group] Unknown Title - OP.mkv":
+			require.Equal(t, 21, lf.MediaId)
+			require.Equal(t, anime.LocalFileTypeNC, lf.Metadata.Type)
+		case "[Group] Unknown Title - SP1.mkv":
+			require.Equal(t, 21, lf.MediaId)
+			require.Equal(t, anime.LocalFileTypeSpecial, lf.Metadata.Type)
+			require.Equal(t, "S1", lf.Metadata.AniDBEpisode)
+		case "[Group] Unknown Title - 02.mkv":
+			// The metadata is left to the hydrator
+			require.Equal(t, 21, lf.MediaId)
+			require.Same(t, metadata[lf.Name], lf.Metadata)
+		default:
+			require.Equal(t, 0, lf.MediaId)
+		}
+	}
+
+	// Lookups, including unknown files, are cached
+	for _, lf := range lfs {
+		lf.MediaId = 0
+	}
+	identified = fi.Identify(lfs)
+	require.Len(t, identified, 4)
+	require.Equal(t, 5, client.calls)
+}
@@ -28,6 +28,8 @@ type Matcher struct {
 	ScanSummaryLogger  *summary.ScanSummaryLogger // optional
 	Algorithm          string
 	Threshold          float64
+	IdentifiedFiles    map[string]*IdentifiedFile // optional - files identified by their hash or NFO files are not matched if their media is in the container
+	TitleAliases       *TitleAliases              // optional - aliases are consulted before matching
 }
 
 var (
@@ -118,6 +120,28 @@ func (m *Matcher) matchLocalFileWithMedia(lf *anime.LocalFile) {
 		m.ScanSummaryLogger.LogPanic(lf, stackTrace)
 	})
 
+	// Files identified by their hash or NFO files are authoritative, as long as their media is in the container
+	if identified, ok := m.IdentifiedFiles[lf.GetNormalizedPath()]; ok {
+		if _, found := m.MediaContainer.GetMediaFromId(identified.MediaId); found {
+			lf.MediaId = identified.MediaId
+			if m.ScanLogger != nil {
+				m.ScanLogger.LogMatcher(zerolog.DebugLevel).
+					Str("filename", lf.Name).
+					Int("mediaId", identified.MediaId).
+					Msg("File already identified")
+			}
+			m.ScanSummaryLogger.LogSuccessfullyMatched(lf, identified.MediaId)
+			return
+		}
+
+		if m.ScanLogger != nil {
+			m.ScanLogger.LogMatcher(zerolog.DebugLevel).
+				Str("filename", lf.Name).
+				Int("mediaId", identified.MediaId).
+				Msg("Identified media not found, matching file")
+		}
+	}
+
 	// Check if the local file has already been matched
 	if lf.MediaId != 0 {
 		if m.ScanLogger != nil {
@@ -139,6 +163,23 @@ func (m *Matcher) matchLocalFileWithMedia(lf *anime.LocalFile) {
 		return
 	}
 
+	// Check if the title has an alias
+	if alias, ok := m.TitleAliases.Find(lf); ok {
+		if _, found := m.MediaContainer.GetMediaFromId(alias.MediaId); found {
+			lf.MediaId = alias.MediaId
+
+			if m.ScanLogger != nil {
+				m.ScanLogger.LogMatcher(zerolog.DebugLevel).
+					Str("filename", lf.Name).
+					Str("alias", alias.Title).
+					Int("mediaId", alias.MediaId).
+					Msg("File matched using title alias")
+			}
+			m.ScanSummaryLogger.LogSuccessfullyMatched(lf, alias.MediaId)
+			return
+		}
+	}
+
 	// Create title variations
 	// Check cache for title variation
 
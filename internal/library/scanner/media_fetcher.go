@@ -38,6 +38,7 @@ type MediaFetcherOptions struct {
 	AnilistRateLimiter     *limiter.Limiter
 	DisableAnimeCollection bool
 	ScanLogger             *ScanLogger
+	MediaIds               []int // optional - media that should be fetched, e.g. media of identified files
 }
 
 // NewMediaFetcher
@@ -145,6 +146,30 @@ func NewMediaFetcher(opts *MediaFetcherOptions) (ret *MediaFetcher, retErr error
 		}
 	}
 
+	// +---------------------+
+	// |   Requested media   |
+	// +---------------------+
+
+	// Fetch the requested media that are not already fetched
+	for _, mId := range lo.Uniq(opts.MediaIds) {
+		if _, found := opts.CompleteAnimeCache.Get(mId); found {
+			continue
+		}
+		opts.AnilistRateLimiter.Wait()
+		media, err := opts.Platform.GetAnimeWithRelations(mId)
+		if err != nil {
+			if mf.ScanLogger != nil {
+				mf.ScanLogger.LogMediaFetcher(zerolog.WarnLevel).
+					Int("mediaId", mId).
+					Str("error", err.Error()).
+					Msg("Could not fetch requested media")
+			}
+			continue
+		}
+		opts.CompleteAnimeCache.Set(media.ID, media)
+		mf.AllMedia = append(mf.AllMedia, media)
+	}
+
 	// +---------------------+
 	// |   Unknown media     |
 	// +---------------------+
@@ -0,0 +1,57 @@
+package scanner
+
+import (
+	"seanime/internal/database/models"
+	"seanime/internal/library/anime"
+	"seanime/internal/library/nfo"
+
+	"github.com/rs/zerolog"
+)
+
+// NewNFOReader returns an NFO reader if NFO identification is enabled in the settings, nil otherwise.
+func NewNFOReader(settings *models.LibrarySettings) *nfo.Reader {
+	if settings == nil || !settings.ScannerUseNFOFiles {
+		return nil
+	}
+	return nfo.NewReader(settings.GetLibraryPaths(), settings.NFOMirrorDir)
+}
+
+// IdentifyFromNFO sets the media ID of the local files that have an NFO file with an AniList ID.
+// It returns the identified files by normalized path.
+// Files identified from an episode NFO file also keep its episode number.
+func IdentifyFromNFO(reader *nfo.Reader, lfs []*anime.LocalFile, scanLogger *ScanLogger) map[string]*IdentifiedFile {
+	ret := make(map[string]*IdentifiedFile)
+
+	for _, lf := range lfs {
+		if lf.MediaId != 0 {
+			continue
+		}
+
+		match, ok := reader.FindMatch(lf.GetPath())
+		if !ok {
+			continue
+		}
+
+		lf.MediaId = match.MediaId
+		if match.AnidbEpisode != "" {
+			lf.Metadata = GetLocalFileMetadataFromAnidbEpisode(match.AnidbEpisode)
+		}
+
+		ret[lf.GetNormalizedPath()] = &IdentifiedFile{
+			NFO:          match.Path,
+			MediaId:      match.MediaId,
+			AnidbEpisode: match.AnidbEpisode,
+		}
+
+		if scanLogger != nil {
+			scanLogger.LogFileIdentifier(zerolog.DebugLevel).
+				Str("filename", lf.Name).
+				Str("nfo", match.Path).
+				Int("mediaId", match.MediaId).
+				Str("anidbEpisode", match.AnidbEpisode).
+				Msg("File identified from NFO file")
+		}
+	}
+
+	return ret
+}
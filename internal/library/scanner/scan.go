@@ -2,12 +2,14 @@ package scanner
 
 import (
 	"errors"
+	"maps"
 	"seanime/internal/api/anilist"
 	"seanime/internal/api/metadata"
 	"seanime/internal/events"
 	"seanime/internal/hook"
 	"seanime/internal/library/anime"
 	"seanime/internal/library/filesystem"
+	"seanime/internal/library/nfo"
 	"seanime/internal/library/summary"
 	"seanime/internal/platforms/platform"
 	"seanime/internal/util"
@@ -35,6 +37,9 @@ type Scanner struct {
 	MetadataProvider   metadata.Provider
 	MatchingThreshold  float64
 	MatchingAlgorithm  string
+	FileIdentifier     *FileIdentifier // optional - identifies files by their hash before matching
+	TitleAliases       *TitleAliases   // optional - aliases learned from manual matches
+	NFOReader          *nfo.Reader     // optional - identifies files using the AniList IDs of their NFO files
 }
 
 // Scan will scan the directory and return a list of anime.LocalFile.
@@ -254,6 +259,27 @@ func (scn *Scanner) Scan() (lfs []*anime.LocalFile, err error) {
 		return localFiles, nil
 	}
 
+	// +---------------------+
+	// |   FileIdentifier    |
+	// +---------------------+
+
+	// Identify files by their NFO files, then by their hash
+	identifiedFiles := make(map[string]*IdentifiedFile)
+	if scn.NFOReader != nil {
+		maps.Copy(identifiedFiles, IdentifyFromNFO(scn.NFOReader, localFiles, scn.ScanLogger))
+	}
+	if scn.FileIdentifier != nil {
+		scn.WSEventManager.SendEvent(events.EventScanProgress, 15)
+		scn.WSEventManager.SendEvent(events.EventScanStatus, "Identifying files...")
+
+		scn.FileIdentifier.ScanLogger = scn.ScanLogger
+		maps.Copy(identifiedFiles, scn.FileIdentifier.Identify(localFiles))
+	}
+	identifiedMediaIds := make([]int, 0, len(identifiedFiles))
+	for _, identified := range identifiedFiles {
+		identifiedMediaIds = append(identifiedMediaIds, identified.MediaId)
+	}
+
 	scn.WSEventManager.SendEvent(events.EventScanProgress, 20)
 	if scn.Enhanced {
 		scn.WSEventManager.SendEvent(events.EventScanStatus, "Fetching media detected from file titles...")
@@ -276,6 +302,7 @@ func (scn *Scanner) Scan() (lfs []*anime.LocalFile, err error) {
 		AnilistRateLimiter:     anilistRateLimiter,
 		DisableAnimeCollection: false,
 		ScanLogger:             scn.ScanLogger,
+		MediaIds:               append(identifiedMediaIds, scn.TitleAliases.GetMediaIds(localFiles)...),
 	})
 	if err != nil {
 		return nil, err
@@ -312,6 +339,8 @@ func (scn *Scanner) Scan() (lfs []*anime.LocalFile, err error) {
 		ScanSummaryLogger:  scn.ScanSummaryLogger,
 		Algorithm:          scn.MatchingAlgorithm,
 		Threshold:          scn.MatchingThreshold,
+		IdentifiedFiles:    identifiedFiles,
+		TitleAliases:       scn.TitleAliases,
 	}
 
 	scn.WSEventManager.SendEvent(events.EventScanProgress, 60)
@@ -345,6 +374,8 @@ func (scn *Scanner) Scan() (lfs []*anime.LocalFile, err error) {
 		Logger:             scn.Logger,
 		ScanLogger:         scn.ScanLogger,
 		ScanSummaryLogger:  scn.ScanSummaryLogger,
+		IdentifiedFiles:    identifiedFiles,
+		TitleAliases:       scn.TitleAliases,
 	}
 	hydrator.HydrateMetadata()
 
@@ -75,6 +75,10 @@ func (sl *ScanLogger) LogMediaFetcher(level zerolog.Level) *zerolog.Event {
 	return sl.logger.WithLevel(level).Str("context", "MediaFetcher")
 }
 
+func (sl *ScanLogger) LogFileIdentifier(level zerolog.Level) *zerolog.Event {
+	return sl.logger.WithLevel(level).Str("context", "FileIdentifier")
+}
+
 // Done flushes the buffer to the log file and closes the file.
 func (sl *ScanLogger) Done() error {
 	if sl.logFile == nil {
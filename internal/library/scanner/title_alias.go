@@ -0,0 +1,167 @@
+package scanner
+
+import (
+	"seanime/internal/database/models"
+	"seanime/internal/library/anime"
+	"seanime/internal/util"
+	"strings"
+	"unicode"
+
+	"github.com/samber/lo"
+)
+
+// TitleAliases is used to look up the title aliases of local files.
+type TitleAliases struct {
+	aliases map[string][]*models.TitleAlias // Normalized title -> aliases
+}
+
+func NewTitleAliases(aliases []*models.TitleAlias) *TitleAliases {
+	ret := &TitleAliases{
+		aliases: make(map[string][]*models.TitleAlias),
+	}
+	for _, alias := range aliases {
+		if alias == nil || alias.MediaId == 0 {
+			continue
+		}
+		title := NormalizeAliasTitle(alias.Title)
+		if title == "" {
+			continue
+		}
+		ret.aliases[title] = append(ret.aliases[title], alias)
+	}
+	return ret
+}
+
+// Find returns the alias that best matches the local file.
+// Aliases with a matching release group or season are preferred over generic ones.
+func (ta *TitleAliases) Find(lf *anime.LocalFile) (*models.TitleAlias, bool) {
+	if ta == nil || lf == nil || lf.ParsedData == nil {
+		return nil, false
+	}
+
+	candidates, ok := ta.aliases[NormalizeAliasTitle(lf.GetParsedTitle())]
+	if !ok {
+		return nil, false
+	}
+
+	group := strings.ToLower(lf.ParsedData.ReleaseGroup)
+	season := getLocalFileSeason(lf)
+
+	var ret *models.TitleAlias
+	bestScore := -1
+	for _, alias := range candidates {
+		score := 0
+		if alias.ReleaseGroup != "" {
+			if strings.ToLower(alias.ReleaseGroup) != group {
+				continue
+			}
+			score += 2
+		}
+		if alias.Season != 0 {
+			if alias.Season != season {
+				continue
+			}
+			score += 1
+		}
+		if score > bestScore {
+			ret = alias
+			bestScore = score
+		}
+	}
+
+	return ret, ret != nil
+}
+
+// GetMediaIds returns the media IDs of the aliases matching the local files.
+func (ta *TitleAliases) GetMediaIds(lfs []*anime.LocalFile) []int {
+	ret := make([]int, 0)
+	for _, lf := range lfs {
+		if alias, ok := ta.Find(lf); ok {
+			ret = append(ret, alias.MediaId)
+		}
+	}
+	return lo.Uniq(ret)
+}
+
+// LearnTitleAliases creates title aliases from local files that were manually matched to the media.
+// The episode offset is the most common difference between the hydrated and parsed episode numbers.
+func LearnTitleAliases(lfs []*anime.LocalFile, mediaId int) []*models.TitleAlias {
+	type key struct {
+		title  string
+		group  string
+		season int
+	}
+
+	offsets := make(map[key]map[int]int)
+	keys := make([]key, 0)
+
+	for _, lf := range lfs {
+		if lf == nil || lf.ParsedData == nil {
+			continue
+		}
+		k := key{
+			title:  NormalizeAliasTitle(lf.GetParsedTitle()),
+			group:  lf.ParsedData.ReleaseGroup,
+			season: getLocalFileSeason(lf),
+		}
+		if k.title == "" {
+			continue
+		}
+		if _, ok := offsets[k]; !ok {
+			offsets[k] = make(map[int]int)
+			keys = append(keys, k)
+		}
+
+		// Only main episodes are used to compute the offset
+		if lf.Metadata == nil || lf.Metadata.Type != anime.LocalFileTypeMain || lf.Metadata.Episode <= 0 {
+			continue
+		}
+		if ep, ok := util.StringToInt(lf.ParsedData.Episode); ok {
+			offsets[k][lf.Metadata.Episode-ep]++
+		}
+	}
+
+	ret := make([]*models.TitleAlias, 0, len(keys))
+	for _, k := range keys {
+		offset, count := 0, 0
+		for o, c := range offsets[k] {
+			if c > count || (c == count && o < offset) {
+				offset, count = o, c
+			}
+		}
+		ret = append(ret, &models.TitleAlias{
+			Title:         k.title,
+			ReleaseGroup:  k.group,
+			Season:        k.season,
+			MediaId:       mediaId,
+			EpisodeOffset: offset,
+		})
+	}
+
+	return ret
+}
+
+// NormalizeAliasTitle lowercases the title and removes punctuation so that small variations are ignored.
+//   - "Mushoku Tensei: Jobless Reincarnation" -> "mushoku tensei jobless reincarnation"
+func NormalizeAliasTitle(title string) string {
+	fields := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// getLocalFileSeason returns the parsed season number, falling back to the folder names.
+func getLocalFileSeason(lf *anime.LocalFile) int {
+	if season, ok := util.StringToInt(lf.ParsedData.Season); ok {
+		return season
+	}
+	for _, fpd := range lf.ParsedFolderData {
+		if fpd == nil {
+			continue
+		}
+		if season, ok := util.StringToInt(fpd.Season); ok {
+			return season
+		}
+	}
+	return 0
+}
@@ -0,0 +1,94 @@
+package scanner
+
+import (
+	"path/filepath"
+	"seanime/internal/database/models"
+	"seanime/internal/library/anime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLearnTitleAliases(t *testing.T) {
+	dir := filepath.FromSlash("E:/Anime")
+
+	lfs := []*anime.LocalFile{
+		anime.NewLocalFile(filepath.Join(dir, "[SubsPlease] Mushoku Tensei S2 - 13 (1080p).mkv"), dir),
+		anime.NewLocalFile(filepath.Join(dir, "[SubsPlease] Mushoku Tensei S2 - 14 (1080p).mkv"), dir),
+		anime.NewLocalFile(filepath.Join(dir, "[SubsPlease] Mushoku Tensei S2 - 15 (1080p).mkv"), dir),
+	}
+	for _, lf := range lfs {
+		lf.MediaId = 166873
+		lf.Metadata = &anime.LocalFileMetadata{Type: anime.LocalFileTypeMain}
+		switch lf.ParsedData.Episode {
+		case "13":
+			lf.Metadata.Episode = 1
+		case "14":
+			lf.Metadata.Episode = 2
+		case "15":
+			lf.Metadata.Episode = 3
+		}
+	}
+
+	aliases := LearnTitleAliases(lfs, 166873)
+	require.Len(t, aliases, 1)
+	require.Equal(t, "mushoku tensei", aliases[0].Title)
+	require.Equal(t, "SubsPlease", aliases[0].ReleaseGroup)
+	require.Equal(t, 2, aliases[0].Season)
+	require.Equal(t, -12, aliases[0].EpisodeOffset)
+
+	lf := anime.NewLocalFile(filepath.Join(dir, "[SubsPlease] Mushoku Tensei S2 - 16 (1080p).mkv"), dir)
+	alias, found := NewTitleAliases(aliases).Find(lf)
+	require.True(t, found)
+	require.Equal(t, 166873, alias.MediaId)
+}
+
+func TestTitleAliases_Find(t *testing.T) {
+	dir := filepath.FromSlash("E:/Anime")
+
+	titleAliases := NewTitleAliases([]*models.TitleAlias{
+		{Title: "Oshi no Ko", MediaId: 150672},
+		{Title: "oshi no ko", Season: 2, MediaId: 166531},
+		{Title: "oshi no ko", ReleaseGroup: "Erai-raws", Season: 2, MediaId: 1},
+	})
+
+	tests := []struct {
+		name            string
+		filename        string
+		expectedMediaId int
+	}{
+		{
+			name:            "Generic alias",
+			filename:        "[SubsPlease] Oshi no Ko - 01 (1080p).mkv",
+			expectedMediaId: 150672,
+		},
+		{
+			name:            "Season alias",
+			filename:        "[SubsPlease] Oshi no Ko S2 - 01 (1080p).mkv",
+			expectedMediaId: 166531,
+		},
+		{
+			name:            "Release group alias",
+			filename:        "[Erai-raws] Oshi no Ko S2 - 01 (1080p).mkv",
+			expectedMediaId: 1,
+		},
+		{
+			name:            "No alias",
+			filename:        "[SubsPlease] Frieren - 01 (1080p).mkv",
+			expectedMediaId: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lf := anime.NewLocalFile(filepath.Join(dir, tt.filename), dir)
+			alias, found := titleAliases.Find(lf)
+			if tt.expectedMediaId == 0 {
+				require.False(t, found)
+				return
+			}
+			require.True(t, found)
+			require.Equal(t, tt.expectedMediaId, alias.MediaId)
+		})
+	}
+}
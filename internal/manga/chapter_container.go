@@ -263,7 +263,8 @@ func (r *Repository) RefreshChapterContainers(mangaCollection *anilist.MangaColl
 				return
 			}
 			// If the bucket type is not chapter, skip
-			if bucketType != bucketTypeChapter {
+			// The merged chapter list is created from the other containers, so it's not refetched
+			if bucketType != bucketTypeChapter || provider == MergedProvider {
 				return
 			}
 
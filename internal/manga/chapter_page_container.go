@@ -41,6 +41,9 @@ func (r *Repository) GetMangaPageContainer(
 ) (ret *PageContainer, err error) {
 	defer util.HandlePanicInModuleWithError("manga/GetMangaPageContainer", &err)
 
+	// Chapters of the merged chapter list are fetched from the provider that supplies them
+	provider = r.resolveChapterProvider(provider, mediaId, chapterId)
+
 	// +---------------------+
 	// |      Downloads      |
 	// +---------------------+
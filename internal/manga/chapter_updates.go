@@ -0,0 +1,464 @@
+package manga
+
+import (
+	"fmt"
+	"os"
+	"seanime/internal/api/anilist"
+	"seanime/internal/database/db"
+	"seanime/internal/database/models"
+	"seanime/internal/events"
+	hibikemanga "seanime/internal/extension/hibike/manga"
+	manga_providers "seanime/internal/manga/providers"
+	"seanime/internal/notifier"
+	"seanime/internal/util"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/samber/mo"
+)
+
+const (
+	// minChapterUpdateCheckInterval is the minimum interval in minutes between two checks
+	minChapterUpdateCheckInterval = 30
+)
+
+type (
+	// ChapterUpdateChecker periodically refreshes the chapter containers of the manga the user is currently reading
+	// and records the chapters that appeared since the last check.
+	ChapterUpdateChecker struct {
+		logger             *zerolog.Logger
+		repository         *Repository
+		downloader         *Downloader
+		database           *db.Database
+		wsEventManager     events.WSEventManagerInterface
+		getMangaCollection func() (*anilist.MangaCollection, error)
+
+		settings          mo.Option[*models.MangaSettings]
+		settingsUpdatedCh chan struct{}
+		mu                sync.RWMutex
+		checkMu           sync.Mutex // Only one check can run at a time
+	}
+
+	NewChapterUpdateCheckerOptions struct {
+		Logger             *zerolog.Logger
+		Repository         *Repository
+		Downloader         *Downloader
+		Database           *db.Database
+		WSEventManager     events.WSEventManagerInterface
+		GetMangaCollection func() (*anilist.MangaCollection, error)
+	}
+
+	// ChapterUpdateFeedItem groups the unread chapter updates of a manga.
+	ChapterUpdateFeedItem struct {
+		MediaId  int                          `json:"mediaId"`
+		Media    *anilist.BaseManga           `json:"media"`
+		Progress int                          `json:"progress"`
+		Chapters []*models.MangaChapterUpdate `json:"chapters"` // Newest first
+	}
+)
+
+func NewChapterUpdateChecker(opts *NewChapterUpdateCheckerOptions) *ChapterUpdateChecker {
+	return &ChapterUpdateChecker{
+		logger:             opts.Logger,
+		repository:         opts.Repository,
+		downloader:         opts.Downloader,
+		database:           opts.Database,
+		wsEventManager:     opts.WSEventManager,
+		getMangaCollection: opts.GetMangaCollection,
+		settings:           mo.None[*models.MangaSettings](),
+		settingsUpdatedCh:  make(chan struct{}, 1),
+	}
+}
+
+// SetSettings updates the settings and restarts the timer.
+func (c *ChapterUpdateChecker) SetSettings(settings *models.MangaSettings) {
+	if c == nil || settings == nil {
+		return
+	}
+
+	c.mu.Lock()
+	c.settings = mo.Some(settings)
+	c.mu.Unlock()
+
+	// Notify the loop without blocking if a notification is already pending
+	select {
+	case c.settingsUpdatedCh <- struct{}{}:
+	default:
+	}
+}
+
+// getInterval returns the interval between two checks and false if the checks are disabled.
+func (c *ChapterUpdateChecker) getInterval() (time.Duration, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	settings, ok := c.settings.Get()
+	if !ok || settings.ChapterUpdateCheckInterval <= 0 {
+		return 0, false
+	}
+
+	interval := max(settings.ChapterUpdateCheckInterval, minChapterUpdateCheckInterval)
+	return time.Duration(interval) * time.Minute, true
+}
+
+// Start is called once to start the checker's main goroutine.
+func (c *ChapterUpdateChecker) Start() {
+	if c == nil {
+		return
+	}
+
+	go func() {
+		defer util.HandlePanicInModuleThen("manga/ChapterUpdateChecker/Start", func() {})
+
+		for {
+			var ticker *time.Ticker
+			var tickerCh <-chan time.Time
+			if interval, ok := c.getInterval(); ok {
+				ticker = time.NewTicker(interval)
+				tickerCh = ticker.C
+			}
+
+			select {
+			case <-c.settingsUpdatedCh:
+				// Restart the loop with the new interval
+			case <-tickerCh:
+				_, _ = c.CheckForUpdates(nil)
+			}
+
+			if ticker != nil {
+				ticker.Stop()
+			}
+		}
+	}()
+}
+
+// CheckForUpdates refreshes the chapter containers of the manga with the "CURRENT" status and records the new chapters.
+//
+// The provider of each manga is taken from selectedProviderMap if it is provided,
+// otherwise the provider the user last read the manga with is used, falling back to the default provider.
+// The first check of a manga only saves its chapter list, since there is nothing to compare it to.
+func (c *ChapterUpdateChecker) CheckForUpdates(selectedProviderMap map[int]string) (ret []*models.MangaChapterUpdate, err error) {
+	defer util.HandlePanicInModuleWithError("manga/CheckForUpdates", &err)
+
+	c.checkMu.Lock()
+	defer c.checkMu.Unlock()
+
+	ret = make([]*models.MangaChapterUpdate, 0)
+
+	mangaCollection, err := c.getMangaCollection()
+	if err != nil {
+		return nil, err
+	}
+	if mangaCollection == nil || mangaCollection.MediaListCollection == nil {
+		return ret, nil
+	}
+
+	var settings *models.MangaSettings
+	c.mu.RLock()
+	settings = c.settings.OrElse(&models.MangaSettings{})
+	c.mu.RUnlock()
+
+	lastUsedProviders := c.repository.getLastUsedProviders()
+
+	c.logger.Debug().Msg("manga: Checking for new chapters")
+
+	mediaTitles := make(map[int]string)
+	for _, list := range mangaCollection.MediaListCollection.Lists {
+		if list.Status == nil || *list.Status != anilist.MediaListStatusCurrent {
+			continue
+		}
+		for _, entry := range list.GetEntries() {
+			if entry.GetMedia() == nil {
+				continue
+			}
+			mediaId := entry.GetMedia().GetID()
+
+			provider := ""
+			// The merged chapter list is not checked, its chapters come from the other providers
+			if selectedProviderMap != nil && selectedProviderMap[mediaId] != MergedProvider {
+				provider = selectedProviderMap[mediaId]
+			}
+			if provider == "" {
+				provider = lastUsedProviders[mediaId]
+			}
+			if provider == "" {
+				provider = settings.DefaultProvider
+			}
+			if provider == "" {
+				continue
+			}
+
+			updates, err := c.checkEntry(entry, provider)
+			if err != nil {
+				c.logger.Warn().Err(err).Int("mediaId", mediaId).Str("provider", provider).Msg("manga: Failed to check for new chapters")
+				continue
+			}
+			if len(updates) > 0 {
+				mediaTitles[mediaId] = entry.GetMedia().GetPreferredTitle()
+			}
+			ret = append(ret, updates...)
+		}
+	}
+
+	c.logger.Debug().Int("count", len(ret)).Msg("manga: Finished checking for new chapters")
+
+	if len(ret) == 0 {
+		return ret, nil
+	}
+
+	// The latest chapter numbers have changed
+	mangaLatestChapterNumberMap.Delete(ChapterCountMapCacheKey)
+
+	c.wsEventManager.SendEvent(events.MangaChapterUpdatesFound, ret)
+	notifier.GlobalNotifier.Notify(notifier.MangaUpdates, formatChapterUpdatesNotification(ret, mediaTitles))
+
+	if settings.AutoDownloadNewChapters && c.downloader != nil {
+		c.queueChapterUpdates(ret)
+	}
+
+	return ret, nil
+}
+
+// checkEntry refetches the chapter container of the manga and records the chapters that weren't found during the previous check.
+// The chapters found are saved in the database, so that the check doesn't depend on the chapter container cache.
+func (c *ChapterUpdateChecker) checkEntry(entry *anilist.MangaListEntry, provider string) ([]*models.MangaChapterUpdate, error) {
+	mediaId := entry.GetMedia().GetID()
+
+	known, hasKnown, err := c.database.GetMangaKnownChapters(provider, mediaId)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket := c.repository.getFcProviderBucket(provider, mediaId, bucketTypeChapter)
+	key := getMangaChapterContainerCacheKey(provider, mediaId)
+
+	previous, hasPrevious := c.repository.getChapterContainerFromFilecache(provider, mediaId)
+	// Use the cached container if the manga hasn't been checked before
+	if !hasKnown && hasPrevious {
+		known = getKnownChapters(previous)
+		hasKnown = true
+	}
+
+	// Remove the cached container so that it is fetched again
+	_ = c.repository.fileCacher.Delete(bucket, key)
+
+	container, err := c.repository.GetMangaChapterContainer(&GetMangaChapterContainerOptions{
+		Provider: provider,
+		MediaId:  mediaId,
+		Titles:   entry.GetMedia().GetAllTitles(),
+		Year:     entry.GetMedia().GetStartYearSafe(),
+	})
+	if err != nil {
+		// Put back the previous container so that the chapters can still be read
+		if hasPrevious {
+			_ = c.repository.fileCacher.Set(bucket, key, previous)
+		}
+		return nil, err
+	}
+
+	ret := make([]*models.MangaChapterUpdate, 0)
+	if hasKnown {
+		now := time.Now()
+		for _, chapter := range getNewChapters(known, container) {
+			update := &models.MangaChapterUpdate{
+				MediaId:       mediaId,
+				Provider:      provider,
+				ChapterId:     chapter.ID,
+				ChapterNumber: manga_providers.GetNormalizedChapter(chapter.Chapter),
+				Title:         chapter.Title,
+				DetectedAt:    now,
+			}
+			inserted, err := c.database.InsertMangaChapterUpdate(update)
+			if err != nil {
+				return ret, err
+			}
+			if inserted {
+				ret = append(ret, update)
+			}
+		}
+	}
+
+	if err := c.database.SaveMangaKnownChapters(provider, mediaId, getKnownChapters(container)); err != nil {
+		return ret, err
+	}
+
+	return ret, nil
+}
+
+// queueChapterUpdates adds the new chapters to the download queue and runs it.
+func (c *ChapterUpdateChecker) queueChapterUpdates(updates []*models.MangaChapterUpdate) {
+	queued := 0
+	for _, update := range updates {
+		err := c.downloader.DownloadChapter(DownloadChapterOptions{
+			Provider:  update.Provider,
+			MediaId:   update.MediaId,
+			ChapterId: update.ChapterId,
+		})
+		if err != nil {
+			c.logger.Warn().Err(err).Str("chapterId", update.ChapterId).Msg("manga: Failed to queue new chapter")
+			continue
+		}
+		queued++
+	}
+
+	if queued > 0 {
+		c.logger.Info().Int("count", queued).Msg("manga: Queued new chapters for download")
+		c.downloader.RunChapterDownloadQueue()
+	}
+}
+
+// GetUnreadFeed returns the recorded chapters that are past the user's progress, grouped by manga.
+// Manga that are no longer in the collection are left out.
+func (c *ChapterUpdateChecker) GetUnreadFeed(mangaCollection *anilist.MangaCollection) ([]*ChapterUpdateFeedItem, error) {
+	updates, err := c.database.GetMangaChapterUpdates()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]*ChapterUpdateFeedItem, 0)
+	itemMap := make(map[int]*ChapterUpdateFeedItem)
+	for _, update := range updates {
+		entry, found := mangaCollection.GetListEntryFromMangaId(update.MediaId)
+		if !found {
+			continue
+		}
+
+		progress := 0
+		if entry.GetProgress() != nil {
+			progress = *entry.GetProgress()
+		}
+		if !isChapterUnread(update.ChapterNumber, progress) {
+			continue
+		}
+
+		item, ok := itemMap[update.MediaId]
+		if !ok {
+			item = &ChapterUpdateFeedItem{
+				MediaId:  update.MediaId,
+				Media:    entry.GetMedia(),
+				Progress: progress,
+				Chapters: make([]*models.MangaChapterUpdate, 0),
+			}
+			itemMap[update.MediaId] = item
+			// Updates are sorted by date, so the manga with the newest chapters come first
+			ret = append(ret, item)
+		}
+		item.Chapters = append(item.Chapters, update)
+	}
+
+	return ret, nil
+}
+
+// DismissUpdates hides the chapter updates from the feed, all of them if no IDs are given.
+func (c *ChapterUpdateChecker) DismissUpdates(ids []uint) error {
+	return c.database.DismissMangaChapterUpdates(ids)
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// getLastUsedProviders returns the provider of the most recently cached chapter container for each manga.
+func (r *Repository) getLastUsedProviders() map[int]string {
+	ret := make(map[int]string)
+
+	entries, err := os.ReadDir(r.cacheDir)
+	if err != nil {
+		return ret
+	}
+
+	lastModTimes := make(map[int]time.Time)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		provider, bucketType, mediaId, ok := ParseChapterContainerFileName(entry.Name())
+		if !ok || bucketType != bucketTypeChapter || provider == MergedProvider {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(lastModTimes[mediaId]) {
+			lastModTimes[mediaId] = info.ModTime()
+			ret[mediaId] = provider
+		}
+	}
+
+	return ret
+}
+
+// getKnownChapters returns the IDs and chapter numbers of the container's chapters.
+func getKnownChapters(container *ChapterContainer) []*db.MangaKnownChapter {
+	ret := make([]*db.MangaKnownChapter, 0, len(container.Chapters))
+	for _, chapter := range container.Chapters {
+		ret = append(ret, &db.MangaKnownChapter{
+			ID:     chapter.ID,
+			Number: manga_providers.GetNormalizedChapter(chapter.Chapter),
+		})
+	}
+	return ret
+}
+
+// getNewChapters returns the chapters of the current container whose ID and chapter number weren't known.
+// Only one chapter is returned per chapter number, e.g. when a chapter is released by multiple scanlators.
+func getNewChapters(known []*db.MangaKnownChapter, current *ChapterContainer) []*hibikemanga.ChapterDetails {
+	ret := make([]*hibikemanga.ChapterDetails, 0)
+	if current == nil {
+		return ret
+	}
+
+	knownIds := make(map[string]struct{}, len(known))
+	knownNumbers := make(map[string]struct{}, len(known))
+	for _, chapter := range known {
+		knownIds[chapter.ID] = struct{}{}
+		knownNumbers[chapter.Number] = struct{}{}
+	}
+
+	for _, chapter := range current.Chapters {
+		if _, ok := knownIds[chapter.ID]; ok {
+			continue
+		}
+		number := manga_providers.GetNormalizedChapter(chapter.Chapter)
+		if _, ok := knownNumbers[number]; ok {
+			continue
+		}
+		knownNumbers[number] = struct{}{}
+		ret = append(ret, chapter)
+	}
+
+	return ret
+}
+
+// isChapterUnread returns true if the chapter number is past the progress.
+// Chapters with a number that can't be parsed are considered unread.
+func isChapterUnread(chapterNumber string, progress int) bool {
+	number, err := strconv.ParseFloat(chapterNumber, 64)
+	if err != nil {
+		return true
+	}
+	return number > float64(progress)
+}
+
+// formatChapterUpdatesNotification returns the notification message, e.g. "New chapters: One Piece (2), Blue Lock (1)"
+func formatChapterUpdatesNotification(updates []*models.MangaChapterUpdate, mediaTitles map[int]string) string {
+	counts := make(map[int]int)
+	mediaIds := make([]int, 0)
+	for _, update := range updates {
+		if _, ok := counts[update.MediaId]; !ok {
+			mediaIds = append(mediaIds, update.MediaId)
+		}
+		counts[update.MediaId]++
+	}
+	slices.Sort(mediaIds)
+
+	parts := make([]string, 0, len(mediaIds))
+	for _, mediaId := range mediaIds {
+		parts = append(parts, fmt.Sprintf("%s (%d)", mediaTitles[mediaId], counts[mediaId]))
+	}
+
+	return "New chapters: " + strings.Join(parts, ", ")
+}
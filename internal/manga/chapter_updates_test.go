@@ -0,0 +1,78 @@
+package manga
+
+import (
+	hibikemanga "seanime/internal/extension/hibike/manga"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetNewChapters(t *testing.T) {
+	previous := &ChapterContainer{
+		Chapters: []*hibikemanga.ChapterDetails{
+			{ID: "a$1", Chapter: "1"},
+			{ID: "a$2", Chapter: "2"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		current  []*hibikemanga.ChapterDetails
+		expected []string
+	}{
+		{
+			name: "no new chapters",
+			current: []*hibikemanga.ChapterDetails{
+				{ID: "a$1", Chapter: "1"},
+				{ID: "a$2", Chapter: "2"},
+			},
+			expected: []string{},
+		},
+		{
+			name: "new chapters",
+			current: []*hibikemanga.ChapterDetails{
+				{ID: "a$1", Chapter: "1"},
+				{ID: "a$2", Chapter: "2"},
+				{ID: "a$3", Chapter: "3"},
+				{ID: "a$3.5", Chapter: "3.5"},
+			},
+			expected: []string{"a$3", "a$3.5"},
+		},
+		{
+			name: "known chapter with a new ID",
+			current: []*hibikemanga.ChapterDetails{
+				{ID: "b$1", Chapter: "0001"},
+				{ID: "a$2", Chapter: "2"},
+			},
+			expected: []string{},
+		},
+		{
+			name: "new chapter released by multiple scanlators",
+			current: []*hibikemanga.ChapterDetails{
+				{ID: "a$1", Chapter: "1"},
+				{ID: "a$2", Chapter: "2"},
+				{ID: "a$3$group-1", Chapter: "3"},
+				{ID: "a$3$group-2", Chapter: "3"},
+			},
+			expected: []string{"a$3$group-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ret := getNewChapters(getKnownChapters(previous), &ChapterContainer{Chapters: tt.current})
+			require.Equal(t, tt.expected, lo.Map(ret, func(c *hibikemanga.ChapterDetails, _ int) string {
+				return c.ID
+			}))
+		})
+	}
+}
+
+func TestIsChapterUnread(t *testing.T) {
+	require.True(t, isChapterUnread("11", 10))
+	require.True(t, isChapterUnread("10.5", 10))
+	require.False(t, isChapterUnread("10", 10))
+	require.False(t, isChapterUnread("3", 10))
+	require.True(t, isChapterUnread("extra", 10))
+}
@@ -16,6 +16,7 @@ import (
 	"sync"
 
 	"github.com/rs/zerolog"
+	"github.com/samber/mo"
 )
 
 type (
@@ -33,6 +34,10 @@ type (
 
 		chapterDownloadedCh chan chapter_downloader.DownloadID
 		readingDownloadDir  bool
+
+		getMedia   func(mediaId int) (*anilist.BaseManga, error) // Used for the export metadata
+		settings   mo.Option[*models.MangaSettings]
+		settingsMu sync.RWMutex
 	}
 
 	// MediaMap is created after reading the download directory.
@@ -66,6 +71,7 @@ type (
 		WSEventManager events.WSEventManagerInterface
 		DownloadDir    string
 		Repository     *Repository
+		GetMedia       func(mediaId int) (*anilist.BaseManga, error)
 	}
 
 	DownloadChapterOptions struct {
@@ -88,6 +94,8 @@ func NewDownloader(opts *NewDownloaderOptions) *Downloader {
 		repository:     opts.Repository,
 		mediaMap:       new(MediaMap),
 		filecacher:     filecacher,
+		getMedia:       opts.GetMedia,
+		settings:       mo.None[*models.MangaSettings](),
 	}
 
 	d.chapterDownloader = chapter_downloader.NewDownloader(&chapter_downloader.NewDownloaderOptions{
@@ -102,6 +110,15 @@ func NewDownloader(opts *NewDownloaderOptions) *Downloader {
 	return d
 }
 
+func (d *Downloader) SetSettings(settings *models.MangaSettings) {
+	if d == nil || settings == nil {
+		return
+	}
+	d.settingsMu.Lock()
+	d.settings = mo.Some(settings)
+	d.settingsMu.Unlock()
+}
+
 // Start is called once to start the Chapter downloader 's main goroutine.
 func (d *Downloader) Start() {
 	d.chapterDownloader.Start()
@@ -126,6 +143,9 @@ func (d *Downloader) Start() {
 
 				// Refresh the media map when a chapter is downloaded
 				d.hydrateMediaMap()
+
+				// Export the chapter to the "send to device" folder if enabled
+				go d.autoExportChapter(downloadId)
 			}
 		}
 	}()
@@ -178,6 +198,9 @@ func (r *Repository) getChapterContainerFromPermanentFilecache(provider string,
 // and invokes the chapter_downloader.Downloader 'Download' method to add the chapter to the download queue.
 func (d *Downloader) DownloadChapter(opts DownloadChapterOptions) error {
 
+	// Chapters of the merged chapter list are downloaded from the provider that supplies them
+	opts.Provider = d.repository.resolveChapterProvider(opts.Provider, opts.MediaId, opts.ChapterId)
+
 	chapterContainer, found := d.repository.getChapterContainerFromFilecache(opts.Provider, opts.MediaId)
 	if !found {
 		return errors.New("chapters not found")
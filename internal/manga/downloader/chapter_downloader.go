@@ -13,6 +13,7 @@ import (
 	"seanime/internal/database/db"
 	"seanime/internal/events"
 	hibikemanga "seanime/internal/extension/hibike/manga"
+	"seanime/internal/hook"
 	manga_providers "seanime/internal/manga/providers"
 	"seanime/internal/util"
 	"strconv"
@@ -131,6 +132,25 @@ func (cd *Downloader) AddToQueue(opts DownloadOptions) error {
 
 	downloadId := opts.DownloadID
 
+	// Trigger hook event
+	event := &MangaChapterDownloadQueuedEvent{
+		Provider:      downloadId.Provider,
+		MediaId:       downloadId.MediaId,
+		ChapterId:     downloadId.ChapterId,
+		ChapterNumber: downloadId.ChapterNumber,
+		Pages:         opts.Pages,
+	}
+	err := hook.GlobalHookManager.OnMangaChapterDownloadQueued().Trigger(event)
+	if err != nil {
+		cd.logger.Error().Err(err).Msg("chapter downloader: Exception occurred while triggering hook event")
+		return err
+	}
+
+	if event.DefaultPrevented {
+		cd.logger.Debug().Msgf("chapter downloader: Adding chapter %s to the queue prevented by hook", downloadId.ChapterId)
+		return nil
+	}
+
 	// Check if chapter is already downloaded
 	registryPath := cd.getChapterRegistryPath(downloadId)
 	if _, err := os.Stat(registryPath); err == nil {
@@ -142,7 +162,7 @@ func (cd *Downloader) AddToQueue(opts DownloadOptions) error {
 	// Start download
 	cd.logger.Debug().Msgf("chapter downloader: Adding chapter to download queue: %s", opts.ChapterId)
 	// Add to queue
-	return cd.queue.Add(downloadId, opts.Pages, opts.StartNow)
+	return cd.queue.Add(downloadId, event.Pages, opts.StartNow)
 }
 
 // DeleteChapter deletes a chapter directory from the download directory.
@@ -196,11 +216,48 @@ func (cd *Downloader) run(queueInfo *QueueInfo) {
 		cd.logger.Error().Msg("chapter downloader: Panic in 'run'")
 	})
 
+	destination := cd.getChapterDownloadDir(queueInfo.DownloadID)
+
+	// Trigger hook event
+	event := &MangaChapterDownloadRequestedEvent{
+		Provider:      queueInfo.Provider,
+		MediaId:       queueInfo.MediaId,
+		ChapterId:     queueInfo.ChapterId,
+		ChapterNumber: queueInfo.ChapterNumber,
+		Destination:   destination,
+	}
+	err := hook.GlobalHookManager.OnMangaChapterDownloadRequested().Trigger(event)
+	if err != nil {
+		cd.logger.Error().Err(err).Msg("chapter downloader: Exception occurred while triggering hook event")
+		queueInfo.Status = QueueStatusErrored
+		cd.queue.HasCompleted(queueInfo)
+		return
+	}
+
+	if event.DefaultPrevented {
+		cd.logger.Debug().Msgf("chapter downloader: Download of chapter %s prevented by hook", queueInfo.ChapterId)
+		cd.queue.HasCompleted(queueInfo)
+		return
+	}
+	destination = event.Destination
+
 	// Download chapter images
-	if err := cd.downloadChapterImages(queueInfo); err != nil {
+	if err := cd.downloadChapterImages(queueInfo, destination); err != nil {
 		return
 	}
 
+	// Trigger hook event
+	err = hook.GlobalHookManager.OnMangaChapterDownloaded().Trigger(&MangaChapterDownloadedEvent{
+		Provider:      queueInfo.Provider,
+		MediaId:       queueInfo.MediaId,
+		ChapterId:     queueInfo.ChapterId,
+		ChapterNumber: queueInfo.ChapterNumber,
+		Destination:   destination,
+	})
+	if err != nil {
+		cd.logger.Error().Err(err).Msg("chapter downloader: Exception occurred while triggering hook event")
+	}
+
 	cd.chapterDownloadedCh <- queueInfo.DownloadID
 }
 
@@ -213,11 +270,10 @@ func (cd *Downloader) run(queueInfo *QueueInfo) {
 //	   ├── 📄 1.jpg
 //	   ├── 📄 2.jpg
 //	   └── 📄 ...
-func (cd *Downloader) downloadChapterImages(queueInfo *QueueInfo) (err error) {
+func (cd *Downloader) downloadChapterImages(queueInfo *QueueInfo, destination string) (err error) {
 
 	// Create download directory
 	// 📁 {provider}_{mediaId}_{chapterId}
-	destination := cd.getChapterDownloadDir(queueInfo.DownloadID)
 	if err = os.MkdirAll(destination, os.ModePerm); err != nil {
 		cd.logger.Error().Err(err).Msgf("chapter downloader: Failed to create download directory for chapter %s", queueInfo.ChapterId)
 		return err
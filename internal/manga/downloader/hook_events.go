@@ -0,0 +1,41 @@
+package chapter_downloader
+
+import (
+	hibikemanga "seanime/internal/extension/hibike/manga"
+	"seanime/internal/hook_resolver"
+)
+
+// MangaChapterDownloadQueuedEvent is triggered when a chapter is about to be added to the download queue.
+// The pages can be modified.
+// Prevent default to skip adding the chapter to the queue.
+type MangaChapterDownloadQueuedEvent struct {
+	hook_resolver.Event
+	Provider      string                     `json:"provider"`
+	MediaId       int                        `json:"mediaId"`
+	ChapterId     string                     `json:"chapterId"`
+	ChapterNumber string                     `json:"chapterNumber"`
+	Pages         []*hibikemanga.ChapterPage `json:"pages"`
+}
+
+// MangaChapterDownloadRequestedEvent is triggered when a chapter of the queue is about to be downloaded.
+// The destination directory of the pages can be modified.
+// Seanime only lists the chapters stored in the download directory, a chapter downloaded elsewhere will not appear as downloaded.
+// Prevent default to skip the download, the chapter will be removed from the queue.
+type MangaChapterDownloadRequestedEvent struct {
+	hook_resolver.Event
+	Provider      string `json:"provider"`
+	MediaId       int    `json:"mediaId"`
+	ChapterId     string `json:"chapterId"`
+	ChapterNumber string `json:"chapterNumber"`
+	Destination   string `json:"destination"`
+}
+
+// MangaChapterDownloadedEvent is triggered after a chapter has been downloaded.
+type MangaChapterDownloadedEvent struct {
+	hook_resolver.Event
+	Provider      string `json:"provider"`
+	MediaId       int    `json:"mediaId"`
+	ChapterId     string `json:"chapterId"`
+	ChapterNumber string `json:"chapterNumber"`
+	Destination   string `json:"destination"`
+}
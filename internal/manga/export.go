@@ -0,0 +1,275 @@
+package manga
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"seanime/internal/api/anilist"
+	"seanime/internal/database/models"
+	chapter_downloader "seanime/internal/manga/downloader"
+	manga_exporter "seanime/internal/manga/exporter"
+	"seanime/internal/util"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const exportsDirName = "exports"
+
+var ErrExportNotFound = errors.New("manga: Export not found")
+
+type (
+	ExportChaptersOptions struct {
+		MediaId    int                   `json:"mediaId"`
+		Provider   string                `json:"provider"`
+		ChapterIds []string              `json:"chapterIds"`
+		Format     manga_exporter.Format `json:"format"`
+		// SingleFile packages all the chapters in one file instead of one file per chapter
+		SingleFile bool `json:"singleFile"`
+		// Volume is the volume the chapters belong to, they are packaged in one file.
+		// Providers don't give volume information so the client selects the chapters of the volume.
+		Volume      int                         `json:"volume"`
+		Image       manga_exporter.ImageOptions `json:"image"`
+		RightToLeft bool                        `json:"rightToLeft"`
+		// SendToDevice writes the exports to the "send to device" folder instead of the exports folder
+		SendToDevice bool `json:"sendToDevice"`
+	}
+
+	ExportedFile struct {
+		Filename string `json:"filename"`
+		Path     string `json:"path"`
+	}
+)
+
+// ExportChapters packages downloaded chapters into CBZ or EPUB files.
+func (d *Downloader) ExportChapters(opts *ExportChaptersOptions) (ret []*ExportedFile, err error) {
+	defer util.HandlePanicInModuleWithError("manga/ExportChapters", &err)
+
+	if !opts.Format.IsValid() {
+		return nil, manga_exporter.ErrInvalidFormat
+	}
+
+	ids := make([]chapter_downloader.DownloadID, 0, len(opts.ChapterIds))
+	d.mediaMapMu.RLock()
+	downloaded := (*d.mediaMap)[opts.MediaId][opts.Provider]
+	d.mediaMapMu.RUnlock()
+	for _, chapterId := range opts.ChapterIds {
+		idx := slices.IndexFunc(downloaded, func(c ProviderDownloadMapChapterInfo) bool {
+			return c.ChapterID == chapterId
+		})
+		if idx == -1 {
+			return nil, fmt.Errorf("manga: Chapter %s is not downloaded", chapterId)
+		}
+		ids = append(ids, chapter_downloader.DownloadID{
+			Provider:      opts.Provider,
+			MediaId:       opts.MediaId,
+			ChapterId:     chapterId,
+			ChapterNumber: downloaded[idx].ChapterNumber,
+		})
+	}
+	if len(ids) == 0 {
+		return nil, manga_exporter.ErrNoChapters
+	}
+
+	// Chapters are exported in reading order
+	slices.SortStableFunc(ids, func(a, b chapter_downloader.DownloadID) int {
+		return compareChapterNumbers(a.ChapterNumber, b.ChapterNumber)
+	})
+
+	dir, err := d.getExportDir(opts.SendToDevice)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := d.getExportMetadata(opts.MediaId)
+	metadata.Volume = opts.Volume
+
+	// Group the chapters by file
+	groups := make([][]chapter_downloader.DownloadID, 0)
+	if opts.SingleFile || opts.Volume > 0 {
+		groups = append(groups, ids)
+	} else {
+		for _, id := range ids {
+			groups = append(groups, []chapter_downloader.DownloadID{id})
+		}
+	}
+
+	d.logger.Debug().Int("mediaId", opts.MediaId).Str("format", string(opts.Format)).Int("files", len(groups)).Msg("manga downloader: Exporting chapters")
+
+	ret = make([]*ExportedFile, 0, len(groups))
+	for _, group := range groups {
+		exportOpts := &manga_exporter.Options{
+			Format:      opts.Format,
+			Metadata:    metadata,
+			Chapters:    d.getExportChapters(group),
+			Image:       opts.Image,
+			RightToLeft: opts.RightToLeft,
+		}
+
+		filename := manga_exporter.Filename(exportOpts)
+		path := filepath.Join(dir, filename)
+		if err = manga_exporter.ExportToFile(path, exportOpts); err != nil {
+			return ret, err
+		}
+
+		d.logger.Info().Str("path", path).Msg("manga downloader: Exported chapters")
+		ret = append(ret, &ExportedFile{
+			Filename: filename,
+			Path:     path,
+		})
+	}
+
+	return ret, nil
+}
+
+// GetExportPath returns the path of a file in the exports folder.
+func (d *Downloader) GetExportPath(filename string) (string, error) {
+	// Prevent path traversal
+	if filename == "" || filename != filepath.Base(filename) {
+		return "", ErrExportNotFound
+	}
+
+	path := filepath.Join(d.downloadDir, exportsDirName, filename)
+	if _, err := os.Stat(path); err != nil {
+		return "", ErrExportNotFound
+	}
+
+	return path, nil
+}
+
+// autoExportChapter exports a chapter to the "send to device" folder after it has been downloaded.
+func (d *Downloader) autoExportChapter(id chapter_downloader.DownloadID) {
+	defer util.HandlePanicInModuleThen("manga/autoExportChapter", func() {})
+
+	settings, ok := d.getSettings()
+	if !ok || settings.ExportDir == "" || !manga_exporter.Format(settings.AutoExportFormat).IsValid() {
+		return
+	}
+
+	metadata := d.getExportMetadata(id.MediaId)
+	exportOpts := &manga_exporter.Options{
+		Format:   manga_exporter.Format(settings.AutoExportFormat),
+		Metadata: metadata,
+		Chapters: d.getExportChapters([]chapter_downloader.DownloadID{id}),
+		Image: manga_exporter.ImageOptions{
+			MaxWidth:  settings.ExportMaxWidth,
+			MaxHeight: settings.ExportMaxHeight,
+			Grayscale: settings.ExportGrayscale,
+		},
+	}
+
+	path := filepath.Join(settings.ExportDir, manga_exporter.Filename(exportOpts))
+	if err := manga_exporter.ExportToFile(path, exportOpts); err != nil {
+		d.logger.Error().Err(err).Str("chapterId", id.ChapterId).Msg("manga downloader: Failed to export downloaded chapter")
+		return
+	}
+
+	d.logger.Info().Str("path", path).Msg("manga downloader: Exported downloaded chapter")
+}
+
+// getExportDir returns the folder the exports are written to.
+func (d *Downloader) getExportDir(sendToDevice bool) (string, error) {
+	if sendToDevice {
+		settings, ok := d.getSettings()
+		if !ok || settings.ExportDir == "" {
+			return "", errors.New("manga: The \"send to device\" folder is not set")
+		}
+		return settings.ExportDir, nil
+	}
+	return filepath.Join(d.downloadDir, exportsDirName), nil
+}
+
+// getExportChapters returns the chapters to export with their titles from the chapter container.
+func (d *Downloader) getExportChapters(ids []chapter_downloader.DownloadID) []*manga_exporter.Chapter {
+	ret := make([]*manga_exporter.Chapter, 0, len(ids))
+	for _, id := range ids {
+		chapter := &manga_exporter.Chapter{
+			Number: id.ChapterNumber,
+			Dir:    filepath.Join(d.downloadDir, chapter_downloader.FormatChapterDirName(id.Provider, id.MediaId, id.ChapterId, id.ChapterNumber)),
+		}
+		if container, found := d.repository.getChapterContainerFromPermanentFilecache(id.Provider, id.MediaId); found {
+			if details, ok := container.GetChapter(id.ChapterId); ok {
+				chapter.Title = details.Title
+			}
+		}
+		ret = append(ret, chapter)
+	}
+	return ret
+}
+
+// getExportMetadata returns the metadata of the manga from AniList.
+// The export is still created without metadata if the manga can't be fetched.
+func (d *Downloader) getExportMetadata(mediaId int) manga_exporter.Metadata {
+	ret := manga_exporter.Metadata{
+		Series: "Manga " + strconv.Itoa(mediaId),
+	}
+	if d.getMedia == nil {
+		return ret
+	}
+
+	media, err := d.getMedia(mediaId)
+	if err != nil || media == nil {
+		d.logger.Warn().Err(err).Int("mediaId", mediaId).Msg("manga downloader: Could not fetch the manga for the export metadata")
+		return ret
+	}
+
+	return newExportMetadata(media)
+}
+
+func (d *Downloader) getSettings() (*models.MangaSettings, bool) {
+	d.settingsMu.RLock()
+	defer d.settingsMu.RUnlock()
+	return d.settings.Get()
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func newExportMetadata(media *anilist.BaseManga) manga_exporter.Metadata {
+	ret := manga_exporter.Metadata{
+		Series:      media.GetPreferredTitle(),
+		Genres:      make([]string, 0, len(media.GetGenres())),
+		Web:         fmt.Sprintf("https://anilist.co/manga/%d", media.GetID()),
+		LanguageISO: "en",
+	}
+
+	if media.GetDescription() != nil {
+		// AniList descriptions contain HTML
+		ret.Summary = util.HTMLToPlainText(*media.GetDescription())
+	}
+	for _, genre := range media.GetGenres() {
+		if genre != nil {
+			ret.Genres = append(ret.Genres, *genre)
+		}
+	}
+	if date := media.GetStartDate(); date != nil {
+		if date.GetYear() != nil {
+			ret.Year = *date.GetYear()
+		}
+		if date.GetMonth() != nil {
+			ret.Month = *date.GetMonth()
+		}
+		if date.GetDay() != nil {
+			ret.Day = *date.GetDay()
+		}
+	}
+	if media.GetChapters() != nil {
+		ret.Count = *media.GetChapters()
+	}
+	if media.GetIsAdult() != nil {
+		ret.IsAdult = *media.GetIsAdult()
+	}
+
+	return ret
+}
+
+// compareChapterNumbers compares chapter numbers numerically, e.g. "2" < "10" < "10.5"
+func compareChapterNumbers(a, b string) int {
+	af, errA := strconv.ParseFloat(a, 64)
+	bf, errB := strconv.ParseFloat(b, 64)
+	if errA != nil || errB != nil {
+		return strings.Compare(a, b)
+	}
+	return cmp.Compare(af, bf)
+}
@@ -0,0 +1,149 @@
+package manga_exporter
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type (
+	// ComicInfo is the metadata file read by comic readers.
+	// https://anansi-project.github.io/docs/comicinfo/documentation
+	ComicInfo struct {
+		XMLName     xml.Name       `xml:"ComicInfo"`
+		XMLNSXsi    string         `xml:"xmlns:xsi,attr"`
+		XMLNSXsd    string         `xml:"xmlns:xsd,attr"`
+		Title       string         `xml:"Title,omitempty"`
+		Series      string         `xml:"Series,omitempty"`
+		Number      string         `xml:"Number,omitempty"`
+		Count       int            `xml:"Count,omitempty"`
+		Volume      int            `xml:"Volume,omitempty"`
+		Summary     string         `xml:"Summary,omitempty"`
+		Year        int            `xml:"Year,omitempty"`
+		Month       int            `xml:"Month,omitempty"`
+		Day         int            `xml:"Day,omitempty"`
+		Genre       string         `xml:"Genre,omitempty"`
+		Web         string         `xml:"Web,omitempty"`
+		PageCount   int            `xml:"PageCount"`
+		LanguageISO string         `xml:"LanguageISO,omitempty"`
+		BlackWhite  string         `xml:"BlackAndWhite,omitempty"`
+		Manga       string         `xml:"Manga"`
+		AgeRating   string         `xml:"AgeRating,omitempty"`
+		Pages       ComicInfoPages `xml:"Pages"`
+	}
+
+	ComicInfoPages struct {
+		Pages []ComicInfoPage `xml:"Page"`
+	}
+
+	ComicInfoPage struct {
+		Image       int    `xml:"Image,attr"`
+		Type        string `xml:"Type,attr,omitempty"`
+		ImageWidth  int    `xml:"ImageWidth,attr,omitempty"`
+		ImageHeight int    `xml:"ImageHeight,attr,omitempty"`
+		Bookmark    string `xml:"Bookmark,attr,omitempty"` // Chapter title, set on the first page of each chapter
+	}
+)
+
+func newComicInfo(opts *Options, chapters [][]*page) *ComicInfo {
+	ret := &ComicInfo{
+		XMLNSXsi:    "http://www.w3.org/2001/XMLSchema-instance",
+		XMLNSXsd:    "http://www.w3.org/2001/XMLSchema",
+		Title:       exportTitle(opts),
+		Series:      opts.Metadata.Series,
+		Number:      chapterRange(opts),
+		Count:       opts.Metadata.Count,
+		Volume:      opts.Metadata.Volume,
+		Summary:     opts.Metadata.Summary,
+		Year:        opts.Metadata.Year,
+		Month:       opts.Metadata.Month,
+		Day:         opts.Metadata.Day,
+		Genre:       strings.Join(opts.Metadata.Genres, ", "),
+		Web:         opts.Metadata.Web,
+		LanguageISO: opts.Metadata.LanguageISO,
+		Manga:       "Yes",
+	}
+	if opts.RightToLeft {
+		ret.Manga = "YesAndRightToLeft"
+	}
+	if opts.Image.Grayscale {
+		ret.BlackWhite = "Yes"
+	}
+	if opts.Metadata.IsAdult {
+		ret.AgeRating = "Adults Only 18+"
+	}
+	// The chapter number is not set when the export is a volume
+	if opts.Metadata.Volume > 0 {
+		ret.Number = strconv.Itoa(opts.Metadata.Volume)
+	}
+
+	for i, pages := range chapters {
+		for _, p := range pages {
+			info := ComicInfoPage{
+				Image:       ret.PageCount,
+				ImageWidth:  p.width,
+				ImageHeight: p.height,
+			}
+			if ret.PageCount == 0 {
+				info.Type = "FrontCover"
+			}
+			if p.index == 0 {
+				info.Bookmark = chapterLabel(opts.Chapters[i])
+			}
+			ret.Pages.Pages = append(ret.Pages.Pages, info)
+			ret.PageCount++
+		}
+	}
+
+	return ret
+}
+
+// writeCBZ writes the pages to a zip archive with a ComicInfo.xml file.
+// Pages are named so that they are sorted in reading order, e.g. "001_001.jpeg"
+func writeCBZ(w io.Writer, opts *Options, chapters [][]*page) error {
+	zw := zip.NewWriter(w)
+
+	f, err := zw.Create("ComicInfo.xml")
+	if err != nil {
+		return err
+	}
+	data, err := xml.MarshalIndent(newComicInfo(opts, chapters), "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err = io.WriteString(f, xml.Header); err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err != nil {
+		return err
+	}
+
+	for _, pages := range chapters {
+		for _, p := range pages {
+			// Images are already compressed
+			f, err := zw.CreateHeader(&zip.FileHeader{
+				Name:   fmt.Sprintf("%03d_%03d.%s", p.chapterIndex+1, p.index+1, p.format),
+				Method: zip.Store,
+			})
+			if err != nil {
+				return err
+			}
+			if _, err = f.Write(p.data); err != nil {
+				return err
+			}
+		}
+	}
+
+	return zw.Close()
+}
+
+// chapterLabel returns the name of the chapter shown in the table of contents.
+func chapterLabel(chapter *Chapter) string {
+	if chapter.Title != "" {
+		return chapter.Title
+	}
+	return "Chapter " + chapter.Number
+}
@@ -0,0 +1,188 @@
+package manga_exporter
+
+import (
+	"archive/zip"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 📦 {name}.epub
+// ├── 📄 mimetype
+// ├── 📁 META-INF
+// │   └── 📄 container.xml
+// └── 📁 OEBPS
+//     ├── 📄 content.opf          <- Package document, fixed layout
+//     ├── 📄 nav.xhtml            <- Table of contents, one entry per chapter
+//     ├── 📁 pages
+//     │   └── 📄 0001.xhtml       <- One document per page, sized to the image
+//     └── 📁 images
+//         └── 📄 0001.jpeg
+
+const epubContainer = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+const epubPage = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<head>
+  <title>%s</title>
+  <meta name="viewport" content="width=%d, height=%d"/>
+  <style>html, body { margin: 0; padding: 0; } img { display: block; width: %dpx; height: %dpx; }</style>
+</head>
+<body>
+  <img src="../images/%s" alt=""/>
+</body>
+</html>
+`
+
+// writeEPUB writes the pages to a fixed-layout EPUB 3 book.
+func writeEPUB(w io.Writer, opts *Options, chapters [][]*page) error {
+	zw := zip.NewWriter(w)
+
+	// The mimetype file must be the first file and must not be compressed
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err = io.WriteString(f, "application/epub+zip"); err != nil {
+		return err
+	}
+
+	if err = writeZipFile(zw, "META-INF/container.xml", epubContainer); err != nil {
+		return err
+	}
+
+	title := exportTitle(opts)
+
+	manifest := strings.Builder{}
+	spine := strings.Builder{}
+	toc := strings.Builder{}
+
+	n := 0
+	for i, pages := range chapters {
+		for _, p := range pages {
+			n++
+			imageName := fmt.Sprintf("%04d.%s", n, p.format)
+			pageName := fmt.Sprintf("%04d.xhtml", n)
+
+			// Images are already compressed
+			imgFile, err := zw.CreateHeader(&zip.FileHeader{Name: "OEBPS/images/" + imageName, Method: zip.Store})
+			if err != nil {
+				return err
+			}
+			if _, err = imgFile.Write(p.data); err != nil {
+				return err
+			}
+
+			pageContent := fmt.Sprintf(epubPage, html.EscapeString(title), p.width, p.height, p.width, p.height, imageName)
+			if err = writeZipFile(zw, "OEBPS/pages/"+pageName, pageContent); err != nil {
+				return err
+			}
+
+			imageProperties := ""
+			if n == 1 {
+				imageProperties = ` properties="cover-image"`
+			}
+			manifest.WriteString(fmt.Sprintf("    <item id=\"img%04d\" href=\"images/%s\" media-type=\"image/%s\"%s/>\n", n, imageName, p.format, imageProperties))
+			manifest.WriteString(fmt.Sprintf("    <item id=\"page%04d\" href=\"pages/%s\" media-type=\"application/xhtml+xml\"/>\n", n, pageName))
+			spine.WriteString(fmt.Sprintf("    <itemref idref=\"page%04d\"/>\n", n))
+
+			if p.index == 0 {
+				toc.WriteString(fmt.Sprintf("      <li><a href=\"pages/%s\">%s</a></li>\n", pageName, html.EscapeString(chapterLabel(opts.Chapters[i]))))
+			}
+		}
+	}
+
+	nav := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<head>
+  <title>%s</title>
+</head>
+<body>
+  <nav epub:type="toc" id="toc">
+    <ol>
+%s    </ol>
+  </nav>
+</body>
+</html>
+`, html.EscapeString(title), toc.String())
+	if err = writeZipFile(zw, "OEBPS/nav.xhtml", nav); err != nil {
+		return err
+	}
+
+	if err = writeZipFile(zw, "OEBPS/content.opf", epubPackageDocument(opts, title, manifest.String(), spine.String())); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func epubPackageDocument(opts *Options, title string, manifest string, spine string) string {
+	language := opts.Metadata.LanguageISO
+	if language == "" {
+		language = "en"
+	}
+
+	progression := "ltr"
+	if opts.RightToLeft {
+		progression = "rtl"
+	}
+
+	metadata := strings.Builder{}
+	metadata.WriteString(fmt.Sprintf("    <dc:identifier id=\"book-id\">urn:uuid:%s</dc:identifier>\n", uuid.NewString()))
+	metadata.WriteString(fmt.Sprintf("    <dc:title>%s</dc:title>\n", html.EscapeString(title)))
+	metadata.WriteString(fmt.Sprintf("    <dc:language>%s</dc:language>\n", html.EscapeString(language)))
+	if opts.Metadata.Summary != "" {
+		metadata.WriteString(fmt.Sprintf("    <dc:description>%s</dc:description>\n", html.EscapeString(opts.Metadata.Summary)))
+	}
+	for _, genre := range opts.Metadata.Genres {
+		metadata.WriteString(fmt.Sprintf("    <dc:subject>%s</dc:subject>\n", html.EscapeString(genre)))
+	}
+	if opts.Metadata.Year > 0 {
+		metadata.WriteString(fmt.Sprintf("    <dc:date>%04d</dc:date>\n", opts.Metadata.Year))
+	}
+	if opts.Metadata.Series != "" {
+		// Lets readers group the books by series
+		metadata.WriteString(fmt.Sprintf("    <meta property=\"belongs-to-collection\" id=\"series\">%s</meta>\n", html.EscapeString(opts.Metadata.Series)))
+		metadata.WriteString("    <meta refines=\"#series\" property=\"collection-type\">series</meta>\n")
+		if opts.Metadata.Volume > 0 {
+			metadata.WriteString(fmt.Sprintf("    <meta refines=\"#series\" property=\"group-position\">%d</meta>\n", opts.Metadata.Volume))
+		}
+	}
+	metadata.WriteString(fmt.Sprintf("    <meta property=\"dcterms:modified\">%s</meta>\n", time.Now().UTC().Format("2006-01-02T15:04:05Z")))
+	metadata.WriteString("    <meta property=\"rendition:layout\">pre-paginated</meta>\n")
+	metadata.WriteString("    <meta property=\"rendition:orientation\">auto</meta>\n")
+	metadata.WriteString("    <meta property=\"rendition:spread\">none</meta>\n")
+
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" prefix="rendition: http://www.idpf.org/vocab/rendition/#">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+%s  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+%s  </manifest>
+  <spine page-progression-direction="%s">
+%s  </spine>
+</package>
+`, metadata.String(), manifest, progression, spine)
+}
+
+func writeZipFile(zw *zip.Writer, name string, content string) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(f, content)
+	return err
+}
@@ -0,0 +1,249 @@
+package manga_exporter
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	chapter_downloader "seanime/internal/manga/downloader"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-json"
+)
+
+// Exports are created from the chapters downloaded by chapter_downloader.Downloader.
+//
+// 📁 {provider}_{mediaId}_{chapterId}_{chapterNumber}
+// ├── 📄 registry.json   <- Page order and dimensions
+// ├── 📄 01.jpeg
+// └── 📄 ...
+//
+// CBZ: A zip archive of the pages with a ComicInfo.xml file.
+// EPUB: A fixed-layout EPUB 3 book with one XHTML document per page.
+
+type Format string
+
+const (
+	FormatCBZ  Format = "cbz"
+	FormatEPUB Format = "epub"
+)
+
+var (
+	ErrInvalidFormat = errors.New("manga exporter: Invalid export format")
+	ErrNoChapters    = errors.New("manga exporter: No chapters to export")
+)
+
+type (
+	// Chapter is a downloaded chapter.
+	Chapter struct {
+		// Number is the normalized chapter number, e.g. "1", "10.5"
+		Number string
+		Title  string
+		// Dir is the path to the chapter directory
+		Dir string
+	}
+
+	// Metadata is written to ComicInfo.xml and the EPUB package document.
+	Metadata struct {
+		Series      string
+		Summary     string
+		Genres      []string
+		Year        int
+		Month       int
+		Day         int
+		Volume      int // 0 if the export is not a volume
+		Count       int // Total number of chapters, 0 if unknown
+		Web         string
+		LanguageISO string
+		IsAdult     bool
+	}
+
+	ImageOptions struct {
+		// MaxWidth and MaxHeight are the dimensions the pages are resized to fit in, 0 means no limit.
+		// Pages are never upscaled.
+		MaxWidth  int `json:"maxWidth"`
+		MaxHeight int `json:"maxHeight"`
+		// Grayscale is useful for e-ink devices since it reduces the file size.
+		Grayscale bool `json:"grayscale"`
+		// Quality of the JPEG encoding of processed pages, from 1 to 100. Defaults to 85.
+		Quality int `json:"quality"`
+	}
+
+	Options struct {
+		Format   Format
+		Metadata Metadata
+		// Chapters are exported in the given order
+		Chapters    []*Chapter
+		Image       ImageOptions
+		RightToLeft bool
+	}
+
+	// page is a page loaded in memory, ready to be written.
+	page struct {
+		chapterIndex int
+		index        int
+		data         []byte
+		format       string // e.g. "jpeg", "png"
+		width        int
+		height       int
+	}
+)
+
+func (f Format) IsValid() bool {
+	return f == FormatCBZ || f == FormatEPUB
+}
+
+// Export writes the chapters to w in the given format.
+func Export(w io.Writer, opts *Options) error {
+	if opts == nil || !opts.Format.IsValid() {
+		return ErrInvalidFormat
+	}
+	if len(opts.Chapters) == 0 {
+		return ErrNoChapters
+	}
+
+	chapters := make([][]*page, 0, len(opts.Chapters))
+	for i, chapter := range opts.Chapters {
+		pages, err := loadChapterPages(i, chapter, opts.Image, opts.Format)
+		if err != nil {
+			return err
+		}
+		chapters = append(chapters, pages)
+	}
+
+	switch opts.Format {
+	case FormatCBZ:
+		return writeCBZ(w, opts, chapters)
+	case FormatEPUB:
+		return writeEPUB(w, opts, chapters)
+	}
+
+	return ErrInvalidFormat
+}
+
+// ExportToFile writes the export to a file.
+// The export is written to a temporary file first so that a partial file is never left behind.
+func ExportToFile(path string, opts *Options) (err error) {
+	if err = os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	if err = Export(f, opts); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
+
+// loadChapterPages reads the registry of the chapter and loads its pages in order.
+func loadChapterPages(chapterIndex int, chapter *Chapter, imageOpts ImageOptions, format Format) ([]*page, error) {
+	data, err := os.ReadFile(filepath.Join(chapter.Dir, "registry.json"))
+	if err != nil {
+		return nil, fmt.Errorf("manga exporter: Failed to read registry of chapter %s, %w", chapter.Number, err)
+	}
+
+	var registry chapter_downloader.Registry
+	if err = json.Unmarshal(data, &registry); err != nil {
+		return nil, fmt.Errorf("manga exporter: Failed to decode registry of chapter %s, %w", chapter.Number, err)
+	}
+
+	infos := make([]chapter_downloader.PageInfo, 0, len(registry))
+	for _, info := range registry {
+		infos = append(infos, info)
+	}
+	slices.SortFunc(infos, func(a, b chapter_downloader.PageInfo) int {
+		return cmp.Compare(a.Index, b.Index)
+	})
+
+	ret := make([]*page, 0, len(infos))
+	for i, info := range infos {
+		buf, err := os.ReadFile(filepath.Join(chapter.Dir, info.Filename))
+		if err != nil {
+			return nil, fmt.Errorf("manga exporter: Failed to read page %s of chapter %s, %w", info.Filename, chapter.Number, err)
+		}
+
+		p := &page{
+			chapterIndex: chapterIndex,
+			index:        i,
+			data:         buf,
+			format:       strings.TrimPrefix(filepath.Ext(info.Filename), "."),
+			width:        info.Width,
+			height:       info.Height,
+		}
+		if err = processPage(p, imageOpts, format); err != nil {
+			return nil, fmt.Errorf("manga exporter: Failed to process page %s of chapter %s, %w", info.Filename, chapter.Number, err)
+		}
+		ret = append(ret, p)
+	}
+
+	return ret, nil
+}
+
+// Filename returns the name of the export file.
+// e.g. "One Piece - Vol. 01.cbz", "One Piece - Ch. 1090.epub", "One Piece - Ch. 1-10.cbz"
+func Filename(opts *Options) string {
+	name := sanitizeFilename(opts.Metadata.Series)
+	if name == "" {
+		name = "Manga"
+	}
+
+	if opts.Metadata.Volume > 0 {
+		name += fmt.Sprintf(" - Vol. %02d", opts.Metadata.Volume)
+	} else if r := chapterRange(opts); r != "" {
+		name += " - Ch. " + r
+	}
+
+	return name + "." + string(opts.Format)
+}
+
+// chapterRange returns the chapter number of the export, e.g. "12" or "1-10"
+func chapterRange(opts *Options) string {
+	if len(opts.Chapters) == 0 {
+		return ""
+	}
+	if len(opts.Chapters) == 1 {
+		return opts.Chapters[0].Number
+	}
+	return opts.Chapters[0].Number + "-" + opts.Chapters[len(opts.Chapters)-1].Number
+}
+
+// exportTitle returns the title of the book, e.g. "One Piece Vol. 1", "One Piece Ch. 1090"
+func exportTitle(opts *Options) string {
+	if opts.Metadata.Volume > 0 {
+		return opts.Metadata.Series + " Vol. " + strconv.Itoa(opts.Metadata.Volume)
+	}
+	return opts.Metadata.Series + " Ch. " + chapterRange(opts)
+}
+
+func sanitizeFilename(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return -1
+		}
+		if r < 32 {
+			return -1
+		}
+		return r
+	}, name)
+	return strings.TrimSpace(name)
+}
@@ -0,0 +1,152 @@
+package manga_exporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	chapter_downloader "seanime/internal/manga/downloader"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/require"
+)
+
+// createChapter creates a downloaded chapter with the given number of pages.
+func createChapter(t *testing.T, number string, pages int) *Chapter {
+	dir := filepath.Join(t.TempDir(), "comick_1_abc_"+number)
+	require.NoError(t, os.MkdirAll(dir, os.ModePerm))
+
+	registry := make(chapter_downloader.Registry)
+	for i := 0; i < pages; i++ {
+		img := image.NewRGBA(image.Rect(0, 0, 200, 300))
+		img.Set(10, 10, color.RGBA{R: 255, A: 255})
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, img))
+
+		filename := fmt.Sprintf("%02d.png", i+1)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, filename), buf.Bytes(), 0644))
+		registry[i] = chapter_downloader.PageInfo{Index: i, Filename: filename, Width: 200, Height: 300}
+	}
+
+	data, err := json.Marshal(registry)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "registry.json"), data, 0644))
+
+	return &Chapter{Number: number, Dir: dir}
+}
+
+func readZip(t *testing.T, data []byte) (*zip.Reader, map[string]string) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		_ = rc.Close()
+		files[f.Name] = string(content)
+	}
+	return zr, files
+}
+
+func TestExport_CBZ(t *testing.T) {
+	var buf bytes.Buffer
+	err := Export(&buf, &Options{
+		Format: FormatCBZ,
+		Metadata: Metadata{
+			Series: "One Piece",
+			Genres: []string{"Action", "Adventure"},
+			Year:   1997,
+		},
+		Chapters:    []*Chapter{createChapter(t, "1", 2), createChapter(t, "2", 3)},
+		RightToLeft: true,
+	})
+	require.NoError(t, err)
+
+	zr, files := readZip(t, buf.Bytes())
+	require.Len(t, zr.File, 6)
+	require.Equal(t, "ComicInfo.xml", zr.File[0].Name)
+	require.Contains(t, files, "001_002.png")
+	require.Contains(t, files, "002_003.png")
+
+	comicInfo := files["ComicInfo.xml"]
+	require.Contains(t, comicInfo, "<Series>One Piece</Series>")
+	require.Contains(t, comicInfo, "<Number>1-2</Number>")
+	require.Contains(t, comicInfo, "<Genre>Action, Adventure</Genre>")
+	require.Contains(t, comicInfo, "<PageCount>5</PageCount>")
+	require.Contains(t, comicInfo, "<Manga>YesAndRightToLeft</Manga>")
+	require.Contains(t, comicInfo, `Bookmark="Chapter 2"`)
+}
+
+func TestExport_EPUB(t *testing.T) {
+	var buf bytes.Buffer
+	err := Export(&buf, &Options{
+		Format:   FormatEPUB,
+		Metadata: Metadata{Series: "One Piece", Volume: 1},
+		Chapters: []*Chapter{createChapter(t, "1", 2)},
+		Image:    ImageOptions{MaxWidth: 100, Grayscale: true},
+	})
+	require.NoError(t, err)
+
+	zr, files := readZip(t, buf.Bytes())
+	require.Equal(t, "mimetype", zr.File[0].Name)
+	require.Equal(t, zip.Store, zr.File[0].Method)
+	require.Equal(t, "application/epub+zip", files["mimetype"])
+	require.Contains(t, files, "META-INF/container.xml")
+	require.Contains(t, files, "OEBPS/nav.xhtml")
+
+	opf := files["OEBPS/content.opf"]
+	require.Contains(t, opf, "<dc:title>One Piece Vol. 1</dc:title>")
+	require.Contains(t, opf, `<meta property="rendition:layout">pre-paginated</meta>`)
+	require.Contains(t, opf, `href="images/0001.jpeg" media-type="image/jpeg" properties="cover-image"`)
+
+	// The pages are resized and converted to grayscale
+	require.Contains(t, files["OEBPS/pages/0002.xhtml"], `content="width=100, height=150"`)
+	img, format, err := image.Decode(strings.NewReader(files["OEBPS/images/0002.jpeg"]))
+	require.NoError(t, err)
+	require.Equal(t, "jpeg", format)
+	require.Equal(t, 100, img.Bounds().Dx())
+	require.IsType(t, &image.Gray{}, img)
+}
+
+func TestExport_InvalidOptions(t *testing.T) {
+	require.ErrorIs(t, Export(io.Discard, &Options{Format: "pdf"}), ErrInvalidFormat)
+	require.ErrorIs(t, Export(io.Discard, &Options{Format: FormatCBZ}), ErrNoChapters)
+}
+
+func TestFitSize(t *testing.T) {
+	tests := []struct {
+		width, height, maxWidth, maxHeight int
+		expectedWidth, expectedHeight      int
+	}{
+		{1000, 1500, 0, 0, 1000, 1500},
+		{1000, 1500, 500, 0, 500, 750},
+		{1000, 1500, 0, 600, 400, 600},
+		{1000, 1500, 500, 600, 400, 600},
+		// Never upscaled
+		{400, 600, 1072, 1448, 400, 600},
+	}
+
+	for _, tt := range tests {
+		w, h := fitSize(tt.width, tt.height, tt.maxWidth, tt.maxHeight)
+		require.Equal(t, tt.expectedWidth, w)
+		require.Equal(t, tt.expectedHeight, h)
+	}
+}
+
+func TestFilename(t *testing.T) {
+	chapters := []*Chapter{{Number: "1"}, {Number: "10"}}
+
+	require.Equal(t, "FateStay Night - Ch. 1-10.cbz", Filename(&Options{Format: FormatCBZ, Metadata: Metadata{Series: "Fate/Stay Night"}, Chapters: chapters}))
+	require.Equal(t, "Blue Lock - Ch. 10.epub", Filename(&Options{Format: FormatEPUB, Metadata: Metadata{Series: "Blue Lock"}, Chapters: chapters[1:]}))
+	require.Equal(t, "Blue Lock - Vol. 02.cbz", Filename(&Options{Format: FormatCBZ, Metadata: Metadata{Series: "Blue Lock", Volume: 2}, Chapters: chapters}))
+}
@@ -0,0 +1,87 @@
+package manga_exporter
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"slices"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // Register WebP format
+)
+
+const defaultJPEGQuality = 85
+
+// epubImageFormats are the image formats that EPUB readers are required to support.
+var epubImageFormats = []string{"jpeg", "png", "gif", "webp"}
+
+func (o ImageOptions) needsProcessing() bool {
+	return o.MaxWidth > 0 || o.MaxHeight > 0 || o.Grayscale
+}
+
+// processPage resizes and converts the page to grayscale if needed.
+// Processed pages are encoded as JPEG.
+func processPage(p *page, opts ImageOptions, format Format) error {
+	// Pages in formats that EPUB readers might not support are converted to JPEG
+	unsupported := format == FormatEPUB && !slices.Contains(epubImageFormats, p.format)
+	if !opts.needsProcessing() && !unsupported {
+		return nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(p.data))
+	if err != nil {
+		return err
+	}
+
+	bounds := img.Bounds()
+	width, height := fitSize(bounds.Dx(), bounds.Dy(), opts.MaxWidth, opts.MaxHeight)
+	if width != bounds.Dx() || height != bounds.Dy() {
+		dst := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+		img = dst
+	}
+
+	if opts.Grayscale {
+		gray := image.NewGray(img.Bounds())
+		draw.Draw(gray, gray.Bounds(), img, img.Bounds().Min, draw.Src)
+		img = gray
+	}
+
+	quality := opts.Quality
+	if quality <= 0 || quality > 100 {
+		quality = defaultJPEGQuality
+	}
+
+	var buf bytes.Buffer
+	if err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return err
+	}
+
+	p.data = buf.Bytes()
+	p.format = "jpeg"
+	p.width = img.Bounds().Dx()
+	p.height = img.Bounds().Dy()
+
+	return nil
+}
+
+// fitSize returns the dimensions of the image scaled down to fit in maxWidth x maxHeight while keeping the aspect ratio.
+// A max of 0 means no limit.
+func fitSize(width, height, maxWidth, maxHeight int) (int, int) {
+	if width <= 0 || height <= 0 {
+		return width, height
+	}
+
+	scale := 1.0
+	if maxWidth > 0 && width > maxWidth {
+		scale = min(scale, float64(maxWidth)/float64(width))
+	}
+	if maxHeight > 0 && height > maxHeight {
+		scale = min(scale, float64(maxHeight)/float64(height))
+	}
+	if scale == 1.0 {
+		return width, height
+	}
+
+	return max(1, int(float64(width)*scale+0.5)), max(1, int(float64(height)*scale+0.5))
+}
@@ -0,0 +1,180 @@
+package manga
+
+import (
+	"errors"
+	"fmt"
+	hibikemanga "seanime/internal/extension/hibike/manga"
+	manga_providers "seanime/internal/manga/providers"
+	"seanime/internal/util"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// MergedProvider is the provider ID of the chapter container that combines the chapters of several providers.
+// Each chapter of the merged container keeps the ID and provider it comes from,
+// so pages and downloads are fetched from the provider that supplies the chapter.
+const MergedProvider = "merged"
+
+var ErrNoMergeProviders = errors.New("manga: No providers to merge chapters from")
+
+type GetMergedChapterContainerOptions struct {
+	MediaId int
+	Titles  []*string
+	Year    int
+	// Providers are in order of preference, the first one is preferred
+	Providers []string
+	// Scanlators and languages are in order of preference.
+	// Chapters from other scanlators or in other languages are only used when no preferred version exists.
+	Scanlators []string
+	Languages  []string
+}
+
+// GetMergedChapterContainer returns a ChapterContainer combining the chapters of several providers.
+// Chapters are deduplicated by chapter number, the version that is kept is chosen by
+// language preference, then scanlator preference, then provider preference.
+func (r *Repository) GetMergedChapterContainer(opts *GetMergedChapterContainerOptions) (ret *ChapterContainer, err error) {
+	defer util.HandlePanicInModuleWithError("manga/GetMergedChapterContainer", &err)
+
+	providers := uniqueProviders(opts.Providers)
+	if len(providers) == 0 {
+		return nil, ErrNoMergeProviders
+	}
+
+	r.logger.Trace().Int("mediaId", opts.MediaId).Strs("providers", providers).Msg("manga: Getting merged chapters")
+
+	// Fetch the chapters of each provider
+	containers := make([]*ChapterContainer, len(providers))
+	errs := make([]error, len(providers))
+	wg := sync.WaitGroup{}
+	for i, provider := range providers {
+		wg.Add(1)
+		go func(i int, provider string) {
+			defer wg.Done()
+			containers[i], errs[i] = r.GetMangaChapterContainer(&GetMangaChapterContainerOptions{
+				Provider: provider,
+				MediaId:  opts.MediaId,
+				Titles:   opts.Titles,
+				Year:     opts.Year,
+			})
+		}(i, provider)
+	}
+	wg.Wait()
+
+	available := make([]*ChapterContainer, 0, len(containers))
+	for i, container := range containers {
+		if errs[i] != nil || container == nil {
+			r.logger.Warn().Err(errs[i]).Str("provider", providers[i]).Int("mediaId", opts.MediaId).Msg("manga: Could not get chapters for merging")
+			continue
+		}
+		available = append(available, container)
+	}
+	if len(available) == 0 {
+		return nil, fmt.Errorf("manga: No chapters found from %s", strings.Join(providers, ", "))
+	}
+
+	ret = &ChapterContainer{
+		MediaId:  opts.MediaId,
+		Provider: MergedProvider,
+		Chapters: mergeChapters(available, providers, opts.Scanlators, opts.Languages),
+	}
+
+	// Cache the container so that pages and downloads can find the provider of each chapter
+	containerBucket := r.getFcProviderBucket(MergedProvider, opts.MediaId, bucketTypeChapter)
+	_ = r.fileCacher.Set(containerBucket, getMangaChapterContainerCacheKey(MergedProvider, opts.MediaId), ret)
+
+	return ret, nil
+}
+
+// resolveChapterProvider returns the provider that supplies the chapter.
+// It returns the provider unchanged if it is not the merged provider.
+func (r *Repository) resolveChapterProvider(provider string, mediaId int, chapterId string) string {
+	if provider != MergedProvider {
+		return provider
+	}
+
+	container, found := r.getChapterContainerFromFilecache(MergedProvider, mediaId)
+	if !found {
+		return provider
+	}
+	chapter, found := container.GetChapter(chapterId)
+	if !found {
+		return provider
+	}
+
+	return chapter.Provider
+}
+
+// mergeChapters combines the chapters of the containers, keeping one chapter per chapter number.
+// The returned chapters are copies sorted by chapter number with their index reassigned.
+func mergeChapters(containers []*ChapterContainer, providers []string, scanlators []string, languages []string) []*hibikemanga.ChapterDetails {
+	best := make(map[string]*hibikemanga.ChapterDetails)
+	order := make([]string, 0)
+
+	for _, container := range containers {
+		for _, chapter := range container.Chapters {
+			// The provider of the container is used to fetch the pages, some providers don't set it on the chapters
+			if chapter.Provider != container.Provider {
+				chapterCopy := *chapter
+				chapterCopy.Provider = container.Provider
+				chapter = &chapterCopy
+			}
+
+			number := manga_providers.GetNormalizedChapter(chapter.Chapter)
+			current, ok := best[number]
+			if !ok {
+				order = append(order, number)
+				best[number] = chapter
+				continue
+			}
+			if compareChapterVersions(chapter, current, providers, scanlators, languages) < 0 {
+				best[number] = chapter
+			}
+		}
+	}
+
+	slices.SortStableFunc(order, compareChapterNumbers)
+
+	ret := make([]*hibikemanga.ChapterDetails, 0, len(order))
+	for i, number := range order {
+		chapterCopy := *best[number]
+		chapterCopy.Index = uint(i)
+		ret = append(ret, &chapterCopy)
+	}
+
+	return ret
+}
+
+// compareChapterVersions returns a negative number if a is preferred over b.
+func compareChapterVersions(a, b *hibikemanga.ChapterDetails, providers []string, scanlators []string, languages []string) int {
+	if c := preferenceRank(languages, a.Language) - preferenceRank(languages, b.Language); c != 0 {
+		return c
+	}
+	if c := preferenceRank(scanlators, a.Scanlator) - preferenceRank(scanlators, b.Scanlator); c != 0 {
+		return c
+	}
+	return preferenceRank(providers, a.Provider) - preferenceRank(providers, b.Provider)
+}
+
+// preferenceRank returns the position of the value in the preferences, values that are not in it come last.
+func preferenceRank(preferences []string, value string) int {
+	idx := slices.IndexFunc(preferences, func(p string) bool {
+		return strings.EqualFold(p, value)
+	})
+	if idx == -1 {
+		return len(preferences)
+	}
+	return idx
+}
+
+// uniqueProviders removes empty and duplicate providers, and the merged provider itself.
+func uniqueProviders(providers []string) []string {
+	ret := make([]string, 0, len(providers))
+	for _, provider := range providers {
+		if provider == "" || provider == MergedProvider || slices.Contains(ret, provider) {
+			continue
+		}
+		ret = append(ret, provider)
+	}
+	return ret
+}
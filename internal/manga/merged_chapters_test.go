@@ -0,0 +1,60 @@
+package manga
+
+import (
+	hibikemanga "seanime/internal/extension/hibike/manga"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeChapters(t *testing.T) {
+	containers := []*ChapterContainer{
+		{
+			Provider: "comick",
+			Chapters: []*hibikemanga.ChapterDetails{
+				{ID: "c1-en-a", Chapter: "1", Language: "en", Scanlator: "Group A"},
+				{ID: "c1-fr", Chapter: "1", Language: "fr"},
+				{ID: "c2-en-b", Chapter: "2", Language: "en", Scanlator: "Group B"},
+				{ID: "c3-en-b", Chapter: "3", Language: "en", Scanlator: "Group B"},
+			},
+		},
+		{
+			Provider: "mangadex",
+			Chapters: []*hibikemanga.ChapterDetails{
+				{ID: "m1-en-a", Chapter: "1", Language: "en", Scanlator: "Group A"},
+				{ID: "m2-en-a", Chapter: "2", Language: "en", Scanlator: "Group A"},
+				// Missing from the first provider
+				{ID: "m10-en-c", Chapter: "10", Language: "en", Scanlator: "Group C"},
+				{ID: "m4-fr", Chapter: "4", Language: "fr"},
+			},
+		},
+	}
+
+	chapters := mergeChapters(containers, []string{"comick", "mangadex"}, []string{"Group A"}, []string{"en"})
+
+	expected := []struct {
+		id       string
+		provider string
+	}{
+		{"c1-en-a", "comick"},   // Same language and scanlator, the preferred provider is kept
+		{"m2-en-a", "mangadex"}, // Preferred scanlator
+		{"c3-en-b", "comick"},
+		{"m4-fr", "mangadex"}, // Only version available
+		{"m10-en-c", "mangadex"},
+	}
+
+	require.Len(t, chapters, len(expected))
+	for i, e := range expected {
+		require.Equal(t, e.id, chapters[i].ID)
+		require.Equal(t, e.provider, chapters[i].Provider)
+		require.Equal(t, uint(i), chapters[i].Index)
+	}
+
+	// The chapters of the containers are not modified
+	require.Equal(t, "", containers[0].Chapters[0].Provider)
+	require.Equal(t, uint(0), containers[1].Chapters[2].Index)
+}
+
+func TestUniqueProviders(t *testing.T) {
+	require.Equal(t, []string{"comick", "mangadex"}, uniqueProviders([]string{"comick", "", MergedProvider, "mangadex", "comick"}))
+}
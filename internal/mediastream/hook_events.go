@@ -0,0 +1,22 @@
+package mediastream
+
+import (
+	"seanime/internal/hook_resolver"
+)
+
+// MediastreamPlaybackRequestedEvent is triggered when the playback of a file or stream is requested.
+// SourceId is empty for local files, and Filepath is the URL of the stream otherwise.
+// The file path can be modified.
+// Prevent default to refuse the playback, an error will be returned to the client.
+type MediastreamPlaybackRequestedEvent struct {
+	hook_resolver.Event
+	Filepath   string     `json:"filepath"`
+	SourceId   string     `json:"sourceId"`
+	StreamType StreamType `json:"streamType"`
+}
+
+// MediastreamMediaContainerEvent is triggered when the media container is being returned to the client.
+type MediastreamMediaContainerEvent struct {
+	hook_resolver.Event
+	MediaContainer *MediaContainer `json:"mediaContainer"`
+}
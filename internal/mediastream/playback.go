@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"github.com/rs/zerolog"
 	"github.com/samber/mo"
+	"seanime/internal/events"
+	"seanime/internal/hook"
 	"seanime/internal/mediastream/videofile"
+	"seanime/internal/util"
 	"seanime/internal/util/result"
 )
 
@@ -61,6 +64,11 @@ func (p *PlaybackManager) RequestPlayback(filepath string, streamType StreamType
 
 	p.logger.Debug().Str("filepath", filepath).Any("type", streamType).Msg("mediastream: Requesting playback")
 
+	filepath, err = p.triggerPlaybackRequestedEvent(filepath, "", streamType)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create a new media container
 	ret, err = p.newMediaContainer(filepath, streamType)
 
@@ -69,6 +77,8 @@ func (p *PlaybackManager) RequestPlayback(filepath string, streamType StreamType
 		return nil, fmt.Errorf("failed to create media container: %v", err)
 	}
 
+	ret = p.triggerMediaContainerEvent(ret)
+
 	// Set the current media container.
 	p.currentMediaContainer = mo.Some(ret)
 
@@ -101,6 +111,70 @@ func (p *PlaybackManager) PreloadPlayback(filepath string, streamType StreamType
 
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
+// RequestSourcePlayback is called to stream a source that is not a local file, e.g. a torrent or debrid stream
+func (p *PlaybackManager) RequestSourcePlayback(source *StreamSource, streamType StreamType) (ret *MediaContainer, err error) {
+
+	p.logger.Debug().Str("source", source.ID).Any("type", streamType).Msg("mediastream: Requesting source playback")
+
+	url, err := p.triggerPlaybackRequestedEvent(source.Url, source.ID, streamType)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a new media container
+	ret, err = p.newMediaContainerWithHash(url, videofile.GetHashFromSourceId(source.ID), streamType)
+
+	if err != nil {
+		p.logger.Error().Err(err).Msg("mediastream: Failed to create media container")
+		return nil, fmt.Errorf("failed to create media container: %v", err)
+	}
+
+	ret = p.triggerMediaContainerEvent(ret)
+
+	// Set the current media container.
+	p.currentMediaContainer = mo.Some(ret)
+
+	p.logger.Info().Str("source", source.ID).Msg("mediastream: Ready to play source")
+
+	return
+}
+
+// triggerPlaybackRequestedEvent lets hooks modify the file path or refuse the playback.
+func (p *PlaybackManager) triggerPlaybackRequestedEvent(filepath string, sourceId string, streamType StreamType) (string, error) {
+	event := &MediastreamPlaybackRequestedEvent{
+		Filepath:   filepath,
+		SourceId:   sourceId,
+		StreamType: streamType,
+	}
+	err := hook.GlobalHookManager.OnMediastreamPlaybackRequested().Trigger(event)
+	if err != nil {
+		p.logger.Error().Err(err).Msg("mediastream: Exception occurred while triggering hook event")
+		return "", fmt.Errorf("mediastream: Error in hook, %w", err)
+	}
+
+	if event.DefaultPrevented {
+		p.logger.Debug().Msg("mediastream: Playback prevented by hook")
+		return "", errors.New("playback prevented by hook")
+	}
+
+	return event.Filepath, nil
+}
+
+func (p *PlaybackManager) triggerMediaContainerEvent(mediaContainer *MediaContainer) *MediaContainer {
+	event := &MediastreamMediaContainerEvent{
+		MediaContainer: mediaContainer,
+	}
+	err := hook.GlobalHookManager.OnMediastreamMediaContainer().Trigger(event)
+	if err != nil {
+		p.logger.Error().Err(err).Msg("mediastream: Exception occurred while triggering hook event")
+		return mediaContainer
+	}
+	if event.MediaContainer == nil {
+		return mediaContainer
+	}
+	return event.MediaContainer
+}
+
 func (p *PlaybackManager) newMediaContainer(filepath string, streamType StreamType) (ret *MediaContainer, err error) {
 	p.logger.Debug().Str("filepath", filepath).Any("type", streamType).Msg("mediastream: New media container requested")
 	// Get the hash of the file.
@@ -109,6 +183,11 @@ func (p *PlaybackManager) newMediaContainer(filepath string, streamType StreamTy
 		return nil, err
 	}
 
+	return p.newMediaContainerWithHash(filepath, hash, streamType)
+}
+
+// newMediaContainerWithHash creates the media container of a file or stream URL.
+func (p *PlaybackManager) newMediaContainerWithHash(filepath string, hash string, streamType StreamType) (ret *MediaContainer, err error) {
 	p.logger.Trace().Str("hash", hash).Msg("mediastream: Checking cache")
 
 	// Check the cache ONLY if the stream type is the same.
@@ -128,22 +207,44 @@ func (p *PlaybackManager) newMediaContainer(filepath string, streamType StreamTy
 
 	p.logger.Debug().Msg("mediastream: Extracting media info")
 
-	ret.MediaInfo, err = p.repository.mediaInfoExtractor.GetInfo(p.repository.settings.MustGet().FfprobePath, filepath)
+	ret.MediaInfo, err = p.repository.mediaInfoExtractor.GetInfoWithHash(p.repository.settings.MustGet().FfprobePath, filepath, hash)
 	if err != nil {
 		return nil, err
 	}
 
-	p.logger.Debug().Msg("mediastream: Extracted media info, extracting attachments")
-
-	// Extract the attachments from the file.
-	err = videofile.ExtractAttachment(p.repository.settings.MustGet().FfmpegPath, filepath, hash, ret.MediaInfo, p.repository.cacheDir, p.logger)
-	if err != nil {
-		p.logger.Error().Err(err).Msg("mediastream: Failed to extract attachments")
-		return nil, err
+	if videofile.IsStreamURL(filepath) {
+		if len(ret.MediaInfo.Subtitles) == 0 {
+			// The fonts are only used by the subtitles, don't read the whole stream for them
+			p.logger.Debug().Msg("mediastream: Extracted media info, stream has no subtitles")
+		} else {
+			// Extracting the subtitles of a stream requires reading the whole file, so it's done in the background.
+			// The client is notified once the subtitles can be loaded.
+			p.logger.Debug().Msg("mediastream: Extracted media info, extracting attachments in the background")
+			mediaInfo := ret.MediaInfo
+			go func() {
+				defer util.HandlePanicInModuleThen("mediastream/newMediaContainerWithHash", func() {})
+				err := videofile.ExtractAttachment(p.repository.settings.MustGet().FfmpegPath, filepath, hash, mediaInfo, p.repository.cacheDir, p.logger)
+				if err != nil {
+					p.logger.Error().Err(err).Msg("mediastream: Failed to extract attachments from stream")
+					return
+				}
+				p.logger.Debug().Msg("mediastream: Extracted attachments from stream")
+				p.repository.wsEventManager.SendEvent(events.MediastreamSourceSubtitlesReady, hash)
+			}()
+		}
+	} else {
+		p.logger.Debug().Msg("mediastream: Extracted media info, extracting attachments")
+
+		// Extract the attachments from the file.
+		err = videofile.ExtractAttachment(p.repository.settings.MustGet().FfmpegPath, filepath, hash, ret.MediaInfo, p.repository.cacheDir, p.logger)
+		if err != nil {
+			p.logger.Error().Err(err).Msg("mediastream: Failed to extract attachments")
+			return nil, err
+		}
+
+		p.logger.Debug().Msg("mediastream: Extracted attachments")
 	}
 
-	p.logger.Debug().Msg("mediastream: Extracted attachments")
-
 	streamUrl := ""
 	switch streamType {
 	case StreamTypeDirect:
@@ -26,6 +26,7 @@ type (
 		wsEventManager     events.WSEventManagerInterface
 		fileCacher         *filecache.Cacher
 		reqMu              sync.Mutex
+		transcodeClientId  string // Client that requested the current transcode stream
 		cacheDir           string // where attachments are stored
 		transcodeDir       string // where stream segments are stored
 	}
@@ -186,10 +187,13 @@ func (r *Repository) RequestTranscodeStream(filepath string, clientId string) (r
 		return nil, errors.New("module not initialized")
 	}
 
+	r.notifyDisplacedTranscodeClient(clientId)
+
 	// Reinitialize the transcoder for each new transcode request
 	if ok := r.initializeTranscoder(r.settings); !ok {
 		return nil, errors.New("real-time transcoder not initialized, check your settings")
 	}
+	r.transcodeClientId = clientId
 
 	ret, err = r.playbackManager.RequestPlayback(filepath, StreamTypeTranscode)
 
@@ -241,6 +245,22 @@ func (r *Repository) RequestPreloadDirectPlay(filepath string) (err error) {
 
 ///////////////////////////////////////////////////////////////////////////////////////////////
 
+// notifyDisplacedTranscodeClient tells the client whose transcode stream is about to be destroyed by another client's request,
+// so that it stops playing instead of requesting segments that no longer exist.
+// The caller should hold reqMu.
+func (r *Repository) notifyDisplacedTranscodeClient(clientId string) {
+	if !r.transcoder.IsPresent() || !r.playbackManager.currentMediaContainer.IsPresent() {
+		return
+	}
+	// Broadcasting would also stop the requesting client, so unknown clients are not notified
+	if r.transcodeClientId == "" || r.transcodeClientId == clientId {
+		return
+	}
+
+	r.logger.Debug().Str("client_id", r.transcodeClientId).Msg("mediastream: Transcode stream taken over by another client")
+	r.wsEventManager.SendEventTo(r.transcodeClientId, events.MediastreamShutdownStream, "Another stream started transcoding")
+}
+
 func (r *Repository) initializeTranscoder(settings mo.Option[*models.MediastreamSettings]) bool {
 	// Destroy the old transcoder if it exists
 	if r.transcoder.IsPresent() {
@@ -0,0 +1,80 @@
+package mediastream
+
+import (
+	"errors"
+	"seanime/internal/events"
+	"seanime/internal/mediastream/videofile"
+)
+
+type (
+	// StreamSource is a media source that is not a local file, e.g. a torrent or debrid stream.
+	// FFprobe and FFmpeg read it over HTTP, so the server must support range requests.
+	StreamSource struct {
+		// ID identifies the stream, it is used to cache the media information and the keyframes.
+		// e.g. "torrentstream:{infoHash}:{filePath}"
+		ID string
+		// Url is the HTTP URL of the stream.
+		Url string
+		// MediaId and EpisodeNumber are sent back to the client with the media container.
+		MediaId       int
+		EpisodeNumber int
+	}
+
+	// SourceReadyPayload is sent to the client with the events.MediastreamSourceReady event.
+	SourceReadyPayload struct {
+		MediaContainer *MediaContainer `json:"mediaContainer"`
+		MediaId        int             `json:"mediaId"`
+		EpisodeNumber  int             `json:"episodeNumber"`
+	}
+)
+
+// RequestTranscodeStreamFromSource transcodes a stream instead of a local file.
+// The returned media container is played the same way as local files.
+func (r *Repository) RequestTranscodeStreamFromSource(source *StreamSource, clientId string) (ret *MediaContainer, err error) {
+	r.reqMu.Lock()
+	defer r.reqMu.Unlock()
+
+	if source == nil || source.ID == "" || !videofile.IsStreamURL(source.Url) {
+		return nil, errors.New("invalid stream source")
+	}
+
+	r.logger.Debug().Str("source", source.ID).Msg("mediastream: Transcode stream requested for source")
+
+	if !r.IsInitialized() {
+		return nil, errors.New("module not initialized")
+	}
+
+	r.notifyDisplacedTranscodeClient(clientId)
+
+	// Reinitialize the transcoder for each new transcode request
+	if ok := r.initializeTranscoder(r.settings); !ok {
+		return nil, errors.New("real-time transcoder not initialized, check your settings")
+	}
+	r.transcodeClientId = clientId
+
+	ret, err = r.playbackManager.RequestSourcePlayback(source, StreamTypeTranscode)
+
+	return
+}
+
+// StartSourceTranscode transcodes the stream and tells the client to play it in the browser.
+// It is called by the torrent and debrid streaming modules once the stream is ready.
+func (r *Repository) StartSourceTranscode(source *StreamSource, clientId string) error {
+	mediaContainer, err := r.RequestTranscodeStreamFromSource(source, clientId)
+	if err != nil {
+		return err
+	}
+
+	payload := &SourceReadyPayload{
+		MediaContainer: mediaContainer,
+		MediaId:        source.MediaId,
+		EpisodeNumber:  source.EpisodeNumber,
+	}
+	if clientId == "" {
+		r.wsEventManager.SendEvent(events.MediastreamSourceReady, payload)
+	} else {
+		r.wsEventManager.SendEventTo(clientId, events.MediastreamSourceReady, payload)
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"seanime/internal/events"
 	"seanime/internal/mediastream/transcoder"
+	"seanime/internal/mediastream/videofile"
 	"strconv"
 	"strings"
 
@@ -161,6 +162,34 @@ func (r *Repository) ShutdownTranscodeStream(clientId string) {
 		return
 	}
 
+	r.shutdownTranscodeStream()
+}
+
+// ShutdownSourceTranscodeStream shuts down the transcode stream only if it is transcoding the source.
+// The transcoder may have moved on to another stream, e.g. one started by another client.
+func (r *Repository) ShutdownSourceTranscodeStream(sourceId string, clientId string) {
+	r.reqMu.Lock()
+	defer r.reqMu.Unlock()
+
+	if !r.IsInitialized() || !r.TranscoderIsInitialized() {
+		return
+	}
+
+	mediaContainer, ok := r.playbackManager.currentMediaContainer.Get()
+	if !ok || mediaContainer.Hash != videofile.GetHashFromSourceId(sourceId) {
+		r.logger.Debug().Str("source", sourceId).Msg("mediastream: Source is no longer transcoded, not shutting down the transcode stream")
+		return
+	}
+
+	r.logger.Debug().Str("client_id", clientId).Str("source", sourceId).Msg("mediastream: Shutting down the transcode stream of the source")
+
+	r.shutdownTranscodeStream()
+}
+
+// shutdownTranscodeStream kills the playback and reloads the transcoder.
+// The caller should hold reqMu.
+func (r *Repository) shutdownTranscodeStream() {
+
 	// Kill playback
 	r.playbackManager.KillPlayback()
 
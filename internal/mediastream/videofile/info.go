@@ -5,7 +5,6 @@ import (
 	"context"
 	"fmt"
 	"mime"
-	"path/filepath"
 	"seanime/internal/util/filecache"
 	"strconv"
 	"strings"
@@ -132,6 +131,12 @@ func (e *MediaInfoExtractor) GetInfo(ffprobePath, path string) (mi *MediaInfo, e
 		return nil, err
 	}
 
+	return e.GetInfoWithHash(ffprobePath, path, hash)
+}
+
+// GetInfoWithHash returns the media information of a file or stream URL identified by the hash.
+// This is used for streams, which cannot be hashed from the file system.
+func (e *MediaInfoExtractor) GetInfoWithHash(ffprobePath, path string, hash string) (mi *MediaInfo, err error) {
 	e.logger.Debug().Str("path", path).Str("hash", hash).Msg("mediastream: Getting media information [MediaInfoExtractor]")
 
 	bucketName := fmt.Sprintf("mediastream_mediainfo_%s", hash)
@@ -179,7 +184,7 @@ func FfprobeGetInfo(ffprobePath, path, hash string) (*MediaInfo, error) {
 		return nil, err
 	}
 
-	ext := filepath.Ext(path)[1:]
+	ext := getExtension(path)
 
 	sizeUint64, _ := strconv.ParseUint(data.Format.Size, 10, 64)
 
@@ -3,7 +3,10 @@ package videofile
 import (
 	"crypto/sha1"
 	"encoding/hex"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
 func GetHashFromPath(path string) (string, error) {
@@ -17,3 +20,26 @@ func GetHashFromPath(path string) (string, error) {
 	sha := hex.EncodeToString(h.Sum(nil))
 	return sha, nil
 }
+
+// GetHashFromSourceId returns the hash of a stream that is not a local file.
+// The ID should stay the same for the same stream, e.g. the info hash of the torrent and the file index.
+func GetHashFromSourceId(id string) string {
+	h := sha1.New()
+	h.Write([]byte("source:" + id))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// IsStreamURL returns true if the path is an HTTP URL instead of a local file.
+func IsStreamURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// getExtension returns the extension of a file path or stream URL, without the dot.
+func getExtension(path string) string {
+	if IsStreamURL(path) {
+		if u, err := url.Parse(path); err == nil {
+			path = u.Path
+		}
+	}
+	return strings.TrimPrefix(filepath.Ext(path), ".")
+}
@@ -0,0 +1,36 @@
+package videofile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetExtension(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/path/to/[SubsPlease] Show - 01 (1080p).mkv", "mkv"},
+		{"http://127.0.0.1:43211/api/v1/torrentstream/stream/0b8f7f8e/Show%20-%2001.mkv", "mkv"},
+		{"https://abcd.debrid.it/dl/AAAAAAAAAAAA/Show.mp4?token=abc.def", "mp4"},
+		{"https://abcd.debrid.it/dl/AAAAAAAAAAAA", ""},
+		{"/path/to/file", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			require.Equal(t, tt.expected, getExtension(tt.path))
+		})
+	}
+}
+
+func TestGetHashFromSourceId(t *testing.T) {
+	hash := GetHashFromSourceId("torrentstream:80431b4f9a12f4e06616062d3d3973b9ef99b5e6:Show - 01.mkv")
+	require.Len(t, hash, 40)
+	require.Equal(t, hash, GetHashFromSourceId("torrentstream:80431b4f9a12f4e06616062d3d3973b9ef99b5e6:Show - 01.mkv"))
+	require.NotEqual(t, hash, GetHashFromSourceId("torrentstream:80431b4f9a12f4e06616062d3d3973b9ef99b5e6:Show - 02.mkv"))
+
+	require.True(t, IsStreamURL("https://abcd.debrid.it/dl/AAAAAAAAAAAA"))
+	require.False(t, IsStreamURL("/path/to/file.mkv"))
+}
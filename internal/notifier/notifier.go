@@ -15,6 +15,8 @@ type (
 		mu       sync.Mutex
 		logoPath string
 		logger   mo.Option[*zerolog.Logger]
+		// listeners are called for every notification, even if it's not pushed
+		listeners map[string]func(id Notification, message string)
 	}
 
 	Notification string
@@ -24,6 +26,7 @@ const (
 	AutoDownloader Notification = "Auto Downloader"
 	AutoScanner    Notification = "Auto Scanner"
 	Debrid         Notification = "Debrid"
+	MangaUpdates   Notification = "Manga"
 )
 
 var GlobalNotifier = NewNotifier()
@@ -34,10 +37,32 @@ func init() {
 
 func NewNotifier() *Notifier {
 	return &Notifier{
-		dataDir:  mo.None[string](),
-		settings: mo.None[*models.NotificationSettings](),
-		mu:       sync.Mutex{},
-		logger:   mo.None[*zerolog.Logger](),
+		dataDir:   mo.None[string](),
+		settings:  mo.None[*models.NotificationSettings](),
+		mu:        sync.Mutex{},
+		logger:    mo.None[*zerolog.Logger](),
+		listeners: make(map[string]func(id Notification, message string)),
+	}
+}
+
+// AddListener registers a function that is called for every notification sent.
+// It is used to observe notifications without pushing them, e.g. when testing plugins.
+func (n *Notifier) AddListener(id string, fn func(id Notification, message string)) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.listeners[id] = fn
+}
+
+func (n *Notifier) RemoveListener(id string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.listeners, id)
+}
+
+// notifyListeners should be called with the lock held.
+func (n *Notifier) notifyListeners(id Notification, message string) {
+	for _, fn := range n.listeners {
+		fn(id, message)
 	}
 }
 
@@ -68,6 +93,8 @@ func (n *Notifier) canProceed(id Notification) bool {
 		return !n.settings.MustGet().DisableAutoDownloaderNotifications
 	case AutoScanner:
 		return !n.settings.MustGet().DisableAutoScannerNotifications
+	case MangaUpdates:
+		return !n.settings.MustGet().DisableMangaUpdateNotifications
 	}
 
 	return false
@@ -16,6 +16,8 @@ func (n *Notifier) Notify(id Notification, message string) {
 		n.mu.Lock()
 		defer n.mu.Unlock()
 
+		n.notifyListeners(id, message)
+
 		if !n.canProceed(id) {
 			return
 		}
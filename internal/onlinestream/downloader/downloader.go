@@ -0,0 +1,627 @@
+package onlinestream_downloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"seanime/internal/database/db"
+	"seanime/internal/database/models"
+	"seanime/internal/events"
+	"seanime/internal/onlinestream"
+	"seanime/internal/util"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+)
+
+// 📁 {downloadDir}
+// └── 📁 {provider}_{mediaId}_{episodeNumber}_{dubbed}     <- Temporary, removed once the episode is remuxed
+//     ├── 📄 playlist.m3u8
+//     ├── 📄 segment_00000.ts
+//     ├── 📄 audio_0_playlist.m3u8                         <- Audio renditions, if any
+//     ├── 📄 audio_0_segment_00000.ts
+//     ├── 📄 ...
+//     └── 📄 subtitle_0.vtt
+//
+// 📁 {libraryDir}
+// └── 📁 {title}
+//     └── 📄 {title} - {episodeNumber}.mkv                  <- Matched by the scanner
+
+var userAgent = util.GetRandomUserAgent()
+
+type (
+	// Downloader is used to download online streaming episodes for offline viewing.
+	// Episodes are remuxed to MKV with their subtitles and written to the library.
+	Downloader struct {
+		logger         *zerolog.Logger
+		wsEventManager events.WSEventManagerInterface
+		database       *db.Database
+		downloadDir    string
+		client         *http.Client
+		mu             sync.Mutex
+		settingsMu     sync.RWMutex
+		settings       Settings
+		queue          *Queue
+		cancelFunc     context.CancelFunc // Cancels the current download
+		runCh          chan *QueueInfo    // Receives a signal to download the next item
+	}
+
+	Settings struct {
+		// Directory the episodes are written to.
+		LibraryDir string
+		FfmpegPath string
+	}
+
+	DownloadID struct {
+		Provider      string `json:"provider"`
+		MediaId       int    `json:"mediaId"`
+		EpisodeNumber int    `json:"episodeNumber"`
+		Dubbed        bool   `json:"dubbed"`
+	}
+
+	// SourceData is the source chosen by the user, stored in the queue.
+	SourceData struct {
+		VideoSource *onlinestream.VideoSource `json:"videoSource"`
+		Subtitles   []*onlinestream.Subtitle  `json:"subtitles"`
+	}
+
+	// EpisodeDownloadedEvent is sent to the client when an episode has been written to the library.
+	EpisodeDownloadedEvent struct {
+		DownloadID
+		Path string `json:"path"`
+	}
+)
+
+type (
+	NewDownloaderOptions struct {
+		Logger         *zerolog.Logger
+		WSEventManager events.WSEventManagerInterface
+		Database       *db.Database
+		// Directory where the episodes are downloaded before being remuxed.
+		DownloadDir string
+	}
+
+	DownloadOptions struct {
+		DownloadID
+		// Title of the media, used to name the file.
+		Title    string
+		Source   *SourceData
+		StartNow bool
+	}
+)
+
+func NewDownloader(opts *NewDownloaderOptions) *Downloader {
+	runCh := make(chan *QueueInfo, 1)
+
+	d := &Downloader{
+		logger:         opts.Logger,
+		wsEventManager: opts.WSEventManager,
+		database:       opts.Database,
+		downloadDir:    opts.DownloadDir,
+		// No overall timeout since downloads can take a while
+		client: &http.Client{
+			Transport: &http.Transport{
+				Proxy:                 http.ProxyFromEnvironment,
+				ResponseHeaderTimeout: 30 * time.Second,
+				IdleConnTimeout:       90 * time.Second,
+			},
+		},
+		runCh:    runCh,
+		queue:    NewQueue(opts.Database, opts.Logger, opts.WSEventManager, runCh),
+		settings: Settings{FfmpegPath: "ffmpeg"},
+	}
+
+	return d
+}
+
+// Start spins up a goroutine that will listen to queue events.
+func (d *Downloader) Start() {
+	// Items that were downloading when the app was closed are restarted
+	_ = d.database.ResetDownloadingOnlinestreamDownloadQueueItems()
+
+	go func() {
+		for {
+			select {
+			// Listen for new queue items
+			case queueInfo := <-d.runCh:
+				d.logger.Debug().Msgf("onlinestream downloader: Received queue item to download: episode %d of %d", queueInfo.EpisodeNumber, queueInfo.MediaId)
+				d.run(queueInfo)
+			}
+		}
+	}()
+}
+
+// SetLibraryDir sets the directory the episodes are written to.
+func (d *Downloader) SetLibraryDir(libraryDir string) {
+	d.settingsMu.Lock()
+	defer d.settingsMu.Unlock()
+	d.settings.LibraryDir = libraryDir
+}
+
+// SetFfmpegPath sets the path to the ffmpeg binary used to remux the episodes.
+func (d *Downloader) SetFfmpegPath(ffmpegPath string) {
+	d.settingsMu.Lock()
+	defer d.settingsMu.Unlock()
+	d.settings.FfmpegPath = ffmpegPath
+	if d.settings.FfmpegPath == "" {
+		d.settings.FfmpegPath = "ffmpeg"
+	}
+}
+
+func (d *Downloader) getSettings() Settings {
+	d.settingsMu.RLock()
+	defer d.settingsMu.RUnlock()
+	return d.settings
+}
+
+// AddToQueue adds an episode to the download queue.
+func (d *Downloader) AddToQueue(opts DownloadOptions) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.getSettings().LibraryDir == "" {
+		return errors.New("library path is not set")
+	}
+	if opts.Source == nil || opts.Source.VideoSource == nil || opts.Source.VideoSource.URL == "" {
+		return errors.New("no video source selected")
+	}
+	if opts.Title == "" {
+		return errors.New("title is empty")
+	}
+
+	d.logger.Debug().Msgf("onlinestream downloader: Adding episode %d of %d to download queue", opts.EpisodeNumber, opts.MediaId)
+
+	return d.queue.Add(opts.DownloadID, opts.Title, opts.Source, opts.StartNow)
+}
+
+// Run starts the downloader if it's not already running.
+func (d *Downloader) Run() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.logger.Debug().Msg("onlinestream downloader: Starting queue")
+
+	d.queue.Run()
+}
+
+// Stop cancels the current download and stops the queue from running.
+// The current item is put back in the queue.
+func (d *Downloader) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.queue.Stop()
+
+	if d.cancelFunc != nil {
+		d.cancelFunc()
+	}
+}
+
+// IsRunning returns true if the queue is running.
+func (d *Downloader) IsRunning() bool {
+	return d.queue.IsActive()
+}
+
+// GetCurrent returns the item being downloaded.
+func (d *Downloader) GetCurrent() (*QueueInfo, bool) {
+	return d.queue.GetCurrent()
+}
+
+func (d *Downloader) GetQueue() ([]*models.OnlinestreamDownloadQueueItem, error) {
+	return d.database.GetOnlinestreamDownloadQueue()
+}
+
+// RemoveFromQueue removes an item from the queue, it cannot be the item being downloaded.
+func (d *Downloader) RemoveFromQueue(id DownloadID) error {
+	if current, ok := d.queue.GetCurrent(); ok && current.DownloadID == id {
+		return errors.New("episode is being downloaded")
+	}
+	err := d.database.DeleteOnlinestreamDownloadQueueItem(id.Provider, id.MediaId, id.EpisodeNumber, id.Dubbed)
+	d.wsEventManager.SendEvent(events.OnlinestreamDownloadQueueUpdated, nil)
+	return err
+}
+
+// ClearQueue removes all the items that are not being downloaded.
+func (d *Downloader) ClearQueue() error {
+	current, hasCurrent := d.queue.GetCurrent()
+	items, err := d.database.GetOnlinestreamDownloadQueue()
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if hasCurrent && current.Provider == item.Provider && current.MediaId == item.MediaID && current.EpisodeNumber == item.EpisodeNumber && current.Dubbed == item.Dubbed {
+			continue
+		}
+		_ = d.database.DeleteOnlinestreamDownloadQueueItem(item.Provider, item.MediaID, item.EpisodeNumber, item.Dubbed)
+	}
+	d.wsEventManager.SendEvent(events.OnlinestreamDownloadQueueUpdated, nil)
+	return nil
+}
+
+// ResetErroredItems puts the errored items back in the queue.
+func (d *Downloader) ResetErroredItems() error {
+	err := d.database.ResetErroredOnlinestreamDownloadQueueItems()
+	d.wsEventManager.SendEvent(events.OnlinestreamDownloadQueueUpdated, nil)
+	return err
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// run downloads the episode based on the QueueInfo provided.
+// This is called successively for each current item being processed.
+func (d *Downloader) run(queueInfo *QueueInfo) {
+
+	defer util.HandlePanicInModuleThen("internal/onlinestream/downloader/run", func() {
+		d.logger.Error().Msg("onlinestream downloader: Panic in 'run'")
+		queueInfo.Status = QueueStatusErrored
+		d.queue.HasCompleted(queueInfo)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.mu.Lock()
+	d.cancelFunc = cancel
+	d.mu.Unlock()
+
+	defer func() {
+		d.mu.Lock()
+		d.cancelFunc = nil
+		d.mu.Unlock()
+		cancel()
+	}()
+
+	dest, err := d.downloadEpisode(ctx, queueInfo)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			d.logger.Info().Msgf("onlinestream downloader: Canceled episode %d of %d", queueInfo.EpisodeNumber, queueInfo.MediaId)
+			queueInfo.Status = QueueStatusNotStarted
+		} else {
+			d.logger.Error().Err(err).Msgf("onlinestream downloader: Failed to download episode %d of %d", queueInfo.EpisodeNumber, queueInfo.MediaId)
+			queueInfo.Status = QueueStatusErrored
+			d.wsEventManager.SendEvent(events.ErrorToast, fmt.Sprintf("Failed to download %s episode %d: %s", queueInfo.Title, queueInfo.EpisodeNumber, err.Error()))
+		}
+		d.queue.HasCompleted(queueInfo)
+		return
+	}
+
+	d.logger.Info().Str("path", dest).Msgf("onlinestream downloader: Finished downloading episode %d of %d", queueInfo.EpisodeNumber, queueInfo.MediaId)
+
+	d.queue.HasCompleted(queueInfo)
+
+	d.wsEventManager.SendEvent(events.OnlinestreamEpisodeDownloaded, &EpisodeDownloadedEvent{
+		DownloadID: queueInfo.DownloadID,
+		Path:       dest,
+	})
+}
+
+// downloadEpisode downloads the video source and subtitles, remuxes them into an MKV file and returns its path.
+func (d *Downloader) downloadEpisode(ctx context.Context, queueInfo *QueueInfo) (string, error) {
+	settings := d.getSettings()
+	if settings.LibraryDir == "" {
+		return "", errors.New("library path is not set")
+	}
+
+	source := queueInfo.Source.VideoSource
+
+	// Create temporary directory
+	// 📁 {provider}_{mediaId}_{episodeNumber}_{dubbed}
+	workDir := d.getEpisodeDownloadDir(queueInfo.DownloadID)
+	_ = os.RemoveAll(workDir)
+	if err := os.MkdirAll(workDir, os.ModePerm); err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(workDir)
+
+	// +---------------------+
+	// |        Video        |
+	// +---------------------+
+
+	var input string
+	var audio []*downloadedAudio
+	var isHLS bool
+	var err error
+
+	onSegmentProgress := func(done int, total int, downloadedBytes int64) {
+		d.queue.updateProgress(queueInfo, func(progress *ProgressInfo) {
+			progress.DownloadedSegments = done
+			progress.TotalSegments = total
+			progress.DownloadedBytes = downloadedBytes
+			progress.Percentage = float64(done) / float64(total) * 100
+		})
+	}
+
+	if isHLSURL(source.URL) {
+		isHLS = true
+		input, audio, err = d.downloadHLS(ctx, source.URL, source.Headers, workDir, onSegmentProgress)
+	} else {
+		input, err = d.downloadDirect(ctx, queueInfo, workDir)
+		// Some sources don't use the .m3u8 extension
+		if err == nil && isHLSFile(input) {
+			isHLS = true
+			input, audio, err = d.downloadHLS(ctx, source.URL, source.Headers, workDir, onSegmentProgress)
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// +---------------------+
+	// |      Subtitles      |
+	// +---------------------+
+
+	subtitles := make([]*downloadedSubtitle, 0, len(queueInfo.Source.Subtitles))
+	for i, sub := range queueInfo.Source.Subtitles {
+		if sub == nil || sub.URL == "" {
+			continue
+		}
+		ext := path.Ext(stripQuery(sub.URL))
+		if ext == "" {
+			ext = ".vtt"
+		}
+		subPath := filepath.Join(workDir, fmt.Sprintf("subtitle_%d%s", i, ext))
+		if _, err := d.downloadFile(ctx, sub.URL, source.Headers, subPath); err != nil {
+			if errors.Is(err, context.Canceled) {
+				return "", err
+			}
+			d.logger.Warn().Err(err).Str("language", sub.Language).Msg("onlinestream downloader: Failed to download subtitle, skipping")
+			continue
+		}
+		subtitles = append(subtitles, &downloadedSubtitle{Path: subPath, Language: sub.Language})
+	}
+
+	// +---------------------+
+	// |        Remux        |
+	// +---------------------+
+
+	d.queue.updateProgress(queueInfo, func(progress *ProgressInfo) {
+		progress.Step = "remuxing"
+		progress.Percentage = 100
+	})
+
+	dest := getEpisodePath(settings.LibraryDir, queueInfo.Title, queueInfo.EpisodeNumber, queueInfo.Dubbed)
+	if err := os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
+		return "", err
+	}
+
+	tmpDest := dest + ".part"
+	defer os.Remove(tmpDest)
+
+	args := getRemuxArgs(input, isHLS, audio, subtitles, tmpDest)
+	d.logger.Trace().Msgf("onlinestream downloader: ffmpeg command: %s %s", settings.FfmpegPath, strings.Join(args, " "))
+
+	cmd := util.NewCmdCtx(ctx, settings.FfmpegPath, args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", fmt.Errorf("ffmpeg failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	if err := os.Rename(tmpDest, dest); err != nil {
+		return "", err
+	}
+
+	return dest, nil
+}
+
+// downloadDirect downloads a non-HLS video source.
+func (d *Downloader) downloadDirect(ctx context.Context, queueInfo *QueueInfo, workDir string) (string, error) {
+	source := queueInfo.Source.VideoSource
+
+	resp, err := d.request(ctx, source.URL, source.Headers)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	ext := path.Ext(stripQuery(source.URL))
+	if ext == "" {
+		ext = ".mp4"
+	}
+	dest := filepath.Join(workDir, "video"+ext)
+
+	f, err := os.Create(dest)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	total := resp.ContentLength
+	buf := make([]byte, 256*1024)
+	var written int64
+	lastPercentage := -1.0
+	for {
+		n, readErr := resp.Body.Read(buf)
+		if n > 0 {
+			if _, err := f.Write(buf[:n]); err != nil {
+				return "", err
+			}
+			written += int64(n)
+
+			percentage := 0.0
+			if total > 0 {
+				percentage = float64(written) / float64(total) * 100
+			}
+			// Throttle progress events
+			if total <= 0 || percentage-lastPercentage >= 1 {
+				lastPercentage = percentage
+				d.queue.updateProgress(queueInfo, func(progress *ProgressInfo) {
+					progress.DownloadedBytes = written
+					progress.Percentage = percentage
+				})
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return "", readErr
+		}
+	}
+
+	return dest, nil
+}
+
+func (d *Downloader) getEpisodeDownloadDir(id DownloadID) string {
+	return filepath.Join(d.downloadDir, fmt.Sprintf("%s_%d_%d_%v", id.Provider, id.MediaId, id.EpisodeNumber, id.Dubbed))
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type downloadedSubtitle struct {
+	Path     string
+	Language string
+}
+
+// downloadedAudio is an audio rendition of an HLS stream.
+type downloadedAudio struct {
+	Path     string
+	Language string
+	Name     string
+}
+
+// getRemuxArgs returns the ffmpeg arguments used to remux the video, audio renditions and subtitles into an MKV file.
+// Streams are copied, subtitles are converted to SubRip.
+func getRemuxArgs(input string, isHLS bool, audio []*downloadedAudio, subtitles []*downloadedSubtitle, output string) []string {
+	args := []string{"-hide_banner", "-loglevel", "error", "-y"}
+
+	// Allow the local playlists to reference the downloaded keys and segments
+	hlsInputArgs := []string{"-allowed_extensions", "ALL", "-protocol_whitelist", "file,crypto,data"}
+
+	if isHLS {
+		args = append(args, hlsInputArgs...)
+	}
+	args = append(args, "-i", input)
+
+	for _, a := range audio {
+		args = append(args, hlsInputArgs...)
+		args = append(args, "-i", a.Path)
+	}
+	for _, sub := range subtitles {
+		args = append(args, "-i", sub.Path)
+	}
+
+	args = append(args, "-map", "0:v?")
+	if len(audio) == 0 {
+		args = append(args, "-map", "0:a?")
+	}
+	for i := range audio {
+		args = append(args, "-map", fmt.Sprintf("%d:a?", i+1))
+	}
+	for i := range subtitles {
+		args = append(args, "-map", fmt.Sprintf("%d:s?", len(audio)+i+1))
+	}
+
+	args = append(args, "-c:v", "copy", "-c:a", "copy")
+	if len(subtitles) > 0 {
+		args = append(args, "-c:s", "srt")
+	}
+	for i, a := range audio {
+		if code := getLanguageCode(a.Language); code != "" {
+			args = append(args, fmt.Sprintf("-metadata:s:a:%d", i), "language="+code)
+		}
+		if a.Name != "" {
+			args = append(args, fmt.Sprintf("-metadata:s:a:%d", i), "title="+a.Name)
+		}
+	}
+	for i, sub := range subtitles {
+		if sub.Language != "" {
+			args = append(args, fmt.Sprintf("-metadata:s:s:%d", i), "title="+sub.Language)
+		}
+		if code := getLanguageCode(sub.Language); code != "" {
+			args = append(args, fmt.Sprintf("-metadata:s:s:%d", i), "language="+code)
+		}
+	}
+
+	args = append(args, "-f", "matroska", output)
+
+	return args
+}
+
+// getLanguageCode returns the ISO 639-2 code of a language given by its code or English name, or an empty string if it is unknown.
+//   - "en" -> "eng"
+//   - "Portuguese (Brazil)" -> "por"
+func getLanguageCode(lang string) string {
+	lang = strings.TrimSpace(lang)
+	if lang == "" {
+		return ""
+	}
+
+	if tag, err := language.Parse(lang); err == nil && tag != language.Und {
+		base, _ := tag.Base()
+		return base.ISO3()
+	}
+
+	// e.g. "Spanish - Latin America", "Portuguese (Brazil)"
+	name := strings.ToLower(strings.TrimSpace(lang))
+	if idx := strings.IndexAny(name, "(-["); idx != -1 {
+		name = strings.TrimSpace(name[:idx])
+	}
+	if name == "" {
+		return ""
+	}
+
+	namer := display.English.Languages()
+	for _, tag := range display.Supported.Tags() {
+		if strings.ToLower(namer.Name(tag)) == name {
+			base, _ := tag.Base()
+			return base.ISO3()
+		}
+	}
+
+	return ""
+}
+
+// getEpisodePath returns the path of the episode in the library.
+// The name is chosen so that the scanner matches the file to the media.
+//
+//	e.g. {libraryDir}/Sousou no Frieren/Sousou no Frieren - 01.mkv
+func getEpisodePath(libraryDir string, title string, episodeNumber int, dubbed bool) string {
+	title = sanitizeFilename(title)
+	filename := fmt.Sprintf("%s - %02d", title, episodeNumber)
+	if dubbed {
+		filename += " [Dub]"
+	}
+	return filepath.Join(libraryDir, title, filename+".mkv")
+}
+
+func sanitizeFilename(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case '<', '>', ':', '"', '/', '\\', '|', '?', '*':
+			return -1
+		}
+		if r < 32 {
+			return -1
+		}
+		return r
+	}, name)
+	return strings.Trim(strings.Join(strings.Fields(name), " "), " .")
+}
+
+func isHLSURL(uri string) bool {
+	return strings.HasSuffix(strings.ToLower(stripQuery(uri)), ".m3u8")
+}
+
+// isHLSFile returns true if the downloaded file is a playlist.
+func isHLSFile(filePath string) bool {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	buf := make([]byte, 16)
+	n, _ := io.ReadFull(f, buf)
+	return isHLSPlaylist(buf[:n])
+}
@@ -0,0 +1,155 @@
+package onlinestream_downloader
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"seanime/internal/events"
+	"seanime/internal/util"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadHLS(t *testing.T) {
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/master.m3u8", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("#EXTM3U\n#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID=\"aud\",NAME=\"Japanese\",LANGUAGE=\"ja\",DEFAULT=YES,URI=\"audio/ja.m3u8\"\n#EXT-X-STREAM-INF:BANDWIDTH=800000,RESOLUTION=640x360,AUDIO=\"aud\"\nlow/index.m3u8\n#EXT-X-STREAM-INF:BANDWIDTH=2800000,RESOLUTION=1280x720,AUDIO=\"aud\"\nhigh/index.m3u8\n"))
+	})
+	mux.HandleFunc("/audio/ja.m3u8", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:25\n#EXT-X-MEDIA-SEQUENCE:0\n#EXTINF:25.0,\nseg0.aac\n#EXT-X-ENDLIST\n"))
+	})
+	mux.HandleFunc("/audio/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("audio " + r.URL.Path))
+	})
+	mux.HandleFunc("/high/index.m3u8", func(w http.ResponseWriter, r *http.Request) {
+		// Headers from the provider are sent
+		if r.Header.Get("Referer") != "https://example.com" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte("#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:10\n#EXT-X-MEDIA-SEQUENCE:0\n#EXT-X-KEY:METHOD=AES-128,URI=\"/key.bin\"\n#EXTINF:10.0,\nseg0.ts\n#EXTINF:10.0,\nseg1.ts?token=abc\n#EXTINF:5.0,\nseg2.ts\n#EXT-X-ENDLIST\n"))
+	})
+	mux.HandleFunc("/high/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("segment " + r.URL.Path))
+	})
+	mux.HandleFunc("/key.bin", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("0123456789abcdef"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	logger := util.NewLogger()
+	d := NewDownloader(&NewDownloaderOptions{
+		Logger:         logger,
+		WSEventManager: events.NewMockWSEventManager(logger),
+		DownloadDir:    t.TempDir(),
+	})
+
+	destination := t.TempDir()
+	var lastDone, lastTotal int
+	playlistPath, audio, err := d.downloadHLS(context.Background(), server.URL+"/master.m3u8", map[string]string{"Referer": "https://example.com"}, destination, func(done int, total int, _ int64) {
+		lastDone, lastTotal = done, total
+	})
+	require.NoError(t, err)
+	require.Equal(t, 4, lastDone)
+	require.Equal(t, 4, lastTotal)
+
+	playlist, err := os.ReadFile(playlistPath)
+	require.NoError(t, err)
+
+	// The playlist references the local files
+	require.Contains(t, string(playlist), "segment_00000.ts")
+	require.Contains(t, string(playlist), "segment_00001.ts")
+	require.Contains(t, string(playlist), "segment_00002.ts")
+	require.Contains(t, string(playlist), `URI="key_0.bin"`)
+	require.NotContains(t, string(playlist), server.URL)
+
+	segment, err := os.ReadFile(filepath.Join(destination, "segment_00001.ts"))
+	require.NoError(t, err)
+	require.Equal(t, "segment /high/seg1.ts", string(segment))
+
+	key, err := os.ReadFile(filepath.Join(destination, "key_0.bin"))
+	require.NoError(t, err)
+	require.Equal(t, "0123456789abcdef", string(key))
+
+	// The audio rendition has its own local playlist
+	require.Len(t, audio, 1)
+	require.Equal(t, "ja", audio[0].Language)
+	require.Equal(t, "Japanese", audio[0].Name)
+	audioPlaylist, err := os.ReadFile(audio[0].Path)
+	require.NoError(t, err)
+	require.Contains(t, string(audioPlaylist), "audio_0_segment_00000.aac")
+
+	audioSegment, err := os.ReadFile(filepath.Join(destination, "audio_0_segment_00000.aac"))
+	require.NoError(t, err)
+	require.Equal(t, "audio /audio/seg0.aac", string(audioSegment))
+}
+
+func TestGetEpisodePath(t *testing.T) {
+
+	tests := []struct {
+		title         string
+		episodeNumber int
+		dubbed        bool
+		expected      string
+	}{
+		{"Sousou no Frieren", 1, false, filepath.Join("lib", "Sousou no Frieren", "Sousou no Frieren - 01.mkv")},
+		{"Re:Zero kara Hajimeru Isekai Seikatsu", 12, false, filepath.Join("lib", "ReZero kara Hajimeru Isekai Seikatsu", "ReZero kara Hajimeru Isekai Seikatsu - 12.mkv")},
+		{"One Piece", 1100, true, filepath.Join("lib", "One Piece", "One Piece - 1100 [Dub].mkv")},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, getEpisodePath("lib", tt.title, tt.episodeNumber, tt.dubbed))
+	}
+}
+
+func TestGetRemuxArgs(t *testing.T) {
+
+	args := getRemuxArgs("playlist.m3u8", true, nil, []*downloadedSubtitle{
+		{Path: "subtitle_0.vtt", Language: "English"},
+		{Path: "subtitle_1.vtt", Language: "Spanish"},
+	}, "out.mkv.part")
+
+	cmd := strings.Join(args, " ")
+	require.Contains(t, cmd, "-allowed_extensions ALL")
+	require.Contains(t, cmd, "-i playlist.m3u8 -i subtitle_0.vtt -i subtitle_1.vtt")
+	require.Contains(t, cmd, "-map 0:v? -map 0:a? -map 1:s? -map 2:s?")
+	require.Contains(t, cmd, "-c:s srt")
+	require.Contains(t, cmd, "-metadata:s:s:1 title=Spanish")
+	require.Contains(t, cmd, "-metadata:s:s:1 language=spa")
+	require.True(t, strings.HasSuffix(cmd, "-f matroska out.mkv.part"))
+
+	// Audio renditions replace the audio of the video playlist
+	args = getRemuxArgs("playlist.m3u8", true, []*downloadedAudio{
+		{Path: "audio_0_playlist.m3u8", Language: "ja", Name: "Japanese"},
+		{Path: "audio_1_playlist.m3u8", Language: "en", Name: "English"},
+	}, []*downloadedSubtitle{
+		{Path: "subtitle_0.vtt", Language: "English"},
+	}, "out.mkv.part")
+
+	cmd = strings.Join(args, " ")
+	require.Contains(t, cmd, "-allowed_extensions ALL -protocol_whitelist file,crypto,data -i audio_1_playlist.m3u8")
+	require.Contains(t, cmd, "-map 0:v? -map 1:a? -map 2:a? -map 3:s?")
+	require.NotContains(t, cmd, "0:a?")
+	require.Contains(t, cmd, "-metadata:s:a:0 language=jpn")
+	require.Contains(t, cmd, "-metadata:s:a:1 title=English")
+
+	args = getRemuxArgs("video.mp4", false, nil, nil, "out.mkv.part")
+	require.NotContains(t, args, "-allowed_extensions")
+	require.NotContains(t, args, "-c:s")
+}
+
+func TestGetLanguageCode(t *testing.T) {
+	require.Equal(t, "eng", getLanguageCode("en"))
+	require.Equal(t, "eng", getLanguageCode("English"))
+	require.Equal(t, "spa", getLanguageCode("Spanish - Latin America"))
+	require.Equal(t, "por", getLanguageCode("pt-BR"))
+	require.Equal(t, "por", getLanguageCode("Portuguese (Brazil)"))
+	require.Equal(t, "", getLanguageCode("Signs & Songs"))
+	require.Equal(t, "", getLanguageCode(""))
+}
@@ -0,0 +1,385 @@
+package onlinestream_downloader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/grafov/m3u8"
+)
+
+const (
+	hlsPlaylistFilename = "playlist.m3u8"
+	// Number of segments downloaded concurrently.
+	hlsSegmentConcurrency = 4
+	// Number of attempts to download a segment.
+	hlsSegmentAttempts = 3
+)
+
+var ErrNotHLS = errors.New("not an HLS playlist")
+
+// hlsPlaylist is a media playlist being downloaded.
+type hlsPlaylist struct {
+	media    *m3u8.MediaPlaylist
+	url      string
+	prefix   string // Prefix of the local files, e.g. "audio_0_"
+	language string // Audio renditions only
+	name     string // Audio renditions only
+}
+
+// hlsAudioRendition is an audio rendition (EXT-X-MEDIA) of a variant, stored in its own media playlist.
+type hlsAudioRendition struct {
+	URL      string
+	Language string
+	Name     string
+}
+
+// downloadHLS downloads the segments of an HLS stream to the destination directory
+// and writes a playlist referencing the local files, which can be read by ffmpeg.
+//   - If the playlist is a master playlist, the variant with the highest bandwidth is downloaded.
+//   - The audio renditions of the variant are downloaded too, each with its own local playlist.
+//   - Encryption keys and initialization sections are downloaded too.
+//
+// It returns the path to the local playlist and the audio renditions.
+func (cd *Downloader) downloadHLS(ctx context.Context, playlistUrl string, headers map[string]string, destination string, onProgress func(done int, total int, downloadedBytes int64)) (string, []*downloadedAudio, error) {
+
+	mediaPl, playlistUrl, renditions, err := cd.getHLSMediaPlaylist(ctx, playlistUrl, headers)
+	if err != nil {
+		return "", nil, err
+	}
+
+	playlists := []*hlsPlaylist{{media: mediaPl, url: playlistUrl}}
+	for i, rendition := range renditions {
+		pl, listType, err := cd.fetchHLSPlaylist(ctx, rendition.URL, headers)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to fetch audio rendition: %w", err)
+		}
+		if listType != m3u8.MEDIA {
+			return "", nil, errors.New("audio rendition is not a media playlist")
+		}
+		playlists = append(playlists, &hlsPlaylist{
+			media:    pl.(*m3u8.MediaPlaylist),
+			url:      rendition.URL,
+			prefix:   fmt.Sprintf("audio_%d_", i),
+			language: rendition.Language,
+			name:     rendition.Name,
+		})
+	}
+
+	type segmentJob struct {
+		playlist *hlsPlaylist
+		index    int
+		segment  *m3u8.MediaSegment
+	}
+
+	jobs := make([]*segmentJob, 0, mediaPl.Count())
+	for _, pl := range playlists {
+		i := 0
+		for _, seg := range pl.media.Segments {
+			if seg != nil {
+				jobs = append(jobs, &segmentJob{playlist: pl, index: i, segment: seg})
+				i++
+			}
+		}
+		if i == 0 {
+			return "", nil, errors.New("playlist has no segments")
+		}
+	}
+
+	// +---------------------+
+	// | Keys & init section |
+	// +---------------------+
+
+	// Remote URI -> Local filename
+	files := make(map[string]string)
+	downloadFile := func(pl *hlsPlaylist, uri string, name string) (string, error) {
+		if uri == "" {
+			return "", nil
+		}
+		absUri := resolveURL(pl.url, uri)
+		if filename, found := files[absUri]; found {
+			return filename, nil
+		}
+		filename := fmt.Sprintf("%s%s_%d%s", pl.prefix, name, len(files), path.Ext(stripQuery(absUri)))
+		if _, err := cd.downloadFile(ctx, absUri, headers, filepath.Join(destination, filename)); err != nil {
+			return "", err
+		}
+		files[absUri] = filename
+		return filename, nil
+	}
+
+	for _, pl := range playlists {
+		if pl.media.Key != nil && pl.media.Key.Method != "NONE" {
+			if pl.media.Key.URI, err = downloadFile(pl, pl.media.Key.URI, "key"); err != nil {
+				return "", nil, fmt.Errorf("failed to download encryption key: %w", err)
+			}
+		}
+		if pl.media.Map != nil {
+			if pl.media.Map.URI, err = downloadFile(pl, pl.media.Map.URI, "init"); err != nil {
+				return "", nil, fmt.Errorf("failed to download initialization section: %w", err)
+			}
+		}
+	}
+	for _, job := range jobs {
+		seg := job.segment
+		if seg.Key != nil && seg.Key.Method != "NONE" {
+			if seg.Key.URI, err = downloadFile(job.playlist, seg.Key.URI, "key"); err != nil {
+				return "", nil, fmt.Errorf("failed to download encryption key: %w", err)
+			}
+		}
+		if seg.Map != nil {
+			if seg.Map.URI, err = downloadFile(job.playlist, seg.Map.URI, "init"); err != nil {
+				return "", nil, fmt.Errorf("failed to download initialization section: %w", err)
+			}
+		}
+	}
+
+	// +---------------------+
+	// |      Segments       |
+	// +---------------------+
+
+	var done atomic.Int32
+	var downloadedBytes atomic.Int64
+	var firstErr error
+	var errOnce sync.Once
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	wg := sync.WaitGroup{}
+	semaphore := make(chan struct{}, hlsSegmentConcurrency)
+	for _, job := range jobs {
+		semaphore <- struct{}{}
+		wg.Add(1)
+		go func(job *segmentJob) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			absUri := resolveURL(job.playlist.url, job.segment.URI)
+			filename := fmt.Sprintf("%ssegment_%05d%s", job.playlist.prefix, job.index, path.Ext(stripQuery(absUri)))
+
+			var n int64
+			var err error
+			for attempt := 0; attempt < hlsSegmentAttempts; attempt++ {
+				if ctx.Err() != nil {
+					return
+				}
+				n, err = cd.downloadFile(ctx, absUri, headers, filepath.Join(destination, filename))
+				if err == nil {
+					break
+				}
+				time.Sleep(time.Duration(attempt+1) * time.Second)
+			}
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = fmt.Errorf("failed to download segment %d: %w", job.index, err)
+					cancel()
+				})
+				return
+			}
+
+			job.segment.URI = filename
+			onProgress(int(done.Add(1)), len(jobs), downloadedBytes.Add(n))
+		}(job)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return "", nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return "", nil, err
+	}
+
+	// +---------------------+
+	// |   Local playlists   |
+	// +---------------------+
+
+	audio := make([]*downloadedAudio, 0, len(playlists)-1)
+	for _, pl := range playlists {
+		playlistPath := filepath.Join(destination, pl.prefix+hlsPlaylistFilename)
+		if err := os.WriteFile(playlistPath, pl.media.Encode().Bytes(), 0644); err != nil {
+			return "", nil, err
+		}
+		if pl.prefix != "" {
+			audio = append(audio, &downloadedAudio{Path: playlistPath, Language: pl.language, Name: pl.name})
+		}
+	}
+
+	return filepath.Join(destination, hlsPlaylistFilename), audio, nil
+}
+
+// getHLSMediaPlaylist fetches the playlist and returns the media playlist along with its URL.
+// If the playlist is a master playlist, the variant with the highest bandwidth is fetched, and its audio renditions are returned.
+func (cd *Downloader) getHLSMediaPlaylist(ctx context.Context, playlistUrl string, headers map[string]string) (*m3u8.MediaPlaylist, string, []*hlsAudioRendition, error) {
+	pl, listType, err := cd.fetchHLSPlaylist(ctx, playlistUrl, headers)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	if listType == m3u8.MEDIA {
+		return pl.(*m3u8.MediaPlaylist), playlistUrl, nil, nil
+	}
+
+	masterPl := pl.(*m3u8.MasterPlaylist)
+	var best *m3u8.Variant
+	for _, variant := range masterPl.Variants {
+		if variant == nil || variant.Iframe {
+			continue
+		}
+		if best == nil || variant.Bandwidth > best.Bandwidth {
+			best = variant
+		}
+	}
+	if best == nil {
+		return nil, "", nil, errors.New("master playlist has no variants")
+	}
+
+	variantUrl := resolveURL(playlistUrl, best.URI)
+	cd.logger.Debug().Str("resolution", best.Resolution).Uint32("bandwidth", best.Bandwidth).Msg("onlinestream downloader: Selected HLS variant")
+
+	pl, listType, err = cd.fetchHLSPlaylist(ctx, variantUrl, headers)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if listType != m3u8.MEDIA {
+		return nil, "", nil, errors.New("variant is not a media playlist")
+	}
+
+	return pl.(*m3u8.MediaPlaylist), variantUrl, getHLSAudioRenditions(playlistUrl, best), nil
+}
+
+// getHLSAudioRenditions returns the audio renditions of the variant's audio group that are stored in their own playlist.
+// Renditions without a URI are already in the variant's stream.
+func getHLSAudioRenditions(playlistUrl string, variant *m3u8.Variant) []*hlsAudioRendition {
+	ret := make([]*hlsAudioRendition, 0)
+	if variant.Audio == "" {
+		return ret
+	}
+
+	seen := make(map[string]struct{})
+	for _, alt := range variant.Alternatives {
+		if alt == nil || alt.Type != "AUDIO" || alt.GroupId != variant.Audio || alt.URI == "" {
+			continue
+		}
+		altUrl := resolveURL(playlistUrl, alt.URI)
+		if _, ok := seen[altUrl]; ok {
+			continue
+		}
+		seen[altUrl] = struct{}{}
+		ret = append(ret, &hlsAudioRendition{
+			URL:      altUrl,
+			Language: alt.Language,
+			Name:     alt.Name,
+		})
+	}
+
+	return ret
+}
+
+func (cd *Downloader) fetchHLSPlaylist(ctx context.Context, playlistUrl string, headers map[string]string) (m3u8.Playlist, m3u8.ListType, error) {
+	resp, err := cd.request(ctx, playlistUrl, headers)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if !isHLSPlaylist(body) {
+		return nil, 0, ErrNotHLS
+	}
+
+	pl, listType, err := m3u8.DecodeFrom(bytes.NewReader(body), true)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to decode playlist: %w", err)
+	}
+
+	return pl, listType, nil
+}
+
+// isHLSPlaylist returns true if the data is an M3U8 playlist.
+func isHLSPlaylist(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))), []byte("#EXTM3U"))
+}
+
+// resolveURL resolves a URI found in a playlist against the playlist URL.
+func resolveURL(base string, ref string) string {
+	baseUrl, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	refUrl, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return baseUrl.ResolveReference(refUrl).String()
+}
+
+func stripQuery(uri string) string {
+	if u, err := url.Parse(uri); err == nil {
+		return u.Path
+	}
+	return uri
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// request sends a GET request with the headers from the provider.
+func (cd *Downloader) request(ctx context.Context, uri string, headers map[string]string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+
+	resp, err := cd.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	return resp, nil
+}
+
+// downloadFile downloads the file to the given path and returns the number of bytes written.
+func (cd *Downloader) downloadFile(ctx context.Context, uri string, headers map[string]string, dest string) (int64, error) {
+	resp, err := cd.request(ctx, uri, headers)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	f, err := os.Create(dest)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	return io.Copy(f, resp.Body)
+}
@@ -0,0 +1,260 @@
+package onlinestream_downloader
+
+import (
+	"seanime/internal/database/db"
+	"seanime/internal/database/models"
+	"seanime/internal/events"
+	"seanime/internal/util"
+	"sync"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+)
+
+const (
+	QueueStatusNotStarted  QueueStatus = "not_started"
+	QueueStatusDownloading QueueStatus = "downloading"
+	QueueStatusErrored     QueueStatus = "errored"
+)
+
+type (
+	// Queue is used to manage the download queue.
+	// It feeds the downloader with the next item in the queue.
+	Queue struct {
+		logger         *zerolog.Logger
+		mu             sync.Mutex
+		db             *db.Database
+		current        *QueueInfo
+		runCh          chan *QueueInfo // Channel to tell downloader to run the next item
+		active         bool
+		wsEventManager events.WSEventManagerInterface
+	}
+
+	QueueStatus string
+
+	// QueueInfo stores details about the download progress of an episode.
+	QueueInfo struct {
+		DownloadID
+		Title    string        `json:"title"`
+		Source   *SourceData   `json:"-"`
+		Status   QueueStatus   `json:"status"`
+		Progress *ProgressInfo `json:"progress"`
+	}
+
+	// ProgressInfo is sent to the client while the episode is being downloaded.
+	ProgressInfo struct {
+		// "downloading" or "remuxing"
+		Step string `json:"step"`
+		// Number of downloaded segments, 0 for non-HLS sources.
+		DownloadedSegments int   `json:"downloadedSegments"`
+		TotalSegments      int   `json:"totalSegments"`
+		DownloadedBytes    int64 `json:"downloadedBytes"`
+		// Between 0 and 100.
+		Percentage float64 `json:"percentage"`
+	}
+)
+
+func NewQueue(db *db.Database, logger *zerolog.Logger, wsEventManager events.WSEventManagerInterface, runCh chan *QueueInfo) *Queue {
+	return &Queue{
+		logger:         logger,
+		db:             db,
+		runCh:          runCh,
+		wsEventManager: wsEventManager,
+	}
+}
+
+// Add adds an episode to the download queue.
+// It tells the queue to download the next item if possible.
+func (q *Queue) Add(id DownloadID, title string, source *SourceData, runNext bool) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	marshalled, err := json.Marshal(source)
+	if err != nil {
+		q.logger.Error().Err(err).Msgf("Failed to marshal source for id %v", id)
+		return err
+	}
+
+	err = q.db.InsertOnlinestreamDownloadQueueItem(&models.OnlinestreamDownloadQueueItem{
+		BaseModel:     models.BaseModel{},
+		Provider:      id.Provider,
+		MediaID:       id.MediaId,
+		EpisodeNumber: id.EpisodeNumber,
+		Dubbed:        id.Dubbed,
+		Title:         title,
+		SourceData:    marshalled,
+		Status:        string(QueueStatusNotStarted),
+	})
+	if err != nil {
+		q.logger.Error().Err(err).Msgf("Failed to insert onlinestream download queue item for id %v", id)
+		return err
+	}
+
+	q.logger.Info().Msgf("onlinestream downloader: Added episode %d of %d to download queue", id.EpisodeNumber, id.MediaId)
+
+	q.wsEventManager.SendEvent(events.OnlinestreamDownloadQueueUpdated, nil)
+
+	if runNext && q.active {
+		// Tells queue to run next if possible
+		go q.runNext()
+	}
+
+	return nil
+}
+
+func (q *Queue) HasCompleted(queueInfo *QueueInfo) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	id := queueInfo.DownloadID
+	if queueInfo.Status == QueueStatusNotStarted {
+		q.logger.Debug().Msgf("onlinestream downloader: Putting episode %d of %d back in the queue", id.EpisodeNumber, id.MediaId)
+		_ = q.db.UpdateOnlinestreamDownloadQueueItemStatus(id.Provider, id.MediaId, id.EpisodeNumber, id.Dubbed, string(QueueStatusNotStarted))
+	} else if queueInfo.Status == QueueStatusErrored {
+		q.logger.Warn().Msgf("onlinestream downloader: Errored episode %d of %d", id.EpisodeNumber, id.MediaId)
+		// Update the status of the current item in the database.
+		_ = q.db.UpdateOnlinestreamDownloadQueueItemStatus(id.Provider, id.MediaId, id.EpisodeNumber, id.Dubbed, string(QueueStatusErrored))
+	} else {
+		q.logger.Debug().Msgf("onlinestream downloader: Dequeueing episode %d of %d", id.EpisodeNumber, id.MediaId)
+		// Dequeue the item from the database.
+		_, err := q.db.DequeueOnlinestreamDownloadQueueItem()
+		if err != nil {
+			q.logger.Error().Err(err).Msgf("Failed to dequeue onlinestream download queue item for id %v", id)
+			return
+		}
+	}
+
+	q.wsEventManager.SendEvent(events.OnlinestreamDownloadQueueUpdated, nil)
+
+	// Reset current item
+	q.current = nil
+
+	if q.active {
+		// Tells queue to run next if possible
+		q.runNext()
+	}
+}
+
+// Run activates the queue and invokes runNext
+func (q *Queue) Run() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.active {
+		q.logger.Debug().Msg("onlinestream downloader: Starting queue")
+	}
+
+	q.active = true
+
+	// Tells queue to run next if possible
+	q.runNext()
+}
+
+// Stop deactivates the queue
+func (q *Queue) Stop() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.active {
+		q.logger.Debug().Msg("onlinestream downloader: Stopping queue")
+	}
+
+	q.active = false
+}
+
+// IsActive returns true if the queue is running.
+func (q *Queue) IsActive() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.active
+}
+
+// runNext runs the next item in the queue.
+//   - Checks if there is a current item, if so, it returns.
+//   - If nothing is running, it gets the next item (QueueInfo) from the database, sets it as current and sends it to the downloader.
+func (q *Queue) runNext() {
+
+	q.logger.Debug().Msg("onlinestream downloader: Processing next item in queue")
+
+	// Catch panic in runNext, so it doesn't bubble up and stop goroutines.
+	defer util.HandlePanicInModuleThen("internal/onlinestream/downloader/runNext", func() {
+		q.logger.Error().Msg("onlinestream downloader: Panic in 'runNext'")
+	})
+
+	if q.current != nil {
+		q.logger.Debug().Msg("onlinestream downloader: Current item is not nil")
+		return
+	}
+
+	// Get next item from the database.
+	next, _ := q.db.GetNextOnlinestreamDownloadQueueItem()
+	if next == nil {
+		q.logger.Debug().Msg("onlinestream downloader: No next item in queue")
+		return
+	}
+
+	id := DownloadID{
+		Provider:      next.Provider,
+		MediaId:       next.MediaID,
+		EpisodeNumber: next.EpisodeNumber,
+		Dubbed:        next.Dubbed,
+	}
+
+	q.wsEventManager.SendEvent(events.OnlinestreamDownloadQueueUpdated, nil)
+	// Update status
+	_ = q.db.UpdateOnlinestreamDownloadQueueItemStatus(id.Provider, id.MediaId, id.EpisodeNumber, id.Dubbed, string(QueueStatusDownloading))
+
+	// Set the current item.
+	q.current = &QueueInfo{
+		DownloadID: id,
+		Title:      next.Title,
+		Status:     QueueStatusDownloading,
+		Progress:   &ProgressInfo{Step: "downloading"},
+	}
+
+	// Unmarshal the source data.
+	err := json.Unmarshal(next.SourceData, &q.current.Source)
+	if err != nil || q.current.Source == nil || q.current.Source.VideoSource == nil {
+		q.logger.Error().Err(err).Msgf("Failed to unmarshal source for id %v", id)
+		_ = q.db.UpdateOnlinestreamDownloadQueueItemStatus(id.Provider, id.MediaId, id.EpisodeNumber, id.Dubbed, string(QueueStatusErrored))
+		q.current = nil
+		// The item is now marked as errored, try the next one
+		q.runNext()
+		return
+	}
+
+	q.logger.Info().Msgf("onlinestream downloader: Running next item in queue: episode %d of %d", id.EpisodeNumber, id.MediaId)
+
+	// Tell Downloader to run
+	q.runCh <- q.current
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (q *Queue) GetCurrent() (qi *QueueInfo, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.current == nil {
+		return nil, false
+	}
+
+	// Return a copy since the progress is updated while downloading
+	progress := *q.current.Progress
+	ret := *q.current
+	ret.Progress = &progress
+
+	return &ret, true
+}
+
+// updateProgress sends the progress of the current item to the client.
+func (q *Queue) updateProgress(queueInfo *QueueInfo, f func(progress *ProgressInfo)) {
+	q.mu.Lock()
+	f(queueInfo.Progress)
+	progress := *queueInfo.Progress
+	info := *queueInfo
+	info.Progress = &progress
+	q.mu.Unlock()
+
+	q.wsEventManager.SendEvent(events.OnlinestreamDownloadProgress, &info)
+}
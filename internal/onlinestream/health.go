@@ -0,0 +1,211 @@
+package onlinestream
+
+import (
+	"math"
+	"seanime/internal/util/filecache"
+	"sort"
+	"sync"
+	"time"
+)
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Health
+// - Each attempt to fetch episode servers is recorded for the provider and for each server
+// - The score is used to order the providers and servers when fetching episode sources
+// - Records are persisted in a permanent bucket
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+const (
+	healthBucketName = "onlinestream-health"
+	healthKey        = "1"
+	// A failure within this window lowers the score.
+	healthRecentFailureWindow = 15 * time.Minute
+)
+
+type (
+	// ProviderHealth contains the health record of a provider or a provider server.
+	ProviderHealth struct {
+		Provider string `json:"provider"`
+		// Empty for the provider record.
+		Server           string     `json:"server,omitempty"`
+		Successes        int        `json:"successes"`
+		Failures         int        `json:"failures"`
+		AverageLatencyMs int64      `json:"averageLatencyMs"`
+		LastSuccess      *time.Time `json:"lastSuccess,omitempty"`
+		LastFailure      *time.Time `json:"lastFailure,omitempty"`
+		LastError        string     `json:"lastError,omitempty"`
+		// Score between 0 and 100, computed when the record is returned.
+		Score float64 `json:"score"`
+	}
+
+	healthTracker struct {
+		mu         sync.Mutex
+		fileCacher *filecache.Cacher
+		// Key -> Record
+		//	e.g. "zoro", "zoro$vidstreaming"
+		records map[string]*ProviderHealth
+		loaded  bool
+	}
+)
+
+func newHealthTracker(fileCacher *filecache.Cacher) *healthTracker {
+	return &healthTracker{
+		fileCacher: fileCacher,
+		records:    make(map[string]*ProviderHealth),
+	}
+}
+
+func getHealthKey(provider string, server string) string {
+	if server == "" {
+		return provider
+	}
+	return provider + "$" + server
+}
+
+// load reads the persisted records once.
+// The caller must hold the lock.
+func (h *healthTracker) load() {
+	if h.loaded {
+		return
+	}
+	h.loaded = true
+
+	if h.fileCacher == nil {
+		return
+	}
+
+	var records map[string]*ProviderHealth
+	if found, _ := h.fileCacher.GetPerm(filecache.NewPermanentBucket(healthBucketName), healthKey, &records); found && records != nil {
+		h.records = records
+	}
+}
+
+// save persists the records.
+// The caller must hold the lock.
+func (h *healthTracker) save() {
+	if h.fileCacher == nil {
+		return
+	}
+	_ = h.fileCacher.SetPerm(filecache.NewPermanentBucket(healthBucketName), healthKey, h.records)
+}
+
+func (h *healthTracker) recordSuccess(provider string, server string, latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.load()
+
+	record := h.getOrCreate(provider, server)
+	now := time.Now()
+	// Running average of the latency of successful attempts
+	record.AverageLatencyMs = (record.AverageLatencyMs*int64(record.Successes) + latency.Milliseconds()) / int64(record.Successes+1)
+	record.Successes++
+	record.LastSuccess = &now
+
+	h.save()
+}
+
+func (h *healthTracker) recordFailure(provider string, server string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.load()
+
+	record := h.getOrCreate(provider, server)
+	now := time.Now()
+	record.Failures++
+	record.LastFailure = &now
+	if err != nil {
+		record.LastError = err.Error()
+	}
+
+	h.save()
+}
+
+func (h *healthTracker) getOrCreate(provider string, server string) *ProviderHealth {
+	key := getHealthKey(provider, server)
+	record, found := h.records[key]
+	if !found || record == nil {
+		record = &ProviderHealth{
+			Provider: provider,
+			Server:   server,
+		}
+		h.records[key] = record
+	}
+	return record
+}
+
+// getScore returns the score of the provider or server.
+// Providers and servers without any record have a neutral score.
+func (h *healthTracker) getScore(provider string, server string) float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.load()
+
+	record, found := h.records[getHealthKey(provider, server)]
+	if !found || record == nil {
+		return computeHealthScore(&ProviderHealth{})
+	}
+	return computeHealthScore(record)
+}
+
+// list returns a copy of all the records, sorted by provider and server.
+func (h *healthTracker) list() []*ProviderHealth {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.load()
+
+	ret := make([]*ProviderHealth, 0, len(h.records))
+	for _, record := range h.records {
+		r := *record
+		r.Score = computeHealthScore(record)
+		ret = append(ret, &r)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Provider != ret[j].Provider {
+			return ret[i].Provider < ret[j].Provider
+		}
+		return ret[i].Server < ret[j].Server
+	})
+
+	return ret
+}
+
+func (h *healthTracker) reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.loaded = true
+	h.records = make(map[string]*ProviderHealth)
+	h.save()
+}
+
+// computeHealthScore returns a score between 0 and 100.
+//   - The success rate is smoothed so that a single attempt doesn't decide the score
+//   - Slow providers lose up to 20 points
+//   - A recent failure costs 20 points
+func computeHealthScore(record *ProviderHealth) float64 {
+	successRate := float64(record.Successes+1) / float64(record.Successes+record.Failures+2)
+	score := successRate * 100
+
+	latencyPenalty := math.Min(float64(record.AverageLatencyMs)/1000, 10) * 2
+	score -= latencyPenalty
+
+	if record.LastFailure != nil && time.Since(*record.LastFailure) < healthRecentFailureWindow {
+		if record.LastSuccess == nil || record.LastSuccess.Before(*record.LastFailure) {
+			score -= 20
+		}
+	}
+
+	return math.Round(math.Max(0, math.Min(100, score))*10) / 10
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// GetProviderHealth returns the health records of the providers and their servers.
+func (r *Repository) GetProviderHealth() []*ProviderHealth {
+	return r.health.list()
+}
+
+// ResetProviderHealth removes all the health records.
+func (r *Repository) ResetProviderHealth() {
+	r.health.reset()
+}
@@ -0,0 +1,78 @@
+package onlinestream
+
+import (
+	"errors"
+	"seanime/internal/util/filecache"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthTracker(t *testing.T) {
+	fileCacher, err := filecache.NewCacher(t.TempDir())
+	require.NoError(t, err)
+
+	h := newHealthTracker(fileCacher)
+
+	// Unknown providers have a neutral score
+	require.Equal(t, 50.0, h.getScore("zoro", ""))
+
+	h.recordSuccess("zoro", "", 500*time.Millisecond)
+	h.recordSuccess("zoro", "vidstreaming", 500*time.Millisecond)
+	h.recordFailure("gogoanime", "", errors.New("extraction failed"))
+
+	require.Greater(t, h.getScore("zoro", ""), h.getScore("gogoanime", ""))
+	require.Greater(t, h.getScore("zoro", ""), h.getScore("animepahe", ""))
+
+	// Records are persisted
+	h2 := newHealthTracker(fileCacher)
+	records := h2.list()
+	require.Len(t, records, 3)
+	require.Equal(t, "gogoanime", records[0].Provider)
+	require.Equal(t, "extraction failed", records[0].LastError)
+	require.Equal(t, 1, records[0].Failures)
+	require.Equal(t, "zoro", records[1].Provider)
+	require.Equal(t, "", records[1].Server)
+	require.Equal(t, "vidstreaming", records[2].Server)
+
+	h2.reset()
+	require.Len(t, newHealthTracker(fileCacher).list(), 0)
+}
+
+func TestComputeHealthScore(t *testing.T) {
+	lastFailure := time.Now()
+
+	tests := []struct {
+		name     string
+		record   *ProviderHealth
+		expected float64
+	}{
+		{
+			name:     "No records",
+			record:   &ProviderHealth{},
+			expected: 50,
+		},
+		{
+			name:     "Always successful",
+			record:   &ProviderHealth{Successes: 8},
+			expected: 90,
+		},
+		{
+			name:     "Slow",
+			record:   &ProviderHealth{Successes: 8, AverageLatencyMs: 5000},
+			expected: 80,
+		},
+		{
+			name:     "Recent failure",
+			record:   &ProviderHealth{Successes: 2, Failures: 2, LastFailure: &lastFailure},
+			expected: 30,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, computeHealthScore(tt.record))
+		})
+	}
+}
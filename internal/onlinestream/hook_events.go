@@ -0,0 +1,50 @@
+package onlinestream
+
+import (
+	"seanime/internal/api/anilist"
+	"seanime/internal/hook_resolver"
+)
+
+// OnlinestreamEpisodeListRequestedEvent is triggered when the episode list of a media is requested from a provider.
+// Prevent default to skip the default behavior and return the modified episodes.
+// If the modified episodes are nil, an error will be returned.
+type OnlinestreamEpisodeListRequestedEvent struct {
+	hook_resolver.Event
+	Provider string             `json:"provider"`
+	Media    *anilist.BaseAnime `json:"media"`
+	Dubbed   bool               `json:"dubbed"`
+	// Empty episode list, will be used if the hook prevents the default behavior
+	Episodes []*Episode `json:"episodes"`
+}
+
+// OnlinestreamEpisodeListEvent is triggered when the episode list is being returned.
+type OnlinestreamEpisodeListEvent struct {
+	hook_resolver.Event
+	Provider string             `json:"provider"`
+	Media    *anilist.BaseAnime `json:"media"`
+	Dubbed   bool               `json:"dubbed"`
+	Episodes []*Episode         `json:"episodes"`
+}
+
+// OnlinestreamEpisodeSourceRequestedEvent is triggered when the video sources of an episode are requested.
+// This event happens before the providers are queried.
+// Prevent default to skip the default behavior and return the modified episode source.
+// If the modified episode source is nil, an error will be returned.
+type OnlinestreamEpisodeSourceRequestedEvent struct {
+	hook_resolver.Event
+	Provider      string `json:"provider"`
+	MediaId       int    `json:"mediaId"`
+	EpisodeNumber int    `json:"episodeNumber"`
+	Dubbed        bool   `json:"dubbed"`
+	Year          int    `json:"year"`
+	// Empty episode source object, will be used if the hook prevents the default behavior
+	EpisodeSource *EpisodeSource `json:"episodeSource"`
+}
+
+// OnlinestreamEpisodeSourceEvent is triggered when the video sources of an episode are being returned.
+// The video sources can be modified, e.g. to rewrite their URLs or headers.
+type OnlinestreamEpisodeSourceEvent struct {
+	hook_resolver.Event
+	MediaId       int            `json:"mediaId"`
+	EpisodeSource *EpisodeSource `json:"episodeSource"`
+}
@@ -9,10 +9,13 @@ import (
 	"seanime/internal/api/metadata"
 	"seanime/internal/database/db"
 	"seanime/internal/extension"
+	"seanime/internal/hook"
 	"seanime/internal/platforms/platform"
 	"seanime/internal/util/filecache"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -25,6 +28,14 @@ type (
 		platform              platform.Platform
 		anilistBaseAnimeCache *anilist.BaseAnimeCache
 		db                    *db.Database
+		health                *healthTracker
+		settingsMu            sync.RWMutex
+		settings              Settings
+	}
+
+	Settings struct {
+		// Providers tried, in order of health, when the requested provider fails to return the episode sources.
+		FallbackProviders []string
 	}
 )
 
@@ -42,7 +53,9 @@ type (
 	}
 
 	EpisodeSource struct {
-		Number       int            `json:"number"`
+		Number int `json:"number"`
+		// Provider that returned the sources, it can differ from the requested provider if it failed.
+		Provider     string         `json:"provider,omitempty"`
 		VideoSources []*VideoSource `json:"videoSources"`
 		Subtitles    []*Subtitle    `json:"subtitles,omitempty"`
 	}
@@ -84,6 +97,18 @@ func NewRepository(opts *NewRepositoryOptions) *Repository {
 		anilistBaseAnimeCache: anilist.NewBaseAnimeCache(),
 		platform:              opts.Platform,
 		db:                    opts.Database,
+		health:                newHealthTracker(opts.FileCacher),
+	}
+}
+
+func (r *Repository) SetSettings(settings *Settings) {
+	if settings == nil {
+		settings = &Settings{}
+	}
+	r.settingsMu.Lock()
+	defer r.settingsMu.Unlock()
+	r.settings = Settings{
+		FallbackProviders: lo.Uniq(lo.Compact(settings.FallbackProviders)),
 	}
 }
 
@@ -147,6 +172,27 @@ func (r *Repository) GetMediaEpisodes(provider string, media *anilist.BaseAnime,
 		return episodes, nil
 	}
 
+	// Trigger hook event
+	reqEvent := &OnlinestreamEpisodeListRequestedEvent{
+		Provider: provider,
+		Media:    media,
+		Dubbed:   dubbed,
+		Episodes: episodes,
+	}
+	err := hook.GlobalHookManager.OnOnlinestreamEpisodeListRequested().Trigger(reqEvent)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("onlinestream: Exception occurred while triggering hook event")
+		return nil, fmt.Errorf("onlinestream: Error in hook, %w", err)
+	}
+
+	// Default prevented, return the episodes
+	if reqEvent.DefaultPrevented {
+		if reqEvent.Episodes == nil {
+			return nil, fmt.Errorf("onlinestream: No episodes returned by hook event")
+		}
+		return reqEvent.Episodes, nil
+	}
+
 	// +---------------------+
 	// |       Anizip        |
 	// +---------------------+
@@ -222,11 +268,51 @@ func (r *Repository) GetMediaEpisodes(provider string, media *anilist.BaseAnime,
 		return item != nil
 	})
 
-	return episodes, nil
+	// Trigger hook event
+	ev := &OnlinestreamEpisodeListEvent{
+		Provider: provider,
+		Media:    media,
+		Dubbed:   dubbed,
+		Episodes: episodes,
+	}
+	err = hook.GlobalHookManager.OnOnlinestreamEpisodeList().Trigger(ev)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("onlinestream: Exception occurred while triggering hook event")
+	}
+
+	return ev.Episodes, nil
 }
 
+// GetEpisodeSources returns the video sources of the episode.
+// If the provider fails, the fallback providers are tried in order of health.
 func (r *Repository) GetEpisodeSources(provider string, mId int, number int, dubbed bool, year int) (*EpisodeSource, error) {
 
+	// Trigger hook event
+	reqEvent := &OnlinestreamEpisodeSourceRequestedEvent{
+		Provider:      provider,
+		MediaId:       mId,
+		EpisodeNumber: number,
+		Dubbed:        dubbed,
+		Year:          year,
+		EpisodeSource: &EpisodeSource{
+			Number:       number,
+			VideoSources: make([]*VideoSource, 0),
+		},
+	}
+	err := hook.GlobalHookManager.OnOnlinestreamEpisodeSourceRequested().Trigger(reqEvent)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("onlinestream: Exception occurred while triggering hook event")
+		return nil, fmt.Errorf("onlinestream: Error in hook, %w", err)
+	}
+
+	// Default prevented, return the episode source
+	if reqEvent.DefaultPrevented {
+		if reqEvent.EpisodeSource == nil {
+			return nil, fmt.Errorf("onlinestream: No episode source returned by hook event")
+		}
+		return r.triggerEpisodeSourceEvent(mId, reqEvent.EpisodeSource), nil
+	}
+
 	// +---------------------+
 	// |        Media        |
 	// +---------------------+
@@ -236,6 +322,77 @@ func (r *Repository) GetEpisodeSources(provider string, mId int, number int, dub
 		return nil, err
 	}
 
+	// +---------------------+
+	// |      Failover       |
+	// +---------------------+
+
+	var lastErr error
+	for _, p := range r.getProviderChain(provider) {
+		sources, err := r.getProviderEpisodeSources(p, media, number, dubbed, year)
+		if err != nil {
+			lastErr = err
+			r.logger.Warn().Err(err).Str("provider", p).Int("episode", number).Msg("onlinestream: Failed to get episode sources, trying next provider")
+			continue
+		}
+		if p != provider {
+			r.logger.Info().Str("provider", p).Str("requestedProvider", provider).Int("episode", number).Msg("onlinestream: Using fallback provider")
+		}
+		return r.triggerEpisodeSourceEvent(mId, sources), nil
+	}
+
+	if lastErr == nil {
+		lastErr = ErrNoVideoSourceFound
+	}
+
+	return nil, lastErr
+}
+
+// triggerEpisodeSourceEvent lets hooks modify the episode source before it is returned.
+func (r *Repository) triggerEpisodeSourceEvent(mId int, sources *EpisodeSource) *EpisodeSource {
+	ev := &OnlinestreamEpisodeSourceEvent{
+		MediaId:       mId,
+		EpisodeSource: sources,
+	}
+	err := hook.GlobalHookManager.OnOnlinestreamEpisodeSource().Trigger(ev)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("onlinestream: Exception occurred while triggering hook event")
+		return sources
+	}
+	if ev.EpisodeSource == nil {
+		return sources
+	}
+	return ev.EpisodeSource
+}
+
+// getProviderChain returns the requested provider followed by the installed fallback providers, ordered by health.
+func (r *Repository) getProviderChain(provider string) []string {
+	r.settingsMu.RLock()
+	fallbacks := make([]string, 0, len(r.settings.FallbackProviders))
+	for _, p := range r.settings.FallbackProviders {
+		if p == provider {
+			continue
+		}
+		if _, ok := extension.GetExtension[extension.OnlinestreamProviderExtension](r.providerExtensionBank, p); !ok {
+			continue
+		}
+		fallbacks = append(fallbacks, p)
+	}
+	r.settingsMu.RUnlock()
+
+	sort.SliceStable(fallbacks, func(i, j int) bool {
+		return r.health.getScore(fallbacks[i], "") > r.health.getScore(fallbacks[j], "")
+	})
+
+	ret := make([]string, 0, len(fallbacks)+1)
+	if provider != "" {
+		ret = append(ret, provider)
+	}
+	return append(ret, fallbacks...)
+}
+
+// getProviderEpisodeSources returns the video sources of the episode from the specified provider.
+func (r *Repository) getProviderEpisodeSources(provider string, media *anilist.BaseAnime, number int, dubbed bool, year int) (*EpisodeSource, error) {
+
 	// +---------------------+
 	// |   Episode servers   |
 	// +---------------------+
@@ -250,6 +407,7 @@ func (r *Repository) GetEpisodeSources(provider string, mId int, number int, dub
 		if ep.Number == number {
 			s := &EpisodeSource{
 				Number:       ep.Number,
+				Provider:     provider,
 				VideoSources: make([]*VideoSource, 0),
 			}
 			for _, es := range ep.Servers {
@@ -279,7 +437,7 @@ func (r *Repository) GetEpisodeSources(provider string, mId int, number int, dub
 		}
 	}
 
-	if sources == nil {
+	if sources == nil || len(sources.VideoSources) == 0 {
 		return nil, ErrNoVideoSourceFound
 	}
 
@@ -8,7 +8,10 @@ import (
 	hibikeonlinestream "seanime/internal/extension/hibike/onlinestream"
 	onlinestream_providers "seanime/internal/onlinestream/providers"
 	"seanime/internal/util/comparison"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 )
 
 var (
@@ -80,6 +83,10 @@ func (r *Repository) getEpisodeContainer(provider string, media *anilist.BaseAni
 		var err error
 		providerEpisodeList, err = r.getProviderEpisodeList(provider, media, dubbed, year)
 		if err != nil {
+			// Not finding the anime doesn't mean the provider is unhealthy
+			if !errors.Is(err, ErrNoAnimeFound) && !errors.Is(err, ErrNoEpisodes) {
+				r.health.recordFailure(provider, "", err)
+			}
 			r.logger.Error().Err(err).Msg("onlinestream: Failed to get provider episodes")
 			return nil, err // ErrNoAnimeFound or ErrNoEpisodes
 		}
@@ -165,6 +172,7 @@ func (r *Repository) getEpisodeContainer(provider string, media *anilist.BaseAni
 }
 
 // getProviderEpisodeServers gets all the available servers for the episode.
+// The servers are ordered by health and each attempt is recorded.
 // It returns errNoEpisodeSourceFound if no sources are found.
 //
 // Example:
@@ -179,18 +187,37 @@ func (r *Repository) getProviderEpisodeServers(provider string, episodeDetails *
 		return nil, fmt.Errorf("provider extension '%s' not found", provider)
 	}
 
-	for _, episodeServer := range providerExtension.GetProvider().GetSettings().EpisodeServers {
+	episodeServers := slices.Clone(providerExtension.GetProvider().GetSettings().EpisodeServers)
+	sort.SliceStable(episodeServers, func(i, j int) bool {
+		return r.health.getScore(provider, episodeServers[i]) > r.health.getScore(provider, episodeServers[j])
+	})
+
+	start := time.Now()
+	var lastErr error
+	for _, episodeServer := range episodeServers {
+		serverStart := time.Now()
 		res, err := providerExtension.GetProvider().FindEpisodeServer(episodeDetails, episodeServer)
-		if err == nil {
+		if err == nil && res != nil && len(res.VideoSources) > 0 {
+			r.health.recordSuccess(provider, episodeServer, time.Since(serverStart))
 			// Add the server to the list for the episode
 			providerServers = append(providerServers, res)
+			continue
 		}
+		if err == nil {
+			err = errNoEpisodeSourceFound
+		}
+		lastErr = err
+		r.health.recordFailure(provider, episodeServer, err)
+		r.logger.Debug().Err(err).Str("provider", provider).Str("server", episodeServer).Msg("onlinestream: Failed to get episode server, trying next server")
 	}
 
 	if len(providerServers) == 0 {
+		r.health.recordFailure(provider, "", lastErr)
 		return nil, errNoEpisodeSourceFound
 	}
 
+	r.health.recordSuccess(provider, "", time.Since(start))
+
 	return providerServers, nil
 }
 
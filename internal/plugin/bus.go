@@ -0,0 +1,289 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"seanime/internal/extension"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrBusPluginNotLoaded  = errors.New("plugin: Plugin not loaded")
+	ErrBusTopicNotDeclared = errors.New("plugin: Topic not declared")
+	ErrBusAccessDenied     = errors.New("plugin: Access to plugin denied")
+	ErrBusFunctionNotFound = errors.New("plugin: Function not found")
+	ErrBusInvalidPayload   = errors.New("plugin: Invalid payload")
+)
+
+// GlobalBus lets plugins publish events to each other and call the functions they export.
+//
+// Payloads, arguments and results are passed as JSON so that no value is shared between the plugin runtimes.
+// Publishing and subscribing is restricted to the topics declared in the publisher's manifest,
+// and calling to the functions it declares as exports.
+// The subscriber and the caller must be granted access to the topics and functions in their own manifest.
+var GlobalBus = NewBus()
+
+type (
+	// Bus routes the events and calls between the loaded plugins.
+	Bus struct {
+		mu          sync.RWMutex
+		plugins     map[string]*extension.Extension      // Extension ID -> loaded plugin
+		exports     map[string]map[string]BusFunction    // Extension ID -> function name -> function
+		subscribers map[string]map[string]*busSubscriber // "publisher/topic" -> subscription ID -> subscriber
+	}
+
+	// BusEvent is delivered to the subscribers of a topic.
+	BusEvent struct {
+		Publisher string
+		Topic     string
+		Payload   []byte // JSON encoded payload
+		Timestamp time.Time
+	}
+
+	// BusHandler receives the events of a topic.
+	// It is called synchronously by Publish and must not block.
+	BusHandler func(event *BusEvent)
+
+	// BusFunction is a function exported by a plugin.
+	// The arguments are a JSON encoded array and the result is JSON encoded.
+	BusFunction func(args []byte) ([]byte, error)
+
+	busSubscriber struct {
+		id         string
+		subscriber string // Extension ID of the subscriber
+		handler    BusHandler
+	}
+)
+
+func NewBus() *Bus {
+	return &Bus{
+		plugins:     make(map[string]*extension.Extension),
+		exports:     make(map[string]map[string]BusFunction),
+		subscribers: make(map[string]map[string]*busSubscriber),
+	}
+}
+
+// Register adds the plugin to the bus when it is loaded.
+func (b *Bus) Register(ext *extension.Extension) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.plugins[ext.ID] = ext
+}
+
+// Unregister removes the plugin, its exports and its subscriptions from the bus when it is unloaded.
+// Subscriptions of other plugins to its topics are kept and receive its events once it is loaded again.
+func (b *Bus) Unregister(extId string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.plugins, extId)
+	delete(b.exports, extId)
+	for key, subscribers := range b.subscribers {
+		for id, sub := range subscribers {
+			if sub.subscriber == extId {
+				delete(subscribers, id)
+			}
+		}
+		if len(subscribers) == 0 {
+			delete(b.subscribers, key)
+		}
+	}
+}
+
+// Publish delivers the event to the subscribers of the topic and returns the number of subscribers.
+// The topic must be declared in the publisher's manifest and the payload must match its schema.
+func (b *Bus) Publish(extId string, topic string, payload []byte) (int, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	publisher, ok := b.plugins[extId]
+	if !ok {
+		return 0, ErrBusPluginNotLoaded
+	}
+
+	declared, ok := getBusTopic(publisher, topic)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrBusTopicNotDeclared, topic)
+	}
+
+	if err := validateBusPayload(declared.Schema, payload); err != nil {
+		return 0, err
+	}
+
+	subscribers := b.subscribers[busTopicKey(extId, topic)]
+	for _, sub := range subscribers {
+		sub.handler(&BusEvent{
+			Publisher: extId,
+			Topic:     topic,
+			Payload:   payload,
+			Timestamp: time.Now(),
+		})
+	}
+
+	return len(subscribers), nil
+}
+
+// Subscribe registers the handler for the events published by the other plugin to the topic.
+// The subscriber must be granted access to the topic in its manifest.
+// It returns the subscription ID used to unsubscribe.
+func (b *Bus) Subscribe(extId string, publisherId string, topic string, handler BusHandler) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	subscriber, ok := b.plugins[extId]
+	if !ok {
+		return "", ErrBusPluginNotLoaded
+	}
+
+	if subscriber.Plugin == nil || !subscriber.Plugin.Permissions.CanSubscribe(publisherId, topic) {
+		return "", fmt.Errorf("%w: %s cannot subscribe to %s/%s", ErrBusAccessDenied, extId, publisherId, topic)
+	}
+
+	// The publisher can be loaded after the subscriber, the topic is only checked if it's loaded
+	if publisher, ok := b.plugins[publisherId]; ok {
+		if _, ok := getBusTopic(publisher, topic); !ok {
+			return "", fmt.Errorf("%w: %s/%s", ErrBusTopicNotDeclared, publisherId, topic)
+		}
+	}
+
+	sub := &busSubscriber{
+		id:         uuid.NewString(),
+		subscriber: extId,
+		handler:    handler,
+	}
+
+	key := busTopicKey(publisherId, topic)
+	if _, ok := b.subscribers[key]; !ok {
+		b.subscribers[key] = make(map[string]*busSubscriber)
+	}
+	b.subscribers[key][sub.id] = sub
+
+	return sub.id, nil
+}
+
+// Unsubscribe removes the subscription.
+func (b *Bus) Unsubscribe(id string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for key, subscribers := range b.subscribers {
+		if _, ok := subscribers[id]; ok {
+			delete(subscribers, id)
+			if len(subscribers) == 0 {
+				delete(b.subscribers, key)
+			}
+			return
+		}
+	}
+}
+
+// Export makes the function callable by other plugins.
+// The function must be declared in the plugin's manifest.
+func (b *Bus) Export(extId string, name string, fn BusFunction) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ext, ok := b.plugins[extId]
+	if !ok {
+		return ErrBusPluginNotLoaded
+	}
+
+	if ext.Plugin == nil || !slices.Contains(ext.Plugin.Bus.Exports, name) {
+		return fmt.Errorf("%w: %s is not declared in the manifest", ErrBusFunctionNotFound, name)
+	}
+
+	if _, ok := b.exports[extId]; !ok {
+		b.exports[extId] = make(map[string]BusFunction)
+	}
+	b.exports[extId][name] = fn
+
+	return nil
+}
+
+// Call calls a function exported by the other plugin.
+// The caller must be granted access to the function in its manifest.
+func (b *Bus) Call(extId string, targetId string, name string, args []byte) ([]byte, error) {
+	b.mu.RLock()
+	caller, ok := b.plugins[extId]
+	if !ok {
+		b.mu.RUnlock()
+		return nil, ErrBusPluginNotLoaded
+	}
+	if caller.Plugin == nil || !caller.Plugin.Permissions.CanCall(targetId, name) {
+		b.mu.RUnlock()
+		return nil, fmt.Errorf("%w: %s cannot call %s.%s", ErrBusAccessDenied, extId, targetId, name)
+	}
+	fn, ok := b.exports[targetId][name]
+	b.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s.%s", ErrBusFunctionNotFound, targetId, name)
+	}
+
+	// The function is called without holding the lock, it can call other plugins
+	return fn(args)
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func busTopicKey(publisherId string, topic string) string {
+	return publisherId + "/" + topic
+}
+
+func getBusTopic(ext *extension.Extension, topic string) (*extension.PluginBusTopic, bool) {
+	if ext.Plugin == nil {
+		return nil, false
+	}
+	return ext.Plugin.Bus.GetTopic(topic)
+}
+
+// validateBusPayload checks that the payload is an object matching the schema of the topic.
+func validateBusPayload(schema map[string]string, payload []byte) error {
+	if len(schema) == 0 {
+		return nil
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(payload, &data); err != nil || data == nil {
+		return fmt.Errorf("%w: expected an object", ErrBusInvalidPayload)
+	}
+
+	for field, fieldType := range schema {
+		optional := strings.HasSuffix(fieldType, "?")
+		fieldType = strings.TrimSuffix(fieldType, "?")
+
+		value, ok := data[field]
+		if !ok || value == nil {
+			if optional {
+				continue
+			}
+			return fmt.Errorf("%w: missing field %q", ErrBusInvalidPayload, field)
+		}
+
+		valid := true
+		switch fieldType {
+		case "string":
+			_, valid = value.(string)
+		case "number":
+			_, valid = value.(float64)
+		case "boolean":
+			_, valid = value.(bool)
+		case "object":
+			_, valid = value.(map[string]interface{})
+		case "array":
+			_, valid = value.([]interface{})
+		}
+		if !valid {
+			return fmt.Errorf("%w: field %q must be of type %s", ErrBusInvalidPayload, field, fieldType)
+		}
+	}
+
+	return nil
+}
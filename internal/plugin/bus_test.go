@@ -0,0 +1,136 @@
+package plugin
+
+import (
+	"seanime/internal/extension"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newBusTestPlugins() (*extension.Extension, *extension.Extension) {
+	publisher := &extension.Extension{
+		ID: "stats-plugin",
+		Plugin: &extension.PluginManifest{
+			Bus: extension.PluginBus{
+				Topics: []extension.PluginBusTopic{
+					{Name: "stats-updated", Schema: map[string]string{"watched": "number", "title": "string?"}},
+					{Name: "private"},
+				},
+				Exports: []string{"getStats"},
+			},
+		},
+	}
+	subscriber := &extension.Extension{
+		ID: "tracker-plugin",
+		Plugin: &extension.PluginManifest{
+			Permissions: extension.PluginPermissions{
+				Plugins: []extension.PluginAccess{
+					{ID: "stats-plugin", Topics: []string{"stats-updated"}, Functions: []string{"getStats"}},
+				},
+			},
+		},
+	}
+	return publisher, subscriber
+}
+
+func TestBus_PublishSubscribe(t *testing.T) {
+	bus := NewBus()
+	publisher, subscriber := newBusTestPlugins()
+	bus.Register(subscriber)
+
+	events := make([]*BusEvent, 0)
+	handler := func(event *BusEvent) {
+		events = append(events, event)
+	}
+
+	// The subscriber can subscribe before the publisher is loaded
+	id, err := bus.Subscribe(subscriber.ID, publisher.ID, "stats-updated", handler)
+	require.NoError(t, err)
+
+	// Access must be granted in the manifest
+	_, err = bus.Subscribe(subscriber.ID, publisher.ID, "private", handler)
+	require.ErrorIs(t, err, ErrBusAccessDenied)
+
+	_, err = bus.Publish(publisher.ID, "stats-updated", []byte(`{"watched": 1}`))
+	require.ErrorIs(t, err, ErrBusPluginNotLoaded)
+
+	bus.Register(publisher)
+
+	delivered, err := bus.Publish(publisher.ID, "stats-updated", []byte(`{"watched": 1}`))
+	require.NoError(t, err)
+	require.Equal(t, 1, delivered)
+	require.Len(t, events, 1)
+	require.Equal(t, "stats-plugin", events[0].Publisher)
+	require.JSONEq(t, `{"watched": 1}`, string(events[0].Payload))
+
+	// Undeclared topics and invalid payloads are rejected
+	_, err = bus.Publish(publisher.ID, "undeclared", []byte(`{}`))
+	require.ErrorIs(t, err, ErrBusTopicNotDeclared)
+	_, err = bus.Publish(publisher.ID, "stats-updated", []byte(`{"watched": "1"}`))
+	require.ErrorIs(t, err, ErrBusInvalidPayload)
+	_, err = bus.Publish(publisher.ID, "stats-updated", []byte(`{"title": "Bocchi"}`))
+	require.ErrorIs(t, err, ErrBusInvalidPayload)
+
+	// Topics without a schema accept any payload
+	_, err = bus.Publish(publisher.ID, "private", []byte(`"anything"`))
+	require.NoError(t, err)
+
+	bus.Unsubscribe(id)
+	delivered, err = bus.Publish(publisher.ID, "stats-updated", []byte(`{"watched": 2}`))
+	require.NoError(t, err)
+	require.Equal(t, 0, delivered)
+	require.Len(t, events, 1)
+}
+
+func TestBus_SubscriptionsSurvivePublisherReload(t *testing.T) {
+	bus := NewBus()
+	publisher, subscriber := newBusTestPlugins()
+	bus.Register(publisher)
+	bus.Register(subscriber)
+
+	count := 0
+	_, err := bus.Subscribe(subscriber.ID, publisher.ID, "stats-updated", func(event *BusEvent) {
+		count++
+	})
+	require.NoError(t, err)
+
+	bus.Unregister(publisher.ID)
+	bus.Register(publisher)
+
+	_, err = bus.Publish(publisher.ID, "stats-updated", []byte(`{"watched": 1}`))
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	// The subscriptions of an unloaded plugin are removed
+	bus.Unregister(subscriber.ID)
+	delivered, err := bus.Publish(publisher.ID, "stats-updated", []byte(`{"watched": 1}`))
+	require.NoError(t, err)
+	require.Equal(t, 0, delivered)
+}
+
+func TestBus_Call(t *testing.T) {
+	bus := NewBus()
+	publisher, subscriber := newBusTestPlugins()
+	bus.Register(publisher)
+	bus.Register(subscriber)
+
+	_, err := bus.Call(subscriber.ID, publisher.ID, "getStats", []byte(`[]`))
+	require.ErrorIs(t, err, ErrBusFunctionNotFound)
+
+	require.ErrorIs(t, bus.Export(publisher.ID, "undeclared", func(args []byte) ([]byte, error) { return nil, nil }), ErrBusFunctionNotFound)
+	require.NoError(t, bus.Export(publisher.ID, "getStats", func(args []byte) ([]byte, error) {
+		return args, nil
+	}))
+
+	ret, err := bus.Call(subscriber.ID, publisher.ID, "getStats", []byte(`[21]`))
+	require.NoError(t, err)
+	require.Equal(t, `[21]`, string(ret))
+
+	// The publisher isn't granted access to the subscriber
+	_, err = bus.Call(publisher.ID, subscriber.ID, "getStats", []byte(`[]`))
+	require.ErrorIs(t, err, ErrBusAccessDenied)
+
+	bus.Unregister(publisher.ID)
+	_, err = bus.Call(subscriber.ID, publisher.ID, "getStats", []byte(`[]`))
+	require.ErrorIs(t, err, ErrBusFunctionNotFound)
+}
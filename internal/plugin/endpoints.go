@@ -0,0 +1,235 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrEndpointNotFound     = errors.New("plugin: Endpoint not found")
+	ErrEndpointMethodDenied = errors.New("plugin: Method not allowed")
+	ErrInvalidEndpoint      = errors.New("plugin: Invalid endpoint")
+)
+
+// EndpointMethodAny matches any HTTP method.
+const EndpointMethodAny = "*"
+
+var endpointMethods = []string{
+	http.MethodGet,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	EndpointMethodAny,
+}
+
+// GlobalEndpointRegistry holds the HTTP endpoints registered by the plugins.
+// The endpoints are served under /api/v1/plugins/{id}.
+var GlobalEndpointRegistry = NewEndpointRegistry()
+
+type (
+	// EndpointRegistry maps the HTTP endpoints of each plugin to their handler.
+	EndpointRegistry struct {
+		mu        sync.RWMutex
+		endpoints map[string][]*endpoint // Extension ID -> endpoints
+	}
+
+	EndpointRequest struct {
+		Method string
+		// Path is relative to the plugin prefix, e.g. "/items/1" for /api/v1/plugins/{id}/items/1
+		Path string
+		// Params are the path parameters, e.g. {"id": "1"} for "/items/:id".
+		// The rest of the path matched by a trailing "*" is stored under "*".
+		Params  map[string]string
+		Query   map[string]string
+		Headers map[string]string
+		Body    []byte
+	}
+
+	EndpointResponse struct {
+		Status  int
+		Headers map[string]string
+		Body    []byte
+	}
+
+	// EndpointHandler handles a request to a plugin endpoint.
+	EndpointHandler func(req *EndpointRequest) (*EndpointResponse, error)
+
+	endpoint struct {
+		method   string
+		path     string
+		segments []string
+		handler  EndpointHandler
+	}
+)
+
+func NewEndpointRegistry() *EndpointRegistry {
+	return &EndpointRegistry{
+		endpoints: make(map[string][]*endpoint),
+	}
+}
+
+// Register adds an endpoint to the plugin.
+// The path can contain parameters (e.g. "/items/:id") and end with a wildcard (e.g. "/files/*").
+// An endpoint with the same method and path replaces the previous one.
+func (r *EndpointRegistry) Register(extId string, method string, path string, handler EndpointHandler) error {
+	method = strings.ToUpper(method)
+	if !slices.Contains(endpointMethods, method) {
+		return fmt.Errorf("%w: unsupported method %q", ErrInvalidEndpoint, method)
+	}
+	if handler == nil {
+		return fmt.Errorf("%w: handler must be a function", ErrInvalidEndpoint)
+	}
+
+	segments := splitEndpointPath(path)
+	for i, segment := range segments {
+		if segment == "*" && i != len(segments)-1 {
+			return fmt.Errorf("%w: wildcard must be the last segment of %q", ErrInvalidEndpoint, path)
+		}
+		if segment == ":" {
+			return fmt.Errorf("%w: unnamed parameter in %q", ErrInvalidEndpoint, path)
+		}
+	}
+
+	e := &endpoint{
+		method:   method,
+		path:     "/" + strings.Join(segments, "/"),
+		segments: segments,
+		handler:  handler,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	endpoints := slices.DeleteFunc(r.endpoints[extId], func(existing *endpoint) bool {
+		return existing.method == e.method && existing.path == e.path
+	})
+	r.endpoints[extId] = append(endpoints, e)
+
+	return nil
+}
+
+// Unregister removes an endpoint of the plugin.
+func (r *EndpointRegistry) Unregister(extId string, method string, path string) {
+	method = strings.ToUpper(method)
+	path = "/" + strings.Join(splitEndpointPath(path), "/")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.endpoints[extId] = slices.DeleteFunc(r.endpoints[extId], func(e *endpoint) bool {
+		return e.method == method && e.path == path
+	})
+	if len(r.endpoints[extId]) == 0 {
+		delete(r.endpoints, extId)
+	}
+}
+
+// UnregisterAll removes all the endpoints of the plugin, e.g. when it is unloaded.
+func (r *EndpointRegistry) UnregisterAll(extId string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.endpoints, extId)
+}
+
+// Serve finds the endpoint of the plugin matching the request and calls its handler.
+// It returns ErrEndpointNotFound if no endpoint matches the path
+// and ErrEndpointMethodDenied if endpoints match the path but not the method.
+func (r *EndpointRegistry) Serve(extId string, req *EndpointRequest) (*EndpointResponse, error) {
+	e, params, err := r.match(extId, req.Method, req.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Params = params
+	return e.handler(req)
+}
+
+// match returns the endpoint matching the method and path.
+// Endpoints with more static segments take precedence over parameters and wildcards.
+func (r *EndpointRegistry) match(extId string, method string, path string) (*endpoint, map[string]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	segments := splitEndpointPath(path)
+	method = strings.ToUpper(method)
+
+	var best *endpoint
+	var bestParams map[string]string
+	bestScore := -1
+	pathMatched := false
+
+	for _, e := range r.endpoints[extId] {
+		params, score, ok := matchEndpointSegments(e.segments, segments)
+		if !ok {
+			continue
+		}
+		pathMatched = true
+		if e.method != method && e.method != EndpointMethodAny {
+			continue
+		}
+		// Prefer the endpoint registered for the method over the catch-all
+		score *= 2
+		if e.method == method {
+			score++
+		}
+		if score > bestScore {
+			best, bestParams, bestScore = e, params, score
+		}
+	}
+
+	if best == nil {
+		if pathMatched {
+			return nil, nil, ErrEndpointMethodDenied
+		}
+		return nil, nil, ErrEndpointNotFound
+	}
+
+	return best, bestParams, nil
+}
+
+// matchEndpointSegments matches the path against the pattern.
+// The score ranks the match by the number of static segments matched.
+func matchEndpointSegments(pattern []string, path []string) (params map[string]string, score int, ok bool) {
+	params = make(map[string]string)
+
+	for i, segment := range pattern {
+		if segment == "*" {
+			params["*"] = strings.Join(path[i:], "/")
+			return params, score * 2, true
+		}
+		if i >= len(path) {
+			return nil, 0, false
+		}
+		if strings.HasPrefix(segment, ":") {
+			params[segment[1:]] = path[i]
+			continue
+		}
+		if segment != path[i] {
+			return nil, 0, false
+		}
+		score++
+	}
+
+	if len(pattern) != len(path) {
+		return nil, 0, false
+	}
+
+	// Exact matches take precedence over wildcards matching the same number of static segments
+	return params, score*2 + 1, true
+}
+
+func splitEndpointPath(path string) []string {
+	ret := make([]string, 0)
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" {
+			ret = append(ret, segment)
+		}
+	}
+	return ret
+}
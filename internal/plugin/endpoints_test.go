@@ -0,0 +1,126 @@
+package plugin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndpointRegistry(t *testing.T) {
+	registry := NewEndpointRegistry()
+
+	handler := func(name string) EndpointHandler {
+		return func(req *EndpointRequest) (*EndpointResponse, error) {
+			return &EndpointResponse{Status: http.StatusOK, Body: []byte(name)}, nil
+		}
+	}
+
+	require.NoError(t, registry.Register("my-plugin", "GET", "/items", handler("list")))
+	require.NoError(t, registry.Register("my-plugin", "GET", "/items/:id", handler("get")))
+	require.NoError(t, registry.Register("my-plugin", "GET", "/items/latest", handler("latest")))
+	require.NoError(t, registry.Register("my-plugin", "post", "/items/:id/", handler("update")))
+	require.NoError(t, registry.Register("my-plugin", "*", "/files/*", handler("files")))
+	require.NoError(t, registry.Register("other-plugin", "GET", "/status", handler("status")))
+
+	require.ErrorIs(t, registry.Register("my-plugin", "TRACE", "/items", handler("trace")), ErrInvalidEndpoint)
+	require.ErrorIs(t, registry.Register("my-plugin", "GET", "/files/*/name", handler("name")), ErrInvalidEndpoint)
+
+	tests := []struct {
+		name           string
+		extId          string
+		method         string
+		path           string
+		expectedBody   string
+		expectedParams map[string]string
+		expectedErr    error
+	}{
+		{
+			name:           "Static path",
+			extId:          "my-plugin",
+			method:         "GET",
+			path:           "/items",
+			expectedBody:   "list",
+			expectedParams: map[string]string{},
+		},
+		{
+			name:           "Path parameter",
+			extId:          "my-plugin",
+			method:         "GET",
+			path:           "/items/42",
+			expectedBody:   "get",
+			expectedParams: map[string]string{"id": "42"},
+		},
+		{
+			name:           "Static segment takes precedence over parameter",
+			extId:          "my-plugin",
+			method:         "GET",
+			path:           "/items/latest",
+			expectedBody:   "latest",
+			expectedParams: map[string]string{},
+		},
+		{
+			name:           "Method",
+			extId:          "my-plugin",
+			method:         "POST",
+			path:           "/items/42",
+			expectedBody:   "update",
+			expectedParams: map[string]string{"id": "42"},
+		},
+		{
+			name:           "Wildcard",
+			extId:          "my-plugin",
+			method:         "DELETE",
+			path:           "/files/covers/1.jpg",
+			expectedBody:   "files",
+			expectedParams: map[string]string{"*": "covers/1.jpg"},
+		},
+		{
+			name:        "Method not allowed",
+			extId:       "my-plugin",
+			method:      "DELETE",
+			path:        "/items/42",
+			expectedErr: ErrEndpointMethodDenied,
+		},
+		{
+			name:        "Not found",
+			extId:       "my-plugin",
+			method:      "GET",
+			path:        "/items/42/pages",
+			expectedErr: ErrEndpointNotFound,
+		},
+		{
+			name:        "Endpoints are scoped to the plugin",
+			extId:       "my-plugin",
+			method:      "GET",
+			path:        "/status",
+			expectedErr: ErrEndpointNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &EndpointRequest{Method: tt.method, Path: tt.path}
+			res, err := registry.Serve(tt.extId, req)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedBody, string(res.Body))
+			require.Equal(t, tt.expectedParams, req.Params)
+		})
+	}
+
+	registry.Unregister("my-plugin", "GET", "/items/latest/")
+	res, err := registry.Serve("my-plugin", &EndpointRequest{Method: "GET", Path: "/items/latest"})
+	require.NoError(t, err)
+	require.Equal(t, "get", string(res.Body))
+
+	registry.UnregisterAll("my-plugin")
+	_, err = registry.Serve("my-plugin", &EndpointRequest{Method: "GET", Path: "/items"})
+	require.ErrorIs(t, err, ErrEndpointNotFound)
+
+	_, err = registry.Serve("other-plugin", &EndpointRequest{Method: "GET", Path: "/status"})
+	require.NoError(t, err)
+}
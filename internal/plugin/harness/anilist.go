@@ -0,0 +1,336 @@
+package plugin_harness
+
+import (
+	"context"
+	"errors"
+	"seanime/internal/api/anilist"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/Yamashou/gqlgenc/clientv2"
+	"github.com/samber/lo"
+)
+
+var (
+	ErrMediaNotFound = errors.New("harness: media not found")
+	ErrNotSupported  = errors.New("harness: query not supported by the fake AniList client")
+)
+
+// FakeAnilistClient is an in-memory implementation of anilist.AnilistClient.
+// It answers queries using the collections and media it is given, and records the list entry updates
+// instead of sending them to AniList.
+type FakeAnilistClient struct {
+	mu              sync.Mutex
+	animeCollection *anilist.AnimeCollection
+	mangaCollection *anilist.MangaCollection
+	anime           map[int]*anilist.BaseAnime
+	manga           map[int]*anilist.BaseManga
+	updates         []*EntryUpdate
+}
+
+// EntryUpdate is a list entry update sent by the plugin.
+type EntryUpdate struct {
+	MediaID  int                      `json:"mediaId"`
+	Status   *anilist.MediaListStatus `json:"status,omitempty"`
+	ScoreRaw *int                     `json:"scoreRaw,omitempty"`
+	Progress *int                     `json:"progress,omitempty"`
+	Repeat   *int                     `json:"repeat,omitempty"`
+	// EntryID is set when the entry is deleted
+	EntryID int  `json:"entryId,omitempty"`
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+var _ anilist.AnilistClient = (*FakeAnilistClient)(nil)
+
+func NewFakeAnilistClient() *FakeAnilistClient {
+	return &FakeAnilistClient{
+		animeCollection: &anilist.AnimeCollection{MediaListCollection: &anilist.AnimeCollection_MediaListCollection{}},
+		mangaCollection: &anilist.MangaCollection{MediaListCollection: &anilist.MangaCollection_MediaListCollection{}},
+		anime:           make(map[int]*anilist.BaseAnime),
+		manga:           make(map[int]*anilist.BaseManga),
+		updates:         make([]*EntryUpdate, 0),
+	}
+}
+
+// SetAnimeCollection sets the anime collection of the user.
+// The media of the collection can also be queried individually.
+func (c *FakeAnilistClient) SetAnimeCollection(collection *anilist.AnimeCollection) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if collection == nil || collection.MediaListCollection == nil {
+		collection = &anilist.AnimeCollection{MediaListCollection: &anilist.AnimeCollection_MediaListCollection{}}
+	}
+	c.animeCollection = collection
+	for _, list := range collection.MediaListCollection.GetLists() {
+		for _, entry := range list.GetEntries() {
+			if entry.GetMedia() != nil {
+				c.anime[entry.GetMedia().GetID()] = entry.GetMedia()
+			}
+		}
+	}
+}
+
+// SetMangaCollection sets the manga collection of the user.
+// The media of the collection can also be queried individually.
+func (c *FakeAnilistClient) SetMangaCollection(collection *anilist.MangaCollection) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if collection == nil || collection.MediaListCollection == nil {
+		collection = &anilist.MangaCollection{MediaListCollection: &anilist.MangaCollection_MediaListCollection{}}
+	}
+	c.mangaCollection = collection
+	for _, list := range collection.MediaListCollection.GetLists() {
+		for _, entry := range list.GetEntries() {
+			if entry.GetMedia() != nil {
+				c.manga[entry.GetMedia().GetID()] = entry.GetMedia()
+			}
+		}
+	}
+}
+
+// AddAnime adds anime that are not in the collection of the user.
+func (c *FakeAnilistClient) AddAnime(media ...*anilist.BaseAnime) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, m := range media {
+		c.anime[m.ID] = m
+	}
+}
+
+// AddManga adds manga that are not in the collection of the user.
+func (c *FakeAnilistClient) AddManga(media ...*anilist.BaseManga) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, m := range media {
+		c.manga[m.ID] = m
+	}
+}
+
+// Updates returns the list entry updates sent so far.
+func (c *FakeAnilistClient) Updates() []*EntryUpdate {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.updates)
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Anime
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (c *FakeAnilistClient) AnimeCollection(ctx context.Context, userName *string, interceptors ...clientv2.RequestInterceptor) (*anilist.AnimeCollection, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.animeCollection, nil
+}
+
+func (c *FakeAnilistClient) AnimeCollectionWithRelations(ctx context.Context, userName *string, interceptors ...clientv2.RequestInterceptor) (*anilist.AnimeCollectionWithRelations, error) {
+	return nil, ErrNotSupported
+}
+
+func (c *FakeAnilistClient) BaseAnimeByMalID(ctx context.Context, id *int, interceptors ...clientv2.RequestInterceptor) (*anilist.BaseAnimeByMalID, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, media := range c.anime {
+		if media.IDMal != nil && id != nil && *media.IDMal == *id {
+			return &anilist.BaseAnimeByMalID{Media: media}, nil
+		}
+	}
+	return nil, ErrMediaNotFound
+}
+
+func (c *FakeAnilistClient) BaseAnimeByID(ctx context.Context, id *int, interceptors ...clientv2.RequestInterceptor) (*anilist.BaseAnimeByID, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	media, ok := c.anime[lo.FromPtr(id)]
+	if !ok {
+		return nil, ErrMediaNotFound
+	}
+	return &anilist.BaseAnimeByID{Media: media}, nil
+}
+
+func (c *FakeAnilistClient) SearchBaseAnimeByIds(ctx context.Context, ids []*int, page *int, perPage *int, status []*anilist.MediaStatus, inCollection *bool, sort []*anilist.MediaSort, season *anilist.MediaSeason, year *int, genre *string, format *anilist.MediaFormat, interceptors ...clientv2.RequestInterceptor) (*anilist.SearchBaseAnimeByIds, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	media := make([]*anilist.BaseAnime, 0)
+	for _, id := range ids {
+		if m, ok := c.anime[lo.FromPtr(id)]; ok {
+			media = append(media, m)
+		}
+	}
+	return &anilist.SearchBaseAnimeByIds{Page: &anilist.SearchBaseAnimeByIds_Page{Media: media}}, nil
+}
+
+func (c *FakeAnilistClient) CompleteAnimeByID(ctx context.Context, id *int, interceptors ...clientv2.RequestInterceptor) (*anilist.CompleteAnimeByID, error) {
+	return nil, ErrNotSupported
+}
+
+func (c *FakeAnilistClient) AnimeDetailsByID(ctx context.Context, id *int, interceptors ...clientv2.RequestInterceptor) (*anilist.AnimeDetailsByID, error) {
+	return nil, ErrNotSupported
+}
+
+func (c *FakeAnilistClient) ListAnime(ctx context.Context, page *int, search *string, perPage *int, sort []*anilist.MediaSort, status []*anilist.MediaStatus, genres []*string, averageScoreGreater *int, season *anilist.MediaSeason, seasonYear *int, format *anilist.MediaFormat, isAdult *bool, interceptors ...clientv2.RequestInterceptor) (*anilist.ListAnime, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	media := make([]*anilist.BaseAnime, 0)
+	for _, m := range c.anime {
+		if matchesSearch(search, m.GetAllTitles()) {
+			media = append(media, m)
+		}
+	}
+	slices.SortFunc(media, func(a, b *anilist.BaseAnime) int { return a.ID - b.ID })
+	return &anilist.ListAnime{Page: &anilist.ListAnime_Page{Media: media}}, nil
+}
+
+func (c *FakeAnilistClient) ListRecentAnime(ctx context.Context, page *int, perPage *int, airingAtGreater *int, airingAtLesser *int, notYetAired *bool, interceptors ...clientv2.RequestInterceptor) (*anilist.ListRecentAnime, error) {
+	return &anilist.ListRecentAnime{Page: &anilist.ListRecentAnime_Page{AiringSchedules: []*anilist.ListRecentAnime_Page_AiringSchedules{}}}, nil
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// List entries
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (c *FakeAnilistClient) UpdateMediaListEntry(ctx context.Context, mediaID *int, status *anilist.MediaListStatus, scoreRaw *int, progress *int, startedAt *anilist.FuzzyDateInput, completedAt *anilist.FuzzyDateInput, interceptors ...clientv2.RequestInterceptor) (*anilist.UpdateMediaListEntry, error) {
+	c.recordUpdate(&EntryUpdate{MediaID: lo.FromPtr(mediaID), Status: status, ScoreRaw: scoreRaw, Progress: progress})
+	return &anilist.UpdateMediaListEntry{SaveMediaListEntry: &anilist.UpdateMediaListEntry_SaveMediaListEntry{ID: lo.FromPtr(mediaID)}}, nil
+}
+
+func (c *FakeAnilistClient) UpdateMediaListEntryProgress(ctx context.Context, mediaID *int, progress *int, status *anilist.MediaListStatus, interceptors ...clientv2.RequestInterceptor) (*anilist.UpdateMediaListEntryProgress, error) {
+	c.recordUpdate(&EntryUpdate{MediaID: lo.FromPtr(mediaID), Status: status, Progress: progress})
+	return &anilist.UpdateMediaListEntryProgress{SaveMediaListEntry: &anilist.UpdateMediaListEntryProgress_SaveMediaListEntry{ID: lo.FromPtr(mediaID)}}, nil
+}
+
+func (c *FakeAnilistClient) UpdateMediaListEntryRepeat(ctx context.Context, mediaID *int, repeat *int, interceptors ...clientv2.RequestInterceptor) (*anilist.UpdateMediaListEntryRepeat, error) {
+	c.recordUpdate(&EntryUpdate{MediaID: lo.FromPtr(mediaID), Repeat: repeat})
+	return &anilist.UpdateMediaListEntryRepeat{SaveMediaListEntry: &anilist.UpdateMediaListEntryRepeat_SaveMediaListEntry{ID: lo.FromPtr(mediaID)}}, nil
+}
+
+func (c *FakeAnilistClient) DeleteEntry(ctx context.Context, mediaListEntryID *int, interceptors ...clientv2.RequestInterceptor) (*anilist.DeleteEntry, error) {
+	c.recordUpdate(&EntryUpdate{EntryID: lo.FromPtr(mediaListEntryID), Deleted: true})
+	return &anilist.DeleteEntry{DeleteMediaListEntry: &anilist.DeleteEntry_DeleteMediaListEntry{Deleted: lo.ToPtr(true)}}, nil
+}
+
+// recordUpdate records the update and applies it to the collections so that refreshed collections reflect it.
+func (c *FakeAnilistClient) recordUpdate(update *EntryUpdate) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.updates = append(c.updates, update)
+
+	for _, list := range c.animeCollection.GetMediaListCollection().GetLists() {
+		for _, entry := range list.GetEntries() {
+			if entry.GetMedia().GetID() != update.MediaID {
+				continue
+			}
+			if update.Progress != nil {
+				entry.Progress = update.Progress
+			}
+			if update.Status != nil {
+				entry.Status = update.Status
+			}
+			if update.Repeat != nil {
+				entry.Repeat = update.Repeat
+			}
+		}
+	}
+
+	for _, list := range c.mangaCollection.GetMediaListCollection().GetLists() {
+		for _, entry := range list.GetEntries() {
+			if entry.GetMedia().GetID() != update.MediaID {
+				continue
+			}
+			if update.Progress != nil {
+				entry.Progress = update.Progress
+			}
+			if update.Status != nil {
+				entry.Status = update.Status
+			}
+			if update.Repeat != nil {
+				entry.Repeat = update.Repeat
+			}
+		}
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Manga
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (c *FakeAnilistClient) MangaCollection(ctx context.Context, userName *string, interceptors ...clientv2.RequestInterceptor) (*anilist.MangaCollection, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.mangaCollection, nil
+}
+
+func (c *FakeAnilistClient) SearchBaseManga(ctx context.Context, page *int, perPage *int, sort []*anilist.MediaSort, search *string, status []*anilist.MediaStatus, interceptors ...clientv2.RequestInterceptor) (*anilist.SearchBaseManga, error) {
+	return &anilist.SearchBaseManga{Page: &anilist.SearchBaseManga_Page{Media: c.searchManga(search)}}, nil
+}
+
+func (c *FakeAnilistClient) BaseMangaByID(ctx context.Context, id *int, interceptors ...clientv2.RequestInterceptor) (*anilist.BaseMangaByID, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	media, ok := c.manga[lo.FromPtr(id)]
+	if !ok {
+		return nil, ErrMediaNotFound
+	}
+	return &anilist.BaseMangaByID{Media: media}, nil
+}
+
+func (c *FakeAnilistClient) MangaDetailsByID(ctx context.Context, id *int, interceptors ...clientv2.RequestInterceptor) (*anilist.MangaDetailsByID, error) {
+	return nil, ErrNotSupported
+}
+
+func (c *FakeAnilistClient) ListManga(ctx context.Context, page *int, search *string, perPage *int, sort []*anilist.MediaSort, status []*anilist.MediaStatus, genres []*string, averageScoreGreater *int, startDateGreater *string, startDateLesser *string, format *anilist.MediaFormat, countryOfOrigin *string, isAdult *bool, interceptors ...clientv2.RequestInterceptor) (*anilist.ListManga, error) {
+	return &anilist.ListManga{Page: &anilist.ListManga_Page{Media: c.searchManga(search)}}, nil
+}
+
+func (c *FakeAnilistClient) searchManga(search *string) []*anilist.BaseManga {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	media := make([]*anilist.BaseManga, 0)
+	for _, m := range c.manga {
+		if matchesSearch(search, m.GetAllTitles()) {
+			media = append(media, m)
+		}
+	}
+	slices.SortFunc(media, func(a, b *anilist.BaseManga) int { return a.ID - b.ID })
+	return media
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Viewer
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (c *FakeAnilistClient) ViewerStats(ctx context.Context, interceptors ...clientv2.RequestInterceptor) (*anilist.ViewerStats, error) {
+	return nil, ErrNotSupported
+}
+
+func (c *FakeAnilistClient) StudioDetails(ctx context.Context, id *int, interceptors ...clientv2.RequestInterceptor) (*anilist.StudioDetails, error) {
+	return nil, ErrNotSupported
+}
+
+func (c *FakeAnilistClient) GetViewer(ctx context.Context, interceptors ...clientv2.RequestInterceptor) (*anilist.GetViewer, error) {
+	return &anilist.GetViewer{Viewer: &anilist.GetViewer_Viewer{Name: Username}}, nil
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func matchesSearch(search *string, titles []*string) bool {
+	if search == nil || *search == "" {
+		return true
+	}
+	for _, title := range titles {
+		if title != nil && strings.Contains(strings.ToLower(*title), strings.ToLower(*search)) {
+			return true
+		}
+	}
+	return false
+}
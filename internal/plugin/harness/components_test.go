@@ -0,0 +1,146 @@
+package plugin_harness
+
+import (
+	"seanime/internal/api/anilist"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+)
+
+const testComponentsPayload = `
+function init() {
+	$ui.register((ctx) => {
+		const tray = ctx.newTray({ withContent: true })
+		const page = ctx.state(1)
+		const tab = ctx.state("table")
+
+		try {
+			tray.badge({ text: "Invalid", intent: "purple" })
+		} catch (e) {
+			console.log("badge: " + e.message)
+		}
+		try {
+			tray.dataTable({ columns: [{ key: "title" }], rows: [], sortBy: "title" })
+		} catch (e) {
+			console.log("dataTable: " + e.message)
+		}
+
+		const rows = []
+		for (let i = 1; i <= 25; i++) {
+			rows.push({ id: i, title: "Title " + String(i).padStart(2, "0"), type: i % 2 ? "TV" : "MOVIE" })
+		}
+
+		tray.render(() => tray.stack([
+			tray.badge("Airing", { intent: "success" }),
+			tray.progress({ value: 30, max: 28 }),
+			tray.tabs({
+				value: tab.get(),
+				onChange: ctx.eventHandler("tab", (e) => tab.set(e.value)),
+				tabs: [
+					{
+						value: "table", label: "Table", items: [
+							tray.dataTable({
+								columns: [{ key: "title", label: "Title", sortable: true }, { key: "type", label: "Type" }],
+								rows: rows,
+								rowKey: "id",
+								pageSize: 10,
+								page: page.get(),
+								sortBy: "title",
+								sortDirection: "desc",
+								onPageChange: ctx.eventHandler("page", (e) => page.set(e.page)),
+							}),
+						],
+					},
+					{ value: "media", label: "Media", items: [tray.mediaCard({ mediaId: 154587 }), tray.mediaGrid([154587, 21, 999])] },
+				],
+			}),
+			tray.modal({ title: "Closed", items: [tray.text("Hidden")] }),
+		]))
+	})
+}
+`
+
+func TestComponents(t *testing.T) {
+	ext := testExtension()
+	ext.Payload = testComponentsPayload
+
+	// The media are set before the plugin is loaded since it can render before the tray is opened
+	collection := testAnimeCollection()
+	collection.MediaListCollection.Lists = append(collection.MediaListCollection.Lists, &anilist.AnimeCollection_MediaListCollection_Lists{
+		Status: lo.ToPtr(anilist.MediaListStatusPlanning),
+		Entries: []*anilist.AnimeCollection_MediaListCollection_Lists_Entries{
+			{
+				ID:     2,
+				Status: lo.ToPtr(anilist.MediaListStatusPlanning),
+				Media:  &anilist.BaseAnime{ID: 21, Title: &anilist.BaseAnime_Title{Romaji: lo.ToPtr("One Piece")}},
+			},
+		},
+	})
+
+	h, err := New(ext, Options{AnimeCollection: collection})
+	require.NoError(t, err)
+	defer h.Close()
+
+	h.OpenTray()
+	require.True(t, h.WaitForTrayRender(1, 2*time.Second))
+	render := h.Recorder().TrayRenders()[0]
+
+	// Invalid props are rejected
+	require.True(t, containsString(h.Recorder().Logs(), "badge: expected one of"))
+	require.True(t, containsString(h.Recorder().Logs(), `dataTable: data-table: "title" is not a sortable column`))
+
+	// Values are normalized
+	progress := FindComponentsByType(render, "progress")[0]["props"].(map[string]interface{})
+	require.EqualValues(t, 28, progress["value"])
+
+	// The closed modal doesn't send its content
+	require.False(t, ContainsText(render, "Hidden"))
+
+	// Only the first page of the sorted rows is sent
+	table := FindComponentsByType(render, "data-table")[0]
+	props := table["props"].(map[string]interface{})
+	rows := props["rows"].([]interface{})
+	require.Len(t, rows, 10)
+	require.Equal(t, "Title 25", rows[0].(map[string]interface{})["title"])
+	require.EqualValues(t, 25, props["totalRows"])
+	require.EqualValues(t, 3, props["totalPages"])
+
+	// Media are not fetched during the render
+	card := FindComponentsByType(render, "media-card")[0]["props"].(map[string]interface{})
+	require.Nil(t, card["media"])
+	require.Equal(t, true, card["loading"])
+
+	// The tray is rendered again once the media are fetched, unknown media are omitted from grids
+	require.True(t, h.WaitUntil(2*time.Second, func() bool {
+		card := FindComponentsByType(h.Recorder().LastTrayRender(), "media-card")[0]["props"].(map[string]interface{})
+		return card["loading"] == false
+	}))
+	render = h.Recorder().LastTrayRender()
+	card = FindComponentsByType(render, "media-card")[0]["props"].(map[string]interface{})
+	require.Equal(t, "Sousou no Frieren", card["media"].(map[string]interface{})["title"])
+	grid := FindComponentsByType(render, "media-grid")[0]["props"].(map[string]interface{})
+	require.Equal(t, false, grid["loading"])
+	require.Len(t, grid["media"], 2)
+	require.Equal(t, "One Piece", grid["media"].([]interface{})[1].(map[string]interface{})["title"])
+
+	// Changing the page re-renders the table, the components inside the tabs keep their IDs
+	// The handler is taken from the last render since event handlers are registered again on each render
+	time.Sleep(InteractionDelay)
+	props = FindComponentsByType(h.Recorder().LastTrayRender(), "data-table")[0]["props"].(map[string]interface{})
+	h.TriggerHandler(props["onPageChange"].(string), map[string]interface{}{"page": 3})
+	require.True(t, h.WaitUntil(2*time.Second, func() bool {
+		return ContainsText(h.Recorder().LastTrayRender(), "Title 05")
+	}))
+	newTable := FindComponentsByType(h.Recorder().LastTrayRender(), "data-table")[0]
+	newProps := newTable["props"].(map[string]interface{})
+	require.Len(t, newProps["rows"], 5)
+	require.EqualValues(t, 3, newProps["page"])
+	require.Equal(t, table["id"], newTable["id"])
+	require.Equal(t, card, FindComponentsByType(h.Recorder().LastTrayRender(), "media-card")[0]["props"])
+
+	// Row data is not mistaken for components
+	row := newProps["rows"].([]interface{})[0].(map[string]interface{})
+	require.EqualValues(t, 5, row["id"])
+}
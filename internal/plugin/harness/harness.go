@@ -0,0 +1,399 @@
+package plugin_harness
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"seanime/internal/api/anilist"
+	"seanime/internal/database/db"
+	"seanime/internal/events"
+	"seanime/internal/extension"
+	"seanime/internal/extension_repo"
+	"seanime/internal/goja/goja_runtime"
+	"seanime/internal/hook"
+	"seanime/internal/library/playbackmanager"
+	"seanime/internal/notifier"
+	"seanime/internal/platforms/anilist_platform"
+	"seanime/internal/platforms/platform"
+	"seanime/internal/plugin"
+	plugin_ui "seanime/internal/plugin/ui"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+)
+
+// Harness loads a plugin without running the app.
+//
+// The plugin is given an app context backed by fakes: an in-memory AniList client, an in-memory database
+// (which also backs $storage) and a playback manager without media player.
+// Client events such as clicks and form submissions are simulated, and the events sent by the plugin
+// (tray renders, toasts, console output) and its system notifications are recorded.
+//
+// The harness replaces the global hook manager and app context while it is loaded,
+// so only one harness should be loaded at a time and tests using it must not run in parallel.
+type Harness struct {
+	ext             *extension.Extension
+	logger          *zerolog.Logger
+	plugin          *extension_repo.GojaPlugin
+	recorder        *Recorder
+	anilistClient   *FakeAnilistClient
+	anilistPlatform platform.Platform
+	database        *db.Database
+	playbackManager *playbackmanager.PlaybackManager
+
+	prevHookManager hook.Manager
+	prevAppContext  plugin.AppContext
+	closed          bool
+}
+
+type Options struct {
+	// Logger is optional, the plugin logs are discarded if not set
+	Logger *zerolog.Logger
+	// AnimeCollection is the AniList anime collection of the user, it is empty if not set
+	AnimeCollection *anilist.AnimeCollection
+	// MangaCollection is the AniList manga collection of the user, it is empty if not set
+	MangaCollection *anilist.MangaCollection
+	// PayloadPath overrides the payload of the manifest with the content of a local file
+	PayloadPath string
+}
+
+// Username is the name of the fake AniList user.
+const Username = "harness"
+
+// ClientID is the ID of the fake client sending events to the plugin.
+const ClientID = "harness"
+
+var (
+	ErrComponentNotFound = errors.New("harness: component not found")
+	ErrNotAPlugin        = errors.New("harness: extension is not a plugin")
+)
+
+// Load loads the plugin from a manifest file or a directory containing a "manifest.json" file.
+// The payload is read from the manifest, or from the file pointed to by its payload URI, relative to the manifest.
+func Load(path string, opts Options) (*Harness, error) {
+	manifestPath := path
+	if info, err := os.Stat(path); err != nil {
+		return nil, err
+	} else if info.IsDir() {
+		manifestPath = filepath.Join(path, "manifest.json")
+	}
+
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("harness: failed to read manifest: %w", err)
+	}
+
+	var ext *extension.Extension
+	if err := json.Unmarshal(data, &ext); err != nil {
+		return nil, fmt.Errorf("harness: invalid manifest: %w", err)
+	}
+
+	payloadPath := opts.PayloadPath
+	if payloadPath == "" && ext.PayloadURI != "" {
+		if strings.HasPrefix(ext.PayloadURI, "http://") || strings.HasPrefix(ext.PayloadURI, "https://") {
+			if ext.Payload == "" {
+				return nil, fmt.Errorf("harness: remote payload URIs are not supported, use a local payload: %s", ext.PayloadURI)
+			}
+		} else {
+			payloadPath = ext.PayloadURI
+			if !filepath.IsAbs(payloadPath) {
+				payloadPath = filepath.Join(filepath.Dir(manifestPath), payloadPath)
+			}
+		}
+	}
+	if payloadPath != "" {
+		payload, err := os.ReadFile(payloadPath)
+		if err != nil {
+			return nil, fmt.Errorf("harness: failed to read payload: %w", err)
+		}
+		ext.Payload = string(payload)
+	}
+
+	if err := extension_repo.ManifestSanityCheck(ext); err != nil {
+		return nil, fmt.Errorf("harness: invalid manifest: %w", err)
+	}
+
+	return New(ext, opts)
+}
+
+// New loads the plugin from its extension object.
+// All the permissions declared in the manifest are granted.
+func New(ext *extension.Extension, opts Options) (h *Harness, err error) {
+	if ext.Type != "" && ext.Type != extension.TypePlugin {
+		return nil, ErrNotAPlugin
+	}
+	if ext.Plugin == nil {
+		ext.Plugin = &extension.PluginManifest{}
+	}
+	if ext.Language == "" {
+		ext.Language = extension.LanguageJavascript
+	}
+	ext.Lang = extension.GetExtensionLang(ext.Lang)
+
+	logger := opts.Logger
+	if logger == nil {
+		nopLogger := zerolog.Nop()
+		logger = &nopLogger
+	}
+
+	h = &Harness{
+		ext:             ext,
+		logger:          logger,
+		recorder:        NewRecorder(logger),
+		anilistClient:   NewFakeAnilistClient(),
+		prevHookManager: hook.GlobalHookManager,
+		prevAppContext:  plugin.GlobalAppContext,
+	}
+
+	// Isolate the plugin from the hooks and modules of previous plugins
+	hook.SetGlobalHookManager(hook.NewHookManager(hook.NewHookManagerOptions{Logger: logger}))
+	plugin.GlobalAppContext = plugin.NewAppContext()
+	plugin.GlobalAppContext.SetLogger(logger)
+
+	defer func() {
+		if err != nil {
+			h.restoreGlobals()
+		}
+	}()
+
+	h.database, err = db.NewInMemoryDatabase(logger)
+	if err != nil {
+		return nil, fmt.Errorf("harness: failed to create database: %w", err)
+	}
+
+	h.anilistClient.SetAnimeCollection(opts.AnimeCollection)
+	h.anilistClient.SetMangaCollection(opts.MangaCollection)
+	h.anilistPlatform = anilist_platform.NewAnilistPlatform(h.anilistClient, logger)
+	// The platform only fetches the collections of a logged-in user
+	h.anilistPlatform.(*anilist_platform.AnilistPlatform).SetUsername(Username)
+
+	refreshAnimeCollection := func() {
+		_, _ = h.anilistPlatform.RefreshAnimeCollection()
+	}
+	refreshMangaCollection := func() {
+		_, _ = h.anilistPlatform.RefreshMangaCollection()
+	}
+
+	h.playbackManager = playbackmanager.New(&playbackmanager.NewPlaybackManagerOptions{
+		WSEventManager:             h.recorder,
+		Logger:                     logger,
+		Platform:                   h.anilistPlatform,
+		Database:                   h.database,
+		RefreshAnimeCollectionFunc: refreshAnimeCollection,
+	})
+
+	plugin.GlobalAppContext.SetModulesPartial(plugin.AppContextModules{
+		Database:                        h.database,
+		AnimeLibraryPaths:               &[]string{},
+		AnilistPlatform:                 h.anilistPlatform,
+		PlaybackManager:                 h.playbackManager,
+		WSEventManager:                  h.recorder,
+		OnRefreshAnilistAnimeCollection: refreshAnimeCollection,
+		OnRefreshAnilistMangaCollection: refreshMangaCollection,
+	})
+
+	notifier.GlobalNotifier.AddListener(h.listenerID(), func(id notifier.Notification, message string) {
+		if string(id) == ext.Name {
+			h.recorder.recordNotification(message)
+		}
+	})
+
+	h.plugin, _, err = extension_repo.NewGojaPlugin(ext, ext.Language, logger, goja_runtime.NewManager(logger), h.recorder)
+	if err != nil {
+		notifier.GlobalNotifier.RemoveListener(h.listenerID())
+		return nil, fmt.Errorf("harness: failed to load plugin: %w", err)
+	}
+
+	return h, nil
+}
+
+// Close unloads the plugin and restores the global hook manager and app context.
+func (h *Harness) Close() {
+	if h.closed {
+		return
+	}
+	h.closed = true
+
+	h.plugin.ClearInterrupt()
+	notifier.GlobalNotifier.RemoveListener(h.listenerID())
+	h.restoreGlobals()
+}
+
+func (h *Harness) restoreGlobals() {
+	hook.SetGlobalHookManager(h.prevHookManager)
+	plugin.GlobalAppContext = h.prevAppContext
+}
+
+func (h *Harness) listenerID() string {
+	return "harness-" + h.ext.ID
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (h *Harness) Extension() *extension.Extension {
+	return h.ext
+}
+
+func (h *Harness) Recorder() *Recorder {
+	return h.recorder
+}
+
+func (h *Harness) Anilist() *FakeAnilistClient {
+	return h.anilistClient
+}
+
+func (h *Harness) AnilistPlatform() platform.Platform {
+	return h.anilistPlatform
+}
+
+func (h *Harness) Database() *db.Database {
+	return h.database
+}
+
+func (h *Harness) PlaybackManager() *playbackmanager.PlaybackManager {
+	return h.playbackManager
+}
+
+// WaitUntil polls the condition until it is true or the timeout is reached.
+// UI updates are asynchronous, so expectations should be awaited.
+// Note that state changes made less than plugin_ui.UIUpdateRateLimit after a render do not trigger a new render.
+func (h *Harness) WaitUntil(timeout time.Duration, cond func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		if cond() {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// WaitForTrayRender waits for the tray to be rendered at least n times.
+func (h *Harness) WaitForTrayRender(n int, timeout time.Duration) bool {
+	return h.WaitUntil(timeout, func() bool {
+		return len(h.recorder.TrayRenders()) >= n
+	})
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Client events
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// SendClientEvent sends a plugin event to the plugin, as if it was sent by the client.
+func (h *Harness) SendClientEvent(t plugin_ui.ClientEventType, payload interface{}) {
+	// The client payload is received as generic JSON
+	var decoded interface{} = map[string]interface{}{}
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err == nil {
+			_ = json.Unmarshal(data, &decoded)
+		}
+	}
+
+	h.recorder.SendClientEvent(&events.WebsocketClientEvent{
+		ClientID: ClientID,
+		Type:     events.PluginEvent,
+		Payload: map[string]interface{}{
+			"extensionId": h.ext.ID,
+			"type":        string(t),
+			"payload":     decoded,
+		},
+	})
+}
+
+// OpenTray opens the tray and requests a render.
+func (h *Harness) OpenTray() {
+	h.SendClientEvent(plugin_ui.ClientTrayOpenedEvent, plugin_ui.ClientTrayOpenedEventPayload{})
+	h.RenderTray()
+}
+
+func (h *Harness) CloseTray() {
+	h.SendClientEvent(plugin_ui.ClientTrayClosedEvent, plugin_ui.ClientTrayClosedEventPayload{})
+}
+
+func (h *Harness) ClickTray() {
+	h.SendClientEvent(plugin_ui.ClientTrayClickedEvent, plugin_ui.ClientTrayClickedEventPayload{})
+}
+
+func (h *Harness) RenderTray() {
+	h.SendClientEvent(plugin_ui.ClientRenderTrayEvent, plugin_ui.ClientRenderTrayEventPayload{})
+}
+
+// TriggerHandler triggers an event handler registered with ctx.eventHandler.
+func (h *Harness) TriggerHandler(handlerName string, event map[string]interface{}) {
+	h.SendClientEvent(plugin_ui.ClientEventHandlerTriggeredEvent, plugin_ui.ClientEventHandlerTriggeredEventPayload{
+		HandlerName: handlerName,
+		Event:       event,
+	})
+}
+
+// ClickButton clicks the button of the last tray render that has the given label.
+func (h *Harness) ClickButton(label string) error {
+	buttons := FindComponents(h.recorder.LastTrayRender(), func(component map[string]interface{}) bool {
+		return component["type"] == "button" && componentProp(component, "label") == label
+	})
+	if len(buttons) == 0 {
+		return fmt.Errorf("%w: button %q", ErrComponentNotFound, label)
+	}
+
+	handlerName := componentProp(buttons[0], "onClick")
+	if handlerName == "" {
+		return fmt.Errorf("harness: button %q has no click handler", label)
+	}
+
+	h.TriggerHandler(handlerName, map[string]interface{}{})
+	return nil
+}
+
+func (h *Harness) SubmitForm(formName string, data map[string]interface{}) {
+	h.SendClientEvent(plugin_ui.ClientFormSubmittedEvent, plugin_ui.ClientFormSubmittedEventPayload{
+		FormName: formName,
+		Data:     data,
+	})
+}
+
+// ClickAction clicks an action registered by the plugin, e.g. an anime page button.
+// The event usually contains the media, e.g. {"media": {...}}.
+func (h *Harness) ClickAction(actionId string, event map[string]interface{}) {
+	h.SendClientEvent(plugin_ui.ClientActionClickedEvent, plugin_ui.ClientActionClickedEventPayload{
+		ActionID: actionId,
+		Event:    event,
+	})
+}
+
+func (h *Harness) ChangeScreen(pathname string, query string) {
+	h.SendClientEvent(plugin_ui.ClientScreenChangedEvent, plugin_ui.ClientScreenChangedEventPayload{
+		Pathname: pathname,
+		Query:    query,
+	})
+}
+
+// SetFieldValue sends the value of a field that has a ref, as if the user typed it.
+func (h *Harness) SetFieldValue(fieldRef string, value interface{}) {
+	h.SendClientEvent(plugin_ui.ClientFieldRefSendValueEvent, plugin_ui.ClientFieldRefSendValueEventPayload{
+		FieldRef: fieldRef,
+		Value:    value,
+	})
+}
+
+func (h *Harness) SetCommandPaletteInput(value string) {
+	h.SendClientEvent(plugin_ui.ClientCommandPaletteInputEvent, plugin_ui.ClientCommandPaletteInputEventPayload{
+		Value: value,
+	})
+}
+
+func (h *Harness) SelectCommandPaletteItem(itemId string) {
+	h.SendClientEvent(plugin_ui.ClientCommandPaletteItemSelectedEvent, plugin_ui.ClientCommandPaletteItemSelectedEventPayload{
+		ItemID: itemId,
+	})
+}
+
+// CallEndpoint sends a request to an HTTP endpoint registered by the plugin.
+func (h *Harness) CallEndpoint(req *plugin.EndpointRequest) (*plugin.EndpointResponse, error) {
+	return plugin.GlobalEndpointRegistry.Serve(h.ext.ID, req)
+}
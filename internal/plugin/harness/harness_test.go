@@ -0,0 +1,178 @@
+package plugin_harness
+
+import (
+	"os"
+	"path/filepath"
+	"seanime/internal/api/anilist"
+	"seanime/internal/extension"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+)
+
+const testPayload = `
+function init() {
+	$ui.register((ctx) => {
+		const tray = ctx.newTray({ withContent: true })
+		const count = ctx.state($storage.get("count") ?? 0)
+
+		const collection = $anilist.getAnimeCollection(false)
+		const entry = collection.MediaListCollection.lists[0].entries[0]
+
+		ctx.registerEventHandler("reset", () => {
+			count.set(0)
+		})
+
+		tray.render(() => tray.stack([
+			tray.text("Count: " + count.get()),
+			tray.text("Watching: " + entry.media.title.romaji),
+			tray.button("Increment", {
+				onClick: ctx.eventHandler("increment", () => {
+					count.set(c => c + 1)
+					$storage.set("count", count.get())
+					$anilist.updateEntryProgress(entry.media.id, entry.progress + 1, 28)
+					ctx.toast.success("Incremented")
+					ctx.notification.send("Count changed")
+					console.log("incremented")
+				}),
+			}),
+		]))
+	})
+}
+`
+
+func testExtension() *extension.Extension {
+	return &extension.Extension{
+		ID:       "harness-test",
+		Name:     "Harness Test",
+		Version:  "1.0.0",
+		Type:     extension.TypePlugin,
+		Language: extension.LanguageJavascript,
+		Author:   "Seanime",
+		Payload:  testPayload,
+		Plugin: &extension.PluginManifest{
+			Version: extension.PluginManifestVersion,
+			Permissions: extension.PluginPermissions{
+				Scopes: []extension.PluginPermissionScope{
+					extension.PluginPermissionStorage,
+					extension.PluginPermissionAnilist,
+					extension.PluginPermissionNotification,
+				},
+			},
+		},
+	}
+}
+
+func testAnimeCollection() *anilist.AnimeCollection {
+	return &anilist.AnimeCollection{
+		MediaListCollection: &anilist.AnimeCollection_MediaListCollection{
+			Lists: []*anilist.AnimeCollection_MediaListCollection_Lists{
+				{
+					Status: lo.ToPtr(anilist.MediaListStatusCurrent),
+					Entries: []*anilist.AnimeCollection_MediaListCollection_Lists_Entries{
+						{
+							ID:       1,
+							Progress: lo.ToPtr(3),
+							Status:   lo.ToPtr(anilist.MediaListStatusCurrent),
+							Media: &anilist.BaseAnime{
+								ID:       154587,
+								Episodes: lo.ToPtr(28),
+								Title:    &anilist.BaseAnime_Title{Romaji: lo.ToPtr("Sousou no Frieren")},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestHarness(t *testing.T) {
+	h, err := New(testExtension(), Options{AnimeCollection: testAnimeCollection()})
+	require.NoError(t, err)
+	defer h.Close()
+
+	require.True(t, h.WaitForTrayRender(1, 2*time.Second))
+	require.True(t, ContainsText(h.Recorder().LastTrayRender(), "Count: 0"))
+	require.True(t, ContainsText(h.Recorder().LastTrayRender(), "Watching: Sousou no Frieren"))
+
+	time.Sleep(InteractionDelay)
+	require.NoError(t, h.ClickButton("Increment"))
+	require.True(t, h.WaitUntil(2*time.Second, func() bool {
+		return ContainsText(h.Recorder().LastTrayRender(), "Count: 1")
+	}))
+
+	// Toasts, notifications and logs are recorded
+	require.True(t, h.WaitUntil(2*time.Second, func() bool {
+		return len(h.Recorder().Toasts()) == 1 && len(h.Recorder().Notifications()) == 1
+	}))
+	require.Equal(t, "Incremented", h.Recorder().Toasts()[0].Message)
+	require.Equal(t, "Count changed", h.Recorder().Notifications()[0])
+	require.True(t, containsString(h.Recorder().Logs(), "incremented"))
+
+	// The update is sent to the fake AniList client
+	updates := h.Anilist().Updates()
+	require.Len(t, updates, 1)
+	require.Equal(t, 154587, updates[0].MediaID)
+	require.Equal(t, 4, *updates[0].Progress)
+
+	// Registered event handlers can be triggered directly
+	time.Sleep(InteractionDelay)
+	h.TriggerHandler("reset", nil)
+	require.True(t, h.WaitUntil(2*time.Second, func() bool {
+		return ContainsText(h.Recorder().LastTrayRender(), "Count: 0")
+	}))
+
+	require.ErrorIs(t, h.ClickButton("Decrement"), ErrComponentNotFound)
+}
+
+func TestScenario(t *testing.T) {
+	dir := t.TempDir()
+
+	ext := testExtension()
+	ext.Payload = ""
+	ext.PayloadURI = "plugin.js"
+	manifest, err := json.Marshal(ext)
+	require.NoError(t, err)
+	collection, err := json.Marshal(testAnimeCollection())
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "manifest.json"), manifest, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "plugin.js"), []byte(testPayload), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "collection.json"), collection, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ScenarioFileName), []byte(`{
+		"fixtures": { "animeCollection": "collection.json" },
+		"steps": [
+			{ "action": "openTray" },
+			{ "action": "expectText", "text": "Watching: Sousou no Frieren" },
+			{ "action": "clickButton", "label": "Increment" },
+			{ "action": "expectText", "text": "Count: 1" },
+			{ "action": "expectToast", "toastType": "success", "text": "Incremented" },
+			{ "action": "expectNotification", "text": "Count changed" }
+		]
+	}`), 0644))
+
+	scenario, err := LoadScenario(dir)
+	require.NoError(t, err)
+
+	report, err := scenario.Run(nil)
+	require.NoError(t, err)
+	require.True(t, report.Passed, report.String())
+	require.Len(t, report.Steps, 6)
+
+	// Failed expectations are reported
+	scenario.Timeout = 200
+	scenario.Steps = []*ScenarioStep{
+		{Action: "openTray"},
+		{Action: "expectText", Text: "Count: 100"},
+		{Action: "clickButton", Label: "Increment"},
+	}
+	report, err = scenario.Run(nil)
+	require.NoError(t, err)
+	require.False(t, report.Passed)
+	require.Len(t, report.Steps, 2)
+	require.Contains(t, report.Steps[1].Error, "Count: 100")
+}
@@ -0,0 +1,281 @@
+package plugin_harness
+
+import (
+	"seanime/internal/events"
+	plugin_ui "seanime/internal/plugin/ui"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+	"github.com/samber/lo"
+)
+
+type (
+	// Recorder is a websocket event manager that records the events sent by the plugin
+	// and lets the harness send client events to it.
+	Recorder struct {
+		logger      *zerolog.Logger
+		mu          sync.RWMutex
+		events      []*RecordedEvent
+		subscribers map[string]*events.ClientEventSubscriber
+		// notifications are recorded from the notifier
+		notifications []string
+	}
+
+	// RecordedEvent is an event sent by the server.
+	// The payload is decoded as generic JSON, the same way the client receives it.
+	RecordedEvent struct {
+		Type    string      `json:"type"`
+		Payload interface{} `json:"payload"`
+	}
+
+	// RecordedPluginEvent is a plugin event sent by the server, e.g. a tray render.
+	RecordedPluginEvent struct {
+		ExtensionID string                    `json:"extensionId"`
+		Type        plugin_ui.ServerEventType `json:"type"`
+		Payload     interface{}               `json:"payload"`
+	}
+
+	Toast struct {
+		// Type is the websocket event type, e.g. "success-toast"
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	}
+)
+
+var _ events.WSEventManagerInterface = (*Recorder)(nil)
+
+var toastTypes = []string{events.SuccessToast, events.ErrorToast, events.InfoToast, events.WarningToast}
+
+func NewRecorder(logger *zerolog.Logger) *Recorder {
+	return &Recorder{
+		logger:        logger,
+		events:        make([]*RecordedEvent, 0),
+		subscribers:   make(map[string]*events.ClientEventSubscriber),
+		notifications: make([]string, 0),
+	}
+}
+
+// SendEvent records the event sent to the client.
+func (r *Recorder) SendEvent(t string, payload interface{}) {
+	// Copy the payload as it would be received by the client, later changes to the payload must not affect the record
+	var decoded interface{}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		r.logger.Warn().Err(err).Str("type", t).Msg("harness: Failed to marshal event payload")
+	} else {
+		_ = json.Unmarshal(data, &decoded)
+	}
+
+	r.mu.Lock()
+	r.events = append(r.events, &RecordedEvent{
+		Type:    t,
+		Payload: decoded,
+	})
+	r.mu.Unlock()
+}
+
+func (r *Recorder) SendEventTo(clientId string, t string, payload interface{}) {
+	r.SendEvent(t, payload)
+}
+
+func (r *Recorder) SubscribeToClientEvents(id string) *events.ClientEventSubscriber {
+	subscriber := &events.ClientEventSubscriber{
+		Channel: make(chan *events.WebsocketClientEvent),
+	}
+	r.mu.Lock()
+	r.subscribers[id] = subscriber
+	r.mu.Unlock()
+	return subscriber
+}
+
+func (r *Recorder) UnsubscribeFromClientEvents(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.subscribers, id)
+}
+
+// SendClientEvent sends an event to the subscribers, as if it was sent by the client.
+func (r *Recorder) SendClientEvent(event *events.WebsocketClientEvent) {
+	r.mu.RLock()
+	subscribers := lo.Values(r.subscribers)
+	r.mu.RUnlock()
+
+	for _, subscriber := range subscribers {
+		subscriber.Channel <- event
+	}
+}
+
+func (r *Recorder) recordNotification(message string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notifications = append(r.notifications, message)
+}
+
+// Reset clears all recorded events and notifications.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = make([]*RecordedEvent, 0)
+	r.notifications = make([]string, 0)
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// Events returns all the events sent to the client.
+func (r *Recorder) Events() []*RecordedEvent {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.events)
+}
+
+// PluginEvents returns the plugin events of the given type, e.g. plugin_ui.ServerTrayUpdatedEvent.
+func (r *Recorder) PluginEvents(t plugin_ui.ServerEventType) []*RecordedPluginEvent {
+	ret := make([]*RecordedPluginEvent, 0)
+	for _, event := range r.Events() {
+		if event.Type != string(events.PluginEvent) {
+			continue
+		}
+		var pluginEvent *RecordedPluginEvent
+		data, _ := json.Marshal(event.Payload)
+		if err := json.Unmarshal(data, &pluginEvent); err != nil || pluginEvent == nil {
+			continue
+		}
+		if pluginEvent.Type == t {
+			ret = append(ret, pluginEvent)
+		}
+	}
+	return ret
+}
+
+// TrayRenders returns the component trees of the tray, in the order they were rendered.
+func (r *Recorder) TrayRenders() []interface{} {
+	ret := make([]interface{}, 0)
+	for _, event := range r.PluginEvents(plugin_ui.ServerTrayUpdatedEvent) {
+		if payload, ok := event.Payload.(map[string]interface{}); ok {
+			ret = append(ret, payload["components"])
+		}
+	}
+	return ret
+}
+
+// LastTrayRender returns the last component tree of the tray, or nil if the tray has not been rendered.
+func (r *Recorder) LastTrayRender() interface{} {
+	renders := r.TrayRenders()
+	if len(renders) == 0 {
+		return nil
+	}
+	return renders[len(renders)-1]
+}
+
+// Toasts returns the toasts sent to the client.
+func (r *Recorder) Toasts() []*Toast {
+	ret := make([]*Toast, 0)
+	for _, event := range r.Events() {
+		if !slices.Contains(toastTypes, event.Type) {
+			continue
+		}
+		message, _ := event.Payload.(string)
+		ret = append(ret, &Toast{Type: event.Type, Message: message})
+	}
+	return ret
+}
+
+// Logs returns the console output of the plugin.
+func (r *Recorder) Logs() []string {
+	ret := make([]string, 0)
+	for _, event := range r.Events() {
+		if event.Type != events.ConsoleLog && event.Type != events.ConsoleWarn {
+			continue
+		}
+		message, _ := event.Payload.(string)
+		ret = append(ret, message)
+	}
+	return ret
+}
+
+// Notifications returns the messages of the system notifications sent by the plugin.
+func (r *Recorder) Notifications() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.notifications)
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Components
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// FindComponents returns the components of a rendered tree that match the predicate.
+// Components are decoded as generic JSON, e.g. {"id": "...", "type": "button", "props": {"label": "Click"}}.
+func FindComponents(tree interface{}, predicate func(component map[string]interface{}) bool) []map[string]interface{} {
+	ret := make([]map[string]interface{}, 0)
+
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		switch v := node.(type) {
+		case []interface{}:
+			for _, child := range v {
+				walk(child)
+			}
+		case map[string]interface{}:
+			if _, isComponent := v["type"].(string); isComponent {
+				if _, hasProps := v["props"]; hasProps && predicate(v) {
+					ret = append(ret, v)
+				}
+			}
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(tree)
+
+	return ret
+}
+
+// FindComponentsByType returns the components of the given type, e.g. "button".
+func FindComponentsByType(tree interface{}, componentType string) []map[string]interface{} {
+	return FindComponents(tree, func(component map[string]interface{}) bool {
+		return component["type"] == componentType
+	})
+}
+
+// ContainsText returns true if a string prop of a component of the tree contains the text.
+func ContainsText(tree interface{}, text string) bool {
+	found := false
+
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		if found {
+			return
+		}
+		switch v := node.(type) {
+		case string:
+			found = strings.Contains(v, text)
+		case []interface{}:
+			for _, child := range v {
+				walk(child)
+			}
+		case map[string]interface{}:
+			if props, ok := v["props"]; ok {
+				walk(props)
+				return
+			}
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(tree)
+
+	return found
+}
+
+// componentProp returns the string prop of a component.
+func componentProp(component map[string]interface{}, name string) string {
+	props, _ := component["props"].(map[string]interface{})
+	value, _ := props[name].(string)
+	return value
+}
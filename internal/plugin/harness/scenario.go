@@ -0,0 +1,320 @@
+package plugin_harness
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	plugin_ui "seanime/internal/plugin/ui"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+	"github.com/samber/lo"
+)
+
+// ScenarioFileName is the name of the scenario file looked up in the plugin directory.
+const ScenarioFileName = "plugin-test.json"
+
+// DefaultExpectTimeout is the time given to the plugin to fulfill an expectation.
+const DefaultExpectTimeout = 2 * time.Second
+
+// InteractionDelay is the time waited after each interaction, like a user would.
+// State changes made less than plugin_ui.UIUpdateRateLimit after a render are not rendered.
+const InteractionDelay = time.Duration(plugin_ui.UIUpdateRateLimit+30) * time.Millisecond
+
+var ErrUnknownAction = errors.New("harness: unknown action")
+
+type (
+	// Scenario describes interactions with a plugin and their expected results.
+	// It lets plugin authors test their plugin from the CLI, e.g. in CI:
+	//
+	//	{
+	//	  "plugin": ".",
+	//	  "fixtures": { "animeCollection": "fixtures/anime-collection.json" },
+	//	  "steps": [
+	//	    { "action": "openTray" },
+	//	    { "action": "expectText", "text": "Hello" },
+	//	    { "action": "clickButton", "label": "Refresh" },
+	//	    { "action": "expectToast", "toastType": "success", "text": "Refreshed" }
+	//	  ]
+	//	}
+	Scenario struct {
+		// Plugin is the path to the plugin directory or manifest, relative to the scenario file.
+		// Defaults to the directory of the scenario file.
+		Plugin string `json:"plugin,omitempty"`
+		// Payload overrides the payload of the manifest, relative to the scenario file
+		Payload  string           `json:"payload,omitempty"`
+		Fixtures ScenarioFixtures `json:"fixtures,omitempty"`
+		// Timeout is the time in milliseconds given to the plugin to fulfill each expectation
+		Timeout int             `json:"timeout,omitempty"`
+		Steps   []*ScenarioStep `json:"steps"`
+
+		dir string
+	}
+
+	// ScenarioFixtures are JSON files, relative to the scenario file, holding the data of the fake AniList client.
+	ScenarioFixtures struct {
+		AnimeCollection string `json:"animeCollection,omitempty"`
+		MangaCollection string `json:"mangaCollection,omitempty"`
+	}
+
+	// ScenarioStep is an interaction or an expectation.
+	//
+	// Actions:
+	//	- "openTray", "closeTray", "clickTray", "renderTray"
+	//	- "clickButton" (label): Clicks a button of the last tray render
+	//	- "triggerHandler" (handler, event): Triggers an event handler
+	//	- "submitForm" (form, data)
+	//	- "clickAction" (id, event): Clicks an action, e.g. an anime page button
+	//	- "changeScreen" (pathname, query)
+	//	- "setFieldValue" (fieldRef, value)
+	//	- "commandPaletteInput" (value), "selectCommandPaletteItem" (id)
+	//	- "wait" (duration): Waits for the duration in milliseconds
+	//	- "expectText" (text): The last tray render contains the text
+	//	- "expectToast" (text, toastType): A toast containing the text was sent, toastType is "success", "error", "info" or "warning"
+	//	- "expectNotification" (text): A notification containing the text was sent
+	//	- "expectLog" (text): The plugin logged a message containing the text
+	ScenarioStep struct {
+		Action    string                 `json:"action"`
+		Label     string                 `json:"label,omitempty"`
+		Handler   string                 `json:"handler,omitempty"`
+		Form      string                 `json:"form,omitempty"`
+		Data      map[string]interface{} `json:"data,omitempty"`
+		ID        string                 `json:"id,omitempty"`
+		Event     map[string]interface{} `json:"event,omitempty"`
+		Pathname  string                 `json:"pathname,omitempty"`
+		Query     string                 `json:"query,omitempty"`
+		FieldRef  string                 `json:"fieldRef,omitempty"`
+		Value     interface{}            `json:"value,omitempty"`
+		Text      string                 `json:"text,omitempty"`
+		ToastType string                 `json:"toastType,omitempty"`
+		Duration  int                    `json:"duration,omitempty"`
+	}
+
+	// ScenarioReport is the result of a scenario.
+	// The scenario stops at the first failed step.
+	ScenarioReport struct {
+		Plugin string                `json:"plugin"`
+		Passed bool                  `json:"passed"`
+		Steps  []*ScenarioStepResult `json:"steps"`
+		// Logs is the console output of the plugin
+		Logs []string `json:"logs"`
+	}
+
+	ScenarioStepResult struct {
+		Index  int    `json:"index"`
+		Action string `json:"action"`
+		Error  string `json:"error,omitempty"`
+	}
+)
+
+// LoadScenario reads a scenario file, or the scenario file of a plugin directory.
+func LoadScenario(path string) (*Scenario, error) {
+	if info, err := os.Stat(path); err != nil {
+		return nil, err
+	} else if info.IsDir() {
+		path = filepath.Join(path, ScenarioFileName)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("harness: failed to read scenario: %w", err)
+	}
+
+	var scenario *Scenario
+	if err := json.Unmarshal(data, &scenario); err != nil {
+		return nil, fmt.Errorf("harness: invalid scenario: %w", err)
+	}
+	scenario.dir = filepath.Dir(path)
+
+	return scenario, nil
+}
+
+// Run loads the plugin, runs the steps and unloads the plugin.
+// An error is returned if the plugin or the fixtures could not be loaded, failed steps are reported.
+func (s *Scenario) Run(logger *zerolog.Logger) (*ScenarioReport, error) {
+	opts := Options{Logger: logger}
+
+	if s.Payload != "" {
+		opts.PayloadPath = s.resolve(s.Payload)
+	}
+	if s.Fixtures.AnimeCollection != "" {
+		if err := readFixture(s.resolve(s.Fixtures.AnimeCollection), &opts.AnimeCollection); err != nil {
+			return nil, err
+		}
+	}
+	if s.Fixtures.MangaCollection != "" {
+		if err := readFixture(s.resolve(s.Fixtures.MangaCollection), &opts.MangaCollection); err != nil {
+			return nil, err
+		}
+	}
+
+	h, err := Load(s.resolve(s.Plugin), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer h.Close()
+
+	return s.RunWith(h), nil
+}
+
+// RunWith runs the steps against a loaded plugin.
+func (s *Scenario) RunWith(h *Harness) *ScenarioReport {
+	report := &ScenarioReport{
+		Plugin: h.Extension().ID,
+		Passed: true,
+		Steps:  make([]*ScenarioStepResult, 0, len(s.Steps)),
+	}
+
+	timeout := DefaultExpectTimeout
+	if s.Timeout > 0 {
+		timeout = time.Duration(s.Timeout) * time.Millisecond
+	}
+
+	for i, step := range s.Steps {
+		result := &ScenarioStepResult{Index: i, Action: step.Action}
+		report.Steps = append(report.Steps, result)
+
+		if err := step.run(h, timeout); err != nil {
+			result.Error = err.Error()
+			report.Passed = false
+			break
+		}
+
+		if !strings.HasPrefix(step.Action, "expect") && step.Action != "wait" {
+			time.Sleep(InteractionDelay)
+		}
+	}
+
+	report.Logs = h.Recorder().Logs()
+
+	return report
+}
+
+func (s *Scenario) resolve(path string) string {
+	if path == "" {
+		return s.dir
+	}
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(s.dir, path)
+}
+
+func readFixture[T any](path string, ret *T) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("harness: failed to read fixture: %w", err)
+	}
+	if err := json.Unmarshal(data, ret); err != nil {
+		return fmt.Errorf("harness: invalid fixture %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (step *ScenarioStep) run(h *Harness, timeout time.Duration) error {
+	switch step.Action {
+	case "openTray":
+		renders := len(h.Recorder().TrayRenders())
+		h.OpenTray()
+		h.WaitForTrayRender(renders+1, timeout)
+	case "closeTray":
+		h.CloseTray()
+	case "clickTray":
+		h.ClickTray()
+	case "renderTray":
+		renders := len(h.Recorder().TrayRenders())
+		h.RenderTray()
+		h.WaitForTrayRender(renders+1, timeout)
+	case "clickButton":
+		// Wait for the button to be rendered
+		h.WaitUntil(timeout, func() bool {
+			return len(FindComponents(h.Recorder().LastTrayRender(), func(component map[string]interface{}) bool {
+				return component["type"] == "button" && componentProp(component, "label") == step.Label
+			})) > 0
+		})
+		return h.ClickButton(step.Label)
+	case "triggerHandler":
+		h.TriggerHandler(step.Handler, step.Event)
+	case "submitForm":
+		h.SubmitForm(step.Form, step.Data)
+	case "clickAction":
+		h.ClickAction(step.ID, step.Event)
+	case "changeScreen":
+		h.ChangeScreen(step.Pathname, step.Query)
+	case "setFieldValue":
+		h.SetFieldValue(step.FieldRef, step.Value)
+	case "commandPaletteInput":
+		h.SetCommandPaletteInput(fmt.Sprint(step.Value))
+	case "selectCommandPaletteItem":
+		h.SelectCommandPaletteItem(step.ID)
+	case "wait":
+		time.Sleep(time.Duration(step.Duration) * time.Millisecond)
+
+	case "expectText":
+		if !h.WaitUntil(timeout, func() bool { return ContainsText(h.Recorder().LastTrayRender(), step.Text) }) {
+			return fmt.Errorf("expected the tray to contain %q", step.Text)
+		}
+	case "expectToast":
+		if !h.WaitUntil(timeout, func() bool {
+			return slices.ContainsFunc(h.Recorder().Toasts(), func(toast *Toast) bool {
+				if step.ToastType != "" && toast.Type != step.ToastType+"-toast" {
+					return false
+				}
+				return strings.Contains(toast.Message, step.Text)
+			})
+		}) {
+			return fmt.Errorf("expected a %s toast containing %q", lo.Ternary(step.ToastType != "", step.ToastType, "any"), step.Text)
+		}
+	case "expectNotification":
+		if !h.WaitUntil(timeout, func() bool { return containsString(h.Recorder().Notifications(), step.Text) }) {
+			return fmt.Errorf("expected a notification containing %q", step.Text)
+		}
+	case "expectLog":
+		if !h.WaitUntil(timeout, func() bool { return containsString(h.Recorder().Logs(), step.Text) }) {
+			return fmt.Errorf("expected a log containing %q", step.Text)
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownAction, step.Action)
+	}
+
+	return nil
+}
+
+func containsString(messages []string, text string) bool {
+	return slices.ContainsFunc(messages, func(message string) bool {
+		return strings.Contains(message, text)
+	})
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// String formats the report for the terminal.
+func (r *ScenarioReport) String() string {
+	var sb strings.Builder
+
+	for _, step := range r.Steps {
+		if step.Error != "" {
+			sb.WriteString(fmt.Sprintf("FAIL  #%d %s: %s\n", step.Index+1, step.Action, step.Error))
+		} else {
+			sb.WriteString(fmt.Sprintf("ok    #%d %s\n", step.Index+1, step.Action))
+		}
+	}
+
+	if !r.Passed {
+		sb.WriteString("\nPlugin output:\n")
+		for _, log := range r.Logs {
+			sb.WriteString("  " + log + "\n")
+		}
+		sb.WriteString(fmt.Sprintf("\nFAIL  %s\n", r.Plugin))
+	} else {
+		sb.WriteString(fmt.Sprintf("\nPASS  %s\n", r.Plugin))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,217 @@
+package plugin_ui
+
+import (
+	"fmt"
+	"seanime/internal/plugin"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/goccy/go-json"
+)
+
+const BusCallTimeout = 30 * time.Second // Maximum time an exported function can take to return
+
+// BusManager lets the plugin publish events to other plugins and call the functions they export.
+// See DOCS.md for the delivery guarantees.
+//
+//	Example:
+//	// Publisher, declares the "stats-updated" topic and the "getStats" export in its manifest
+//	ctx.bus.publish("stats-updated", { watched: 12 })
+//	ctx.bus.export("getStats", async (mediaId) => ({ watched: 12 }))
+//
+//	// Subscriber, is granted access to the topic and function of "stats-plugin" in its manifest
+//	const unsubscribe = ctx.bus.subscribe("stats-plugin", "stats-updated", (event) => {
+//		console.log(event.payload.watched)
+//	})
+//	const stats = await ctx.bus.call("stats-plugin", "getStats", 21)
+type BusManager struct {
+	ctx *Context
+}
+
+func NewBusManager(ctx *Context) *BusManager {
+	return &BusManager{
+		ctx: ctx,
+	}
+}
+
+func (b *BusManager) bind(contextObj *goja.Object) {
+	plugin.GlobalBus.Register(b.ctx.ext)
+
+	busObj := b.ctx.vm.NewObject()
+	_ = busObj.Set("publish", b.jsPublish)
+	_ = busObj.Set("subscribe", b.jsSubscribe)
+	_ = busObj.Set("export", b.jsExport)
+	_ = busObj.Set("call", b.jsCall)
+
+	_ = contextObj.Set("bus", busObj)
+
+	b.ctx.registerOnCleanup(func() {
+		b.ctx.logger.Debug().Msg("plugin: Unregistering from the plugin bus")
+		plugin.GlobalBus.Unregister(b.ctx.ext.ID)
+	})
+}
+
+// jsPublish publishes an event to a topic declared in the manifest.
+// It returns the number of subscribers the event was delivered to.
+//
+//	Example:
+//	ctx.bus.publish("stats-updated", { watched: 12 })
+func (b *BusManager) jsPublish(call goja.FunctionCall) goja.Value {
+	topic, ok := call.Argument(0).Export().(string)
+	if !ok {
+		b.ctx.handleTypeError("bus: publish requires a topic")
+		return goja.Undefined()
+	}
+
+	payload, err := json.Marshal(call.Argument(1).Export())
+	if err != nil {
+		b.ctx.handleTypeError(fmt.Sprintf("bus: cannot serialize payload: %s", err.Error()))
+		return goja.Undefined()
+	}
+
+	delivered, err := plugin.GlobalBus.Publish(b.ctx.ext.ID, topic, payload)
+	if err != nil {
+		b.ctx.handleTypeError(err.Error())
+		return goja.Undefined()
+	}
+
+	return b.ctx.vm.ToValue(delivered)
+}
+
+// jsSubscribe subscribes to a topic of another plugin.
+// It returns a function to unsubscribe.
+//
+//	Example:
+//	const unsubscribe = ctx.bus.subscribe("stats-plugin", "stats-updated", (event) => {})
+func (b *BusManager) jsSubscribe(call goja.FunctionCall) goja.Value {
+	publisherId, ok := call.Argument(0).Export().(string)
+	if !ok {
+		b.ctx.handleTypeError("bus: subscribe requires a plugin ID")
+		return goja.Undefined()
+	}
+	topic, ok := call.Argument(1).Export().(string)
+	if !ok {
+		b.ctx.handleTypeError("bus: subscribe requires a topic")
+		return goja.Undefined()
+	}
+	callback, ok := goja.AssertFunction(call.Argument(2))
+	if !ok {
+		b.ctx.handleTypeError("bus: subscribe requires a callback function")
+		return goja.Undefined()
+	}
+
+	id, err := plugin.GlobalBus.Subscribe(b.ctx.ext.ID, publisherId, topic, func(event *plugin.BusEvent) {
+		// Events are queued in the order they are published
+		b.ctx.scheduler.ScheduleAsync(func() error {
+			var payload interface{}
+			_ = json.Unmarshal(event.Payload, &payload)
+
+			eventObj := b.ctx.vm.NewObject()
+			_ = eventObj.Set("publisher", event.Publisher)
+			_ = eventObj.Set("topic", event.Topic)
+			_ = eventObj.Set("payload", payload)
+			_ = eventObj.Set("timestamp", event.Timestamp.UnixMilli())
+
+			_, err := callback(goja.Undefined(), eventObj)
+			return err
+		})
+	})
+	if err != nil {
+		b.ctx.handleTypeError(err.Error())
+		return goja.Undefined()
+	}
+
+	return b.ctx.vm.ToValue(func() {
+		plugin.GlobalBus.Unsubscribe(id)
+	})
+}
+
+// jsExport exports a function declared in the manifest so that other plugins can call it.
+//
+//	Example:
+//	ctx.bus.export("getStats", async (mediaId) => ({ watched: 12 }))
+func (b *BusManager) jsExport(call goja.FunctionCall) goja.Value {
+	name, ok := call.Argument(0).Export().(string)
+	if !ok {
+		b.ctx.handleTypeError("bus: export requires a function name")
+		return goja.Undefined()
+	}
+	callback, ok := goja.AssertFunction(call.Argument(1))
+	if !ok {
+		b.ctx.handleTypeError("bus: export requires a function")
+		return goja.Undefined()
+	}
+
+	err := plugin.GlobalBus.Export(b.ctx.ext.ID, name, func(args []byte) ([]byte, error) {
+		var ret []byte
+		err := b.ctx.callAndAwait(callback, BusCallTimeout, func() []goja.Value {
+			var values []interface{}
+			_ = json.Unmarshal(args, &values)
+			jsArgs := make([]goja.Value, 0, len(values))
+			for _, value := range values {
+				jsArgs = append(jsArgs, b.ctx.vm.ToValue(value))
+			}
+			return jsArgs
+		}, func(result goja.Value) error {
+			var err error
+			if result == nil || goja.IsUndefined(result) {
+				ret = []byte("null")
+				return nil
+			}
+			ret, err = json.Marshal(result.Export())
+			return err
+		})
+		return ret, err
+	})
+	if err != nil {
+		b.ctx.handleTypeError(err.Error())
+	}
+
+	return goja.Undefined()
+}
+
+// jsCall calls a function exported by another plugin.
+// It returns a promise that resolves to the result of the function.
+//
+//	Example:
+//	const stats = await ctx.bus.call("stats-plugin", "getStats", 21)
+func (b *BusManager) jsCall(call goja.FunctionCall) goja.Value {
+	targetId, ok := call.Argument(0).Export().(string)
+	if !ok {
+		b.ctx.handleTypeError("bus: call requires a plugin ID")
+		return goja.Undefined()
+	}
+	name, ok := call.Argument(1).Export().(string)
+	if !ok {
+		b.ctx.handleTypeError("bus: call requires a function name")
+		return goja.Undefined()
+	}
+
+	values := make([]interface{}, 0, len(call.Arguments))
+	for _, arg := range call.Arguments[2:] {
+		values = append(values, arg.Export())
+	}
+	args, err := json.Marshal(values)
+	if err != nil {
+		b.ctx.handleTypeError(fmt.Sprintf("bus: cannot serialize arguments: %s", err.Error()))
+		return goja.Undefined()
+	}
+
+	promise, resolve, reject := b.ctx.vm.NewPromise()
+
+	go func() {
+		ret, err := plugin.GlobalBus.Call(b.ctx.ext.ID, targetId, name, args)
+		b.ctx.scheduler.ScheduleAsync(func() error {
+			if err != nil {
+				_ = reject(b.ctx.vm.NewGoError(err))
+				return nil
+			}
+			var result interface{}
+			_ = json.Unmarshal(ret, &result)
+			_ = resolve(b.ctx.vm.ToValue(result))
+			return nil
+		})
+	}()
+
+	return b.ctx.vm.ToValue(promise)
+}
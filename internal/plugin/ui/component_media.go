@@ -0,0 +1,236 @@
+package plugin_ui
+
+import (
+	"context"
+	"fmt"
+	"seanime/internal/api/anilist"
+	"seanime/internal/util"
+	"sync"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+const (
+	// mediaFetchTimeout is the time given to AniList to return the media of a batch
+	mediaFetchTimeout = 10 * time.Second
+	// mediaRetryInterval is the time after which a media that could not be fetched is fetched again
+	mediaRetryInterval = time.Minute
+	// mediaFetchConcurrency is the number of manga fetched at the same time, manga cannot be fetched in batches
+	mediaFetchConcurrency = 5
+	// maxGridMedia is the maximum number of media a media grid can display.
+	// It is also the number of anime fetched per request.
+	maxGridMedia = 50
+)
+
+// ComponentMedia is the summary of an AniList media sent with media cards and grids.
+type ComponentMedia struct {
+	ID          int    `json:"id"`
+	Type        string `json:"type"`
+	Title       string `json:"title"`
+	CoverImage  string `json:"coverImage,omitempty"`
+	BannerImage string `json:"bannerImage,omitempty"`
+	Format      string `json:"format,omitempty"`
+	Status      string `json:"status,omitempty"`
+	Episodes    int    `json:"episodes,omitempty"`
+	Chapters    int    `json:"chapters,omitempty"`
+	Year        int    `json:"year,omitempty"`
+	IsAdult     bool   `json:"isAdult,omitempty"`
+}
+
+type cachedMedia struct {
+	media     *ComponentMedia // nil if the media could not be fetched
+	fetchedAt time.Time
+}
+
+// mediaCache caches the media resolved by the components of a ComponentManager.
+// Media are fetched from AniList once, re-rendering a component does not fetch the media again.
+//
+// Media are never fetched while the plugin is rendering.
+// Missing media are queued during the render and fetched in the background once it is done,
+// the components are rendered again when the media are in the cache.
+type mediaCache struct {
+	mu      sync.Mutex
+	items   map[string]*cachedMedia
+	queued  map[string][]int    // Key: Media type, media waiting to be fetched
+	pending map[string]struct{} // Key: Cache key, media queued or being fetched
+}
+
+func mediaCacheKey(mediaType string, mediaID int) string {
+	return fmt.Sprintf("%s:%d", mediaType, mediaID)
+}
+
+func (c *ComponentManager) getMediaCache() *mediaCache {
+	c.mediaCacheOnce.Do(func() {
+		c.mediaCache = &mediaCache{
+			items:   make(map[string]*cachedMedia),
+			queued:  make(map[string][]int),
+			pending: make(map[string]struct{}),
+		}
+	})
+	return c.mediaCache
+}
+
+// resolveMedia returns the cached summaries of the given media, in the same order.
+// Media that could not be fetched are nil.
+// Media that are not in the cache yet are nil and queued, in which case loading is true.
+func (c *ComponentManager) resolveMedia(mediaType string, mediaIDs []int) (ret []*ComponentMedia, loading bool) {
+	cache := c.getMediaCache()
+	ret = make([]*ComponentMedia, len(mediaIDs))
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	for i, mediaID := range mediaIDs {
+		key := mediaCacheKey(mediaType, mediaID)
+		cached, ok := cache.items[key]
+		if ok && (cached.media != nil || time.Since(cached.fetchedAt) < mediaRetryInterval) {
+			ret[i] = cached.media
+			continue
+		}
+		loading = true
+		if _, ok := cache.pending[key]; ok {
+			continue
+		}
+		cache.pending[key] = struct{}{}
+		cache.queued[mediaType] = append(cache.queued[mediaType], mediaID)
+	}
+
+	return ret, loading
+}
+
+// fetchQueuedMedia fetches the media queued during the last render in the background.
+// onMediaResolved is called once they are in the cache.
+func (c *ComponentManager) fetchQueuedMedia() {
+	if c.mediaCache == nil {
+		return
+	}
+	cache := c.mediaCache
+
+	cache.mu.Lock()
+	queued := cache.queued
+	cache.queued = make(map[string][]int)
+	cache.mu.Unlock()
+
+	if len(queued) == 0 {
+		return
+	}
+
+	go func() {
+		defer util.HandlePanicInModuleThen("plugin/ui/fetchQueuedMedia", func() {})
+
+		for mediaType, mediaIDs := range queued {
+			fetched := c.fetchMedia(mediaType, mediaIDs)
+
+			cache.mu.Lock()
+			for _, mediaID := range mediaIDs {
+				key := mediaCacheKey(mediaType, mediaID)
+				cache.items[key] = &cachedMedia{
+					media:     fetched[mediaID],
+					fetchedAt: time.Now(),
+				}
+				delete(cache.pending, key)
+			}
+			cache.mu.Unlock()
+		}
+
+		if c.onMediaResolved != nil {
+			c.onMediaResolved()
+		}
+	}()
+}
+
+// fetchMedia fetches media from AniList.
+// Anime are fetched in batches, manga are fetched one by one.
+// The AniList client is used directly instead of the platform to avoid triggering hooks for the plugin's components.
+func (c *ComponentManager) fetchMedia(mediaType string, mediaIDs []int) map[int]*ComponentMedia {
+	ret := make(map[int]*ComponentMedia)
+
+	anilistPlatform, ok := c.ctx.ui.appContext.AnilistPlatform().Get()
+	if !ok {
+		c.ctx.logger.Warn().Msg("plugin: Cannot resolve media, AniList platform not set")
+		return ret
+	}
+	client := anilistPlatform.GetAnilistClient()
+	if client == nil {
+		return ret
+	}
+
+	switch mediaType {
+	case "manga":
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, mediaFetchConcurrency)
+		for _, mediaID := range mediaIDs {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(mediaID int) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				ctx, cancel := context.WithTimeout(context.Background(), mediaFetchTimeout)
+				defer cancel()
+
+				res, err := client.BaseMangaByID(ctx, &mediaID)
+				if err != nil || res.GetMedia() == nil {
+					c.ctx.logger.Debug().Err(err).Int("mediaId", mediaID).Msg("plugin: Failed to fetch manga for component")
+					return
+				}
+				mu.Lock()
+				ret[mediaID] = newComponentMediaFromManga(res.GetMedia())
+				mu.Unlock()
+			}(mediaID)
+		}
+		wg.Wait()
+	default:
+		for _, chunk := range lo.Chunk(mediaIDs, maxGridMedia) {
+			ids := make([]*int, 0, len(chunk))
+			for _, mediaID := range chunk {
+				ids = append(ids, lo.ToPtr(mediaID))
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), mediaFetchTimeout)
+			res, err := client.SearchBaseAnimeByIds(ctx, ids, lo.ToPtr(1), lo.ToPtr(len(ids)), nil, nil, nil, nil, nil, nil, nil)
+			cancel()
+			if err != nil || res.GetPage() == nil {
+				c.ctx.logger.Debug().Err(err).Ints("mediaIds", chunk).Msg("plugin: Failed to fetch anime for components")
+				continue
+			}
+			for _, media := range res.GetPage().GetMedia() {
+				ret[media.GetID()] = newComponentMediaFromAnime(media)
+			}
+		}
+	}
+
+	return ret
+}
+
+func newComponentMediaFromAnime(media *anilist.BaseAnime) *ComponentMedia {
+	return &ComponentMedia{
+		ID:          media.GetID(),
+		Type:        "anime",
+		Title:       media.GetPreferredTitle(),
+		CoverImage:  media.GetCoverImageSafe(),
+		BannerImage: lo.FromPtr(media.GetBannerImage()),
+		Format:      string(lo.FromPtr(media.GetFormat())),
+		Status:      string(lo.FromPtr(media.GetStatus())),
+		Episodes:    lo.FromPtr(media.GetEpisodes()),
+		Year:        media.GetStartYearSafe(),
+		IsAdult:     lo.FromPtr(media.GetIsAdult()),
+	}
+}
+
+func newComponentMediaFromManga(media *anilist.BaseManga) *ComponentMedia {
+	return &ComponentMedia{
+		ID:          media.GetID(),
+		Type:        "manga",
+		Title:       media.GetPreferredTitle(),
+		CoverImage:  media.GetCoverImageSafe(),
+		BannerImage: lo.FromPtr(media.GetBannerImage()),
+		Format:      string(lo.FromPtr(media.GetFormat())),
+		Status:      string(lo.FromPtr(media.GetStatus())),
+		Chapters:    lo.FromPtr(media.GetChapters()),
+		Year:        media.GetStartYearSafe(),
+		IsAdult:     lo.FromPtr(media.GetIsAdult()),
+	}
+}
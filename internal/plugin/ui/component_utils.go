@@ -3,6 +3,8 @@ package plugin_ui
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/dop251/goja"
 	"github.com/goccy/go-json"
@@ -18,6 +20,9 @@ func (c *ComponentManager) renderComponents(renderFunc func(goja.FunctionCall) g
 	// Get new components
 	newComponents := c.getComponentsData(renderFunc)
 
+	// Fetch the media that media cards and grids are waiting for
+	c.fetchQueuedMedia()
+
 	// If we have previous components, perform diffing
 	if c.lastRenderedComponents != nil {
 		newComponents = c.componentDiff(c.lastRenderedComponents, newComponents)
@@ -61,6 +66,12 @@ type ComponentProp struct {
 }
 
 func defineComponent(vm *goja.Runtime, call goja.FunctionCall, t string, propDefs []ComponentProp) goja.Value {
+	return vm.ToValue(newComponent(vm, call, t, propDefs))
+}
+
+// newComponent creates a component from the arguments of the call and validates its props.
+// Components that derive props from others (e.g. dataTable) can modify the props before returning the component.
+func newComponent(vm *goja.Runtime, call goja.FunctionCall, t string, propDefs []ComponentProp) Component {
 	component := Component{
 		ID:    uuid.New().String(),
 		Type:  t,
@@ -160,7 +171,7 @@ func defineComponent(vm *goja.Runtime, call goja.FunctionCall, t string, propDef
 		component.Props[k] = v
 	}
 
-	return vm.ToValue(component)
+	return component
 }
 
 // Helper function to create a validation function for a specific type
@@ -231,6 +242,85 @@ func validateType(expectedType string) func(interface{}) error {
 	}
 }
 
+// validateOneOf returns a validation function that checks that the value is one of the given strings
+func validateOneOf(values ...string) func(interface{}) error {
+	return func(value interface{}) error {
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected string, got %T", value)
+		}
+		if !slices.Contains(values, str) {
+			return fmt.Errorf("expected one of %s, got %q", strings.Join(values, ", "), str)
+		}
+		return nil
+	}
+}
+
+// validateMinNumber returns a validation function that checks that the value is a number greater than or equal to min
+func validateMinNumber(min float64) func(interface{}) error {
+	return func(value interface{}) error {
+		n, ok := toFloat(value)
+		if !ok {
+			return fmt.Errorf("expected number, got %T", value)
+		}
+		if n < min {
+			return fmt.Errorf("expected a number greater than or equal to %v, got %v", min, n)
+		}
+		return nil
+	}
+}
+
+// validateObjects returns a validation function that checks that the value is an array of objects.
+// validateItem is called for each object.
+func validateObjects(name string, validateItem func(item map[string]interface{}) error) func(interface{}) error {
+	return func(value interface{}) error {
+		arr, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s must be an array of objects", name)
+		}
+		for _, v := range arr {
+			item, ok := v.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s must be an array of objects, got %T", name, v)
+			}
+			if validateItem == nil {
+				continue
+			}
+			if err := validateItem(item); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		return nil
+	}
+}
+
+// requireProps panics if one of the props was not provided.
+// Unlike ComponentProp.Required, it checks each prop individually.
+func requireProps(vm *goja.Runtime, component Component, names ...string) {
+	for _, name := range names {
+		if v, ok := component.Props[name]; !ok || v == nil {
+			panic(vm.NewTypeError(fmt.Sprintf("%s: %s is required", component.Type, name)))
+		}
+	}
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func toInt(value interface{}) (int, bool) {
+	n, ok := toFloat(value)
+	return int(n), ok
+}
+
 // componentDiff compares two component trees and returns a new component tree that preserves the ID of old components that did not change.
 // It also recursively handles props and items arrays.
 //
@@ -269,16 +359,27 @@ func (c *ComponentManager) componentDiff(old, new interface{}) (ret interface{})
 							}
 							// Handle other props
 							for k, v := range newProps {
-								if k != "items" { // Skip items as we already handled it
-									if oldV, exists := oldProps[k]; exists {
-										newProps[k] = c.componentDiff(oldV, v)
-									}
+								if k == "items" { // Skip items as we already handled it
+									continue
+								}
+								if _, isData := dataProps[k]; isData {
+									continue
+								}
+								if oldV, exists := oldProps[k]; exists {
+									newProps[k] = c.componentDiff(oldV, v)
 								}
 							}
 							newMap["props"] = newProps
 						}
 					}
 				}
+			} else if _, hasNewType := newMap["type"]; !hasNewType {
+				// Plain objects can hold components, e.g. the tabs of a tabs component
+				for k, v := range newMap {
+					if oldV, exists := oldMap[k]; exists {
+						newMap[k] = c.componentDiff(oldV, v)
+					}
+				}
 			}
 			return newMap
 		}
@@ -338,6 +439,9 @@ func (c *ComponentManager) componentDiff(old, new interface{}) (ret interface{})
 					if oldType != "" && oldType == newType {
 						result[i] = c.componentDiff(oldComp, newComp)
 						matched = true
+					} else if isPlainObject(oldComp) && isPlainObject(newComp) {
+						result[i] = c.componentDiff(oldComp, newComp)
+						matched = true
 						// t.ctx.logger.Debug().
 						// 	Str("type", oldType).
 						// 	Msg("Component matched by type and position")
@@ -372,3 +476,26 @@ func (c *ComponentManager) componentDiff(old, new interface{}) (ret interface{})
 
 	return new
 }
+
+// dataProps are props that only hold data and never contain components.
+// They are not diffed, which avoids walking large arrays (e.g. the rows of a data table)
+// and mistaking objects that have a "type" field for components.
+var dataProps = map[string]struct{}{
+	"style":    {},
+	"fieldRef": {},
+	"options":  {},
+	"columns":  {},
+	"rows":     {},
+	"media":    {},
+	"mediaIds": {},
+}
+
+// isPlainObject returns true if the value is an object that is not a component
+func isPlainObject(v interface{}) bool {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, hasType := m["type"]
+	return !hasType
+}
@@ -1,7 +1,13 @@
 package plugin_ui
 
 import (
+	"cmp"
 	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/dop251/goja"
 	"github.com/goccy/go-json"
@@ -18,6 +24,12 @@ type ComponentManager struct {
 
 	// Last rendered components
 	lastRenderedComponents interface{}
+
+	// Media resolved by media cards and grids
+	mediaCache     *mediaCache
+	mediaCacheOnce sync.Once
+	// Called when media queued during a render are in the cache, should render the components again
+	onMediaResolved func()
 }
 
 // jsDiv
@@ -259,3 +271,377 @@ func (c *ComponentManager) jsSwitch(call goja.FunctionCall) goja.Value {
 		{Name: "className", Type: "string", Required: false, Validate: validateType("string")},
 	})
 }
+
+////////////////////////////////////////////
+// Display
+////////////////////////////////////////////
+
+func validateImageSrc(v interface{}) error {
+	src, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("expected string, got %T", v)
+	}
+	if !strings.HasPrefix(src, "https://") && !strings.HasPrefix(src, "http://") && !strings.HasPrefix(src, "data:image/") {
+		return errors.New("src must be an http(s) URL or an image data URL")
+	}
+	return nil
+}
+
+// jsImage
+//
+//	Example:
+//	const image = tray.image("https://example.com/image.png")
+//	// or
+//	const image = tray.image({ src: "https://example.com/image.png", alt: "Image", width: 200, fit: "cover" })
+func (c *ComponentManager) jsImage(call goja.FunctionCall) goja.Value {
+	component := newComponent(c.ctx.vm, call, "image", []ComponentProp{
+		{Name: "src", Type: "string", Required: true, OptionalFirstArg: true, Validate: validateImageSrc},
+		{Name: "alt", Type: "string", Required: false, Default: "", Validate: validateType("string")},
+		{Name: "width", Type: "number", Required: false, Validate: validateMinNumber(0)},
+		{Name: "height", Type: "number", Required: false, Validate: validateMinNumber(0)},
+		{Name: "fit", Type: "string", Required: false, Default: "cover", Validate: validateOneOf("cover", "contain", "fill", "none")},
+		{Name: "style", Type: "object", Required: false, Validate: validateType("object")},
+		{Name: "className", Type: "string", Required: false, Validate: validateType("string")},
+	})
+	requireProps(c.ctx.vm, component, "src")
+	return c.ctx.vm.ToValue(component)
+}
+
+// jsProgress
+//
+//	Example:
+//	const progress = tray.progress({ value: 3, max: 12, label: "Episodes watched", showValue: true })
+func (c *ComponentManager) jsProgress(call goja.FunctionCall) goja.Value {
+	component := newComponent(c.ctx.vm, call, "progress", []ComponentProp{
+		{Name: "value", Type: "number", Required: true, Validate: validateMinNumber(0)},
+		{Name: "max", Type: "number", Required: false, Default: 100, Validate: validateMinNumber(1)},
+		{Name: "label", Type: "string", Required: false, Validate: validateType("string")},
+		{Name: "showValue", Type: "boolean", Required: false, Default: false, Validate: validateType("boolean")},
+		{Name: "size", Type: "string", Required: false, Default: "md", Validate: validateOneOf("xs", "sm", "md", "lg", "xl")},
+		{Name: "style", Type: "object", Required: false, Validate: validateType("object")},
+		{Name: "className", Type: "string", Required: false, Validate: validateType("string")},
+	})
+	requireProps(c.ctx.vm, component, "value")
+
+	// Clamp the value so that the client doesn't have to
+	value, _ := toFloat(component.Props["value"])
+	maxValue, _ := toFloat(component.Props["max"])
+	component.Props["value"] = math.Min(value, maxValue)
+
+	return c.ctx.vm.ToValue(component)
+}
+
+var badgeIntents = []string{
+	"gray", "primary", "success", "warning", "alert", "info", "white",
+	"gray-solid", "primary-solid", "success-solid", "warning-solid", "alert-solid", "info-solid", "white-solid",
+}
+
+// jsBadge
+//
+//	Example:
+//	const badge = tray.badge("New")
+//	// or
+//	const badge = tray.badge({ text: "Airing", intent: "success", size: "sm" })
+func (c *ComponentManager) jsBadge(call goja.FunctionCall) goja.Value {
+	component := newComponent(c.ctx.vm, call, "badge", []ComponentProp{
+		{Name: "text", Type: "string", Required: true, OptionalFirstArg: true, Validate: validateType("string")},
+		{Name: "intent", Type: "string", Required: false, Default: "gray", Validate: validateOneOf(badgeIntents...)},
+		{Name: "size", Type: "string", Required: false, Default: "md", Validate: validateOneOf("sm", "md", "lg", "xl")},
+		{Name: "style", Type: "object", Required: false, Validate: validateType("object")},
+		{Name: "className", Type: "string", Required: false, Validate: validateType("string")},
+	})
+	requireProps(c.ctx.vm, component, "text")
+	return c.ctx.vm.ToValue(component)
+}
+
+func validateTab(tab map[string]interface{}) error {
+	if v, ok := tab["value"].(string); !ok || v == "" {
+		return errors.New("each tab must have a value")
+	}
+	if _, ok := tab["label"].(string); !ok {
+		return errors.New("each tab must have a label")
+	}
+	if items, ok := tab["items"]; ok && items != nil {
+		if _, ok := items.([]interface{}); !ok {
+			return errors.New("the items of a tab must be an array")
+		}
+	}
+	return nil
+}
+
+// jsTabs
+//
+//	Example:
+//	const tabs = tray.tabs({
+//		tabs: [
+//			{ value: "watching", label: "Watching", items: [tray.text("...")] },
+//			{ value: "planning", label: "Planning", items: [tray.text("...")] },
+//		],
+//		value: currentTab.get(),
+//		onChange: ctx.eventHandler("tabs", (e) => currentTab.set(e.value)),
+//	})
+func (c *ComponentManager) jsTabs(call goja.FunctionCall) goja.Value {
+	component := newComponent(c.ctx.vm, call, "tabs", []ComponentProp{
+		{Name: "tabs", Type: "array", Required: true, OptionalFirstArg: true, Validate: validateObjects("tabs", validateTab)},
+		{Name: "value", Type: "string", Required: false, Validate: validateType("string")},
+		{Name: "onChange", Type: "string", Required: false, Validate: validateType("string")},
+		{Name: "style", Type: "object", Required: false, Validate: validateType("object")},
+		{Name: "className", Type: "string", Required: false, Validate: validateType("string")},
+	})
+	requireProps(c.ctx.vm, component, "tabs")
+
+	// Select the first tab by default
+	tabs := component.Props["tabs"].([]interface{})
+	if _, ok := component.Props["value"]; !ok && len(tabs) > 0 {
+		component.Props["value"] = tabs[0].(map[string]interface{})["value"]
+	}
+
+	return c.ctx.vm.ToValue(component)
+}
+
+// jsModal
+//
+//	Example:
+//	const modal = tray.modal({
+//		title: "Details",
+//		open: modalOpen.get(),
+//		items: [tray.text("...")],
+//		onClose: ctx.eventHandler("close-modal", () => modalOpen.set(false)),
+//	})
+func (c *ComponentManager) jsModal(call goja.FunctionCall) goja.Value {
+	component := newComponent(c.ctx.vm, call, "modal", []ComponentProp{
+		{Name: "items", Type: "array", Required: false, Validate: validateType("array")},
+		{Name: "title", Type: "string", Required: false, Validate: validateType("string")},
+		{Name: "description", Type: "string", Required: false, Validate: validateType("string")},
+		{Name: "open", Type: "boolean", Required: false, Default: false, Validate: validateType("boolean")},
+		{Name: "onClose", Type: "string", Required: false, Validate: validateType("string")},
+		{Name: "size", Type: "string", Required: false, Default: "md", Validate: validateOneOf("sm", "md", "lg", "xl")},
+		{Name: "style", Type: "object", Required: false, Validate: validateType("object")},
+		{Name: "className", Type: "string", Required: false, Validate: validateType("string")},
+	})
+
+	// The content of a closed modal is not rendered by the client, don't send it
+	if open, _ := component.Props["open"].(bool); !open {
+		delete(component.Props, "items")
+	}
+
+	return c.ctx.vm.ToValue(component)
+}
+
+////////////////////////////////////////////
+// Data
+////////////////////////////////////////////
+
+func validateColumn(column map[string]interface{}) error {
+	if v, ok := column["key"].(string); !ok || v == "" {
+		return errors.New("each column must have a key")
+	}
+	if v, ok := column["label"]; ok {
+		if _, ok := v.(string); !ok {
+			return errors.New("the label of a column must be a string")
+		}
+	}
+	if v, ok := column["sortable"]; ok {
+		if _, ok := v.(bool); !ok {
+			return errors.New("the sortable property of a column must be a boolean")
+		}
+	}
+	return nil
+}
+
+// compareCells compares two cell values of a data table.
+// Numbers are compared numerically, strings case-insensitively and empty cells come first.
+func compareCells(a, b interface{}) int {
+	if a == nil || b == nil {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return -1
+		default:
+			return 1
+		}
+	}
+	if an, ok := toFloat(a); ok {
+		if bn, ok := toFloat(b); ok {
+			return cmp.Compare(an, bn)
+		}
+	}
+	if ab, ok := a.(bool); ok {
+		if bb, ok := b.(bool); ok {
+			switch {
+			case ab == bb:
+				return 0
+			case !ab:
+				return -1
+			default:
+				return 1
+			}
+		}
+	}
+	return cmp.Compare(strings.ToLower(fmt.Sprint(a)), strings.ToLower(fmt.Sprint(b)))
+}
+
+// jsDataTable
+// Rows are sorted and paginated on the server, only the rows of the current page are sent to the client.
+//
+//	Example:
+//	const table = tray.dataTable({
+//		columns: [
+//			{ key: "title", label: "Title", sortable: true },
+//			{ key: "progress", label: "Progress", sortable: true },
+//		],
+//		rows: entries.map(e => ({ id: e.media.id, title: e.media.title.userPreferred, progress: e.progress })),
+//		rowKey: "id",
+//		pageSize: 10,
+//		page: page.get(),
+//		sortBy: sort.get().sortBy,
+//		sortDirection: sort.get().sortDirection,
+//		onPageChange: ctx.eventHandler("table-page", (e) => page.set(e.page)),
+//		onSortChange: ctx.eventHandler("table-sort", (e) => sort.set(e)),
+//		onRowClick: ctx.eventHandler("table-row", (e) => console.log(e.row.id)),
+//	})
+func (c *ComponentManager) jsDataTable(call goja.FunctionCall) goja.Value {
+	vm := c.ctx.vm
+	component := newComponent(vm, call, "data-table", []ComponentProp{
+		{Name: "columns", Type: "array", Required: true, Validate: validateObjects("columns", validateColumn)},
+		{Name: "rows", Type: "array", Required: true, Validate: validateObjects("rows", nil)},
+		{Name: "rowKey", Type: "string", Required: false, Validate: validateType("string")},
+		{Name: "pageSize", Type: "number", Required: false, Default: 10, Validate: validateMinNumber(1)},
+		{Name: "page", Type: "number", Required: false, Default: 1, Validate: validateMinNumber(1)},
+		{Name: "sortBy", Type: "string", Required: false, Validate: validateType("string")},
+		{Name: "sortDirection", Type: "string", Required: false, Default: "asc", Validate: validateOneOf("asc", "desc")},
+		{Name: "onPageChange", Type: "string", Required: false, Validate: validateType("string")},
+		{Name: "onSortChange", Type: "string", Required: false, Validate: validateType("string")},
+		{Name: "onRowClick", Type: "string", Required: false, Validate: validateType("string")},
+		{Name: "emptyText", Type: "string", Required: false, Validate: validateType("string")},
+		{Name: "style", Type: "object", Required: false, Validate: validateType("object")},
+		{Name: "className", Type: "string", Required: false, Validate: validateType("string")},
+	})
+	requireProps(vm, component, "columns", "rows")
+
+	rows := slices.Clone(component.Props["rows"].([]interface{}))
+
+	// Sort the rows
+	if sortBy, ok := component.Props["sortBy"].(string); ok && sortBy != "" {
+		sortable := slices.ContainsFunc(component.Props["columns"].([]interface{}), func(column interface{}) bool {
+			col := column.(map[string]interface{})
+			isSortable, _ := col["sortable"].(bool)
+			return col["key"] == sortBy && isSortable
+		})
+		if !sortable {
+			panic(vm.NewTypeError(fmt.Sprintf("data-table: %q is not a sortable column", sortBy)))
+		}
+
+		desc := component.Props["sortDirection"] == "desc"
+		slices.SortStableFunc(rows, func(a, b interface{}) int {
+			ret := compareCells(a.(map[string]interface{})[sortBy], b.(map[string]interface{})[sortBy])
+			if desc {
+				return -ret
+			}
+			return ret
+		})
+	}
+
+	// Paginate the rows
+	pageSize, _ := toInt(component.Props["pageSize"])
+	page, _ := toInt(component.Props["page"])
+	totalPages := max(1, (len(rows)+pageSize-1)/pageSize)
+	page = min(page, totalPages)
+	start := min((page-1)*pageSize, len(rows))
+	end := min(start+pageSize, len(rows))
+
+	component.Props["rows"] = rows[start:end]
+	component.Props["page"] = page
+	component.Props["pageSize"] = pageSize
+	component.Props["totalRows"] = len(rows)
+	component.Props["totalPages"] = totalPages
+
+	return vm.ToValue(component)
+}
+
+////////////////////////////////////////////
+// Media
+////////////////////////////////////////////
+
+func validateMediaIDs(v interface{}) error {
+	arr, ok := v.([]interface{})
+	if !ok {
+		return fmt.Errorf("mediaIds must be an array of numbers, got %T", v)
+	}
+	if len(arr) > maxGridMedia {
+		return fmt.Errorf("mediaIds cannot contain more than %d media", maxGridMedia)
+	}
+	for _, id := range arr {
+		if err := validateMinNumber(1)(id); err != nil {
+			return fmt.Errorf("mediaIds: %w", err)
+		}
+	}
+	return nil
+}
+
+// jsMediaCard
+// The media is fetched from AniList in the background and sent with the component as the "media" prop.
+// "loading" is true until the media is fetched, the component is then rendered again.
+//
+//	Example:
+//	const card = tray.mediaCard({ mediaId: 21, type: "anime", onClick: ctx.eventHandler("card", () => {}) })
+func (c *ComponentManager) jsMediaCard(call goja.FunctionCall) goja.Value {
+	component := newComponent(c.ctx.vm, call, "media-card", []ComponentProp{
+		{Name: "mediaId", Type: "number", Required: true, Validate: validateMinNumber(1)},
+		{Name: "type", Type: "string", Required: false, Default: "anime", Validate: validateOneOf("anime", "manga")},
+		{Name: "onClick", Type: "string", Required: false, Validate: validateType("string")},
+		{Name: "style", Type: "object", Required: false, Validate: validateType("object")},
+		{Name: "className", Type: "string", Required: false, Validate: validateType("string")},
+	})
+	requireProps(c.ctx.vm, component, "mediaId")
+
+	mediaID, _ := toInt(component.Props["mediaId"])
+	component.Props["mediaId"] = mediaID
+	media, loading := c.resolveMedia(component.Props["type"].(string), []int{mediaID})
+	component.Props["media"] = media[0]
+	component.Props["loading"] = loading
+
+	return c.ctx.vm.ToValue(component)
+}
+
+// jsMediaGrid
+// The media are fetched from AniList in the background and sent with the component as the "media" prop, media that could not be found are omitted.
+// "loading" is true until all the media are fetched, the component is then rendered again.
+//
+//	Example:
+//	const grid = tray.mediaGrid({
+//		mediaIds: [21, 154587],
+//		columns: 3,
+//		onItemClick: ctx.eventHandler("grid", (e) => console.log(e.mediaId)),
+//	})
+func (c *ComponentManager) jsMediaGrid(call goja.FunctionCall) goja.Value {
+	component := newComponent(c.ctx.vm, call, "media-grid", []ComponentProp{
+		{Name: "mediaIds", Type: "array", Required: true, OptionalFirstArg: true, Validate: validateMediaIDs},
+		{Name: "type", Type: "string", Required: false, Default: "anime", Validate: validateOneOf("anime", "manga")},
+		{Name: "columns", Type: "number", Required: false, Default: 4, Validate: validateMinNumber(1)},
+		{Name: "onItemClick", Type: "string", Required: false, Validate: validateType("string")},
+		{Name: "style", Type: "object", Required: false, Validate: validateType("object")},
+		{Name: "className", Type: "string", Required: false, Validate: validateType("string")},
+	})
+	requireProps(c.ctx.vm, component, "mediaIds")
+
+	mediaIDs := make([]int, 0)
+	for _, id := range component.Props["mediaIds"].([]interface{}) {
+		mediaID, _ := toInt(id)
+		mediaIDs = append(mediaIDs, mediaID)
+	}
+	component.Props["mediaIds"] = mediaIDs
+
+	resolved, loading := c.resolveMedia(component.Props["type"].(string), mediaIDs)
+	media := make([]*ComponentMedia, 0, len(mediaIDs))
+	for _, m := range resolved {
+		if m != nil {
+			media = append(media, m)
+		}
+	}
+	component.Props["media"] = media
+	component.Props["loading"] = loading
+
+	return c.ctx.vm.ToValue(component)
+}
@@ -61,6 +61,8 @@ type Context struct {
 	commandPaletteManager *CommandPaletteManager // Register and manage command palette
 	domManager            *DOMManager            // DOM manipulation manager
 	notificationManager   *NotificationManager   // Register and manage notifications
+	httpManager           *HTTPManager           // Register and manage HTTP endpoints
+	busManager            *BusManager            // Publish and subscribe to events of other plugins
 
 	atomicCleanupCounter atomic.Int64
 	onCleanupFns         *result.Map[int64, func()]
@@ -118,6 +120,8 @@ func NewContext(ui *UI) *Context {
 	ret.commandPaletteManager = NewCommandPaletteManager(ret)
 	ret.domManager = NewDOMManager(ret)
 	ret.notificationManager = NewNotificationManager(ret)
+	ret.httpManager = NewHTTPManager(ret)
+	ret.busManager = NewBusManager(ret)
 
 	return ret
 }
@@ -165,6 +169,8 @@ func (c *Context) createAndBindContextObject(vm *goja.Runtime) {
 	plugin.GlobalAppContext.BindOnlinestreamToContextObj(vm, obj, c.logger, c.ext, c.scheduler)
 	// Bind mediastream
 	plugin.GlobalAppContext.BindMediastreamToContextObj(vm, obj, c.logger, c.ext, c.scheduler)
+	// Bind plugin bus, access to other plugins is granted in the manifest
+	c.busManager.bind(obj)
 
 	if c.ext.Plugin != nil {
 		for _, permission := range c.ext.Plugin.Permissions.Scopes {
@@ -187,6 +193,9 @@ func (c *Context) createAndBindContextObject(vm *goja.Runtime) {
 			case extension.PluginPermissionTorrentClient:
 				// Bind torrent client to the context object
 				plugin.GlobalAppContext.BindTorrentClientToContextObj(vm, obj, c.logger, c.ext, c.scheduler)
+			case extension.PluginPermissionHTTPEndpoints:
+				// Bind HTTP endpoints to the context object
+				c.httpManager.bind(obj)
 			}
 		}
 	}
@@ -414,6 +423,59 @@ func (c *Context) registerOnCleanup(fn func()) {
 	c.onCleanupFns.Set(c.atomicCleanupCounter.Load(), fn)
 }
 
+// callAndAwait calls the function in the VM and waits for the promise it returns to settle.
+// getArgs and onResult are called in the VM, onResult receives the returned or resolved value.
+// Errors thrown by the function are returned and don't count as plugin exceptions.
+func (c *Context) callAndAwait(fn goja.Callable, timeout time.Duration, getArgs func() []goja.Value, onResult func(goja.Value) error) error {
+	doneCh := make(chan error, 1)
+
+	err := c.scheduler.ScheduleWithTimeout(func() error {
+		ret, err := fn(goja.Undefined(), getArgs()...)
+		if err != nil {
+			doneCh <- err
+			return nil
+		}
+
+		promise, ok := ret.Export().(*goja.Promise)
+		if !ok {
+			doneCh <- onResult(ret)
+			return nil
+		}
+
+		// Wait for the promise without blocking the VM
+		go func() {
+			deadline := time.After(timeout)
+			for promise.State() == goja.PromiseStatePending {
+				select {
+				case <-deadline:
+					return
+				case <-time.After(10 * time.Millisecond):
+				}
+			}
+
+			c.scheduler.ScheduleAsync(func() error {
+				if promise.State() == goja.PromiseStateRejected {
+					doneCh <- fmt.Errorf("promise rejected: %v", promise.Result())
+					return nil
+				}
+				doneCh <- onResult(promise.Result())
+				return nil
+			})
+		}()
+		return nil
+	}, timeout)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case err = <-doneCh:
+		return err
+	case <-time.After(timeout):
+		return ErrCallTimeout
+	}
+}
+
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
 // jsState is used to create a new state object
@@ -0,0 +1,248 @@
+package plugin_ui
+
+import (
+	"fmt"
+	"net/http"
+	"seanime/internal/plugin"
+	"sync"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/goccy/go-json"
+)
+
+const EndpointTimeout = 30 * time.Second // Maximum time a plugin endpoint can take to respond
+
+// HTTPManager lets the plugin expose HTTP endpoints under /api/v1/plugins/{id}.
+//
+//	Example:
+//	ctx.http.get("/items/:id", (req, res) => {
+//		res.json({ id: req.params.id })
+//	})
+//	ctx.http.post("/items", async (req, res) => {
+//		const body = req.json()
+//		res.status(201).json(await save(body))
+//	})
+type HTTPManager struct {
+	ctx *Context
+}
+
+// endpointResponse is written to by the handler on the VM goroutine
+// and read by the HTTP handler once the plugin handler has returned.
+type endpointResponse struct {
+	mu      sync.Mutex
+	status  int
+	headers map[string]string
+	body    []byte
+	sent    bool
+}
+
+func NewHTTPManager(ctx *Context) *HTTPManager {
+	return &HTTPManager{
+		ctx: ctx,
+	}
+}
+
+func (h *HTTPManager) bind(contextObj *goja.Object) {
+	httpObj := h.ctx.vm.NewObject()
+	_ = httpObj.Set("handle", h.jsHandle)
+	_ = httpObj.Set("get", h.jsHandleMethod(http.MethodGet))
+	_ = httpObj.Set("post", h.jsHandleMethod(http.MethodPost))
+	_ = httpObj.Set("put", h.jsHandleMethod(http.MethodPut))
+	_ = httpObj.Set("patch", h.jsHandleMethod(http.MethodPatch))
+	_ = httpObj.Set("delete", h.jsHandleMethod(http.MethodDelete))
+	_ = httpObj.Set("remove", h.jsRemove)
+
+	_ = contextObj.Set("http", httpObj)
+
+	h.ctx.registerOnCleanup(func() {
+		h.ctx.logger.Debug().Msg("plugin: Unregistering HTTP endpoints")
+		plugin.GlobalEndpointRegistry.UnregisterAll(h.ctx.ext.ID)
+	})
+}
+
+// jsHandle registers an endpoint for the given method, "*" matches any method.
+//
+//	Example:
+//	ctx.http.handle("GET", "/status", (req, res) => res.text("ok"))
+func (h *HTTPManager) jsHandle(call goja.FunctionCall) goja.Value {
+	method, ok := call.Argument(0).Export().(string)
+	if !ok {
+		h.ctx.handleTypeError("http: handle requires a method")
+		return goja.Undefined()
+	}
+	h.register(method, call.Argument(1), call.Argument(2))
+	return goja.Undefined()
+}
+
+func (h *HTTPManager) jsHandleMethod(method string) func(call goja.FunctionCall) goja.Value {
+	return func(call goja.FunctionCall) goja.Value {
+		h.register(method, call.Argument(0), call.Argument(1))
+		return goja.Undefined()
+	}
+}
+
+// jsRemove unregisters an endpoint.
+//
+//	Example:
+//	ctx.http.remove("GET", "/status")
+func (h *HTTPManager) jsRemove(call goja.FunctionCall) goja.Value {
+	method, ok := call.Argument(0).Export().(string)
+	if !ok {
+		h.ctx.handleTypeError("http: remove requires a method")
+		return goja.Undefined()
+	}
+	path, ok := call.Argument(1).Export().(string)
+	if !ok {
+		h.ctx.handleTypeError("http: remove requires a path")
+		return goja.Undefined()
+	}
+	plugin.GlobalEndpointRegistry.Unregister(h.ctx.ext.ID, method, path)
+	return goja.Undefined()
+}
+
+func (h *HTTPManager) register(method string, pathValue goja.Value, handlerValue goja.Value) {
+	path, ok := pathValue.Export().(string)
+	if !ok {
+		h.ctx.handleTypeError("http: path must be a string")
+		return
+	}
+	callback, ok := goja.AssertFunction(handlerValue)
+	if !ok {
+		h.ctx.handleTypeError("http: handler must be a function")
+		return
+	}
+
+	err := plugin.GlobalEndpointRegistry.Register(h.ctx.ext.ID, method, path, h.newEndpointHandler(callback))
+	if err != nil {
+		h.ctx.handleTypeError(err.Error())
+		return
+	}
+
+	h.ctx.logger.Trace().Str("method", method).Str("path", path).Msg("plugin: Registered HTTP endpoint")
+}
+
+// newEndpointHandler returns a handler calling the JS function in the plugin VM.
+// Errors thrown by the function are returned to the client and don't count as plugin exceptions.
+func (h *HTTPManager) newEndpointHandler(callback goja.Callable) plugin.EndpointHandler {
+	return func(req *plugin.EndpointRequest) (*plugin.EndpointResponse, error) {
+		res := &endpointResponse{
+			status:  http.StatusOK,
+			headers: make(map[string]string),
+		}
+
+		err := h.ctx.callAndAwait(callback, EndpointTimeout, func() []goja.Value {
+			return []goja.Value{h.newRequestObject(req), h.newResponseObject(res)}
+		}, res.sendReturnValue)
+		if err != nil {
+			return nil, err
+		}
+
+		return res.toEndpointResponse(), nil
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (h *HTTPManager) newRequestObject(req *plugin.EndpointRequest) *goja.Object {
+	obj := h.ctx.vm.NewObject()
+	_ = obj.Set("method", req.Method)
+	_ = obj.Set("path", req.Path)
+	_ = obj.Set("params", req.Params)
+	_ = obj.Set("query", req.Query)
+	_ = obj.Set("headers", req.Headers)
+	_ = obj.Set("body", string(req.Body))
+	// json parses the body, it returns undefined if the body is empty
+	_ = obj.Set("json", func() goja.Value {
+		if len(req.Body) == 0 {
+			return goja.Undefined()
+		}
+		var data interface{}
+		if err := json.Unmarshal(req.Body, &data); err != nil {
+			panic(h.ctx.vm.NewTypeError("http: invalid JSON body: %s", err.Error()))
+		}
+		return h.ctx.vm.ToValue(data)
+	})
+	return obj
+}
+
+func (h *HTTPManager) newResponseObject(res *endpointResponse) *goja.Object {
+	obj := h.ctx.vm.NewObject()
+	// status sets the status code, e.g. res.status(404).json({ error: "Not found" })
+	_ = obj.Set("status", func(code int) *goja.Object {
+		if code < 100 || code > 599 {
+			panic(h.ctx.vm.NewTypeError("http: invalid status code %d", code))
+		}
+		res.mu.Lock()
+		res.status = code
+		res.mu.Unlock()
+		return obj
+	})
+	_ = obj.Set("header", func(key string, value string) *goja.Object {
+		res.mu.Lock()
+		res.headers[http.CanonicalHeaderKey(key)] = value
+		res.mu.Unlock()
+		return obj
+	})
+	_ = obj.Set("json", func(data goja.Value) {
+		body, err := json.Marshal(data.Export())
+		if err != nil {
+			panic(h.ctx.vm.NewTypeError("http: cannot serialize response: %s", err.Error()))
+		}
+		res.send("application/json", body)
+	})
+	_ = obj.Set("text", func(text string) {
+		res.send("text/plain; charset=utf-8", []byte(text))
+	})
+	_ = obj.Set("html", func(html string) {
+		res.send("text/html; charset=utf-8", []byte(html))
+	})
+	return obj
+}
+
+func (r *endpointResponse) send(contentType string, body []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.headers["Content-Type"]; !ok {
+		r.headers["Content-Type"] = contentType
+	}
+	r.body = body
+	r.sent = true
+}
+
+// sendReturnValue sends the value returned by the handler as JSON if the handler didn't send a response.
+func (r *endpointResponse) sendReturnValue(value goja.Value) error {
+	r.mu.Lock()
+	sent := r.sent
+	r.mu.Unlock()
+	if sent || value == nil || goja.IsUndefined(value) || goja.IsNull(value) {
+		return nil
+	}
+
+	body, err := json.Marshal(value.Export())
+	if err != nil {
+		return fmt.Errorf("http: cannot serialize response: %w", err)
+	}
+	r.send("application/json", body)
+	return nil
+}
+
+func (r *endpointResponse) toEndpointResponse() *plugin.EndpointResponse {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ret := &plugin.EndpointResponse{
+		Status:  r.status,
+		Headers: make(map[string]string, len(r.headers)),
+		Body:    r.body,
+	}
+	for key, value := range r.headers {
+		ret.Headers[key] = value
+	}
+	// Nothing was sent
+	if !r.sent && r.status == http.StatusOK {
+		ret.Status = http.StatusNoContent
+	}
+
+	return ret
+}
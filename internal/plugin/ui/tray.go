@@ -18,11 +18,14 @@ type TrayManager struct {
 }
 
 func NewTrayManager(ctx *Context) *TrayManager {
-	return &TrayManager{
+	t := &TrayManager{
 		ctx:              ctx,
 		tray:             mo.None[*Tray](),
 		componentManager: &ComponentManager{ctx: ctx},
 	}
+	// Render the tray again once the media of media cards and grids are fetched
+	t.componentManager.onMediaResolved = t.renderTrayScheduled
+	return t
 }
 
 // renderTrayScheduled renders the new component tree.
@@ -164,6 +167,14 @@ func (t *TrayManager) jsNewTray(call goja.FunctionCall) goja.Value {
 	_ = trayObj.Set("switch", t.componentManager.jsSwitch)
 	_ = trayObj.Set("checkbox", t.componentManager.jsCheckbox)
 	_ = trayObj.Set("select", t.componentManager.jsSelect)
+	_ = trayObj.Set("image", t.componentManager.jsImage)
+	_ = trayObj.Set("progress", t.componentManager.jsProgress)
+	_ = trayObj.Set("badge", t.componentManager.jsBadge)
+	_ = trayObj.Set("tabs", t.componentManager.jsTabs)
+	_ = trayObj.Set("modal", t.componentManager.jsModal)
+	_ = trayObj.Set("dataTable", t.componentManager.jsDataTable)
+	_ = trayObj.Set("mediaCard", t.componentManager.jsMediaCard)
+	_ = trayObj.Set("mediaGrid", t.componentManager.jsMediaGrid)
 
 	return trayObj
 }
@@ -18,6 +18,7 @@ import (
 var (
 	ErrTooManyExceptions = errors.New("plugin: Too many exceptions")
 	ErrFatalError        = errors.New("plugin: Fatal error")
+	ErrCallTimeout       = errors.New("plugin: Timed out waiting for the plugin")
 )
 
 const (
@@ -24,6 +24,11 @@ func startApp(embeddedLogo []byte) (*core.App, core.SeanimeFlags, *updater.SelfU
 	// Get the flags
 	flags := core.GetSeanimeFlags()
 
+	// Run the test scenario of a plugin without starting the app
+	if flags.TestPlugin != "" {
+		os.Exit(runPluginTest(flags.TestPlugin))
+	}
+
 	selfupdater := updater.NewSelfUpdater()
 
 	// Create the app instance
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"fmt"
+	plugin_harness "seanime/internal/plugin/harness"
+)
+
+// runPluginTest runs the test scenario of a plugin with the plugin harness and prints the report.
+// It returns the exit code of the process.
+func runPluginTest(path string) int {
+	scenario, err := plugin_harness.LoadScenario(path)
+	if err != nil {
+		fmt.Printf("Failed to load the test scenario: %v\n", err)
+		return 1
+	}
+
+	report, err := scenario.Run(nil)
+	if err != nil {
+		fmt.Printf("Failed to run the test scenario: %v\n", err)
+		return 1
+	}
+
+	fmt.Print(report.String())
+
+	if !report.Passed {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,273 @@
+package aria2
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+)
+
+type (
+	// Client is a client for the aria2 JSON-RPC interface.
+	Client struct {
+		Logger  *zerolog.Logger
+		baseUrl string
+		secret  string
+		client  *http.Client
+		mu      sync.Mutex
+		reqId   int
+	}
+
+	NewClientOptions struct {
+		Logger *zerolog.Logger
+		Secret string // RPC secret token, set with --rpc-secret
+		Host   string // Default: 127.0.0.1
+		Port   int    // Default: 6800
+	}
+
+	// Status is the status of a download, returned by tellStatus, tellActive, tellWaiting and tellStopped.
+	// Numbers are returned as strings by aria2.
+	Status struct {
+		Gid             string      `json:"gid"`
+		Status          string      `json:"status"`
+		TotalLength     string      `json:"totalLength"`
+		CompletedLength string      `json:"completedLength"`
+		DownloadSpeed   string      `json:"downloadSpeed"`
+		UploadSpeed     string      `json:"uploadSpeed"`
+		NumSeeders      string      `json:"numSeeders"`
+		InfoHash        string      `json:"infoHash"`
+		Dir             string      `json:"dir"`
+		FollowedBy      []string    `json:"followedBy"`
+		Files           []*File     `json:"files"`
+		Bittorrent      *Bittorrent `json:"bittorrent"`
+		ErrorMessage    string      `json:"errorMessage"`
+	}
+
+	Bittorrent struct {
+		Info *struct {
+			Name string `json:"name"`
+		} `json:"info"`
+	}
+
+	File struct {
+		Index           string `json:"index"` // 1-based
+		Path            string `json:"path"`
+		Length          string `json:"length"`
+		CompletedLength string `json:"completedLength"`
+		Selected        string `json:"selected"`
+	}
+
+	rpcRequest struct {
+		JsonRpc string        `json:"jsonrpc"`
+		Id      string        `json:"id"`
+		Method  string        `json:"method"`
+		Params  []interface{} `json:"params"`
+	}
+
+	rpcResponse struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+)
+
+const (
+	StatusActive   = "active"
+	StatusWaiting  = "waiting"
+	StatusPaused   = "paused"
+	StatusError    = "error"
+	StatusComplete = "complete"
+	StatusRemoved  = "removed"
+)
+
+var statusKeys = []string{
+	"gid", "status", "totalLength", "completedLength", "downloadSpeed", "uploadSpeed",
+	"numSeeders", "infoHash", "dir", "followedBy", "bittorrent", "errorMessage",
+}
+
+// maxStoppedDownloads is the number of waiting and stopped downloads that are returned.
+const maxStoppedDownloads = 1000
+
+func New(opts *NewClientOptions) *Client {
+	if opts.Host == "" {
+		opts.Host = "127.0.0.1"
+	}
+	if opts.Port == 0 {
+		opts.Port = 6800
+	}
+
+	baseUrl := fmt.Sprintf("http://%s:%d/jsonrpc", opts.Host, opts.Port)
+	if strings.HasPrefix(opts.Host, "https://") || strings.HasPrefix(opts.Host, "http://") {
+		baseUrl = fmt.Sprintf("%s:%d/jsonrpc", strings.TrimSuffix(opts.Host, "/"), opts.Port)
+	}
+
+	return &Client{
+		Logger:  opts.Logger,
+		baseUrl: baseUrl,
+		secret:  opts.Secret,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+func (c *Client) call(method string, result interface{}, params ...interface{}) error {
+	c.mu.Lock()
+	c.reqId++
+	id := c.reqId
+	c.mu.Unlock()
+
+	// The secret token is the first parameter of every method
+	if c.secret != "" {
+		params = append([]interface{}{"token:" + c.secret}, params...)
+	}
+	if params == nil {
+		params = make([]interface{}, 0)
+	}
+
+	body, err := json.Marshal(&rpcRequest{
+		JsonRpc: "2.0",
+		Id:      fmt.Sprintf("seanime-%d", id),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.baseUrl, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var res rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return fmt.Errorf("aria2: failed to decode response (status %d): %w", resp.StatusCode, err)
+	}
+
+	if res.Error != nil {
+		return fmt.Errorf("aria2: %s failed: %s", method, res.Error.Message)
+	}
+
+	if result != nil && len(res.Result) > 0 {
+		if err := json.Unmarshal(res.Result, result); err != nil {
+			return fmt.Errorf("aria2: failed to decode %s result: %w", method, err)
+		}
+	}
+
+	return nil
+}
+
+// GetVersion returns the version of aria2.
+func (c *Client) GetVersion() (string, error) {
+	var res struct {
+		Version string `json:"version"`
+	}
+	if err := c.call("aria2.getVersion", &res); err != nil {
+		return "", err
+	}
+	return res.Version, nil
+}
+
+// GetAll returns the active, waiting and stopped downloads.
+func (c *Client) GetAll(withFiles bool) ([]*Status, error) {
+	keys := statusKeys
+	if withFiles {
+		keys = append(keys[:len(keys):len(keys)], "files")
+	}
+
+	var active, waiting, stopped []*Status
+	if err := c.call("aria2.tellActive", &active, keys); err != nil {
+		return nil, err
+	}
+	if err := c.call("aria2.tellWaiting", &waiting, 0, maxStoppedDownloads, keys); err != nil {
+		return nil, err
+	}
+	if err := c.call("aria2.tellStopped", &stopped, 0, maxStoppedDownloads, keys); err != nil {
+		return nil, err
+	}
+
+	ret := make([]*Status, 0, len(active)+len(waiting)+len(stopped))
+	ret = append(ret, active...)
+	ret = append(ret, waiting...)
+	ret = append(ret, stopped...)
+	return ret, nil
+}
+
+// AddUri adds a download, e.g. a magnet link, and returns its GID.
+func (c *Client) AddUri(uri string, dir string) (string, error) {
+	options := map[string]string{}
+	if dir != "" {
+		options["dir"] = dir
+	}
+
+	var gid string
+	if err := c.call("aria2.addUri", &gid, []string{uri}, options); err != nil {
+		return "", err
+	}
+	return gid, nil
+}
+
+func (c *Client) Remove(gid string) error {
+	return c.call("aria2.remove", nil, gid)
+}
+
+// RemoveDownloadResult removes a completed, errored or removed download from the list.
+func (c *Client) RemoveDownloadResult(gid string) error {
+	return c.call("aria2.removeDownloadResult", nil, gid)
+}
+
+func (c *Client) Pause(gid string) error {
+	return c.call("aria2.pause", nil, gid)
+}
+
+func (c *Client) Unpause(gid string) error {
+	return c.call("aria2.unpause", nil, gid)
+}
+
+func (c *Client) GetFiles(gid string) ([]*File, error) {
+	var ret []*File
+	if err := c.call("aria2.getFiles", &ret, gid); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// ChangeOption changes the options of a download, e.g. "select-file".
+func (c *Client) ChangeOption(gid string, options map[string]string) error {
+	return c.call("aria2.changeOption", nil, gid, options)
+}
+
+// IsMetadata returns true if the download is the metadata download of a magnet link.
+// aria2 creates a new download for the files when the metadata is downloaded, see Status.FollowedBy.
+func (s *Status) IsMetadata() bool {
+	if len(s.FollowedBy) > 0 {
+		return true
+	}
+	return len(s.Files) == 1 && strings.HasPrefix(s.Files[0].Path, "[METADATA]")
+}
+
+// GetName returns the name of the torrent, or the path of the first file.
+func (s *Status) GetName() string {
+	if s.Bittorrent != nil && s.Bittorrent.Info != nil && s.Bittorrent.Info.Name != "" {
+		return s.Bittorrent.Info.Name
+	}
+	if len(s.Files) > 0 {
+		return s.Files[0].Path
+	}
+	return s.Gid
+}
@@ -0,0 +1,314 @@
+package deluge
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+)
+
+type (
+	// Client is a client for the Deluge Web UI JSON-RPC API.
+	Client struct {
+		Logger   *zerolog.Logger
+		baseUrl  string
+		password string
+		client   *http.Client
+		mu       sync.Mutex
+		reqId    int
+		loggedIn bool
+	}
+
+	NewClientOptions struct {
+		Logger   *zerolog.Logger
+		Password string
+		Host     string // Default: 127.0.0.1
+		Port     int    // Default: 8112
+	}
+
+	// TorrentStatus contains the fields requested by GetTorrentsStatus.
+	TorrentStatus struct {
+		Hash                string  `json:"hash"`
+		Name                string  `json:"name"`
+		State               string  `json:"state"`
+		Progress            float64 `json:"progress"` // 0-100
+		TotalSize           int64   `json:"total_size"`
+		Eta                 int64   `json:"eta"`
+		NumSeeds            int     `json:"num_seeds"`
+		DownloadPayloadRate int64   `json:"download_payload_rate"`
+		UploadPayloadRate   int64   `json:"upload_payload_rate"`
+		SavePath            string  `json:"save_path"`
+		IsFinished          bool    `json:"is_finished"`
+		Files               []*File `json:"files"`
+		FilePriorities      []int   `json:"file_priorities"`
+	}
+
+	File struct {
+		Index  int    `json:"index"`
+		Path   string `json:"path"`
+		Size   int64  `json:"size"`
+		Offset int64  `json:"offset"`
+	}
+
+	rpcRequest struct {
+		Method string        `json:"method"`
+		Params []interface{} `json:"params"`
+		Id     int           `json:"id"`
+	}
+
+	rpcResponse struct {
+		Result json.RawMessage `json:"result"`
+		Error  *rpcError       `json:"error"`
+		Id     int             `json:"id"`
+	}
+
+	rpcError struct {
+		Message string `json:"message"`
+		Code    int    `json:"code"`
+	}
+)
+
+const (
+	StateDownloading = "Downloading"
+	StateSeeding     = "Seeding"
+	StatePaused      = "Paused"
+	StateChecking    = "Checking"
+	StateQueued      = "Queued"
+	StateAllocating  = "Allocating"
+	StateMoving      = "Moving"
+	StateError       = "Error"
+)
+
+var statusKeys = []string{
+	"hash", "name", "state", "progress", "total_size", "eta", "num_seeds",
+	"download_payload_rate", "upload_payload_rate", "save_path", "is_finished",
+}
+
+func New(opts *NewClientOptions) *Client {
+	if opts.Host == "" {
+		opts.Host = "127.0.0.1"
+	}
+	if opts.Port == 0 {
+		opts.Port = 8112
+	}
+
+	baseUrl := fmt.Sprintf("http://%s:%d/json", opts.Host, opts.Port)
+	if strings.HasPrefix(opts.Host, "https://") || strings.HasPrefix(opts.Host, "http://") {
+		baseUrl = fmt.Sprintf("%s:%d/json", strings.TrimSuffix(opts.Host, "/"), opts.Port)
+	}
+
+	jar, _ := cookiejar.New(nil)
+
+	return &Client{
+		Logger:   opts.Logger,
+		baseUrl:  baseUrl,
+		password: opts.Password,
+		client: &http.Client{
+			Jar:     jar,
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// call sends a request to the Web UI, logging in and connecting to the daemon if needed.
+func (c *Client) call(method string, result interface{}, params ...interface{}) error {
+	c.mu.Lock()
+	loggedIn := c.loggedIn
+	c.mu.Unlock()
+
+	if !loggedIn {
+		if err := c.login(); err != nil {
+			return err
+		}
+	}
+
+	err := c.rawCall(method, result, params...)
+	if err != nil && isAuthError(err) {
+		// The session expired
+		if err := c.login(); err != nil {
+			return err
+		}
+		return c.rawCall(method, result, params...)
+	}
+	return err
+}
+
+func (c *Client) rawCall(method string, result interface{}, params ...interface{}) error {
+	if params == nil {
+		params = make([]interface{}, 0)
+	}
+
+	c.mu.Lock()
+	c.reqId++
+	id := c.reqId
+	c.mu.Unlock()
+
+	body, err := json.Marshal(&rpcRequest{
+		Method: method,
+		Params: params,
+		Id:     id,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.baseUrl, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("deluge: %s returned status %d", method, resp.StatusCode)
+	}
+
+	var res rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return fmt.Errorf("deluge: failed to decode response: %w", err)
+	}
+
+	if res.Error != nil {
+		return &Error{Method: method, Message: res.Error.Message, Code: res.Error.Code}
+	}
+
+	if result != nil && len(res.Result) > 0 {
+		if err := json.Unmarshal(res.Result, result); err != nil {
+			return fmt.Errorf("deluge: failed to decode %s result: %w", method, err)
+		}
+	}
+
+	return nil
+}
+
+// Error is returned when the Web UI returns an error.
+type Error struct {
+	Method  string
+	Message string
+	Code    int
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("deluge: %s failed: %s", e.Method, e.Message)
+}
+
+func isAuthError(err error) bool {
+	var e *Error
+	// Code 1 is returned when the session is not authenticated
+	return errors.As(err, &e) && e.Code == 1
+}
+
+// login authenticates with the Web UI and connects it to the first daemon if it is not connected.
+func (c *Client) login() error {
+	var ok bool
+	if err := c.rawCall("auth.login", &ok, c.password); err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("deluge: invalid password")
+	}
+
+	var connected bool
+	if err := c.rawCall("web.connected", &connected); err != nil {
+		return err
+	}
+
+	if !connected {
+		// [[id, host, port, status], ...]
+		var hosts [][]interface{}
+		if err := c.rawCall("web.get_hosts", &hosts); err != nil {
+			return err
+		}
+		if len(hosts) == 0 || len(hosts[0]) == 0 {
+			return errors.New("deluge: no daemon configured in the Web UI")
+		}
+		if err := c.rawCall("web.connect", nil, hosts[0][0]); err != nil {
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	c.loggedIn = true
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Ping returns nil if the Web UI is reachable and connected to a daemon.
+func (c *Client) Ping() error {
+	var connected bool
+	if err := c.call("web.connected", &connected); err != nil {
+		return err
+	}
+	if !connected {
+		return errors.New("deluge: Web UI is not connected to a daemon")
+	}
+	return nil
+}
+
+// GetTorrentsStatus returns the status of the torrents, by hash.
+// If hashes is empty, all torrents are returned.
+func (c *Client) GetTorrentsStatus(hashes []string, withFiles bool) (map[string]*TorrentStatus, error) {
+	filter := map[string]interface{}{}
+	if len(hashes) > 0 {
+		filter["id"] = hashes
+	}
+
+	keys := statusKeys
+	if withFiles {
+		keys = append(keys[:len(keys):len(keys)], "files", "file_priorities")
+	}
+
+	ret := make(map[string]*TorrentStatus)
+	if err := c.call("core.get_torrents_status", &ret, filter, keys); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// AddMagnet adds a magnet link and returns the hash of the torrent.
+func (c *Client) AddMagnet(magnet string, downloadLocation string) (string, error) {
+	options := map[string]interface{}{}
+	if downloadLocation != "" {
+		options["download_location"] = downloadLocation
+	}
+
+	var hash string
+	if err := c.call("core.add_torrent_magnet", &hash, magnet, options); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+func (c *Client) RemoveTorrent(hash string, removeData bool) error {
+	return c.call("core.remove_torrent", nil, hash, removeData)
+}
+
+func (c *Client) PauseTorrents(hashes []string) error {
+	return c.call("core.pause_torrents", nil, hashes)
+}
+
+func (c *Client) ResumeTorrents(hashes []string) error {
+	return c.call("core.resume_torrents", nil, hashes)
+}
+
+// SetFilePriorities sets the priority of each file of the torrent, 0 means the file is not downloaded.
+func (c *Client) SetFilePriorities(hash string, priorities []int) error {
+	return c.call("core.set_torrent_options", nil, []string{hash}, map[string]interface{}{
+		"file_priorities": priorities,
+	})
+}
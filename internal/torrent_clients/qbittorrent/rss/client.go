@@ -7,6 +7,7 @@ import (
 	"net/url"
 	qbittorrent_model "seanime/internal/torrent_clients/qbittorrent/model"
 	qbittorrent_util "seanime/internal/torrent_clients/qbittorrent/util"
+	"strconv"
 )
 
 type Client struct {
@@ -26,7 +27,7 @@ func (c Client) AddFolder(folder string) error {
 
 func (c Client) AddFeed(link string, folder string) error {
 	params := url.Values{}
-	params.Add("path", folder)
+	params.Add("url", link)
 	if folder != "" {
 		params.Add("path", folder)
 	}
@@ -95,3 +96,15 @@ func (c Client) GetRules() (map[string]qbittorrent_model.RuleDefinition, error)
 	}
 	return res, nil
 }
+
+// GetItems returns the RSS items.
+// Folders are objects containing other items, feeds are objects containing a "uid" and a "url".
+func (c Client) GetItems(withData bool) (map[string]json.RawMessage, error) {
+	params := url.Values{}
+	params.Add("withData", strconv.FormatBool(withData))
+	var res map[string]json.RawMessage
+	if err := qbittorrent_util.GetInto(c.Client, &res, c.BaseUrl+"/items?"+params.Encode(), nil); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,334 @@
+package rtorrent
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type (
+	// Client is a client for the rTorrent XML-RPC interface.
+	// It connects over HTTP (e.g. ruTorrent's RPC plugin or a web server mount) or directly over SCGI.
+	Client struct {
+		Logger   *zerolog.Logger
+		url      *url.URL
+		username string
+		password string
+		client   *http.Client
+	}
+
+	NewClientOptions struct {
+		Logger *zerolog.Logger
+		// URL of the XML-RPC endpoint, e.g.
+		//	- http://127.0.0.1:8080/RPC2
+		//	- https://example.com/rutorrent/plugins/rpc/rpc.php
+		//	- scgi://127.0.0.1:5000
+		//	- scgi:///home/user/.rtorrent.sock
+		URL      string
+		Username string // HTTP basic auth
+		Password string
+	}
+
+	// Torrent contains the fields returned by GetTorrents.
+	Torrent struct {
+		Hash           string
+		Name           string
+		SizeBytes      int64
+		CompletedBytes int64
+		DownRate       int64
+		UpRate         int64
+		PeersComplete  int64
+		State          bool // Started
+		IsActive       bool // Not paused
+		Complete       bool
+		Hashing        bool
+		Directory      string
+		BasePath       string
+	}
+)
+
+// torrentFields are the d.multicall2 commands, in the order of the Torrent fields.
+var torrentFields = []interface{}{
+	"d.hash=", "d.name=", "d.size_bytes=", "d.completed_bytes=", "d.down.rate=", "d.up.rate=",
+	"d.peers_complete=", "d.state=", "d.is_active=", "d.complete=", "d.hashing=", "d.directory=", "d.base_path=",
+}
+
+func New(opts *NewClientOptions) (*Client, error) {
+	if opts.URL == "" {
+		return nil, errors.New("rtorrent: no URL provided")
+	}
+
+	u, err := url.Parse(opts.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "http", "https", "scgi":
+	default:
+		return nil, fmt.Errorf("rtorrent: unsupported URL scheme %q", u.Scheme)
+	}
+
+	return &Client{
+		Logger:   opts.Logger,
+		url:      u,
+		username: opts.Username,
+		password: opts.Password,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}, nil
+}
+
+// Call calls an XML-RPC method and returns the decoded result.
+func (c *Client) Call(method string, params ...interface{}) (interface{}, error) {
+	body, err := encodeRequest(method, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []byte
+	if c.url.Scheme == "scgi" {
+		res, err = c.doSCGI(body)
+	} else {
+		res, err = c.doHTTP(body)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeResponse(res)
+}
+
+func (c *Client) doHTTP(body []byte) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodPost, c.url.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "text/xml")
+	if c.username != "" || c.password != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("rtorrent: request failed with status %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// doSCGI sends the request directly to rTorrent's SCGI socket (network.scgi.open_port or network.scgi.open_local).
+func (c *Client) doSCGI(body []byte) ([]byte, error) {
+	network, address := "tcp", c.url.Host
+	if c.url.Host == "" {
+		network, address = "unix", c.url.Path
+	}
+
+	conn, err := net.DialTimeout(network, address, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(30 * time.Second))
+
+	// Headers are a netstring of null-terminated key/value pairs, CONTENT_LENGTH must come first
+	headers := "CONTENT_LENGTH\x00" + strconv.Itoa(len(body)) + "\x00" +
+		"SCGI\x001\x00" +
+		"REQUEST_METHOD\x00POST\x00" +
+		"REQUEST_URI\x00/RPC2\x00"
+	req := strconv.Itoa(len(headers)) + ":" + headers + "," + string(body)
+
+	if _, err := conn.Write([]byte(req)); err != nil {
+		return nil, err
+	}
+
+	res, err := io.ReadAll(bufio.NewReader(conn))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSCGIResponse(res)
+}
+
+// parseSCGIResponse removes the CGI headers of the response.
+func parseSCGIResponse(res []byte) ([]byte, error) {
+	idx := bytes.Index(res, []byte("\r\n\r\n"))
+	sepLen := 4
+	if idx == -1 {
+		idx = bytes.Index(res, []byte("\n\n"))
+		sepLen = 2
+	}
+	if idx == -1 {
+		return nil, errors.New("rtorrent: invalid SCGI response")
+	}
+
+	for _, line := range strings.Split(string(res[:idx]), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "Status:") && !strings.Contains(line, "200") {
+			return nil, fmt.Errorf("rtorrent: request failed, %s", line)
+		}
+	}
+
+	return res[idx+sepLen:], nil
+}
+
+// GetVersion returns the version of rTorrent.
+func (c *Client) GetVersion() (string, error) {
+	res, err := c.Call("system.client_version")
+	if err != nil {
+		return "", err
+	}
+	return toString(res), nil
+}
+
+// GetTorrents returns all the torrents in the main view.
+func (c *Client) GetTorrents() ([]*Torrent, error) {
+	params := append([]interface{}{"", "main"}, torrentFields...)
+	res, err := c.Call("d.multicall2", params...)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, ok := res.([]interface{})
+	if !ok {
+		return nil, errors.New("rtorrent: unexpected d.multicall2 response")
+	}
+
+	ret := make([]*Torrent, 0, len(rows))
+	for _, row := range rows {
+		fields, ok := row.([]interface{})
+		if !ok || len(fields) < len(torrentFields) {
+			continue
+		}
+		ret = append(ret, &Torrent{
+			Hash:           toString(fields[0]),
+			Name:           toString(fields[1]),
+			SizeBytes:      toInt(fields[2]),
+			CompletedBytes: toInt(fields[3]),
+			DownRate:       toInt(fields[4]),
+			UpRate:         toInt(fields[5]),
+			PeersComplete:  toInt(fields[6]),
+			State:          toInt(fields[7]) == 1,
+			IsActive:       toInt(fields[8]) == 1,
+			Complete:       toInt(fields[9]) == 1,
+			Hashing:        toInt(fields[10]) != 0,
+			Directory:      toString(fields[11]),
+			BasePath:       toString(fields[12]),
+		})
+	}
+
+	return ret, nil
+}
+
+// LoadStart adds a magnet link or torrent URL and starts it.
+func (c *Client) LoadStart(uri string, directory string) error {
+	params := []interface{}{"", uri}
+	if directory != "" {
+		params = append(params, fmt.Sprintf("d.directory.set=\"%s\"", strings.ReplaceAll(directory, `"`, `\"`)))
+	}
+	_, err := c.Call("load.start", params...)
+	return err
+}
+
+// Erase removes the torrent from rTorrent, the files are not deleted.
+func (c *Client) Erase(hash string) error {
+	_, err := c.Call("d.erase", hash)
+	return err
+}
+
+// Stop stops the torrent, it is shown as paused or stopped in the torrent list.
+func (c *Client) Stop(hash string) error {
+	_, err := c.Call("d.stop", hash)
+	return err
+}
+
+// Start starts the torrent and resumes it if it was paused.
+func (c *Client) Start(hash string) error {
+	if _, err := c.Call("d.start", hash); err != nil {
+		return err
+	}
+	_, err := c.Call("d.resume", hash)
+	return err
+}
+
+// GetFiles returns the paths of the files of the torrent, relative to the base path.
+func (c *Client) GetFiles(hash string) ([]string, error) {
+	res, err := c.Call("f.multicall", hash, "", "f.path=")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, ok := res.([]interface{})
+	if !ok {
+		return nil, errors.New("rtorrent: unexpected f.multicall response")
+	}
+
+	ret := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if fields, ok := row.([]interface{}); ok && len(fields) > 0 {
+			ret = append(ret, toString(fields[0]))
+		}
+	}
+	return ret, nil
+}
+
+// DisableFiles sets the priority of the files to "off" so that they are not downloaded.
+func (c *Client) DisableFiles(hash string, indices []int) error {
+	for _, idx := range indices {
+		if _, err := c.Call("f.priority.set", fmt.Sprintf("%s:f%d", hash, idx), 0); err != nil {
+			return err
+		}
+	}
+	_, err := c.Call("d.update_priorities", hash)
+	return err
+}
+
+// Exists returns true if the torrent is in rTorrent.
+func (c *Client) Exists(hash string) bool {
+	_, err := c.Call("d.name", hash)
+	return err == nil
+}
+
+func toString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	default:
+		return ""
+	}
+}
+
+func toInt(v interface{}) int64 {
+	switch v := v.(type) {
+	case int64:
+		return v
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	case string:
+		i, _ := strconv.ParseInt(v, 10, 64)
+		return i
+	default:
+		return 0
+	}
+}
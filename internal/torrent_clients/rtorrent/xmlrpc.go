@@ -0,0 +1,237 @@
+package rtorrent
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Minimal XML-RPC codec, only supports the types used by rTorrent.
+
+// Fault is returned when rTorrent returns an XML-RPC fault.
+type Fault struct {
+	Code    int
+	Message string
+}
+
+func (f *Fault) Error() string {
+	return fmt.Sprintf("rtorrent: fault %d: %s", f.Code, f.Message)
+}
+
+// encodeRequest encodes a method call.
+// Supported parameter types are string, int, int64, bool and []interface{}.
+func encodeRequest(method string, params ...interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0"?><methodCall><methodName>`)
+	if err := xml.EscapeText(&buf, []byte(method)); err != nil {
+		return nil, err
+	}
+	buf.WriteString(`</methodName><params>`)
+	for _, p := range params {
+		buf.WriteString(`<param>`)
+		if err := encodeValue(&buf, p); err != nil {
+			return nil, err
+		}
+		buf.WriteString(`</param>`)
+	}
+	buf.WriteString(`</params></methodCall>`)
+	return buf.Bytes(), nil
+}
+
+func encodeValue(buf *bytes.Buffer, v interface{}) error {
+	buf.WriteString(`<value>`)
+	switch v := v.(type) {
+	case string:
+		buf.WriteString(`<string>`)
+		if err := xml.EscapeText(buf, []byte(v)); err != nil {
+			return err
+		}
+		buf.WriteString(`</string>`)
+	case int:
+		buf.WriteString(`<i8>` + strconv.Itoa(v) + `</i8>`)
+	case int64:
+		buf.WriteString(`<i8>` + strconv.FormatInt(v, 10) + `</i8>`)
+	case bool:
+		if v {
+			buf.WriteString(`<boolean>1</boolean>`)
+		} else {
+			buf.WriteString(`<boolean>0</boolean>`)
+		}
+	case []interface{}:
+		buf.WriteString(`<array><data>`)
+		for _, item := range v {
+			if err := encodeValue(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteString(`</data></array>`)
+	case []string:
+		buf.WriteString(`<array><data>`)
+		for _, item := range v {
+			if err := encodeValue(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteString(`</data></array>`)
+	default:
+		return fmt.Errorf("rtorrent: unsupported XML-RPC type %T", v)
+	}
+	buf.WriteString(`</value>`)
+	return nil
+}
+
+// decodeResponse decodes a method response.
+// Values are decoded as string, int64, bool, float64, []interface{} or map[string]interface{}.
+func decodeResponse(data []byte) (interface{}, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			if err == io.EOF {
+				return nil, errors.New("rtorrent: empty XML-RPC response")
+			}
+			return nil, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "fault":
+			v, err := decodeNextValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			fault := &Fault{}
+			if m, ok := v.(map[string]interface{}); ok {
+				if code, ok := m["faultCode"].(int64); ok {
+					fault.Code = int(code)
+				}
+				fault.Message, _ = m["faultString"].(string)
+			}
+			return nil, fault
+		case "param":
+			return decodeNextValue(dec)
+		}
+	}
+}
+
+// decodeNextValue decodes the next <value> element.
+func decodeNextValue(dec *xml.Decoder) (interface{}, error) {
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local == "value" {
+			return decodeValue(dec)
+		}
+	}
+}
+
+// decodeValue decodes the content of a <value> element, the start element has already been read.
+func decodeValue(dec *xml.Decoder) (interface{}, error) {
+	var text strings.Builder
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			// <value>text</value> is a string
+			return text.String(), nil
+		case xml.StartElement:
+			v, err := decodeTyped(dec, t.Name.Local)
+			if err != nil {
+				return nil, err
+			}
+			// Read until </value>
+			if err := dec.Skip(); err != nil {
+				return nil, err
+			}
+			return v, nil
+		}
+	}
+}
+
+func decodeTyped(dec *xml.Decoder, typ string) (interface{}, error) {
+	switch typ {
+	case "array":
+		ret := make([]interface{}, 0)
+		for {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				if t.Name.Local == "value" {
+					v, err := decodeValue(dec)
+					if err != nil {
+						return nil, err
+					}
+					ret = append(ret, v)
+				}
+			case xml.EndElement:
+				if t.Name.Local == "array" {
+					return ret, nil
+				}
+			}
+		}
+	case "struct":
+		ret := make(map[string]interface{})
+		var name string
+		for {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				switch t.Name.Local {
+				case "name":
+					var s string
+					if err := dec.DecodeElement(&s, &t); err != nil {
+						return nil, err
+					}
+					name = s
+				case "value":
+					v, err := decodeValue(dec)
+					if err != nil {
+						return nil, err
+					}
+					ret[name] = v
+				}
+			case xml.EndElement:
+				if t.Name.Local == "struct" {
+					return ret, nil
+				}
+			}
+		}
+	default:
+		var s string
+		start := xml.StartElement{Name: xml.Name{Local: typ}}
+		if err := dec.DecodeElement(&s, &start); err != nil {
+			return nil, err
+		}
+		s = strings.TrimSpace(s)
+		switch typ {
+		case "i4", "i8", "int":
+			return strconv.ParseInt(s, 10, 64)
+		case "boolean":
+			return s == "1", nil
+		case "double":
+			return strconv.ParseFloat(s, 64)
+		default:
+			return s, nil
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package rtorrent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeRequest(t *testing.T) {
+	body, err := encodeRequest("d.multicall2", "", "main", []string{"d.hash=", "d.name="}, 5, true)
+	require.NoError(t, err)
+
+	s := string(body)
+	require.True(t, strings.HasPrefix(s, "<?xml"))
+	require.Contains(t, s, "<methodName>d.multicall2</methodName>")
+	require.Contains(t, s, "<value><string>main</string></value>")
+	require.Contains(t, s, "<array><data><value><string>d.hash=</string></value><value><string>d.name=</string></value></data></array>")
+	require.Contains(t, s, "<i8>5</i8>")
+	require.Contains(t, s, "<boolean>1</boolean>")
+}
+
+func TestDecodeResponse(t *testing.T) {
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<methodResponse>
+<params>
+<param><value><array><data>
+<value><array><data>
+<value><string>ABCDEF</string></value>
+<value><string>Show &amp; Tell</string></value>
+<value><i8>1024</i8></value>
+<value><i4>1</i4></value>
+</data></array></value>
+</data></array></value></param>
+</params>
+</methodResponse>`
+
+	v, err := decodeResponse([]byte(data))
+	require.NoError(t, err)
+
+	rows, ok := v.([]interface{})
+	require.True(t, ok)
+	require.Len(t, rows, 1)
+
+	row := rows[0].([]interface{})
+	require.Equal(t, "ABCDEF", row[0])
+	require.Equal(t, "Show & Tell", row[1])
+	require.Equal(t, int64(1024), row[2])
+	require.Equal(t, int64(1), row[3])
+}
+
+func TestDecodeResponseFault(t *testing.T) {
+	data := `<?xml version="1.0"?>
+<methodResponse><fault><value><struct>
+<member><name>faultCode</name><value><i4>-501</i4></value></member>
+<member><name>faultString</name><value><string>Could not find info-hash.</string></value></member>
+</struct></value></fault></methodResponse>`
+
+	_, err := decodeResponse([]byte(data))
+	require.Error(t, err)
+
+	fault, ok := err.(*Fault)
+	require.True(t, ok)
+	require.Equal(t, -501, fault.Code)
+	require.Equal(t, "Could not find info-hash.", fault.Message)
+}
+
+func TestParseSCGIResponse(t *testing.T) {
+	body, err := parseSCGIResponse([]byte("Status: 200 OK\r\nContent-Type: text/xml\r\nContent-Length: 5\r\n\r\n<xml>"))
+	require.NoError(t, err)
+	require.Equal(t, "<xml>", string(body))
+
+	_, err = parseSCGIResponse([]byte("Status: 500 Internal Server Error\r\n\r\n"))
+	require.Error(t, err)
+
+	_, err = parseSCGIResponse([]byte("<xml>"))
+	require.Error(t, err)
+}
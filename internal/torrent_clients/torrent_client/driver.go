@@ -0,0 +1,58 @@
+package torrent_client
+
+import (
+	"errors"
+)
+
+var (
+	ErrNoTorrentClient          = errors.New("torrent client: No torrent client selected")
+	ErrTorrentClientUnavailable = errors.New("torrent client: Selected torrent client is not available")
+)
+
+// Driver is implemented by each supported torrent client.
+// Hashes are lowercase info hashes.
+type Driver interface {
+	// CheckStart returns true if the client is reachable, it may try to start the client.
+	CheckStart() bool
+	TorrentExists(hash string) bool
+	GetList() ([]*Torrent, error)
+	AddMagnets(magnets []string, dest string) error
+	// RemoveTorrents removes the torrents and their files.
+	RemoveTorrents(hashes []string) error
+	PauseTorrents(hashes []string) error
+	ResumeTorrents(hashes []string) error
+	// DeselectFiles stops the download of the files at the given indices.
+	DeselectFiles(hash string, indices []int) error
+	// GetFiles returns the paths of the files in the torrent, in the order of their indices.
+	// It returns an empty slice if the metadata is not available yet.
+	GetFiles(hash string) ([]string, error)
+}
+
+// activeCounter is implemented by the drivers that count the active torrents without listing all of them.
+type activeCounter interface {
+	GetActiveCount(ret *ActiveCount) error
+}
+
+// noneDriver is used when no torrent client is selected.
+type noneDriver struct{}
+
+func (noneDriver) CheckStart() bool                  { return true }
+func (noneDriver) TorrentExists(string) bool         { return false }
+func (noneDriver) GetList() ([]*Torrent, error)      { return nil, ErrNoTorrentClient }
+func (noneDriver) AddMagnets([]string, string) error { return ErrNoTorrentClient }
+func (noneDriver) RemoveTorrents([]string) error     { return nil }
+func (noneDriver) PauseTorrents([]string) error      { return nil }
+func (noneDriver) ResumeTorrents([]string) error     { return nil }
+func (noneDriver) DeselectFiles(string, []int) error { return nil }
+func (noneDriver) GetFiles(string) ([]string, error) { return nil, ErrNoTorrentClient }
+
+// unavailableDriver is used when the selected torrent client could not be created.
+// Unlike noneDriver, it is never ready.
+type unavailableDriver struct {
+	noneDriver
+}
+
+func (unavailableDriver) CheckStart() bool                  { return false }
+func (unavailableDriver) GetList() ([]*Torrent, error)      { return nil, ErrTorrentClientUnavailable }
+func (unavailableDriver) AddMagnets([]string, string) error { return ErrTorrentClientUnavailable }
+func (unavailableDriver) GetFiles(string) ([]string, error) { return nil, ErrTorrentClientUnavailable }
@@ -0,0 +1,307 @@
+package torrent_client
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"seanime/internal/torrent_clients/aria2"
+	"seanime/internal/util"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+type aria2Driver struct {
+	client *aria2.Client
+	logger *zerolog.Logger
+}
+
+// findDownloads returns the downloads of the torrent, including the metadata download of a magnet link.
+func (d *aria2Driver) findDownloads(hash string, withFiles bool) ([]*aria2.Status, error) {
+	downloads, err := d.client.GetAll(withFiles)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*aria2.Status, 0)
+	for _, s := range downloads {
+		if strings.EqualFold(s.InfoHash, hash) {
+			ret = append(ret, s)
+		}
+	}
+	return ret, nil
+}
+
+// findDownload returns the download of the torrent's files, or the metadata download if the files are not known yet.
+func (d *aria2Driver) findDownload(hash string, withFiles bool) (*aria2.Status, error) {
+	downloads, err := d.findDownloads(hash, withFiles)
+	if err != nil {
+		return nil, err
+	}
+	var ret *aria2.Status
+	for _, s := range downloads {
+		if ret == nil || (ret.IsMetadata() && !s.IsMetadata()) {
+			ret = s
+		}
+	}
+	if ret == nil {
+		return nil, errors.New("torrent client: Torrent not found (aria2)")
+	}
+	return ret, nil
+}
+
+func (d *aria2Driver) CheckStart() bool {
+	_, err := d.client.GetVersion()
+	return err == nil
+}
+
+func (d *aria2Driver) TorrentExists(hash string) bool {
+	downloads, err := d.findDownloads(hash, false)
+	return err == nil && len(downloads) > 0
+}
+
+func (d *aria2Driver) GetList() ([]*Torrent, error) {
+	downloads, err := d.client.GetAll(true)
+	if err != nil {
+		d.logger.Err(err).Msg("torrent client: Error while getting torrent list (aria2)")
+		return nil, err
+	}
+	ret := make([]*Torrent, 0, len(downloads))
+	for _, s := range downloads {
+		// Ignore regular downloads and the metadata downloads of magnet links
+		if s.InfoHash == "" || s.IsMetadata() {
+			continue
+		}
+		ret = append(ret, fromAria2Status(s))
+	}
+	return ret, nil
+}
+
+func (d *aria2Driver) AddMagnets(magnets []string, dest string) error {
+	for _, magnet := range magnets {
+		if _, err := d.client.AddUri(magnet, dest); err != nil {
+			d.logger.Err(err).Msg("torrent client: Error while adding magnets (aria2)")
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveTorrents removes the downloads and deletes their files.
+// aria2 does not delete the files, so they are only deleted if they are accessible from this machine.
+func (d *aria2Driver) RemoveTorrents(hashes []string) error {
+	for _, hash := range hashes {
+		downloads, err := d.findDownloads(hash, true)
+		if err != nil {
+			return err
+		}
+		for _, s := range downloads {
+			switch s.Status {
+			case aria2.StatusActive, aria2.StatusWaiting, aria2.StatusPaused:
+				if err := d.client.Remove(s.Gid); err != nil {
+					return err
+				}
+			}
+			_ = d.client.RemoveDownloadResult(s.Gid)
+
+			if s.IsMetadata() {
+				continue
+			}
+			for _, p := range getAria2DataPaths(s) {
+				if err := removeLocalData(p); err != nil {
+					d.logger.Warn().Err(err).Str("path", p).Msg("torrent client: Could not delete torrent files (aria2)")
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (d *aria2Driver) PauseTorrents(hashes []string) error {
+	for _, hash := range hashes {
+		s, err := d.findDownload(hash, false)
+		if err != nil {
+			return err
+		}
+		if s.Status != aria2.StatusActive && s.Status != aria2.StatusWaiting {
+			continue
+		}
+		if err := d.client.Pause(s.Gid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *aria2Driver) ResumeTorrents(hashes []string) error {
+	for _, hash := range hashes {
+		s, err := d.findDownload(hash, false)
+		if err != nil {
+			return err
+		}
+		if s.Status != aria2.StatusPaused {
+			continue
+		}
+		if err := d.client.Unpause(s.Gid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeselectFiles sets the "select-file" option to every file that is not deselected.
+func (d *aria2Driver) DeselectFiles(hash string, indices []int) error {
+	s, err := d.findDownload(hash, true)
+	if err != nil {
+		return err
+	}
+	if s.IsMetadata() {
+		return errors.New("torrent client: Metadata not available yet (aria2)")
+	}
+
+	deselected := make(map[int]struct{}, len(indices))
+	for _, idx := range indices {
+		deselected[idx] = struct{}{}
+	}
+
+	selected := make([]string, 0, len(s.Files))
+	for i, f := range s.Files {
+		if _, ok := deselected[i]; ok {
+			continue
+		}
+		// aria2 indices are 1-based
+		if f.Index != "" {
+			selected = append(selected, f.Index)
+		} else {
+			selected = append(selected, strconv.Itoa(i+1))
+		}
+	}
+	if len(selected) == 0 {
+		return errors.New("torrent client: Cannot deselect every file (aria2)")
+	}
+
+	return d.client.ChangeOption(s.Gid, map[string]string{
+		"select-file": strings.Join(selected, ","),
+	})
+}
+
+func (d *aria2Driver) GetFiles(hash string) ([]string, error) {
+	ret := make([]string, 0)
+	s, err := d.findDownload(hash, true)
+	if err != nil {
+		// The download might not be listed yet
+		return ret, nil
+	}
+	if s.IsMetadata() {
+		return ret, nil
+	}
+	for _, f := range s.Files {
+		ret = append(ret, relativeAria2Path(s.Dir, f.Path))
+	}
+	return ret, nil
+}
+
+// relativeAria2Path returns the path of the file relative to the download directory,
+// aria2 returns absolute paths.
+func relativeAria2Path(dir string, path string) string {
+	if dir == "" {
+		return path
+	}
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// getAria2DataPaths returns the top-level paths of the torrent's files in the download directory.
+func getAria2DataPaths(s *aria2.Status) []string {
+	if s.Dir == "" || !filepath.IsAbs(s.Dir) {
+		return nil
+	}
+	seen := make(map[string]struct{})
+	ret := make([]string, 0)
+	for _, f := range s.Files {
+		rel := relativeAria2Path(s.Dir, f.Path)
+		if rel == "" || rel == f.Path {
+			continue
+		}
+		top := strings.Split(rel, "/")[0]
+		if top == "" || top == "." || top == ".." {
+			continue
+		}
+		if _, ok := seen[top]; ok {
+			continue
+		}
+		seen[top] = struct{}{}
+		ret = append(ret, filepath.Join(s.Dir, top))
+	}
+	return ret
+}
+
+// removeLocalData deletes the files of a torrent if they exist on this machine.
+func removeLocalData(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return os.RemoveAll(path)
+}
+
+func fromAria2Status(s *aria2.Status) *Torrent {
+	totalLength, _ := strconv.ParseInt(s.TotalLength, 10, 64)
+	completedLength, _ := strconv.ParseInt(s.CompletedLength, 10, 64)
+	downloadSpeed, _ := strconv.Atoi(s.DownloadSpeed)
+	uploadSpeed, _ := strconv.Atoi(s.UploadSpeed)
+	numSeeders, _ := strconv.Atoi(s.NumSeeders)
+
+	progress := 0.0
+	if totalLength > 0 {
+		progress = float64(completedLength) / float64(totalLength)
+	}
+
+	eta := 8640000 // Same as qBittorrent's "infinity"
+	if downloadSpeed > 0 {
+		eta = int((totalLength - completedLength) / int64(downloadSpeed))
+	}
+
+	contentPath := s.Dir
+	if paths := getAria2DataPaths(s); len(paths) == 1 {
+		contentPath = paths[0]
+	}
+
+	return &Torrent{
+		Name:        s.GetName(),
+		Hash:        strings.ToLower(s.InfoHash),
+		Seeds:       numSeeders,
+		UpSpeed:     util.ToHumanReadableSpeed(uploadSpeed),
+		DownSpeed:   util.ToHumanReadableSpeed(downloadSpeed),
+		Progress:    progress,
+		Size:        util.Bytes(uint64(totalLength)),
+		Eta:         util.FormatETA(eta),
+		ContentPath: contentPath,
+		Status:      fromAria2TorrentStatus(s.Status, progress),
+	}
+}
+
+// fromAria2TorrentStatus returns a normalized status for the torrent.
+func fromAria2TorrentStatus(st string, progress float64) TorrentStatus {
+	switch st {
+	case aria2.StatusActive:
+		// aria2 keeps seeding completed torrents while they are active
+		if progress >= 1 {
+			return TorrentStatusSeeding
+		}
+		return TorrentStatusDownloading
+	case aria2.StatusWaiting:
+		return TorrentStatusDownloading
+	case aria2.StatusPaused:
+		return TorrentStatusPaused
+	case aria2.StatusComplete:
+		return TorrentStatusStopped
+	default:
+		return TorrentStatusOther
+	}
+}
@@ -0,0 +1,142 @@
+package torrent_client
+
+import (
+	"errors"
+	"seanime/internal/torrent_clients/deluge"
+	"seanime/internal/util"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+type delugeDriver struct {
+	client *deluge.Client
+	logger *zerolog.Logger
+}
+
+func (d *delugeDriver) CheckStart() bool {
+	return d.client.Ping() == nil
+}
+
+func (d *delugeDriver) TorrentExists(hash string) bool {
+	torrents, err := d.client.GetTorrentsStatus([]string{hash}, false)
+	return err == nil && len(torrents) > 0
+}
+
+func (d *delugeDriver) GetList() ([]*Torrent, error) {
+	torrents, err := d.client.GetTorrentsStatus(nil, false)
+	if err != nil {
+		d.logger.Err(err).Msg("torrent client: Error while getting torrent list (Deluge)")
+		return nil, err
+	}
+	ret := make([]*Torrent, 0, len(torrents))
+	for hash, t := range torrents {
+		if t.Hash == "" {
+			t.Hash = hash
+		}
+		ret = append(ret, fromDelugeTorrent(t))
+	}
+	return ret, nil
+}
+
+func (d *delugeDriver) AddMagnets(magnets []string, dest string) error {
+	for _, magnet := range magnets {
+		if _, err := d.client.AddMagnet(magnet, dest); err != nil {
+			d.logger.Err(err).Msg("torrent client: Error while adding magnets (Deluge)")
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *delugeDriver) RemoveTorrents(hashes []string) error {
+	for _, hash := range hashes {
+		if err := d.client.RemoveTorrent(hash, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *delugeDriver) PauseTorrents(hashes []string) error {
+	return d.client.PauseTorrents(hashes)
+}
+
+func (d *delugeDriver) ResumeTorrents(hashes []string) error {
+	return d.client.ResumeTorrents(hashes)
+}
+
+func (d *delugeDriver) DeselectFiles(hash string, indices []int) error {
+	torrents, err := d.client.GetTorrentsStatus([]string{hash}, true)
+	if err != nil {
+		return err
+	}
+	t, ok := torrents[hash]
+	if !ok {
+		return errors.New("torrent client: Torrent not found (Deluge)")
+	}
+
+	// Deluge expects the priority of every file
+	priorities := make([]int, len(t.Files))
+	for i := range priorities {
+		priorities[i] = 1
+		if i < len(t.FilePriorities) {
+			priorities[i] = t.FilePriorities[i]
+		}
+	}
+	for _, idx := range indices {
+		if idx >= 0 && idx < len(priorities) {
+			priorities[idx] = 0
+		}
+	}
+
+	return d.client.SetFilePriorities(hash, priorities)
+}
+
+func (d *delugeDriver) GetFiles(hash string) ([]string, error) {
+	torrents, err := d.client.GetTorrentsStatus([]string{hash}, true)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]string, 0)
+	t, ok := torrents[hash]
+	if !ok {
+		return ret, nil
+	}
+	for _, f := range t.Files {
+		ret = append(ret, f.Path)
+	}
+	return ret, nil
+}
+
+func fromDelugeTorrent(t *deluge.TorrentStatus) *Torrent {
+	return &Torrent{
+		Name:        t.Name,
+		Hash:        strings.ToLower(t.Hash),
+		Seeds:       t.NumSeeds,
+		UpSpeed:     util.ToHumanReadableSpeed(int(t.UploadPayloadRate)),
+		DownSpeed:   util.ToHumanReadableSpeed(int(t.DownloadPayloadRate)),
+		Progress:    t.Progress / 100,
+		Size:        util.Bytes(uint64(t.TotalSize)),
+		Eta:         util.FormatETA(int(t.Eta)),
+		ContentPath: t.SavePath,
+		Status:      fromDelugeTorrentStatus(t.State, t.IsFinished),
+	}
+}
+
+// fromDelugeTorrentStatus returns a normalized status for the torrent.
+func fromDelugeTorrentStatus(state string, isFinished bool) TorrentStatus {
+	switch state {
+	case deluge.StateSeeding:
+		return TorrentStatusSeeding
+	case deluge.StatePaused:
+		if isFinished {
+			return TorrentStatusStopped
+		}
+		return TorrentStatusPaused
+	case deluge.StateDownloading, deluge.StateChecking, deluge.StateQueued, deluge.StateAllocating:
+		return TorrentStatusDownloading
+	default:
+		return TorrentStatusOther
+	}
+}
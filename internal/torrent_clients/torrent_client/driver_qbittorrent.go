@@ -0,0 +1,95 @@
+package torrent_client
+
+import (
+	"seanime/internal/torrent_clients/qbittorrent"
+	"seanime/internal/torrent_clients/qbittorrent/model"
+	"strconv"
+
+	"github.com/rs/zerolog"
+)
+
+type qbittorrentDriver struct {
+	client *qbittorrent.Client
+	logger *zerolog.Logger
+}
+
+func (d *qbittorrentDriver) CheckStart() bool {
+	return d.client.CheckStart()
+}
+
+func (d *qbittorrentDriver) TorrentExists(hash string) bool {
+	p, err := d.client.Torrent.GetProperties(hash)
+	return err == nil && p != nil
+}
+
+func (d *qbittorrentDriver) GetList() ([]*Torrent, error) {
+	torrents, err := d.client.Torrent.GetList(&qbittorrent_model.GetTorrentListOptions{Filter: "all"})
+	if err != nil {
+		d.logger.Err(err).Msg("torrent client: Error while getting torrent list (qBittorrent)")
+		return nil, err
+	}
+	return fromQbitTorrents(torrents), nil
+}
+
+// GetActiveCount only counts the downloading and seeding torrents, using qBittorrent's filters.
+func (d *qbittorrentDriver) GetActiveCount(ret *ActiveCount) error {
+	torrents, err := d.client.Torrent.GetList(&qbittorrent_model.GetTorrentListOptions{Filter: "downloading"})
+	if err != nil {
+		return err
+	}
+	torrents2, err := d.client.Torrent.GetList(&qbittorrent_model.GetTorrentListOptions{Filter: "seeding"})
+	if err != nil {
+		return err
+	}
+	torrents = append(torrents, torrents2...)
+	for _, t := range torrents {
+		switch fromQbitTorrentStatus(t.State) {
+		case TorrentStatusDownloading:
+			ret.Downloading++
+		case TorrentStatusSeeding:
+			ret.Seeding++
+		case TorrentStatusPaused:
+			ret.Paused++
+		}
+	}
+	return nil
+}
+
+func (d *qbittorrentDriver) AddMagnets(magnets []string, dest string) error {
+	return d.client.Torrent.AddURLs(magnets, &qbittorrent_model.AddTorrentsOptions{
+		Savepath: dest,
+		Tags:     d.client.Tags,
+	})
+}
+
+func (d *qbittorrentDriver) RemoveTorrents(hashes []string) error {
+	return d.client.Torrent.DeleteTorrents(hashes, true)
+}
+
+func (d *qbittorrentDriver) PauseTorrents(hashes []string) error {
+	return d.client.Torrent.StopTorrents(hashes)
+}
+
+func (d *qbittorrentDriver) ResumeTorrents(hashes []string) error {
+	return d.client.Torrent.ResumeTorrents(hashes)
+}
+
+func (d *qbittorrentDriver) DeselectFiles(hash string, indices []int) error {
+	strIndices := make([]string, len(indices), len(indices))
+	for i, v := range indices {
+		strIndices[i] = strconv.Itoa(v)
+	}
+	return d.client.Torrent.SetFilePriorities(hash, strIndices, 0)
+}
+
+func (d *qbittorrentDriver) GetFiles(hash string) ([]string, error) {
+	qbitFiles, err := d.client.Torrent.GetContents(hash)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]string, 0, len(qbitFiles))
+	for _, f := range qbitFiles {
+		ret = append(ret, f.Name)
+	}
+	return ret, nil
+}
@@ -0,0 +1,158 @@
+package torrent_client
+
+import (
+	"path/filepath"
+	"seanime/internal/torrent_clients/rtorrent"
+	"seanime/internal/util"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+type rtorrentDriver struct {
+	client *rtorrent.Client
+	logger *zerolog.Logger
+}
+
+// rTorrent uses uppercase hashes
+func toRtorrentHash(hash string) string {
+	return strings.ToUpper(hash)
+}
+
+func (d *rtorrentDriver) CheckStart() bool {
+	_, err := d.client.GetVersion()
+	return err == nil
+}
+
+func (d *rtorrentDriver) TorrentExists(hash string) bool {
+	return d.client.Exists(toRtorrentHash(hash))
+}
+
+func (d *rtorrentDriver) GetList() ([]*Torrent, error) {
+	torrents, err := d.client.GetTorrents()
+	if err != nil {
+		d.logger.Err(err).Msg("torrent client: Error while getting torrent list (rTorrent)")
+		return nil, err
+	}
+	ret := make([]*Torrent, 0, len(torrents))
+	for _, t := range torrents {
+		ret = append(ret, fromRtorrentTorrent(t))
+	}
+	return ret, nil
+}
+
+func (d *rtorrentDriver) AddMagnets(magnets []string, dest string) error {
+	for _, magnet := range magnets {
+		if err := d.client.LoadStart(magnet, dest); err != nil {
+			d.logger.Err(err).Msg("torrent client: Error while adding magnets (rTorrent)")
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveTorrents erases the torrents and deletes their files.
+// rTorrent does not delete the files, so they are only deleted if they are accessible from this machine.
+func (d *rtorrentDriver) RemoveTorrents(hashes []string) error {
+	torrents, err := d.client.GetTorrents()
+	if err != nil {
+		return err
+	}
+
+	for _, hash := range hashes {
+		basePath := ""
+		for _, t := range torrents {
+			if strings.EqualFold(t.Hash, hash) {
+				basePath = t.BasePath
+				break
+			}
+		}
+
+		if err := d.client.Erase(toRtorrentHash(hash)); err != nil {
+			return err
+		}
+
+		if basePath != "" && filepath.IsAbs(basePath) && filepath.Dir(basePath) != basePath {
+			if err := removeLocalData(basePath); err != nil {
+				d.logger.Warn().Err(err).Str("path", basePath).Msg("torrent client: Could not delete torrent files (rTorrent)")
+			}
+		}
+	}
+	return nil
+}
+
+func (d *rtorrentDriver) PauseTorrents(hashes []string) error {
+	for _, hash := range hashes {
+		if err := d.client.Stop(toRtorrentHash(hash)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *rtorrentDriver) ResumeTorrents(hashes []string) error {
+	for _, hash := range hashes {
+		if err := d.client.Start(toRtorrentHash(hash)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *rtorrentDriver) DeselectFiles(hash string, indices []int) error {
+	return d.client.DisableFiles(toRtorrentHash(hash), indices)
+}
+
+func (d *rtorrentDriver) GetFiles(hash string) ([]string, error) {
+	files, err := d.client.GetFiles(toRtorrentHash(hash))
+	if err != nil {
+		return nil, err
+	}
+	// Magnet links are added as a "<hash>.meta" file until the metadata is downloaded
+	if len(files) == 1 && strings.HasSuffix(files[0], ".meta") {
+		return make([]string, 0), nil
+	}
+	return files, nil
+}
+
+func fromRtorrentTorrent(t *rtorrent.Torrent) *Torrent {
+	progress := 0.0
+	if t.SizeBytes > 0 {
+		progress = float64(t.CompletedBytes) / float64(t.SizeBytes)
+	}
+
+	eta := 8640000 // Same as qBittorrent's "infinity"
+	if t.DownRate > 0 {
+		eta = int((t.SizeBytes - t.CompletedBytes) / t.DownRate)
+	}
+
+	return &Torrent{
+		Name:        t.Name,
+		Hash:        strings.ToLower(t.Hash),
+		Seeds:       int(t.PeersComplete),
+		UpSpeed:     util.ToHumanReadableSpeed(int(t.UpRate)),
+		DownSpeed:   util.ToHumanReadableSpeed(int(t.DownRate)),
+		Progress:    progress,
+		Size:        util.Bytes(uint64(t.SizeBytes)),
+		Eta:         util.FormatETA(eta),
+		ContentPath: t.BasePath,
+		Status:      fromRtorrentTorrentStatus(t),
+	}
+}
+
+// fromRtorrentTorrentStatus returns a normalized status for the torrent.
+func fromRtorrentTorrentStatus(t *rtorrent.Torrent) TorrentStatus {
+	switch {
+	case t.Hashing:
+		return TorrentStatusDownloading
+	case !t.State || !t.IsActive:
+		if t.Complete {
+			return TorrentStatusStopped
+		}
+		return TorrentStatusPaused
+	case t.Complete:
+		return TorrentStatusSeeding
+	default:
+		return TorrentStatusDownloading
+	}
+}
@@ -0,0 +1,97 @@
+package torrent_client
+
+import (
+	"seanime/internal/torrent_clients/aria2"
+	"seanime/internal/torrent_clients/rtorrent"
+	"seanime/internal/util"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromAria2Status(t *testing.T) {
+	s := &aria2.Status{
+		Gid:             "2089b05ecca3d829",
+		Status:          aria2.StatusActive,
+		TotalLength:     "1000",
+		CompletedLength: "250",
+		DownloadSpeed:   "75",
+		UploadSpeed:     "0",
+		NumSeeders:      "4",
+		InfoHash:        "ABCDEF0123",
+		Dir:             "/downloads",
+		Files: []*aria2.File{
+			{Index: "1", Path: "/downloads/Show/Show - 01.mkv"},
+			{Index: "2", Path: "/downloads/Show/Show - 02.mkv"},
+		},
+	}
+
+	torrent := fromAria2Status(s)
+	require.Equal(t, "abcdef0123", torrent.Hash)
+	require.Equal(t, 0.25, torrent.Progress)
+	require.Equal(t, 4, torrent.Seeds)
+	require.Equal(t, TorrentStatusDownloading, torrent.Status)
+	require.Equal(t, "/downloads/Show", torrent.ContentPath)
+
+	require.Equal(t, "Show/Show - 01.mkv", relativeAria2Path(s.Dir, s.Files[0].Path))
+
+	s.CompletedLength = "1000"
+	require.Equal(t, TorrentStatusSeeding, fromAria2Status(s).Status)
+
+	s.Status = aria2.StatusComplete
+	require.Equal(t, TorrentStatusStopped, fromAria2Status(s).Status)
+}
+
+func TestFromRtorrentTorrentStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		torrent  *rtorrent.Torrent
+		expected TorrentStatus
+	}{
+		{
+			name:     "downloading",
+			torrent:  &rtorrent.Torrent{State: true, IsActive: true},
+			expected: TorrentStatusDownloading,
+		},
+		{
+			name:     "seeding",
+			torrent:  &rtorrent.Torrent{State: true, IsActive: true, Complete: true},
+			expected: TorrentStatusSeeding,
+		},
+		{
+			name:     "paused",
+			torrent:  &rtorrent.Torrent{State: true, IsActive: false},
+			expected: TorrentStatusPaused,
+		},
+		{
+			name:     "stopped",
+			torrent:  &rtorrent.Torrent{State: false, Complete: true},
+			expected: TorrentStatusStopped,
+		},
+		{
+			name:     "hashing",
+			torrent:  &rtorrent.Torrent{Hashing: true, Complete: true},
+			expected: TorrentStatusDownloading,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, fromRtorrentTorrentStatus(tt.torrent))
+		})
+	}
+}
+
+func TestNewDriver(t *testing.T) {
+	logger := util.NewLogger()
+
+	// No torrent client selected
+	driver := newDriver(&NewRepositoryOptions{Logger: logger, Provider: NoneClient})
+	require.True(t, driver.CheckStart())
+
+	// The selected torrent client could not be created
+	driver = newDriver(&NewRepositoryOptions{Logger: logger, Provider: DelugeClient})
+	require.False(t, driver.CheckStart())
+	_, err := driver.GetList()
+	require.ErrorIs(t, err, ErrTorrentClientUnavailable)
+}
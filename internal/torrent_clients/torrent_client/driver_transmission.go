@@ -0,0 +1,110 @@
+package torrent_client
+
+import (
+	"context"
+	"errors"
+	"seanime/internal/torrent_clients/transmission"
+
+	"github.com/hekmon/transmissionrpc/v3"
+	"github.com/rs/zerolog"
+)
+
+type transmissionDriver struct {
+	transmission *transmission.Transmission
+	logger       *zerolog.Logger
+}
+
+func (d *transmissionDriver) CheckStart() bool {
+	return d.transmission.CheckStart()
+}
+
+func (d *transmissionDriver) TorrentExists(hash string) bool {
+	torrents, err := d.transmission.Client.TorrentGetAllForHashes(context.Background(), []string{hash})
+	return err == nil && len(torrents) > 0
+}
+
+func (d *transmissionDriver) GetList() ([]*Torrent, error) {
+	torrents, err := d.transmission.Client.TorrentGetAll(context.Background())
+	if err != nil {
+		d.logger.Err(err).Msg("torrent client: Error while getting torrent list (Transmission)")
+		return nil, err
+	}
+	return fromTransmissionTorrents(torrents), nil
+}
+
+func (d *transmissionDriver) AddMagnets(magnets []string, dest string) error {
+	for _, magnet := range magnets {
+		_, err := d.transmission.Client.TorrentAdd(context.Background(), transmissionrpc.TorrentAddPayload{
+			Filename:    &magnet,
+			DownloadDir: &dest,
+		})
+		if err != nil {
+			d.logger.Err(err).Msg("torrent client: Error while adding magnets (Transmission)")
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *transmissionDriver) RemoveTorrents(hashes []string) error {
+	torrents, err := d.transmission.Client.TorrentGetAllForHashes(context.Background(), hashes)
+	if err != nil {
+		d.logger.Err(err).Msg("torrent client: Error while fetching torrents (Transmission)")
+		return err
+	}
+	ids := make([]int64, len(torrents))
+	for i, t := range torrents {
+		ids[i] = *t.ID
+	}
+	err = d.transmission.Client.TorrentRemove(context.Background(), transmissionrpc.TorrentRemovePayload{
+		IDs:             ids,
+		DeleteLocalData: true,
+	})
+	if err != nil {
+		d.logger.Err(err).Msg("torrent client: Error while removing torrents (Transmission)")
+		return err
+	}
+	return nil
+}
+
+func (d *transmissionDriver) PauseTorrents(hashes []string) error {
+	return d.transmission.Client.TorrentStopHashes(context.Background(), hashes)
+}
+
+func (d *transmissionDriver) ResumeTorrents(hashes []string) error {
+	return d.transmission.Client.TorrentStartHashes(context.Background(), hashes)
+}
+
+func (d *transmissionDriver) DeselectFiles(hash string, indices []int) error {
+	torrents, err := d.transmission.Client.TorrentGetAllForHashes(context.Background(), []string{hash})
+	if err != nil {
+		d.logger.Err(err).Msg("torrent client: Error while deselecting files (Transmission)")
+		return err
+	}
+	if len(torrents) == 0 || torrents[0].ID == nil {
+		return errors.New("torrent client: Torrent not found (Transmission)")
+	}
+	id := *torrents[0].ID
+	ind := make([]int64, len(indices), len(indices))
+	for i, v := range indices {
+		ind[i] = int64(v)
+	}
+	return d.transmission.Client.TorrentSet(context.Background(), transmissionrpc.TorrentSetPayload{
+		FilesUnwanted: ind,
+		IDs:           []int64{id},
+	})
+}
+
+func (d *transmissionDriver) GetFiles(hash string) ([]string, error) {
+	torrents, err := d.transmission.Client.TorrentGetAllForHashes(context.Background(), []string{hash})
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]string, 0)
+	if len(torrents) > 0 {
+		for _, f := range torrents[0].Files {
+			ret = append(ret, f.Name)
+		}
+	}
+	return ret, nil
+}
@@ -1 +1,38 @@
 package torrent_client
+
+import (
+	"seanime/internal/hook_resolver"
+)
+
+// TorrentClientAddTorrentsRequestedEvent is triggered when torrents are about to be added to the torrent client.
+// The magnets and the destination can be modified.
+// Prevent default to veto the addition, an error will be returned to the caller.
+type TorrentClientAddTorrentsRequestedEvent struct {
+	hook_resolver.Event
+	Magnets     []string `json:"magnets"`
+	Destination string   `json:"destination"`
+}
+
+// TorrentClientTorrentsAddedEvent is triggered after the torrents have been added to the torrent client.
+type TorrentClientTorrentsAddedEvent struct {
+	hook_resolver.Event
+	Magnets     []string `json:"magnets"`
+	Destination string   `json:"destination"`
+}
+
+// TorrentClientActionRequestedEvent is triggered when torrents are about to be removed, paused or resumed.
+// The action is one of "remove", "pause" or "resume".
+// The hashes can be modified.
+// Prevent default to veto the action, an error will be returned to the caller.
+type TorrentClientActionRequestedEvent struct {
+	hook_resolver.Event
+	Action string   `json:"action"`
+	Hashes []string `json:"hashes"`
+}
+
+// TorrentClientActionEvent is triggered after torrents have been removed, paused or resumed.
+type TorrentClientActionEvent struct {
+	hook_resolver.Event
+	Action string   `json:"action"`
+	Hashes []string `json:"hashes"`
+}
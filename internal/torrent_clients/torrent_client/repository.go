@@ -3,29 +3,43 @@ package torrent_client
 import (
 	"context"
 	"errors"
-	"github.com/hekmon/transmissionrpc/v3"
 	"github.com/rs/zerolog"
 	"seanime/internal/api/metadata"
 	"seanime/internal/events"
+	"seanime/internal/hook"
+	"seanime/internal/torrent_clients/aria2"
+	"seanime/internal/torrent_clients/deluge"
 	"seanime/internal/torrent_clients/qbittorrent"
-	"seanime/internal/torrent_clients/qbittorrent/model"
+	"seanime/internal/torrent_clients/rtorrent"
 	"seanime/internal/torrent_clients/transmission"
 	"seanime/internal/torrents/torrent"
-	"strconv"
 	"time"
 )
 
 const (
 	QbittorrentClient  = "qbittorrent"
 	TransmissionClient = "transmission"
+	DelugeClient       = "deluge"
+	RTorrentClient     = "rtorrent"
+	Aria2Client        = "aria2"
 	NoneClient         = "none"
 )
 
+// Actions passed to the TorrentClientActionRequestedEvent and TorrentClientActionEvent hook events.
+const (
+	ActionRemove = "remove"
+	ActionPause  = "pause"
+	ActionResume = "resume"
+)
+
+var ErrPreventedByHook = errors.New("torrent client: Action prevented by hook")
+
 type (
 	Repository struct {
 		logger                      *zerolog.Logger
 		qBittorrentClient           *qbittorrent.Client
 		transmission                *transmission.Transmission
+		driver                      Driver
 		torrentRepository           *torrent.Repository
 		provider                    string
 		metadataProvider            metadata.Provider
@@ -37,6 +51,9 @@ type (
 		Logger            *zerolog.Logger
 		QbittorrentClient *qbittorrent.Client
 		Transmission      *transmission.Transmission
+		Deluge            *deluge.Client
+		RTorrent          *rtorrent.Client
+		Aria2             *aria2.Client
 		TorrentRepository *torrent.Repository
 		Provider          string
 		MetadataProvider  metadata.Provider
@@ -57,6 +74,7 @@ func NewRepository(opts *NewRepositoryOptions) *Repository {
 		logger:             opts.Logger,
 		qBittorrentClient:  opts.QbittorrentClient,
 		transmission:       opts.Transmission,
+		driver:             newDriver(opts),
 		torrentRepository:  opts.TorrentRepository,
 		provider:           opts.Provider,
 		metadataProvider:   opts.MetadataProvider,
@@ -64,6 +82,35 @@ func NewRepository(opts *NewRepositoryOptions) *Repository {
 	}
 }
 
+// newDriver returns the driver of the selected torrent client.
+func newDriver(opts *NewRepositoryOptions) Driver {
+	switch opts.Provider {
+	case QbittorrentClient:
+		if opts.QbittorrentClient != nil {
+			return &qbittorrentDriver{client: opts.QbittorrentClient, logger: opts.Logger}
+		}
+	case TransmissionClient:
+		if opts.Transmission != nil {
+			return &transmissionDriver{transmission: opts.Transmission, logger: opts.Logger}
+		}
+	case DelugeClient:
+		if opts.Deluge != nil {
+			return &delugeDriver{client: opts.Deluge, logger: opts.Logger}
+		}
+	case RTorrentClient:
+		if opts.RTorrent != nil {
+			return &rtorrentDriver{client: opts.RTorrent, logger: opts.Logger}
+		}
+	case Aria2Client:
+		if opts.Aria2 != nil {
+			return &aria2Driver{client: opts.Aria2, logger: opts.Logger}
+		}
+	case NoneClient:
+		return noneDriver{}
+	}
+	return unavailableDriver{}
+}
+
 func (r *Repository) Shutdown() {
 	if r.activeTorrentCountCtxCancel != nil {
 		r.activeTorrentCountCtxCancel()
@@ -103,51 +150,24 @@ func (r *Repository) GetProvider() string {
 	return r.provider
 }
 
-func (r *Repository) Start() bool {
-	switch r.provider {
-	case QbittorrentClient:
-		return r.qBittorrentClient.CheckStart()
-	case TransmissionClient:
-		return r.transmission.CheckStart()
-	case NoneClient:
-		return true
-	default:
-		return false
+// GetQbittorrentClient returns the qBittorrent client if it is the selected torrent client.
+func (r *Repository) GetQbittorrentClient() (*qbittorrent.Client, bool) {
+	if r.provider != QbittorrentClient || r.qBittorrentClient == nil {
+		return nil, false
 	}
+	return r.qBittorrentClient, true
+}
+
+func (r *Repository) Start() bool {
+	return r.driver.CheckStart()
 }
 func (r *Repository) TorrentExists(hash string) bool {
-	switch r.provider {
-	case QbittorrentClient:
-		p, err := r.qBittorrentClient.Torrent.GetProperties(hash)
-		return err == nil && p != nil
-	case TransmissionClient:
-		torrents, err := r.transmission.Client.TorrentGetAllForHashes(context.Background(), []string{hash})
-		return err == nil && len(torrents) > 0
-	default:
-		return false
-	}
+	return r.driver.TorrentExists(hash)
 }
 
 // GetList will return all torrents from the torrent client.
 func (r *Repository) GetList() ([]*Torrent, error) {
-	switch r.provider {
-	case QbittorrentClient:
-		torrents, err := r.qBittorrentClient.Torrent.GetList(&qbittorrent_model.GetTorrentListOptions{Filter: "all"})
-		if err != nil {
-			r.logger.Err(err).Msg("torrent client: Error while getting torrent list (qBittorrent)")
-			return nil, err
-		}
-		return r.FromQbitTorrents(torrents), nil
-	case TransmissionClient:
-		torrents, err := r.transmission.Client.TorrentGetAll(context.Background())
-		if err != nil {
-			r.logger.Err(err).Msg("torrent client: Error while getting torrent list (Transmission)")
-			return nil, err
-		}
-		return r.FromTransmissionTorrents(torrents), nil
-	default:
-		return nil, errors.New("torrent client: No torrent client provider found")
-	}
+	return r.driver.GetList()
 }
 
 // GetActiveCount will return the count of active torrents (downloading, seeding, paused).
@@ -155,49 +175,27 @@ func (r *Repository) GetActiveCount(ret *ActiveCount) {
 	ret.Seeding = 0
 	ret.Downloading = 0
 	ret.Paused = 0
-	switch r.provider {
-	case QbittorrentClient:
-		torrents, err := r.qBittorrentClient.Torrent.GetList(&qbittorrent_model.GetTorrentListOptions{Filter: "downloading"})
-		if err != nil {
-			return
-		}
-		torrents2, err := r.qBittorrentClient.Torrent.GetList(&qbittorrent_model.GetTorrentListOptions{Filter: "seeding"})
-		if err != nil {
-			return
-		}
-		torrents = append(torrents, torrents2...)
-		for _, t := range torrents {
-			switch fromQbitTorrentStatus(t.State) {
-			case TorrentStatusDownloading:
-				ret.Downloading++
-			case TorrentStatusSeeding:
-				ret.Seeding++
-			case TorrentStatusPaused:
-				ret.Paused++
-			}
-		}
-	case TransmissionClient:
-		torrents, err := r.transmission.Client.TorrentGet(context.Background(), []string{"id", "status", "isFinished"}, nil)
-		if err != nil {
-			return
-		}
-		for _, t := range torrents {
-			if t.Status == nil || t.IsFinished == nil {
-				continue
-			}
-			switch fromTransmissionTorrentStatus(*t.Status, *t.IsFinished) {
-			case TorrentStatusDownloading:
-				ret.Downloading++
-			case TorrentStatusSeeding:
-				ret.Seeding++
-			case TorrentStatusPaused:
-				ret.Paused++
-			}
-		}
+	if r.provider == NoneClient {
+		return
+	}
+	if counter, ok := r.driver.(activeCounter); ok {
+		_ = counter.GetActiveCount(ret)
 		return
-	default:
+	}
+	torrents, err := r.driver.GetList()
+	if err != nil {
 		return
 	}
+	for _, t := range torrents {
+		switch t.Status {
+		case TorrentStatusDownloading:
+			ret.Downloading++
+		case TorrentStatusSeeding:
+			ret.Seeding++
+		case TorrentStatusPaused:
+			ret.Paused++
+		}
+	}
 }
 
 // GetActiveTorrents will return all torrents that are currently downloading, paused or seeding.
@@ -223,28 +221,24 @@ func (r *Repository) AddMagnets(magnets []string, dest string) error {
 		return nil
 	}
 
-	var err error
-	switch r.provider {
-	case QbittorrentClient:
-		err = r.qBittorrentClient.Torrent.AddURLs(magnets, &qbittorrent_model.AddTorrentsOptions{
-			Savepath: dest,
-			Tags:     r.qBittorrentClient.Tags,
-		})
-	case TransmissionClient:
-		for _, magnet := range magnets {
-			_, err = r.transmission.Client.TorrentAdd(context.Background(), transmissionrpc.TorrentAddPayload{
-				Filename:    &magnet,
-				DownloadDir: &dest,
-			})
-			if err != nil {
-				r.logger.Err(err).Msg("torrent client: Error while adding magnets (Transmission)")
-				break
-			}
-		}
-	case NoneClient:
-		return errors.New("torrent client: No torrent client selected")
+	// Trigger hook event
+	reqEvent := &TorrentClientAddTorrentsRequestedEvent{
+		Magnets:     magnets,
+		Destination: dest,
+	}
+	err := hook.GlobalHookManager.OnTorrentClientAddTorrentsRequested().Trigger(reqEvent)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("torrent client: Exception occurred while triggering hook event")
+		return err
+	}
+
+	if reqEvent.DefaultPrevented {
+		r.logger.Debug().Msg("torrent client: Adding torrents prevented by hook")
+		return ErrPreventedByHook
 	}
+	magnets, dest = reqEvent.Magnets, reqEvent.Destination
 
+	err = r.driver.AddMagnets(magnets, dest)
 	if err != nil {
 		r.logger.Err(err).Msg("torrent client: Error while adding magnets")
 		return err
@@ -252,55 +246,48 @@ func (r *Repository) AddMagnets(magnets []string, dest string) error {
 
 	r.logger.Debug().Msg("torrent client: Added torrents")
 
+	// Trigger hook event
+	err = hook.GlobalHookManager.OnTorrentClientTorrentsAdded().Trigger(&TorrentClientTorrentsAddedEvent{
+		Magnets:     magnets,
+		Destination: dest,
+	})
+	if err != nil {
+		r.logger.Error().Err(err).Msg("torrent client: Exception occurred while triggering hook event")
+	}
+
 	return nil
 }
 
 func (r *Repository) RemoveTorrents(hashes []string) error {
 	r.logger.Trace().Msg("torrent client: Removing torrents")
 
-	var err error
-	switch r.provider {
-	case QbittorrentClient:
-		err = r.qBittorrentClient.Torrent.DeleteTorrents(hashes, true)
-	case TransmissionClient:
-		torrents, err := r.transmission.Client.TorrentGetAllForHashes(context.Background(), hashes)
-		if err != nil {
-			r.logger.Err(err).Msg("torrent client: Error while fetching torrents (Transmission)")
-			return err
-		}
-		ids := make([]int64, len(torrents))
-		for i, t := range torrents {
-			ids[i] = *t.ID
-		}
-		err = r.transmission.Client.TorrentRemove(context.Background(), transmissionrpc.TorrentRemovePayload{
-			IDs:             ids,
-			DeleteLocalData: true,
-		})
-		if err != nil {
-			r.logger.Err(err).Msg("torrent client: Error while removing torrents (Transmission)")
-			return err
-		}
+	hashes, err := r.triggerActionRequestedEvent(ActionRemove, hashes)
+	if err != nil {
+		return err
 	}
+
+	err = r.driver.RemoveTorrents(hashes)
 	if err != nil {
 		r.logger.Err(err).Msg("torrent client: Error while removing torrents")
 		return err
 	}
 
 	r.logger.Debug().Any("hashes", hashes).Msg("torrent client: Removed torrents")
+
+	r.triggerActionEvent(ActionRemove, hashes)
 	return nil
 }
 
 func (r *Repository) PauseTorrents(hashes []string) error {
 	r.logger.Trace().Msg("torrent client: Pausing torrents")
 
-	var err error
-	switch r.provider {
-	case QbittorrentClient:
-		err = r.qBittorrentClient.Torrent.StopTorrents(hashes)
-	case TransmissionClient:
-		err = r.transmission.Client.TorrentStopHashes(context.Background(), hashes)
+	hashes, err := r.triggerActionRequestedEvent(ActionPause, hashes)
+	if err != nil {
+		return err
 	}
 
+	err = r.driver.PauseTorrents(hashes)
+
 	if err != nil {
 		r.logger.Err(err).Msg("torrent client: Error while pausing torrents")
 		return err
@@ -308,20 +295,21 @@ func (r *Repository) PauseTorrents(hashes []string) error {
 
 	r.logger.Debug().Any("hashes", hashes).Msg("torrent client: Paused torrents")
 
+	r.triggerActionEvent(ActionPause, hashes)
+
 	return nil
 }
 
 func (r *Repository) ResumeTorrents(hashes []string) error {
 	r.logger.Trace().Msg("torrent client: Resuming torrents")
 
-	var err error
-	switch r.provider {
-	case QbittorrentClient:
-		err = r.qBittorrentClient.Torrent.ResumeTorrents(hashes)
-	case TransmissionClient:
-		err = r.transmission.Client.TorrentStartHashes(context.Background(), hashes)
+	hashes, err := r.triggerActionRequestedEvent(ActionResume, hashes)
+	if err != nil {
+		return err
 	}
 
+	err = r.driver.ResumeTorrents(hashes)
+
 	if err != nil {
 		r.logger.Err(err).Msg("torrent client: Error while resuming torrents")
 		return err
@@ -329,35 +317,43 @@ func (r *Repository) ResumeTorrents(hashes []string) error {
 
 	r.logger.Debug().Any("hashes", hashes).Msg("torrent client: Resumed torrents")
 
+	r.triggerActionEvent(ActionResume, hashes)
+
 	return nil
 }
 
-func (r *Repository) DeselectFiles(hash string, indices []int) error {
+// triggerActionRequestedEvent lets hooks modify the hashes or veto the action before it is sent to the torrent client.
+func (r *Repository) triggerActionRequestedEvent(action string, hashes []string) ([]string, error) {
+	event := &TorrentClientActionRequestedEvent{
+		Action: action,
+		Hashes: hashes,
+	}
+	err := hook.GlobalHookManager.OnTorrentClientActionRequested().Trigger(event)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("torrent client: Exception occurred while triggering hook event")
+		return nil, err
+	}
 
-	var err error
-	switch r.provider {
-	case QbittorrentClient:
-		strIndices := make([]string, len(indices), len(indices))
-		for i, v := range indices {
-			strIndices[i] = strconv.Itoa(v)
-		}
-		err = r.qBittorrentClient.Torrent.SetFilePriorities(hash, strIndices, 0)
-	case TransmissionClient:
-		torrents, err := r.transmission.Client.TorrentGetAllForHashes(context.Background(), []string{hash})
-		if err != nil || torrents[0].ID == nil {
-			r.logger.Err(err).Msg("torrent client: Error while deselecting files (Transmission)")
-			return err
-		}
-		id := *torrents[0].ID
-		ind := make([]int64, len(indices), len(indices))
-		for i, v := range indices {
-			ind[i] = int64(v)
-		}
-		err = r.transmission.Client.TorrentSet(context.Background(), transmissionrpc.TorrentSetPayload{
-			FilesUnwanted: ind,
-			IDs:           []int64{id},
-		})
+	if event.DefaultPrevented {
+		r.logger.Debug().Str("action", action).Msg("torrent client: Action prevented by hook")
+		return nil, ErrPreventedByHook
+	}
+
+	return event.Hashes, nil
+}
+
+func (r *Repository) triggerActionEvent(action string, hashes []string) {
+	err := hook.GlobalHookManager.OnTorrentClientAction().Trigger(&TorrentClientActionEvent{
+		Action: action,
+		Hashes: hashes,
+	})
+	if err != nil {
+		r.logger.Error().Err(err).Msg("torrent client: Exception occurred while triggering hook event")
 	}
+}
+
+func (r *Repository) DeselectFiles(hash string, indices []int) error {
+	err := r.driver.DeselectFiles(hash, indices)
 
 	if err != nil {
 		r.logger.Err(err).Msg("torrent client: Error while deselecting files")
@@ -389,26 +385,11 @@ func (r *Repository) GetFiles(hash string) (filenames []string, err error) {
 				err = errors.New("torrent client: Unable to retrieve torrent files (timeout)")
 				return
 			case <-ticker.C:
-				switch r.provider {
-				case QbittorrentClient:
-					qbitFiles, err := r.qBittorrentClient.Torrent.GetContents(hash)
-					if err == nil && qbitFiles != nil && len(qbitFiles) > 0 {
-						r.logger.Debug().Str("hash", hash).Int("count", len(qbitFiles)).Msg("torrent client: Retrieved torrent files")
-						for _, f := range qbitFiles {
-							filenames = append(filenames, f.Name)
-						}
-						return
-					}
-				case TransmissionClient:
-					torrents, err := r.transmission.Client.TorrentGetAllForHashes(context.Background(), []string{hash})
-					if err == nil && len(torrents) > 0 && torrents[0].Files != nil && len(torrents[0].Files) > 0 {
-						transmissionFiles := torrents[0].Files
-						r.logger.Debug().Str("hash", hash).Int("count", len(transmissionFiles)).Msg("torrent client: Retrieved torrent files")
-						for _, f := range transmissionFiles {
-							filenames = append(filenames, f.Name)
-						}
-						return
-					}
+				files, err := r.driver.GetFiles(hash)
+				if err == nil && len(files) > 0 {
+					r.logger.Debug().Str("hash", hash).Int("count", len(files)).Msg("torrent client: Retrieved torrent files")
+					filenames = append(filenames, files...)
+					return
 				}
 			}
 		}
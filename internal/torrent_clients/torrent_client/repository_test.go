@@ -0,0 +1,74 @@
+package torrent_client
+
+import (
+	"seanime/internal/hook"
+	"seanime/internal/hook_resolver"
+	"seanime/internal/util"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordingDriver records the calls sent to the torrent client.
+type recordingDriver struct {
+	noneDriver
+	magnets []string
+	dest    string
+	paused  []string
+}
+
+func (d *recordingDriver) AddMagnets(magnets []string, dest string) error {
+	d.magnets = magnets
+	d.dest = dest
+	return nil
+}
+
+func (d *recordingDriver) PauseTorrents(hashes []string) error {
+	d.paused = hashes
+	return nil
+}
+
+func TestRepository_Hooks(t *testing.T) {
+	driver := &recordingDriver{}
+	repo := &Repository{
+		logger: util.NewLogger(),
+		driver: driver,
+	}
+
+	// Change the destination of the torrents
+	id := hook.GlobalHookManager.OnTorrentClientAddTorrentsRequested().BindFunc(func(e hook_resolver.Resolver) error {
+		event := e.(*TorrentClientAddTorrentsRequestedEvent)
+		event.Destination = "/downloads/Anime"
+		return e.Next()
+	})
+	require.NoError(t, repo.AddMagnets([]string{"magnet:?xt=urn:btih:abc"}, "/downloads"))
+	require.Equal(t, "/downloads/Anime", driver.dest)
+	hook.GlobalHookManager.OnTorrentClientAddTorrentsRequested().Unbind(id)
+
+	// Veto the addition
+	id = hook.GlobalHookManager.OnTorrentClientAddTorrentsRequested().BindFunc(func(e hook_resolver.Resolver) error {
+		e.PreventDefault()
+		return e.Next()
+	})
+	driver.magnets = nil
+	require.ErrorIs(t, repo.AddMagnets([]string{"magnet:?xt=urn:btih:def"}, "/downloads"), ErrPreventedByHook)
+	require.Nil(t, driver.magnets)
+	hook.GlobalHookManager.OnTorrentClientAddTorrentsRequested().Unbind(id)
+
+	// Filter the hashes of an action
+	actions := make([]string, 0)
+	reqId := hook.GlobalHookManager.OnTorrentClientActionRequested().BindFunc(func(e hook_resolver.Resolver) error {
+		event := e.(*TorrentClientActionRequestedEvent)
+		event.Hashes = event.Hashes[:1]
+		return e.Next()
+	})
+	id = hook.GlobalHookManager.OnTorrentClientAction().BindFunc(func(e hook_resolver.Resolver) error {
+		actions = append(actions, e.(*TorrentClientActionEvent).Action)
+		return e.Next()
+	})
+	require.NoError(t, repo.PauseTorrents([]string{"abc", "def"}))
+	require.Equal(t, []string{"abc"}, driver.paused)
+	require.Equal(t, []string{ActionPause}, actions)
+	hook.GlobalHookManager.OnTorrentClientActionRequested().Unbind(reqId)
+	hook.GlobalHookManager.OnTorrentClientAction().Unbind(id)
+}
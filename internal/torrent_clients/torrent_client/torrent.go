@@ -35,15 +35,15 @@ type (
 //	return &Torrent{}
 //})
 
-func (r *Repository) FromTransmissionTorrents(t []transmissionrpc.Torrent) []*Torrent {
+func fromTransmissionTorrents(t []transmissionrpc.Torrent) []*Torrent {
 	ret := make([]*Torrent, 0, len(t))
 	for _, t := range t {
-		ret = append(ret, r.FromTransmissionTorrent(&t))
+		ret = append(ret, fromTransmissionTorrent(&t))
 	}
 	return ret
 }
 
-func (r *Repository) FromTransmissionTorrent(t *transmissionrpc.Torrent) *Torrent {
+func fromTransmissionTorrent(t *transmissionrpc.Torrent) *Torrent {
 	torrent := &Torrent{}
 
 	torrent.Name = "N/A"
@@ -114,14 +114,14 @@ func fromTransmissionTorrentStatus(st transmissionrpc.TorrentStatus, isFinished
 	}
 }
 
-func (r *Repository) FromQbitTorrents(t []*qbittorrent_model.Torrent) []*Torrent {
+func fromQbitTorrents(t []*qbittorrent_model.Torrent) []*Torrent {
 	ret := make([]*Torrent, 0, len(t))
 	for _, t := range t {
-		ret = append(ret, r.FromQbitTorrent(t))
+		ret = append(ret, fromQbitTorrent(t))
 	}
 	return ret
 }
-func (r *Repository) FromQbitTorrent(t *qbittorrent_model.Torrent) *Torrent {
+func fromQbitTorrent(t *qbittorrent_model.Torrent) *Torrent {
 	torrent := &Torrent{}
 
 	torrent.Name = t.Name
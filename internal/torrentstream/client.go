@@ -11,6 +11,7 @@ import (
 	"path"
 	"seanime/internal/mediaplayers/mediaplayer"
 	"seanime/internal/util"
+	"seanime/internal/util/result"
 	"strings"
 	"sync"
 	"time"
@@ -26,19 +27,16 @@ type (
 	Client struct {
 		repository *Repository
 
-		torrentClient        mo.Option[*torrent.Client]
-		currentTorrent       mo.Option[*torrent.Torrent]
-		currentFile          mo.Option[*torrent.File]
-		currentTorrentStatus TorrentStatus
-		cancelFunc           context.CancelFunc
+		torrentClient    mo.Option[*torrent.Client]
+		sessions         *result.Map[string, *StreamSession] // Key: Session ID
+		currentSessionId mo.Option[string]                   // Last session that was started
+		reserved         map[string]int                      // Key: Info hash (hex), number of requests preparing the torrent
+		cancelFunc       context.CancelFunc
 
 		mu                          sync.Mutex
 		stopCh                      chan struct{}                    // Closed when the media player stops
 		mediaPlayerPlaybackStatusCh chan *mediaplayer.PlaybackStatus // Continuously receives playback status
 		timeSinceLoggedSeeding      time.Time
-		lastSpeedCheck              time.Time // Track the last time we checked speeds
-		lastBytesCompleted          int64     // Track the last bytes completed
-		lastBytesWrittenData        int64     // Track the last bytes written data
 	}
 
 	TorrentStatus struct {
@@ -60,8 +58,9 @@ func NewClient(repository *Repository) *Client {
 	ret := &Client{
 		repository:                  repository,
 		torrentClient:               mo.None[*torrent.Client](),
-		currentFile:                 mo.None[*torrent.File](),
-		currentTorrent:              mo.None[*torrent.Torrent](),
+		sessions:                    result.NewResultMap[string, *StreamSession](),
+		currentSessionId:            mo.None[string](),
+		reserved:                    make(map[string]int),
 		stopCh:                      make(chan struct{}),
 		mediaPlayerPlaybackStatusCh: make(chan *mediaplayer.PlaybackStatus, 1),
 	}
@@ -70,7 +69,7 @@ func NewClient(repository *Repository) *Client {
 }
 
 // initializeClient will create and torrent client.
-// The client is designed to support only one torrent at a time, and seed it.
+// The client supports one torrent per stream session, and seeds them.
 // Upon initialization, the client will drop all torrents.
 func (c *Client) initializeClient() error {
 	// Fail if no settings
@@ -105,6 +104,14 @@ func (c *Client) initializeClient() error {
 		// cfg.DisableAggressiveUpload = true
 	}
 
+	// The rate limiters are shared by all sessions
+	if settings.DownloadRateLimit > 0 {
+		cfg.DownloadRateLimiter = newRateLimiter(settings.DownloadRateLimit)
+	}
+	if settings.UploadRateLimit > 0 {
+		cfg.UploadRateLimiter = newRateLimiter(settings.UploadRateLimit)
+	}
+
 	//cfg.DisableAggressiveUpload = true
 	//cfg.Debug = true
 
@@ -129,6 +136,8 @@ func (c *Client) initializeClient() error {
 	}
 	c.repository.logger.Info().Msgf("torrentstream: Initialized torrent client on port %d", settings.TorrentClientPort)
 	c.torrentClient = mo.Some(client)
+	c.sessions.Clear()
+	c.currentSessionId = mo.None[string]()
 	c.dropTorrents()
 	c.mu.Unlock()
 
@@ -162,7 +171,7 @@ func (c *Client) initializeClient() error {
 
 			case status := <-c.mediaPlayerPlaybackStatusCh:
 				// DEVNOTE: When this is received, "default" case is executed right after
-				if status != nil && c.currentFile.IsPresent() && c.repository.playback.currentVideoDuration == 0 {
+				if _, found := c.getMediaPlayerSession(); status != nil && found && c.repository.playback.currentVideoDuration == 0 {
 					// If the stored video duration is 0 but the media player status shows a duration that is not 0
 					// we know that the video has been loaded and is playing
 					if c.repository.playback.currentVideoDuration == 0 && status.Duration > 0 {
@@ -175,67 +184,10 @@ func (c *Client) initializeClient() error {
 				}
 			default:
 				c.mu.Lock()
-				if c.torrentClient.IsPresent() && c.currentTorrent.IsPresent() && c.currentFile.IsPresent() {
-					t := c.currentTorrent.MustGet()
-					f := c.currentFile.MustGet()
-
-					// Get the current time
-					now := time.Now()
-					elapsed := now.Sub(c.lastSpeedCheck).Seconds()
-
-					// downloadProgress is the number of bytes downloaded
-					downloadProgress := t.BytesCompleted()
-
-					downloadSpeed := ""
-					if elapsed > 0 {
-						bytesPerSecond := float64(downloadProgress-c.lastBytesCompleted) / elapsed
-						if bytesPerSecond > 0 {
-							downloadSpeed = fmt.Sprintf("%s/s", util.Bytes(uint64(bytesPerSecond)))
-						}
-					}
-					size := util.Bytes(uint64(f.Length()))
-
-					bytesWrittenData := t.Stats().BytesWrittenData
-					uploadSpeed := ""
-					if elapsed > 0 {
-						bytesPerSecond := float64((&bytesWrittenData).Int64()-c.lastBytesWrittenData) / elapsed
-						if bytesPerSecond > 0 {
-							uploadSpeed = fmt.Sprintf("%s/s", util.Bytes(uint64(bytesPerSecond)))
-						}
-					}
-
-					// Update the stored values for next calculation
-					c.lastBytesCompleted = downloadProgress
-					c.lastBytesWrittenData = (&bytesWrittenData).Int64()
-					c.lastSpeedCheck = now
-
-					if t.PeerConns() != nil {
-						c.currentTorrentStatus.Seeders = len(t.PeerConns())
-					}
-
-					//// If the torrent status went from 0% to > 0%, send an event, as the torrent has been loaded
-					//if c.currentTorrentStatus.ProgressPercentage == 0. && c.getTorrentPercentage(c.currentTorrent) > 0. {
-					//	c.repository.logger.Debug().Msg("torrentstream: Torrent loaded, sending event")
-					//	c.repository.wsEventManager.SendEvent(eventTorrentLoaded, nil)
-					//}
-
-					c.currentTorrentStatus = TorrentStatus{
-						Size:               size,
-						UploadProgress:     (&bytesWrittenData).Int64() - c.currentTorrentStatus.UploadProgress,
-						DownloadSpeed:      downloadSpeed,
-						UploadSpeed:        uploadSpeed,
-						DownloadProgress:   downloadProgress,
-						ProgressPercentage: c.getTorrentPercentage(c.currentTorrent, c.currentFile),
-						Seeders:            t.Stats().ConnectedSeeders,
+				if c.torrentClient.IsPresent() {
+					for _, session := range c.sessions.Values() {
+						c.updateSessionStatus(session)
 					}
-					c.repository.wsEventManager.SendEvent(eventTorrentStatus, c.currentTorrentStatus)
-					// Always log the progress so the user knows what's happening
-					c.repository.logger.Trace().Msgf("torrentstream: Progress: %.2f%%, Download speed: %s, Upload speed: %s, Size: %s",
-						c.currentTorrentStatus.ProgressPercentage,
-						c.currentTorrentStatus.DownloadSpeed,
-						c.currentTorrentStatus.UploadSpeed,
-						c.currentTorrentStatus.Size)
-					c.timeSinceLoggedSeeding = time.Now()
 				}
 				c.mu.Unlock()
 				if c.torrentClient.IsPresent() {
@@ -256,13 +208,85 @@ func (c *Client) initializeClient() error {
 	return nil
 }
 
+// updateSessionStatus refreshes the status of the session and sends it to the client.
+// The caller should hold the lock.
+func (c *Client) updateSessionStatus(session *StreamSession) {
+	t := session.torrent
+	f := session.file
+
+	// Get the current time
+	now := time.Now()
+	elapsed := now.Sub(session.lastSpeedCheck).Seconds()
+
+	// downloadProgress is the number of bytes downloaded
+	downloadProgress := t.BytesCompleted()
+
+	downloadSpeed := ""
+	if elapsed > 0 {
+		bytesPerSecond := float64(downloadProgress-session.lastBytesCompleted) / elapsed
+		if bytesPerSecond > 0 {
+			downloadSpeed = fmt.Sprintf("%s/s", util.Bytes(uint64(bytesPerSecond)))
+		}
+	}
+	size := util.Bytes(uint64(f.Length()))
+
+	bytesWrittenData := t.Stats().BytesWrittenData
+	uploadSpeed := ""
+	if elapsed > 0 {
+		bytesPerSecond := float64((&bytesWrittenData).Int64()-session.lastBytesWrittenData) / elapsed
+		if bytesPerSecond > 0 {
+			uploadSpeed = fmt.Sprintf("%s/s", util.Bytes(uint64(bytesPerSecond)))
+		}
+	}
+
+	// Update the stored values for next calculation
+	session.lastBytesCompleted = downloadProgress
+	session.lastBytesWrittenData = (&bytesWrittenData).Int64()
+	session.lastSpeedCheck = now
+
+	session.Status = TorrentStatus{
+		Size:               size,
+		UploadProgress:     (&bytesWrittenData).Int64() - session.Status.UploadProgress,
+		DownloadSpeed:      downloadSpeed,
+		UploadSpeed:        uploadSpeed,
+		DownloadProgress:   downloadProgress,
+		ProgressPercentage: c.getTorrentPercentage(mo.Some(t), mo.Some(f)),
+		Seeders:            t.Stats().ConnectedSeeders,
+	}
+	c.repository.wsEventManager.SendEvent(eventTorrentSessionStatus, &SessionStatus{
+		SessionId: session.ID,
+		Status:    session.Status,
+	})
+	c.repository.sendSessionEvent(session.ClientId, eventTorrentStatus, session.Status)
+	// Always log the progress so the user knows what's happening
+	c.repository.logger.Trace().Str("sessionId", session.ID).Msgf("torrentstream: Progress: %.2f%%, Download speed: %s, Upload speed: %s, Size: %s",
+		session.Status.ProgressPercentage,
+		session.Status.DownloadSpeed,
+		session.Status.UploadSpeed,
+		session.Status.Size)
+	c.timeSinceLoggedSeeding = time.Now()
+}
+
+// GetStreamingUrl returns the streaming URL of the current session.
 func (c *Client) GetStreamingUrl() string {
+	session, ok := c.getCurrentSession().Get()
+	if !ok {
+		return ""
+	}
+	return c.GetSessionStreamingUrl(session.ID)
+}
+
+// GetSessionStreamingUrl returns the streaming URL of the session.
+// e.g. http://127.0.0.1:43211/api/v1/torrentstream/stream/{sessionId}/{filename}
+func (c *Client) GetSessionStreamingUrl(sessionId string) string {
 	if c.torrentClient.IsAbsent() {
 		return ""
 	}
-	if c.currentFile.IsAbsent() {
+	session, found := c.getSession(sessionId)
+	if !found {
 		return ""
 	}
+	streamPath := url.PathEscape(session.ID) + "/" + url.PathEscape(session.file.DisplayPath())
 	settings, ok := c.repository.settings.Get()
 	if !ok {
 		return ""
@@ -277,7 +301,7 @@ func (c *Client) GetStreamingUrl() string {
 		if settings.StreamUrlAddress != "" {
 			address = settings.StreamUrlAddress
 		}
-		_url := fmt.Sprintf("http://%s/api/v1/torrentstream/stream/%s", address, url.PathEscape(c.currentFile.MustGet().DisplayPath()))
+		_url := fmt.Sprintf("http://%s/api/v1/torrentstream/stream/%s", address, streamPath)
 		if strings.HasPrefix(_url, "http://http") {
 			_url = strings.Replace(_url, "http://http", "http", 1)
 		}
@@ -291,9 +315,9 @@ func (c *Client) GetStreamingUrl() string {
 	if host == "" {
 		host = "127.0.0.1"
 	}
-	_url := fmt.Sprintf("http://%s:%d/stream/%s", host, settings.StreamingServerPort, url.PathEscape(c.currentFile.MustGet().DisplayPath()))
+	_url := fmt.Sprintf("http://%s:%d/stream/%s", host, settings.StreamingServerPort, streamPath)
 	if settings.StreamUrlAddress != "" {
-		_url = fmt.Sprintf("http://%s/stream/%s", settings.StreamUrlAddress, url.PathEscape(c.currentFile.MustGet().DisplayPath()))
+		_url = fmt.Sprintf("http://%s/stream/%s", settings.StreamUrlAddress, streamPath)
 		if strings.HasPrefix(_url, "http://http") {
 			_url = strings.Replace(_url, "http://http", "http", 1)
 		}
@@ -301,33 +325,54 @@ func (c *Client) GetStreamingUrl() string {
 	return _url
 }
 
-func (c *Client) AddTorrent(id string) (*torrent.Torrent, error) {
+// AddTorrent adds the torrent and holds it in the reservation until the reservation is released.
+func (c *Client) AddTorrent(id string, reservation *torrentReservation) (*torrent.Torrent, error) {
 	if c.torrentClient.IsAbsent() {
 		return nil, errors.New("torrent client is not initialized")
 	}
 
-	// Drop all torrents
+	// Drop all torrents that are not being streamed or prepared
+	c.mu.Lock()
 	for _, t := range c.torrentClient.MustGet().Torrents() {
-		t.Drop()
+		if !c.isTorrentInUse(t, "") {
+			t.Drop()
+		}
 	}
+	c.mu.Unlock()
 
 	if strings.HasPrefix(id, "magnet") {
-		return c.addTorrentMagnet(id)
+		return c.addTorrentMagnet(id, reservation)
 	}
 
 	if strings.HasPrefix(id, "http") {
-		return c.addTorrentFromDownloadURL(id)
+		return c.addTorrentFromDownloadURL(id, reservation)
 	}
 
-	return c.addTorrentFromFile(id)
+	return c.addTorrentFromFile(id, reservation)
+}
+
+// addAndReserve adds the torrent and reserves it under the client's lock,
+// so that it cannot be dropped by another request in between.
+func (c *Client) addAndReserve(add func(*torrent.Client) (*torrent.Torrent, error), reservation *torrentReservation) (*torrent.Torrent, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, err := add(c.torrentClient.MustGet())
+	if err != nil {
+		return nil, err
+	}
+	reservation.reserve(t.InfoHash().HexString())
+	return t, nil
 }
 
-func (c *Client) addTorrentMagnet(magnet string) (*torrent.Torrent, error) {
+func (c *Client) addTorrentMagnet(magnet string, reservation *torrentReservation) (*torrent.Torrent, error) {
 	if c.torrentClient.IsAbsent() {
 		return nil, errors.New("torrent client is not initialized")
 	}
 
-	t, err := c.torrentClient.MustGet().AddMagnet(magnet)
+	t, err := c.addAndReserve(func(tc *torrent.Client) (*torrent.Torrent, error) {
+		return tc.AddMagnet(magnet)
+	}, reservation)
 	if err != nil {
 		return nil, err
 	}
@@ -340,19 +385,21 @@ func (c *Client) addTorrentMagnet(magnet string) (*torrent.Torrent, error) {
 		//t.Drop()
 		return nil, errors.New("torrent closed")
 	case <-time.After(1 * time.Minute):
-		t.Drop()
+		reservation.remove(t)
 		return nil, errors.New("timeout waiting for torrent info")
 	}
 	c.repository.logger.Info().Msgf("torrentstream: Torrent added: %s", t.InfoHash().AsString())
 	return t, nil
 }
 
-func (c *Client) addTorrentFromFile(fp string) (*torrent.Torrent, error) {
+func (c *Client) addTorrentFromFile(fp string, reservation *torrentReservation) (*torrent.Torrent, error) {
 	if c.torrentClient.IsAbsent() {
 		return nil, errors.New("torrent client is not initialized")
 	}
 
-	t, err := c.torrentClient.MustGet().AddTorrentFromFile(fp)
+	t, err := c.addAndReserve(func(tc *torrent.Client) (*torrent.Torrent, error) {
+		return tc.AddTorrentFromFile(fp)
+	}, reservation)
 	if err != nil {
 		return nil, err
 	}
@@ -362,7 +409,7 @@ func (c *Client) addTorrentFromFile(fp string) (*torrent.Torrent, error) {
 	return t, nil
 }
 
-func (c *Client) addTorrentFromDownloadURL(url string) (*torrent.Torrent, error) {
+func (c *Client) addTorrentFromDownloadURL(url string, reservation *torrentReservation) (*torrent.Torrent, error) {
 	if c.torrentClient.IsAbsent() {
 		return nil, errors.New("torrent client is not initialized")
 	}
@@ -385,7 +432,9 @@ func (c *Client) addTorrentFromDownloadURL(url string) (*torrent.Torrent, error)
 		return nil, err
 	}
 
-	t, err := c.torrentClient.MustGet().AddTorrentFromFile(file.Name())
+	t, err := c.addAndReserve(func(tc *torrent.Client) (*torrent.Torrent, error) {
+		return tc.AddTorrentFromFile(file.Name())
+	}, reservation)
 	if err != nil {
 		return nil, err
 	}
@@ -394,10 +443,10 @@ func (c *Client) addTorrentFromDownloadURL(url string) (*torrent.Torrent, error)
 	case <-t.GotInfo():
 		break
 	case <-t.Closed():
-		t.Drop()
+		reservation.remove(t)
 		return nil, errors.New("torrent closed")
 	case <-time.After(1 * time.Minute):
-		t.Drop()
+		reservation.remove(t)
 		return nil, errors.New("timeout waiting for torrent info")
 	}
 	c.repository.logger.Info().Msgf("torrentstream: Added torrent: %s", t.InfoHash().AsString())
@@ -410,9 +459,11 @@ func (c *Client) Shutdown() (errs []error) {
 	if c.torrentClient.IsAbsent() {
 		return
 	}
+	c.mu.Lock()
+	c.sessions.Clear()
+	c.currentSessionId = mo.None[string]()
 	c.dropTorrents()
-	c.currentTorrent = mo.None[*torrent.Torrent]()
-	c.currentTorrentStatus = TorrentStatus{}
+	c.mu.Unlock()
 	c.repository.logger.Debug().Msg("torrentstream: Closing torrent client")
 	return c.torrentClient.MustGet().Close()
 }
@@ -442,6 +493,14 @@ func (c *Client) RemoveTorrent(infoHash string) error {
 	torrents := c.torrentClient.MustGet().Torrents()
 	for _, t := range torrents {
 		if t.InfoHash().AsString() == infoHash {
+			// Do not remove a torrent that another session is streaming or a request is preparing
+			c.mu.Lock()
+			inUse := c.isTorrentInUse(t, "")
+			c.mu.Unlock()
+			if inUse {
+				c.repository.logger.Debug().Msgf("torrentstream: Torrent is being streamed, not removing: %s", infoHash)
+				return nil
+			}
 			t.Drop()
 			c.repository.logger.Debug().Msgf("torrentstream: Removed torrent: %s", infoHash)
 			return nil
@@ -450,29 +509,42 @@ func (c *Client) RemoveTorrent(infoHash string) error {
 	return fmt.Errorf("no torrent found")
 }
 
+// dropTorrents drops the torrents that are not streamed by a session or being prepared and deletes their data.
+// The caller should hold the client's lock.
 func (c *Client) dropTorrents() {
 	if c.torrentClient.IsAbsent() {
 		return
 	}
-	c.repository.logger.Trace().Msg("torrentstream: Dropping all torrents")
+	c.repository.logger.Trace().Msg("torrentstream: Dropping unused torrents")
+
+	inUse := make(map[string]struct{})
+	for _, session := range c.sessions.Values() {
+		inUse[session.torrent.InfoHash().HexString()] = struct{}{}
+	}
+	for infoHash := range c.reserved {
+		inUse[infoHash] = struct{}{}
+	}
 
 	for _, t := range c.torrentClient.MustGet().Torrents() {
+		if _, ok := inUse[t.InfoHash().HexString()]; ok {
+			continue
+		}
 		t.Drop()
 	}
 
 	if c.repository.settings.IsPresent() {
-		// Delete all torrents
+		// Delete the data of the dropped torrents
 		fe, err := os.ReadDir(c.repository.settings.MustGet().DownloadDir)
 		if err == nil {
 			for _, f := range fe {
-				if f.IsDir() {
+				if _, ok := inUse[f.Name()]; f.IsDir() && !ok {
 					_ = os.RemoveAll(path.Join(c.repository.settings.MustGet().DownloadDir, f.Name()))
 				}
 			}
 		}
 	}
 
-	c.repository.logger.Debug().Msg("torrentstream: Dropped all torrents")
+	c.repository.logger.Debug().Msg("torrentstream: Dropped unused torrents")
 }
 
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -491,14 +563,14 @@ func (c *Client) getTorrentPercentage(t mo.Option[*torrent.Torrent], f mo.Option
 	return float64(f.MustGet().BytesCompleted()) / float64(f.MustGet().Length()) * 100
 }
 
-// readyToStream determines if enough of the file has been downloaded to begin streaming
+// readyToStream determines if enough of the session's file has been downloaded to begin streaming
 // Uses both absolute size (minimum buffer) and a percentage-based approach
-func (c *Client) readyToStream() bool {
-	if c.currentTorrent.IsAbsent() || c.currentFile.IsAbsent() {
+func (c *Client) readyToStream(session *StreamSession) bool {
+	if session == nil || session.file == nil {
 		return false
 	}
 
-	file := c.currentFile.MustGet()
+	file := session.file
 
 	// Always need at least 1MB to start playback (typical header size for many formats)
 	const minimumBufferBytes int64 = 1 * 1024 * 1024 // 1MB
@@ -522,3 +594,11 @@ func (c *Client) readyToStream() bool {
 	// Ready when both minimum buffer is met AND percentage threshold is reached
 	return bytesCompleted >= minimumBufferBytes && percentCompleted >= percentThreshold
 }
+
+// newRateLimiter returns a rate limiter for a limit in KiB/s.
+func newRateLimiter(kibPerSecond int) *rate.Limiter {
+	bytesPerSecond := kibPerSecond * 1024
+	// The burst needs to be at least as large as a chunk
+	burst := max(bytesPerSecond, 1<<16)
+	return rate.NewLimiter(rate.Limit(bytesPerSecond), burst)
+}
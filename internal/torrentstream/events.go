@@ -8,6 +8,7 @@ const (
 	eventTorrentStartedPlaying = "torrentstream-torrent-started-playing"
 	eventTorrentStatus         = "torrentstream-torrent-status"
 	eventTorrentStopped        = "torrentstream-torrent-stopped"
+	eventTorrentSessionStatus  = "torrentstream-session-status"
 )
 
 type TorrentLoadingStatus struct {
@@ -80,7 +80,7 @@ func (r *Repository) setPriorityDownloadStrategy(t *torrent.Torrent, file *torre
 	// }
 }
 
-func (r *Repository) findBestTorrent(media *anilist.CompleteAnime, aniDbEpisode string, episodeNumber int) (ret *playbackTorrent, err error) {
+func (r *Repository) findBestTorrent(media *anilist.CompleteAnime, aniDbEpisode string, episodeNumber int, reservation *torrentReservation) (ret *playbackTorrent, err error) {
 	defer util.HandlePanicInModuleWithError("torrentstream/findBestTorrent", &err)
 
 	r.logger.Debug().Msgf("torrentstream: Finding best torrent for %s, Episode %d", media.GetTitleSafe(), episodeNumber)
@@ -229,7 +229,7 @@ searchLoop:
 		}
 		r.logger.Debug().Msgf("torrentstream: Adding torrent %s from magnet", searchT.Link)
 
-		t, err := r.client.AddTorrent(magnet)
+		t, err := r.client.AddTorrent(magnet, reservation)
 		if err != nil {
 			r.logger.Warn().Err(err).Msgf("torrentstream: Error adding torrent %s", searchT.Link)
 			tries++
@@ -282,9 +282,7 @@ searchLoop:
 		if err != nil {
 			r.logger.Warn().Err(err).Msg("torrentstream: Error analyzing torrent files")
 			// Remove torrent on failure
-			go func() {
-				_ = r.client.RemoveTorrent(t.InfoHash().AsString())
-			}()
+			go reservation.remove(t)
 			tries++
 			continue
 		}
@@ -294,9 +292,7 @@ searchLoop:
 		if !found {
 			r.logger.Error().Msgf("torrentstream: Failed to auto-select episode from torrent %s", searchT.Link)
 			// Remove torrent on failure
-			go func() {
-				_ = r.client.RemoveTorrent(t.InfoHash().AsString())
-			}()
+			go reservation.remove(t)
 			tries++
 			continue
 		}
@@ -331,7 +327,7 @@ searchLoop:
 }
 
 // findBestTorrentFromManualSelection is like findBestTorrent but no need to search for the best torrent first
-func (r *Repository) findBestTorrentFromManualSelection(t *hibiketorrent.AnimeTorrent, media *anilist.CompleteAnime, aniDbEpisode string, chosenFileIndex *int) (*playbackTorrent, error) {
+func (r *Repository) findBestTorrentFromManualSelection(t *hibiketorrent.AnimeTorrent, media *anilist.CompleteAnime, aniDbEpisode string, chosenFileIndex *int, reservation *torrentReservation) (*playbackTorrent, error) {
 
 	r.logger.Debug().Msgf("torrentstream: Analyzing torrent from %s for %s", t.Link, media.GetTitleSafe())
 
@@ -348,7 +344,7 @@ func (r *Repository) findBestTorrentFromManualSelection(t *hibiketorrent.AnimeTo
 		r.logger.Error().Err(err).Msgf("torrentstream: Error scraping magnet link for %s", t.Link)
 		return nil, fmt.Errorf("could not get magnet link from %s", t.Link)
 	}
-	selectedTorrent, err := r.client.AddTorrent(magnet)
+	selectedTorrent, err := r.client.AddTorrent(magnet, reservation)
 	if err != nil {
 		r.logger.Error().Err(err).Msgf("torrentstream: Error adding torrent %s", t.Link)
 		return nil, err
@@ -401,9 +397,7 @@ func (r *Repository) findBestTorrentFromManualSelection(t *hibiketorrent.AnimeTo
 		if err != nil {
 			r.logger.Warn().Err(err).Msg("torrentstream: Error analyzing torrent files")
 			// Remove torrent on failure
-			go func() {
-				_ = r.client.RemoveTorrent(selectedTorrent.InfoHash().AsString())
-			}()
+			go reservation.remove(selectedTorrent)
 			return nil, err
 		}
 
@@ -412,9 +406,7 @@ func (r *Repository) findBestTorrentFromManualSelection(t *hibiketorrent.AnimeTo
 		if !found {
 			r.logger.Error().Msgf("torrentstream: Failed to auto-select episode from torrent %s", selectedTorrent.Info().Name)
 			// Remove torrent on failure
-			go func() {
-				_ = r.client.RemoveTorrent(selectedTorrent.InfoHash().AsString())
-			}()
+			go reservation.remove(selectedTorrent)
 			return nil, ErrNoEpisodeFound
 		}
 
@@ -40,15 +40,15 @@ func (r *Repository) listenToMediaPlayerEvents() {
 				r.playback.currentVideoDuration = 0
 			case _ = <-r.mediaPlayerRepositorySubscriber.StreamingVideoCompletedCh:
 			case _ = <-r.mediaPlayerRepositorySubscriber.StreamingTrackingStoppedCh:
-				if r.client.currentTorrent.IsPresent() {
+				if session, found := r.client.getMediaPlayerSession(); found {
 					go func() {
 						defer func() {
 							if r := recover(); r != nil {
 							}
 						}()
 						r.logger.Debug().Msg("torrentstream: Media player stopped event received")
-						// Stop the stream
-						_ = r.StopStream()
+						// Stop the stream played by the media player, other sessions are not affected
+						_ = r.StopSession(session.ID)
 						// Stop the server
 						//r.serverManager.stopServer()
 						//// Signal to client.go that the media player has stopped
@@ -57,7 +57,7 @@ func (r *Repository) listenToMediaPlayerEvents() {
 				}
 			case status := <-r.mediaPlayerRepositorySubscriber.StreamingPlaybackStatusCh:
 				go func() {
-					if status != nil && r.client.currentTorrent.IsPresent() {
+					if _, found := r.client.getMediaPlayerSession(); status != nil && found {
 						r.client.mediaPlayerPlaybackStatusCh <- status
 					}
 				}()
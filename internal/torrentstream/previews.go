@@ -40,7 +40,11 @@ func (r *Repository) GetTorrentFilePreviewsFromManualSelection(opts *GetTorrentF
 
 	r.logger.Trace().Str("hash", opts.Torrent.InfoHash).Msg("torrentstream: Getting file previews for torrent selection")
 
-	selectedTorrent, err := r.client.AddTorrent(opts.Magnet)
+	// Keep the torrent from being dropped while the previews are built
+	reservation := r.client.newTorrentReservation()
+	defer reservation.release()
+
+	selectedTorrent, err := r.client.AddTorrent(opts.Magnet, reservation)
 	if err != nil {
 		r.logger.Error().Err(err).Msgf("torrentstream: Error adding torrent %s", opts.Magnet)
 		return nil, err
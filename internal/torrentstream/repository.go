@@ -15,6 +15,7 @@ import (
 	hibiketorrent "seanime/internal/extension/hibike/torrent"
 	"seanime/internal/library/playbackmanager"
 	"seanime/internal/mediaplayers/mediaplayer"
+	"seanime/internal/mediastream"
 	"seanime/internal/platforms/platform"
 	"seanime/internal/torrents/torrent"
 	"seanime/internal/util"
@@ -41,6 +42,7 @@ type (
 		playbackManager                 *playbackmanager.PlaybackManager
 		mediaPlayerRepository           *mediaplayer.Repository
 		mediaPlayerRepositorySubscriber *mediaplayer.RepositorySubscriber
+		mediastreamRepository           *mediastream.Repository
 		logger                          *zerolog.Logger
 		db                              *db.Database
 	}
@@ -62,6 +64,8 @@ type (
 		PlaybackManager    *playbackmanager.PlaybackManager
 		WSEventManager     events.WSEventManagerInterface
 		Database           *db.Database
+		// Used to transcode streams played in the browser
+		MediastreamRepository *mediastream.Repository
 	}
 )
 
@@ -82,6 +86,7 @@ func NewRepository(opts *NewRepositoryOptions) *Repository {
 		playbackManager:                 opts.PlaybackManager,
 		mediaPlayerRepository:           nil,
 		mediaPlayerRepositorySubscriber: nil,
+		mediastreamRepository:           opts.MediastreamRepository,
 		logger:                          opts.Logger,
 		db:                              opts.Database,
 	}
@@ -71,18 +71,35 @@ func (s *serverManager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.lastUsed = time.Now()
 	s.repository.logger.Trace().Str("range", r.Header.Get("Range")).Msg("torrentstream: Stream endpoint hit")
 
-	if s.repository.client.currentFile.IsAbsent() || s.repository.client.currentTorrent.IsAbsent() {
+	// Find the session from the URL, e.g. /stream/{sessionId}/{filename}
+	// Legacy URLs without a session ID are served by the last session that was started
+	var session *StreamSession
+	var found bool
+	if sessionId := getSessionIdFromPath(r.URL.Path); sessionId != "" {
+		// The session was stopped, don't serve another session's file
+		session, found = s.repository.client.getSession(sessionId)
+		if !found {
+			s.repository.logger.Debug().Str("sessionId", sessionId).Msg("torrentstream: Stream session not found")
+			http.Error(w, "Stream session not found", http.StatusNotFound)
+			return
+		}
+	} else {
+		session, found = s.repository.client.getCurrentSession().Get()
+	}
+	if !found {
 		s.repository.logger.Error().Msg("torrentstream: No torrent to stream")
 		http.Error(w, "No torrent to stream", http.StatusNotFound)
 		return
 	}
 
-	file := s.repository.client.currentFile.MustGet()
-	s.repository.logger.Trace().Str("file", file.DisplayPath()).Msg("torrentstream: New reader")
+	file := session.file
+	s.repository.logger.Trace().Str("sessionId", session.ID).Str("file", file.DisplayPath()).Msg("torrentstream: New reader")
 	tr := file.NewReader()
+	session.readers.Add(1)
 	defer func(tr torrent.Reader) {
 		s.repository.logger.Trace().Msg("torrentstream: Closing reader")
 		_ = tr.Close()
+		session.readers.Add(-1)
 	}(tr)
 
 	tr.SetResponsive()
@@ -92,10 +109,9 @@ func (s *serverManager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// If this is a range request for a later part of the file, prioritize those pieces
 	rangeHeader := r.Header.Get("Range")
-	if rangeHeader != "" && s.repository.client.currentTorrent.IsPresent() {
-		t := s.repository.client.currentTorrent.MustGet()
+	if rangeHeader != "" {
 		// Attempt to prioritize the pieces requested in the range
-		s.prioritizeRangeRequestPieces(rangeHeader, file, t)
+		s.prioritizeRangeRequestPieces(rangeHeader, file, session.torrent)
 	}
 
 	s.repository.logger.Trace().Str("file", file.DisplayPath()).Msg("torrentstream: Serving file content")
@@ -0,0 +1,350 @@
+package torrentstream
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/anacrolix/torrent"
+	"github.com/google/uuid"
+	"github.com/samber/mo"
+)
+
+const (
+	// defaultMaxConcurrentSessions is used when the setting is not set
+	defaultMaxConcurrentSessions = 3
+)
+
+var (
+	ErrSessionNotFound = errors.New("torrentstream: Stream session not found")
+	ErrTooManySessions = errors.New("torrentstream: Too many streams running at the same time, stop one before starting another")
+)
+
+type (
+	// StreamSession is a stream started by a client.
+	// Each session has its own torrent and file, so that different episodes can be streamed at the same time.
+	StreamSession struct {
+		ID            string        `json:"id"`
+		ClientId      string        `json:"clientId"`
+		MediaId       int           `json:"mediaId"`
+		EpisodeNumber int           `json:"episodeNumber"`
+		PlaybackType  PlaybackType  `json:"playbackType"`
+		Filename      string        `json:"filename"`
+		StartedAt     time.Time     `json:"startedAt"`
+		Readers       int32         `json:"readers"` // Number of open readers, only set in snapshots
+		Status        TorrentStatus `json:"status"`
+
+		torrent *torrent.Torrent
+		file    *torrent.File
+		readers atomic.Int32
+
+		// Used to calculate the speeds
+		lastSpeedCheck       time.Time
+		lastBytesCompleted   int64
+		lastBytesWrittenData int64
+	}
+
+	// SessionStatus is sent with the per-session status event
+	SessionStatus struct {
+		SessionId string        `json:"sessionId"`
+		Status    TorrentStatus `json:"status"`
+	}
+)
+
+// snapshot returns a copy of the session that can be safely serialized.
+// The caller should hold the client's lock.
+func (s *StreamSession) snapshot() *StreamSession {
+	return &StreamSession{
+		ID:            s.ID,
+		ClientId:      s.ClientId,
+		MediaId:       s.MediaId,
+		EpisodeNumber: s.EpisodeNumber,
+		PlaybackType:  s.PlaybackType,
+		Filename:      s.Filename,
+		StartedAt:     s.StartedAt,
+		Readers:       s.readers.Load(),
+		Status:        s.Status,
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (c *Client) maxSessions() int {
+	if settings, ok := c.repository.settings.Get(); ok && settings.MaxConcurrentSessions > 0 {
+		return settings.MaxConcurrentSessions
+	}
+	return defaultMaxConcurrentSessions
+}
+
+// canAddSession returns an error if the maximum number of sessions is reached.
+func (c *Client) canAddSession() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.sessions.Keys()) >= c.maxSessions() {
+		return ErrTooManySessions
+	}
+	return nil
+}
+
+// addSession registers a new session for the torrent and file and makes it the current session.
+func (c *Client) addSession(opts *StartStreamOptions, t *torrent.Torrent, f *torrent.File) (*StreamSession, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Checked again since another stream might have started while the torrent was being selected
+	if len(c.sessions.Keys()) >= c.maxSessions() {
+		return nil, ErrTooManySessions
+	}
+
+	session := &StreamSession{
+		ID:            uuid.NewString(),
+		ClientId:      opts.ClientId,
+		MediaId:       opts.MediaId,
+		EpisodeNumber: opts.EpisodeNumber,
+		PlaybackType:  opts.PlaybackType,
+		Filename:      f.DisplayPath(),
+		StartedAt:     time.Now(),
+		torrent:       t,
+		file:          f,
+	}
+	c.sessions.Set(session.ID, session)
+	c.currentSessionId = mo.Some(session.ID)
+
+	c.repository.logger.Debug().Str("sessionId", session.ID).Str("file", session.Filename).Msg("torrentstream: Session created")
+
+	return session, nil
+}
+
+// getSession returns the session with the given ID.
+func (c *Client) getSession(id string) (*StreamSession, bool) {
+	return c.sessions.Get(id)
+}
+
+// getCurrentSession returns the last session that was started, if it is still running.
+func (c *Client) getCurrentSession() mo.Option[*StreamSession] {
+	id, ok := c.currentSessionId.Get()
+	if !ok {
+		return mo.None[*StreamSession]()
+	}
+	session, ok := c.sessions.Get(id)
+	if !ok {
+		return mo.None[*StreamSession]()
+	}
+	return mo.Some(session)
+}
+
+// getClientSession returns the last session started by the client.
+// Sessions that were not started by a client (e.g. playlists) are shared, they are returned if the client has no session.
+// The caller should hold the client's lock.
+func (c *Client) getClientSession(clientId string) (*StreamSession, bool) {
+	var ret *StreamSession
+	for _, s := range c.sessions.Values() {
+		if s.ClientId != clientId && s.ClientId != "" {
+			continue
+		}
+		// Prefer the client's own sessions over shared ones, then the most recent one
+		if ret == nil ||
+			(ret.ClientId == "" && s.ClientId != "") ||
+			(ret.ClientId == s.ClientId && s.StartedAt.After(ret.StartedAt)) {
+			ret = s
+		}
+	}
+	return ret, ret != nil
+}
+
+// findSession returns the first session matching the predicate.
+func (c *Client) findSession(predicate func(s *StreamSession) bool) (*StreamSession, bool) {
+	var ret *StreamSession
+	c.sessions.Range(func(_ string, s *StreamSession) bool {
+		if predicate(s) {
+			ret = s
+			return false
+		}
+		return true
+	})
+	return ret, ret != nil
+}
+
+// getMediaPlayerSession returns the session being played by the media player.
+// There can only be one since the media player is shared.
+func (c *Client) getMediaPlayerSession() (*StreamSession, bool) {
+	return c.findSession(func(s *StreamSession) bool {
+		return s.PlaybackType == PlaybackTypeDefault
+	})
+}
+
+// isTorrentInUse returns true if a session other than the excluded one streams from the torrent,
+// or if a request is still preparing it.
+// The caller should hold the client's lock.
+func (c *Client) isTorrentInUse(t *torrent.Torrent, excludedId string) bool {
+	if c.reserved[t.InfoHash().HexString()] > 0 {
+		return true
+	}
+	_, found := c.findSession(func(s *StreamSession) bool {
+		return s.ID != excludedId && s.torrent.InfoHash() == t.InfoHash()
+	})
+	return found
+}
+
+// torrentReservation holds the torrents added by a request (e.g. stream, previews) before a session uses them.
+// Reserved torrents are not dropped when other torrents are added or sessions are stopped.
+type torrentReservation struct {
+	client     *Client
+	infoHashes []string
+}
+
+func (c *Client) newTorrentReservation() *torrentReservation {
+	return &torrentReservation{client: c}
+}
+
+// reserve marks the torrent as being prepared.
+// The caller should hold the client's lock.
+func (tr *torrentReservation) reserve(infoHash string) {
+	tr.client.reserved[infoHash]++
+	tr.infoHashes = append(tr.infoHashes, infoHash)
+}
+
+// unreserve removes one reservation of the torrent.
+// The caller should hold the client's lock.
+func (tr *torrentReservation) unreserve(infoHash string) {
+	for i, h := range tr.infoHashes {
+		if h != infoHash {
+			continue
+		}
+		tr.infoHashes = append(tr.infoHashes[:i], tr.infoHashes[i+1:]...)
+		tr.client.reserved[infoHash]--
+		if tr.client.reserved[infoHash] <= 0 {
+			delete(tr.client.reserved, infoHash)
+		}
+		return
+	}
+}
+
+// remove releases the torrent and drops it if no other request or session uses it.
+func (tr *torrentReservation) remove(t *torrent.Torrent) {
+	tr.client.mu.Lock()
+	defer tr.client.mu.Unlock()
+
+	tr.unreserve(t.InfoHash().HexString())
+	if c := tr.client; !c.isTorrentInUse(t, "") {
+		t.Drop()
+		c.repository.logger.Debug().Msgf("torrentstream: Removed torrent: %s", t.InfoHash().HexString())
+	}
+}
+
+// release releases all the torrents held by the reservation.
+// This should be called once the torrents are used by a session or are no longer needed.
+func (tr *torrentReservation) release() {
+	tr.client.mu.Lock()
+	defer tr.client.mu.Unlock()
+
+	for len(tr.infoHashes) > 0 {
+		tr.unreserve(tr.infoHashes[0])
+	}
+}
+
+// removeSession removes the session and drops its torrent if it is not used by another session.
+// The caller should hold the client's lock.
+func (c *Client) removeSession(session *StreamSession, dropTorrent bool) {
+	c.sessions.Delete(session.ID)
+	if id, ok := c.currentSessionId.Get(); ok && id == session.ID {
+		c.currentSessionId = mo.None[string]()
+	}
+
+	if !dropTorrent || c.isTorrentInUse(session.torrent, session.ID) {
+		return
+	}
+
+	infoHash := session.torrent.InfoHash()
+	session.torrent.Drop()
+	c.repository.logger.Debug().Str("sessionId", session.ID).Msgf("torrentstream: Dropped torrent %s", infoHash.HexString())
+
+	// Delete the downloaded data
+	// e.g. /path/to/temp/seanime/torrentstream/{infohash}
+	if settings, ok := c.repository.settings.Get(); ok && settings.DownloadDir != "" {
+		_ = os.RemoveAll(filepath.Join(settings.DownloadDir, infoHash.HexString()))
+	}
+}
+
+// ListSessions returns a snapshot of the running sessions, oldest first.
+func (r *Repository) ListSessions() []*StreamSession {
+	r.client.mu.Lock()
+	defer r.client.mu.Unlock()
+
+	ret := make([]*StreamSession, 0)
+	for _, s := range r.client.sessions.Values() {
+		ret = append(ret, s.snapshot())
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].StartedAt.Before(ret[j].StartedAt)
+	})
+	return ret
+}
+
+// StopSession stops the session and drops its torrent if it's below a threshold.
+func (r *Repository) StopSession(id string) error {
+	r.client.mu.Lock()
+	session, ok := r.client.getSession(id)
+	if !ok {
+		r.client.mu.Unlock()
+		return ErrSessionNotFound
+	}
+
+	r.logger.Info().Str("sessionId", id).Msg("torrentstream: Stopping session")
+
+	// This is to prevent the client from downloading the whole torrent when the user stops watching
+	// Also, the torrent might be a batch - so we don't want to download the whole thing
+	dropTorrent := session.Status.ProgressPercentage < 70
+	if dropTorrent {
+		r.logger.Debug().Str("sessionId", id).Msg("torrentstream: Dropping torrent, completion is less than 70%")
+	}
+	r.client.removeSession(session, dropTorrent)
+	noSessionsLeft := len(r.client.sessions.Keys()) == 0
+	r.client.mu.Unlock()
+
+	settings, ok := r.settings.Get()
+	if ok && settings.UseSeparateServer && noSessionsLeft {
+		r.serverManager.stopServer() // Stop the server
+	}
+	r.sendSessionEvent(session.ClientId, eventTorrentStopped, nil) // Send torrent stopped event
+	if session.PlaybackType == PlaybackTypeDefault && r.mediaPlayerRepository != nil {
+		r.mediaPlayerRepository.Stop() // Stop the media player gracefully if it's running
+	}
+	r.stopTranscodeStream(session) // Stop the transcoder if the session is played in the browser
+
+	r.logger.Info().Str("sessionId", id).Msg("torrentstream: Session stopped")
+	return nil
+}
+
+// sendSessionEvent sends the event to the client that started the session.
+// The event is sent to all clients if the session was not started by a specific client.
+func (r *Repository) sendSessionEvent(clientId string, t string, payload interface{}) {
+	if clientId == "" {
+		r.wsEventManager.SendEvent(t, payload)
+		return
+	}
+	r.wsEventManager.SendEventTo(clientId, t, payload)
+}
+
+// getSessionIdFromPath returns the session ID from a stream URL path, or an empty string for legacy URLs.
+// e.g. /api/v1/torrentstream/stream/{sessionId}/{filename} or /stream/{sessionId}/{filename}
+func getSessionIdFromPath(p string) string {
+	idx := strings.Index(p, "/stream/")
+	if idx == -1 {
+		return ""
+	}
+	rest := p[idx+len("/stream/"):]
+	sessionId, _, found := strings.Cut(rest, "/")
+	if !found {
+		return ""
+	}
+	// Legacy URLs can have directories in the filename
+	if _, err := uuid.Parse(sessionId); err != nil {
+		return ""
+	}
+	return sessionId
+}
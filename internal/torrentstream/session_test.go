@@ -0,0 +1,116 @@
+package torrentstream
+
+import (
+	"seanime/internal/database/models"
+	"seanime/internal/events"
+	"seanime/internal/util"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSessionIdFromPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/api/v1/torrentstream/stream/0b8f7f8e-1d1c-4a7e-9d4b-2f6a1c3e5d7f/Show - 01.mkv", "0b8f7f8e-1d1c-4a7e-9d4b-2f6a1c3e5d7f"},
+		{"/stream/0b8f7f8e-1d1c-4a7e-9d4b-2f6a1c3e5d7f/Show - 01.mkv", "0b8f7f8e-1d1c-4a7e-9d4b-2f6a1c3e5d7f"},
+		// URLs without a session ID
+		{"/api/v1/torrentstream/stream/Show - 01.mkv", ""},
+		{"/api/v1/torrentstream/sessions", ""},
+		{"/api/v1/torrentstream/stream/Batch/Show - 01.mkv", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			require.Equal(t, tt.expected, getSessionIdFromPath(tt.path))
+		})
+	}
+}
+
+func TestClient_CanAddSession(t *testing.T) {
+	logger := util.NewLogger()
+	repo := NewRepository(&NewRepositoryOptions{
+		Logger:         logger,
+		WSEventManager: events.NewMockWSEventManager(logger),
+	})
+
+	// Default cap
+	for i := 0; i < defaultMaxConcurrentSessions; i++ {
+		require.NoError(t, repo.client.canAddSession())
+		repo.client.sessions.Set(strconv.Itoa(i), &StreamSession{})
+	}
+	require.ErrorIs(t, repo.client.canAddSession(), ErrTooManySessions)
+
+	// Cap from the settings
+	repo.settings = mo.Some(Settings{
+		TorrentstreamSettings: models.TorrentstreamSettings{MaxConcurrentSessions: 4},
+	})
+	require.NoError(t, repo.client.canAddSession())
+}
+
+func TestClient_GetClientSession(t *testing.T) {
+	logger := util.NewLogger()
+	repo := NewRepository(&NewRepositoryOptions{
+		Logger:         logger,
+		WSEventManager: events.NewMockWSEventManager(logger),
+	})
+
+	now := time.Now()
+	repo.client.sessions.Set("a1", &StreamSession{ID: "a1", ClientId: "a", StartedAt: now.Add(-2 * time.Minute)})
+	repo.client.sessions.Set("a2", &StreamSession{ID: "a2", ClientId: "a", StartedAt: now.Add(-time.Minute)})
+	repo.client.sessions.Set("b1", &StreamSession{ID: "b1", ClientId: "b", StartedAt: now})
+	repo.client.sessions.Set("shared", &StreamSession{ID: "shared", StartedAt: now})
+
+	// The client's last session is returned, not the last session that was started
+	session, ok := repo.client.getClientSession("a")
+	require.True(t, ok)
+	require.Equal(t, "a2", session.ID)
+
+	session, ok = repo.client.getClientSession("b")
+	require.True(t, ok)
+	require.Equal(t, "b1", session.ID)
+
+	// Shared sessions are returned to clients without a session
+	session, ok = repo.client.getClientSession("c")
+	require.True(t, ok)
+	require.Equal(t, "shared", session.ID)
+
+	repo.client.sessions.Delete("shared")
+	_, ok = repo.client.getClientSession("c")
+	require.False(t, ok)
+}
+
+func TestTorrentReservation(t *testing.T) {
+	logger := util.NewLogger()
+	repo := NewRepository(&NewRepositoryOptions{
+		Logger:         logger,
+		WSEventManager: events.NewMockWSEventManager(logger),
+	})
+
+	// Two requests prepare the same torrent
+	r1 := repo.client.newTorrentReservation()
+	r2 := repo.client.newTorrentReservation()
+	repo.client.mu.Lock()
+	r1.reserve("hash")
+	r1.reserve("other")
+	r2.reserve("hash")
+	repo.client.mu.Unlock()
+	require.Equal(t, 2, repo.client.reserved["hash"])
+
+	// The torrent stays reserved until both requests release it
+	r1.release()
+	require.Equal(t, 1, repo.client.reserved["hash"])
+	require.NotContains(t, repo.client.reserved, "other")
+
+	r2.release()
+	require.Empty(t, repo.client.reserved)
+
+	// Releasing twice is a no-op
+	r2.release()
+	require.Empty(t, repo.client.reserved)
+}
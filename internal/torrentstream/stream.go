@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"seanime/internal/api/anilist"
 	"seanime/internal/api/metadata"
+	"seanime/internal/continuity"
 	"seanime/internal/events"
 	hibiketorrent "seanime/internal/extension/hibike/torrent"
 	"seanime/internal/hook"
@@ -11,9 +12,6 @@ import (
 	"seanime/internal/util"
 	"strconv"
 	"time"
-
-	"github.com/anacrolix/torrent"
-	"github.com/samber/mo"
 )
 
 type PlaybackType string
@@ -21,6 +19,7 @@ type PlaybackType string
 const (
 	PlaybackTypeDefault        PlaybackType = "default"
 	PlaybackTypeExternalPlayer PlaybackType = "externalPlayerLink"
+	PlaybackTypeTranscode      PlaybackType = "transcode" // Transcoded by the mediastream module and played in the browser
 )
 
 type StartStreamOptions struct {
@@ -46,7 +45,29 @@ func (r *Repository) StartStream(opts *StartStreamOptions) (err error) {
 		Any("playbackType", opts.PlaybackType).
 		Int("mediaId", opts.MediaId).Msgf("torrentstream: Starting stream for episode %s", opts.AniDBEpisode)
 
-	r.wsEventManager.SendEvent(eventTorrentLoading, nil)
+	//
+	// Make room for the new session
+	//
+	// A client can only watch one stream at a time
+	if opts.ClientId != "" {
+		if session, found := r.client.findSession(func(s *StreamSession) bool { return s.ClientId == opts.ClientId }); found {
+			r.logger.Debug().Str("sessionId", session.ID).Msg("torrentstream: Stopping previous session of the client")
+			_ = r.StopSession(session.ID)
+		}
+	}
+	// The media player can only play one stream at a time
+	if opts.PlaybackType == PlaybackTypeDefault {
+		if session, found := r.client.getMediaPlayerSession(); found {
+			r.logger.Debug().Str("sessionId", session.ID).Msg("torrentstream: Stopping previous media player session")
+			_ = r.StopSession(session.ID)
+		}
+	}
+	if err = r.client.canAddSession(); err != nil {
+		r.sendSessionEvent(opts.ClientId, eventTorrentLoadingFailed, nil)
+		return err
+	}
+
+	r.sendSessionEvent(opts.ClientId, eventTorrentLoading, nil)
 
 	//
 	// Get the media info
@@ -62,35 +83,42 @@ func (r *Repository) StartStream(opts *StartStreamOptions) (err error) {
 	//
 	// Find the best torrent / Select the torrent
 	//
+	// The torrents are reserved until the session is created, so that other streams don't drop them
+	reservation := r.client.newTorrentReservation()
+	defer reservation.release()
+
 	var torrentToStream *playbackTorrent
 	switch opts.AutoSelect {
 	case true:
-		torrentToStream, err = r.findBestTorrent(media, aniDbEpisode, episodeNumber)
+		torrentToStream, err = r.findBestTorrent(media, aniDbEpisode, episodeNumber, reservation)
 		if err != nil {
-			r.wsEventManager.SendEvent(eventTorrentLoadingFailed, nil)
+			r.sendSessionEvent(opts.ClientId, eventTorrentLoadingFailed, nil)
 			return err
 		}
 	case false:
 		if opts.Torrent == nil {
 			return fmt.Errorf("torrentstream: No torrent provided")
 		}
-		torrentToStream, err = r.findBestTorrentFromManualSelection(opts.Torrent, media, aniDbEpisode, opts.FileIndex)
+		torrentToStream, err = r.findBestTorrentFromManualSelection(opts.Torrent, media, aniDbEpisode, opts.FileIndex, reservation)
 		if err != nil {
-			r.wsEventManager.SendEvent(eventTorrentLoadingFailed, nil)
+			r.sendSessionEvent(opts.ClientId, eventTorrentLoadingFailed, nil)
 			return err
 		}
 	}
 
 	if torrentToStream == nil {
-		r.wsEventManager.SendEvent(eventTorrentLoadingFailed, nil)
+		r.sendSessionEvent(opts.ClientId, eventTorrentLoadingFailed, nil)
 		return fmt.Errorf("torrentstream: No torrent selected")
 	}
 
 	//
-	// Set current file & torrent
+	// Create the session
 	//
-	r.client.currentFile = mo.Some(torrentToStream.File)
-	r.client.currentTorrent = mo.Some(torrentToStream.Torrent)
+	session, err := r.client.addSession(opts, torrentToStream.Torrent, torrentToStream.File)
+	if err != nil {
+		r.sendSessionEvent(opts.ClientId, eventTorrentLoadingFailed, nil)
+		return err
+	}
 
 	r.sendTorrentLoadingStatus(TLSStateStartingServer, "")
 
@@ -106,17 +134,17 @@ func (r *Repository) StartStream(opts *StartStreamOptions) (err error) {
 
 	go func() {
 		// Add the torrent to the history if it is a batch & manually selected
-		if len(r.client.currentTorrent.MustGet().Files()) > 1 && opts.Torrent != nil {
+		if len(session.torrent.Files()) > 1 && opts.Torrent != nil {
 			r.AddBatchHistory(opts.MediaId, opts.Torrent) // ran in goroutine
 		}
 
 		for {
 			// This is to make sure the client is ready to stream before we start the stream
-			if r.client.readyToStream() {
+			if r.client.readyToStream(session) {
 				break
 			}
-			// If for some reason the torrent is dropped, we kill the goroutine
-			if r.client.torrentClient.IsAbsent() || r.client.currentTorrent.IsAbsent() {
+			// If for some reason the session is stopped, we kill the goroutine
+			if _, found := r.client.getSession(session.ID); r.client.torrentClient.IsAbsent() || !found {
 				return
 			}
 			r.logger.Debug().Msg("torrentstream: Waiting for playable threshold to be reached")
@@ -125,7 +153,7 @@ func (r *Repository) StartStream(opts *StartStreamOptions) (err error) {
 
 		event := &TorrentStreamSendStreamToMediaPlayerEvent{
 			WindowTitle:  "",
-			StreamURL:    r.client.GetStreamingUrl(),
+			StreamURL:    r.client.GetSessionStreamingUrl(session.ID),
 			Media:        media.ToBaseAnime(),
 			AniDbEpisode: aniDbEpisode,
 			PlaybackType: string(opts.PlaybackType),
@@ -153,14 +181,15 @@ func (r *Repository) StartStream(opts *StartStreamOptions) (err error) {
 			//
 			r.logger.Debug().Msg("torrentstream: Starting the media player")
 			err = r.playbackManager.StartStreamingUsingMediaPlayer(windowTitle, &playbackmanager.StartPlayingOptions{
-				Payload:   streamURL,
-				UserAgent: opts.UserAgent,
-				ClientId:  opts.ClientId,
+				Payload:    streamURL,
+				UserAgent:  opts.UserAgent,
+				ClientId:   opts.ClientId,
+				StreamKind: continuity.TorrentstreamKind,
 			}, media, aniDbEpisode)
 			if err != nil {
-				// Failed to start the stream, we'll drop the torrents and stop the server
-				r.wsEventManager.SendEvent(eventTorrentLoadingFailed, nil)
-				_ = r.StopStream()
+				// Failed to start the stream, we'll drop the torrent and stop the server
+				r.sendSessionEvent(opts.ClientId, eventTorrentLoadingFailed, nil)
+				_ = r.StopSession(session.ID)
 				r.logger.Error().Err(err).Msg("torrentstream: Failed to start the stream")
 			}
 
@@ -171,55 +200,59 @@ func (r *Repository) StartStream(opts *StartStreamOptions) (err error) {
 				MediaId       int    `json:"mediaId"`
 				EpisodeNumber int    `json:"episodeNumber"`
 			}{
-				Url:           r.client.GetStreamingUrl(),
+				Url:           r.client.GetSessionStreamingUrl(session.ID),
 				MediaId:       opts.MediaId,
 				EpisodeNumber: opts.EpisodeNumber,
 			})
 
 			// Signal to the client that the torrent has started playing (remove loading status)
 			// We can't know for sure
-			r.wsEventManager.SendEvent(eventTorrentStartedPlaying, nil)
+			r.sendSessionEvent(opts.ClientId, eventTorrentStartedPlaying, nil)
+
+		case PlaybackTypeTranscode:
+			//
+			// Transcode the stream
+			//
+			r.logger.Debug().Msg("torrentstream: Sending the stream to the transcoder")
+			err = r.startTranscodeStream(session, streamURL)
+			if err != nil {
+				r.sendSessionEvent(opts.ClientId, eventTorrentLoadingFailed, nil)
+				_ = r.StopSession(session.ID)
+				r.logger.Error().Err(err).Msg("torrentstream: Failed to transcode the stream")
+				return
+			}
+			r.sendSessionEvent(opts.ClientId, eventTorrentStartedPlaying, nil)
 		}
 	}()
 
-	r.wsEventManager.SendEvent(eventTorrentLoaded, nil)
-	r.logger.Info().Msg("torrentstream: Stream started")
+	r.sendSessionEvent(opts.ClientId, eventTorrentLoaded, nil)
+	r.logger.Info().Str("sessionId", session.ID).Msg("torrentstream: Stream started")
 
 	return nil
 }
 
-func (r *Repository) StopStream() error {
+// StopStream stops the last session started by the client.
+// Sessions started by other clients are not affected.
+func (r *Repository) StopStream(clientId string) error {
 	defer func() {
 		if r := recover(); r != nil {
 		}
 	}()
-	r.logger.Info().Msg("torrentstream: Stopping stream")
+	r.logger.Info().Str("clientId", clientId).Msg("torrentstream: Stopping stream")
 
-	// Stop the client
-	// This will stop the stream and close the server
-	// This also sends the eventTorrentStopped event
 	r.client.mu.Lock()
-	//r.client.stopCh = make(chan struct{})
-	r.client.repository.logger.Debug().Msg("torrentstream: Handling media player stopped event")
-	// This is to prevent the client from downloading the whole torrent when the user stops watching
-	// Also, the torrent might be a batch - so we don't want to download the whole thing
-	if r.client.currentTorrent.IsPresent() {
-		if r.client.currentTorrentStatus.ProgressPercentage < 70 {
-			r.client.repository.logger.Debug().Msg("torrentstream: Dropping torrent, completion is less than 70%")
-			r.client.dropTorrents()
-		}
-		r.client.repository.logger.Debug().Msg("torrentstream: Resetting current torrent and status")
+	session, ok := r.client.getClientSession(clientId)
+	r.client.mu.Unlock()
+	if !ok {
+		// Nothing to stop, reset the client state
+		r.sendSessionEvent(clientId, eventTorrentStopped, nil)
+		return nil
 	}
-	r.client.currentTorrent = mo.None[*torrent.Torrent]() // Reset the current torrent
-	r.client.currentFile = mo.None[*torrent.File]()       // Reset the current file
-	r.client.currentTorrentStatus = TorrentStatus{}       // Reset the torrent status
-	settings, ok := r.client.repository.settings.Get()
-	if ok && settings.UseSeparateServer {
-		r.client.repository.serverManager.stopServer() // Stop the server
+
+	err := r.StopSession(session.ID)
+	if err != nil {
+		return err
 	}
-	r.client.repository.wsEventManager.SendEvent(eventTorrentStopped, nil) // Send torrent stopped event
-	r.client.repository.mediaPlayerRepository.Stop()                       // Stop the media player gracefully if it's running
-	r.client.mu.Unlock()
 
 	r.logger.Info().Msg("torrentstream: Stream stopped")
 
@@ -243,25 +276,42 @@ func (r *Repository) StopStream() error {
 //	return nil
 //}
 
-func (r *Repository) DropTorrent() error {
-	r.logger.Info().Msg("torrentstream: Dropping last torrent")
+// DropTorrent stops the sessions started by the client and drops their torrents.
+// Torrents that are no longer streamed (e.g. seeding) are also dropped, torrents used by other clients are kept.
+func (r *Repository) DropTorrent(clientId string) error {
+	r.logger.Info().Str("clientId", clientId).Msg("torrentstream: Dropping torrents")
 
 	if r.client.torrentClient.IsAbsent() {
 		return nil
 	}
 
-	for _, t := range r.client.torrentClient.MustGet().Torrents() {
-		t.Drop()
+	r.client.mu.Lock()
+	stopped := make([]*StreamSession, 0)
+	for _, session := range r.client.sessions.Values() {
+		if session.ClientId != clientId && session.ClientId != "" {
+			continue
+		}
+		r.client.removeSession(session, true)
+		stopped = append(stopped, session)
 	}
+	r.client.dropTorrents()
+	noSessionsLeft := len(r.client.sessions.Keys()) == 0
+	r.client.mu.Unlock()
 
 	// Also stop the server, since it's dropped
 	settings, ok := r.settings.Get()
-	if ok && settings.UseSeparateServer {
+	if ok && settings.UseSeparateServer && noSessionsLeft {
 		r.serverManager.stopServer()
 	}
-	r.mediaPlayerRepository.Stop()
+	for _, session := range stopped {
+		r.sendSessionEvent(session.ClientId, eventTorrentStopped, nil)
+		if session.PlaybackType == PlaybackTypeDefault && r.mediaPlayerRepository != nil {
+			r.mediaPlayerRepository.Stop()
+		}
+		r.stopTranscodeStream(session)
+	}
 
-	r.logger.Info().Msg("torrentstream: Dropped last torrent")
+	r.logger.Info().Int("sessions", len(stopped)).Msg("torrentstream: Dropped torrents")
 
 	return nil
 }
@@ -0,0 +1,35 @@
+package torrentstream
+
+import (
+	"errors"
+	"fmt"
+	"seanime/internal/mediastream"
+)
+
+// startTranscodeStream sends the session's file to the transcoder so that it can be watched in the browser.
+// The transcoder reads the file from the streaming server like a media player would.
+func (r *Repository) startTranscodeStream(session *StreamSession, streamUrl string) error {
+	if r.mediastreamRepository == nil || !r.mediastreamRepository.IsInitialized() {
+		return errors.New("torrentstream: Media streaming is not enabled, cannot transcode the stream")
+	}
+
+	return r.mediastreamRepository.StartSourceTranscode(&mediastream.StreamSource{
+		ID:            getTranscodeSourceId(session),
+		Url:           streamUrl,
+		MediaId:       session.MediaId,
+		EpisodeNumber: session.EpisodeNumber,
+	}, session.ClientId)
+}
+
+// stopTranscodeStream stops the transcoder if it is transcoding the session's file.
+func (r *Repository) stopTranscodeStream(session *StreamSession) {
+	if session.PlaybackType != PlaybackTypeTranscode || r.mediastreamRepository == nil {
+		return
+	}
+	r.mediastreamRepository.ShutdownSourceTranscodeStream(getTranscodeSourceId(session), session.ClientId)
+}
+
+// getTranscodeSourceId returns the ID of the session's file in the transcoder.
+func getTranscodeSourceId(session *StreamSession) string {
+	return fmt.Sprintf("torrentstream:%s:%s", session.torrent.InfoHash().HexString(), session.file.Path())
+}
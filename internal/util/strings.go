@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"html"
 	"math/big"
 	"path/filepath"
 	"regexp"
@@ -256,3 +257,13 @@ func RandomStringWithAlphabet(length int, alphabet string) string {
 
 	return string(b)
 }
+
+var htmlTagRegex = regexp.MustCompile(`<[^>]*>`)
+
+// HTMLToPlainText removes the HTML tags of a description (e.g. AniList descriptions) and unescapes its entities.
+// Line breaks are kept.
+func HTMLToPlainText(s string) string {
+	s = strings.ReplaceAll(s, "<br>", "\n")
+	s = htmlTagRegex.ReplaceAllString(s, "")
+	return strings.TrimSpace(html.UnescapeString(s))
+}
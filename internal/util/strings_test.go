@@ -53,3 +53,22 @@ func TestIsBase64Encoded(t *testing.T) {
 		}
 	}
 }
+
+func TestHTMLToPlainText(t *testing.T) {
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Line 1<br>Line 2", "Line 1\nLine 2"},
+		{"<i>Tom &amp; Jerry</i> ", "Tom & Jerry"},
+		{"(Source: <a href=\"https://example.com\">Example</a>)", "(Source: Example)"},
+	}
+
+	for _, test := range tests {
+		if ret := HTMLToPlainText(test.input); ret != test.expected {
+			t.Errorf("Expected %q, got %q", test.expected, ret)
+		}
+	}
+
+}
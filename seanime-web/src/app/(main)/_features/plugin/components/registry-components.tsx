@@ -1,13 +1,21 @@
 import { RenderPluginComponents } from "@/app/(main)/_features/plugin/components/registry"
 import { useWebsocketSender } from "@/app/(main)/_hooks/handle-websockets"
+import { SeaLink } from "@/components/shared/sea-link"
+import { Badge, BadgeProps } from "@/components/ui/badge"
 import { Button, ButtonProps } from "@/components/ui/button"
 import { Checkbox } from "@/components/ui/checkbox"
 import { cn } from "@/components/ui/core/styling"
 import { DatePicker } from "@/components/ui/date-picker"
 import { LoadingSpinner } from "@/components/ui/loading-spinner"
+import { Modal } from "@/components/ui/modal"
+import { Pagination, PaginationEllipsis, PaginationItem, PaginationTrigger } from "@/components/ui/pagination"
+import { ProgressBar } from "@/components/ui/progress-bar"
 import { RadioGroup } from "@/components/ui/radio-group"
 import { Select } from "@/components/ui/select"
+import { Skeleton } from "@/components/ui/skeleton"
 import { Switch } from "@/components/ui/switch"
+import { Table, TableBody, TableCell, TableHead, TableHeader, TableRow } from "@/components/ui/table"
+import { Tabs, TabsContent, TabsList, TabsTrigger } from "@/components/ui/tabs"
 import { TextInput } from "@/components/ui/text-input"
 import { useDebounce } from "@/hooks/use-debounce"
 import React, { useEffect } from "react"
@@ -474,6 +482,415 @@ export function PluginText({ text, style, className }: TextProps) {
     return <p className={cn("w-full break-all", className)} style={style}>{text}</p>
 }
 
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Display
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+interface ImageProps {
+    src: string
+    alt?: string
+    width?: number
+    height?: number
+    fit?: "cover" | "contain" | "fill" | "none"
+    style?: React.CSSProperties
+    className?: string
+}
+
+export function PluginImage({ src, alt, width, height, fit = "cover", style, className }: ImageProps) {
+    return (
+        <img
+            src={src}
+            alt={alt || ""}
+            width={width}
+            height={height}
+            className={cn("max-w-full rounded-md", className)}
+            style={{ objectFit: fit, ...(style || {}) }}
+            loading="lazy"
+        />
+    )
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+interface ProgressProps {
+    value: number
+    max?: number
+    label?: string
+    showValue?: boolean
+    size?: "xs" | "sm" | "md" | "lg" | "xl"
+    style?: React.CSSProperties
+    className?: string
+}
+
+export function PluginProgress({ value, max = 100, label, showValue, size = "md", style, className }: ProgressProps) {
+    const percentage = max > 0 ? Math.round((value / max) * 100) : 0
+
+    return (
+        <div className={cn("w-full space-y-1", className)} style={style}>
+            {(label || showValue) && (
+                <div className="flex items-center justify-between text-sm text-[--muted]">
+                    <span>{label}</span>
+                    {showValue && <span>{value} / {max}</span>}
+                </div>
+            )}
+            <ProgressBar value={percentage} size={size} />
+        </div>
+    )
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+interface BadgeComponentProps {
+    text: string
+    intent?: BadgeProps["intent"]
+    size?: "sm" | "md" | "lg" | "xl"
+    style?: React.CSSProperties
+    className?: string
+}
+
+export function PluginBadge({ text, intent = "gray", size = "md", style, className }: BadgeComponentProps) {
+    return <Badge intent={intent} size={size} style={style} className={className}>{text}</Badge>
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+interface TabsComponentProps {
+    tabs: Array<{
+        value: string
+        label: string
+        items?: any[]
+    }>
+    value?: string
+    onChange?: string
+    style?: React.CSSProperties
+    className?: string
+}
+
+export function PluginTabs(props: TabsComponentProps) {
+    const { sendEventHandlerTriggeredEvent } = usePluginSendEventHandlerTriggeredEvent()
+    const { trayIcon } = usePluginTray()
+    const [value, setValue] = React.useState(props.value)
+
+    useEffect(() => {
+        setValue(props.value)
+    }, [props.value])
+
+    function handleValueChange(value: string) {
+        setValue(value)
+        if (props.onChange) {
+            sendEventHandlerTriggeredEvent({
+                handlerName: props.onChange,
+                event: { value },
+            }, trayIcon.extensionId)
+        }
+    }
+
+    return (
+        <Tabs value={value} onValueChange={handleValueChange} className={props.className} style={props.style}>
+            <TabsList className="h-10">
+                {props.tabs.map(tab => <TabsTrigger key={tab.value} value={tab.value}>{tab.label}</TabsTrigger>)}
+            </TabsList>
+            {props.tabs.map(tab => (
+                <TabsContent key={tab.value} value={tab.value} className="pt-2">
+                    {tab.items && tab.items.length > 0 && <RenderPluginComponents data={tab.items} />}
+                </TabsContent>
+            ))}
+        </Tabs>
+    )
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+interface ModalComponentProps {
+    items?: any[]
+    title?: string
+    description?: string
+    open?: boolean
+    onClose?: string
+    size?: "sm" | "md" | "lg" | "xl"
+    style?: React.CSSProperties
+    className?: string
+}
+
+const modalSizeClass = {
+    sm: "max-w-md",
+    md: "max-w-lg",
+    lg: "max-w-3xl",
+    xl: "max-w-5xl",
+}
+
+export function PluginModal(props: ModalComponentProps) {
+    const { sendEventHandlerTriggeredEvent } = usePluginSendEventHandlerTriggeredEvent()
+    const { trayIcon } = usePluginTray()
+
+    function handleOpenChange(open: boolean) {
+        if (!open && props.onClose) {
+            sendEventHandlerTriggeredEvent({
+                handlerName: props.onClose,
+                event: {},
+            }, trayIcon.extensionId)
+        }
+    }
+
+    return (
+        <Modal
+            open={!!props.open}
+            onOpenChange={handleOpenChange}
+            title={props.title}
+            description={props.description}
+            contentClass={cn(modalSizeClass[props.size || "md"], props.className)}
+        >
+            <div style={props.style}>
+                {props.items && props.items.length > 0 && <RenderPluginComponents data={props.items} />}
+            </div>
+        </Modal>
+    )
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Data
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+interface DataTableProps {
+    columns: Array<{
+        key: string
+        label?: string
+        sortable?: boolean
+    }>
+    // Rows of the current page, sorted by the server
+    rows: Array<Record<string, any>>
+    rowKey?: string
+    page: number
+    pageSize: number
+    totalRows: number
+    totalPages: number
+    sortBy?: string
+    sortDirection?: "asc" | "desc"
+    onPageChange?: string
+    onSortChange?: string
+    onRowClick?: string
+    emptyText?: string
+    style?: React.CSSProperties
+    className?: string
+}
+
+export function PluginDataTable(props: DataTableProps) {
+    const { sendEventHandlerTriggeredEvent } = usePluginSendEventHandlerTriggeredEvent()
+    const { trayIcon } = usePluginTray()
+
+    function trigger(handlerName: string | undefined, event: Record<string, any>) {
+        if (handlerName) {
+            sendEventHandlerTriggeredEvent({ handlerName, event }, trayIcon.extensionId)
+        }
+    }
+
+    function handleSort(key: string) {
+        const sortDirection = props.sortBy === key && props.sortDirection === "asc" ? "desc" : "asc"
+        trigger(props.onSortChange, { sortBy: key, sortDirection })
+    }
+
+    // Show the first, last and surrounding pages
+    const pages = Array.from({ length: props.totalPages }, (_, i) => i + 1)
+        .filter(p => p === 1 || p === props.totalPages || Math.abs(p - props.page) <= 1)
+
+    return (
+        <div className={cn("w-full space-y-2", props.className)} style={props.style}>
+            <Table>
+                <TableHeader>
+                    <TableRow>
+                        {props.columns.map(column => (
+                            <TableHead
+                                key={column.key}
+                                className={cn(column.sortable && props.onSortChange && "cursor-pointer select-none")}
+                                onClick={() => column.sortable && handleSort(column.key)}
+                            >
+                                {column.label ?? column.key}
+                                {props.sortBy === column.key && (props.sortDirection === "desc" ? " ↓" : " ↑")}
+                            </TableHead>
+                        ))}
+                    </TableRow>
+                </TableHeader>
+                <TableBody>
+                    {props.rows.length === 0 && (
+                        <TableRow>
+                            <TableCell colSpan={props.columns.length} className="text-center text-[--muted]">
+                                {props.emptyText || "No data"}
+                            </TableCell>
+                        </TableRow>
+                    )}
+                    {props.rows.map((row, i) => (
+                        <TableRow
+                            key={props.rowKey ? String(row[props.rowKey]) : i}
+                            className={cn(props.onRowClick && "cursor-pointer")}
+                            onClick={() => trigger(props.onRowClick, { row })}
+                        >
+                            {props.columns.map(column => (
+                                <TableCell key={column.key}>{row[column.key] == null ? "" : String(row[column.key])}</TableCell>
+                            ))}
+                        </TableRow>
+                    ))}
+                </TableBody>
+            </Table>
+            {props.totalPages > 1 && (
+                <Pagination>
+                    <PaginationTrigger
+                        direction="previous"
+                        isDisabled={props.page <= 1}
+                        onClick={() => props.page > 1 && trigger(props.onPageChange, { page: props.page - 1 })}
+                    />
+                    {pages.map((p, i) => (
+                        <React.Fragment key={p}>
+                            {i > 0 && p - pages[i - 1] > 1 && <PaginationEllipsis />}
+                            <PaginationItem
+                                value={p}
+                                data-selected={p === props.page}
+                                onClick={() => trigger(props.onPageChange, { page: p })}
+                            />
+                        </React.Fragment>
+                    ))}
+                    <PaginationTrigger
+                        direction="next"
+                        isDisabled={props.page >= props.totalPages}
+                        onClick={() => props.page < props.totalPages && trigger(props.onPageChange, { page: props.page + 1 })}
+                    />
+                </Pagination>
+            )}
+        </div>
+    )
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Media
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type PluginMedia = {
+    id: number
+    type: "anime" | "manga"
+    title: string
+    coverImage?: string
+    bannerImage?: string
+    format?: string
+    status?: string
+    episodes?: number
+    chapters?: number
+    year?: number
+    isAdult?: boolean
+}
+
+function PluginMediaItem({ media, onClick }: { media: PluginMedia, onClick?: () => void }) {
+    const content = (
+        <div className="space-y-1">
+            <div className="aspect-[6/8] w-full overflow-hidden rounded-md bg-[--subtle]">
+                {media.coverImage && <img src={media.coverImage} alt={media.title} className="w-full h-full object-cover" loading="lazy" />}
+            </div>
+            <p className="text-sm font-medium line-clamp-2">{media.title}</p>
+            <p className="text-xs text-[--muted]">
+                {[media.format, media.year, media.type === "manga" ? media.chapters && `${media.chapters} chapters` : media.episodes && `${media.episodes} episodes`]
+                    .filter(Boolean)
+                    .join(" · ")}
+            </p>
+        </div>
+    )
+
+    if (onClick) {
+        return <div className="cursor-pointer" onClick={onClick}>{content}</div>
+    }
+
+    return <SeaLink href={media.type === "manga" ? `/manga/entry?id=${media.id}` : `/entry?id=${media.id}`}>{content}</SeaLink>
+}
+
+function PluginMediaItemSkeleton() {
+    return (
+        <div className="space-y-1">
+            <Skeleton className="aspect-[6/8] w-full rounded-md" />
+            <Skeleton className="h-4 w-3/4" />
+        </div>
+    )
+}
+
+interface MediaCardProps {
+    mediaId: number
+    type: "anime" | "manga"
+    // Fetched by the server, null if the media could not be found or is still loading
+    media?: PluginMedia | null
+    // True while the server is fetching the media, the component is rendered again once it's done
+    loading?: boolean
+    onClick?: string
+    style?: React.CSSProperties
+    className?: string
+}
+
+export function PluginMediaCard(props: MediaCardProps) {
+    const { sendEventHandlerTriggeredEvent } = usePluginSendEventHandlerTriggeredEvent()
+    const { trayIcon } = usePluginTray()
+
+    if (!props.media && props.loading) {
+        return (
+            <div className={cn("w-40", props.className)} style={props.style}>
+                <PluginMediaItemSkeleton />
+            </div>
+        )
+    }
+
+    if (!props.media) {
+        return <div className={cn("text-sm text-[--muted]", props.className)} style={props.style}>Media not found</div>
+    }
+
+    const onClick = props.onClick ? () => {
+        sendEventHandlerTriggeredEvent({
+            handlerName: props.onClick!,
+            event: { mediaId: props.mediaId },
+        }, trayIcon.extensionId)
+    } : undefined
+
+    return (
+        <div className={cn("w-40", props.className)} style={props.style}>
+            <PluginMediaItem media={props.media} onClick={onClick} />
+        </div>
+    )
+}
+
+interface MediaGridProps {
+    mediaIds: number[]
+    type: "anime" | "manga"
+    // Fetched by the server, media that could not be found are omitted
+    media?: PluginMedia[]
+    // True while the server is fetching some of the media, the component is rendered again once it's done
+    loading?: boolean
+    columns?: number
+    onItemClick?: string
+    style?: React.CSSProperties
+    className?: string
+}
+
+export function PluginMediaGrid(props: MediaGridProps) {
+    const { sendEventHandlerTriggeredEvent } = usePluginSendEventHandlerTriggeredEvent()
+    const { trayIcon } = usePluginTray()
+
+    return (
+        <div
+            className={cn("grid gap-3", props.className)}
+            style={{ gridTemplateColumns: `repeat(${props.columns || 4}, minmax(0, 1fr))`, ...(props.style || {}) }}
+        >
+            {props.media?.map(media => (
+                <PluginMediaItem
+                    key={media.id}
+                    media={media}
+                    onClick={props.onItemClick ? () => {
+                        sendEventHandlerTriggeredEvent({
+                            handlerName: props.onItemClick!,
+                            event: { mediaId: media.id },
+                        }, trayIcon.extensionId)
+                    } : undefined}
+                />
+            ))}
+            {props.loading && Array.from({ length: Math.max(0, Math.min(props.mediaIds.length, 50) - (props.media?.length || 0)) }).map((_, i) => (
+                <PluginMediaItemSkeleton key={`skeleton-${i}`} />
+            ))}
+        </div>
+    )
+}
+
 /////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
 interface FormProps {
@@ -1,16 +1,24 @@
 "use client"
 
 import {
+    PluginBadge,
     PluginButton,
     PluginCheckbox,
+    PluginDataTable,
     PluginDiv,
     PluginFlex,
     PluginForm,
+    PluginImage,
     PluginInput,
+    PluginMediaCard,
+    PluginMediaGrid,
+    PluginModal,
+    PluginProgress,
     PluginRadioGroup,
     PluginSelect,
     PluginStack,
     PluginSwitch,
+    PluginTabs,
     PluginText,
 } from "@/app/(main)/_features/plugin/components/registry-components"
 import type React from "react"
@@ -30,6 +38,14 @@ export const registry: ComponentRegistry = new Map([
     ["radio-group", PluginRadioGroup],
     ["checkbox", PluginCheckbox],
     ["select", PluginSelect],
+    ["image", PluginImage],
+    ["progress", PluginProgress],
+    ["badge", PluginBadge],
+    ["tabs", PluginTabs],
+    ["modal", PluginModal],
+    ["data-table", PluginDataTable],
+    ["media-card", PluginMediaCard],
+    ["media-grid", PluginMediaGrid],
 ] as any)
 
 